@@ -0,0 +1,366 @@
+// cosi: collective signing 운영용 명령행 도구
+//
+// 키 생성, 그룹 파일 작성, 원격 cosigner(cosignerd)와의 서명 라운드,
+// 부분 서명 병합, 오프라인 검증을 하위 명령으로 제공한다.
+// 바이트 값(공개키, 서명, 마스크)은 모두 hex 문자열로 주고받는다.
+//
+//	cosi keygen -o cosigner1.pem
+//	cosi pubkey -key cosigner1.pem
+//	cosi group -threshold 2 -leader <hex> -o group.json <hex>@cosigner1:7000 <hex>@cosigner2:7000 ...
+//	cosi fingerprint -group group.json
+//	cosi sign -key leader.pem -group group.json message.txt
+//	cosi aggregate -group group.json <sig> <sig> ...
+//	cosi verify -group group.json -sig <hex> [-session <hex> -leader <id>] message.txt
+//
+// 메시지 파일을 생략하거나 "-"이면 표준 입력에서 읽는다.
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"test-server/cosirpc"
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/golang-x-crypto/ed25519/cosi"
+	"test-server/golang-x-crypto/ed25519/cosi/protocol"
+	"test-server/group"
+)
+
+// errInvalid: verify가 서명을 거부했을 때, 종료 코드 1
+var errInvalid = errors.New("signature rejected")
+
+// stderr: sign의 라운드 정보(부재 cosigner, 세션 binding) 출력 대상, 테스트에서 교체
+var stderr io.Writer = os.Stderr
+
+type command struct {
+	name, usage string
+	run         func(fs *flag.FlagSet, args []string, stdin io.Reader, stdout io.Writer) error
+}
+
+var commands = []command{
+	{"keygen", "개인키를 만들어 파일에 쓰고 공개키 출력", keygen},
+	{"pubkey", "개인키 파일의 공개키 출력", pubkey},
+	{"group", "공개키[@주소] 목록으로 그룹 파일 작성", makeGroup},
+	{"fingerprint", "그룹 fingerprint 출력 (데몬의 -group-fingerprint 값)", fingerprint},
+	{"sign", "그룹의 cosigner와 서명 라운드를 진행하고 서명 출력", sign},
+	{"aggregate", "서로 다른 cosigner의 부분 서명을 하나로 병합", aggregate},
+	{"verify", "collective signature 검증", verify},
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "cosi:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) == 0 {
+		usage()
+		return flag.ErrHelp
+	}
+	for _, c := range commands {
+		if c.name == args[0] {
+			fs := flag.NewFlagSet("cosi "+c.name, flag.ContinueOnError)
+			return c.run(fs, args[1:], stdin, stdout)
+		}
+	}
+	usage()
+	return fmt.Errorf("unknown command %q", args[0])
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: cosi <command> [flags] [args]")
+	fmt.Fprintln(os.Stderr)
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-12s %s\n", c.name, c.usage)
+	}
+	fmt.Fprintln(os.Stderr, "\n\"cosi <command> -h\"로 명령별 플래그 확인")
+}
+
+func keygen(fs *flag.FlagSet, args []string, _ io.Reader, stdout io.Writer) error {
+	out := fs.String("o", "", "개인키를 쓸 파일 (필수, 이미 있으면 실패)")
+	format := fs.String("format", "pem", "개인키 형식: pem (PKCS #8) 또는 openssh")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *out == "" {
+		return errors.New("-o is required")
+	}
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		return err
+	}
+	var data []byte
+	switch *format {
+	case "pem":
+		data, err = ed25519.MarshalPrivateKeyPEM(priv)
+	case "openssh":
+		data, err = ed25519.MarshalOpenSSHPrivateKey(priv, "", nil)
+	default:
+		return fmt.Errorf("unknown key format %q", *format)
+	}
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(*out, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(stdout, hex.EncodeToString(pub))
+	return err
+}
+
+func pubkey(fs *flag.FlagSet, args []string, _ io.Reader, stdout io.Writer) error {
+	keyPath := fs.String("key", "", "개인키 파일 (PKCS #8 PEM 또는 OpenSSH)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	priv, err := group.LoadPrivateKey(*keyPath)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(stdout, hex.EncodeToString(priv.Public().(ed25519.PublicKey)))
+	return err
+}
+
+// listFlag: 여러 번 줄 수 있는 문자열 플래그
+type listFlag []string
+
+func (l *listFlag) String() string     { return strings.Join(*l, ",") }
+func (l *listFlag) Set(s string) error { *l = append(*l, s); return nil }
+
+func makeGroup(fs *flag.FlagSet, args []string, _ io.Reader, stdout io.Writer) error {
+	var leaders listFlag
+	threshold := fs.Int("threshold", 0, "서명에 필요한 최소 cosigner 수 (0: 모두)")
+	fs.Var(&leaders, "leader", "cosigner가 받아들일 leader 공개키 (hex, 여러 번 지정 가능)")
+	out := fs.String("o", "", "그룹 파일을 쓸 경로 (기본값: 표준 출력)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	f := &group.File{Threshold: *threshold}
+	for _, arg := range fs.Args() {
+		key, addr, _ := strings.Cut(arg, "@")
+		var m group.Member
+		if err := m.PublicKey.UnmarshalText([]byte(key)); err != nil {
+			return fmt.Errorf("cosigner %q: %v", arg, err)
+		}
+		m.Address = addr
+		f.Cosigners = append(f.Cosigners, m)
+	}
+	for _, l := range leaders {
+		var k group.PublicKey
+		if err := k.UnmarshalText([]byte(l)); err != nil {
+			return fmt.Errorf("leader %q: %v", l, err)
+		}
+		f.Leaders = append(f.Leaders, k)
+	}
+	if err := f.Validate(); err != nil {
+		return err
+	}
+	data, err := f.Marshal()
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		_, err = stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(*out, data, 0o644)
+}
+
+func fingerprint(fs *flag.FlagSet, args []string, _ io.Reader, stdout io.Writer) error {
+	groupPath := fs.String("group", "", "그룹 파일 (JSON)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	g, err := group.Load(*groupPath)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(stdout, hex.EncodeToString(g.Fingerprint()))
+	return err
+}
+
+func sign(fs *flag.FlagSet, args []string, stdin io.Reader, stdout io.Writer) error {
+	var metadata listFlag
+	keyPath := fs.String("key", "", "leader 개인키 파일 (PKCS #8 PEM 또는 OpenSSH)")
+	groupPath := fs.String("group", "", "그룹 파일 (JSON)")
+	transport := fs.String("transport", cosirpc.TransportMTLS, "transport: "+strings.Join(cosirpc.Transports, ", "))
+	threshold := fs.Int("threshold", -1, "서명에 필요한 최소 cosigner 수 (기본값: 그룹 파일의 threshold)")
+	mask := fs.String("mask", "", "제외할 cosigner 비트마스크 (hex)")
+	fs.Var(&metadata, "metadata", "cosigner 검증 정책에 전달할 key=value (여러 번 지정 가능)")
+	phaseTimeout := fs.Duration("phase-timeout", protocol.DefaultPhaseTimeout, "commit/response 단계 제한 시간")
+	timeout := fs.Duration("timeout", time.Minute, "라운드 전체 제한 시간")
+	bind := fs.Bool("bind-session", true, "라운드를 세션과 leader에 묶음 (cosi.Binding)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *keyPath == "" || *groupPath == "" {
+		return errors.New("-key and -group are required")
+	}
+	priv, err := group.LoadPrivateKey(*keyPath)
+	if err != nil {
+		return err
+	}
+	g, err := group.Load(*groupPath)
+	if err != nil {
+		return err
+	}
+	maskBytes, err := hex.DecodeString(*mask)
+	if err != nil {
+		return fmt.Errorf("-mask: %v", err)
+	}
+	md := make(protocol.Metadata)
+	for _, kv := range metadata {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			return fmt.Errorf("-metadata %q: want key=value", kv)
+		}
+		md[k] = v
+	}
+	message, err := readMessage(fs, stdin)
+	if err != nil {
+		return err
+	}
+
+	cosigners, closeAll, err := cosirpc.DialGroup(*transport, priv, g)
+	if err != nil {
+		return err
+	}
+	defer closeAll()
+	leader, err := protocol.NewLeader(g.PublicKeys(), cosigners, nil)
+	if err != nil {
+		return err
+	}
+	leader.Threshold = g.Threshold
+	if *threshold >= 0 {
+		leader.Threshold = *threshold
+	}
+	leader.PhaseTimeout = *phaseTimeout
+	leader.BindSessions = *bind
+	leader.ID = hex.EncodeToString(priv.Public().(ed25519.PublicKey))
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	if len(md) > 0 {
+		ctx = protocol.WithMetadata(ctx, md)
+	}
+	res, err := leader.SignWithMask(ctx, message, maskBytes)
+	if err != nil {
+		return err
+	}
+	if len(res.Absent) > 0 || len(res.Blamed) > 0 {
+		fmt.Fprintf(stderr, "cosi: signed without cosigners absent=%v blamed=%v\n", res.Absent, res.Blamed)
+	}
+	if res.Binding != nil {
+		fmt.Fprintf(stderr, "cosi: bound; verify with -session %x -leader %s\n", res.Binding.SessionID, res.Binding.Leader)
+	}
+	_, err = fmt.Fprintln(stdout, hex.EncodeToString(res.Signature))
+	return err
+}
+
+func aggregate(fs *flag.FlagSet, args []string, _ io.Reader, stdout io.Writer) error {
+	groupPath := fs.String("group", "", "그룹 파일 (JSON)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *groupPath == "" || fs.NArg() < 2 {
+		return errors.New("usage: cosi aggregate -group group.json <sig> <sig> ...")
+	}
+	g, err := group.Load(*groupPath)
+	if err != nil {
+		return err
+	}
+	cos := g.NewCosigners()
+	sig, err := hex.DecodeString(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("signature 1: %v", err)
+	}
+	for i, arg := range fs.Args()[1:] {
+		part, err := hex.DecodeString(arg)
+		if err != nil {
+			return fmt.Errorf("signature %d: %v", i+2, err)
+		}
+		if sig, err = cos.MergeSignatures(sig, part); err != nil {
+			return fmt.Errorf("signature %d: %w", i+2, err)
+		}
+	}
+	_, err = fmt.Fprintln(stdout, hex.EncodeToString(sig))
+	return err
+}
+
+func verify(fs *flag.FlagSet, args []string, stdin io.Reader, stdout io.Writer) error {
+	groupPath := fs.String("group", "", "그룹 파일 (JSON)")
+	sigHex := fs.String("sig", "", "collective signature (hex)")
+	threshold := fs.Int("threshold", -1, "서명에 필요한 최소 cosigner 수 (기본값: 그룹 파일의 threshold)")
+	session := fs.String("session", "", "서명이 묶인 세션 ID (hex, cosi sign이 stderr에 출력)")
+	leaderID := fs.String("leader", "", "서명이 묶인 leader ID (-session과 함께 지정)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if (*session == "") != (*leaderID == "") {
+		return errors.New("-session and -leader go together")
+	}
+	if *groupPath == "" || *sigHex == "" {
+		return errors.New("-group and -sig are required")
+	}
+	g, err := group.Load(*groupPath)
+	if err != nil {
+		return err
+	}
+	if *threshold >= 0 {
+		g.Threshold = *threshold
+	}
+	sig, err := hex.DecodeString(*sigHex)
+	if err != nil {
+		return fmt.Errorf("-sig: %v", err)
+	}
+	message, err := readMessage(fs, stdin)
+	if err != nil {
+		return err
+	}
+	if *session != "" {
+		sid, err := hex.DecodeString(*session)
+		if err != nil {
+			return fmt.Errorf("-session: %v", err)
+		}
+		message = cosi.Binding{SessionID: sid, Leader: []byte(*leaderID)}.Message(message)
+	}
+	cos := g.NewCosigners()
+	if err := cos.VerifyErr(message, sig); err != nil {
+		return fmt.Errorf("%w: %v", errInvalid, err)
+	}
+	_, err = fmt.Fprintf(stdout, "ok: signed by %d of %d cosigners\n", cos.CountEnabled(), cos.CountTotal())
+	return err
+}
+
+// readMessage: 남은 인자의 파일, 없거나 "-"이면 stdin에서 메시지를 읽음
+func readMessage(fs *flag.FlagSet, stdin io.Reader) ([]byte, error) {
+	switch fs.NArg() {
+	case 0:
+		return io.ReadAll(stdin)
+	case 1:
+		if fs.Arg(0) == "-" {
+			return io.ReadAll(stdin)
+		}
+		return os.ReadFile(fs.Arg(0))
+	}
+	return nil, fmt.Errorf("too many arguments: %q", fs.Args())
+}
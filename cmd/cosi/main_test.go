@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+
+	"test-server/cosirpc"
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/golang-x-crypto/ed25519/cosi"
+	"test-server/golang-x-crypto/ed25519/cosi/protocol"
+	"test-server/group"
+)
+
+func cosiRun(t *testing.T, stdin string, args ...string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	err := run(args, strings.NewReader(stdin), &out)
+	return strings.TrimSpace(out.String()), err
+}
+
+func mustRun(t *testing.T, stdin string, args ...string) string {
+	t.Helper()
+	out, err := cosiRun(t, stdin, args...)
+	if err != nil {
+		t.Fatalf("cosi %s: %v", strings.Join(args, " "), err)
+	}
+	return out
+}
+
+// serveCosigners: 키 파일마다 mtls cosirpc 서버를 띄움
+// 주소를 그룹 파일에 넣어야 하므로 listener를 먼저 만들고 그룹 작성 후 serve 시작
+func serveCosigners(t *testing.T, keyPaths []string) (addrs []string, serve func(groupPath string)) {
+	var listeners []net.Listener
+	for range keyPaths {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { lis.Close() })
+		listeners = append(listeners, lis)
+		addrs = append(addrs, lis.Addr().String())
+	}
+	return addrs, func(groupPath string) {
+		g, err := group.Load(groupPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, path := range keyPaths {
+			priv, err := group.LoadPrivateKey(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			creds, peerKey, err := cosirpc.ServerCredentials(cosirpc.TransportMTLS, priv, g.LeaderKeys(), g.Fingerprint())
+			if err != nil {
+				t.Fatal(err)
+			}
+			gs := grpc.NewServer(grpc.Creds(creds))
+			(&cosirpc.Server{Cosigner: protocol.NewService(priv, nil), PeerKey: peerKey}).Register(gs)
+			go gs.Serve(listeners[i])
+			t.Cleanup(gs.Stop)
+		}
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	dir := t.TempDir()
+	leaderKey := filepath.Join(dir, "leader.pem")
+	leaderPub := mustRun(t, "", "keygen", "-o", leaderKey)
+	if got := mustRun(t, "", "pubkey", "-key", leaderKey); got != leaderPub {
+		t.Errorf("pubkey = %s, keygen printed %s", got, leaderPub)
+	}
+
+	var keyPaths, pubs []string
+	for i := 0; i < 3; i++ {
+		path := filepath.Join(dir, fmt.Sprintf("c%d.key", i))
+		format := "pem"
+		if i == 2 {
+			format = "openssh"
+		}
+		keyPaths = append(keyPaths, path)
+		pubs = append(pubs, mustRun(t, "", "keygen", "-format", format, "-o", path))
+	}
+	if _, err := cosiRun(t, "", "keygen", "-o", keyPaths[0]); err == nil {
+		t.Errorf("keygen overwrote an existing key")
+	}
+
+	addrs, serve := serveCosigners(t, keyPaths)
+	groupPath := filepath.Join(dir, "group.json")
+	args := []string{"group", "-threshold", "2", "-leader", leaderPub, "-o", groupPath}
+	for i := range pubs {
+		args = append(args, pubs[i]+"@"+addrs[i])
+	}
+	mustRun(t, "", args...)
+	serve(groupPath)
+	if fp := mustRun(t, "", "fingerprint", "-group", groupPath); len(fp) != 2*cosi.FingerprintSize {
+		t.Errorf("fingerprint = %q", fp)
+	}
+
+	message := "test message"
+	sig := mustRun(t, message, "sign", "-bind-session=false", "-key", leaderKey, "-group", groupPath, "-metadata", "content-type=text/plain")
+	if got := mustRun(t, message, "verify", "-group", groupPath, "-sig", sig); !strings.HasPrefix(got, "ok: signed by 3 of 3") {
+		t.Errorf("verify = %q", got)
+	}
+	msgPath := filepath.Join(dir, "message.txt")
+	if err := os.WriteFile(msgPath, []byte(message), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mustRun(t, "", "verify", "-group", groupPath, "-sig", sig, msgPath)
+	if _, err := cosiRun(t, "other message", "verify", "-group", groupPath, "-sig", sig); !errors.Is(err, errInvalid) {
+		t.Errorf("verify(other message) error = %v, want errInvalid", err)
+	}
+
+	// 마스크로 cosigner 1을 빼면 threshold 2는 통과, 3은 실패
+	sig = mustRun(t, message, "sign", "-bind-session=false", "-key", leaderKey, "-group", groupPath, "-mask", "02")
+	mustRun(t, message, "verify", "-group", groupPath, "-sig", sig)
+	if _, err := cosiRun(t, message, "verify", "-group", groupPath, "-threshold", "3", "-sig", sig); !errors.Is(err, errInvalid) {
+		t.Errorf("verify(-threshold 3) error = %v, want errInvalid", err)
+	}
+
+	// 기본값인 묶인 서명은 sign이 stderr에 출력한 -session, -leader로만 검증됨
+	var hint bytes.Buffer
+	stderr = &hint
+	defer func() { stderr = os.Stderr }()
+	sig = mustRun(t, message, "sign", "-key", leaderKey, "-group", groupPath)
+	_, bound, ok := strings.Cut(strings.TrimSpace(hint.String()), "verify with ")
+	if !ok {
+		t.Fatalf("sign printed no binding: %q", hint.String())
+	}
+	if _, err := cosiRun(t, message, "verify", "-group", groupPath, "-sig", sig); !errors.Is(err, errInvalid) {
+		t.Errorf("bound signature verified without its binding: %v", err)
+	}
+	mustRun(t, message, append([]string{"verify", "-group", groupPath, "-sig", sig}, strings.Fields(bound)...)...)
+	if _, err := cosiRun(t, message, "verify", "-group", groupPath, "-sig", sig, "-session", "00", "-leader", leaderPub); !errors.Is(err, errInvalid) {
+		t.Errorf("bound signature verified under another session: %v", err)
+	}
+	if _, err := cosiRun(t, message, "verify", "-group", groupPath, "-sig", sig, "-session", "00"); err == nil || errors.Is(err, errInvalid) {
+		t.Errorf("-session without -leader: %v", err)
+	}
+}
+
+func TestGroupErrors(t *testing.T) {
+	pub, _, _ := ed25519.GenerateKey(nil)
+	key := hex.EncodeToString(pub)
+	for _, args := range [][]string{
+		{"group"},
+		{"group", "zz@localhost:7000"},
+		{"group", key, key},
+		{"group", "-threshold", "2", key},
+		{"group", "-leader", "abcd", key},
+	} {
+		if _, err := cosiRun(t, "", args...); err == nil {
+			t.Errorf("cosi %s succeeded", strings.Join(args, " "))
+		}
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	const n = 4
+	pubs := make([]ed25519.PublicKey, n)
+	privs := make([]ed25519.PrivateKey, n)
+	f := &group.File{}
+	for i := range pubs {
+		pubs[i], privs[i], _ = ed25519.GenerateKey(nil)
+		f.Cosigners = append(f.Cosigners, group.Member{PublicKey: group.PublicKey(pubs[i])})
+	}
+	data, err := f.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	groupPath := filepath.Join(t.TempDir(), "group.json")
+	if err := os.WriteFile(groupPath, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// commit은 그룹 전체가 함께 하고, 응답은 두 sub-leader가 절반씩 모은 경우
+	message := []byte("test message")
+	cos := cosi.NewCosigners(pubs, nil)
+	commits := make([]cosi.Commitment, n)
+	secrets := make([]*cosi.Secret, n)
+	for i := range commits {
+		commits[i], secrets[i], _ = cosi.Commit(nil)
+	}
+	aggK, aggR := cos.AggregatePublicKey(), cos.AggregateCommit(commits)
+	partial := func(lo, hi int) string {
+		sub := cosi.NewCosigners(pubs, nil)
+		parts := make([]cosi.SignaturePart, n)
+		for i := range parts {
+			if i >= lo && i < hi {
+				parts[i] = cosi.Cosign(privs[i], secrets[i], message, aggK, aggR)
+			} else {
+				parts[i] = make([]byte, 32)
+				sub.SetMaskBit(i, cosi.Disabled)
+			}
+		}
+		return hex.EncodeToString(append(sub.AggregateSignature(aggR, parts), sub.Mask()...))
+	}
+	sig1, sig2 := partial(0, 2), partial(2, n)
+
+	if _, err := cosiRun(t, string(message), "verify", "-group", groupPath, "-sig", sig1); !errors.Is(err, errInvalid) {
+		t.Errorf("partial signature accepted without threshold: %v", err)
+	}
+	sig := mustRun(t, "", "aggregate", "-group", groupPath, sig1, sig2)
+	mustRun(t, string(message), "verify", "-group", groupPath, "-sig", sig)
+	if _, err := cosiRun(t, "", "aggregate", "-group", groupPath, sig1, sig1); err == nil {
+		t.Errorf("overlapping partial signatures merged")
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"test-server/config"
+	"test-server/golang-x-crypto/ed25519/cosi/protocol"
+	"test-server/health"
+)
+
+// lifecycle.go: 종료 시 진행 중인 라운드 정리와 세션 상태 보존
+//
+// SIGTERM을 받으면
+//  1. 새 라운드(commit)를 ErrDraining으로 거부하고 /readyz를 실패로 바꾼다.
+//  2. 열린 세션이 응답을 받거나 만료될 때까지 최대 -drain-timeout 기다린다.
+//  3. 남은 세션은 -state-file에 저장한다(없으면 버림).
+//  4. gRPC 서버를 GracefulStop으로 닫는다.
+//
+// 다시 시작할 때 state 파일이 있으면 세션을 복원하고 파일을 지운 뒤 서비스를 시작하므로,
+// leader는 만료 전이라면 재시작을 사이에 둔 라운드도 마칠 수 있다.
+
+// shutdown: 위 순서로 cosigner를 종료
+func shutdown(svc *protocol.Service, gs *grpc.Server, stopHealth func(), drainTimeout time.Duration,
+	stateFile string, logger *slog.Logger) {
+
+	logger.Info("draining", "open-sessions", svc.OpenSessions(), "timeout", drainTimeout)
+	svc.Drain()
+	ctx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+	err := svc.WaitIdle(ctx)
+	cancel()
+	if err != nil {
+		if stateFile == "" {
+			logger.Warn("dropping open sessions, no -state-file", "open-sessions", svc.OpenSessions())
+		} else if n, err := saveSessions(stateFile, svc); err != nil {
+			logger.Error("saving open sessions failed", "file", stateFile, "saved", n, "err", err)
+		} else {
+			logger.Info("saved open sessions", "file", stateFile, "sessions", n)
+		}
+	}
+	stopHealth()
+	gs.GracefulStop()
+}
+
+// drainingCheck: 종료 중이면 실패하는 readiness 점검
+func drainingCheck(svc *protocol.Service) health.Check {
+	return health.Check{Name: "draining", Run: func(context.Context) (string, error) {
+		if svc.Draining() {
+			return "", errors.New("shutting down")
+		}
+		return "", nil
+	}}
+}
+
+// saveSessions: 열린 세션을 path에 원자적으로 저장
+// 세션에는 commit secret이 들어 있으므로 파일은 0600으로 만든다.
+func saveSessions(path string, svc *protocol.Service) (int, error) {
+	var buf bytes.Buffer
+	n, err := svc.SaveSessions(&buf)
+	if n == 0 {
+		return 0, err
+	}
+	if werr := config.WriteFile(path, buf.Bytes(), 0o600); werr != nil {
+		return 0, werr
+	}
+	return n, err
+}
+
+// loadSessions: path가 있으면 세션을 복원하고 파일을 지움
+// 같은 commit secret이 두 번 복원되지 않도록, 지우지 못하면 에러로 시작을 멈춘다.
+func loadSessions(path string, svc *protocol.Service) (int, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	n, err := svc.LoadSessions(f)
+	f.Close()
+	if err != nil {
+		return 0, fmt.Errorf("state file %s: %w (move it aside to start without it)", path, err)
+	}
+	if err := os.Remove(path); err != nil {
+		return 0, fmt.Errorf("state file %s: %w", path, err)
+	}
+	return n, nil
+}
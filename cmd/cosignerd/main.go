@@ -0,0 +1,297 @@
+// cosignerd: cosi 서명 라운드에 참여하는 cosigner 데몬
+//
+// 개인키와 그룹 파일(group 패키지)을 읽고, 그룹 파일의 leader만 받아들이는
+// transport로 Cosigner gRPC 서비스(cosirpc)를 제공한다.
+// 서명할 메시지는 검증 정책(-max-message-size, -content-types, -policy)을
+// 모두 통과해야 commit한다.
+// SIGHUP을 받거나(-reload-interval을 주면) 그룹 파일이 바뀌면 그룹을 다시 읽는다(reload.go).
+// SIGTERM을 받으면 진행 중인 라운드를 마치고(-drain-timeout) 남은 세션을
+// -state-file에 저장한 뒤 종료한다(lifecycle.go).
+// -health-listen을 주면 그 주소에서 /healthz, /readyz(health 패키지)로 상태를 보고한다.
+//
+//	cosignerd -key cosigner1.pem -group group.json -listen :7000
+//
+// 모든 플래그는 설정 파일(-config)과 COSIGNERD_ 환경 변수로도 줄 수 있다(config 패키지).
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"test-server/config"
+	"test-server/cosirpc"
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/golang-x-crypto/ed25519/cosi/celpolicy"
+	"test-server/golang-x-crypto/ed25519/cosi/protocol"
+	"test-server/group"
+	"test-server/health"
+)
+
+type options struct {
+	keyPath, groupPath string
+	listen             string
+	transport          string
+	fingerprint        config.Fingerprint
+
+	maxMessageSize int
+	contentTypes   config.List
+	policyPath     string
+	requireBinding bool
+	sessionTimeout time.Duration
+	limits         protocol.Limits
+
+	healthListen string
+	maxRoundAge  time.Duration
+
+	drainTimeout time.Duration
+	stateFile    string
+
+	reloadInterval time.Duration
+	groupUpdates   bool
+
+	logLevel slog.Level
+}
+
+func main() {
+	var o options
+	flag.StringVar(&o.keyPath, "key", "", "개인키 파일 (PKCS #8 PEM 또는 OpenSSH)")
+	flag.StringVar(&o.groupPath, "group", "", "그룹 파일 (JSON)")
+	flag.StringVar(&o.listen, "listen", "", "listen 주소 (기본값: 그룹 파일의 자기 주소)")
+	flag.StringVar(&o.transport, "transport", cosirpc.TransportMTLS,
+		"transport: "+strings.Join(cosirpc.Transports, ", "))
+	flag.Var(&o.fingerprint, "group-fingerprint", "그룹 fingerprint 고정 (hex, cosi fingerprint로 확인)")
+	flag.IntVar(&o.maxMessageSize, "max-message-size", 0, "서명할 메시지 최대 크기 (0: 제한 없음)")
+	flag.Var(&o.contentTypes, "content-types", "허용할 content-type 메타데이터 (쉼표로 구분, 여러 번 지정 가능)")
+	flag.StringVar(&o.policyPath, "policy", "", "승인 규칙 CEL 표현식 파일 (celpolicy)")
+	flag.BoolVar(&o.requireBinding, "require-binding", true, "세션과 leader에 묶인 라운드만 서명 (false면 묶이지 않은 라운드도 서명)")
+	flag.DurationVar(&o.sessionTimeout, "session-timeout", protocol.DefaultSessionTimeout, "응답 없는 세션 보관 시간")
+	flag.Float64Var(&o.limits.Rate, "rate", 0, "초당 commit 요청 수 제한 (0: 제한 없음)")
+	flag.IntVar(&o.limits.Burst, "burst", 0, "-rate의 burst")
+	flag.Float64Var(&o.limits.LeaderRate, "leader-rate", 0, "leader별 초당 commit 요청 수 제한")
+	flag.IntVar(&o.limits.LeaderBurst, "leader-burst", 0, "-leader-rate의 burst")
+	flag.IntVar(&o.limits.MaxRounds, "max-rounds", 0, "동시에 열린 세션 수 제한")
+	flag.IntVar(&o.limits.MaxLeaderRounds, "max-leader-rounds", 0, "leader별 동시에 열린 세션 수 제한")
+	flag.StringVar(&o.healthListen, "health-listen", "", "/healthz, /readyz HTTP listen 주소 (기본값: 사용 안 함)")
+	flag.DurationVar(&o.maxRoundAge, "max-round-age", 0, "마지막 성공 라운드가 이보다 오래되면 /readyz 실패 (0: 보고만 함)")
+	flag.DurationVar(&o.drainTimeout, "drain-timeout", 30*time.Second, "종료 시 진행 중인 라운드를 기다리는 최대 시간")
+	flag.StringVar(&o.stateFile, "state-file", "", "종료 시 남은 세션을 저장하고 시작 시 복원할 파일 (기본값: 저장 안 함)")
+	flag.DurationVar(&o.reloadInterval, "reload-interval", 0, "그룹 파일 변경 확인 주기 (0: SIGHUP으로만 다시 읽음)")
+	flag.BoolVar(&o.groupUpdates, "group-updates", false, "leader가 보낸, 실행 중인 그룹이 승인한 그룹 파일로 -group 파일을 교체")
+	flag.TextVar(&o.logLevel, "log-level", slog.LevelInfo, "로그 레벨 (debug, info, warn, error)")
+	if err := config.Parse(flag.CommandLine, os.Args[1:], "COSIGNERD"); err != nil {
+		fmt.Fprintln(os.Stderr, "cosignerd:", err)
+		os.Exit(2)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: o.logLevel}))
+	if err := run(&o, logger); err != nil {
+		logger.Error("cosignerd failed", "err", err)
+		os.Exit(1)
+	}
+}
+
+func run(o *options, logger *slog.Logger) error {
+	validator, err := o.validate()
+	if err != nil {
+		return err
+	}
+	priv, err := group.LoadPrivateKey(o.keyPath)
+	if err != nil {
+		return err
+	}
+	pub := priv.Public().(ed25519.PublicKey)
+	svc := protocol.NewService(priv, nil)
+	svc.SessionTimeout = o.sessionTimeout
+	svc.Limits = o.limits
+	svc.Validator = validator
+	svc.RequireBinding = o.requireBinding
+	svc.Logger = logger
+	if o.stateFile != "" {
+		n, err := loadSessions(o.stateFile, svc)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			logger.Info("restored open sessions", "file", o.stateFile, "sessions", n)
+		}
+	}
+	groups := &groupLoader{o: o, priv: priv, svc: svc, logger: logger}
+	if err := groups.load(); err != nil {
+		return err
+	}
+	g := groups.current.Load()
+	listen := o.listen
+	if listen == "" {
+		listen = g.file.Cosigners[g.index].Address
+	}
+	if listen == "" {
+		return errors.New("no -listen address and none in the group file")
+	}
+
+	lastRound := health.NewLastRound()
+	gs := grpc.NewServer(grpc.Creds(groups.creds))
+	srv := &cosirpc.Server{Cosigner: &recordingCosigner{svc, lastRound}, PeerKey: groups.peerKey}
+	if groups.peerKey != nil {
+		srv.Authorize = groups.authorize
+	}
+	if o.groupUpdates {
+		srv.GroupUpdater = groups.update
+	}
+	srv.Register(gs)
+
+	lis, err := net.Listen("tcp", listen)
+	if err != nil {
+		return err
+	}
+	stopHealth := func() {}
+	if o.healthListen != "" {
+		checker := &health.Checker{Checks: []health.Check{
+			health.Key(priv),
+			health.KeyFile(o.keyPath, pub),
+			health.Group(o.groupPath, groups.fingerprint),
+			listenerCheck(lis.Addr().String()),
+			lastRound.Check(o.maxRoundAge),
+			drainingCheck(svc),
+		}}
+		hs := &http.Server{Handler: checker.Handler()}
+		stopHealth = func() { hs.Close() }
+		hlis, err := net.Listen("tcp", o.healthListen)
+		if err != nil {
+			lis.Close()
+			return err
+		}
+		go func() {
+			if err := hs.Serve(hlis); err != http.ErrServerClosed {
+				logger.Error("health server failed", "err", err)
+			}
+		}()
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	reloader := &config.Reloader{Path: o.groupPath, Interval: o.reloadInterval, Signals: hup,
+		Reload: groups.load, Logger: logger}
+	reloader.Start(ctx)
+	done := make(chan struct{})
+	go func() {
+		<-ctx.Done()
+		stop() // 두 번째 신호는 바로 종료
+		shutdown(svc, gs, stopHealth, o.drainTimeout, o.stateFile, logger)
+		close(done)
+	}()
+
+	logger.Info("cosignerd started", "addr", lis.Addr().String(), "index", g.index,
+		"cosigners", len(g.file.Cosigners), "transport", o.transport)
+	if err := gs.Serve(lis); err != nil {
+		return err
+	}
+	<-done
+	logger.Info("stopped")
+	return nil
+}
+
+// recordingCosigner: 성공한 응답(서명 라운드 참여)을 lastRound에 기록
+type recordingCosigner struct {
+	protocol.Cosigner
+	lastRound *health.LastRound
+}
+
+func (c *recordingCosigner) Respond(ctx context.Context, req *protocol.ChallengeRequest) (*protocol.ChallengeResponse, error) {
+	resp, err := c.Cosigner.Respond(ctx, req)
+	if err == nil {
+		c.lastRound.Record()
+	}
+	return resp, err
+}
+
+// listenerCheck: cosigner gRPC listener가 TCP 연결을 받는지
+// leader 쪽에서 보는 transport 연결은 leaderd의 peers 점검이 확인한다.
+func listenerCheck(addr string) health.Check {
+	return health.Check{Name: "listener", Run: func(ctx context.Context) (string, error) {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return "", err
+		}
+		conn.Close()
+		return addr, nil
+	}}
+}
+
+// validate: 시작 전 설정 검사, 문제를 모두 모아 보고
+// 승인 규칙도 여기서 컴파일해 검증 정책(newValidator)을 반환
+func (o *options) validate() (protocol.Validator, error) {
+	var p config.Problems
+	p.Check("key", config.KeyFile(o.keyPath))
+	p.Check("group", config.File(o.groupPath))
+	if o.listen != "" {
+		p.Check("listen", config.Addr(o.listen))
+	}
+	if !slices.Contains(cosirpc.Transports, o.transport) {
+		p.Check("transport", fmt.Errorf("unknown transport %q (want one of %s)", o.transport, strings.Join(cosirpc.Transports, ", ")))
+	}
+	p.Check("max-message-size", config.NonNegative(o.maxMessageSize))
+	p.Check("session-timeout", config.Positive(o.sessionTimeout))
+	p.Check("burst", config.NonNegative(o.limits.Burst))
+	p.Check("leader-burst", config.NonNegative(o.limits.LeaderBurst))
+	p.Check("max-rounds", config.NonNegative(o.limits.MaxRounds))
+	p.Check("max-leader-rounds", config.NonNegative(o.limits.MaxLeaderRounds))
+	if o.healthListen != "" {
+		p.Check("health-listen", config.Addr(o.healthListen))
+	}
+	if o.maxRoundAge < 0 {
+		p.Check("max-round-age", fmt.Errorf("must not be negative, got %v", o.maxRoundAge))
+	}
+	p.Check("drain-timeout", config.Positive(o.drainTimeout))
+	if o.reloadInterval < 0 {
+		p.Check("reload-interval", fmt.Errorf("must not be negative, got %v", o.reloadInterval))
+	}
+	if o.stateFile != "" {
+		p.Check("state-file", config.Dir(filepath.Dir(o.stateFile)))
+	}
+	validator, err := newValidator(o)
+	p.Check("policy", err)
+	return validator, p.Err()
+}
+
+// newValidator: 플래그로 지정한 검증 정책을 모두 통과해야 하는 Validator, 없으면 nil
+func newValidator(o *options) (protocol.Validator, error) {
+	var vs []protocol.Validator
+	if o.maxMessageSize > 0 {
+		vs = append(vs, protocol.MaxMessageSize(o.maxMessageSize))
+	}
+	if len(o.contentTypes) > 0 {
+		vs = append(vs, protocol.ContentTypes(o.contentTypes...))
+	}
+	if o.policyPath != "" {
+		expr, err := os.ReadFile(o.policyPath)
+		if err != nil {
+			return nil, err
+		}
+		p, err := celpolicy.New(string(expr))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", o.policyPath, err)
+		}
+		vs = append(vs, p)
+	}
+	if len(vs) == 0 {
+		return nil, nil
+	}
+	return protocol.Validators(vs...), nil
+}
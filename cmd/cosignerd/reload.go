@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"test-server/config"
+	"test-server/cosirpc"
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/golang-x-crypto/ed25519/cosi/protocol"
+	"test-server/group"
+)
+
+// reload.go: 그룹 파일 재적재 (SIGHUP, -reload-interval)
+//
+// 그룹 파일을 다시 읽으면 서비스를 멈추지 않고
+//   - 받아들일 leader와 transport 설정(credentials)을 새 연결부터 바꾸고,
+//     이미 연결된 leader도 요청마다 새 leader 목록으로 확인하며(cosirpc.Server.Authorize),
+//   - 새 그룹의 fingerprint로 라운드를 받는다(protocol.Service.SetGroup).
+//
+// 이전 그룹으로 연 세션은 그대로 응답한다. listen 주소는 다시 시작해야 바뀐다.
+//
+// -group-updates를 주면 leader가 UpdateGroup RPC로 보낸 그룹 파일도 받아들인다.
+// 실행 중인 그룹이 그 파일을 승인(group.File.VerifyRotation)했을 때만
+// -group 파일을 교체하고 같은 방법으로 다시 읽는다.
+
+// groupLoader: 그룹 파일을 읽어 서비스와 transport에 반영
+type groupLoader struct {
+	o      *options
+	priv   ed25519.PrivateKey
+	svc    *protocol.Service
+	logger *slog.Logger
+
+	creds   *cosirpc.SwappableCredentials
+	peerKey func(context.Context) (ed25519.PublicKey, error) // transport는 바뀌지 않으므로 처음 것을 씀
+	mu      sync.Mutex                                       // load, update 직렬화
+	current atomic.Pointer[loadedGroup]
+}
+
+// loadedGroup: 실행 중인 그룹
+type loadedGroup struct {
+	file        *group.File
+	fingerprint []byte
+	index       int
+}
+
+// load: 그룹 파일을 읽어 검사하고 반영, 실패하면 실행 중인 그룹을 그대로 둠
+func (l *groupLoader) load() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.loadLocked()
+}
+
+func (l *groupLoader) loadLocked() error {
+	g, err := group.Load(l.o.groupPath)
+	if err != nil {
+		return err
+	}
+	if err := l.o.fingerprint.Check(g); err != nil {
+		return fmt.Errorf("%s: %w", l.o.groupPath, err)
+	}
+	index := g.Index(l.priv.Public().(ed25519.PublicKey))
+	if index < 0 {
+		return fmt.Errorf("key %s is not a member of group %s", l.o.keyPath, l.o.groupPath)
+	}
+	fingerprint := g.Fingerprint()
+	creds, peerKey, err := cosirpc.ServerCredentials(l.o.transport, l.priv, g.LeaderKeys(), fingerprint)
+	if err != nil {
+		return err
+	}
+
+	lg := &loadedGroup{file: g, fingerprint: fingerprint, index: index}
+	old := l.current.Load()
+	if l.creds == nil {
+		l.creds, l.peerKey = cosirpc.NewSwappableCredentials(creds), peerKey
+	} else {
+		l.creds.Swap(creds)
+	}
+	l.svc.SetGroup(fingerprint)
+	l.current.Store(lg)
+	if old == nil {
+		return nil
+	}
+	if old.file.Cosigners[old.index].Address != g.Cosigners[index].Address && l.o.listen == "" {
+		l.logger.Warn("own address changed in the group file, restart to listen on it",
+			"address", g.Cosigners[index].Address)
+	}
+	if !bytes.Equal(old.fingerprint, fingerprint) {
+		l.logger.Info("group changed", "fingerprint", hex.EncodeToString(fingerprint),
+			"index", index, "cosigners", len(g.Cosigners))
+	}
+	return nil
+}
+
+// update: leader가 보낸 그룹 파일이 실행 중인 그룹의 승인을 받았으면 -group 파일을 교체하고 다시 읽음
+// (cosirpc.Server.GroupUpdater)
+func (l *groupLoader) update(_ context.Context, leader string, u *cosirpc.GroupUpdate) ([]byte, error) {
+	next, err := group.Parse(u.Group)
+	if err != nil {
+		return nil, fmt.Errorf("invalid group file: %v", err)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	cur := l.current.Load()
+	fingerprint := next.Fingerprint()
+	if bytes.Equal(fingerprint, cur.fingerprint) {
+		return fingerprint, nil // 이미 반영됨 (새 그룹 파일로 시작한 cosigner 포함)
+	}
+	if err := cur.file.VerifyRotation(next, u.Signature, u.Binding); err != nil {
+		return nil, err
+	}
+	if err := l.o.fingerprint.Check(next); err != nil {
+		return nil, err
+	}
+	if next.Index(l.priv.Public().(ed25519.PublicKey)) < 0 {
+		return nil, errors.New("this cosigner is not a member of the new group")
+	}
+	data, err := next.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(l.o.groupPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := config.WriteFile(l.o.groupPath, data, info.Mode().Perm()); err != nil {
+		return nil, err
+	}
+	l.logger.Info("group file replaced by an endorsed update", "leader", leader,
+		"fingerprint", hex.EncodeToString(fingerprint))
+	if err := l.loadLocked(); err != nil {
+		return nil, err
+	}
+	return fingerprint, nil
+}
+
+// authorize: 인증된 leader가 실행 중인 그룹의 leader인지 (cosirpc.Server.Authorize)
+func (l *groupLoader) authorize(leader ed25519.PublicKey) error {
+	for _, k := range l.current.Load().file.LeaderKeys() {
+		if bytes.Equal(k, leader) {
+			return nil
+		}
+	}
+	return errors.New("leader is not in the current group")
+}
+
+// fingerprint: 실행 중인 그룹의 fingerprint
+func (l *groupLoader) fingerprint() []byte {
+	return l.current.Load().fingerprint
+}
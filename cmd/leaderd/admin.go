@@ -0,0 +1,396 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"test-server/cosirpc"
+	"test-server/golang-x-crypto/ed25519/cosi"
+	"test-server/golang-x-crypto/ed25519/cosi/protocol"
+	"test-server/group"
+)
+
+// admin.go: 그룹 구성원 관리 API (-admin-listen)
+//
+// 모든 요청에 Authorization: Bearer <-admin-token-file의 내용>이 필요하다.
+//
+//	GET    /admin/v1/group                   실행 중인 그룹과 cosigner별 연결 상태, 연결되지 않는 cosigner 마스크
+//	POST   /admin/v1/proposals               cosigner 추가·삭제(와 threshold 변경) 제안, 새 그룹 파일을 만든다
+//	GET    /admin/v1/proposals               제안 목록
+//	GET    /admin/v1/proposals/{id}          제안 하나
+//	DELETE /admin/v1/proposals/{id}          제안 폐기
+//	POST   /admin/v1/proposals/{id}/endorse  지금 그룹이 새 그룹 파일에 서명 (group.RotationMessage)
+//	POST   /admin/v1/proposals/{id}/apply    승인된 새 그룹을 leader에 반영하고 cosigner에 배포
+//
+// 변경은 제안 → endorse → apply 순서로 진행한다. endorse는 보통의 서명 라운드로,
+// cosigner는 content-type이 group.RotationContentType인 메시지로 받아 검증 정책을 적용한다.
+// apply는 endorsement를 다시 검증하고 -group 파일을 교체한 뒤, 새 그룹의 cosigner에
+// UpdateGroup RPC로 새 파일과 endorsement를 보낸다(cosignerd -group-updates).
+// 제안은 메모리에만 있으며, 그룹이 바뀌면(apply, 재적재) 이전 그룹에 대한 제안은 쓸 수 없다.
+
+type proposalRequest struct {
+	Add       []group.Member    `json:"add,omitempty"`
+	Remove    []group.PublicKey `json:"remove,omitempty"`
+	Threshold *int              `json:"threshold,omitempty"` // 없으면 지금 threshold (삭제로 범위를 넘으면 cosigner 수)
+}
+
+type proposal struct {
+	ID          string       `json:"id"`
+	Created     time.Time    `json:"created"`
+	From        hexBytes     `json:"from"` // 제안한 때의 그룹 fingerprint
+	Fingerprint hexBytes     `json:"fingerprint"`
+	Group       *group.File  `json:"group"`
+	Added       []int        `json:"added,omitempty"`   // 새 그룹에서의 번호
+	Removed     []int        `json:"removed,omitempty"` // 지금 그룹에서의 번호
+	Endorsement *endorsement `json:"endorsement,omitempty"`
+}
+
+type endorsement struct {
+	Signature hexBytes `json:"signature"` // R || s || mask
+	Absent    []int    `json:"absent,omitempty"`
+	Binding   *binding `json:"binding,omitempty"`
+}
+
+type memberStatus struct {
+	Index     int             `json:"index"`
+	PublicKey group.PublicKey `json:"publicKey"`
+	Address   string          `json:"address,omitempty"`
+	Reachable bool            `json:"reachable"`
+	Error     string          `json:"error,omitempty"`
+}
+
+type groupStatus struct {
+	Fingerprint hexBytes          `json:"fingerprint"`
+	Threshold   int               `json:"threshold"` // 서명에 필요한 cosigner 수
+	Leaders     []group.PublicKey `json:"leaders,omitempty"`
+	Cosigners   []memberStatus    `json:"cosigners"`
+	Mask        hexBytes          `json:"mask"` // 연결되지 않는 cosigner 비트, POST /v1/sign의 mask로 쓸 수 있음
+}
+
+type applyResult struct {
+	Fingerprint hexBytes       `json:"fingerprint"`
+	Updated     []int          `json:"updated,omitempty"` // 새 그룹을 반영한 cosigner
+	Failed      map[int]string `json:"failed,omitempty"`  // 반영하지 못한 cosigner와 이유 (직접 교체 필요)
+}
+
+// admin: 관리 API 핸들러
+type admin struct {
+	token   [sha256.Size]byte // 토큰의 SHA-256 (길이와 무관하게 상수 시간 비교)
+	signer  *signer
+	current func() *members
+	install func(*group.File) error
+	timeout time.Duration // 상태 확인, 배포 요청의 제한 시간
+	logger  *slog.Logger
+	rand    io.Reader // 제안 ID 생성
+
+	mu        sync.Mutex
+	proposals map[string]*proposal
+}
+
+func newAdmin(token []byte, s *signer, current func() *members, install func(*group.File) error,
+	timeout time.Duration, logger *slog.Logger) *admin {
+
+	return &admin{
+		token:     sha256.Sum256(token),
+		signer:    s,
+		current:   current,
+		install:   install,
+		timeout:   timeout,
+		logger:    logger,
+		rand:      rand.Reader,
+		proposals: make(map[string]*proposal),
+	}
+}
+
+// handler: 인증을 거치는 /admin/v1/ 핸들러
+func (a *admin) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /admin/v1/group", a.serveGroup)
+	mux.HandleFunc("POST /admin/v1/proposals", a.servePropose)
+	mux.HandleFunc("GET /admin/v1/proposals", a.serveProposals)
+	mux.HandleFunc("GET /admin/v1/proposals/{id}", a.serveProposal)
+	mux.HandleFunc("DELETE /admin/v1/proposals/{id}", a.serveDiscard)
+	mux.HandleFunc("POST /admin/v1/proposals/{id}/endorse", a.serveEndorse)
+	mux.HandleFunc("POST /admin/v1/proposals/{id}/apply", a.serveApply)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		sum := sha256.Sum256([]byte(token))
+		if !ok || subtle.ConstantTimeCompare(sum[:], a.token[:]) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="leaderd admin"`)
+			writeJSON(w, http.StatusUnauthorized, &errorResponse{Error: "missing or invalid admin token"})
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func (a *admin) serveGroup(w http.ResponseWriter, r *http.Request) {
+	m := a.current()
+	ctx, cancel := context.WithTimeout(r.Context(), a.timeout)
+	defer cancel()
+	errs := probe(ctx, m.cosigners)
+	st := &groupStatus{
+		Fingerprint: m.fingerprint,
+		Threshold:   m.need,
+		Leaders:     m.file.Leaders,
+		Mask:        make([]byte, (len(m.cosigners)+7)>>3),
+	}
+	for i, c := range m.file.Cosigners {
+		ms := memberStatus{Index: i, PublicKey: c.PublicKey, Address: c.Address, Reachable: errs[i] == nil}
+		if errs[i] != nil {
+			ms.Error = errs[i].Error()
+			st.Mask[i>>3] |= 1 << uint(i&7)
+		}
+		st.Cosigners = append(st.Cosigners, ms)
+	}
+	writeJSON(w, http.StatusOK, st)
+}
+
+func (a *admin) servePropose(w http.ResponseWriter, r *http.Request) {
+	var req proposalRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, &errorResponse{Error: "invalid request body: " + err.Error()})
+		return
+	}
+	p, err := propose(a.current().file, &req)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, &errorResponse{Error: err.Error()})
+		return
+	}
+	id := make([]byte, 8)
+	if _, err := io.ReadFull(a.rand, id); err != nil {
+		writeJSON(w, http.StatusInternalServerError, &errorResponse{Error: "generating proposal ID: " + err.Error()})
+		return
+	}
+	p.ID, p.Created = hex.EncodeToString(id), time.Now().UTC()
+	a.mu.Lock()
+	a.proposals[p.ID] = p
+	a.mu.Unlock()
+	a.logger.Info("group change proposed", "proposal", p.ID, "added", p.Added, "removed", p.Removed,
+		"threshold", p.Group.Threshold)
+	writeJSON(w, http.StatusCreated, p)
+}
+
+// propose: cur에 req를 적용한 새 그룹 파일 제안
+func propose(cur *group.File, req *proposalRequest) (*proposal, error) {
+	if len(req.Add) == 0 && len(req.Remove) == 0 && req.Threshold == nil {
+		return nil, errors.New("nothing to change")
+	}
+	p := &proposal{From: cur.Fingerprint()}
+	next := &group.File{Leaders: cur.Leaders, Threshold: cur.Threshold}
+	for i, m := range cur.Cosigners {
+		if slices.ContainsFunc(req.Remove, func(k group.PublicKey) bool { return bytes.Equal(k, m.PublicKey) }) {
+			p.Removed = append(p.Removed, i)
+			continue
+		}
+		next.Cosigners = append(next.Cosigners, m)
+	}
+	if len(p.Removed) != len(req.Remove) {
+		return nil, errors.New("remove: not every key is a cosigner of the current group")
+	}
+	for _, m := range req.Add {
+		if m.Address == "" {
+			return nil, fmt.Errorf("add: cosigner %x has no address", []byte(m.PublicKey))
+		}
+		p.Added = append(p.Added, len(next.Cosigners))
+		next.Cosigners = append(next.Cosigners, m)
+	}
+	if req.Threshold != nil {
+		next.Threshold = *req.Threshold
+	} else if next.Threshold > len(next.Cosigners) {
+		next.Threshold = len(next.Cosigners)
+	}
+	if err := next.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid new group: %v", err)
+	}
+	p.Group, p.Fingerprint = next, next.Fingerprint()
+	return p, nil
+}
+
+func (a *admin) serveProposals(w http.ResponseWriter, r *http.Request) {
+	a.mu.Lock()
+	list := make([]*proposal, 0, len(a.proposals))
+	for _, p := range a.proposals {
+		list = append(list, p)
+	}
+	a.mu.Unlock()
+	slices.SortFunc(list, func(p, q *proposal) int { return p.Created.Compare(q.Created) })
+	writeJSON(w, http.StatusOK, list)
+}
+
+// lookup: {id}의 제안, 없으면 404 응답 후 nil
+func (a *admin) lookup(w http.ResponseWriter, r *http.Request) *proposal {
+	a.mu.Lock()
+	p := a.proposals[r.PathValue("id")]
+	a.mu.Unlock()
+	if p == nil {
+		writeJSON(w, http.StatusNotFound, &errorResponse{Error: "no such proposal"})
+	}
+	return p
+}
+
+// checkCurrent: 제안이 실행 중인 그룹에 대한 것인지, 아니면 409 응답 후 nil
+func (a *admin) checkCurrent(w http.ResponseWriter, p *proposal) *members {
+	m := a.current()
+	if !bytes.Equal(p.From, m.fingerprint) {
+		writeJSON(w, http.StatusConflict, &errorResponse{Error: "proposal is for a previous group, propose again"})
+		return nil
+	}
+	return m
+}
+
+func (a *admin) serveProposal(w http.ResponseWriter, r *http.Request) {
+	if p := a.lookup(w, r); p != nil {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		writeJSON(w, http.StatusOK, p)
+	}
+}
+
+func (a *admin) serveDiscard(w http.ResponseWriter, r *http.Request) {
+	if p := a.lookup(w, r); p != nil {
+		a.mu.Lock()
+		delete(a.proposals, p.ID)
+		a.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (a *admin) serveEndorse(w http.ResponseWriter, r *http.Request) {
+	p := a.lookup(w, r)
+	if p == nil {
+		return
+	}
+	m := a.checkCurrent(w, p)
+	if m == nil {
+		return
+	}
+	message, err := group.RotationMessage(p.From, p.Group)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, &errorResponse{Error: err.Error()})
+		return
+	}
+	res, err := a.signer.sign(r.Context(), &signRequest{
+		Message:  message,
+		Metadata: map[string]string{protocol.MetadataContentType: group.RotationContentType},
+	})
+	if err != nil {
+		resp := &errorResponse{Error: err.Error()}
+		var se *protocol.SignError
+		if errors.As(err, &se) {
+			resp.Attempts, resp.Blamed = se.Attempts, se.Blamed
+		}
+		writeJSON(w, signErrorStatus(err), resp)
+		return
+	}
+	// leader의 -threshold가 그룹 파일보다 낮을 수 있으므로 cosigner가 할 검증을 미리 해 본다.
+	if err := m.file.VerifyRotation(p.Group, res.Signature, res.Binding); err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, &errorResponse{Error: err.Error(), Attempts: res.Attempts})
+		return
+	}
+	e := &endorsement{Signature: res.Signature, Absent: res.Absent}
+	if res.Binding != nil {
+		e.Binding = &binding{res.Binding.SessionID, string(res.Binding.Leader)}
+	}
+	a.mu.Lock()
+	p.Endorsement = e
+	a.mu.Unlock()
+	a.logger.Info("group change endorsed", "proposal", p.ID, "absent", res.Absent)
+	a.serveProposal(w, r)
+}
+
+func (a *admin) serveApply(w http.ResponseWriter, r *http.Request) {
+	p := a.lookup(w, r)
+	if p == nil {
+		return
+	}
+	m := a.checkCurrent(w, p)
+	if m == nil {
+		return
+	}
+	a.mu.Lock()
+	e := p.Endorsement
+	a.mu.Unlock()
+	if e == nil {
+		writeJSON(w, http.StatusConflict, &errorResponse{Error: "proposal is not endorsed yet"})
+		return
+	}
+	u := &cosirpc.GroupUpdate{Signature: e.Signature}
+	if e.Binding != nil {
+		u.Binding = &cosi.Binding{SessionID: e.Binding.SessionID, Leader: []byte(e.Binding.Leader)}
+	}
+	if err := m.file.VerifyRotation(p.Group, u.Signature, u.Binding); err != nil {
+		writeJSON(w, http.StatusConflict, &errorResponse{Error: err.Error()})
+		return
+	}
+	data, err := p.Group.Marshal()
+	if err == nil {
+		u.Group = data
+		err = a.install(p.Group)
+	}
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, &errorResponse{Error: "installing the new group: " + err.Error()})
+		return
+	}
+	// 다른 제안은 모두 이전 그룹에 대한 것이 되었다.
+	a.mu.Lock()
+	clear(a.proposals)
+	a.mu.Unlock()
+	a.logger.Info("group change applied", "proposal", p.ID, "fingerprint", hex.EncodeToString(p.Fingerprint))
+
+	ctx, cancel := context.WithTimeout(r.Context(), a.timeout)
+	defer cancel()
+	writeJSON(w, http.StatusOK, distribute(ctx, a.current(), u))
+}
+
+// distribute: m의 cosigner 모두에 병렬로 그룹 변경을 보냄
+func distribute(ctx context.Context, m *members, u *cosirpc.GroupUpdate) *applyResult {
+	type updater interface {
+		UpdateGroup(context.Context, *cosirpc.GroupUpdate) ([]byte, error)
+	}
+	res := &applyResult{Fingerprint: m.fingerprint, Failed: make(map[int]string)}
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+	for i, c := range m.cosigners {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var err error
+			if up, ok := c.(updater); !ok {
+				err = errors.New("cosigner does not take group updates")
+			} else if fingerprint, uerr := up.UpdateGroup(ctx, u); uerr != nil {
+				err = uerr
+			} else if !bytes.Equal(fingerprint, m.fingerprint) {
+				err = fmt.Errorf("cosigner runs group %x", fingerprint)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				res.Failed[i] = err.Error()
+			} else {
+				res.Updated = append(res.Updated, i)
+			}
+		}()
+	}
+	wg.Wait()
+	slices.Sort(res.Updated)
+	return res
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"test-server/golang-x-crypto/ed25519/cosi/protocol"
+	"test-server/group"
+)
+
+func adminRequest(t *testing.T, ts *httptest.Server, method, path, token, body string, v any) int {
+	req, err := http.NewRequest(method, ts.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if v != nil && resp.StatusCode != http.StatusNoContent {
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestAdmin(t *testing.T) {
+	nw, s, _ := newTestServer(t, 0)
+	nw.Leader.Threshold = 2
+	f := &group.File{Threshold: 2}
+	for _, pub := range nw.PublicKeys {
+		f.Cosigners = append(f.Cosigners, group.Member{PublicKey: group.PublicKey(pub), Address: "localhost:7000"})
+	}
+	cosigners := make([]protocol.Cosigner, len(nw.PublicKeys))
+	for i := range cosigners {
+		cosigners[i] = nw.Link(i)
+	}
+	current := &members{file: f, fingerprint: f.Fingerprint(), cosigners: cosigners, need: 2}
+	var installed *group.File
+	install := func(next *group.File) error {
+		installed = next
+		current = &members{file: next, fingerprint: next.Fingerprint(), cosigners: cosigners[:2], need: 2}
+		return nil
+	}
+	ad := newAdmin([]byte("secret"), s, func() *members { return current }, install, time.Second,
+		slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ts := httptest.NewServer(ad.handler())
+	defer ts.Close()
+
+	if status := adminRequest(t, ts, "GET", "/admin/v1/group", "", "", nil); status != http.StatusUnauthorized {
+		t.Errorf("no token: status %d, want 401", status)
+	}
+	if status := adminRequest(t, ts, "GET", "/admin/v1/group", "wrong", "", nil); status != http.StatusUnauthorized {
+		t.Errorf("wrong token: status %d, want 401", status)
+	}
+	var st groupStatus
+	if status := adminRequest(t, ts, "GET", "/admin/v1/group", "secret", "", &st); status != http.StatusOK {
+		t.Fatalf("group: status %d", status)
+	}
+	if len(st.Cosigners) != 3 || !st.Cosigners[0].Reachable || hex.EncodeToString(st.Mask) != "00" {
+		t.Errorf("group status: %+v", st)
+	}
+
+	var p proposal
+	body := `{"remove": ["` + hex.EncodeToString(nw.PublicKeys[2]) + `"]}`
+	if status := adminRequest(t, ts, "POST", "/admin/v1/proposals", "secret", body, &p); status != http.StatusCreated {
+		t.Fatalf("propose: status %d", status)
+	}
+	if len(p.Group.Cosigners) != 2 || len(p.Removed) != 1 || p.Removed[0] != 2 {
+		t.Errorf("proposal: %+v", p)
+	}
+	if status := adminRequest(t, ts, "POST", "/admin/v1/proposals", "secret", `{"remove": ["`+hex.EncodeToString(make([]byte, 32))+`"]}`, nil); status != http.StatusBadRequest {
+		t.Errorf("removing a non-member: status %d, want 400", status)
+	}
+	ad.rand = iotest.ErrReader(errors.New("entropy source failed"))
+	var e errorResponse
+	if status := adminRequest(t, ts, "POST", "/admin/v1/proposals", "secret", body, &e); status != http.StatusInternalServerError || e.Error == "" {
+		t.Errorf("propose without randomness: status %d, error %q, want 500", status, e.Error)
+	}
+	ad.rand = rand.Reader
+	if status := adminRequest(t, ts, "POST", "/admin/v1/proposals/"+p.ID+"/apply", "secret", "", nil); status != http.StatusConflict {
+		t.Errorf("apply before endorse: status %d, want 409", status)
+	}
+
+	if status := adminRequest(t, ts, "POST", "/admin/v1/proposals/"+p.ID+"/endorse", "secret", "", &p); status != http.StatusOK {
+		t.Fatalf("endorse: status %d", status)
+	}
+	if err := f.VerifyRotation(p.Group, p.Endorsement.Signature, nil); err != nil {
+		t.Errorf("endorsement: %v", err)
+	}
+
+	var res applyResult
+	if status := adminRequest(t, ts, "POST", "/admin/v1/proposals/"+p.ID+"/apply", "secret", "", &res); status != http.StatusOK {
+		t.Fatalf("apply: status %d", status)
+	}
+	if installed == nil || len(installed.Cosigners) != 2 {
+		t.Fatalf("installed group: %+v", installed)
+	}
+	// 메모리 cosigner는 UpdateGroup을 지원하지 않으므로 직접 교체 대상으로 보고
+	if len(res.Failed) != 2 || hex.EncodeToString(res.Fingerprint) != hex.EncodeToString(installed.Fingerprint()) {
+		t.Errorf("apply result: %+v", res)
+	}
+	var list []*proposal
+	adminRequest(t, ts, "GET", "/admin/v1/proposals", "secret", "", &list)
+	if len(list) != 0 {
+		t.Errorf("%d proposals left after apply", len(list))
+	}
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"test-server/golang-x-crypto/ed25519/cosi/protocol"
+	"test-server/health"
+)
+
+// api.go에는 서명 요청을 받는 JSON HTTP API만 구현
+// 바이트 값(메시지, 마스크, 서명)은 hex 문자열로 주고받는다.
+
+const maxRequestSize = 4 << 20 // 요청 본문 최대 크기(4MiB)
+
+// hexBytes: JSON에서 hex 문자열로 인코딩되는 바이트열
+type hexBytes []byte
+
+func (b hexBytes) MarshalText() ([]byte, error) {
+	return []byte(hex.EncodeToString(b)), nil
+}
+
+func (b *hexBytes) UnmarshalText(text []byte) error {
+	v, err := hex.DecodeString(string(text))
+	if err != nil {
+		return fmt.Errorf("invalid hex string: %v", err)
+	}
+	*b = v
+	return nil
+}
+
+type signRequest struct {
+	Message  hexBytes          `json:"message"`
+	Metadata map[string]string `json:"metadata,omitempty"` // cosigner의 검증 정책에 전달
+	Mask     hexBytes          `json:"mask,omitempty"`     // 제외할 cosigner 비트마스크
+}
+
+type binding struct {
+	SessionID hexBytes `json:"sessionId"`
+	Leader    string   `json:"leader"`
+}
+
+type signResponse struct {
+	Signature hexBytes `json:"signature"` // R || s || mask
+	Attempts  int      `json:"attempts"`  // 모든 라운드의 시도 횟수 합
+	Absent    []int    `json:"absent,omitempty"`
+	Blamed    []int    `json:"blamed,omitempty"`
+	Binding   *binding `json:"binding,omitempty"` // bound 라운드면 cosi.VerifyBound에 필요
+}
+
+type errorResponse struct {
+	Error    string `json:"error"`
+	Attempts int    `json:"attempts,omitempty"`
+	Blamed   []int  `json:"blamed,omitempty"`
+}
+
+// signer: protocol.Leader를 감싸 라운드 단위 재시도를 추가
+// Leader는 한 라운드 안에서 응답하지 않거나 잘못된 cosigner를 빼고 재시작하지만,
+// commit 단계에서 cosigner가 부족하면(일시적 장애, backpressure) 바로 실패하므로
+// 그 경우 backoff 후 라운드 전체를 다시 시도한다.
+type signer struct {
+	leader  atomic.Pointer[protocol.Leader] // 그룹 재적재 시 교체 (reload.go)
+	timeout time.Duration                   // 요청 하나의 전체 제한 시간
+	retries int                             // ErrInsufficientCosigners 시 추가 라운드 수
+	backoff time.Duration                   // 첫 재시도 전 대기, 재시도마다 두 배
+	logger  *slog.Logger
+
+	lastRound *health.LastRound // 성공한 라운드 기록, nil이면 기록 안 함
+}
+
+func (s *signer) sign(ctx context.Context, req *signRequest) (*protocol.Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	if req.Metadata != nil {
+		ctx = protocol.WithMetadata(ctx, protocol.Metadata(req.Metadata))
+	}
+	attempts := 0
+	backoff := s.backoff
+	for round := 0; ; round++ {
+		res, err := s.leader.Load().SignWithMask(ctx, req.Message, req.Mask)
+		if err == nil {
+			res.Attempts += attempts
+			if s.lastRound != nil {
+				s.lastRound.Record()
+			}
+			return res, nil
+		}
+		var se *protocol.SignError
+		if errors.As(err, &se) {
+			attempts += se.Attempts
+			se.Attempts = attempts
+		}
+		if round == s.retries || !errors.Is(err, protocol.ErrInsufficientCosigners) {
+			return nil, err
+		}
+		s.logger.Warn("signing round failed, retrying", "round", round+1, "backoff", backoff, "err", err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return nil, &protocol.SignError{Attempts: attempts, Err: ctx.Err()}
+		}
+		backoff *= 2
+	}
+}
+
+// newAPIHandler: POST /v1/sign
+func newAPIHandler(s *signer) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/sign", s.serveSign)
+	return mux
+}
+
+func (s *signer) serveSign(w http.ResponseWriter, r *http.Request) {
+	var req signRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, &errorResponse{Error: "invalid request body: " + err.Error()})
+		return
+	}
+
+	res, err := s.sign(r.Context(), &req)
+	if err != nil {
+		resp := &errorResponse{Error: err.Error()}
+		var se *protocol.SignError
+		if errors.As(err, &se) {
+			resp.Attempts, resp.Blamed = se.Attempts, se.Blamed
+		}
+		writeJSON(w, signErrorStatus(err), resp)
+		return
+	}
+	resp := &signResponse{
+		Signature: res.Signature,
+		Attempts:  res.Attempts,
+		Absent:    res.Absent,
+		Blamed:    res.Blamed,
+	}
+	if res.Binding != nil {
+		resp.Binding = &binding{res.Binding.SessionID, string(res.Binding.Leader)}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// 에러 → HTTP status
+// cosigner 부족은 503, 시간 초과는 504, 그 밖의 라운드 실패는 500
+func signErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, protocol.ErrInsufficientCosigners):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, context.Canceled):
+		return 499 // client closed request
+	}
+	return http.StatusInternalServerError
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"test-server/golang-x-crypto/ed25519/cosi"
+	"test-server/golang-x-crypto/ed25519/cosi/cositest"
+	"test-server/health"
+)
+
+func newTestServer(t *testing.T, retries int) (*cositest.Network, *signer, *httptest.Server) {
+	nw, err := cositest.NewNetwork(3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(nw.Close)
+	nw.Leader.PhaseTimeout = 100 * time.Millisecond
+	s := &signer{
+		timeout: 5 * time.Second,
+		retries: retries,
+		backoff: 50 * time.Millisecond,
+		logger:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+
+		lastRound: health.NewLastRound(),
+	}
+	s.leader.Store(nw.Leader)
+	ts := httptest.NewServer(newAPIHandler(s))
+	t.Cleanup(ts.Close)
+	return nw, s, ts
+}
+
+func postSign(t *testing.T, ts *httptest.Server, body string) (int, map[string]any) {
+	resp, err := http.Post(ts.URL+"/v1/sign", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var v map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&v); err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, v
+}
+
+func TestSign(t *testing.T) {
+	nw, s, ts := newTestServer(t, 0)
+	message := []byte("test message")
+	status, v := postSign(t, ts, `{"message": "`+hex.EncodeToString(message)+`", "metadata": {"content-type": "text/plain"}}`)
+	if status != http.StatusOK {
+		t.Fatalf("status %d: %v", status, v)
+	}
+	sig, err := hex.DecodeString(v["signature"].(string))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cosi.Verify(nw.PublicKeys, nil, message, sig) {
+		t.Errorf("collective signature rejected")
+	}
+	if _, ok := s.lastRound.Age(); !ok {
+		t.Errorf("successful round not recorded")
+	}
+
+	if status, _ := postSign(t, ts, `{"message": "zz"}`); status != http.StatusBadRequest {
+		t.Errorf("bad hex: status %d, want 400", status)
+	}
+}
+
+func TestSignRetry(t *testing.T) {
+	nw, _, ts := newTestServer(t, 0)
+	nw.SetOnline(1, false)
+	status, v := postSign(t, ts, `{"message": "00"}`)
+	if status != http.StatusServiceUnavailable {
+		t.Fatalf("offline cosigner: status %d, want 503: %v", status, v)
+	}
+
+	// 재시도 대기 중에 cosigner가 돌아오면 다음 라운드에서 성공
+	nw, _, ts = newTestServer(t, 2)
+	nw.SetOnline(1, false)
+	time.AfterFunc(20*time.Millisecond, func() { nw.SetOnline(1, true) })
+	status, v = postSign(t, ts, `{"message": "00"}`)
+	if status != http.StatusOK {
+		t.Fatalf("after retry: status %d: %v", status, v)
+	}
+	if v["attempts"].(float64) < 2 {
+		t.Errorf("attempts = %v, want the failed round counted", v["attempts"])
+	}
+}
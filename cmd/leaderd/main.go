@@ -0,0 +1,273 @@
+// leaderd: cosi 서명 라운드를 주관하는 leader 데몬
+//
+// 그룹 파일(group 패키지)의 cosigner에 cosirpc로 연결하고,
+// HTTP API(POST /v1/sign)로 받은 메시지마다 서명 라운드를 진행해
+// 완성된 collective signature를 돌려준다.
+// 같은 주소에서 /healthz, /readyz(health 패키지)로 상태를 보고한다.
+//
+//	leaderd -key leader.pem -group group.json -listen :8090
+//
+// cosigner는 그룹 파일의 leaders에 이 데몬의 공개키가 있어야 연결을 받아들인다.
+// SIGHUP을 받거나(-reload-interval을 주면) 그룹 파일이 바뀌면 그룹을 다시 읽는다(reload.go).
+// -admin-listen을 주면 그 주소에서 cosigner 추가·삭제를 위한 관리 API를 제공한다(admin.go).
+// SIGTERM을 받으면 새 요청을 받지 않고 진행 중인 라운드를 최대 -drain-timeout 기다린 뒤,
+// 그때까지 끝나지 않은 라운드는 취소하고 종료한다.
+// 모든 플래그는 설정 파일(-config)과 LEADERD_ 환경 변수로도 줄 수 있다(config 패키지).
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"test-server/config"
+	"test-server/cosirpc"
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/golang-x-crypto/ed25519/cosi/protocol"
+	"test-server/group"
+	"test-server/health"
+)
+
+type options struct {
+	keyPath, groupPath string
+	listen             string
+	transport          string
+	fingerprint        config.Fingerprint
+
+	threshold    int
+	phaseTimeout time.Duration
+	maxAttempts  int
+	bindSessions bool
+
+	requestTimeout time.Duration
+	retries        int
+	retryBackoff   time.Duration
+
+	maxRoundAge  time.Duration
+	drainTimeout time.Duration
+
+	reloadInterval time.Duration
+
+	adminListen    string
+	adminTokenFile string
+
+	logLevel slog.Level
+}
+
+func main() {
+	var o options
+	flag.StringVar(&o.keyPath, "key", "", "leader 개인키 파일 (PKCS #8 PEM 또는 OpenSSH)")
+	flag.StringVar(&o.groupPath, "group", "", "그룹 파일 (JSON)")
+	flag.StringVar(&o.listen, "listen", ":8090", "HTTP API listen 주소")
+	flag.StringVar(&o.transport, "transport", cosirpc.TransportMTLS,
+		"transport: "+strings.Join(cosirpc.Transports, ", "))
+	flag.Var(&o.fingerprint, "group-fingerprint", "그룹 fingerprint 고정 (hex, cosi fingerprint로 확인)")
+	flag.IntVar(&o.threshold, "threshold", -1, "서명에 필요한 최소 cosigner 수 (기본값: 그룹 파일의 threshold)")
+	flag.DurationVar(&o.phaseTimeout, "phase-timeout", protocol.DefaultPhaseTimeout, "commit/response 단계 제한 시간")
+	flag.IntVar(&o.maxAttempts, "max-attempts", protocol.DefaultMaxAttempts, "라운드 하나의 최대 시도 횟수")
+	flag.BoolVar(&o.bindSessions, "bind-sessions", true, "라운드를 세션과 leader에 묶음 (cosi.Binding)")
+	flag.DurationVar(&o.requestTimeout, "request-timeout", time.Minute, "서명 요청 하나의 제한 시간")
+	flag.IntVar(&o.retries, "retries", 2, "cosigner 부족으로 실패한 라운드의 재시도 횟수")
+	flag.DurationVar(&o.retryBackoff, "retry-backoff", time.Second, "첫 재시도 전 대기 시간 (재시도마다 두 배)")
+	flag.DurationVar(&o.maxRoundAge, "max-round-age", 0, "마지막 성공 라운드가 이보다 오래되면 /readyz 실패 (0: 보고만 함)")
+	flag.DurationVar(&o.drainTimeout, "drain-timeout", 30*time.Second, "종료 시 진행 중인 라운드를 기다리는 최대 시간")
+	flag.DurationVar(&o.reloadInterval, "reload-interval", 0, "그룹 파일 변경 확인 주기 (0: SIGHUP으로만 다시 읽음)")
+	flag.StringVar(&o.adminListen, "admin-listen", "", "그룹 관리 API listen 주소 (기본값: 사용 안 함)")
+	flag.StringVar(&o.adminTokenFile, "admin-token-file", "", "관리 API bearer 토큰 파일 (-admin-listen에 필요)")
+	flag.TextVar(&o.logLevel, "log-level", slog.LevelInfo, "로그 레벨 (debug, info, warn, error)")
+	if err := config.Parse(flag.CommandLine, os.Args[1:], "LEADERD"); err != nil {
+		fmt.Fprintln(os.Stderr, "leaderd:", err)
+		os.Exit(2)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: o.logLevel}))
+	if err := run(&o, logger); err != nil {
+		logger.Error("leaderd failed", "err", err)
+		os.Exit(1)
+	}
+}
+
+func run(o *options, logger *slog.Logger) error {
+	if err := o.validate(); err != nil {
+		return err
+	}
+	priv, err := group.LoadPrivateKey(o.keyPath)
+	if err != nil {
+		return err
+	}
+	s := &signer{
+		timeout:   o.requestTimeout,
+		retries:   o.retries,
+		backoff:   o.retryBackoff,
+		logger:    logger,
+		lastRound: health.NewLastRound(),
+	}
+	groups := &groupLoader{o: o, priv: priv, signer: s, logger: logger}
+	if err := groups.load(); err != nil {
+		return err
+	}
+	defer groups.close()
+	checker := &health.Checker{Checks: []health.Check{
+		health.Key(priv),
+		health.KeyFile(o.keyPath, priv.Public().(ed25519.PublicKey)),
+		health.Group(o.groupPath, groups.fingerprint),
+		peersCheck(groups.current.Load),
+		s.lastRound.Check(o.maxRoundAge),
+	}}
+	mux := newAPIHandler(s)
+	checker.Register(mux)
+	// drain-timeout이 지나면 baseCtx를 취소해 남은 라운드를 멈춘다.
+	baseCtx, cancelRounds := context.WithCancel(context.Background())
+	defer cancelRounds()
+	srv := &http.Server{Handler: mux, BaseContext: func(net.Listener) context.Context { return baseCtx }}
+	lis, err := net.Listen("tcp", o.listen)
+	if err != nil {
+		return err
+	}
+	var as *http.Server // 관리 API (admin.go)
+	if o.adminListen != "" {
+		token, err := os.ReadFile(o.adminTokenFile)
+		if err == nil && len(bytes.TrimSpace(token)) == 0 {
+			err = fmt.Errorf("admin token file %s is empty", o.adminTokenFile)
+		}
+		if err != nil {
+			lis.Close()
+			return err
+		}
+		ad := newAdmin(bytes.TrimSpace(token), s, groups.current.Load, groups.install, o.phaseTimeout, logger)
+		as = &http.Server{Handler: ad.handler(), BaseContext: srv.BaseContext}
+		alis, err := net.Listen("tcp", o.adminListen)
+		if err != nil {
+			lis.Close()
+			return err
+		}
+		go func() {
+			if err := as.Serve(alis); err != http.ErrServerClosed {
+				logger.Error("admin server failed", "err", err)
+			}
+		}()
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	reloader := &config.Reloader{Path: o.groupPath, Interval: o.reloadInterval, Signals: hup,
+		Reload: groups.load, Logger: logger}
+	reloader.Start(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		<-ctx.Done()
+		stop() // 두 번째 신호는 바로 종료
+		logger.Info("draining", "timeout", o.drainTimeout)
+		ctx, cancel := context.WithTimeout(context.Background(), o.drainTimeout)
+		defer cancel()
+		err := srv.Shutdown(ctx)
+		if as != nil {
+			err = errors.Join(err, as.Shutdown(ctx))
+		}
+		if err != nil {
+			logger.Warn("cancelling rounds still in flight", "err", err)
+			cancelRounds()
+			srv.Close()
+			if as != nil {
+				as.Close()
+			}
+		}
+	}()
+
+	m := groups.current.Load()
+	logger.Info("leaderd started", "addr", lis.Addr().String(), "id", s.leader.Load().ID,
+		"cosigners", len(m.cosigners), "threshold", m.need, "transport", o.transport)
+	if err := srv.Serve(lis); err != http.ErrServerClosed {
+		return err
+	}
+	<-done
+	logger.Info("stopped")
+	return nil
+}
+
+// peersCheck: 실행 중인 그룹의 cosigner 중 필요한 수 이상과 연결(transport 핸드셰이크 포함)되는지
+func peersCheck(current func() *members) health.Check {
+	return health.Check{Name: "peers", Run: func(ctx context.Context) (string, error) {
+		m := current()
+		var down []string
+		for i, err := range probe(ctx, m.cosigners) {
+			if err != nil {
+				down = append(down, fmt.Sprintf("%d (%v)", i, err))
+			}
+		}
+		up := len(m.cosigners) - len(down)
+		detail := fmt.Sprintf("%d of %d cosigners reachable", up, len(m.cosigners))
+		if up < m.need {
+			return detail, fmt.Errorf("%s, need %d; unreachable: %s", detail, m.need, strings.Join(down, ", "))
+		}
+		return detail, nil
+	}}
+}
+
+// probe: cosigner마다 병렬로 연결을 시도한 결과, 연결되면(원격이 아니면) nil
+func probe(ctx context.Context, cosigners []protocol.Cosigner) []error {
+	errs := make([]error, len(cosigners))
+	var wg sync.WaitGroup
+	for i, c := range cosigners {
+		r, ok := c.(interface{ WaitReady(context.Context) error })
+		if !ok {
+			continue // 원격이 아닌 cosigner
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = r.WaitReady(ctx)
+		}()
+	}
+	wg.Wait()
+	return errs
+}
+
+// validate: 시작 전 설정 검사, 문제를 모두 모아 보고
+func (o *options) validate() error {
+	var p config.Problems
+	p.Check("key", config.KeyFile(o.keyPath))
+	p.Check("group", config.File(o.groupPath))
+	p.Check("listen", config.Addr(o.listen))
+	if !slices.Contains(cosirpc.Transports, o.transport) {
+		p.Check("transport", fmt.Errorf("unknown transport %q (want one of %s)", o.transport, strings.Join(cosirpc.Transports, ", ")))
+	}
+	p.Check("phase-timeout", config.Positive(o.phaseTimeout))
+	p.Check("max-attempts", config.NonNegative(o.maxAttempts))
+	p.Check("request-timeout", config.Positive(o.requestTimeout))
+	p.Check("drain-timeout", config.Positive(o.drainTimeout))
+	if o.reloadInterval < 0 {
+		p.Check("reload-interval", fmt.Errorf("must not be negative, got %v", o.reloadInterval))
+	}
+	if o.adminListen != "" {
+		p.Check("admin-listen", config.Addr(o.adminListen))
+		if err := config.Required(o.adminTokenFile); err != nil {
+			p.Check("admin-token-file", err)
+		} else {
+			p.Check("admin-token-file", config.KeyFile(o.adminTokenFile))
+		}
+	}
+	p.Check("retries", config.NonNegative(o.retries))
+	if o.maxRoundAge < 0 {
+		p.Check("max-round-age", fmt.Errorf("must not be negative, got %v", o.maxRoundAge))
+	}
+	if o.retryBackoff < 0 {
+		p.Check("retry-backoff", fmt.Errorf("must not be negative, got %v", o.retryBackoff))
+	}
+	return p.Err()
+}
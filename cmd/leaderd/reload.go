@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"test-server/config"
+	"test-server/cosirpc"
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/golang-x-crypto/ed25519/cosi/protocol"
+	"test-server/group"
+)
+
+// reload.go: 그룹 파일 재적재 (SIGHUP, -reload-interval)
+//
+// 그룹 파일을 다시 읽을 때마다 새 cosigner 연결과 protocol.Leader를 만들어 signer의 Leader를
+// 통째로 교체한다. 진행 중인 요청은 시작할 때의 Leader로 끝나고, 이전 연결은 그 요청들이
+// 끝날 시간(-request-timeout)이 지난 뒤 닫는다.
+// 새 Leader는 commit 요청에 새 그룹의 fingerprint를 싣고 보내므로, 아직 이전 그룹으로
+// 실행 중인 cosigner는 라운드를 거부한다(protocol.ErrStaleGroup).
+
+// members: 그룹 파일 하나로 만든 cosigner 연결
+type members struct {
+	file        *group.File
+	fingerprint []byte
+	cosigners   []protocol.Cosigner
+	need        int // 서명에 필요한 cosigner 수
+	close       func()
+}
+
+// groupLoader: 그룹 파일을 읽어 signer의 Leader를 교체
+type groupLoader struct {
+	o      *options
+	priv   ed25519.PrivateKey
+	signer *signer
+	logger *slog.Logger
+
+	mu      sync.Mutex // load, install 직렬화
+	current atomic.Pointer[members]
+}
+
+// load: 그룹 파일을 읽어 검사하고 연결한 뒤 signer의 Leader를 교체
+// 실패하면 실행 중인 그룹을 그대로 둔다.
+func (l *groupLoader) load() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.loadLocked()
+}
+
+func (l *groupLoader) loadLocked() error {
+	g, err := group.Load(l.o.groupPath)
+	if err != nil {
+		return err
+	}
+	if err := l.o.fingerprint.Check(g); err != nil {
+		return fmt.Errorf("%s: %w", l.o.groupPath, err)
+	}
+	threshold := g.Threshold
+	if l.o.threshold >= 0 {
+		threshold = l.o.threshold
+	}
+	if threshold > len(g.Cosigners) {
+		return fmt.Errorf("threshold %d exceeds the %d cosigners", threshold, len(g.Cosigners))
+	}
+
+	cosigners, closeAll, err := cosirpc.DialGroup(l.o.transport, l.priv, g)
+	if err != nil {
+		return err
+	}
+	leader, err := protocol.NewLeader(g.PublicKeys(), cosigners, nil)
+	if err != nil {
+		closeAll()
+		return err
+	}
+	leader.Threshold = threshold
+	leader.PhaseTimeout = l.o.phaseTimeout
+	leader.MaxAttempts = l.o.maxAttempts
+	leader.BindSessions = l.o.bindSessions
+	// 인증된 transport에서 cosigner가 보는 leader ID(cosirpc.Server.PeerKey)와 같게
+	leader.ID = hex.EncodeToString(l.priv.Public().(ed25519.PublicKey))
+	leader.Logger = l.logger
+
+	m := &members{file: g, fingerprint: leader.Fingerprint(), cosigners: cosigners, need: threshold, close: closeAll}
+	if m.need == 0 {
+		m.need = len(cosigners)
+	}
+	l.signer.leader.Store(leader)
+	if old := l.current.Swap(m); old != nil {
+		time.AfterFunc(l.o.requestTimeout, old.close)
+		l.logger.Info("group changed", "fingerprint", hex.EncodeToString(m.fingerprint),
+			"cosigners", len(g.Cosigners), "threshold", threshold)
+	}
+	return nil
+}
+
+// install: -group 파일을 next로 교체하고 다시 읽음 (admin API의 apply)
+// -group-fingerprint에 맞지 않으면 파일을 건드리지 않는다.
+func (l *groupLoader) install(next *group.File) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.o.fingerprint.Check(next); err != nil {
+		return err
+	}
+	data, err := next.Marshal()
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(l.o.groupPath)
+	if err != nil {
+		return err
+	}
+	if err := config.WriteFile(l.o.groupPath, data, info.Mode().Perm()); err != nil {
+		return err
+	}
+	return l.loadLocked()
+}
+
+// fingerprint: 실행 중인 그룹의 fingerprint
+func (l *groupLoader) fingerprint() []byte {
+	return l.current.Load().fingerprint
+}
+
+// close: 실행 중인 그룹의 연결 종료
+func (l *groupLoader) close() {
+	if m := l.current.Load(); m != nil {
+		m.close()
+	}
+}
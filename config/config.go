@@ -0,0 +1,156 @@
+// Package config: 데몬 설정 계층화와 시작 시 검사
+//
+// 데몬의 설정 항목은 모두 flag.FlagSet의 플래그로 정의하고,
+// Parse가 같은 항목을 다음 순서로 덮어쓴다(뒤가 우선).
+//
+//  1. 플래그 기본값
+//  2. 설정 파일(-config 또는 <PREFIX>_CONFIG): 키가 플래그 이름인 JSON 객체
+//  3. 환경 변수: <PREFIX>_<플래그 이름을 대문자로, '-'는 '_'로>
+//  4. 명령행 플래그
+//
+// 예를 들어 cosignerd의 -max-message-size는 설정 파일에서 "max-message-size",
+// 환경 변수로는 COSIGNERD_MAX_MESSAGE_SIZE이다.
+//
+//	{
+//	  "key": "/etc/cosi/cosigner.pem",
+//	  "group": "/etc/cosi/group.json",
+//	  "group-fingerprint": "9f86d081...",
+//	  "session-timeout": "30s",
+//	  "content-types": ["text/plain", "application/json"]
+//	}
+//
+// 설정 파일의 값은 문자열, 숫자, bool 또는 그 배열이다.
+// 배열은 원소마다 플래그를 한 번씩 지정한 것과 같다(List 플래그에 모두 추가됨).
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ConfigFlag: 설정 파일 경로 플래그 이름
+const ConfigFlag = "config"
+
+// EnvName: prefix 데몬에서 플래그 name에 해당하는 환경 변수 이름
+func EnvName(prefix, name string) string {
+	return prefix + "_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// Parse: fs에 -config 플래그를 추가하고 args를 파싱한 뒤,
+// 명령행에서 지정하지 않은 플래그를 환경 변수, 설정 파일 순으로 채움
+// 잘못된 값과 알 수 없는 설정 키는 출처(파일, 환경 변수)를 밝힌 에러로 모아 반환
+func Parse(fs *flag.FlagSet, args []string, envPrefix string) error {
+	path := fs.String(ConfigFlag, "", "설정 파일 (JSON, 키는 플래그 이름; 환경 변수 "+EnvName(envPrefix, ConfigFlag)+")")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	var errs []error
+	fs.VisitAll(func(f *flag.Flag) {
+		if set[f.Name] {
+			return
+		}
+		name := EnvName(envPrefix, f.Name)
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			return
+		}
+		if err := fs.Set(f.Name, v); err != nil {
+			errs = append(errs, fmt.Errorf("environment variable %s: invalid value %q: %v", name, v, err))
+		}
+		set[f.Name] = true
+	})
+	if *path != "" {
+		errs = append(errs, applyFile(fs, *path, set)...)
+	}
+	return errors.Join(errs...)
+}
+
+// applyFile: 설정 파일 값 중 아직 지정되지 않은 플래그만 적용
+func applyFile(fs *flag.FlagSet, path string, set map[string]bool) []error {
+	values, err := readFile(path)
+	if err != nil {
+		return []error{fmt.Errorf("config file %s: %w", path, err)}
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		if fs.Lookup(name) == nil || name == ConfigFlag {
+			errs = append(errs, fmt.Errorf("config file %s: unknown setting %q (see -h for the list)", path, name))
+			continue
+		}
+		if set[name] {
+			continue
+		}
+		for _, v := range values[name] {
+			if err := fs.Set(name, v); err != nil {
+				errs = append(errs, fmt.Errorf("config file %s: %q: invalid value %q: %v", path, name, v, err))
+				break
+			}
+		}
+	}
+	return errs
+}
+
+// readFile: 설정 파일을 플래그 이름 → 값 목록으로 읽음
+func readFile(path string) (map[string][]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]json.RawMessage
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&raw); err != nil {
+		return nil, err
+	}
+	values := make(map[string][]string, len(raw))
+	for name, msg := range raw {
+		var v any
+		d := json.NewDecoder(bytes.NewReader(msg))
+		d.UseNumber()
+		if err := d.Decode(&v); err != nil {
+			return nil, fmt.Errorf("%q: %v", name, err)
+		}
+		list, ok := v.([]any)
+		if !ok {
+			list = []any{v}
+		}
+		for _, elem := range list {
+			s, err := scalar(elem)
+			if err != nil {
+				return nil, fmt.Errorf("%q: %v", name, err)
+			}
+			values[name] = append(values[name], s)
+		}
+	}
+	return values, nil
+}
+
+func scalar(v any) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		if v {
+			return "true", nil
+		}
+		return "false", nil
+	}
+	return "", fmt.Errorf("want a string, number, bool or array of them, got %T", v)
+}
@@ -0,0 +1,192 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/group"
+)
+
+type testOptions struct {
+	listen  string
+	timeout time.Duration
+	size    int
+	verbose bool
+	types   List
+}
+
+func newFlagSet(o *testOptions) *flag.FlagSet {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.StringVar(&o.listen, "listen", ":7000", "")
+	fs.DurationVar(&o.timeout, "session-timeout", time.Minute, "")
+	fs.IntVar(&o.size, "max-message-size", 0, "")
+	fs.BoolVar(&o.verbose, "verbose", false, "")
+	fs.Var(&o.types, "content-types", "")
+	return fs
+}
+
+func writeFile(t *testing.T, name, content string, perm os.FileMode) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), perm); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParseLayering(t *testing.T) {
+	path := writeFile(t, "config.json", `{
+		"listen": ":7100",
+		"session-timeout": "30s",
+		"max-message-size": 1024,
+		"verbose": true,
+		"content-types": ["text/plain", "application/json"]
+	}`, 0o644)
+
+	// 파일 < 환경 변수 < 명령행
+	t.Setenv("TESTD_CONFIG", path)
+	t.Setenv("TESTD_SESSION_TIMEOUT", "10s")
+	t.Setenv("TESTD_MAX_MESSAGE_SIZE", "2048")
+	var o testOptions
+	if err := Parse(newFlagSet(&o), []string{"-max-message-size", "4096"}, "TESTD"); err != nil {
+		t.Fatal(err)
+	}
+	if o.listen != ":7100" || !o.verbose {
+		t.Errorf("file values not applied: listen=%q verbose=%v", o.listen, o.verbose)
+	}
+	if o.timeout != 10*time.Second {
+		t.Errorf("session-timeout = %v, want the environment's 10s", o.timeout)
+	}
+	if o.size != 4096 {
+		t.Errorf("max-message-size = %d, want the command line's 4096", o.size)
+	}
+	if o.types.String() != "text/plain,application/json" {
+		t.Errorf("content-types = %q", o.types.String())
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	path := writeFile(t, "config.json", `{"listen": ":7100", "max-mesage-size": 10, "session-timeout": "soon"}`, 0o644)
+	t.Setenv("TESTD_MAX_MESSAGE_SIZE", "big")
+	var o testOptions
+	err := Parse(newFlagSet(&o), []string{"-config", path}, "TESTD")
+	if err == nil {
+		t.Fatal("Parse succeeded")
+	}
+	for _, want := range []string{
+		"environment variable TESTD_MAX_MESSAGE_SIZE",
+		`unknown setting "max-mesage-size"`,
+		`"session-timeout"`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+
+	path = writeFile(t, "bad.json", `{"listen": {"host": "localhost"}}`, 0o644)
+	if err := Parse(newFlagSet(&o), []string{"-config", path}, "TESTD"); err == nil || !strings.Contains(err.Error(), "bad.json") {
+		t.Errorf("nested value: error = %v", err)
+	}
+}
+
+func TestChecks(t *testing.T) {
+	key := writeFile(t, "key.pem", "key", 0o600)
+	open := writeFile(t, "open.pem", "key", 0o644)
+
+	var p Problems
+	p.Check("key", KeyFile(key))
+	p.Check("listen", Addr("localhost:7000"))
+	p.Check("session-timeout", Positive(time.Second))
+	p.Check("state-file", Dir(filepath.Dir(key)))
+	if err := p.Err(); err != nil {
+		t.Fatalf("valid settings rejected: %v", err)
+	}
+
+	p.Check("key", KeyFile(open))
+	p.Check("group", File(filepath.Join(t.TempDir(), "missing.json")))
+	p.Check("listen", Addr("localhost"))
+	p.Check("retries", NonNegative(-1))
+	p.Check("state-file", Dir(key))
+	err := p.Err()
+	if len(p) != 5 || err == nil {
+		t.Fatalf("got %d problems: %v", len(p), err)
+	}
+	for _, want := range []string{"-key: ", "chmod 600", "-group: ", "-listen: ", "-retries: ", "-state-file: "} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("missing file error is not os.ErrNotExist")
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	f := &group.File{}
+	for i := 0; i < 3; i++ {
+		pub, _, _ := ed25519.GenerateKey(nil)
+		f.Cosigners = append(f.Cosigners, group.Member{PublicKey: group.PublicKey(pub)})
+	}
+	var pin Fingerprint
+	if err := pin.Check(f); err != nil {
+		t.Errorf("unpinned: %v", err)
+	}
+	if err := pin.Set("abcd"); err == nil {
+		t.Errorf("short fingerprint accepted")
+	}
+	if err := pin.Set(Fingerprint(f.Fingerprint()).String()); err != nil {
+		t.Fatal(err)
+	}
+	if err := pin.Check(f); err != nil {
+		t.Errorf("matching fingerprint: %v", err)
+	}
+	f.Cosigners[0], f.Cosigners[1] = f.Cosigners[1], f.Cosigners[0]
+	if err := pin.Check(f); !errors.Is(err, ErrFingerprintMismatch) {
+		t.Errorf("reordered group: error = %v, want ErrFingerprintMismatch", err)
+	}
+}
+
+func TestReloader(t *testing.T) {
+	path := writeFile(t, "group.json", "v1", 0o644)
+	reloads := make(chan struct{}, 10)
+	signals := make(chan os.Signal, 1)
+	r := &Reloader{Path: path, Interval: 10 * time.Millisecond, Signals: signals, Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+		Reload: func() error {
+			reloads <- struct{}{}
+			return errors.New("invalid group")
+		}}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r.Start(ctx)
+
+	wait := func(what string) {
+		t.Helper()
+		select {
+		case <-reloads:
+		case <-time.After(time.Second):
+			t.Fatalf("no reload after %s", what)
+		}
+	}
+	if err := os.WriteFile(path, []byte("v2"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	wait("file change")
+	signals <- syscall.SIGHUP
+	wait("SIGHUP")
+
+	// 실패한 내용으로 다시 시도하지 않음
+	select {
+	case <-reloads:
+		t.Errorf("reload repeated for an unchanged file")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
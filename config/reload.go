@@ -0,0 +1,94 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Reloader: 파일을 SIGHUP(Signals)이나 주기적 확인으로 다시 읽게 하는 루프
+//
+// Interval마다 Path의 내용을 확인해 바뀌었으면 Reload를 부르고,
+// Signals로 신호가 오면 바뀌지 않았어도 부른다.
+// Reload가 실패하면 데몬은 실행 중인 설정을 그대로 유지해야 한다.
+// 같은 내용으로 실패를 반복하지 않도록, 실패한 내용도 확인한 것으로 친다.
+type Reloader struct {
+	Path     string
+	Interval time.Duration // 0이면 파일을 확인하지 않고 신호로만 다시 읽음
+	Signals  <-chan os.Signal
+	Reload   func() error
+	Logger   *slog.Logger
+}
+
+// Start: 지금의 파일 내용을 기준으로 ctx가 끝날 때까지 백그라운드에서 실행
+// 파일을 처음 읽은 직후에 불러야 그 뒤의 변경을 놓치지 않는다.
+func (r *Reloader) Start(ctx context.Context) {
+	last := fileDigest(r.Path)
+	go r.run(ctx, last)
+}
+
+func (r *Reloader) run(ctx context.Context, last []byte) {
+	var tick <-chan time.Time
+	if r.Interval > 0 {
+		t := time.NewTicker(r.Interval)
+		defer t.Stop()
+		tick = t.C
+	}
+	for {
+		var cause string
+		select {
+		case <-ctx.Done():
+			return
+		case sig := <-r.Signals:
+			cause = sig.String()
+		case <-tick:
+			d := fileDigest(r.Path)
+			if d == nil || bytes.Equal(d, last) {
+				continue // 읽을 수 없는 동안(교체 중 등)은 다음 확인까지 기다림
+			}
+			cause = "file changed"
+		}
+		last = fileDigest(r.Path)
+		if err := r.Reload(); err != nil {
+			r.Logger.Error("reload failed, keeping the running configuration", "file", r.Path, "cause", cause, "err", err)
+			continue
+		}
+		r.Logger.Info("reloaded", "file", r.Path, "cause", cause)
+	}
+}
+
+// fileDigest: 파일 내용의 SHA-256, 읽을 수 없으면 nil
+func fileDigest(path string) []byte {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	sum := sha256.Sum256(data)
+	return sum[:]
+}
+
+// WriteFile: path를 data로 원자적으로 교체 (같은 디렉터리의 임시 파일에 쓰고 fsync 후 rename)
+// 파일을 감시하는 쪽(Reloader)이 쓰다 만 내용을 읽는 일이 없다.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err = tmp.Chmod(perm); err == nil {
+		if _, err = tmp.Write(data); err == nil {
+			err = tmp.Sync()
+		}
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
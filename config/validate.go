@@ -0,0 +1,178 @@
+package config
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"test-server/golang-x-crypto/ed25519/cosi"
+	"test-server/group"
+)
+
+// validate.go: 시작 시 설정 검사
+// 데몬은 Problems에 항목별 검사 결과를 모아 문제를 한 번에 모두 보고한다.
+
+// Problems: 설정 검사에서 찾은 문제 목록
+type Problems []error
+
+// Check: err가 있으면 설정 항목(플래그 이름) setting의 문제로 추가
+func (p *Problems) Check(setting string, err error) {
+	if err != nil {
+		*p = append(*p, fmt.Errorf("-%s: %w", setting, err))
+	}
+}
+
+// Err: 문제가 없으면 nil, 있으면 모두 나열한 에러
+func (p Problems) Err() error {
+	if len(p) == 0 {
+		return nil
+	}
+	return p
+}
+
+func (p Problems) Error() string {
+	msgs := make([]string, len(p))
+	for i, err := range p {
+		msgs[i] = err.Error()
+	}
+	return "invalid configuration: " + strings.Join(msgs, "; ")
+}
+
+func (p Problems) Unwrap() []error { return p }
+
+// Required: 값이 비어 있지 않은지
+func Required(value string) error {
+	if value == "" {
+		return errors.New("required")
+	}
+	return nil
+}
+
+// Addr: host:port 형식의 listen/dial 주소인지
+func Addr(addr string) error {
+	if addr == "" {
+		return errors.New("missing address")
+	}
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	if _, err := net.LookupPort("tcp", port); err != nil {
+		return fmt.Errorf("invalid port %q", port)
+	}
+	return nil
+}
+
+// File: 읽을 수 있는 일반 파일인지
+func File(path string) error {
+	if path == "" {
+		return errors.New("required")
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("%s is not a regular file", path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// Dir: 디렉터리가 있는지
+func Dir(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", path)
+	}
+	return nil
+}
+
+// KeyFile: File 검사에 더해, 다른 사용자가 읽을 수 없는 파일인지
+func KeyFile(path string) error {
+	if err := File(path); err != nil {
+		return err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if perm := info.Mode().Perm(); perm&0o077 != 0 {
+		return fmt.Errorf("private key %s is accessible by other users (mode %#o); run chmod 600 %s", path, perm, path)
+	}
+	return nil
+}
+
+// Positive: 시간 값이 0보다 큰지
+func Positive(d time.Duration) error {
+	if d <= 0 {
+		return fmt.Errorf("must be positive, got %v", d)
+	}
+	return nil
+}
+
+// NonNegative: 정수 값이 0 이상인지
+func NonNegative(n int) error {
+	if n < 0 {
+		return fmt.Errorf("must not be negative, got %d", n)
+	}
+	return nil
+}
+
+// ErrFingerprintMismatch: 그룹 파일이 고정한 fingerprint와 다름
+var ErrFingerprintMismatch = errors.New("group fingerprint mismatch")
+
+// Fingerprint: 그룹 fingerprint 고정용 hex 플래그 값(cosi.FingerprintSize 바이트)
+// 비어 있으면 고정하지 않음
+type Fingerprint []byte
+
+func (f Fingerprint) String() string { return hex.EncodeToString(f) }
+
+func (f *Fingerprint) Set(s string) error {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return fmt.Errorf("invalid hex fingerprint: %v", err)
+	}
+	if len(b) != 0 && len(b) != cosi.FingerprintSize {
+		return fmt.Errorf("fingerprint must be %d bytes, got %d", cosi.FingerprintSize, len(b))
+	}
+	*f = b
+	return nil
+}
+
+// Check: 고정된 fingerprint가 있으면 g의 fingerprint와 같은지 확인
+// 다른 그룹 파일(멤버가 바뀌었거나 순서가 다른)로 시작하는 것을 막는다.
+func (f Fingerprint) Check(g *group.File) error {
+	if len(f) == 0 {
+		return nil
+	}
+	if got := g.Fingerprint(); !bytes.Equal(got, f) {
+		return fmt.Errorf("%w: group file has %x, pinned %x", ErrFingerprintMismatch, got, []byte(f))
+	}
+	return nil
+}
+
+// List: 여러 번 지정하거나 쉼표로 구분해 주는 문자열 목록 플래그 값
+type List []string
+
+func (l List) String() string { return strings.Join(l, ",") }
+
+func (l *List) Set(s string) error {
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*l = append(*l, v)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,277 @@
+// Package cosirpc: cosi 서명 프로토콜(protocol.Cosigner)의 gRPC 바인딩
+//
+// Server는 cosigner 데몬에서 protocol.Service를 Cosigner gRPC 서비스로 제공하고,
+// Client는 leader에서 원격 cosigner를 protocol.Cosigner로 사용하게 한다.
+// transport 보안은 mtls 또는 noiseconn의 credentials로 맞춘다.
+//
+// protocol 패키지의 에러는 gRPC status code로 전달되고,
+// Client에서 다시 같은 sentinel 에러(errors.Is로 확인 가능)로 복원된다.
+package cosirpc
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/status"
+
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/golang-x-crypto/ed25519/cosi"
+	"test-server/golang-x-crypto/ed25519/cosi/protocol"
+	pb "test-server/proto_interface"
+)
+
+// Server: protocol.Cosigner를 Cosigner gRPC 서비스로 제공
+type Server struct {
+	pb.UnimplementedCosignerServer
+
+	// Cosigner: 요청을 처리할 cosigner, 보통 *protocol.Service
+	Cosigner protocol.Cosigner
+
+	// PeerKey: 인증된 transport에서 호출한 leader의 키를 반환
+	// (mtls.PeerFromContext, noiseconn.PeerFromContext).
+	// 설정 시 요청의 Leader를 hex 인코딩한 peer 키로 덮어쓴다.
+	PeerKey func(ctx context.Context) (ed25519.PublicKey, error)
+
+	// Authorize: PeerKey로 인증한 leader를 요청마다 다시 확인, 에러면 Unauthenticated
+	// 그룹 재적재로 빠진 leader가 이미 맺은 연결로 보내는 요청을 막는다.
+	Authorize func(leader ed25519.PublicKey) error
+
+	// GroupUpdater: leader(인증된 transport에서는 hex 인코딩한 peer 키)가 보낸 그룹 변경을 처리하고
+	// 새 그룹의 fingerprint를 반환, nil이면 UpdateGroup RPC는 Unimplemented
+	GroupUpdater func(ctx context.Context, leader string, u *GroupUpdate) ([]byte, error)
+}
+
+// GroupUpdate: 지금 그룹이 승인한 새 그룹 파일 (group.File.VerifyRotation으로 검증)
+type GroupUpdate struct {
+	Group     []byte        // 새 그룹 파일 (JSON)
+	Signature []byte        // 지금 그룹의 endorsement
+	Binding   *cosi.Binding // bound 라운드에서 만든 서명이면 그 binding
+}
+
+// Register: s를 gRPC 서버에 등록
+func (s *Server) Register(gs grpc.ServiceRegistrar) {
+	pb.RegisterCosignerServer(gs, s)
+}
+
+func (s *Server) leader(ctx context.Context, claimed string) (string, error) {
+	if s.PeerKey == nil {
+		return claimed, nil
+	}
+	pub, err := s.PeerKey(ctx)
+	if err != nil {
+		return "", status.Error(codes.Unauthenticated, err.Error())
+	}
+	if s.Authorize != nil {
+		if err := s.Authorize(pub); err != nil {
+			return "", status.Error(codes.Unauthenticated, err.Error())
+		}
+	}
+	return hex.EncodeToString(pub), nil
+}
+
+func (s *Server) Commit(ctx context.Context, req *pb.CosiCommitRequest) (*pb.CosiCommitResponse, error) {
+	leader, err := s.leader(ctx, req.Leader)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.Cosigner.Commit(ctx, &protocol.CommitRequest{
+		SessionID:    req.SessionId,
+		Message:      req.Message,
+		Leader:       leader,
+		Term:         req.Term,
+		Bound:        req.Bound,
+		Group:        req.Group,
+		Metadata:     protocol.Metadata(req.Metadata),
+		TraceContext: req.TraceContext,
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.CosiCommitResponse{Commit: resp.Commit}, nil
+}
+
+func (s *Server) Respond(ctx context.Context, req *pb.CosiChallengeRequest) (*pb.CosiChallengeResponse, error) {
+	leader, err := s.leader(ctx, req.Leader)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.Cosigner.Respond(ctx, &protocol.ChallengeRequest{
+		SessionID:       req.SessionId,
+		Message:         req.Message,
+		AggregateKey:    req.AggregateKey,
+		AggregateCommit: req.AggregateCommit,
+		Leader:          leader,
+		TraceContext:    req.TraceContext,
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.CosiChallengeResponse{Part: resp.Part}, nil
+}
+
+func (s *Server) UpdateGroup(ctx context.Context, req *pb.CosiGroupUpdate) (*pb.CosiGroupUpdateResponse, error) {
+	if s.GroupUpdater == nil {
+		return nil, status.Error(codes.Unimplemented, "group updates are disabled on this cosigner")
+	}
+	leader, err := s.leader(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	u := &GroupUpdate{Group: req.Group, Signature: req.Signature}
+	if req.BindingSessionId != nil {
+		u.Binding = &cosi.Binding{SessionID: req.BindingSessionId, Leader: req.BindingLeader}
+	}
+	fingerprint, err := s.GroupUpdater(ctx, leader, u)
+	if err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return &pb.CosiGroupUpdateResponse{Fingerprint: fingerprint}, nil
+}
+
+// protocol 에러 ↔ gRPC status code
+var statusCodes = []struct {
+	err  error
+	code codes.Code
+}{
+	{protocol.ErrRejected, codes.PermissionDenied},
+	{protocol.ErrBackpressure, codes.ResourceExhausted},
+	{protocol.ErrUnknownSession, codes.NotFound},
+	{protocol.ErrDuplicateSession, codes.AlreadyExists},
+	{protocol.ErrUnbound, codes.FailedPrecondition},
+	{protocol.ErrDraining, codes.Unavailable},
+	{protocol.ErrStaleGroup, codes.Aborted},
+}
+
+func toStatus(err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+	for _, sc := range statusCodes {
+		if errors.Is(err, sc.err) {
+			return status.Error(sc.code, err.Error())
+		}
+	}
+	return status.Error(codes.Unknown, err.Error())
+}
+
+// fromStatus: Server가 보낸 status를 protocol 에러로 복원
+// 메시지는 원래 에러 문자열이므로 그대로 두고 sentinel만 감싼다.
+func fromStatus(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	switch st.Code() {
+	case codes.Canceled:
+		return fmt.Errorf("%w: %s", context.Canceled, st.Message())
+	case codes.DeadlineExceeded:
+		return fmt.Errorf("%w: %s", context.DeadlineExceeded, st.Message())
+	}
+	for _, sc := range statusCodes {
+		// Unavailable은 연결 실패에도 쓰이므로 cosigner가 보낸 ErrDraining만 복원
+		if st.Code() == sc.code && (sc.code != codes.Unavailable || st.Message() == sc.err.Error()) {
+			return &remoteError{sc.err, st.Message()}
+		}
+	}
+	return err
+}
+
+// remoteError: 원격 cosigner가 보낸 protocol 에러
+type remoteError struct {
+	sentinel error
+	msg      string
+}
+
+func (e *remoteError) Error() string { return e.msg }
+func (e *remoteError) Unwrap() error { return e.sentinel }
+
+// Client: 원격 cosigner에 대한 protocol.Cosigner
+type Client struct {
+	conn *grpc.ClientConn
+	c    pb.CosignerClient
+}
+
+// Dial: target의 cosigner에 연결(연결은 첫 요청 시 수립)
+// opts에는 보통 mtls.ClientCredentials 등의 transport credentials를 넘긴다.
+func Dial(target string, opts ...grpc.DialOption) (*Client, error) {
+	conn, err := grpc.NewClient(target, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{conn: conn, c: pb.NewCosignerClient(conn)}, nil
+}
+
+// Close: 연결 종료
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// WaitReady: 연결(transport 핸드셰이크 포함)을 시도하고 결과를 기다림
+// 연결이 실패 상태(재연결 backoff 중 포함)이거나 ctx가 끝나면 에러 반환
+func (c *Client) WaitReady(ctx context.Context) error {
+	c.conn.Connect()
+	for {
+		state := c.conn.GetState()
+		switch state {
+		case connectivity.Ready:
+			return nil
+		case connectivity.TransientFailure, connectivity.Shutdown:
+			return fmt.Errorf("connection %s", strings.ToLower(state.String()))
+		}
+		if !c.conn.WaitForStateChange(ctx, state) {
+			return fmt.Errorf("connection %s: %w", strings.ToLower(state.String()), ctx.Err())
+		}
+	}
+}
+
+func (c *Client) Commit(ctx context.Context, req *protocol.CommitRequest) (*protocol.CommitResponse, error) {
+	resp, err := c.c.Commit(ctx, &pb.CosiCommitRequest{
+		SessionId:    req.SessionID,
+		Message:      req.Message,
+		Leader:       req.Leader,
+		Term:         req.Term,
+		Bound:        req.Bound,
+		Group:        req.Group,
+		Metadata:     req.Metadata,
+		TraceContext: req.TraceContext,
+	})
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+	return &protocol.CommitResponse{Commit: resp.Commit}, nil
+}
+
+func (c *Client) Respond(ctx context.Context, req *protocol.ChallengeRequest) (*protocol.ChallengeResponse, error) {
+	resp, err := c.c.Respond(ctx, &pb.CosiChallengeRequest{
+		SessionId:       req.SessionID,
+		Message:         req.Message,
+		AggregateKey:    req.AggregateKey,
+		AggregateCommit: req.AggregateCommit,
+		Leader:          req.Leader,
+		TraceContext:    req.TraceContext,
+	})
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+	return &protocol.ChallengeResponse{Part: resp.Part}, nil
+}
+
+// UpdateGroup: cosigner에 승인된 새 그룹 파일을 보내고 cosigner가 반영한 그룹의 fingerprint를 받음
+// protocol 밖의 관리 요청이므로 에러는 gRPC status 그대로 반환한다.
+func (c *Client) UpdateGroup(ctx context.Context, u *GroupUpdate) ([]byte, error) {
+	req := &pb.CosiGroupUpdate{Group: u.Group, Signature: u.Signature}
+	if u.Binding != nil {
+		req.BindingSessionId, req.BindingLeader = u.Binding.SessionID, u.Binding.Leader
+	}
+	resp, err := c.c.UpdateGroup(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Fingerprint, nil
+}
@@ -0,0 +1,286 @@
+package cosirpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/golang-x-crypto/ed25519/cosi"
+	"test-server/golang-x-crypto/ed25519/cosi/mtls"
+	"test-server/golang-x-crypto/ed25519/cosi/protocol"
+)
+
+// startCosigners: n개의 cosigner를 transport로 띄우고 각각의 키, 주소, 연결한 Client를 반환
+func startCosigners(t *testing.T, transport string, n int, leader ed25519.PrivateKey,
+	validator protocol.Validator) ([]ed25519.PublicKey, []string, []*Client) {
+
+	keys := make([]ed25519.PublicKey, n)
+	privs := make([]ed25519.PrivateKey, n)
+	for i := range keys {
+		keys[i], privs[i], _ = ed25519.GenerateKey(nil)
+	}
+	fingerprint := cosi.NewCosigners(keys, nil).Fingerprint()
+	leaderPub := leader.Public().(ed25519.PublicKey)
+
+	addrs := make([]string, n)
+	clients := make([]*Client, n)
+	for i := range keys {
+		svc := protocol.NewService(privs[i], nil)
+		svc.Validator = validator
+		creds, peerKey, err := ServerCredentials(transport, privs[i], []ed25519.PublicKey{leaderPub}, fingerprint)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gs := grpc.NewServer(grpc.Creds(creds))
+		(&Server{Cosigner: svc, PeerKey: peerKey}).Register(gs)
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		go gs.Serve(lis)
+		addrs[i] = lis.Addr().String()
+		t.Cleanup(gs.Stop)
+
+		clientCreds, err := ClientCredentials(transport, leader, keys[i], fingerprint)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, err := Dial(addrs[i], grpc.WithTransportCredentials(clientCreds))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { c.Close() })
+		clients[i] = c
+	}
+	return keys, addrs, clients
+}
+
+func TestRound(t *testing.T) {
+	for _, transport := range Transports {
+		t.Run(transport, func(t *testing.T) {
+			_, leader, _ := ed25519.GenerateKey(nil)
+			keys, _, clients := startCosigners(t, transport, 3, leader, nil)
+			cosigners := make([]protocol.Cosigner, len(clients))
+			for i, c := range clients {
+				cosigners[i] = c
+			}
+			l, err := protocol.NewLeader(keys, cosigners, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			message := []byte("test message")
+			res, err := l.Sign(context.Background(), message)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !cosi.Verify(keys, nil, message, res.Signature) {
+				t.Errorf("collective signature rejected")
+			}
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	_, leader, _ := ed25519.GenerateKey(nil)
+	_, _, clients := startCosigners(t, TransportMTLS, 1, leader, protocol.MaxMessageSize(4))
+	ctx := context.Background()
+
+	_, err := clients[0].Commit(ctx, &protocol.CommitRequest{SessionID: []byte("s1"), Message: []byte("too long")})
+	if !errors.Is(err, protocol.ErrRejected) {
+		t.Errorf("Commit(long message) error = %v, want ErrRejected", err)
+	}
+	_, err = clients[0].Respond(ctx, &protocol.ChallengeRequest{SessionID: []byte("s2"), Message: []byte("msg")})
+	if !errors.Is(err, protocol.ErrUnknownSession) {
+		t.Errorf("Respond(unknown session) error = %v, want ErrUnknownSession", err)
+	}
+	if _, err := clients[0].Commit(ctx, &protocol.CommitRequest{SessionID: []byte("s3"), Message: []byte("ok")}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = clients[0].Commit(ctx, &protocol.CommitRequest{SessionID: []byte("s3"), Message: []byte("ok")})
+	if !errors.Is(err, protocol.ErrDuplicateSession) {
+		t.Errorf("Commit(duplicate session) error = %v, want ErrDuplicateSession", err)
+	}
+}
+
+func TestDraining(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(nil)
+	svc := protocol.NewService(priv, nil)
+	svc.Drain()
+	gs := grpc.NewServer()
+	(&Server{Cosigner: svc}).Register(gs)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go gs.Serve(lis)
+	defer gs.Stop()
+	c, err := Dial(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	_, err = c.Commit(context.Background(), &protocol.CommitRequest{SessionID: []byte("s"), Message: []byte("m")})
+	if !errors.Is(err, protocol.ErrDraining) {
+		t.Errorf("Commit(draining) error = %v, want ErrDraining", err)
+	}
+}
+
+func TestReloadGroup(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(nil)
+	pub := priv.Public().(ed25519.PublicKey)
+	_, leaderA, _ := ed25519.GenerateKey(nil)
+	_, leaderB, _ := ed25519.GenerateKey(nil)
+	svc := protocol.NewService(priv, nil)
+	svc.SetGroup([]byte("group 1"))
+	serverCreds := func(leader ed25519.PrivateKey) *SwappableCredentials {
+		creds, _, err := ServerCredentials(TransportMTLS, priv, []ed25519.PublicKey{leader.Public().(ed25519.PublicKey)}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return NewSwappableCredentials(creds)
+	}
+	creds := serverCreds(leaderA)
+	allowed := leaderA.Public().(ed25519.PublicKey)
+	gs := grpc.NewServer(grpc.Creds(creds))
+	(&Server{Cosigner: svc, PeerKey: mtls.PeerFromContext, Authorize: func(leader ed25519.PublicKey) error {
+		if !leader.Equal(allowed) {
+			return errors.New("not a leader")
+		}
+		return nil
+	}}).Register(gs)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go gs.Serve(lis)
+	defer gs.Stop()
+	dial := func(leader ed25519.PrivateKey) *Client {
+		clientCreds, err := ClientCredentials(TransportMTLS, leader, pub, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, err := Dial(lis.Addr().String(), grpc.WithTransportCredentials(clientCreds))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { c.Close() })
+		return c
+	}
+	ctx := context.Background()
+	a := dial(leaderA)
+	_, err = a.Commit(ctx, &protocol.CommitRequest{SessionID: []byte("s1"), Message: []byte("m"), Group: []byte("group 2")})
+	if !errors.Is(err, protocol.ErrStaleGroup) {
+		t.Errorf("Commit(other group) error = %v, want ErrStaleGroup", err)
+	}
+
+	// 재적재: leader A → B
+	creds.Swap(serverCreds(leaderB).current())
+	allowed = leaderB.Public().(ed25519.PublicKey)
+	svc.SetGroup([]byte("group 2"))
+	if _, err := a.Commit(ctx, &protocol.CommitRequest{SessionID: []byte("s2"), Message: []byte("m")}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("removed leader on an open connection: error = %v, want Unauthenticated", err)
+	}
+	b := dial(leaderB)
+	if _, err := b.Commit(ctx, &protocol.CommitRequest{SessionID: []byte("s3"), Message: []byte("m"), Group: []byte("group 2")}); err != nil {
+		t.Errorf("new leader: %v", err)
+	}
+}
+
+func TestUpdateGroup(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(nil)
+	srv := &Server{Cosigner: protocol.NewService(priv, nil)}
+	gs := grpc.NewServer()
+	srv.Register(gs)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go gs.Serve(lis)
+	defer gs.Stop()
+	c, err := Dial(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+	u := &GroupUpdate{Group: []byte("{}"), Signature: []byte("sig"), Binding: &cosi.Binding{SessionID: []byte("s"), Leader: []byte("l")}}
+	if _, err := c.UpdateGroup(ctx, u); status.Code(err) != codes.Unimplemented {
+		t.Errorf("updates disabled: error = %v, want Unimplemented", err)
+	}
+
+	var got *GroupUpdate
+	srv.GroupUpdater = func(_ context.Context, _ string, u *GroupUpdate) ([]byte, error) {
+		got = u
+		return []byte("fingerprint"), nil
+	}
+	fingerprint, err := c.UpdateGroup(ctx, u)
+	if err != nil || string(fingerprint) != "fingerprint" {
+		t.Fatalf("UpdateGroup = %q, %v", fingerprint, err)
+	}
+	if string(got.Group) != "{}" || string(got.Signature) != "sig" || got.Binding == nil || !got.Binding.Equal(*u.Binding) {
+		t.Errorf("server got %+v", got)
+	}
+	srv.GroupUpdater = func(context.Context, string, *GroupUpdate) ([]byte, error) {
+		return nil, errors.New("not endorsed")
+	}
+	if _, err := c.UpdateGroup(ctx, u); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("rejected update: error = %v, want FailedPrecondition", err)
+	}
+}
+
+func TestUnknownLeader(t *testing.T) {
+	for _, transport := range []string{TransportMTLS, TransportNoise} {
+		_, leader, _ := ed25519.GenerateKey(nil)
+		keys, addrs, _ := startCosigners(t, transport, 1, leader, nil)
+		fingerprint := cosi.NewCosigners(keys, nil).Fingerprint()
+
+		// 그룹 파일에 없는 leader 키로는 연결되지 않아야 함
+		_, other, _ := ed25519.GenerateKey(nil)
+		creds, err := ClientCredentials(transport, other, keys[0], fingerprint)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, err := Dial(addrs[0], grpc.WithTransportCredentials(creds))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer c.Close()
+		if _, err := c.Commit(context.Background(), &protocol.CommitRequest{SessionID: []byte("s"), Message: []byte("m")}); err == nil {
+			t.Errorf("%s: commit from unknown leader accepted", transport)
+		}
+	}
+}
+
+func TestWaitReady(t *testing.T) {
+	_, leader, _ := ed25519.GenerateKey(nil)
+	_, _, clients := startCosigners(t, TransportMTLS, 1, leader, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := clients[0].WaitReady(ctx); err != nil {
+		t.Errorf("WaitReady: %v", err)
+	}
+
+	// 아무도 listen하지 않는 주소
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := lis.Addr().String()
+	lis.Close()
+	c, err := Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if err := c.WaitReady(ctx); err == nil {
+		t.Errorf("WaitReady succeeded without a server")
+	}
+}
@@ -0,0 +1,155 @@
+package cosirpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/golang-x-crypto/ed25519/cosi/mtls"
+	"test-server/golang-x-crypto/ed25519/cosi/noiseconn"
+	"test-server/golang-x-crypto/ed25519/cosi/protocol"
+	"test-server/group"
+)
+
+// Transport 이름
+const (
+	TransportMTLS     = "mtls"     // Ed25519 키로 상호 인증하는 TLS 1.3 (기본값)
+	TransportNoise    = "noise"    // Noise 채널, prologue는 그룹 fingerprint
+	TransportInsecure = "insecure" // 암호화·인증 없음, 로컬 테스트 전용
+)
+
+// Transports: 지원하는 transport 이름 목록
+var Transports = []string{TransportMTLS, TransportNoise, TransportInsecure}
+
+// ServerCredentials: cosigner(privateKey)가 leaders만 받아들이는 transport credentials와,
+// 호출한 leader의 인증된 키를 꺼내는 Server.PeerKey용 함수(insecure면 nil)
+func ServerCredentials(transport string, privateKey ed25519.PrivateKey, leaders []ed25519.PublicKey,
+	fingerprint []byte) (credentials.TransportCredentials, func(context.Context) (ed25519.PublicKey, error), error) {
+
+	switch transport {
+	case TransportMTLS, "":
+		if len(leaders) == 0 {
+			return nil, nil, fmt.Errorf("cosirpc: %s transport needs at least one leader key", TransportMTLS)
+		}
+		creds, err := mtls.ServerCredentials(privateKey, leaders)
+		return creds, mtls.PeerFromContext, err
+	case TransportNoise:
+		if len(leaders) == 0 {
+			return nil, nil, fmt.Errorf("cosirpc: %s transport needs at least one leader key", TransportNoise)
+		}
+		creds := noiseconn.ServerCredentials(&noiseconn.Config{
+			PrivateKey: privateKey,
+			Peers:      leaders,
+			Prologue:   fingerprint,
+		})
+		return creds, noiseconn.PeerFromContext, nil
+	case TransportInsecure:
+		return insecure.NewCredentials(), nil, nil
+	}
+	return nil, nil, fmt.Errorf("cosirpc: unknown transport %q", transport)
+}
+
+// SwappableCredentials: 새 연결의 핸드셰이크를 Swap으로 마지막에 넣은 credentials로 처리
+// 그룹 재적재 때 서버를 다시 띄우지 않고 받아들일 leader와 fingerprint를 바꾸는 데 쓴다.
+// 이미 맺은 연결은 그대로 유지된다(빠진 leader는 Server.Authorize로 막는다).
+type SwappableCredentials struct {
+	cur atomic.Pointer[credentialsBox]
+}
+
+type credentialsBox struct {
+	credentials.TransportCredentials
+}
+
+// NewSwappableCredentials: creds로 시작하는 SwappableCredentials
+func NewSwappableCredentials(creds credentials.TransportCredentials) *SwappableCredentials {
+	s := &SwappableCredentials{}
+	s.Swap(creds)
+	return s
+}
+
+// Swap: 이후 연결에 쓸 credentials 교체
+func (s *SwappableCredentials) Swap(creds credentials.TransportCredentials) {
+	s.cur.Store(&credentialsBox{creds})
+}
+
+func (s *SwappableCredentials) current() credentials.TransportCredentials {
+	return s.cur.Load().TransportCredentials
+}
+
+func (s *SwappableCredentials) ClientHandshake(ctx context.Context, authority string, conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return s.current().ClientHandshake(ctx, authority, conn)
+}
+
+func (s *SwappableCredentials) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return s.current().ServerHandshake(conn)
+}
+
+func (s *SwappableCredentials) Info() credentials.ProtocolInfo {
+	return s.current().Info()
+}
+
+func (s *SwappableCredentials) Clone() credentials.TransportCredentials {
+	return NewSwappableCredentials(s.current().Clone())
+}
+
+func (s *SwappableCredentials) OverrideServerName(name string) error {
+	return s.current().OverrideServerName(name)
+}
+
+// ClientCredentials: leader(privateKey)가 server 키의 cosigner에 연결할 때의 transport credentials
+func ClientCredentials(transport string, privateKey ed25519.PrivateKey, server ed25519.PublicKey,
+	fingerprint []byte) (credentials.TransportCredentials, error) {
+
+	switch transport {
+	case TransportMTLS, "":
+		return mtls.ClientCredentials(privateKey, server)
+	case TransportNoise:
+		return noiseconn.ClientCredentials(&noiseconn.Config{
+			PrivateKey: privateKey,
+			Peers:      []ed25519.PublicKey{server},
+			Pattern:    noiseconn.IK,
+			Prologue:   fingerprint,
+		}), nil
+	case TransportInsecure:
+		return insecure.NewCredentials(), nil
+	}
+	return nil, fmt.Errorf("cosirpc: unknown transport %q", transport)
+}
+
+// DialGroup: 그룹 파일의 cosigner마다 transport로 Client를 만들어 그룹 순서대로 반환
+// (protocol.NewLeader에 그대로 넘길 수 있음), 모두 닫는 함수도 함께 반환
+func DialGroup(transport string, privateKey ed25519.PrivateKey, g *group.File) ([]protocol.Cosigner, func(), error) {
+	var clients []*Client
+	closeAll := func() {
+		for _, c := range clients {
+			c.Close()
+		}
+	}
+	fingerprint := g.Fingerprint()
+	cosigners := make([]protocol.Cosigner, len(g.Cosigners))
+	for i, m := range g.Cosigners {
+		if m.Address == "" {
+			closeAll()
+			return nil, nil, fmt.Errorf("cosigner %d has no address in the group file", i)
+		}
+		creds, err := ClientCredentials(transport, privateKey, ed25519.PublicKey(m.PublicKey), fingerprint)
+		if err != nil {
+			closeAll()
+			return nil, nil, err
+		}
+		c, err := Dial(m.Address, grpc.WithTransportCredentials(creds))
+		if err != nil {
+			closeAll()
+			return nil, nil, err
+		}
+		clients = append(clients, c)
+		cosigners[i] = c
+	}
+	return cosigners, closeAll, nil
+}
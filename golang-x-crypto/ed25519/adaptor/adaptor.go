@@ -0,0 +1,235 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package adaptor implements Schnorr adaptor signatures with Ed25519 keys.
+//
+// A pre-signature on a message is bound to an adaptor point T = t*B
+// whose discrete logarithm t, the adaptor secret, the signer need not know.
+// Anyone can check a pre-signature against the public key, message and T
+// with PreVerify, but it is not a valid signature:
+// only someone knowing t can complete it with Adapt into
+// an ordinary Ed25519 signature, accepted by ed25519.Verify.
+// Whoever holds both the pre-signature and the completed signature
+// can then recover t with Extract.
+// Publishing a signature thus reveals the adaptor secret,
+// the building block of atomic swaps and payment channels.
+package adaptor
+
+import (
+	cryptorand "crypto/rand"
+	"crypto/sha512"
+	"errors"
+	"io"
+
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/golang-x-crypto/ed25519/internal/edwards25519"
+	"test-server/golang-x-crypto/ed25519/schnorr"
+)
+
+const (
+	// PreSignatureSize is the size, in bytes, of pre-signatures:
+	// the nonce point R' followed by the scalar s'.
+	PreSignatureSize = 64
+	// SecretSize is the size, in bytes, of adaptor secrets.
+	SecretSize = 32
+	// PointSize is the size, in bytes, of adaptor points.
+	PointSize = 32
+)
+
+// ErrMismatch is returned by Extract when the signature
+// was not completed from the pre-signature with the given adaptor point.
+var ErrMismatch = errors.New("adaptor: signature does not complete pre-signature")
+
+var (
+	scZero      [32]byte
+	scOne       = [32]byte{1}
+	scMinusOne  = [32]byte{0xec, 0xd3, 0xf5, 0x5c, 0x1a, 0x63, 0x12, 0x58, 0xd6, 0x9c, 0xf7, 0xa2, 0xde, 0xf9, 0xde, 0x14, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x10}
+	errBadPoint = errors.New("adaptor: invalid point")
+)
+
+// GenerateSecret returns a new adaptor secret t and its point T = t*B,
+// using entropy from rand, or crypto/rand if rand is nil.
+func GenerateSecret(rand io.Reader) (secret, point []byte, err error) {
+	if rand == nil {
+		rand = cryptorand.Reader
+	}
+	var wide [64]byte
+	if _, err := io.ReadFull(rand, wide[:]); err != nil {
+		return nil, nil, err
+	}
+	var t [32]byte
+	edwards25519.ScReduce(&t, &wide)
+	var T edwards25519.ExtendedGroupElement
+	edwards25519.GeScalarMultBase(&T, &t)
+	var Tb [32]byte
+	T.ToBytes(&Tb)
+	return t[:], Tb[:], nil
+}
+
+// PreSign returns a pre-signature of message by privateKey,
+// bound to the adaptor point T.
+// It returns an error if T is not a valid point.
+// It will panic if len(privateKey) is not ed25519.PrivateKeySize.
+func PreSign(privateKey ed25519.PrivateKey, message, adaptorPoint []byte) ([]byte, error) {
+	if len(privateKey) != ed25519.PrivateKeySize {
+		panic("adaptor: bad private key length")
+	}
+	T, err := decode(adaptorPoint)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha512.Sum512(privateKey[:32])
+	var a [32]byte
+	copy(a[:], digest[:32])
+	a[0] &= 248
+	a[31] &= 63
+	a[31] |= 64
+
+	// The nonce is derived as in Ed25519,
+	// with the adaptor point bound in.
+	h := sha512.New()
+	h.Write(digest[32:])
+	h.Write(adaptorPoint)
+	h.Write(message)
+	var wide [64]byte
+	h.Sum(wide[:0])
+	var r [32]byte
+	edwards25519.ScReduce(&r, &wide)
+
+	var R1, R edwards25519.ExtendedGroupElement
+	edwards25519.GeScalarMultBase(&R1, &r)
+	R.Add(&R1, T)
+	k := challenge(&R, privateKey[32:], message)
+
+	// s' = r + k*a
+	var s [32]byte
+	edwards25519.ScMulAdd(&s, &k, &a, &r)
+
+	pre := make([]byte, PreSignatureSize)
+	var R1b [32]byte
+	R1.ToBytes(&R1b)
+	copy(pre, R1b[:])
+	copy(pre[32:], s[:])
+	return pre, nil
+}
+
+// PreVerify reports whether pre is a valid pre-signature of message
+// by publicKey bound to the adaptor point T:
+// one that Adapt completes into a valid signature
+// given the adaptor secret of T.
+func PreVerify(publicKey ed25519.PublicKey, message, adaptorPoint, pre []byte) bool {
+	if len(publicKey) != ed25519.PublicKeySize || len(pre) != PreSignatureSize ||
+		!ed25519.IsCanonicalScalar(pre[32:]) {
+		return false
+	}
+	A, err := decode(publicKey)
+	if err != nil {
+		return false
+	}
+	T, err := decode(adaptorPoint)
+	if err != nil {
+		return false
+	}
+	R1, err := decode(pre[:32])
+	if err != nil {
+		return false
+	}
+	var R edwards25519.ExtendedGroupElement
+	R.Add(R1, T)
+	k := challenge(&R, publicKey, message)
+
+	// s'*B == R' + k*A
+	var s [32]byte
+	copy(s[:], pre[32:])
+	var sB, kA, rhs edwards25519.ExtendedGroupElement
+	edwards25519.GeScalarMultBase(&sB, &s)
+	edwards25519.GeScalarMultVartime(&kA, &k, A)
+	rhs.Add(R1, &kA)
+	return equal(&sB, &rhs)
+}
+
+// Adapt completes pre, a pre-signature bound to the adaptor point
+// of secret, into an Ed25519 signature.
+// The result is only valid if pre passed PreVerify
+// for that adaptor point.
+func Adapt(pre, secret []byte) ([]byte, error) {
+	if len(pre) != PreSignatureSize || len(secret) != SecretSize ||
+		!ed25519.IsCanonicalScalar(secret) {
+		return nil, errors.New("adaptor: malformed pre-signature or secret")
+	}
+	R1, err := decode(pre[:32])
+	if err != nil {
+		return nil, err
+	}
+	var t, s1, s [32]byte
+	copy(t[:], secret)
+	copy(s1[:], pre[32:])
+	var T, R edwards25519.ExtendedGroupElement
+	edwards25519.GeScalarMultBase(&T, &t)
+	R.Add(R1, &T)
+
+	// s = s' + t
+	edwards25519.ScMulAdd(&s, &scOne, &s1, &t)
+
+	sig := make([]byte, ed25519.SignatureSize)
+	var Rb [32]byte
+	R.ToBytes(&Rb)
+	copy(sig, Rb[:])
+	copy(sig[32:], s[:])
+	return sig, nil
+}
+
+// Extract recovers the adaptor secret of the adaptor point T
+// from a pre-signature pre and the signature sig completed from it.
+func Extract(pre, sig, adaptorPoint []byte) ([]byte, error) {
+	if len(pre) != PreSignatureSize || len(sig) != ed25519.SignatureSize ||
+		!ed25519.IsCanonicalScalar(pre[32:]) || !ed25519.IsCanonicalScalar(sig[32:]) {
+		return nil, ErrMismatch
+	}
+	T, err := decode(adaptorPoint)
+	if err != nil {
+		return nil, err
+	}
+	// t = s - s'
+	var s, s1, negS1, t [32]byte
+	copy(s[:], sig[32:])
+	copy(s1[:], pre[32:])
+	edwards25519.ScMulAdd(&negS1, &scMinusOne, &s1, &scZero)
+	edwards25519.ScMulAdd(&t, &scOne, &s, &negS1)
+
+	var tB edwards25519.ExtendedGroupElement
+	edwards25519.GeScalarMultBase(&tB, &t)
+	if !equal(&tB, T) {
+		return nil, ErrMismatch
+	}
+	return t[:], nil
+}
+
+// challenge returns the Ed25519 challenge scalar H(R || A || M).
+func challenge(R *edwards25519.ExtendedGroupElement, publicKey, message []byte) [32]byte {
+	var Rb [32]byte
+	R.ToBytes(&Rb)
+	return schnorr.Ed25519.Challenge(Rb[:], publicKey, message)
+}
+
+// decode decodes a canonical point encoding,
+// rejecting points of small order.
+func decode(p []byte) (*edwards25519.ExtendedGroupElement, error) {
+	if !ed25519.IsCanonicalPoint(p) || ed25519.IsSmallOrder(p) {
+		return nil, errBadPoint
+	}
+	var b [32]byte
+	copy(b[:], p)
+	P := new(edwards25519.ExtendedGroupElement)
+	P.FromBytes(&b)
+	return P, nil
+}
+
+// equal reports whether P and Q are the same point.
+func equal(P, Q *edwards25519.ExtendedGroupElement) bool {
+	var p, q [32]byte
+	P.ToBytes(&p)
+	Q.ToBytes(&q)
+	return p == q
+}
@@ -0,0 +1,76 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package adaptor
+
+import (
+	"bytes"
+	"testing"
+
+	"test-server/golang-x-crypto/ed25519"
+)
+
+func TestAdaptor(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	secret, point, err := GenerateSecret(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := []byte("swap 1 coin for 2 tokens")
+
+	pre, err := PreSign(priv, message, point)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !PreVerify(pub, message, point, pre) {
+		t.Fatalf("valid pre-signature rejected")
+	}
+	if ed25519.Verify(pub, message, pre) {
+		t.Errorf("pre-signature accepted as a signature")
+	}
+
+	sig, err := Adapt(pre, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ed25519.Verify(pub, message, sig) {
+		t.Fatalf("adapted signature rejected")
+	}
+	got, err := Extract(pre, sig, point)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, secret) {
+		t.Errorf("extracted secret %x, want %x", got, secret)
+	}
+}
+
+func TestAdaptorRejects(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	secret, point, _ := GenerateSecret(nil)
+	otherSecret, otherPoint, _ := GenerateSecret(nil)
+	message := []byte("message")
+	pre, _ := PreSign(priv, message, point)
+
+	if PreVerify(pub, []byte("other"), point, pre) {
+		t.Errorf("pre-signature accepted for other message")
+	}
+	if PreVerify(pub, message, otherPoint, pre) {
+		t.Errorf("pre-signature accepted for other adaptor point")
+	}
+	sig, _ := Adapt(pre, otherSecret)
+	if ed25519.Verify(pub, message, sig) {
+		t.Errorf("signature adapted with wrong secret accepted")
+	}
+	if _, err := Extract(pre, sig, point); err != ErrMismatch {
+		t.Errorf("Extract with wrong completion: got %v, want ErrMismatch", err)
+	}
+	sig, _ = Adapt(pre, secret)
+	if _, err := Extract(pre, sig, otherPoint); err != ErrMismatch {
+		t.Errorf("Extract with wrong point: got %v, want ErrMismatch", err)
+	}
+	if _, err := PreSign(priv, message, make([]byte, PointSize)); err == nil {
+		t.Errorf("invalid adaptor point accepted")
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	cryptorand "crypto/rand"
+	"crypto/sha512"
+	"io"
+	"runtime"
+	"sync"
+
+	"test-server/golang-x-crypto/ed25519/internal/edwards25519"
+)
+
+// VerifyBatch reports whether every sigs[i] is a valid signature
+// of msgs[i] by pubs[i].
+// If not, it also returns the indices of the invalid signatures,
+// in increasing order.
+// It will panic if the three slices differ in length.
+//
+// The batch is checked at once with a random linear combination
+// of the verification equations,
+// which for large batches is two to three times faster
+// than verifying each signature with Verify;
+// only if the combined check fails are the signatures verified one by one
+// to identify the invalid ones.
+//
+// The combined check uses the cofactored equation
+// [8][S]B = [8]R + [8][k]A,
+// so a signature crafted with small-order components,
+// which Verify rejects, may pass as part of a valid batch.
+// Signatures produced by Sign always verify the same way in both.
+func VerifyBatch(pubs []PublicKey, msgs, sigs [][]byte) (bool, []int) {
+	if len(pubs) != len(msgs) || len(pubs) != len(sigs) {
+		panic("ed25519: batch slices differ in length")
+	}
+	if len(pubs) == 0 {
+		return true, nil
+	}
+	if len(pubs) > 1 && batchCheck(pubs, msgs, sigs, cryptorand.Reader) {
+		return true, nil
+	}
+	var invalid []int
+	for i := range pubs {
+		if len(pubs[i]) != PublicKeySize || !Verify(pubs[i], msgs[i], sigs[i]) {
+			invalid = append(invalid, i)
+		}
+	}
+	return len(invalid) == 0, invalid
+}
+
+// batchCheck checks the combined verification equation
+// [8]([Σ z_i S_i]B - Σ [z_i]R_i - Σ [z_i k_i]A_i) = 0
+// for random 128-bit coefficients z_i drawn from rand.
+// It returns false if any input is malformed.
+func batchCheck(pubs []PublicKey, msgs, sigs [][]byte, rand io.Reader) bool {
+	n := len(pubs)
+	scalars := make([]*[32]byte, 0, 2*n)
+	points := make([]*edwards25519.ExtendedGroupElement, 0, 2*n)
+	decoded := make([]edwards25519.ExtendedGroupElement, 2*n) // R_i, A_i
+	encoded := make([][32]byte, 2*n)
+	coeffs := make([][32]byte, 2*n)
+	var sumS, zero [32]byte
+
+	random := make([]byte, 16*n)
+	if _, err := io.ReadFull(rand, random); err != nil {
+		return false
+	}
+
+	// Decompress all the points at once, so that GeFromBytesBatch
+	// can decode them four at a time where AVX2 is available.
+	encodings := make([]*[32]byte, 2*n)
+	for i := range pubs {
+		sig := sigs[i]
+		if len(pubs[i]) != PublicKeySize || len(sig) != SignatureSize || sig[63]&224 != 0 {
+			return false
+		}
+		copy(encoded[2*i][:], sig[:32])
+		copy(encoded[2*i+1][:], pubs[i])
+		encodings[2*i], encodings[2*i+1] = &encoded[2*i], &encoded[2*i+1]
+	}
+	if edwards25519.GeFromBytesBatch(decoded, encodings) != -1 {
+		return false
+	}
+
+	h := sha512.New()
+	for i := range pubs {
+		sig := sigs[i]
+		h.Reset()
+		h.Write(sig[:32])
+		h.Write(pubs[i])
+		h.Write(msgs[i])
+		var digest [64]byte
+		h.Sum(digest[:0])
+		var k [32]byte
+		edwards25519.ScReduce(&k, &digest)
+
+		z := &coeffs[2*i]
+		copy(z[:16], random[16*i:])
+		var s [32]byte
+		copy(s[:], sig[32:])
+		edwards25519.ScMulAdd(&sumS, z, &s, &sumS)
+
+		zk := &coeffs[2*i+1]
+		edwards25519.ScMulAdd(zk, z, &k, &zero)
+
+		scalars = append(scalars, z, zk)
+		points = append(points, &decoded[2*i], &decoded[2*i+1])
+	}
+
+	// sum = Σ z_i R_i + Σ z_i k_i A_i, to be subtracted from (Σ z_i S_i) B
+	var sum, sB, check edwards25519.ExtendedGroupElement
+	edwards25519.GeMultiScalarMultVartime(&sum, scalars, points)
+	edwards25519.GeScalarMultBase(&sB, &sumS)
+	check.Sub(&sB, &sum)
+	check.MulByCofactor(&check)
+	return check.IsIdentity()
+}
+
+// GenerateKeys generates n key pairs using entropy from rand,
+// or crypto/rand.Reader if rand is nil.
+// The keys are those that n successive calls to GenerateKey
+// would return with the same rand, but they are computed faster:
+// the seeds are read at once, the keys share two allocations,
+// and the points are encoded with a single field inversion.
+// It will panic if n is negative.
+func GenerateKeys(rand io.Reader, n int) ([]PublicKey, []PrivateKey, error) {
+	return GenerateKeysParallel(rand, n, 1)
+}
+
+// GenerateKeysParallel is like GenerateKeys, but splits the work
+// across workers goroutines, or runtime.GOMAXPROCS(0) if workers <= 0.
+// The keys do not depend on the number of workers.
+func GenerateKeysParallel(rand io.Reader, n, workers int) ([]PublicKey, []PrivateKey, error) {
+	if n < 0 {
+		panic("ed25519: negative key count")
+	}
+	if rand == nil {
+		rand = cryptorand.Reader
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = min(workers, (n+keygenChunk-1)/keygenChunk)
+
+	seeds := make([]byte, n*SeedSize)
+	if _, err := io.ReadFull(rand, seeds); err != nil {
+		return nil, nil, err
+	}
+	privateKeys := make([]byte, n*PrivateKeySize)
+	for i := 0; i < n; i++ {
+		copy(privateKeys[i*PrivateKeySize:], seeds[i*SeedSize:(i+1)*SeedSize])
+	}
+	clear(seeds)
+	publicKeys := make([]byte, n*PublicKeySize)
+
+	if workers <= 1 {
+		expandKeys(publicKeys, privateKeys)
+	} else {
+		var wg sync.WaitGroup
+		per := (n + workers - 1) / workers
+		for lo := 0; lo < n; lo += per {
+			hi := min(lo+per, n)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				expandKeys(publicKeys[lo*PublicKeySize:hi*PublicKeySize],
+					privateKeys[lo*PrivateKeySize:hi*PrivateKeySize])
+			}()
+		}
+		wg.Wait()
+	}
+
+	pubs := make([]PublicKey, n)
+	privs := make([]PrivateKey, n)
+	for i := range pubs {
+		pubs[i] = publicKeys[i*PublicKeySize : (i+1)*PublicKeySize : (i+1)*PublicKeySize]
+		privs[i] = privateKeys[i*PrivateKeySize : (i+1)*PrivateKeySize : (i+1)*PrivateKeySize]
+	}
+	return pubs, privs, nil
+}
+
+// keygenChunk is the smallest number of keys worth a goroutine.
+const keygenChunk = 64
+
+// expandKeys fills in publicKeys and the second half of privateKeys,
+// which hold consecutive key pairs, from the seeds in privateKeys.
+func expandKeys(publicKeys, privateKeys []byte) {
+	n := len(publicKeys) / PublicKeySize
+	points := make([]edwards25519.ExtendedGroupElement, n)
+	for i := range points {
+		digest := sha512.Sum512(privateKeys[i*PrivateKeySize : i*PrivateKeySize+SeedSize])
+		digest[0] &= 248
+		digest[31] &= 127
+		digest[31] |= 64
+		var a [32]byte
+		copy(a[:], digest[:])
+		edwards25519.GeScalarMultBase(&points[i], &a)
+		clear(digest[:])
+		clear(a[:])
+	}
+
+	// Montgomery's trick: invert the product of all Z coordinates,
+	// then peel off each 1/Z_i using the prefix products.
+	prefix := make([]edwards25519.FieldElement, n)
+	var acc, inv, recip, x, y edwards25519.FieldElement
+	edwards25519.FeOne(&acc)
+	for i := range points {
+		prefix[i] = acc
+		edwards25519.FeMul(&acc, &acc, &points[i].Z)
+	}
+	edwards25519.FeInvert(&inv, &acc)
+	for i := n - 1; i >= 0; i-- {
+		edwards25519.FeMul(&recip, &inv, &prefix[i])
+		edwards25519.FeMul(&inv, &inv, &points[i].Z)
+
+		var s [32]byte
+		edwards25519.FeMul(&x, &points[i].X, &recip)
+		edwards25519.FeMul(&y, &points[i].Y, &recip)
+		edwards25519.FeToBytes(&s, &y)
+		s[31] ^= edwards25519.FeIsNegative(&x) << 7
+		copy(publicKeys[i*PublicKeySize:], s[:])
+		copy(privateKeys[i*PrivateKeySize+SeedSize:], s[:])
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package bip39 backs up Ed25519 keys as BIP39 mnemonics:
+// lists of 12 to 24 English words encoding random entropy,
+// from which a seed and then a key are derived.
+//
+// Mnemonics and seeds follow BIP39
+// (https://github.com/bitcoin/bips/blob/master/bip-0039.mediawiki),
+// and keys are derived from seeds as Ed25519 keys are by SLIP-0010
+// (https://github.com/satoshilabs/slips/blob/master/slip-0010.md),
+// so the same mnemonic yields the same key in other wallets and tools
+// that follow those specifications.
+//
+// A cosigner key is typically generated and backed up with
+//
+//	mnemonic, err := bip39.GenerateMnemonic(256, nil)
+//	priv, err := bip39.NewKeyFromMnemonic(mnemonic, "")
+//
+// and recovered later by calling NewKeyFromMnemonic again.
+package bip39
+
+import (
+	"crypto/hmac"
+	cryptorand "crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	_ "embed"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/text/unicode/norm"
+
+	"test-server/golang-x-crypto/ed25519"
+)
+
+// english.txt is the BIP39 English word list, one word per line.
+//
+//go:embed english.txt
+var englishTxt string
+
+var (
+	wordList  = strings.Fields(englishTxt)
+	wordIndex = func() map[string]int {
+		m := make(map[string]int, len(wordList))
+		for i, w := range wordList {
+			m[w] = i
+		}
+		return m
+	}()
+)
+
+// ErrChecksum is returned for a mnemonic of valid words
+// whose checksum does not match, which usually means
+// a word was mistyped or the words were reordered.
+var ErrChecksum = errors.New("bip39: mnemonic checksum mismatch")
+
+// GenerateMnemonic returns a mnemonic encoding bits of entropy
+// read from rand, or from crypto/rand if rand is nil.
+// bits must be 128, 160, 192, 224 or 256,
+// giving 12, 15, 18, 21 or 24 words.
+func GenerateMnemonic(bits int, rand io.Reader) (string, error) {
+	if bits < 128 || bits > 256 || bits%32 != 0 {
+		return "", errors.New("bip39: bad entropy size " + strconv.Itoa(bits))
+	}
+	if rand == nil {
+		rand = cryptorand.Reader
+	}
+	entropy := make([]byte, bits/8)
+	if _, err := io.ReadFull(rand, entropy); err != nil {
+		return "", err
+	}
+	return NewMnemonic(entropy)
+}
+
+// NewMnemonic returns the mnemonic encoding entropy,
+// which must be 16, 20, 24, 28 or 32 bytes long.
+func NewMnemonic(entropy []byte) (string, error) {
+	n := len(entropy)
+	if n < 16 || n > 32 || n%4 != 0 {
+		return "", errors.New("bip39: bad entropy length " + strconv.Itoa(n))
+	}
+	// The entropy is followed by the first n/4 bits of its SHA-256 hash,
+	// and the result split into 11-bit word indices.
+	sum := sha256.Sum256(entropy)
+	data := append(append([]byte{}, entropy...), sum[0])
+	words := make([]string, (n*8+n/4)/11)
+	for i := range words {
+		words[i] = wordList[bits11(data, i)]
+	}
+	return strings.Join(words, " "), nil
+}
+
+// bits11 returns the i-th 11-bit big-endian group of data.
+func bits11(data []byte, i int) int {
+	v := 0
+	for bit := i * 11; bit < (i+1)*11; bit++ {
+		v = v<<1 | int(data[bit/8]>>(7-bit%8)&1)
+	}
+	return v
+}
+
+// MnemonicToEntropy returns the entropy encoded by mnemonic,
+// checking that every word is in the word list and the checksum matches.
+// Words may be separated by any white space.
+func MnemonicToEntropy(mnemonic string) ([]byte, error) {
+	words := strings.Fields(norm.NFKD.String(mnemonic))
+	if n := len(words); n < 12 || n > 24 || n%3 != 0 {
+		return nil, errors.New("bip39: bad mnemonic length " + strconv.Itoa(n))
+	}
+	bits := len(words) * 11
+	data := make([]byte, (bits+7)/8)
+	for i, w := range words {
+		idx, ok := wordIndex[strings.ToLower(w)]
+		if !ok {
+			return nil, errors.New("bip39: unknown word " + strconv.Quote(w))
+		}
+		for b := 0; b < 11; b++ {
+			if idx>>(10-b)&1 != 0 {
+				bit := i*11 + b
+				data[bit/8] |= 0x80 >> (bit % 8)
+			}
+		}
+	}
+	checksumBits := bits / 33
+	entropy := data[:(bits-checksumBits)/8]
+	sum := sha256.Sum256(entropy)
+	if data[len(entropy)]>>(8-checksumBits) != sum[0]>>(8-checksumBits) {
+		return nil, ErrChecksum
+	}
+	return entropy, nil
+}
+
+// IsValid reports whether mnemonic is a valid BIP39 mnemonic.
+func IsValid(mnemonic string) bool {
+	_, err := MnemonicToEntropy(mnemonic)
+	return err == nil
+}
+
+// Seed returns the 64-byte BIP39 seed of mnemonic and passphrase,
+// after checking that the mnemonic is valid.
+// The passphrase, which may be empty, acts as an extra secret:
+// each passphrase yields a different, equally plausible seed.
+func Seed(mnemonic, passphrase string) ([]byte, error) {
+	if _, err := MnemonicToEntropy(mnemonic); err != nil {
+		return nil, err
+	}
+	m := strings.Join(strings.Fields(norm.NFKD.String(mnemonic)), " ")
+	salt := "mnemonic" + norm.NFKD.String(passphrase)
+	return pbkdf2.Key([]byte(m), []byte(salt), 2048, 64, sha512.New), nil
+}
+
+// HardenedOffset is added to each index of a derivation path,
+// as SLIP-0010 only defines hardened derivation for Ed25519.
+const HardenedOffset = 1 << 31
+
+// DeriveKey returns the Ed25519 key derived from a BIP39 seed
+// along path, following SLIP-0010.
+// Each index of the path is hardened: 44 stands for 44'.
+// An empty path returns the master key.
+func DeriveKey(seed []byte, path ...uint32) ed25519.PrivateKey {
+	mac := hmac.New(sha512.New, []byte("ed25519 seed"))
+	mac.Write(seed)
+	I := mac.Sum(nil)
+	for _, index := range path {
+		mac = hmac.New(sha512.New, I[32:])
+		mac.Write([]byte{0})
+		mac.Write(I[:32])
+		mac.Write(binary.BigEndian.AppendUint32(nil, index|HardenedOffset))
+		I = mac.Sum(nil)
+	}
+	return ed25519.NewKeyFromSeed(I[:32])
+}
+
+// NewKeyFromMnemonic recovers the Ed25519 key backed up as mnemonic,
+// with the given passphrase, derived along path (see DeriveKey).
+func NewKeyFromMnemonic(mnemonic, passphrase string, path ...uint32) (ed25519.PrivateKey, error) {
+	seed, err := Seed(mnemonic, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return DeriveKey(seed, path...), nil
+}
@@ -0,0 +1,137 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bip39
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestWordList(t *testing.T) {
+	sum := sha256.Sum256([]byte(englishTxt))
+	if got, want := hex.EncodeToString(sum[:]), "2f5eed53a4727b4bf8880d8f3f199efc90e58503646d9ff8eff3a2ed3b24dbda"; got != want {
+		t.Errorf("word list hash %s, want %s", got, want)
+	}
+	if len(wordList) != 2048 || len(wordIndex) != 2048 {
+		t.Errorf("word list has %d words, %d distinct", len(wordList), len(wordIndex))
+	}
+}
+
+// Test vectors from the BIP39 reference implementation,
+// all with passphrase "TREZOR".
+var vectors = []struct {
+	entropy, mnemonic, seed string
+}{
+	{
+		"00000000000000000000000000000000",
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+		"c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04",
+	},
+	{
+		"7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f",
+		"legal winner thank year wave sausage worth useful legal winner thank yellow",
+		"2e8905819b8723fe2c1d161860e5ee1830318dbf49a83bd451cfb8440c28bd6fa457fe1296106559a3c80937a1c1069be3a3a5bd381ee6260e8d9739fce1f607",
+	},
+	{
+		"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+		"zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo vote",
+		"dd48c104698c30cfe2b6142103248622fb7bb0ff692eebb00089b32d22484e1613912f0a5b694407be899ffd31ed3992c456cdf60f5d4564b8ba3f05a69890ad",
+	},
+}
+
+func TestVectors(t *testing.T) {
+	for _, v := range vectors {
+		entropy, _ := hex.DecodeString(v.entropy)
+		mnemonic, err := NewMnemonic(entropy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mnemonic != v.mnemonic {
+			t.Errorf("NewMnemonic(%s) = %q, want %q", v.entropy, mnemonic, v.mnemonic)
+		}
+		got, err := MnemonicToEntropy(v.mnemonic)
+		if err != nil || !bytes.Equal(got, entropy) {
+			t.Errorf("MnemonicToEntropy(%q) = %x, %v; want %s", v.mnemonic, got, err, v.entropy)
+		}
+		seed, err := Seed(v.mnemonic, "TREZOR")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hex.EncodeToString(seed) != v.seed {
+			t.Errorf("Seed(%q) = %x, want %s", v.mnemonic, seed, v.seed)
+		}
+	}
+}
+
+func TestInvalidMnemonics(t *testing.T) {
+	for _, m := range []string{
+		"",
+		"abandon abandon abandon",
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon",
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abut",
+	} {
+		if IsValid(m) {
+			t.Errorf("%q accepted", m)
+		}
+	}
+	if _, err := MnemonicToEntropy(strings.Repeat("abandon ", 11) + "abandon"); err != ErrChecksum {
+		t.Errorf("got %v, want ErrChecksum", err)
+	}
+	if !IsValid("  Abandon abandon abandon abandon abandon abandon\nabandon abandon abandon abandon abandon about ") {
+		t.Errorf("mnemonic with extra white space and capitals rejected")
+	}
+}
+
+// Test vector 1 for Ed25519 from SLIP-0010.
+func TestDeriveKey(t *testing.T) {
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	tests := []struct {
+		path      []uint32
+		priv, pub string
+	}{
+		{nil,
+			"2b4be7f19ee27bbf30c667b642d5f4aa69fd169872f8fc3059c08ebae2eb19e7",
+			"a4b2856bfec510abab89753fac1ac0e1112364e7d250545963f135f2a33188ed"},
+		{[]uint32{0},
+			"68e0fe46dfb67e368c75379acec591dad19df3cde26e63b93a8e704f1dade7a3",
+			"8c8a13df77a28f3445213a0f432fde644acaa215fc72dcdf300d5efaa85d350c"},
+	}
+	for _, tt := range tests {
+		priv := DeriveKey(seed, tt.path...)
+		if got := hex.EncodeToString(priv.Seed()); got != tt.priv {
+			t.Errorf("path %v: private key %s, want %s", tt.path, got, tt.priv)
+		}
+		if got := hex.EncodeToString(priv[32:]); got != tt.pub {
+			t.Errorf("path %v: public key %s, want %s", tt.path, got, tt.pub)
+		}
+	}
+}
+
+func TestGenerateRecover(t *testing.T) {
+	for _, bits := range []int{128, 256} {
+		mnemonic, err := GenerateMnemonic(bits, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := len(strings.Fields(mnemonic)); n != bits/32*3 {
+			t.Errorf("%d bits: %d words", bits, n)
+		}
+		k1, err := NewKeyFromMnemonic(mnemonic, "pass", 44, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		k2, _ := NewKeyFromMnemonic(mnemonic, "pass", 44, 0)
+		k3, _ := NewKeyFromMnemonic(mnemonic, "other", 44, 0)
+		if !k1.Equal(k2) || k1.Equal(k3) {
+			t.Errorf("key recovery not deterministic in mnemonic and passphrase")
+		}
+	}
+	if _, err := GenerateMnemonic(100, nil); err == nil {
+		t.Errorf("bad entropy size accepted")
+	}
+}
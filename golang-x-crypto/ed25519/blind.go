@@ -0,0 +1,135 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"crypto"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/sha3"
+
+	"test-server/golang-x-crypto/ed25519/internal/edwards25519"
+)
+
+// Key blinding derives from one long-term key pair any number of
+// blinded key pairs, one per blinding parameter, such as an epoch number.
+// Anyone knowing the long-term public key can compute the blinded public keys,
+// but blinded public keys of different parameters cannot be linked
+// to each other or to the long-term key without it.
+// The holder of the long-term private key can sign under each blinded key,
+// and the signatures verify with Verify like any other.
+//
+// The blinding factor is derived as in Appendix A.2 of the Tor v3 onion
+// service specification (rend-spec-v3), with an empty secret,
+// so that EpochParam yields the blinded keys of Tor onion services.
+
+const (
+	blindString       = "Derive temporary signing key\x00"
+	blindPrefixString = "Derive temporary signing key hash input"
+	blindBasepoint    = "(15112221349535400772501151409588531511454012693041857206046113283949847762202, " +
+		"46316835694926478169428394003475163141307993866256225615783033603165251855960)"
+)
+
+// EpochParam returns the blinding parameter of time period number period
+// of periodLength minutes, as used by Tor to blind onion service keys.
+func EpochParam(period, periodLength uint64) []byte {
+	param := []byte("key-blind")
+	param = binary.BigEndian.AppendUint64(param, period)
+	return binary.BigEndian.AppendUint64(param, periodLength)
+}
+
+// blindingFactor returns the clamped blinding factor for publicKey and param.
+func blindingFactor(publicKey []byte, param []byte) [32]byte {
+	h := sha3.New256()
+	h.Write([]byte(blindString))
+	h.Write(publicKey)
+	h.Write([]byte(blindBasepoint))
+	h.Write(param)
+	var factor [32]byte
+	h.Sum(factor[:0])
+	factor[0] &= 248
+	factor[31] &= 63
+	factor[31] |= 64
+	return factor
+}
+
+// BlindPublicKey returns the blinded form of publicKey for param.
+// It returns an error if publicKey is not a valid public key.
+func BlindPublicKey(publicKey PublicKey, param []byte) (PublicKey, error) {
+	var A edwards25519.ExtendedGroupElement
+	if !decodePoint(&A, publicKey) {
+		return nil, errors.New("ed25519: invalid public key")
+	}
+	factor := blindingFactor(publicKey, param)
+	var blinded edwards25519.ExtendedGroupElement
+	edwards25519.GeScalarMultVartime(&blinded, &factor, &A)
+	var out [32]byte
+	blinded.ToBytes(&out)
+	return PublicKey(out[:]), nil
+}
+
+// BlindedPrivateKey is the private key of a blinded key pair.
+// Unlike a PrivateKey, it has no seed:
+// it holds the blinded secret scalar and nonce prefix directly.
+type BlindedPrivateKey struct {
+	publicKey PublicKey
+	scalar    [32]byte
+	prefix    [32]byte
+}
+
+// BlindPrivateKey returns the blinded form of privateKey for param,
+// whose public key is BlindPublicKey(privateKey.Public(), param).
+// It will panic if len(privateKey) is not PrivateKeySize.
+func BlindPrivateKey(privateKey PrivateKey, param []byte) *BlindedPrivateKey {
+	if l := len(privateKey); l != PrivateKeySize {
+		panic("ed25519: bad private key length")
+	}
+	digest := sha512.Sum512(privateKey[:32])
+	var a [32]byte
+	copy(a[:], digest[:32])
+	a[0] &= 248
+	a[31] &= 63
+	a[31] |= 64
+
+	var zero [32]byte
+	factor := blindingFactor(privateKey[32:], param)
+	k := new(BlindedPrivateKey)
+	edwards25519.ScMulAdd(&k.scalar, &factor, &a, &zero)
+
+	h := sha512.New()
+	h.Write([]byte(blindPrefixString))
+	h.Write(digest[32:])
+	var prefix [64]byte
+	h.Sum(prefix[:0])
+	copy(k.prefix[:], prefix[:32])
+
+	var A edwards25519.ExtendedGroupElement
+	edwards25519.GeScalarMultBase(&A, &k.scalar)
+	var pub [32]byte
+	A.ToBytes(&pub)
+	k.publicKey = PublicKey(pub[:])
+	return k
+}
+
+// Public returns the blinded PublicKey corresponding to k.
+func (k *BlindedPrivateKey) Public() crypto.PublicKey {
+	return append(PublicKey{}, k.publicKey...)
+}
+
+// Sign signs message with k, returning a signature
+// that Verify accepts under the blinded public key.
+// rand is ignored. opts.HashFunc() must return zero:
+// only plain Ed25519 is supported.
+func (k *BlindedPrivateKey) Sign(rand io.Reader, message []byte, opts crypto.SignerOpts) ([]byte, error) {
+	if opts.HashFunc() != crypto.Hash(0) {
+		return nil, errors.New("ed25519: cannot sign hashed message with blinded key")
+	}
+	return signExpanded(&k.scalar, k.prefix[:], k.publicKey, message, domPrefixPure, "", nil), nil
+}
+
+var _ crypto.Signer = (*BlindedPrivateKey)(nil)
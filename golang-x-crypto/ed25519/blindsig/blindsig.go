@@ -0,0 +1,231 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package blindsig implements blind Schnorr signatures with Ed25519 keys:
+// a signer signs a message without learning it,
+// and cannot later link the signature to the signing session,
+// as needed for privacy-preserving token issuance.
+// The resulting signatures are ordinary Ed25519 signatures,
+// verified with ed25519.Verify.
+//
+// A signing session runs in three moves:
+//
+//	signer: commitment, err := signer.Commit()         // to user
+//	user:   req, err := blindsig.NewRequest(pub, msg, commitment, nil)
+//	        challenge := req.Challenge()               // to signer
+//	signer: response, err := signer.Respond(challenge) // to user
+//	user:   sig, err := req.Finish(response)
+//
+// Blind Schnorr signatures are only secure if the signer
+// completes each session before opening the next:
+// an attacker running many sessions concurrently can forge
+// an extra signature (the ROS attack of Benhamouda et al., 2020).
+// A Signer therefore holds at most one open session.
+package blindsig
+
+import (
+	cryptorand "crypto/rand"
+	"crypto/sha512"
+	"errors"
+	"io"
+
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/golang-x-crypto/ed25519/internal/edwards25519"
+)
+
+// Signer holds the signer's state across blind signing sessions.
+type Signer struct {
+	rand       io.Reader
+	privateKey ed25519.PrivateKey
+	nonce      *[32]byte // nonce of the open session, if any
+}
+
+// NewSigner creates a Signer signing with privateKey.
+// Session nonces are drawn from rand, or from crypto/rand if rand is nil.
+func NewSigner(privateKey ed25519.PrivateKey, rand io.Reader) *Signer {
+	if rand == nil {
+		rand = cryptorand.Reader
+	}
+	return &Signer{rand: rand, privateKey: privateKey}
+}
+
+// Commit opens a signing session, returning the commitment
+// to send to the user.
+// It returns an error if a session is already open.
+func (s *Signer) Commit() ([]byte, error) {
+	if s.nonce != nil {
+		return nil, errors.New("blindsig: signing session already open")
+	}
+	r, err := randomScalar(s.rand)
+	if err != nil {
+		return nil, err
+	}
+	s.nonce = r
+	var R edwards25519.ExtendedGroupElement
+	edwards25519.GeScalarMultBase(&R, r)
+	var Rb [32]byte
+	R.ToBytes(&Rb)
+	return Rb[:], nil
+}
+
+// Abort closes the open signing session, if any, without responding.
+func (s *Signer) Abort() {
+	s.nonce = nil
+}
+
+// Respond closes the open signing session,
+// returning the response to the user's challenge.
+func (s *Signer) Respond(challenge []byte) ([]byte, error) {
+	if s.nonce == nil {
+		return nil, errors.New("blindsig: no open signing session")
+	}
+	if !ed25519.IsCanonicalScalar(challenge) {
+		return nil, errors.New("blindsig: malformed challenge")
+	}
+	r := s.nonce
+	s.nonce = nil
+
+	digest := sha512.Sum512(s.privateKey[:32])
+	var a, c, resp [32]byte
+	copy(a[:], digest[:32])
+	a[0] &= 248
+	a[31] &= 63
+	a[31] |= 64
+	copy(c[:], challenge)
+
+	// s = r + c*a
+	edwards25519.ScMulAdd(&resp, &c, &a, r)
+	return resp[:], nil
+}
+
+// Request holds the user's state for one blind signing session.
+type Request struct {
+	publicKey  ed25519.PublicKey
+	commitment [32]byte // R, from the signer
+	alpha      [32]byte
+	challenge  [32]byte // c = c' + beta, to the signer
+	blindedR   [32]byte // R' = R + alpha*B + beta*A
+	done       bool
+}
+
+// NewRequest starts a request for a blind signature of message
+// by publicKey, given the signer's commitment.
+// Blinding factors are drawn from rand, or from crypto/rand if rand is nil.
+func NewRequest(publicKey ed25519.PublicKey, message, commitment []byte,
+	rand io.Reader) (*Request, error) {
+
+	if rand == nil {
+		rand = cryptorand.Reader
+	}
+	A, err := decode(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	R, err := decode(commitment)
+	if err != nil {
+		return nil, err
+	}
+	alpha, err := randomScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+	beta, err := randomScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+
+	// R' = R + alpha*B + beta*A
+	var aB, bA, R1 edwards25519.ExtendedGroupElement
+	edwards25519.GeScalarMultBase(&aB, alpha)
+	edwards25519.GeScalarMultVartime(&bA, beta, A)
+	R1.Add(R, &aB)
+	R1.Add(&R1, &bA)
+
+	req := &Request{publicKey: append(ed25519.PublicKey{}, publicKey...), alpha: *alpha}
+	copy(req.commitment[:], commitment)
+	R1.ToBytes(&req.blindedR)
+
+	// c = H(R' || A || M) + beta
+	h := sha512.New()
+	h.Write(req.blindedR[:])
+	h.Write(publicKey)
+	h.Write(message)
+	var wide [64]byte
+	h.Sum(wide[:0])
+	var c1 [32]byte
+	edwards25519.ScReduce(&c1, &wide)
+	edwards25519.ScMulAdd(&req.challenge, &scOne, &c1, beta)
+	return req, nil
+}
+
+// Challenge returns the blinded challenge to send to the signer.
+func (r *Request) Challenge() []byte {
+	return append([]byte{}, r.challenge[:]...)
+}
+
+// Finish checks the signer's response and unblinds it,
+// returning the Ed25519 signature of the message.
+// A Request can only be finished once.
+func (r *Request) Finish(response []byte) ([]byte, error) {
+	if r.done {
+		return nil, errors.New("blindsig: request already finished")
+	}
+	if !ed25519.IsCanonicalScalar(response) {
+		return nil, errors.New("blindsig: malformed response")
+	}
+	A, err := decode(r.publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// s*B == R + c*A
+	var s [32]byte
+	copy(s[:], response)
+	var sB, cA, R, rhs edwards25519.ExtendedGroupElement
+	edwards25519.GeScalarMultBase(&sB, &s)
+	edwards25519.GeScalarMultVartime(&cA, &r.challenge, A)
+	R.FromBytes(&r.commitment)
+	rhs.Add(&R, &cA)
+	var lhsB, rhsB [32]byte
+	sB.ToBytes(&lhsB)
+	rhs.ToBytes(&rhsB)
+	if lhsB != rhsB {
+		return nil, errors.New("blindsig: invalid response from signer")
+	}
+	r.done = true
+
+	// s' = s + alpha
+	var s1 [32]byte
+	edwards25519.ScMulAdd(&s1, &scOne, &s, &r.alpha)
+	sig := make([]byte, ed25519.SignatureSize)
+	copy(sig, r.blindedR[:])
+	copy(sig[32:], s1[:])
+	return sig, nil
+}
+
+var scOne = [32]byte{1}
+
+// randomScalar returns a uniformly random scalar read from rand.
+func randomScalar(rand io.Reader) (*[32]byte, error) {
+	var wide [64]byte
+	if _, err := io.ReadFull(rand, wide[:]); err != nil {
+		return nil, err
+	}
+	s := new([32]byte)
+	edwards25519.ScReduce(s, &wide)
+	return s, nil
+}
+
+// decode decodes a canonical point encoding,
+// rejecting points of small order.
+func decode(p []byte) (*edwards25519.ExtendedGroupElement, error) {
+	if !ed25519.IsCanonicalPoint(p) || ed25519.IsSmallOrder(p) {
+		return nil, errors.New("blindsig: invalid point")
+	}
+	var b [32]byte
+	copy(b[:], p)
+	P := new(edwards25519.ExtendedGroupElement)
+	P.FromBytes(&b)
+	return P, nil
+}
@@ -0,0 +1,71 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package blindsig
+
+import (
+	"bytes"
+	"testing"
+
+	"test-server/golang-x-crypto/ed25519"
+)
+
+func TestBlindSign(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	signer := NewSigner(priv, nil)
+	message := []byte("token 7f3a")
+
+	commitment, err := signer.Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := signer.Commit(); err == nil {
+		t.Errorf("concurrent session opened")
+	}
+	req, err := NewRequest(pub, message, commitment, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, err := signer.Respond(req.Challenge())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := req.Finish(response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ed25519.Verify(pub, message, sig) {
+		t.Fatalf("blind signature rejected")
+	}
+	// The signer saw neither the signature's R nor its s.
+	if bytes.Equal(sig[:32], commitment) || bytes.Equal(sig[32:], response) {
+		t.Errorf("signature not blinded")
+	}
+	if _, err := req.Finish(response); err == nil {
+		t.Errorf("request finished twice")
+	}
+	if _, err := signer.Respond(req.Challenge()); err == nil {
+		t.Errorf("closed session responded to")
+	}
+}
+
+func TestBlindSignBadResponse(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	signer := NewSigner(priv, nil)
+	commitment, _ := signer.Commit()
+	req, err := NewRequest(pub, []byte("message"), commitment, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, _ := signer.Respond(req.Challenge())
+	response[0] ^= 1
+	if _, err := req.Finish(response); err == nil {
+		t.Errorf("corrupted response accepted")
+	}
+
+	signer.Abort()
+	if _, err := signer.Commit(); err != nil {
+		t.Errorf("new session after Abort: %v", err)
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cosi
+
+import (
+	"encoding/asn1"
+	"errors"
+
+	"test-server/golang-x-crypto/ed25519"
+)
+
+// DER envelope for collective signatures,
+// for embedding in PKI artifacts and parsing by non-Go stacks:
+//
+//	CollectiveSignature ::= SEQUENCE {
+//	    version           INTEGER { v1(1) },
+//	    r                 OCTET STRING (SIZE(32)),
+//	    s                 OCTET STRING (SIZE(32)),
+//	    mask              OCTET STRING,
+//	    groupFingerprint  [0] IMPLICIT OCTET STRING OPTIONAL
+//	}
+//
+// The mask is carried verbatim in this package's layout
+// (little-endian bit order, a set bit marking an absent cosigner),
+// and is empty if all cosigners signed.
+// The optional group fingerprint is the value of Cosigners.Fingerprint.
+
+const derVersion = 1
+
+type derSignature struct {
+	Version     int
+	R           []byte
+	S           []byte
+	Mask        []byte
+	Fingerprint []byte `asn1:"optional,tag:0"`
+}
+
+// MarshalDER encodes a collective signature in the R || s [|| mask] layout
+// as a DER CollectiveSignature structure.
+// The group fingerprint is optional and may be nil.
+func MarshalDER(sig, fingerprint []byte) ([]byte, error) {
+	if len(sig) < ed25519.SignatureSize {
+		return nil, errors.New("cosi: signature too short")
+	}
+	if fingerprint != nil && len(fingerprint) != FingerprintSize {
+		return nil, errors.New("cosi: bad group fingerprint length")
+	}
+	return asn1.Marshal(derSignature{
+		Version:     derVersion,
+		R:           sig[:32],
+		S:           sig[32:64],
+		Mask:        append([]byte{}, sig[64:]...),
+		Fingerprint: fingerprint,
+	})
+}
+
+// ParseDER decodes a DER CollectiveSignature structure,
+// returning the signature in the R || s [|| mask] layout
+// and the group fingerprint, which is nil if absent.
+func ParseDER(der []byte) (sig, fingerprint []byte, err error) {
+	var ds derSignature
+	rest, err := asn1.Unmarshal(der, &ds)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(rest) != 0 {
+		return nil, nil, errors.New("cosi: trailing data after DER signature")
+	}
+	if ds.Version != derVersion {
+		return nil, nil, errors.New("cosi: unsupported DER signature version")
+	}
+	if len(ds.R) != 32 || len(ds.S) != 32 {
+		return nil, nil, errors.New("cosi: bad DER signature component length")
+	}
+	if ds.Fingerprint != nil && len(ds.Fingerprint) != FingerprintSize {
+		return nil, nil, errors.New("cosi: bad group fingerprint length")
+	}
+	sig = make([]byte, 0, ed25519.SignatureSize+len(ds.Mask))
+	sig = append(append(append(sig, ds.R...), ds.S...), ds.Mask...)
+	return sig, ds.Fingerprint, nil
+}
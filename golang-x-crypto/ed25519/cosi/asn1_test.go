@@ -0,0 +1,60 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cosi
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDERRoundTrip(t *testing.T) {
+	n := 9
+	genKeys(n)
+	parts := make([]*Participant, n)
+	for i := range parts {
+		parts[i] = NewParticipant(priKeys[i], nil)
+	}
+	cos := NewCosigners(pubKeys[:n], nil)
+	sig := runSession(t, cos, parts, 5, map[int]bool{8: true})
+	fp := cos.Fingerprint()
+
+	for _, tc := range []struct {
+		sig, fp []byte
+	}{
+		{sig, fp},
+		{sig, nil},
+		{sig[:64], nil},
+	} {
+		der, err := MarshalDER(tc.sig, tc.fp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gotSig, gotFP, err := ParseDER(der)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(gotSig, tc.sig) || !bytes.Equal(gotFP, tc.fp) {
+			t.Errorf("DER envelope does not round-trip")
+		}
+	}
+
+	verifier := NewCosigners(pubKeys[:n], nil)
+	verifier.SetPolicy(ThresholdPolicy(n - 1))
+	der, _ := MarshalDER(sig, fp)
+	gotSig, gotFP, _ := ParseDER(der)
+	if !bytes.Equal(gotFP, verifier.Fingerprint()) {
+		t.Errorf("group fingerprint mismatch")
+	}
+	if !verifier.Verify(rightMessage, gotSig) {
+		t.Errorf("valid signature rejected after DER round trip")
+	}
+
+	if bytes.Equal(NewCosigners(pubKeys[1:n], nil).Fingerprint(), fp) {
+		t.Errorf("different groups share a fingerprint")
+	}
+	if _, _, err := ParseDER(append(der, 0)); err == nil {
+		t.Errorf("DER with trailing data accepted")
+	}
+}
@@ -0,0 +1,350 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package audit keeps a tamper-evident record
+// of every signature part a cosigner has produced,
+// so its operator can later prove or disprove
+// what the node did and did not co-sign.
+//
+// Each Entry names the session and the SHA-256 digest of the message
+// and includes the hash of the previous entry,
+// forming a hash chain over the whole history.
+// At regular intervals the Trail signs the head of the chain
+// with the cosigner's own key, producing a Checkpoint;
+// once a checkpoint has been published or handed to a third party,
+// the entries it covers can no longer be rewritten or dropped unnoticed.
+//
+// A Trail satisfies protocol.Auditor
+// and is installed on a cosigner through protocol.Service.Auditor.
+// If a Trail is given a Writer, every entry and checkpoint
+// is also appended to it as a JSON line;
+// Load reads such a file back and checks it.
+package audit
+
+import (
+	"bufio"
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"test-server/golang-x-crypto/ed25519"
+)
+
+// DefaultCheckpointEvery is the number of entries between checkpoints
+// when Trail.CheckpointEvery is zero.
+const DefaultCheckpointEvery = 100
+
+const (
+	entryDomain      = "cosi-audit-entry-v1"
+	checkpointDomain = "cosi-audit-checkpoint-v1"
+)
+
+// Entry records one signature part produced by the cosigner.
+type Entry struct {
+	Seq     uint64    `json:"seq"`     // position in the trail, from 0
+	Time    time.Time `json:"time"`    // when the part was produced
+	Session []byte    `json:"session"` // protocol session identifier
+	Digest  [32]byte  `json:"digest"`  // SHA-256 of the co-signed message
+	Prev    [32]byte  `json:"prev"`    // Hash of the previous entry; zero for the first
+}
+
+// Hash returns the chain hash of the entry.
+func (e *Entry) Hash() [32]byte {
+	h := sha256.New()
+	h.Write([]byte(entryDomain))
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], e.Seq)
+	binary.BigEndian.PutUint64(b[8:], uint64(e.Time.UnixNano()))
+	h.Write(b[:])
+	h.Write(binary.BigEndian.AppendUint32(nil, uint32(len(e.Session))))
+	h.Write(e.Session)
+	h.Write(e.Digest[:])
+	h.Write(e.Prev[:])
+	var sum [32]byte
+	h.Sum(sum[:0])
+	return sum
+}
+
+// Checkpoint is the cosigner's signature
+// on the first Size entries of its trail.
+type Checkpoint struct {
+	Size      uint64    `json:"size"`
+	Head      [32]byte  `json:"head"` // Hash of entry Size-1; zero if Size is 0
+	Time      time.Time `json:"time"`
+	Signature []byte    `json:"signature"`
+}
+
+// Message returns the message signed for the checkpoint.
+func (c *Checkpoint) Message() []byte {
+	b := make([]byte, 0, len(checkpointDomain)+8+32+8)
+	b = append(b, checkpointDomain...)
+	b = binary.BigEndian.AppendUint64(b, c.Size)
+	b = append(b, c.Head[:]...)
+	return binary.BigEndian.AppendUint64(b, uint64(c.Time.UnixNano()))
+}
+
+// Verify checks the checkpoint's signature.
+// It reports false, rather than panicking, for a malformed publicKey.
+func (c *Checkpoint) Verify(publicKey ed25519.PublicKey) bool {
+	return ed25519.VerifyErr(publicKey, c.Message(), c.Signature) == nil
+}
+
+// Trail is a cosigner's audit trail.
+// It is safe for concurrent use.
+type Trail struct {
+	// CheckpointEvery is the number of entries after which
+	// a checkpoint is signed automatically.
+	// If zero, DefaultCheckpointEvery is used.
+	CheckpointEvery int
+
+	key crypto.Signer
+	w   io.Writer
+
+	mu          sync.Mutex
+	entries     []Entry
+	checkpoints []Checkpoint
+	head        [32]byte
+	err         error // sticky write error
+}
+
+// New creates an empty trail signed with signer,
+// which should hold the cosigner's own key:
+// an ed25519.PrivateKey, or an ed25519.RemoteSigner
+// if the key lives in a token or KMS.
+// If w is non-nil, every entry and checkpoint is appended to it.
+func New(signer crypto.Signer, w io.Writer) *Trail {
+	return &Trail{key: signer, w: w}
+}
+
+// record is one line of the trail's output.
+type record struct {
+	Entry      *Entry      `json:"entry,omitempty"`
+	Checkpoint *Checkpoint `json:"checkpoint,omitempty"`
+}
+
+// RecordPart appends an entry for a signature part
+// produced in the given session over message.
+// It implements protocol.Auditor.
+// Once writing to the trail's Writer has failed,
+// RecordPart keeps failing with the same error,
+// so the cosigner stops releasing parts it cannot account for.
+func (t *Trail) RecordPart(sessionID, message []byte) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.err != nil {
+		return t.err
+	}
+	e := Entry{
+		Seq:     uint64(len(t.entries)),
+		Time:    time.Now().UTC(),
+		Session: append([]byte{}, sessionID...),
+		Digest:  sha256.Sum256(message),
+		Prev:    t.head,
+	}
+	if err := t.writeLocked(record{Entry: &e}); err != nil {
+		return err
+	}
+	t.entries = append(t.entries, e)
+	t.head = e.Hash()
+
+	every := t.CheckpointEvery
+	if every <= 0 {
+		every = DefaultCheckpointEvery
+	}
+	if len(t.entries)%every == 0 {
+		_, err := t.checkpointLocked()
+		return err
+	}
+	return nil
+}
+
+// Checkpoint signs the current head of the trail.
+func (t *Trail) Checkpoint() (*Checkpoint, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.err != nil {
+		return nil, t.err
+	}
+	return t.checkpointLocked()
+}
+
+func (t *Trail) checkpointLocked() (*Checkpoint, error) {
+	c := Checkpoint{
+		Size: uint64(len(t.entries)),
+		Head: t.head,
+		Time: time.Now().UTC(),
+	}
+	sig, err := ed25519.SignContext(context.Background(), t.key, c.Message())
+	if err != nil {
+		return nil, err
+	}
+	c.Signature = sig
+	if err := t.writeLocked(record{Checkpoint: &c}); err != nil {
+		return nil, err
+	}
+	t.checkpoints = append(t.checkpoints, c)
+	return &c, nil
+}
+
+func (t *Trail) writeLocked(r record) error {
+	if t.w == nil {
+		return nil
+	}
+	line, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if _, err := t.w.Write(append(line, '\n')); err != nil {
+		t.err = err
+		return err
+	}
+	return nil
+}
+
+// Run signs a checkpoint every interval
+// whenever new entries have been recorded since the last one,
+// until ctx is done.
+func (t *Trail) Run(ctx context.Context, interval time.Duration) error {
+	tick := time.NewTicker(interval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-tick.C:
+		}
+		t.mu.Lock()
+		var err error
+		if n := len(t.checkpoints); n == 0 || t.checkpoints[n-1].Size < uint64(len(t.entries)) {
+			if t.err == nil {
+				_, err = t.checkpointLocked()
+			} else {
+				err = t.err
+			}
+		}
+		t.mu.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Size returns the number of entries in the trail.
+func (t *Trail) Size() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.entries)
+}
+
+// Entries returns the entries with sequence numbers in [from, to).
+func (t *Trail) Entries(from, to uint64) []Entry {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if to > uint64(len(t.entries)) {
+		to = uint64(len(t.entries))
+	}
+	if from >= to {
+		return nil
+	}
+	return append([]Entry{}, t.entries[from:to]...)
+}
+
+// Lookup returns the entries for signature parts over the message
+// with the given SHA-256 digest.
+// An empty result means the cosigner never co-signed it,
+// as far as the trail's checkpoints can attest.
+func (t *Trail) Lookup(digest [32]byte) []Entry {
+	return t.filter(func(e *Entry) bool { return e.Digest == digest })
+}
+
+// Between returns the entries recorded in the time range [from, to).
+func (t *Trail) Between(from, to time.Time) []Entry {
+	return t.filter(func(e *Entry) bool {
+		return !e.Time.Before(from) && e.Time.Before(to)
+	})
+}
+
+func (t *Trail) filter(keep func(*Entry) bool) []Entry {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var out []Entry
+	for i := range t.entries {
+		if keep(&t.entries[i]) {
+			out = append(out, t.entries[i])
+		}
+	}
+	return out
+}
+
+// Checkpoints returns all checkpoints signed so far.
+func (t *Trail) Checkpoints() []Checkpoint {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Checkpoint{}, t.checkpoints...)
+}
+
+// Verify checks that entries form an unbroken hash chain from the start
+// and that every checkpoint is signed by publicKey
+// and agrees with the chain.
+// Entries after the last checkpoint are chained but not yet attested.
+func Verify(publicKey ed25519.PublicKey, entries []Entry, checkpoints []Checkpoint) error {
+	heads := make([][32]byte, len(entries)+1)
+	for i := range entries {
+		e := &entries[i]
+		if e.Seq != uint64(i) {
+			return errors.New("audit: entry " + strconv.Itoa(i) + " out of sequence")
+		}
+		if e.Prev != heads[i] {
+			return errors.New("audit: hash chain broken at entry " + strconv.Itoa(i))
+		}
+		heads[i+1] = e.Hash()
+	}
+	for i := range checkpoints {
+		c := &checkpoints[i]
+		if !c.Verify(publicKey) {
+			return errors.New("audit: bad signature on checkpoint " + strconv.Itoa(i))
+		}
+		if c.Size > uint64(len(entries)) || c.Head != heads[c.Size] {
+			return errors.New("audit: checkpoint " + strconv.Itoa(i) + " does not match the entries")
+		}
+	}
+	return nil
+}
+
+// Load reads a trail written by a Trail's Writer,
+// checks it with Verify, and returns its entries and checkpoints.
+func Load(r io.Reader, publicKey ed25519.PublicKey) ([]Entry, []Checkpoint, error) {
+	var entries []Entry
+	var checkpoints []Checkpoint
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		var rec record
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			return nil, nil, err
+		}
+		switch {
+		case rec.Entry != nil:
+			entries = append(entries, *rec.Entry)
+		case rec.Checkpoint != nil:
+			checkpoints = append(checkpoints, *rec.Checkpoint)
+		default:
+			return nil, nil, errors.New("audit: empty record")
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, nil, err
+	}
+	if err := Verify(publicKey, entries, checkpoints); err != nil {
+		return nil, nil, err
+	}
+	return entries, checkpoints, nil
+}
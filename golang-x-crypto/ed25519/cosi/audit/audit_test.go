@@ -0,0 +1,92 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package audit_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"testing"
+
+	"test-server/golang-x-crypto/ed25519/cosi/audit"
+	"test-server/golang-x-crypto/ed25519/cosi/cositest"
+)
+
+func TestTrail(t *testing.T) {
+	nw, err := cositest.NewNetwork(3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nw.Close()
+
+	var buf bytes.Buffer
+	trail := audit.New(nw.PrivateKeys[1], &buf)
+	trail.CheckpointEvery = 2
+	nw.Services[1].Auditor = trail
+
+	ctx := context.Background()
+	msgs := [][]byte{[]byte("one"), []byte("two"), []byte("three")}
+	for _, m := range msgs {
+		if _, err := nw.Sign(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := trail.Checkpoint(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := trail.Lookup(sha256.Sum256([]byte("two"))); len(got) != 1 || got[0].Seq != 1 {
+		t.Errorf("lookup: got %v", got)
+	}
+	if got := trail.Lookup(sha256.Sum256([]byte("four"))); len(got) != 0 {
+		t.Errorf("lookup of unsigned message: got %v", got)
+	}
+	if cps := trail.Checkpoints(); len(cps) != 2 || cps[0].Size != 2 || cps[1].Size != 3 {
+		t.Errorf("checkpoints: %+v", cps)
+	}
+
+	pub := nw.PublicKeys[1]
+	entries, cps, err := audit.Load(bytes.NewReader(buf.Bytes()), pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 || len(cps) != 2 {
+		t.Fatalf("loaded %d entries, %d checkpoints", len(entries), len(cps))
+	}
+
+	// dropping an entry from the record is detected
+	if audit.Verify(pub, append(entries[:1:1], entries[2:]...), cps) == nil {
+		t.Error("missing entry not detected")
+	}
+	// so is rewriting what was signed
+	entries[0].Digest[0] ^= 1
+	if audit.Verify(pub, entries, cps) == nil {
+		t.Error("altered entry not detected")
+	}
+	entries[0].Digest[0] ^= 1
+	if audit.Verify(nw.PublicKeys[0], entries, cps) == nil {
+		t.Error("checkpoint under wrong key accepted")
+	}
+	if audit.Verify(pub[:31], entries, cps) == nil {
+		t.Error("checkpoint under truncated key accepted")
+	}
+}
+
+type failWriter struct{}
+
+func (failWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestTrailFailsClosed(t *testing.T) {
+	nw, err := cositest.NewNetwork(2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nw.Close()
+	nw.Services[0].Auditor = audit.New(nw.PrivateKeys[0], failWriter{})
+	if _, err := nw.Sign(context.Background(), []byte("message")); err == nil {
+		t.Error("round succeeded although the audit trail could not be written")
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package beacon implements a public randomness beacon
+// built from collective signatures.
+//
+// In each round the group collectively signs the round number
+// together with the previous round's output,
+// and the SHA-256 hash of the resulting signature
+// is published as that round's randomness.
+// Chaining every round to its predecessor
+// lets anyone verify a whole sequence of beacon outputs
+// back to a public genesis seed.
+//
+// Note that Schnorr-style collective signatures are not unique:
+// the leader and cosigners choose fresh commits for each signature,
+// so a party able to abort and restart a round
+// may get several candidate outputs to choose from.
+// The beacon output is unpredictable to outsiders,
+// but applications needing bias-resistance against
+// a colluding leader must combine it with other measures.
+package beacon
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"test-server/golang-x-crypto/ed25519/cosi"
+	"test-server/golang-x-crypto/ed25519/cosi/protocol"
+)
+
+const beaconDomain = "cosi-beacon-v1"
+
+// Signer runs collective signing rounds;
+// it is satisfied by *protocol.Leader.
+type Signer interface {
+	Sign(ctx context.Context, message []byte) (*protocol.Result, error)
+}
+
+// Round is one output of the beacon.
+type Round struct {
+	Number    uint64 // round number, starting at 1
+	Prev      []byte // randomness of the previous round, or GenesisRandomness for round 1
+	Signature []byte // collective signature on Message
+}
+
+// Message returns the message collectively signed in this round.
+func (r *Round) Message() []byte {
+	b := make([]byte, 0, len(beaconDomain)+8+len(r.Prev))
+	b = append(b, beaconDomain...)
+	b = binary.BigEndian.AppendUint64(b, r.Number)
+	return append(b, r.Prev...)
+}
+
+// Randomness returns the round's public random output:
+// the SHA-256 hash of its collective signature.
+func (r *Round) Randomness() []byte {
+	sum := sha256.Sum256(r.Signature)
+	return sum[:]
+}
+
+// GenesisRandomness returns the value chained into round 1
+// for a beacon started from the given public genesis seed.
+func GenesisRandomness(genesis []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte(beaconDomain))
+	h.Write(genesis)
+	return h.Sum(nil)
+}
+
+// Beacon produces successive rounds of randomness.
+// It is safe for concurrent use.
+type Beacon struct {
+	signer Signer
+
+	mu   sync.Mutex
+	next uint64
+	prev []byte
+}
+
+// New creates a beacon starting at round 1
+// from the given public genesis seed.
+func New(signer Signer, genesis []byte) *Beacon {
+	return &Beacon{signer: signer, next: 1, prev: GenesisRandomness(genesis)}
+}
+
+// Next runs the next round of the beacon.
+// Rounds are produced strictly in sequence;
+// a failed round is retried by the next call.
+func (b *Beacon) Next(ctx context.Context) (*Round, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	r := &Round{Number: b.next, Prev: b.prev}
+	res, err := b.signer.Sign(ctx, r.Message())
+	if err != nil {
+		return nil, err
+	}
+	r.Signature = res.Signature
+	b.next++
+	b.prev = r.Randomness()
+	return r, nil
+}
+
+// Run produces a round every interval until ctx is done,
+// passing each one to publish, then returns ctx.Err().
+// Failed rounds are retried at the next tick.
+func (b *Beacon) Run(ctx context.Context, interval time.Duration, publish func(*Round)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if r, err := b.Next(ctx); err == nil {
+				publish(r)
+			}
+		}
+	}
+}
+
+// VerifyRound checks a single round against the randomness
+// of its predecessor, using the cosigner group's current policy.
+func VerifyRound(cosigners *cosi.Cosigners, prev []byte, r *Round) bool {
+	return subtle.ConstantTimeCompare(r.Prev, prev) == 1 &&
+		cosigners.Verify(r.Message(), r.Signature)
+}
+
+// VerifyChain checks a consecutive sequence of rounds
+// starting at round 1 of a beacon with the given genesis seed.
+// It returns an error identifying the first invalid round.
+func VerifyChain(cosigners *cosi.Cosigners, genesis []byte, rounds []*Round) error {
+	prev := GenesisRandomness(genesis)
+	for i, r := range rounds {
+		if r.Number != uint64(i+1) {
+			return errors.New("beacon: round " + strconv.FormatUint(r.Number, 10) +
+				" out of sequence")
+		}
+		if !VerifyRound(cosigners, prev, r) {
+			return errors.New("beacon: invalid round " + strconv.FormatUint(r.Number, 10))
+		}
+		prev = r.Randomness()
+	}
+	return nil
+}
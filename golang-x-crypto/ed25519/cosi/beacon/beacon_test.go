@@ -0,0 +1,52 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package beacon
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"test-server/golang-x-crypto/ed25519/cosi/cositest"
+)
+
+func TestBeaconChain(t *testing.T) {
+	nw, err := cositest.NewNetwork(4, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nw.Close()
+
+	genesis := []byte("genesis")
+	b := New(nw.Leader, genesis)
+	var rounds []*Round
+	for i := 0; i < 4; i++ {
+		r, err := b.Next(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		rounds = append(rounds, r)
+	}
+
+	cos := nw.Cosigners()
+	if err := VerifyChain(cos, genesis, rounds); err != nil {
+		t.Errorf("valid chain rejected: %v", err)
+	}
+	if bytes.Equal(rounds[1].Randomness(), rounds[2].Randomness()) {
+		t.Errorf("repeated beacon output")
+	}
+	if err := VerifyChain(cos, []byte("other"), rounds); err == nil {
+		t.Errorf("chain from wrong genesis accepted")
+	}
+	if err := VerifyChain(cos, genesis, append(rounds[:1:1], rounds[2:]...)); err == nil {
+		t.Errorf("chain with missing round accepted")
+	}
+
+	forged := *rounds[2]
+	forged.Prev = rounds[0].Randomness()
+	if VerifyRound(cos, rounds[1].Randomness(), &forged) {
+		t.Errorf("round with wrong predecessor accepted")
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cosi
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+
+	"test-server/golang-x-crypto/ed25519"
+)
+
+const bindingDomain = "cosi-bound-session-v1"
+
+// ErrBoundMessage is returned by Participant.Cosign
+// for a message that belongs to a bound round (see IsBoundMessage).
+var ErrBoundMessage = errors.New("cosi: unbound round over a bound-session message")
+
+// Binding ties a signing round to its session and its leader.
+//
+// Every message signed in a bound round starts with a domain label
+// that unbound rounds refuse to sign (see IsBoundMessage),
+// so a leader cannot forge a bound signature for another session or leader
+// by running an unbound round over Binding.Message.
+//
+// In a bound round the cosigners sign not the message itself
+// but Binding.Message(message), which prefixes the message
+// with the session identifier and the leader's identity.
+// The challenge hash then depends on both,
+// so a signature part produced in one round
+// is useless in a round with another session or leader,
+// and a cosigner refuses to produce a part for any binding
+// other than the one it committed under
+// (see Participant.CommitBound).
+// Verifiers of a bound signature need the binding as well (see VerifyBound).
+type Binding struct {
+	SessionID []byte
+	Leader    []byte
+}
+
+// Message returns the message actually signed in a round with this binding:
+// a domain label, the length-prefixed session ID and leader identity,
+// and the message.
+func (b Binding) Message(message []byte) []byte {
+	m := make([]byte, 0, len(bindingDomain)+2*binary.MaxVarintLen64+
+		len(b.SessionID)+len(b.Leader)+len(message))
+	m = append(m, bindingDomain...)
+	m = binary.AppendUvarint(m, uint64(len(b.SessionID)))
+	m = append(m, b.SessionID...)
+	m = binary.AppendUvarint(m, uint64(len(b.Leader)))
+	m = append(m, b.Leader...)
+	return append(m, message...)
+}
+
+// IsBoundMessage reports whether message starts with the domain label
+// of Binding.Message, and so may only be signed in a bound round.
+func IsBoundMessage(message []byte) bool {
+	return bytes.HasPrefix(message, []byte(bindingDomain))
+}
+
+// Equal reports whether b and o bind the same session and leader.
+func (b Binding) Equal(o Binding) bool {
+	return bytes.Equal(b.SessionID, o.SessionID) && bytes.Equal(b.Leader, o.Leader)
+}
+
+// VerifyBound checks a collective signature produced in a bound round
+// (see Binding), as Verify does for an unbound one.
+func VerifyBound(publicKeys []ed25519.PublicKey, policy Policy,
+	binding Binding, message, sig []byte) bool {
+
+	return Verify(publicKeys, policy, binding.Message(message), sig)
+}
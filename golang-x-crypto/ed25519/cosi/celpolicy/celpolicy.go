@@ -0,0 +1,167 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package celpolicy lets operators express a cosigner's approval rules
+// as CEL expressions (https://cel.dev),
+// so the rules can be changed without rebuilding the cosigner.
+//
+// A Policy is a protocol.Validator:
+// the cosigner evaluates its expression for every message
+// it is asked to sign, and commits only if the result is true.
+// The expression sees the following variables:
+//
+//	message   bytes                      the message itself
+//	size      int                        len(message)
+//	digest    string                     hex SHA-256 of the message
+//	metadata  map(string, string)        the request's protocol.Metadata
+//	leader    string                     the requesting leader's identity
+//	now       google.protobuf.Timestamp  the time of evaluation
+//
+// For example, to accept only small JSON messages from leader "a"
+// during office hours in Seoul:
+//
+//	leader == "a" && size <= 4096 &&
+//	metadata["content-type"] == "application/json" &&
+//	now.getHours("Asia/Seoul") >= 9 && now.getHours("Asia/Seoul") < 18
+//
+// Indexing a metadata key that is absent is an evaluation error,
+// which refuses the message; use `"key" in metadata` to test for a key.
+package celpolicy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/cel-go/cel"
+
+	"test-server/golang-x-crypto/ed25519/cosi/protocol"
+)
+
+// MaxCost bounds the evaluation cost of an expression,
+// in CEL's abstract cost units,
+// so a careless rule cannot stall the cosigner.
+const MaxCost = 100000
+
+var env *cel.Env
+
+func init() {
+	var err error
+	env, err = cel.NewEnv(
+		cel.Variable("message", cel.BytesType),
+		cel.Variable("size", cel.IntType),
+		cel.Variable("digest", cel.StringType),
+		cel.Variable("metadata", cel.MapType(cel.StringType, cel.StringType)),
+		cel.Variable("leader", cel.StringType),
+		cel.Variable("now", cel.TimestampType),
+	)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Rule is a compiled approval rule.
+type Rule struct {
+	source string
+	prg    cel.Program
+}
+
+// Compile parses and type-checks a CEL expression,
+// which must evaluate to a bool.
+func Compile(expr string) (*Rule, error) {
+	ast, iss := env.Compile(expr)
+	if iss.Err() != nil {
+		return nil, fmt.Errorf("celpolicy: %w", iss.Err())
+	}
+	if ast.OutputType() != cel.BoolType {
+		return nil, errors.New("celpolicy: expression must be of type bool, not " +
+			ast.OutputType().String())
+	}
+	prg, err := env.Program(ast, cel.CostLimit(MaxCost))
+	if err != nil {
+		return nil, fmt.Errorf("celpolicy: %w", err)
+	}
+	return &Rule{source: expr, prg: prg}, nil
+}
+
+// String returns the rule's source expression.
+func (r *Rule) String() string { return r.source }
+
+// Eval reports whether the rule approves a message.
+func (r *Rule) Eval(ctx context.Context, message []byte, md protocol.Metadata,
+	leader string, now time.Time) (bool, error) {
+
+	digest := sha256.Sum256(message)
+	if md == nil {
+		md = protocol.Metadata{}
+	}
+	out, _, err := r.prg.ContextEval(ctx, map[string]any{
+		"message":  message,
+		"size":     len(message),
+		"digest":   hex.EncodeToString(digest[:]),
+		"metadata": map[string]string(md),
+		"leader":   leader,
+		"now":      now,
+	})
+	if err != nil {
+		return false, err
+	}
+	ok, isBool := out.Value().(bool)
+	return ok && isBool, nil
+}
+
+// Policy is a protocol.Validator enforcing the current Rule.
+// The rule may be replaced at any time with Set or SetRule,
+// e.g. when the operator edits the cosigner's configuration;
+// requests already being validated finish under the old rule.
+type Policy struct {
+	rule atomic.Pointer[Rule]
+
+	now func() time.Time
+}
+
+// New returns a Policy enforcing the given expression.
+func New(expr string) (*Policy, error) {
+	r, err := Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &Policy{now: time.Now}
+	p.rule.Store(r)
+	return p, nil
+}
+
+// Set compiles expr and, if it is valid, makes it the policy's rule.
+// On error the previous rule stays in force.
+func (p *Policy) Set(expr string) error {
+	r, err := Compile(expr)
+	if err != nil {
+		return err
+	}
+	p.SetRule(r)
+	return nil
+}
+
+// SetRule makes r the policy's rule.
+func (p *Policy) SetRule(r *Rule) { p.rule.Store(r) }
+
+// Rule returns the rule currently in force.
+func (p *Policy) Rule() *Rule { return p.rule.Load() }
+
+// Validate implements protocol.Validator.
+func (p *Policy) Validate(ctx context.Context, message []byte, md protocol.Metadata) error {
+	r := p.rule.Load()
+	ok, err := r.Eval(ctx, message, md, protocol.LeaderFromContext(ctx), p.now())
+	if err != nil {
+		return fmt.Errorf("celpolicy: evaluating %q: %w", r.source, err)
+	}
+	if !ok {
+		return fmt.Errorf("celpolicy: denied by %q", r.source)
+	}
+	return nil
+}
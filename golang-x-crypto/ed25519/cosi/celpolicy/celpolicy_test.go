@@ -0,0 +1,82 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package celpolicy
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"test-server/golang-x-crypto/ed25519/cosi/cositest"
+	"test-server/golang-x-crypto/ed25519/cosi/protocol"
+)
+
+func TestCompile(t *testing.T) {
+	for _, expr := range []string{
+		`size +`,       // syntax
+		`size`,         // not a bool
+		`unknown == 1`, // undeclared variable
+		`leader == 1`,  // type mismatch
+	} {
+		if _, err := Compile(expr); err == nil {
+			t.Errorf("Compile(%q) succeeded", expr)
+		}
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	p, err := New(`leader == "a" && size <= 8 &&
+		metadata["content-type"] == "text/plain" &&
+		now.getHours("UTC") >= 9 && now.getHours("UTC") < 18`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.now = func() time.Time { return time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC) }
+
+	nw, err := cositest.NewNetwork(3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nw.Close()
+	for _, s := range nw.Services {
+		s.Validator = p
+	}
+	nw.Leader.ID = "a"
+
+	ctx := protocol.WithMetadata(context.Background(),
+		protocol.Metadata{"content-type": "text/plain"})
+	if _, err := nw.Sign(ctx, []byte("ok")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := nw.Sign(ctx, []byte("too long a message")); err == nil {
+		t.Error("oversized message signed")
+	}
+	if _, err := nw.Sign(context.Background(), []byte("ok")); err == nil {
+		t.Error("message without metadata signed")
+	}
+
+	p.now = func() time.Time { return time.Date(2024, 1, 1, 20, 0, 0, 0, time.UTC) }
+	if _, err := nw.Sign(ctx, []byte("ok")); err == nil {
+		t.Error("message signed out of hours")
+	}
+
+	// a bad update leaves the old rule in force
+	if err := p.Set(`size >`); err == nil {
+		t.Error("invalid rule accepted")
+	}
+	if err := p.Set(`digest == "` +
+		`2689367b205c16ce32ed4200942b8b8b1e262dfc70d9bc9fbc77c49699a4f1df"`); err != nil {
+		t.Fatal(err)
+	}
+	err = p.Validate(ctx, []byte("ok"), nil)
+	if err != nil {
+		t.Errorf("digest rule: %v", err)
+	}
+	_, err = nw.Services[0].Commit(ctx, &protocol.CommitRequest{SessionID: []byte("s"), Message: []byte("no")})
+	if !errors.Is(err, protocol.ErrRejected) {
+		t.Errorf("got %v, want rejection", err)
+	}
+}
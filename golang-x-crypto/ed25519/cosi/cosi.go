@@ -245,6 +245,9 @@
 package cosi
 
 import (
+	"strconv"
+	"strings"
+
 	//"golang.org/x/crypto/ed25519"
 	//"golang.org/x/crypto/ed25519/internal/edwards25519"
 	"test-server/golang-x-crypto/ed25519"
@@ -285,6 +288,9 @@ type Cosigners struct {
 
 	// cosigner-presence policy for checking signatures
 	policy Policy
+
+	// external encoding of participation masks
+	format MaskFormat
 }
 
 // NewCosigners creates a new Cosigners object
@@ -299,6 +305,9 @@ type Cosigners struct {
 //
 // The mask parameter may be nil to enable all participants initially,
 // and otherwise is an initial participation bitmask as defined in SetMask.
+//
+// NewCosigners returns nil if any of the public keys is invalid;
+// use NewCosignersErr to find out which.
 func NewCosigners(publicKeys []ed25519.PublicKey, mask []byte) *Cosigners {
 	/* var publicKeyBytes [32]byte
 	cos := &Cosigners{}
@@ -320,7 +329,31 @@ func NewCosigners(publicKeys []ed25519.PublicKey, mask []byte) *Cosigners {
 
 	cos.policy = fullPolicy{}
 	return cos */
-	var pkBytes [32]byte
+	cos, err := NewCosignersErr(publicKeys, mask)
+	if err != nil {
+		return nil
+	}
+	return cos
+}
+
+// InvalidKeyError reports a public key rejected by NewCosignersErr
+// or NewCosignersStrict.
+type InvalidKeyError struct {
+	Index  int    // position of the offending key in the list
+	Reason string // why the key was rejected
+	Err    error  // underlying ed25519 error, if any
+}
+
+func (e *InvalidKeyError) Error() string {
+	return "cosi: invalid public key " + strconv.Itoa(e.Index) + ": " + e.Reason
+}
+
+func (e *InvalidKeyError) Unwrap() error { return e.Err }
+
+// NewCosignersErr is like NewCosigners,
+// but returns an *InvalidKeyError identifying the first unusable public key
+// instead of returning nil.
+func NewCosignersErr(publicKeys []ed25519.PublicKey, mask []byte) (*Cosigners, error) {
 	cos := &Cosigners{
 		keys:   make([]edwards25519.ExtendedGroupElement, len(publicKeys)),
 		mask:   make([]byte, (len(publicKeys)+7)>>3), // 0 == Enabled
@@ -329,18 +362,49 @@ func NewCosigners(publicKeys []ed25519.PublicKey, mask []byte) *Cosigners {
 
 	cos.aggr.Zero() // 집계키를 에드워즈 군의 단위원으로 초기화
 
-	for i, pk := range publicKeys {
-		copy(pkBytes[:], pk)
-		if !cos.keys[i].FromBytes(&pkBytes) {
-			return nil // invalid public key
-		}
+	if err := decodeKeys(cos.keys, publicKeys); err != nil {
+		return nil, err
+	}
+	for i := range cos.keys {
 		cos.aggr.Add(&cos.aggr, &cos.keys[i])
 	}
 
 	if mask != nil {
 		cos.SetMask(mask)
 	}
-	return cos
+	return cos, nil
+}
+
+// NewCosignersStrict is like NewCosignersErr,
+// but also rejects any public key that fails ed25519.ValidatePublicKey:
+// non-canonical encodings, the identity and other small-order points,
+// and points with a small-order component.
+// Such keys cannot come from honest cosigners,
+// and a rogue one would weaken every aggregate it is part of,
+// so roster changes should admit keys through NewCosignersStrict.
+// Validation costs a scalar multiplication per key,
+// which verifiers rebuilding a known roster can skip
+// by using NewCosigners or NewCosignersErr.
+func NewCosignersStrict(publicKeys []ed25519.PublicKey, mask []byte) (*Cosigners, error) {
+	for i, pk := range publicKeys {
+		if err := ed25519.ValidatePublicKey(pk); err != nil {
+			return nil, &InvalidKeyError{i, strings.TrimPrefix(err.Error(), "ed25519: "), err}
+		}
+	}
+	return NewCosignersErr(publicKeys, mask)
+}
+
+// Clone returns a copy of the Cosigners object
+// that can be used and mutated independently of the original.
+// The immutable list of decompressed public keys is shared,
+// while the participation mask, cached aggregate key,
+// policy and mask format are copied,
+// so cloning costs time proportional to the mask length
+// rather than to the number of cosigners.
+func (cos *Cosigners) Clone() *Cosigners {
+	c := *cos
+	c.mask = append([]byte{}, cos.mask...)
+	return &c
 }
 
 // CountTotal returns the total number of cosigners,
@@ -377,7 +441,24 @@ func (cos *Cosigners) CountEnabled() int {
 // If the mask provided is too short (or nil),
 // SetMask conservatively interprets the bits of the missing bytes
 // to be 0, or Enabled.
+//
+// If the Cosigners object uses the SparseMask format,
+// a non-empty mask is instead interpreted as a sparse index list
+// as described in SetMaskFormat,
+// and a malformed one enables no cosigner.
+// A nil or empty mask enables every cosigner in either format.
 func (cos *Cosigners) SetMask(mask []byte) {
+	if len(mask) != 0 && cos.format == SparseMask {
+		dense, err := cos.sparseToDense(mask)
+		if err != nil {
+			dense = cos.allDisabled() // malformed: conservatively enable no-one
+		}
+		mask = dense
+	}
+	cos.setDenseMask(mask)
+}
+
+func (cos *Cosigners) setDenseMask(mask []byte) {
 	masklen := len(mask)
 	for i := range cos.keys {
 		byt := i >> 3
@@ -399,8 +480,12 @@ func (cos *Cosigners) SetMask(mask []byte) {
 }
 
 // Mask returns the current cosigner disable-mask
-// represented a byte-packed little-endian bit-vector.
+// represented a byte-packed little-endian bit-vector,
+// or as a sparse index list if the SparseMask format is in use.
 func (cos *Cosigners) Mask() []byte {
+	if cos.format == SparseMask {
+		return cos.denseToSparse(cos.mask)
+	}
 	return append([]byte{}, cos.mask...) // return copy of internal mask
 }
 
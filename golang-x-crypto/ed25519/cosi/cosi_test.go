@@ -6,6 +6,9 @@ package cosi
 
 import (
 	//"encoding/hex"
+	"bytes"
+	"errors"
+	"runtime"
 	"testing"
 
 	//"golang.org/x/crypto/ed25519"
@@ -271,3 +274,112 @@ func BenchmarkVerify1000CollectiveWorst(b *testing.B) {
 func BenchmarkVerify1000Individual(b *testing.B) {
 	benchVerifyInd(b, 1000)
 }
+
+func TestNewCosignersErr(t *testing.T) {
+	genKeys(3)
+	badPoint := make(ed25519.PublicKey, ed25519.PublicKeySize)
+	badPoint[0] = 2 // y = 2 is not on the curve
+
+	for _, tc := range []struct {
+		key   ed25519.PublicKey
+		index int
+	}{
+		{badPoint, 1},
+		{pubKeys[0][:31], 2},
+	} {
+		keys := append([]ed25519.PublicKey{}, pubKeys[:3]...)
+		keys[tc.index] = tc.key
+		cos, err := NewCosignersErr(keys, nil)
+		if cos != nil || err == nil {
+			t.Fatalf("invalid key %d accepted", tc.index)
+		}
+		if kerr, ok := err.(*InvalidKeyError); !ok || kerr.Index != tc.index {
+			t.Errorf("error %v does not identify key %d", err, tc.index)
+		}
+		if NewCosigners(keys, nil) != nil {
+			t.Errorf("NewCosigners accepted invalid key %d", tc.index)
+		}
+	}
+
+	if _, err := NewCosignersErr(pubKeys[:3], nil); err != nil {
+		t.Errorf("valid keys rejected: %v", err)
+	}
+}
+
+func TestNewCosignersParallel(t *testing.T) {
+	genKeys(1000)
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(1))
+	serial := NewCosigners(pubKeys[:1000], nil)
+	runtime.GOMAXPROCS(4)
+	parallel := NewCosigners(pubKeys[:1000], nil)
+	if !bytes.Equal(serial.AggregatePublicKey(), parallel.AggregatePublicKey()) {
+		t.Errorf("aggregate key depends on the number of CPUs")
+	}
+
+	badPoint := make(ed25519.PublicKey, ed25519.PublicKeySize)
+	badPoint[0] = 2
+	keys := append([]ed25519.PublicKey{}, pubKeys[:1000]...)
+	keys[900] = badPoint
+	keys[300] = badPoint
+	_, err := NewCosignersErr(keys, nil)
+	if kerr, ok := err.(*InvalidKeyError); !ok || kerr.Index != 300 {
+		t.Errorf("error %v does not identify key 300", err)
+	}
+}
+
+func BenchmarkNewCosigners1000(b *testing.B) {
+	genKeys(1000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		NewCosigners(pubKeys[:1000], nil)
+	}
+}
+
+func TestNewCosignersStrict(t *testing.T) {
+	genKeys(3)
+	identity := make(ed25519.PublicKey, ed25519.PublicKeySize)
+	identity[0] = 1
+
+	keys := append([]ed25519.PublicKey{}, pubKeys[:3]...)
+	keys[2] = identity
+	if _, err := NewCosignersErr(keys, nil); err != nil {
+		t.Fatalf("NewCosignersErr rejected the identity: %v", err)
+	}
+	_, err := NewCosignersStrict(keys, nil)
+	if kerr, ok := err.(*InvalidKeyError); !ok || kerr.Index != 2 {
+		t.Fatalf("error %v does not identify key 2", err)
+	}
+	if !errors.Is(err, ed25519.ErrSmallOrder) {
+		t.Errorf("error %v does not wrap ed25519.ErrSmallOrder", err)
+	}
+
+	if _, err := NewCosignersStrict(pubKeys[:3], nil); err != nil {
+		t.Errorf("valid keys rejected: %v", err)
+	}
+}
+
+func TestClone(t *testing.T) {
+	n := 12
+	genKeys(n)
+	cos := NewCosigners(pubKeys[:n], nil)
+	cos.SetPolicy(ThresholdPolicy(n - 1))
+	cos.SetMaskBit(5, Disabled)
+	aggK := cos.AggregatePublicKey()
+
+	c := cos.Clone()
+	if string(c.AggregatePublicKey()) != string(aggK) || c.MaskBit(5) != Disabled {
+		t.Fatalf("clone does not match original")
+	}
+	c.SetMaskBit(5, Enabled)
+	c.SetMaskBit(9, Disabled)
+	if cos.MaskBit(5) != Disabled || cos.MaskBit(9) != Enabled ||
+		string(cos.AggregatePublicKey()) != string(aggK) {
+		t.Errorf("mutating the clone changed the original")
+	}
+	if string(c.AggregatePublicKey()) == string(aggK) {
+		t.Errorf("clone aggregate key not updated")
+	}
+	if !c.policy.Check(c) {
+		t.Errorf("clone lost the policy")
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cosicose
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// Just enough CBOR (RFC 8949) to encode and decode COSE_Sign1 structures:
+// integers, byte and text strings, arrays, maps, tags and null,
+// all in definite-length, preferred (shortest) encoding.
+
+const (
+	majorUint   = 0
+	majorNegInt = 1
+	majorBytes  = 2
+	majorText   = 3
+	majorArray  = 4
+	majorMap    = 5
+	majorTag    = 6
+	majorSimple = 7
+
+	simpleNull = 22
+)
+
+var errCBOR = errors.New("cosicose: malformed CBOR")
+
+func appendHead(b []byte, major byte, n uint64) []byte {
+	m := major << 5
+	switch {
+	case n < 24:
+		return append(b, m|byte(n))
+	case n <= 0xff:
+		return append(b, m|24, byte(n))
+	case n <= 0xffff:
+		return binary.BigEndian.AppendUint16(append(b, m|25), uint16(n))
+	case n <= 0xffffffff:
+		return binary.BigEndian.AppendUint32(append(b, m|26), uint32(n))
+	}
+	return binary.BigEndian.AppendUint64(append(b, m|27), n)
+}
+
+func appendInt(b []byte, v int64) []byte {
+	if v < 0 {
+		return appendHead(b, majorNegInt, uint64(-1-v))
+	}
+	return appendHead(b, majorUint, uint64(v))
+}
+
+func appendBytes(b, s []byte) []byte {
+	return append(appendHead(b, majorBytes, uint64(len(s))), s...)
+}
+
+func appendText(b []byte, s string) []byte {
+	return append(appendHead(b, majorText, uint64(len(s))), s...)
+}
+
+func appendNull(b []byte) []byte {
+	return append(b, majorSimple<<5|simpleNull)
+}
+
+// decoder reads CBOR data items from a byte slice.
+type decoder struct {
+	data []byte
+}
+
+// head reads the initial byte and argument of the next data item.
+func (d *decoder) head() (major byte, n uint64, err error) {
+	if len(d.data) == 0 {
+		return 0, 0, errCBOR
+	}
+	major, info := d.data[0]>>5, d.data[0]&31
+	d.data = d.data[1:]
+	var size int
+	switch {
+	case info < 24:
+		return major, uint64(info), nil
+	case info == 24:
+		size = 1
+	case info == 25:
+		size = 2
+	case info == 26:
+		size = 4
+	case info == 27:
+		size = 8
+	default:
+		return 0, 0, errCBOR // indefinite lengths and reserved values
+	}
+	if len(d.data) < size {
+		return 0, 0, errCBOR
+	}
+	for _, c := range d.data[:size] {
+		n = n<<8 | uint64(c)
+	}
+	d.data = d.data[size:]
+	return major, n, nil
+}
+
+func (d *decoder) expect(major byte) (uint64, error) {
+	m, n, err := d.head()
+	if err != nil {
+		return 0, err
+	}
+	if m != major {
+		return 0, errCBOR
+	}
+	return n, nil
+}
+
+func (d *decoder) int() (int64, error) {
+	m, n, err := d.head()
+	if err != nil {
+		return 0, err
+	}
+	if n > 1<<63-1 {
+		return 0, errCBOR
+	}
+	switch m {
+	case majorUint:
+		return int64(n), nil
+	case majorNegInt:
+		return -1 - int64(n), nil
+	}
+	return 0, errCBOR
+}
+
+func (d *decoder) bytes() ([]byte, error) {
+	n, err := d.expect(majorBytes)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(d.data)) < n {
+		return nil, errCBOR
+	}
+	b := d.data[:n:n]
+	d.data = d.data[n:]
+	return b, nil
+}
+
+// bytesOrNull reads a byte string, returning nil for a CBOR null.
+func (d *decoder) bytesOrNull() ([]byte, error) {
+	if len(d.data) > 0 && d.data[0] == majorSimple<<5|simpleNull {
+		d.data = d.data[1:]
+		return nil, nil
+	}
+	return d.bytes()
+}
+
+// skip discards the next data item, including any nested items.
+func (d *decoder) skip() error {
+	m, n, err := d.head()
+	if err != nil {
+		return err
+	}
+	switch m {
+	case majorBytes, majorText:
+		if uint64(len(d.data)) < n {
+			return errCBOR
+		}
+		d.data = d.data[n:]
+	case majorArray, majorMap:
+		items := n
+		if m == majorMap {
+			items *= 2
+		}
+		for ; items > 0; items-- {
+			if err := d.skip(); err != nil {
+				return err
+			}
+		}
+	case majorTag:
+		return d.skip()
+	}
+	return nil
+}
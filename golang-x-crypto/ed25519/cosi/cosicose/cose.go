@@ -0,0 +1,171 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package cosicose wraps collective signatures
+// in COSE_Sign1 structures (RFC 9052),
+// for use in CBOR-based ecosystems such as CBOR Web Tokens.
+//
+// The cosigners collectively sign the COSE Sig_structure
+// returned by ToBeSigned, which covers a fixed protected header
+// naming the private-use algorithm identifier Algorithm.
+// The 64-byte R || s core of the collective signature
+// becomes the COSE signature,
+// and the participation mask travels in the unprotected header
+// under the private-use label HeaderMask.
+// The mask need not be integrity-protected by COSE itself,
+// because it determines the aggregate public key
+// and is therefore already bound into the collective signature.
+package cosicose
+
+import (
+	"errors"
+
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/golang-x-crypto/ed25519/cosi"
+)
+
+const (
+	// Algorithm is the COSE algorithm identifier
+	// used for Ed25519 collective signatures,
+	// taken from the private-use range.
+	Algorithm = -65537
+
+	// HeaderMask is the unprotected header label
+	// carrying the participation mask,
+	// taken from the private-use range.
+	HeaderMask = -65538
+
+	// Sign1Tag is the CBOR tag identifying a COSE_Sign1 structure.
+	Sign1Tag = 18
+
+	headerAlg = 1
+)
+
+// protectedHeader is the serialized protected header map {1: Algorithm}.
+var protectedHeader = appendInt(appendInt(appendHead(nil, majorMap, 1), headerAlg), Algorithm)
+
+// Sign1 is a decoded COSE_Sign1 structure carrying a collective signature.
+type Sign1 struct {
+	Payload   []byte // nil if the payload is detached
+	Signature []byte // the R || s core of the collective signature
+	Mask      []byte // participation mask; nil if all cosigners signed
+}
+
+// ToBeSigned returns the COSE Sig_structure over payload and externalAAD,
+// which is the message the cosigners must collectively sign.
+func ToBeSigned(payload, externalAAD []byte) []byte {
+	b := appendHead(nil, majorArray, 4)
+	b = appendText(b, "Signature1")
+	b = appendBytes(b, protectedHeader)
+	b = appendBytes(b, externalAAD)
+	return appendBytes(b, payload)
+}
+
+// Encode builds a tagged COSE_Sign1 structure
+// from a payload and a collective signature on ToBeSigned(payload, aad),
+// in the R || s [|| mask] layout produced by this repository's cosi package.
+// If detached is true, the payload is omitted from the structure.
+func Encode(payload, sig []byte, detached bool) ([]byte, error) {
+	if len(sig) < ed25519.SignatureSize {
+		return nil, errors.New("cosicose: signature too short")
+	}
+	mask := sig[ed25519.SignatureSize:]
+
+	b := appendHead(nil, majorTag, Sign1Tag)
+	b = appendHead(b, majorArray, 4)
+	b = appendBytes(b, protectedHeader)
+	if len(mask) > 0 {
+		b = appendHead(b, majorMap, 1)
+		b = appendInt(b, HeaderMask)
+		b = appendBytes(b, mask)
+	} else {
+		b = appendHead(b, majorMap, 0)
+	}
+	if detached {
+		b = appendNull(b)
+	} else {
+		b = appendBytes(b, payload)
+	}
+	return appendBytes(b, sig[:ed25519.SignatureSize]), nil
+}
+
+// Decode parses a COSE_Sign1 structure, tagged or untagged,
+// and checks that its protected header names Algorithm.
+func Decode(data []byte) (*Sign1, error) {
+	d := &decoder{data: data}
+	if len(d.data) > 0 && d.data[0]>>5 == majorTag {
+		tag, err := d.expect(majorTag)
+		if err != nil {
+			return nil, err
+		}
+		if tag != Sign1Tag {
+			return nil, errors.New("cosicose: not a COSE_Sign1 structure")
+		}
+	}
+	if n, err := d.expect(majorArray); err != nil || n != 4 {
+		return nil, errCBOR
+	}
+
+	protected, err := d.bytes()
+	if err != nil {
+		return nil, err
+	}
+	if string(protected) != string(protectedHeader) {
+		return nil, errors.New("cosicose: unsupported protected header")
+	}
+
+	msg := &Sign1{}
+	n, err := d.expect(majorMap)
+	if err != nil {
+		return nil, err
+	}
+	for ; n > 0; n-- {
+		label, err := d.int()
+		if err != nil {
+			return nil, err
+		}
+		if label != HeaderMask {
+			if err := d.skip(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if msg.Mask, err = d.bytes(); err != nil {
+			return nil, err
+		}
+	}
+
+	if msg.Payload, err = d.bytesOrNull(); err != nil {
+		return nil, err
+	}
+	if msg.Signature, err = d.bytes(); err != nil {
+		return nil, err
+	}
+	if len(msg.Signature) != ed25519.SignatureSize {
+		return nil, errors.New("cosicose: bad signature length")
+	}
+	if len(d.data) != 0 {
+		return nil, errors.New("cosicose: trailing data")
+	}
+	return msg, nil
+}
+
+// CollectiveSignature reassembles the R || s [|| mask] signature
+// accepted by cosi.Cosigners.Verify.
+func (m *Sign1) CollectiveSignature() []byte {
+	return append(append([]byte{}, m.Signature...), m.Mask...)
+}
+
+// Verify checks the COSE_Sign1 structure against the cosigner group,
+// using the group's current policy.
+// For a detached payload, the caller supplies it in payload;
+// otherwise payload must be nil and the embedded payload is used.
+func (m *Sign1) Verify(cosigners *cosi.Cosigners, payload, externalAAD []byte) bool {
+	if payload == nil {
+		payload = m.Payload
+	} else if m.Payload != nil {
+		return false
+	}
+	return cosigners.Verify(ToBeSigned(payload, externalAAD), m.CollectiveSignature())
+}
@@ -0,0 +1,73 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cosicose
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"testing"
+
+	"test-server/golang-x-crypto/ed25519/cosi"
+	"test-server/golang-x-crypto/ed25519/cosi/cositest"
+)
+
+func TestProtectedHeader(t *testing.T) {
+	// {1: -65537}
+	if got := hex.EncodeToString(protectedHeader); got != "a1013a00010000" {
+		t.Errorf("protected header %s", got)
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	nw, err := cositest.NewNetwork(4, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nw.Close()
+	nw.Leader.Threshold = 3
+	nw.SetOnline(1, false)
+
+	payload := []byte("claims")
+	aad := []byte("context")
+	res, err := nw.Sign(context.Background(), ToBeSigned(payload, aad))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, detached := range []bool{false, true} {
+		data, err := Encode(payload, res.Signature, detached)
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg, err := Decode(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(msg.CollectiveSignature(), res.Signature) {
+			t.Errorf("signature does not round-trip")
+		}
+
+		var external []byte
+		if detached {
+			external = payload
+		}
+		cos := nw.Cosigners()
+		cos.SetPolicy(cosi.ThresholdPolicy(3))
+		if !msg.Verify(cos, external, aad) {
+			t.Errorf("detached=%v: valid COSE_Sign1 rejected", detached)
+		}
+		if cos.MaskBit(1) != cosi.Disabled {
+			t.Errorf("detached=%v: mask not carried", detached)
+		}
+		if msg.Verify(cos, external, []byte("other context")) {
+			t.Errorf("detached=%v: wrong external AAD accepted", detached)
+		}
+	}
+
+	if _, err := Decode([]byte{0xd2, 0x84}); err == nil {
+		t.Errorf("truncated structure decoded")
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package cosijws carries collective signatures
+// as JWS Compact Serializations (RFC 7515),
+// so that they can be transported by existing JWT plumbing.
+//
+// Tokens use the algorithm name "EdDSA-CoSi".
+// The JWS signature is the 64-byte R || s core of the collective signature,
+// and the participation mask is carried, base64url-encoded,
+// in the "cosi_mask" member of the protected header.
+// Since the protected header is part of the signed content,
+// the mask must be fixed before the cosigners commit;
+// Sign takes care of restarting the round under a new mask
+// whenever the set of cosigners that actually signed differs
+// from the one announced in the header.
+package cosijws
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/golang-x-crypto/ed25519/cosi"
+	"test-server/golang-x-crypto/ed25519/cosi/protocol"
+)
+
+// Algorithm is the JWS "alg" value for Ed25519 collective signatures.
+const Algorithm = "EdDSA-CoSi"
+
+// maxMaskRetries bounds how often Sign restarts under an updated mask.
+const maxMaskRetries = 3
+
+var b64 = base64.RawURLEncoding
+
+// Header is the JWS protected header of a collectively signed token.
+type Header struct {
+	Alg  string `json:"alg"`
+	Typ  string `json:"typ,omitempty"`
+	Kid  string `json:"kid,omitempty"`
+	Mask string `json:"cosi_mask,omitempty"` // base64url participation mask
+}
+
+// SigningInput returns the JWS signing input
+// BASE64URL(header) || '.' || BASE64URL(payload)
+// for a token announcing the given participation mask,
+// which is the message the cosigners must collectively sign.
+// The header's Alg and Mask fields are filled in by SigningInput.
+func SigningInput(header Header, payload, mask []byte) (string, error) {
+	header.Alg = Algorithm
+	header.Mask = ""
+	if len(mask) > 0 {
+		header.Mask = b64.EncodeToString(mask)
+	}
+	h, err := json.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+	return b64.EncodeToString(h) + "." + b64.EncodeToString(payload), nil
+}
+
+// Assemble completes a token from its signing input
+// and a collective signature on it in the R || s [|| mask] layout.
+// The signature's mask must match the one announced in the header.
+func Assemble(signingInput string, sig []byte) (string, error) {
+	if len(sig) < ed25519.SignatureSize {
+		return "", errors.New("cosijws: signature too short")
+	}
+	header, _, err := parseHeader(signingInput)
+	if err != nil {
+		return "", err
+	}
+	mask, err := b64.DecodeString(header.Mask)
+	if err != nil {
+		return "", err
+	}
+	if !bytes.Equal(mask, sig[ed25519.SignatureSize:]) {
+		return "", errors.New("cosijws: signature mask differs from header")
+	}
+	return signingInput + "." + b64.EncodeToString(sig[:ed25519.SignatureSize]), nil
+}
+
+// Sign runs collective signing rounds with leader
+// until it obtains a token whose header announces exactly
+// the set of cosigners that signed it.
+// The first round announces initialMask (nil meaning every cosigner).
+func Sign(ctx context.Context, leader *protocol.Leader, header Header,
+	payload, initialMask []byte) (string, error) {
+
+	mask := initialMask
+	for i := 0; i <= maxMaskRetries; i++ {
+		input, err := SigningInput(header, payload, mask)
+		if err != nil {
+			return "", err
+		}
+		res, err := leader.SignWithMask(ctx, []byte(input), mask)
+		if err != nil {
+			return "", err
+		}
+		got := res.Signature[ed25519.SignatureSize:]
+		if sameMask(got, mask) {
+			return Assemble(input, append(res.Signature[:ed25519.SignatureSize:ed25519.SignatureSize], mask...))
+		}
+		mask = got // someone dropped out; announce the new set and retry
+	}
+	return "", errors.New("cosijws: cosigner set did not stabilize")
+}
+
+// sameMask reports whether two masks disable the same cosigners,
+// treating a nil mask as all-enabled.
+func sameMask(a, b []byte) bool {
+	for len(a) < len(b) {
+		a = append(a, 0)
+	}
+	for len(b) < len(a) {
+		b = append(b, 0)
+	}
+	return bytes.Equal(a, b)
+}
+
+func parseHeader(signingInput string) (*Header, string, error) {
+	encHeader, encPayload, ok := strings.Cut(signingInput, ".")
+	if !ok {
+		return nil, "", errors.New("cosijws: malformed token")
+	}
+	raw, err := b64.DecodeString(encHeader)
+	if err != nil {
+		return nil, "", err
+	}
+	var header Header
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return nil, "", err
+	}
+	if header.Alg != Algorithm {
+		return nil, "", errors.New("cosijws: unexpected alg " + header.Alg)
+	}
+	return &header, encPayload, nil
+}
+
+// Verify checks a token against the cosigner group
+// using the group's current policy,
+// and returns the token's header and payload if it is valid.
+// On return, the mask of cosigners reflects the token's signers.
+func Verify(cosigners *cosi.Cosigners, token string) (*Header, []byte, error) {
+	i := strings.LastIndexByte(token, '.')
+	if i < 0 {
+		return nil, nil, errors.New("cosijws: malformed token")
+	}
+	input, encSig := token[:i], token[i+1:]
+	header, encPayload, err := parseHeader(input)
+	if err != nil {
+		return nil, nil, err
+	}
+	payload, err := b64.DecodeString(encPayload)
+	if err != nil {
+		return nil, nil, err
+	}
+	sig, err := b64.DecodeString(encSig)
+	if err != nil {
+		return nil, nil, err
+	}
+	mask, err := b64.DecodeString(header.Mask)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(sig) != ed25519.SignatureSize {
+		return nil, nil, errors.New("cosijws: bad signature length")
+	}
+	if len(mask) > 0 && len(mask) != cosigners.MaskLen() {
+		return nil, nil, errors.New("cosijws: bad mask length")
+	}
+	if !cosigners.Verify([]byte(input), append(sig, mask...)) {
+		return nil, nil, errors.New("cosijws: invalid collective signature")
+	}
+	return header, payload, nil
+}
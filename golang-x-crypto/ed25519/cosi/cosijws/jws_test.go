@@ -0,0 +1,61 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cosijws
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"test-server/golang-x-crypto/ed25519/cosi"
+	"test-server/golang-x-crypto/ed25519/cosi/cositest"
+)
+
+func TestSignVerifyToken(t *testing.T) {
+	nw, err := cositest.NewNetwork(5, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nw.Close()
+	nw.Leader.Threshold = 4
+
+	payload := []byte(`{"sub":"node-1"}`)
+	token, err := Sign(context.Background(), nw.Leader, Header{Typ: "JWT"}, payload, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, got, err := Verify(nw.Cosigners(), token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(payload) || header.Typ != "JWT" || header.Mask != "" {
+		t.Errorf("unexpected header %+v or payload %q", header, got)
+	}
+
+	// With a cosigner offline, the announced mask must follow the signers.
+	nw.SetOnline(3, false)
+	token, err = Sign(context.Background(), nw.Leader, Header{}, payload, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cos := nw.Cosigners()
+	if _, _, err := Verify(cos, token); err == nil {
+		t.Errorf("partial token accepted under full policy")
+	}
+	cos.SetPolicy(cosi.ThresholdPolicy(4))
+	header, _, err = Verify(cos, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.Mask == "" || cos.MaskBit(3) != cosi.Disabled {
+		t.Errorf("mask not reconstructed from token")
+	}
+
+	parts := strings.Split(token, ".")
+	parts[1] = b64.EncodeToString([]byte(`{"sub":"node-2"}`))
+	if _, _, err := Verify(cos, strings.Join(parts, ".")); err == nil {
+		t.Errorf("token with altered payload accepted")
+	}
+}
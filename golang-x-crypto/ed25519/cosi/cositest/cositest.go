@@ -0,0 +1,286 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package cositest provides an in-memory cosigning network
+// for integration tests of applications built on the cosi protocol.
+//
+// A Network runs N cosigners in-process,
+// each served by its own goroutine,
+// and wires them to a protocol.Leader over channels,
+// so complete signing rounds can be exercised without sockets.
+// Individual cosigners can be taken offline,
+// rounds can be run with an explicit participation mask,
+// and faults (dropped, delayed or corrupted replies)
+// can be injected on the link to any cosigner
+// to exercise the leader's retry and blame logic.
+package cositest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/golang-x-crypto/ed25519/cosi"
+	"test-server/golang-x-crypto/ed25519/cosi/protocol"
+)
+
+// ErrOffline is returned by a link to a cosigner that has been taken offline.
+var ErrOffline = errors.New("cositest: cosigner offline")
+
+// ErrClosed is returned by a link after its Network has been closed.
+var ErrClosed = errors.New("cositest: network closed")
+
+// Network is an in-memory group of cosigners and their leader.
+type Network struct {
+	PublicKeys  []ed25519.PublicKey
+	PrivateKeys []ed25519.PrivateKey
+
+	// Services holds the cosigner side of the protocol for each member.
+	Services []*protocol.Service
+
+	// Leader drives rounds against the members over in-memory links.
+	// Its timeouts and threshold may be adjusted before use.
+	Leader *protocol.Leader
+
+	links []*Link
+	done  chan struct{}
+	once  sync.Once
+}
+
+// NewNetwork creates a network of n cosigners with freshly generated keys.
+// All key and commit randomness is derived from rand,
+// or drawn from crypto/rand if rand is nil.
+// When rand is given, each cosigner and the leader get
+// an independent stream seeded from it,
+// so a deterministic rand makes every round of the network reproducible
+// regardless of goroutine scheduling.
+// The caller must Close the network when done.
+func NewNetwork(n int, rand io.Reader) (*Network, error) {
+	nw := &Network{done: make(chan struct{})}
+	cosigners := make([]protocol.Cosigner, n)
+	for i := 0; i < n; i++ {
+		r, err := derive(rand)
+		if err != nil {
+			return nil, err
+		}
+		pub, priv, err := ed25519.GenerateKey(r)
+		if err != nil {
+			return nil, err
+		}
+		svc := protocol.NewService(priv, r)
+		l := newLink(i, svc, nw.done)
+		nw.PublicKeys = append(nw.PublicKeys, pub)
+		nw.PrivateKeys = append(nw.PrivateKeys, priv)
+		nw.Services = append(nw.Services, svc)
+		nw.links = append(nw.links, l)
+		cosigners[i] = l
+	}
+
+	r, err := derive(rand)
+	if err != nil {
+		return nil, err
+	}
+	leader, err := protocol.NewLeader(nw.PublicKeys, cosigners, r)
+	if err != nil {
+		return nil, err
+	}
+	nw.Leader = leader
+	return nw, nil
+}
+
+// Close stops all cosigner goroutines.
+func (nw *Network) Close() {
+	nw.once.Do(func() { close(nw.done) })
+}
+
+// Link returns the leader's link to the i-th cosigner.
+func (nw *Network) Link(i int) *Link {
+	return nw.links[i]
+}
+
+// SetOnline takes the i-th cosigner offline or brings it back.
+// An offline cosigner fails every request immediately with ErrOffline.
+func (nw *Network) SetOnline(i int, online bool) {
+	nw.links[i].setOnline(online)
+}
+
+// Sign runs a signing round over message with every online cosigner.
+func (nw *Network) Sign(ctx context.Context, message []byte) (*protocol.Result, error) {
+	return nw.Leader.Sign(ctx, message)
+}
+
+// SignWithMask runs a signing round over message,
+// contacting only the cosigners enabled in mask.
+func (nw *Network) SignWithMask(ctx context.Context, message, mask []byte) (*protocol.Result, error) {
+	return nw.Leader.SignWithMask(ctx, message, mask)
+}
+
+// Cosigners returns a fresh Cosigners object for the network's group,
+// for use in verifying its signatures.
+func (nw *Network) Cosigners() *cosi.Cosigners {
+	return cosi.NewCosigners(nw.PublicKeys, nil)
+}
+
+// Verify checks a collective signature produced by the network
+// under the given policy (nil meaning every cosigner must have signed).
+func (nw *Network) Verify(message, sig []byte, policy cosi.Policy) bool {
+	return cosi.Verify(nw.PublicKeys, policy, message, sig)
+}
+
+// Mask builds a participation mask for a group of n cosigners
+// in which the listed cosigners are disabled.
+func Mask(n int, disabled ...int) []byte {
+	mask := make([]byte, (n+7)>>3)
+	for _, i := range disabled {
+		mask[i>>3] |= 1 << uint(i&7)
+	}
+	return mask
+}
+
+// derive returns an independent deterministic stream seeded from rand,
+// or nil (meaning crypto/rand) if rand is nil.
+func derive(rand io.Reader) (io.Reader, error) {
+	if rand == nil {
+		return nil, nil
+	}
+	s := &stream{}
+	if _, err := io.ReadFull(rand, s.seed[:]); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// stream is a deterministic byte stream: SHA-256 in counter mode over a seed.
+// It is safe for concurrent use.
+type stream struct {
+	mu    sync.Mutex
+	seed  [32]byte
+	ctr   uint64
+	avail []byte
+}
+
+func (s *stream) Read(buf []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for n < len(buf) {
+		if len(s.avail) == 0 {
+			var block [40]byte
+			copy(block[:], s.seed[:])
+			binary.LittleEndian.PutUint64(block[32:], s.ctr)
+			s.ctr++
+			sum := sha256.Sum256(block[:])
+			s.avail = sum[:]
+		}
+		c := copy(buf[n:], s.avail)
+		s.avail = s.avail[c:]
+		n += c
+	}
+	return n, nil
+}
+
+// Link is the leader's channel-based connection to one cosigner.
+// It implements protocol.Cosigner.
+type Link struct {
+	index int
+	reqs  chan *linkRequest
+	done  <-chan struct{}
+
+	mu     sync.Mutex
+	online bool
+	fault  *Fault
+}
+
+type linkRequest struct {
+	ctx       context.Context
+	commit    *protocol.CommitRequest
+	challenge *protocol.ChallengeRequest
+	reply     chan linkReply
+}
+
+type linkReply struct {
+	commit *protocol.CommitResponse
+	part   *protocol.ChallengeResponse
+	err    error
+}
+
+func newLink(index int, svc *protocol.Service, done <-chan struct{}) *Link {
+	l := &Link{
+		index:  index,
+		reqs:   make(chan *linkRequest),
+		done:   done,
+		online: true,
+	}
+	go l.serve(svc)
+	return l
+}
+
+// serve is the cosigner's goroutine, answering requests from the leader.
+func (l *Link) serve(svc *protocol.Service) {
+	for {
+		select {
+		case <-l.done:
+			return
+		case req := <-l.reqs:
+			var rep linkReply
+			if req.commit != nil {
+				rep.commit, rep.err = svc.Commit(req.ctx, req.commit)
+			} else {
+				rep.part, rep.err = svc.Respond(req.ctx, req.challenge)
+			}
+			req.reply <- rep
+		}
+	}
+}
+
+func (l *Link) setOnline(online bool) {
+	l.mu.Lock()
+	l.online = online
+	l.mu.Unlock()
+}
+
+func (l *Link) isOnline() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.online
+}
+
+func (l *Link) roundTrip(ctx context.Context, phase Phase, req *linkRequest) (linkReply, error) {
+	if !l.isOnline() {
+		return linkReply{}, ErrOffline
+	}
+	req.ctx = ctx
+	req.reply = make(chan linkReply, 1)
+	select {
+	case l.reqs <- req:
+	case <-ctx.Done():
+		return linkReply{}, ctx.Err()
+	case <-l.done:
+		return linkReply{}, ErrClosed
+	}
+	select {
+	case rep := <-req.reply:
+		return l.inject(ctx, phase, rep)
+	case <-ctx.Done():
+		return linkReply{}, ctx.Err()
+	case <-l.done:
+		return linkReply{}, ErrClosed
+	}
+}
+
+// Commit implements protocol.Cosigner.
+func (l *Link) Commit(ctx context.Context, req *protocol.CommitRequest) (*protocol.CommitResponse, error) {
+	rep, err := l.roundTrip(ctx, CommitPhase, &linkRequest{commit: req})
+	return rep.commit, err
+}
+
+// Respond implements protocol.Cosigner.
+func (l *Link) Respond(ctx context.Context, req *protocol.ChallengeRequest) (*protocol.ChallengeResponse, error) {
+	rep, err := l.roundTrip(ctx, ResponsePhase, &linkRequest{challenge: req})
+	return rep.part, err
+}
@@ -0,0 +1,114 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cositest
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"test-server/golang-x-crypto/ed25519/cosi"
+)
+
+// countReader yields the byte sequence 0, 1, 2, ...
+type countReader struct{ next byte }
+
+func (cr *countReader) Read(buf []byte) (int, error) {
+	for i := range buf {
+		buf[i] = cr.next
+		cr.next++
+	}
+	return len(buf), nil
+}
+
+var message = []byte("test message")
+
+func TestNetworkRound(t *testing.T) {
+	nw, err := NewNetwork(5, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nw.Close()
+
+	res, err := nw.Sign(context.Background(), message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !nw.Verify(message, res.Signature, nil) {
+		t.Errorf("valid signature rejected")
+	}
+	if len(res.Absent) != 0 || res.Attempts != 1 {
+		t.Errorf("unexpected result %+v", res)
+	}
+}
+
+func TestNetworkMask(t *testing.T) {
+	nw, err := NewNetwork(6, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nw.Close()
+	nw.Leader.Threshold = 4
+
+	res, err := nw.SignWithMask(context.Background(), message, Mask(6, 1, 4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nw.Verify(message, res.Signature, nil) {
+		t.Errorf("partial signature accepted under full policy")
+	}
+	if !nw.Verify(message, res.Signature, cosi.ThresholdPolicy(4)) {
+		t.Errorf("partial signature rejected under threshold policy")
+	}
+	cos := nw.Cosigners()
+	cos.SetPolicy(cosi.ThresholdPolicy(4))
+	cos.Verify(message, res.Signature)
+	if cos.MaskBit(1) != cosi.Disabled || cos.MaskBit(4) != cosi.Disabled {
+		t.Errorf("masked-out cosigners appear as signers")
+	}
+
+	if _, err := nw.SignWithMask(context.Background(), message, Mask(6, 0, 1, 2)); err == nil {
+		t.Errorf("round below threshold succeeded")
+	}
+}
+
+func TestNetworkOffline(t *testing.T) {
+	nw, err := NewNetwork(4, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nw.Close()
+	nw.Leader.Threshold = 3
+	nw.SetOnline(2, false)
+
+	res, err := nw.Sign(context.Background(), message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Absent) != 1 || res.Absent[0] != 2 {
+		t.Errorf("absent cosigners %v, want [2]", res.Absent)
+	}
+	if !nw.Verify(message, res.Signature, cosi.ThresholdPolicy(3)) {
+		t.Errorf("valid signature rejected")
+	}
+}
+
+func TestNetworkDeterministic(t *testing.T) {
+	sign := func() []byte {
+		nw, err := NewNetwork(4, &countReader{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer nw.Close()
+		res, err := nw.Sign(context.Background(), message)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res.Signature
+	}
+	if !bytes.Equal(sign(), sign()) {
+		t.Errorf("seeded networks produced different signatures")
+	}
+}
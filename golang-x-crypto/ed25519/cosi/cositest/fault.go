@@ -0,0 +1,125 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cositest
+
+import (
+	"context"
+	"time"
+)
+
+// Phase selects the protocol phase a Fault applies to.
+type Phase int
+
+const (
+	AnyPhase      Phase = iota // both phases
+	CommitPhase                // commit requests
+	ResponsePhase              // challenge requests
+)
+
+// Fault describes a failure injected on the link to one cosigner.
+// The cosigner itself always processes the request normally;
+// the fault only affects what the leader gets back.
+type Fault struct {
+	// Phase restricts the fault to one protocol phase.
+	Phase Phase
+
+	// Times is the number of replies the fault applies to,
+	// after which it disarms itself. Zero means every reply.
+	Times int
+
+	// Drop swallows the reply, so the leader sees the phase deadline expire.
+	Drop bool
+
+	// Delay holds the reply back for the given duration.
+	// A delay longer than the leader's phase timeout
+	// makes the reply arrive too late.
+	Delay time.Duration
+
+	// Corrupt flips the low bit of the byte at CorruptOffset
+	// in the commit or signature part carried by the reply.
+	Corrupt       bool
+	CorruptOffset int
+}
+
+// SetFault installs a fault on the link to the i-th cosigner,
+// replacing any fault already installed there.
+// A nil fault restores normal operation.
+func (nw *Network) SetFault(i int, f *Fault) {
+	nw.links[i].SetFault(f)
+}
+
+// SetFault installs a fault on this link,
+// replacing any fault already installed.
+// A nil fault restores normal operation.
+func (l *Link) SetFault(f *Fault) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if f == nil {
+		l.fault = nil
+		return
+	}
+	copied := *f
+	l.fault = &copied
+}
+
+// takeFault returns the fault to apply to a reply in the given phase,
+// consuming one use of it, or nil if none applies.
+func (l *Link) takeFault(phase Phase) *Fault {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	f := l.fault
+	if f == nil || (f.Phase != AnyPhase && f.Phase != phase) {
+		return nil
+	}
+	if f.Times > 0 {
+		f.Times--
+		if f.Times == 0 {
+			l.fault = nil
+		}
+	}
+	return f
+}
+
+// inject applies the link's fault, if any, to a reply from the cosigner.
+func (l *Link) inject(ctx context.Context, phase Phase, rep linkReply) (linkReply, error) {
+	f := l.takeFault(phase)
+	if f == nil || rep.err != nil {
+		return rep, rep.err
+	}
+	if f.Drop {
+		<-ctx.Done()
+		return linkReply{}, ctx.Err()
+	}
+	if f.Delay > 0 {
+		t := time.NewTimer(f.Delay)
+		defer t.Stop()
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return linkReply{}, ctx.Err()
+		}
+	}
+	if f.Corrupt {
+		if rep.commit != nil {
+			c := *rep.commit
+			c.Commit = corrupt(c.Commit, f.CorruptOffset)
+			rep.commit = &c
+		}
+		if rep.part != nil {
+			p := *rep.part
+			p.Part = corrupt(p.Part, f.CorruptOffset)
+			rep.part = &p
+		}
+	}
+	return rep, nil
+}
+
+func corrupt(b []byte, offset int) []byte {
+	b = append([]byte{}, b...)
+	if len(b) > 0 {
+		b[offset%len(b)] ^= 1
+	}
+	return b
+}
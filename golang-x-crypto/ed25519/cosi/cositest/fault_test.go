@@ -0,0 +1,112 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cositest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"test-server/golang-x-crypto/ed25519/cosi"
+	"test-server/golang-x-crypto/ed25519/cosi/protocol"
+)
+
+func newFaultyNetwork(t *testing.T, n int) *Network {
+	nw, err := NewNetwork(n, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nw.Leader.PhaseTimeout = 50 * time.Millisecond
+	nw.Leader.Threshold = n - 1
+	return nw
+}
+
+func TestFaultCorruptPartIsBlamed(t *testing.T) {
+	nw := newFaultyNetwork(t, 5)
+	defer nw.Close()
+	nw.SetFault(3, &Fault{Phase: ResponsePhase, Corrupt: true, CorruptOffset: 5})
+
+	res, err := nw.Sign(context.Background(), message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Blamed) != 1 || res.Blamed[0] != 3 {
+		t.Errorf("blamed %v, want [3]", res.Blamed)
+	}
+	if res.Attempts != 2 {
+		t.Errorf("took %d attempts, want 2", res.Attempts)
+	}
+	if !nw.Verify(message, res.Signature, cosi.ThresholdPolicy(4)) {
+		t.Errorf("valid signature rejected")
+	}
+}
+
+func TestFaultDropResponseRestarts(t *testing.T) {
+	nw := newFaultyNetwork(t, 4)
+	defer nw.Close()
+	nw.SetFault(0, &Fault{Phase: ResponsePhase, Drop: true})
+
+	res, err := nw.Sign(context.Background(), message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Blamed) != 0 {
+		t.Errorf("silent cosigner blamed: %v", res.Blamed)
+	}
+	if len(res.Absent) != 1 || res.Absent[0] != 0 || res.Attempts != 2 {
+		t.Errorf("unexpected result %+v", res)
+	}
+}
+
+func TestFaultDelayedCommit(t *testing.T) {
+	nw := newFaultyNetwork(t, 4)
+	defer nw.Close()
+	nw.SetFault(1, &Fault{Phase: CommitPhase, Delay: time.Second})
+
+	res, err := nw.Sign(context.Background(), message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Absent) != 1 || res.Absent[0] != 1 || res.Attempts != 1 {
+		t.Errorf("unexpected result %+v", res)
+	}
+}
+
+func TestFaultTooManyFailures(t *testing.T) {
+	nw := newFaultyNetwork(t, 4)
+	defer nw.Close()
+	nw.SetFault(0, &Fault{Phase: ResponsePhase, Corrupt: true})
+	nw.SetFault(2, &Fault{Phase: ResponsePhase, Corrupt: true})
+
+	_, err := nw.Sign(context.Background(), message)
+	var serr *protocol.SignError
+	if !errors.As(err, &serr) {
+		t.Fatalf("got error %v, want SignError", err)
+	}
+	if !errors.Is(err, protocol.ErrInsufficientCosigners) {
+		t.Errorf("got %v, want ErrInsufficientCosigners", err)
+	}
+	if len(serr.Blamed) != 2 {
+		t.Errorf("blamed %v, want [0 2]", serr.Blamed)
+	}
+}
+
+func TestFaultTimes(t *testing.T) {
+	nw := newFaultyNetwork(t, 3)
+	defer nw.Close()
+	nw.SetFault(2, &Fault{Drop: true, Times: 1})
+
+	if _, err := nw.Sign(context.Background(), message); err != nil {
+		t.Fatal(err)
+	}
+	res, err := nw.Sign(context.Background(), message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Absent) != 0 {
+		t.Errorf("fault still armed after use: absent %v", res.Absent)
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build js && wasm
+
+// Command cosiwasm exposes collective signature verification to JavaScript,
+// for browsers and edge-function runtimes. Build it with either toolchain:
+//
+//	GOOS=js GOARCH=wasm go build -o cosi.wasm
+//	tinygo build -o cosi.wasm -target wasm -no-debug
+//
+// and load cosi.wasm with the wasm_exec.js of the same toolchain. Once
+// running, it defines two global functions, which take their byte strings
+// as Uint8Arrays:
+//
+//	cosiVerify(publicKeys, message, signature[, threshold])
+//	ed25519Verify(publicKey, message, signature)
+//
+// cosiVerify checks a collective signature with cosi.Verify, where
+// publicKeys is an array of the cosigners' keys in order, and returns
+// whether at least threshold cosigners signed, or all of them when
+// threshold is omitted. ed25519Verify checks a single Ed25519 signature.
+// Both return false, rather than throwing, when an argument has the
+// wrong type.
+//
+// Under TinyGo, the ed25519 package leaves out its X.509, PKCS #8 and
+// OpenSSH encodings, which depend on packages TinyGo does not support.
+package main
+
+import (
+	"syscall/js"
+
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/golang-x-crypto/ed25519/cosi"
+)
+
+func main() {
+	js.Global().Set("cosiVerify", js.FuncOf(cosiVerify))
+	js.Global().Set("ed25519Verify", js.FuncOf(ed25519Verify))
+	select {}
+}
+
+func cosiVerify(_ js.Value, args []js.Value) any {
+	if len(args) < 3 || len(args) > 4 {
+		return false
+	}
+	keys, ok := publicKeys(args[0])
+	if !ok {
+		return false
+	}
+	message, ok1 := copyBytes(args[1])
+	sig, ok2 := copyBytes(args[2])
+	if !ok1 || !ok2 {
+		return false
+	}
+	var policy cosi.Policy // every cosigner
+	if len(args) == 4 && !args[3].IsUndefined() {
+		if args[3].Type() != js.TypeNumber {
+			return false
+		}
+		policy = cosi.ThresholdPolicy(args[3].Int())
+	}
+	return cosi.Verify(keys, policy, message, sig)
+}
+
+func ed25519Verify(_ js.Value, args []js.Value) any {
+	if len(args) != 3 {
+		return false
+	}
+	publicKey, ok1 := copyBytes(args[0])
+	message, ok2 := copyBytes(args[1])
+	sig, ok3 := copyBytes(args[2])
+	if !ok1 || !ok2 || !ok3 || len(publicKey) != ed25519.PublicKeySize {
+		return false
+	}
+	return ed25519.Verify(publicKey, message, sig)
+}
+
+var uint8Array = js.Global().Get("Uint8Array")
+
+// copyBytes copies the contents of the Uint8Array v.
+func copyBytes(v js.Value) ([]byte, bool) {
+	if v.Type() != js.TypeObject || !v.InstanceOf(uint8Array) {
+		return nil, false
+	}
+	b := make([]byte, v.Length())
+	js.CopyBytesToGo(b, v)
+	return b, true
+}
+
+// publicKeys copies the array of Uint8Arrays v.
+func publicKeys(v js.Value) ([]ed25519.PublicKey, bool) {
+	if !js.Global().Get("Array").Call("isArray", v).Bool() {
+		return nil, false
+	}
+	keys := make([]ed25519.PublicKey, v.Length())
+	for i := range keys {
+		k, ok := copyBytes(v.Index(i))
+		if !ok || len(k) != ed25519.PublicKeySize {
+			return nil, false
+		}
+		keys[i] = k
+	}
+	return keys, true
+}
@@ -0,0 +1,77 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build js && wasm
+
+package main
+
+import (
+	"context"
+	"syscall/js"
+	"testing"
+
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/golang-x-crypto/ed25519/cosi/cositest"
+)
+
+func uint8ArrayOf(b []byte) js.Value {
+	v := uint8Array.New(len(b))
+	js.CopyBytesToJS(v, b)
+	return v
+}
+
+func TestCosiVerify(t *testing.T) {
+	nw, err := cositest.NewNetwork(5, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nw.Close()
+	nw.Leader.Threshold = 4
+	message := []byte("test message")
+	res, err := nw.SignWithMask(context.Background(), message, cositest.Mask(5, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := make([]any, len(nw.PublicKeys))
+	for i, k := range nw.PublicKeys {
+		keys[i] = uint8ArrayOf(k)
+	}
+	args := func(msg []byte, rest ...any) []js.Value {
+		v := []js.Value{js.ValueOf(keys), uint8ArrayOf(msg), uint8ArrayOf(res.Signature)}
+		for _, r := range rest {
+			v = append(v, js.ValueOf(r))
+		}
+		return v
+	}
+	for _, tt := range []struct {
+		name string
+		args []js.Value
+		want bool
+	}{
+		{"threshold met", args(message, 4), true},
+		{"threshold not met", args(message, 5), false},
+		{"default policy", args(message), false},
+		{"wrong message", args([]byte("other message"), 4), false},
+		{"key not an array", []js.Value{uint8ArrayOf(nw.PublicKeys[0]), uint8ArrayOf(message), uint8ArrayOf(res.Signature)}, false},
+		{"message a string", []js.Value{js.ValueOf(keys), js.ValueOf("test message"), uint8ArrayOf(res.Signature)}, false},
+	} {
+		if got := cosiVerify(js.Undefined(), tt.args); got != tt.want {
+			t.Errorf("%s: cosiVerify = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEd25519Verify(t *testing.T) {
+	public, private, _ := ed25519.GenerateKey(nil)
+	message := []byte("test message")
+	sig := ed25519.Sign(private, message)
+	if !ed25519Verify(js.Undefined(), []js.Value{uint8ArrayOf(public), uint8ArrayOf(message), uint8ArrayOf(sig)}).(bool) {
+		t.Errorf("valid signature rejected")
+	}
+	sig[0] ^= 1
+	if ed25519Verify(js.Undefined(), []js.Value{uint8ArrayOf(public), uint8ArrayOf(message), uint8ArrayOf(sig)}).(bool) {
+		t.Errorf("invalid signature accepted")
+	}
+}
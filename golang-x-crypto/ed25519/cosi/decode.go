@@ -0,0 +1,80 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cosi
+
+import (
+	"runtime"
+	"strconv"
+	"sync"
+
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/golang-x-crypto/ed25519/internal/edwards25519"
+)
+
+// decodeChunk is the smallest number of keys worth a goroutine.
+const decodeChunk = 128
+
+// decodeKeys decompresses publicKeys into keys, which has the same length,
+// returning an *InvalidKeyError for the first key that is not valid.
+//
+// Decompression costs a field exponentiation per key, to take the square
+// root of u/v. The ref10 code computes it as u*v^3*(u*v^7)^((p-5)/8),
+// without an inversion, so there is none to share between keys
+// with Montgomery's trick; instead, each range is decompressed with
+// edwards25519.GeFromBytesBatch, which computes four exponentiations
+// at a time with AVX2, and large groups are split across the
+// available CPUs.
+func decodeKeys(keys []edwards25519.ExtendedGroupElement, publicKeys []ed25519.PublicKey) error {
+	n := len(publicKeys)
+	workers := min(runtime.GOMAXPROCS(0), (n+decodeChunk-1)/decodeChunk)
+	if workers <= 1 {
+		return decodeRange(keys, publicKeys, 0, n)
+	}
+	errs := make([]error, workers)
+	per := (n + workers - 1) / workers
+	var wg sync.WaitGroup
+	for w := range errs {
+		lo, hi := w*per, min((w+1)*per, n)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[w] = decodeRange(keys, publicKeys, lo, hi)
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeRange decompresses publicKeys[lo:hi] into keys[lo:hi].
+func decodeRange(keys []edwards25519.ExtendedGroupElement, publicKeys []ed25519.PublicKey, lo, hi int) error {
+	encoded := make([][32]byte, hi-lo)
+	encodings := make([]*[32]byte, 0, hi-lo)
+	for i := lo; i < hi; i++ {
+		pk := publicKeys[i]
+		if len(pk) != ed25519.PublicKeySize {
+			// Report an invalid point before it, if any.
+			if err := decodeBatch(keys, encodings, lo); err != nil {
+				return err
+			}
+			return &InvalidKeyError{i, "bad length " + strconv.Itoa(len(pk)), ed25519.ErrPublicKeySize}
+		}
+		copy(encoded[i-lo][:], pk)
+		encodings = append(encodings, &encoded[i-lo])
+	}
+	return decodeBatch(keys, encodings, lo)
+}
+
+// decodeBatch decompresses encodings into keys[lo:].
+func decodeBatch(keys []edwards25519.ExtendedGroupElement, encodings []*[32]byte, lo int) error {
+	if i := edwards25519.GeFromBytesBatch(keys[lo:], encodings); i >= 0 {
+		return &InvalidKeyError{lo + i, "not a valid curve point", ed25519.ErrInvalidPoint}
+	}
+	return nil
+}
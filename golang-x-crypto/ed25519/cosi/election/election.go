@@ -0,0 +1,388 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package election lets a cosigner group choose its own leader
+// instead of relying on an externally designated one.
+//
+// Election is lease-based.
+// A candidate asks every member for a lease on leadership
+// for a numbered term, signing the request with its key;
+// each member grants at most one unexpired lease at a time,
+// and answers with a signed grant.
+// A candidate holding grants from a majority of the group
+// is leader until its lease runs out,
+// and keeps renewing the lease while it is alive.
+// When the leader dies its leases expire,
+// and the next member to campaign is elected for a higher term.
+//
+// A candidate measures its lease from before it sent its requests,
+// and members measure theirs from when they granted,
+// so, given clocks that run at about the same rate,
+// a leader's own view of its lease always ends
+// before the majority that granted it is free to elect another.
+//
+// Leases are requested over the Peer interface,
+// which a transport implements for each remote member
+// the same way it implements protocol.Cosigner;
+// a Node itself implements Peer for its own side of the exchange.
+// The elected leader announces its identity and term
+// in every round through protocol.Leader's ID and Term,
+// and members refuse rounds from anyone else
+// through the Node's Validator.
+package election
+
+import (
+	"context"
+	"crypto"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+	mrand "math/rand"
+	"strconv"
+	"sync"
+	"time"
+
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/golang-x-crypto/ed25519/cosi/protocol"
+)
+
+// DefaultLeaseDuration is the lease length used when Node.LeaseDuration is zero.
+const DefaultLeaseDuration = 5 * time.Second
+
+const (
+	requestDomain = "cosi-election-request-v1"
+	grantDomain   = "cosi-election-grant-v1"
+)
+
+// ErrNotElected is returned by Campaign
+// when the candidate did not obtain a majority of grants.
+var ErrNotElected = errors.New("election: no majority for candidate")
+
+// LeaseRequest asks a member to grant leadership for a term.
+type LeaseRequest struct {
+	Term      uint64
+	Candidate int    // index of the candidate in the group
+	Signature []byte // candidate's signature on RequestMessage(Term, Candidate)
+}
+
+// LeaseResponse is a member's answer to a LeaseRequest.
+// If the lease is refused, Term and Holder describe
+// the lease the member has granted instead.
+type LeaseResponse struct {
+	Granted   bool
+	Term      uint64
+	Holder    int
+	Signature []byte // if granted, the member's signature on GrantMessage
+}
+
+// Peer is a candidate's handle on one member of the group.
+type Peer interface {
+	RequestLease(ctx context.Context, req *LeaseRequest) (*LeaseResponse, error)
+}
+
+// RequestMessage returns the message a candidate signs
+// to ask for leadership in a term.
+func RequestMessage(term uint64, candidate int) []byte {
+	b := append([]byte(requestDomain), make([]byte, 12)...)
+	binary.BigEndian.PutUint64(b[len(requestDomain):], term)
+	binary.BigEndian.PutUint32(b[len(requestDomain)+8:], uint32(candidate))
+	return b
+}
+
+// GrantMessage returns the message a member signs
+// to grant a candidate leadership in a term.
+func GrantMessage(term uint64, candidate, voter int) []byte {
+	b := append([]byte(grantDomain), make([]byte, 16)...)
+	binary.BigEndian.PutUint64(b[len(grantDomain):], term)
+	binary.BigEndian.PutUint32(b[len(grantDomain)+8:], uint32(candidate))
+	binary.BigEndian.PutUint32(b[len(grantDomain)+12:], uint32(voter))
+	return b
+}
+
+// MemberID returns the identity under which a member leads rounds,
+// the hex encoding of its public key.
+func MemberID(publicKey ed25519.PublicKey) string {
+	return hex.EncodeToString(publicKey)
+}
+
+// Grant is one member's signed vote for a leader.
+type Grant struct {
+	Voter     int
+	Signature []byte
+}
+
+// Lease describes a won election.
+// Its grants prove to any holder of the group's public keys
+// that a majority elected the leader for the term (see VerifyLease).
+type Lease struct {
+	Term   uint64
+	Leader int
+	Expiry time.Time // local time at which the leader's lease ends
+	Grants []Grant
+}
+
+// VerifyLease checks that a lease carries valid grants
+// from a majority of the group identified by publicKeys.
+func VerifyLease(publicKeys []ed25519.PublicKey, l *Lease) bool {
+	seen := make(map[int]bool)
+	for _, g := range l.Grants {
+		if g.Voter < 0 || g.Voter >= len(publicKeys) || seen[g.Voter] ||
+			ed25519.VerifyErr(publicKeys[g.Voter], GrantMessage(l.Term, l.Leader, g.Voter), g.Signature) != nil {
+			return false
+		}
+		seen[g.Voter] = true
+	}
+	return len(seen) > len(publicKeys)/2
+}
+
+// Node is one member's participant in leader election.
+// It is safe for concurrent use.
+type Node struct {
+	// LeaseDuration is the length of each lease.
+	// If zero, DefaultLeaseDuration is used.
+	LeaseDuration time.Duration
+
+	self       int
+	signer     crypto.Signer
+	publicKeys []ed25519.PublicKey
+	peers      []Peer
+	now        func() time.Time
+
+	mu     sync.Mutex
+	term   uint64    // highest term granted
+	holder int       // member holding this node's grant, or -1
+	until  time.Time // when this node's grant expires
+	rand   *mrand.Rand
+}
+
+// NewNode creates the election participant for member self
+// of the group identified by publicKeys,
+// signing with signer, which holds the private key of member self
+// as an ed25519.PrivateKey or, for a key kept in a token or KMS,
+// an ed25519.RemoteSigner, and reaching member i through peers[i].
+// The entry for self itself is ignored and may be nil.
+// The random seed for campaign backoff is drawn from rand,
+// or from the current time if rand is nil.
+func NewNode(self int, signer crypto.Signer,
+	publicKeys []ed25519.PublicKey, peers []Peer, rand io.Reader) (*Node, error) {
+
+	if len(peers) != len(publicKeys) || self < 0 || self >= len(publicKeys) {
+		return nil, errors.New("election: one peer per public key required")
+	}
+	seed := time.Now().UnixNano()
+	if rand != nil {
+		var b [8]byte
+		if _, err := io.ReadFull(rand, b[:]); err != nil {
+			return nil, err
+		}
+		seed = int64(binary.LittleEndian.Uint64(b[:]))
+	}
+	return &Node{
+		self:       self,
+		signer:     signer,
+		publicKeys: publicKeys,
+		peers:      peers,
+		now:        time.Now,
+		holder:     -1,
+		rand:       mrand.New(mrand.NewSource(seed)),
+	}, nil
+}
+
+func (n *Node) leaseDuration() time.Duration {
+	if n.LeaseDuration == 0 {
+		return DefaultLeaseDuration
+	}
+	return n.LeaseDuration
+}
+
+// RequestLease implements Peer for this node's side of the exchange.
+func (n *Node) RequestLease(ctx context.Context, req *LeaseRequest) (*LeaseResponse, error) {
+	if req.Candidate < 0 || req.Candidate >= len(n.publicKeys) ||
+		ed25519.VerifyErr(n.publicKeys[req.Candidate],
+			RequestMessage(req.Term, req.Candidate), req.Signature) != nil {
+		return nil, errors.New("election: bad lease request signature")
+	}
+
+	n.mu.Lock()
+	now := n.now()
+	active := n.holder >= 0 && now.Before(n.until)
+	if req.Term < n.term || active && n.holder != req.Candidate {
+		resp := &LeaseResponse{Term: n.term, Holder: n.holder}
+		n.mu.Unlock()
+		return resp, nil
+	}
+	n.term = req.Term
+	n.holder = req.Candidate
+	n.until = now.Add(n.leaseDuration())
+	n.mu.Unlock()
+
+	// A grant that fails to be signed is as good as lost in transit.
+	sig, err := ed25519.SignContext(ctx, n.signer, GrantMessage(req.Term, req.Candidate, n.self))
+	if err != nil {
+		return nil, err
+	}
+	return &LeaseResponse{
+		Granted:   true,
+		Term:      req.Term,
+		Holder:    req.Candidate,
+		Signature: sig,
+	}, nil
+}
+
+// Leader returns the member this node currently grants leadership to
+// and its term; ok is false if no lease is active.
+func (n *Node) Leader() (leader int, term uint64, ok bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.holder < 0 || !n.now().Before(n.until) {
+		return -1, n.term, false
+	}
+	return n.holder, n.term, true
+}
+
+// Campaign asks every member for a lease in a new term,
+// or renews the lease of the current term if this node holds it.
+// It returns the lease if a majority granted it.
+func (n *Node) Campaign(ctx context.Context) (*Lease, error) {
+	n.mu.Lock()
+	term := n.term + 1
+	if n.holder == n.self && n.now().Before(n.until) {
+		term = n.term // renewal
+	}
+	n.mu.Unlock()
+
+	start := n.now()
+	sig, err := ed25519.SignContext(ctx, n.signer, RequestMessage(term, n.self))
+	if err != nil {
+		return nil, err
+	}
+	req := &LeaseRequest{
+		Term:      term,
+		Candidate: n.self,
+		Signature: sig,
+	}
+	type reply struct {
+		i    int
+		resp *LeaseResponse
+	}
+	replies := make(chan reply, len(n.peers))
+	for i, p := range n.peers {
+		if i == n.self {
+			p = n
+		}
+		go func(i int, p Peer) {
+			resp, err := p.RequestLease(ctx, req)
+			if err != nil {
+				resp = nil
+			}
+			replies <- reply{i, resp}
+		}(i, p)
+	}
+
+	lease := &Lease{Term: term, Leader: n.self}
+	var highest uint64
+	for range n.peers {
+		r := <-replies
+		if r.resp == nil {
+			continue
+		}
+		if r.resp.Granted && r.resp.Term == term &&
+			ed25519.VerifyErr(n.publicKeys[r.i], GrantMessage(term, n.self, r.i), r.resp.Signature) == nil {
+			lease.Grants = append(lease.Grants, Grant{Voter: r.i, Signature: r.resp.Signature})
+		} else if r.resp.Term > highest {
+			highest = r.resp.Term
+		}
+	}
+
+	elected := len(lease.Grants) > len(n.publicKeys)/2
+	n.mu.Lock()
+	if !elected && n.holder == n.self && n.term == term {
+		// withdraw the vote for ourselves,
+		// so that a rival campaigning at the same time can win
+		n.holder = -1
+	}
+	if highest > n.term {
+		n.term = highest // outbid it in the next campaign
+	}
+	n.mu.Unlock()
+
+	if !elected {
+		return nil, ErrNotElected
+	}
+	lease.Expiry = start.Add(n.leaseDuration())
+	return lease, nil
+}
+
+// Run takes part in elections until ctx is done:
+// while this node leads, it renews its lease
+// every third of the lease duration;
+// while another member leads, it waits for that lease to run out;
+// and while nobody leads, it campaigns after a random backoff.
+// elected is called each time this node wins a new term,
+// typically to set protocol.Leader's ID and Term and start leading rounds.
+func (n *Node) Run(ctx context.Context, elected func(*Lease)) error {
+	d := n.leaseDuration()
+	var current *Lease
+	for {
+		var wait time.Duration
+		leader, _, ok := n.Leader()
+		switch {
+		case current != nil && n.now().Before(current.Expiry):
+			wait = d / 3
+		case ok && leader != n.self:
+			current = nil
+			n.mu.Lock()
+			wait = n.until.Sub(n.now())
+			n.mu.Unlock()
+		default:
+			current = nil
+			n.mu.Lock()
+			wait = time.Duration(n.rand.Int63n(int64(d/2) + 1))
+			n.mu.Unlock()
+		}
+
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+
+		if current == nil {
+			if leader, _, ok := n.Leader(); ok && leader != n.self {
+				continue
+			}
+		}
+		lease, err := n.Campaign(ctx)
+		if err != nil {
+			continue
+		}
+		if current == nil || lease.Term != current.Term {
+			if elected != nil {
+				elected(lease)
+			}
+		}
+		current = lease
+	}
+}
+
+// Validator returns a protocol.Validator that admits only rounds
+// from the member this node has granted an active lease,
+// announced under the granted term.
+func (n *Node) Validator() protocol.Validator {
+	return protocol.ValidatorFunc(func(ctx context.Context, _ []byte, _ protocol.Metadata) error {
+		leader, term, ok := n.Leader()
+		if !ok {
+			return errors.New("election: no leader elected")
+		}
+		if protocol.LeaderFromContext(ctx) != MemberID(n.publicKeys[leader]) ||
+			protocol.TermFromContext(ctx) != term {
+			return errors.New("election: round not from the leader of term " +
+				strconv.FormatUint(term, 10))
+		}
+		return nil
+	})
+}
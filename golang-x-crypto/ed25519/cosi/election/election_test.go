@@ -0,0 +1,199 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package election
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/golang-x-crypto/ed25519/cosi/protocol"
+)
+
+// link is a peer handle that can be cut.
+type link struct {
+	node *Node
+	down atomic.Bool
+}
+
+func (l *link) RequestLease(ctx context.Context, req *LeaseRequest) (*LeaseResponse, error) {
+	if l.down.Load() {
+		return nil, errors.New("link down")
+	}
+	return l.node.RequestLease(ctx, req)
+}
+
+type group struct {
+	pubs  []ed25519.PublicKey
+	nodes []*Node
+	links []*link
+	mu    sync.Mutex
+	now   time.Time
+}
+
+func newGroup(t *testing.T, n int) *group {
+	g := &group{now: time.Unix(1000, 0)}
+	var privs []ed25519.PrivateKey
+	for i := 0; i < n; i++ {
+		pub, priv, err := ed25519.GenerateKey(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.pubs = append(g.pubs, pub)
+		privs = append(privs, priv)
+		g.links = append(g.links, &link{})
+	}
+	peers := make([]Peer, n)
+	for i := range peers {
+		peers[i] = g.links[i]
+	}
+	for i := 0; i < n; i++ {
+		node, err := NewNode(i, privs[i], g.pubs, peers, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		node.LeaseDuration = time.Second
+		g.nodes = append(g.nodes, node)
+		g.links[i].node = node
+	}
+	return g
+}
+
+// manualClock makes every node use the group's clock.
+func (g *group) manualClock() {
+	for _, n := range g.nodes {
+		n.now = func() time.Time {
+			g.mu.Lock()
+			defer g.mu.Unlock()
+			return g.now
+		}
+	}
+}
+
+func (g *group) advance(d time.Duration) {
+	g.mu.Lock()
+	g.now = g.now.Add(d)
+	g.mu.Unlock()
+}
+
+func TestCampaign(t *testing.T) {
+	g := newGroup(t, 3)
+	g.manualClock()
+	ctx := context.Background()
+
+	lease, err := g.nodes[0].Campaign(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lease.Term != 1 || lease.Leader != 0 || !VerifyLease(g.pubs, lease) {
+		t.Errorf("bad lease %+v", lease)
+	}
+	if _, err := g.nodes[1].Campaign(ctx); err != ErrNotElected {
+		t.Errorf("rival candidate during lease: got %v", err)
+	}
+
+	// the leader renews within its term
+	g.advance(500 * time.Millisecond)
+	if lease, err = g.nodes[0].Campaign(ctx); err != nil || lease.Term != 1 {
+		t.Fatalf("renewal: %v, %+v", err, lease)
+	}
+
+	// the leader dies; once its leases expire another member takes over
+	g.links[0].down.Store(true)
+	if _, err := g.nodes[1].Campaign(ctx); err != ErrNotElected {
+		t.Errorf("takeover before expiry: got %v", err)
+	}
+	g.advance(1500 * time.Millisecond)
+	lease, err = g.nodes[1].Campaign(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lease.Term <= 1 || lease.Leader != 1 || !VerifyLease(g.pubs, lease) {
+		t.Errorf("bad lease %+v", lease)
+	}
+
+	// a lease with forged or too few grants does not verify
+	forged := *lease
+	forged.Leader = 2
+	if VerifyLease(g.pubs, &forged) {
+		t.Error("forged lease verified")
+	}
+	forged = *lease
+	forged.Grants = forged.Grants[:1]
+	if VerifyLease(g.pubs, &forged) {
+		t.Error("minority lease verified")
+	}
+
+	// members admit rounds only from the elected leader and term
+	_, priv, _ := ed25519.GenerateKey(nil)
+	svc := protocol.NewService(priv, nil)
+	svc.Validator = g.nodes[2].Validator()
+	check := func(leader int, term uint64) error {
+		_, err := svc.Commit(ctx, &protocol.CommitRequest{
+			SessionID: []byte{byte(leader), byte(term)},
+			Leader:    MemberID(g.pubs[leader]),
+			Term:      term,
+		})
+		return err
+	}
+	if err := check(1, lease.Term); err != nil {
+		t.Errorf("elected leader refused: %v", err)
+	}
+	if check(0, 1) == nil {
+		t.Error("deposed leader admitted")
+	}
+	if check(1, lease.Term+1) == nil {
+		t.Error("wrong term admitted")
+	}
+}
+
+func TestRunFailover(t *testing.T) {
+	g := newGroup(t, 3)
+	for _, n := range g.nodes {
+		n.LeaseDuration = 60 * time.Millisecond
+	}
+	elected := make(chan *Lease, 10)
+	cancels := make([]context.CancelFunc, len(g.nodes))
+	for i, n := range g.nodes {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancels[i] = cancel
+		go n.Run(ctx, func(l *Lease) { elected <- l })
+	}
+	defer func() {
+		for _, c := range cancels {
+			c()
+		}
+	}()
+
+	var first *Lease
+	select {
+	case first = <-elected:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no leader elected")
+	}
+
+	// kill the leader
+	cancels[first.Leader]()
+	g.links[first.Leader].down.Store(true)
+	deadline := time.After(5 * time.Second)
+	for {
+		select {
+		case l := <-elected:
+			if l.Leader == first.Leader {
+				continue
+			}
+			if l.Term <= first.Term {
+				t.Errorf("new leader in term %d after term %d", l.Term, first.Term)
+			}
+			return
+		case <-deadline:
+			t.Fatal("no failover")
+		}
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cosi
+
+import (
+	"encoding/binary"
+	"errors"
+	"time"
+
+	"test-server/golang-x-crypto/ed25519"
+)
+
+// Signed envelopes carry a message together with the time it was signed
+// and an optional expiry, both covered by the collective signature,
+// for short-lived statements such as authorization tokens.
+// The signed content is
+//
+//	"cosi-envelope-v1" || timestamp || expiry || message
+//
+// with the timestamp and expiry as big-endian Unix times in nanoseconds,
+// and a zero expiry meaning the envelope never expires.
+// A marshaled envelope is the signed content,
+// prefixed with its uint32 big-endian length,
+// followed by the signature.
+
+const envelopeDomain = "cosi-envelope-v1"
+
+var (
+	// ErrEnvelopeExpired is returned by VerifyEnvelope
+	// for an envelope past its expiry.
+	ErrEnvelopeExpired = errors.New("cosi: envelope expired")
+
+	// ErrEnvelopeNotYetValid is returned by VerifyEnvelope
+	// for an envelope whose timestamp lies in the future.
+	ErrEnvelopeNotYetValid = errors.New("cosi: envelope timestamp in the future")
+
+	// ErrEnvelopeStale is returned by VerifyEnvelope
+	// for an envelope older than the freshness window allows.
+	ErrEnvelopeStale = errors.New("cosi: envelope too old")
+)
+
+// Envelope is a message with a signing time and an optional expiry.
+type Envelope struct {
+	Message   []byte
+	Timestamp time.Time
+	Expiry    time.Time // zero if the envelope does not expire
+
+	// Signature is the collective signature on SignedContent,
+	// in the R || s || mask layout.
+	Signature []byte
+}
+
+// NewEnvelope returns an unsigned envelope for message
+// timestamped now and expiring after ttl, or never if ttl is zero.
+func NewEnvelope(message []byte, now time.Time, ttl time.Duration) *Envelope {
+	e := &Envelope{Message: message, Timestamp: now}
+	if ttl > 0 {
+		e.Expiry = now.Add(ttl)
+	}
+	return e
+}
+
+// SignedContent returns the bytes the cosigners sign for this envelope.
+func (e *Envelope) SignedContent() []byte {
+	b := make([]byte, 0, len(envelopeDomain)+16+len(e.Message))
+	b = append(b, envelopeDomain...)
+	b = binary.BigEndian.AppendUint64(b, uint64(e.Timestamp.UnixNano()))
+	var expiry int64
+	if !e.Expiry.IsZero() {
+		expiry = e.Expiry.UnixNano()
+	}
+	b = binary.BigEndian.AppendUint64(b, uint64(expiry))
+	return append(b, e.Message...)
+}
+
+// ParseEnvelopeContent decodes the signed content of an envelope,
+// as cosigners do to check an envelope before signing it.
+// The returned envelope has no signature.
+func ParseEnvelopeContent(content []byte) (*Envelope, error) {
+	if len(content) < len(envelopeDomain)+16 ||
+		string(content[:len(envelopeDomain)]) != envelopeDomain {
+		return nil, errors.New("cosi: not an envelope")
+	}
+	b := content[len(envelopeDomain):]
+	e := &Envelope{
+		Timestamp: time.Unix(0, int64(binary.BigEndian.Uint64(b))),
+		Message:   append([]byte{}, b[16:]...),
+	}
+	if expiry := int64(binary.BigEndian.Uint64(b[8:])); expiry != 0 {
+		e.Expiry = time.Unix(0, expiry)
+	}
+	return e, nil
+}
+
+// MarshalBinary encodes a signed envelope
+// as its length-prefixed signed content followed by the signature.
+func (e *Envelope) MarshalBinary() ([]byte, error) {
+	if len(e.Signature) < ed25519.SignatureSize {
+		return nil, errors.New("cosi: envelope not signed")
+	}
+	content := e.SignedContent()
+	b := binary.BigEndian.AppendUint32(nil, uint32(len(content)))
+	b = append(b, content...)
+	return append(b, e.Signature...), nil
+}
+
+// UnmarshalBinary decodes a signed envelope encoded by MarshalBinary.
+// It does not verify the signature.
+func (e *Envelope) UnmarshalBinary(data []byte) error {
+	if len(data) < 4 {
+		return errors.New("cosi: envelope too short")
+	}
+	n := binary.BigEndian.Uint32(data)
+	if uint64(len(data)-4) < uint64(n)+ed25519.SignatureSize {
+		return errors.New("cosi: envelope too short")
+	}
+	parsed, err := ParseEnvelopeContent(data[4 : 4+n])
+	if err != nil {
+		return err
+	}
+	parsed.Signature = append([]byte{}, data[4+n:]...)
+	*e = *parsed
+	return nil
+}
+
+// Freshness is the validity window VerifyEnvelope enforces.
+type Freshness struct {
+	// MaxAge, if nonzero, rejects envelopes signed longer ago than this,
+	// whether or not they carry an expiry.
+	MaxAge time.Duration
+
+	// Skew is the clock difference tolerated
+	// between the signers and the verifier.
+	Skew time.Duration
+
+	// Now returns the current time. If nil, time.Now is used.
+	Now func() time.Time
+}
+
+// Check reports whether an envelope's times
+// fall within the freshness window,
+// returning ErrEnvelopeNotYetValid, ErrEnvelopeExpired or ErrEnvelopeStale if not.
+func (f Freshness) Check(e *Envelope) error {
+	now := time.Now()
+	if f.Now != nil {
+		now = f.Now()
+	}
+	switch {
+	case e.Timestamp.After(now.Add(f.Skew)):
+		return ErrEnvelopeNotYetValid
+	case !e.Expiry.IsZero() && now.Add(-f.Skew).After(e.Expiry):
+		return ErrEnvelopeExpired
+	case f.MaxAge > 0 && now.Sub(e.Timestamp) > f.MaxAge+f.Skew:
+		return ErrEnvelopeStale
+	}
+	return nil
+}
+
+// VerifyEnvelope checks the collective signature on an envelope
+// under publicKeys and policy as described for Verify,
+// and then its times against the freshness window.
+// It returns the errors of Cosigners.VerifyErr for a bad signature,
+// and those of Freshness.Check for a signature outside its window.
+func VerifyEnvelope(publicKeys []ed25519.PublicKey, policy Policy,
+	e *Envelope, freshness Freshness) error {
+
+	if len(e.Signature) < ed25519.SignatureSize {
+		return ErrMalformedSignature
+	}
+	cos := NewCosigners(publicKeys, e.Signature[64:])
+	if cos == nil {
+		return ErrMalformedSignature
+	}
+	cos.SetPolicy(policy)
+	if err := cos.VerifyErr(e.SignedContent(), e.Signature); err != nil {
+		return err
+	}
+	return freshness.Check(e)
+}
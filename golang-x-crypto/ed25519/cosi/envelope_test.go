@@ -0,0 +1,58 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cosi
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestEnvelope(t *testing.T) {
+	n := 3
+	genKeys(n)
+	now := time.Unix(1700000000, 0)
+	e := NewEnvelope([]byte("grant access"), now, time.Minute)
+	e.Signature = testCosign(t, e.SignedContent(), priKeys[:n], NewCosigners(pubKeys[:n], nil))
+
+	b, err := e.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Envelope
+	if err := got.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Message, e.Message) || !got.Timestamp.Equal(e.Timestamp) ||
+		!got.Expiry.Equal(e.Expiry) || !bytes.Equal(got.Signature, e.Signature) {
+		t.Fatalf("round trip: got %+v, want %+v", got, e)
+	}
+
+	at := func(d time.Duration) Freshness {
+		return Freshness{Skew: time.Second, MaxAge: 30 * time.Second,
+			Now: func() time.Time { return now.Add(d) }}
+	}
+	for _, test := range []struct {
+		offset time.Duration
+		want   error
+	}{
+		{0, nil},
+		{20 * time.Second, nil},
+		{-500 * time.Millisecond, nil}, // within the skew
+		{-time.Minute, ErrEnvelopeNotYetValid},
+		{40 * time.Second, ErrEnvelopeStale},
+		{2 * time.Minute, ErrEnvelopeExpired},
+	} {
+		if err := VerifyEnvelope(pubKeys[:n], nil, &got, at(test.offset)); err != test.want {
+			t.Errorf("at %v: got %v, want %v", test.offset, err, test.want)
+		}
+	}
+
+	// the times are covered by the signature
+	got.Expiry = got.Expiry.Add(time.Hour)
+	if err := VerifyEnvelope(pubKeys[:n], nil, &got, at(0)); err != ErrInvalidSignature {
+		t.Errorf("extended expiry: got %v, want ErrInvalidSignature", err)
+	}
+}
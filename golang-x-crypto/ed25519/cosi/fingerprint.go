@@ -0,0 +1,38 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cosi
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// FingerprintSize is the size, in bytes, of a cosigner group fingerprint.
+const FingerprintSize = sha256.Size
+
+const fingerprintDomain = "cosi-group-v1"
+
+// Fingerprint returns a short identifier of the cosigner group:
+// a SHA-256 digest over the number of cosigners
+// and their public keys in order.
+// Two Cosigners objects have the same fingerprint exactly when
+// they were created from identical public key lists,
+// regardless of their current masks or policies,
+// so the fingerprint lets a verifier check that a signature
+// was produced for the group it expects.
+func (cos *Cosigners) Fingerprint() []byte {
+	var n [8]byte
+	binary.BigEndian.PutUint64(n[:], uint64(len(cos.keys)))
+
+	h := sha256.New()
+	h.Write([]byte(fingerprintDomain))
+	h.Write(n[:])
+	var keyBytes [32]byte
+	for i := range cos.keys {
+		cos.keys[i].ToBytes(&keyBytes)
+		h.Write(keyBytes[:])
+	}
+	return h.Sum(nil)
+}
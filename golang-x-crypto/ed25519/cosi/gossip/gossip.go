@@ -0,0 +1,437 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package gossip collects commits for large cosigner groups
+// by aggregating them among the cosigners themselves,
+// so the leader no longer receives one commit per cosigner.
+//
+// When a round starts, every member commits locally
+// and then runs ⌈log₂ n⌉ exchange steps.
+// In step k, member i swaps its partial aggregate with member i XOR 2ᵏ,
+// whose partial covers the disjoint block of 2ᵏ members next to its own,
+// and adds it to its own,
+// so after the last step every member holds the aggregate commit
+// of every member that took part, together with its participation mask.
+// A member whose partner does not answer within the step timeout
+// asks the other members of the partner's block for the same partial,
+// since they all hold it after step k-1,
+// and carries on without the block only if none of them answers;
+// the missing members then simply show as absent in the mask.
+//
+// The leader announces the round to every member,
+// then pulls the finished aggregate from a single member,
+// trying the next one if that member fails.
+// It asks member 0 first: when the group size is not a power of two,
+// members at the end of the group have no partner in some steps
+// and may finish with only part of the aggregate.
+// The response phase runs as in package protocol,
+// against the cosigners enabled in the aggregate's mask.
+// Since the leader never sees individual commits,
+// it cannot check individual signature parts;
+// if the final signature is invalid, Sign fails with ErrInvalidSignature
+// and the caller should fall back to a protocol.Leader round,
+// which identifies and excludes the culprit.
+package gossip
+
+import (
+	"context"
+	cryptorand "crypto/rand"
+	"errors"
+	"io"
+	"math/bits"
+	"strconv"
+	"sync"
+	"time"
+
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/golang-x-crypto/ed25519/cosi"
+	"test-server/golang-x-crypto/ed25519/cosi/protocol"
+)
+
+// DefaultStepTimeout bounds each exchange step
+// when Node.StepTimeout is zero.
+const DefaultStepTimeout = time.Second
+
+var (
+	// ErrUnknownSession is returned by Node.Result
+	// for a session the member never started.
+	ErrUnknownSession = errors.New("gossip: unknown session")
+
+	// ErrNoAggregate is returned by Sign
+	// when no member could supply an aggregate.
+	ErrNoAggregate = errors.New("gossip: no member produced an aggregate")
+
+	// ErrInvalidSignature is returned by Sign
+	// when the combined signature parts do not verify.
+	ErrInvalidSignature = errors.New("gossip: aggregated signature invalid")
+)
+
+// Partial is the aggregate commit of a set of members.
+type Partial struct {
+	Mask   []byte          // participation mask, in cosi's dense layout
+	Commit cosi.Commitment // sum of the enabled members' commits
+}
+
+// Message carries a member's partial aggregate
+// to its partner in one exchange step.
+type Message struct {
+	SessionID []byte
+	Step      int
+	From      int
+	Partial   Partial
+}
+
+// StartRequest announces a round to a member.
+type StartRequest struct {
+	SessionID []byte
+	Message   []byte
+	Leader    string
+	Term      uint64
+	Metadata  protocol.Metadata
+}
+
+// Member is a handle on one cosigner taking part in gossip rounds,
+// local or remote; a Node implements it.
+type Member interface {
+	// Gossip delivers a partner's partial aggregate.
+	Gossip(ctx context.Context, msg *Message) error
+
+	// Start makes the member commit and begin exchanging,
+	// returning without waiting for the exchange to finish.
+	Start(ctx context.Context, req *StartRequest) error
+
+	// Result waits for the member's exchange to finish
+	// and returns its aggregate.
+	Result(ctx context.Context, sessionID []byte) (*Partial, error)
+
+	// Partial waits for the member to reach the given step
+	// and returns the partial aggregate it sends in that step,
+	// for members whose own partner in that step is unreachable.
+	Partial(ctx context.Context, sessionID []byte, step int) (*Partial, error)
+}
+
+// Node is the gossip side of one cosigner,
+// committing through the cosigner's protocol.Service.
+// It is safe for concurrent use.
+type Node struct {
+	// StepTimeout bounds the wait for a partner in each step.
+	// If zero, DefaultStepTimeout is used.
+	StepTimeout time.Duration
+
+	self    int
+	n       int
+	service *protocol.Service
+	members []Member
+
+	mu       sync.Mutex
+	sessions map[string]*session
+}
+
+type session struct {
+	started bool
+	inbox   []chan *Message // one per step, buffered
+	sent    []*Partial      // partial sent in each step
+	reached []chan struct{} // closed once the step's partial is set
+	done    chan struct{}   // closed when the result is ready
+	result  *Partial
+}
+
+// NewNode creates the gossip side of member self
+// of a group of len(members) cosigners,
+// reaching member i through members[i] (the entry for self is unused)
+// and committing through service.
+func NewNode(self int, service *protocol.Service, members []Member) *Node {
+	return &Node{
+		self:     self,
+		n:        len(members),
+		service:  service,
+		members:  members,
+		sessions: make(map[string]*session),
+	}
+}
+
+// steps returns the number of exchange steps in a group of n members.
+func steps(n int) int {
+	return bits.Len(uint(n - 1))
+}
+
+func (nd *Node) session(id []byte) *session {
+	nd.mu.Lock()
+	defer nd.mu.Unlock()
+	s := nd.sessions[string(id)]
+	if s == nil {
+		s = &session{done: make(chan struct{}), sent: make([]*Partial, steps(nd.n))}
+		for k := 0; k < steps(nd.n); k++ {
+			s.inbox = append(s.inbox, make(chan *Message, 1))
+			s.reached = append(s.reached, make(chan struct{}))
+		}
+		nd.sessions[string(id)] = s
+	}
+	return s
+}
+
+// Gossip implements Member.
+func (nd *Node) Gossip(_ context.Context, msg *Message) error {
+	if msg.Step < 0 || msg.Step >= steps(nd.n) || msg.From != nd.self^(1<<uint(msg.Step)) {
+		return errors.New("gossip: message from a member that is not our partner")
+	}
+	s := nd.session(msg.SessionID)
+	select {
+	case s.inbox[msg.Step] <- msg:
+		return nil
+	default:
+		return errors.New("gossip: duplicate message")
+	}
+}
+
+// Start implements Member.
+func (nd *Node) Start(ctx context.Context, req *StartRequest) error {
+	resp, err := nd.service.Commit(ctx, &protocol.CommitRequest{
+		SessionID: req.SessionID,
+		Message:   req.Message,
+		Leader:    req.Leader,
+		Term:      req.Term,
+		Metadata:  req.Metadata,
+	})
+	if err != nil {
+		return err
+	}
+	s := nd.session(req.SessionID)
+	nd.mu.Lock()
+	s.started = true
+	nd.mu.Unlock()
+	mask := make([]byte, (nd.n+7)>>3)
+	for i := 0; i < nd.n; i++ {
+		if i != nd.self {
+			mask[i>>3] |= 1 << uint(i&7)
+		}
+	}
+	go nd.exchange(req.SessionID, s, &Partial{Mask: mask, Commit: resp.Commit})
+	return nil
+}
+
+// exchange runs the exchange steps and publishes the result.
+func (nd *Node) exchange(id []byte, s *session, own *Partial) {
+	timeout := nd.StepTimeout
+	if timeout == 0 {
+		timeout = DefaultStepTimeout
+	}
+	p := own
+	for k := 0; k < steps(nd.n); k++ {
+		s.sent[k] = p
+		close(s.reached[k])
+		partner := nd.self ^ (1 << uint(k))
+		if partner >= nd.n {
+			continue
+		}
+		msg := &Message{SessionID: id, Step: k, From: nd.self, Partial: *p}
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			nd.members[partner].Gossip(ctx, msg)
+		}()
+		var q *Partial
+		t := time.NewTimer(timeout)
+		select {
+		case msg := <-s.inbox[k]:
+			q = &msg.Partial
+		case <-t.C:
+			q = nd.fallback(id, partner, k, timeout)
+		}
+		t.Stop()
+		if q != nil {
+			if merged, err := nd.merge(p, q, partner, k); err == nil {
+				p = merged
+			}
+		}
+	}
+	s.result = p
+	close(s.done)
+
+	// forget the session if the leader never asks this member for it
+	time.AfterFunc(protocol.DefaultSessionTimeout, func() {
+		nd.mu.Lock()
+		if nd.sessions[string(id)] == s {
+			delete(nd.sessions, string(id))
+		}
+		nd.mu.Unlock()
+	})
+}
+
+// fallback asks the other members of partner's block in step k
+// for the partial the partner would have sent.
+func (nd *Node) fallback(id []byte, partner, k int, timeout time.Duration) *Partial {
+	lo := partner &^ (1<<uint(k) - 1)
+	for m := lo; m < lo+1<<uint(k) && m < nd.n; m++ {
+		if m == partner {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		q, err := nd.members[m].Partial(ctx, id, k)
+		cancel()
+		if err == nil && q != nil {
+			return q
+		}
+	}
+	return nil
+}
+
+// merge adds the partial of partner's block in step k to p,
+// refusing a partial that claims members outside that block,
+// which would otherwise be counted twice.
+func (nd *Node) merge(p, q *Partial, partner, k int) (*Partial, error) {
+	if len(q.Mask) != len(p.Mask) {
+		return nil, errors.New("gossip: bad mask length")
+	}
+	lo := partner &^ (1<<uint(k) - 1)
+	hi := lo + 1<<uint(k)
+	for i := 0; i < nd.n; i++ {
+		enabled := q.Mask[i>>3]&(1<<uint(i&7)) == 0
+		if enabled && (i < lo || i >= hi) {
+			return nil, errors.New("gossip: partial covers members outside the partner's block")
+		}
+	}
+	sum, err := cosi.SumCommits(p.Commit, q.Commit)
+	if err != nil {
+		return nil, err
+	}
+	return &Partial{Mask: cosi.MaskAnd(p.Mask, q.Mask), Commit: sum}, nil
+}
+
+// Partial implements Member.
+func (nd *Node) Partial(ctx context.Context, sessionID []byte, step int) (*Partial, error) {
+	if step < 0 || step >= steps(nd.n) {
+		return nil, errors.New("gossip: no such step")
+	}
+	nd.mu.Lock()
+	s := nd.sessions[string(sessionID)]
+	started := s != nil && s.started
+	nd.mu.Unlock()
+	if !started {
+		return nil, ErrUnknownSession
+	}
+	select {
+	case <-s.reached[step]:
+		return s.sent[step], nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Result implements Member.
+// The session's exchange state is released once the result is returned.
+func (nd *Node) Result(ctx context.Context, sessionID []byte) (*Partial, error) {
+	nd.mu.Lock()
+	s := nd.sessions[string(sessionID)]
+	started := s != nil && s.started
+	nd.mu.Unlock()
+	if !started {
+		return nil, ErrUnknownSession
+	}
+	select {
+	case <-s.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	nd.mu.Lock()
+	delete(nd.sessions, string(sessionID))
+	nd.mu.Unlock()
+	return s.result, nil
+}
+
+// Sign runs a collective signing round on message in gossip mode.
+// The round is announced to every member,
+// the aggregate commit is taken from the first member able to supply one,
+// and the signature parts are then requested
+// from the cosigners enabled in its mask through cosigners,
+// which are typically the members' protocol.Service handles.
+// The leader identity and term of the round are taken from leader.
+// Session identifiers are drawn from rand, or crypto/rand if rand is nil.
+func Sign(ctx context.Context, cos *cosi.Cosigners, members []Member,
+	cosigners []protocol.Cosigner, leader *protocol.Leader,
+	message []byte, rand io.Reader) ([]byte, error) {
+
+	if rand == nil {
+		rand = cryptorand.Reader
+	}
+	id := make([]byte, cosi.SessionIDSize)
+	if _, err := io.ReadFull(rand, id); err != nil {
+		return nil, err
+	}
+	start := &StartRequest{SessionID: id, Message: message}
+	if leader != nil {
+		start.Leader, start.Term = leader.ID, leader.Term
+	}
+	start.Metadata = protocol.MetadataFromContext(ctx)
+
+	var wg sync.WaitGroup
+	for _, m := range members {
+		wg.Add(1)
+		go func(m Member) {
+			defer wg.Done()
+			m.Start(ctx, start)
+		}(m)
+	}
+	wg.Wait()
+
+	var agg *Partial
+	for _, m := range members {
+		if p, err := m.Result(ctx, id); err == nil {
+			agg = p
+			break
+		}
+	}
+	if agg == nil {
+		return nil, ErrNoAggregate
+	}
+
+	// the aggregate's mask is dense;
+	// the caller's policy is applied when it verifies the signature
+	cos = cos.Clone()
+	cos.SetMaskFormat(cosi.DenseMask)
+	cos.SetPolicy(cosi.ThresholdPolicy(0))
+	cos.SetMask(agg.Mask)
+	aggK := cos.AggregatePublicKey()
+	req := &protocol.ChallengeRequest{
+		SessionID:       id,
+		Message:         message,
+		AggregateKey:    aggK,
+		AggregateCommit: agg.Commit,
+	}
+	n := cos.CountTotal()
+	parts := make([]cosi.SignaturePart, n)
+	errs := make([]error, n)
+	for i := 0; i < n; i++ {
+		if cos.MaskBit(i) == cosi.Disabled {
+			continue
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp, err := cosigners[i].Respond(ctx, req)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			parts[i] = resp.Part
+		}(i)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			return nil, errors.New("gossip: cosigner " + strconv.Itoa(i) + " failed to respond: " + err.Error())
+		}
+	}
+
+	s, err := cosi.SumParts(parts...)
+	if err != nil {
+		return nil, err
+	}
+	sig := append(append(append(make([]byte, 0, ed25519.SignatureSize+len(agg.Mask)),
+		agg.Commit...), s...), agg.Mask...)
+	if !cos.Verify(message, sig) {
+		return nil, ErrInvalidSignature
+	}
+	return sig, nil
+}
@@ -0,0 +1,95 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gossip
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/golang-x-crypto/ed25519/cosi"
+	"test-server/golang-x-crypto/ed25519/cosi/protocol"
+)
+
+var errDown = errors.New("member down")
+
+// offline is a member that never answers.
+type offline struct{}
+
+func (offline) Gossip(context.Context, *Message) error                 { return errDown }
+func (offline) Start(context.Context, *StartRequest) error             { return errDown }
+func (offline) Result(context.Context, []byte) (*Partial, error)       { return nil, errDown }
+func (offline) Partial(context.Context, []byte, int) (*Partial, error) { return nil, errDown }
+
+func newGroup(t *testing.T, n int, down ...int) (*cosi.Cosigners, []Member, []protocol.Cosigner) {
+	var pubs []ed25519.PublicKey
+	members := make([]Member, n)
+	cosigners := make([]protocol.Cosigner, n)
+	for i := 0; i < n; i++ {
+		pub, priv, err := ed25519.GenerateKey(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pubs = append(pubs, pub)
+		svc := protocol.NewService(priv, nil)
+		node := NewNode(i, svc, members)
+		node.StepTimeout = 50 * time.Millisecond
+		members[i] = node
+		cosigners[i] = svc
+	}
+	for _, i := range down {
+		members[i] = offline{}
+	}
+	return cosi.NewCosigners(pubs, nil), members, cosigners
+}
+
+func TestSign(t *testing.T) {
+	for _, n := range []int{1, 2, 5, 8} {
+		cos, members, cosigners := newGroup(t, n)
+		msg := []byte("message")
+		sig, err := Sign(context.Background(), cos, members, cosigners, nil, msg, nil)
+		if err != nil {
+			t.Fatalf("n=%d: %v", n, err)
+		}
+		if !cos.Verify(msg, sig) {
+			t.Errorf("n=%d: signature rejected", n)
+		}
+	}
+}
+
+func TestSignOffline(t *testing.T) {
+	cos, members, cosigners := newGroup(t, 8, 0, 5)
+	msg := []byte("message")
+	sig, err := Sign(context.Background(), cos, members, cosigners, nil, msg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cos.SetPolicy(cosi.ThresholdPolicy(6))
+	if !cos.Verify(msg, sig) {
+		t.Fatal("signature rejected")
+	}
+	for i := 0; i < 8; i++ {
+		want := cosi.Enabled
+		if i == 0 || i == 5 {
+			want = cosi.Disabled
+		}
+		if cos.MaskBit(i) != want {
+			t.Errorf("member %d: mask bit %v, want %v", i, cos.MaskBit(i), want)
+		}
+	}
+}
+
+func TestMergeRejectsOverlap(t *testing.T) {
+	nd := &Node{self: 0, n: 4}
+	own := &Partial{Mask: []byte{0x0e}, Commit: make(cosi.Commitment, 32)}
+	// in step 1 the partner of member 0 is member 2, whose block is {2, 3};
+	// a partial claiming member 0 would count it twice
+	claim := &Partial{Mask: []byte{0x02}, Commit: make(cosi.Commitment, 32)}
+	if _, err := nd.merge(own, claim, 2, 1); err == nil {
+		t.Error("overlapping partial merged")
+	}
+}
@@ -0,0 +1,266 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package groupenc implements encryption to a cosigner group,
+// so that the same group that collectively signs
+// can also receive sealed data.
+//
+// Data is encrypted with an ElGamal-style key agreement
+// against the group's aggregate public key A = a_1*B + ... + a_n*B
+// (as returned by cosi.Cosigners.AggregatePublicKey),
+// followed by AES-256-GCM under a key derived from the shared point r*A.
+// Decryption requires a decryption share a_i*(r*B)
+// from every cosigner whose key is part of the aggregate,
+// each accompanied by a Chaum-Pedersen proof
+// that it was computed with the cosigner's real private key,
+// so an invalid share can be detected and its author blamed.
+//
+// Because cosigners hold independent keys rather than shares
+// of a jointly generated secret,
+// the scheme is n-of-n over the chosen set of cosigners:
+// encrypt to the aggregate key of a subset (selected with a participation mask)
+// if not all cosigners are expected to be available for decryption.
+package groupenc
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	cryptorand "crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"errors"
+	"io"
+
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/golang-x-crypto/ed25519/internal/edwards25519"
+)
+
+const (
+	keyDomain   = "cosi-groupenc-key-v1"
+	proofDomain = "cosi-groupenc-dleq-v1"
+)
+
+// ShareSize is the size, in bytes, of an encoded decryption share:
+// the share point followed by the 64-byte proof.
+const ShareSize = 32 + 64
+
+// Ciphertext is data sealed to a group key.
+type Ciphertext struct {
+	Ephemeral []byte // r*B
+	Sealed    []byte // AES-256-GCM ciphertext and tag
+}
+
+// Encrypt seals plaintext to the given group (aggregate) public key,
+// authenticating but not encrypting additionalData.
+// Randomness is drawn from rand, or crypto/rand if rand is nil.
+func Encrypt(groupKey ed25519.PublicKey, plaintext, additionalData []byte,
+	rand io.Reader) (*Ciphertext, error) {
+
+	A, err := decodePoint(groupKey)
+	if err != nil {
+		return nil, err
+	}
+	r, err := randomScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+
+	var C1, S edwards25519.ExtendedGroupElement
+	edwards25519.GeScalarMultBase(&C1, &r)
+	edwards25519.GeScalarMult(&S, &r, A)
+	var c1Bytes, sBytes [32]byte
+	C1.ToBytes(&c1Bytes)
+	S.ToBytes(&sBytes)
+
+	aead, err := newAEAD(groupKey, c1Bytes[:], sBytes[:])
+	if err != nil {
+		return nil, err
+	}
+	return &Ciphertext{
+		Ephemeral: c1Bytes[:],
+		Sealed:    aead.Seal(nil, make([]byte, aead.NonceSize()), plaintext, additionalData),
+	}, nil
+}
+
+// DecryptShare computes the holder of privateKey's decryption share
+// for a ciphertext, together with a proof of its correctness.
+// Proof randomness is drawn from rand, or crypto/rand if rand is nil.
+func DecryptShare(privateKey ed25519.PrivateKey, ct *Ciphertext, rand io.Reader) ([]byte, error) {
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return nil, errors.New("groupenc: bad private key length")
+	}
+	C1, err := decodePoint(ct.Ephemeral)
+	if err != nil {
+		return nil, err
+	}
+
+	digest := sha512.Sum512(privateKey[:32])
+	var a [32]byte
+	copy(a[:], digest[:32])
+	a[0] &= 248
+	a[31] &= 63
+	a[31] |= 64
+
+	var D edwards25519.ExtendedGroupElement
+	edwards25519.GeScalarMult(&D, &a, C1)
+	var dBytes [32]byte
+	D.ToBytes(&dBytes)
+
+	// Chaum-Pedersen proof that log_B(A_i) = log_C1(D)
+	k, err := randomScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+	var T1, T2 edwards25519.ExtendedGroupElement
+	edwards25519.GeScalarMultBase(&T1, &k)
+	edwards25519.GeScalarMult(&T2, &k, C1)
+	var t1Bytes, t2Bytes [32]byte
+	T1.ToBytes(&t1Bytes)
+	T2.ToBytes(&t2Bytes)
+
+	c := proofChallenge(privateKey[32:], ct.Ephemeral, dBytes[:], t1Bytes[:], t2Bytes[:])
+	var z [32]byte
+	edwards25519.ScMulAdd(&z, &c, &a, &k) // z = c*a + k
+
+	share := make([]byte, 0, ShareSize)
+	share = append(share, dBytes[:]...)
+	share = append(share, c[:]...)
+	return append(share, z[:]...), nil
+}
+
+// VerifyShare checks a decryption share for a ciphertext
+// against the public key of the cosigner that produced it.
+func VerifyShare(publicKey ed25519.PublicKey, ct *Ciphertext, share []byte) bool {
+	if len(share) != ShareSize || share[63]&224 != 0 || share[95]&224 != 0 {
+		return false
+	}
+	Ai, err := decodePoint(publicKey)
+	if err != nil {
+		return false
+	}
+	C1, err := decodePoint(ct.Ephemeral)
+	if err != nil {
+		return false
+	}
+	D, err := decodePoint(share[:32])
+	if err != nil {
+		return false
+	}
+	var c, z [32]byte
+	copy(c[:], share[32:64])
+	copy(z[:], share[64:96])
+
+	// T1 = z*B - c*A_i
+	var negAi edwards25519.ExtendedGroupElement
+	negAi = *Ai
+	edwards25519.FeNeg(&negAi.X, &negAi.X)
+	edwards25519.FeNeg(&negAi.T, &negAi.T)
+	var T1 edwards25519.ProjectiveGroupElement
+	edwards25519.GeDoubleScalarMultVartime(&T1, &c, &negAi, &z)
+	var t1Bytes [32]byte
+	T1.ToBytes(&t1Bytes)
+
+	// T2 = z*C1 - c*D
+	var zC1, cD, T2 edwards25519.ExtendedGroupElement
+	edwards25519.GeScalarMultVartime(&zC1, &z, C1)
+	edwards25519.GeScalarMultVartime(&cD, &c, D)
+	T2.Sub(&zC1, &cD)
+	var t2Bytes [32]byte
+	T2.ToBytes(&t2Bytes)
+
+	want := proofChallenge(publicKey, ct.Ephemeral, share[:32], t1Bytes[:], t2Bytes[:])
+	return subtle.ConstantTimeCompare(want[:], c[:]) == 1
+}
+
+// Decrypt opens a ciphertext sealed to groupKey
+// given the decryption shares of every cosigner
+// whose key is part of the aggregate group key.
+// Shares should first be checked with VerifyShare;
+// Decrypt fails if any share is missing or wrong.
+func Decrypt(groupKey ed25519.PublicKey, ct *Ciphertext, shares [][]byte,
+	additionalData []byte) ([]byte, error) {
+
+	var S, D edwards25519.ExtendedGroupElement
+	S.Zero()
+	for _, share := range shares {
+		if len(share) != ShareSize {
+			return nil, errors.New("groupenc: bad share length")
+		}
+		var dBytes [32]byte
+		copy(dBytes[:], share[:32])
+		if !D.FromBytes(&dBytes) {
+			return nil, errors.New("groupenc: invalid share")
+		}
+		S.Add(&S, &D)
+	}
+	var sBytes [32]byte
+	S.ToBytes(&sBytes)
+
+	aead, err := newAEAD(groupKey, ct.Ephemeral, sBytes[:])
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Open(nil, make([]byte, aead.NonceSize()), ct.Sealed, additionalData)
+	if err != nil {
+		return nil, errors.New("groupenc: decryption failed")
+	}
+	return plaintext, nil
+}
+
+// newAEAD derives the single-use AES-256-GCM key for a ciphertext.
+// Since every key encrypts exactly one message, a zero nonce is safe.
+func newAEAD(groupKey ed25519.PublicKey, ephemeral, shared []byte) (cipher.AEAD, error) {
+	h := sha256.New()
+	h.Write([]byte(keyDomain))
+	h.Write(groupKey)
+	h.Write(ephemeral)
+	h.Write(shared)
+	block, err := aes.NewCipher(h.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func proofChallenge(publicKey, ephemeral, share, t1, t2 []byte) [32]byte {
+	h := sha512.New()
+	h.Write([]byte(proofDomain))
+	h.Write(publicKey)
+	h.Write(ephemeral)
+	h.Write(share)
+	h.Write(t1)
+	h.Write(t2)
+	var digest [64]byte
+	h.Sum(digest[:0])
+	var c [32]byte
+	edwards25519.ScReduce(&c, &digest)
+	return c
+}
+
+func randomScalar(rand io.Reader) ([32]byte, error) {
+	if rand == nil {
+		rand = cryptorand.Reader
+	}
+	var wide [64]byte
+	var s [32]byte
+	if _, err := io.ReadFull(rand, wide[:]); err != nil {
+		return s, err
+	}
+	edwards25519.ScReduce(&s, &wide)
+	return s, nil
+}
+
+func decodePoint(b []byte) (*edwards25519.ExtendedGroupElement, error) {
+	if len(b) != 32 {
+		return nil, errors.New("groupenc: bad point length")
+	}
+	var buf [32]byte
+	copy(buf[:], b)
+	var p edwards25519.ExtendedGroupElement
+	if !p.FromBytes(&buf) {
+		return nil, errors.New("groupenc: invalid point")
+	}
+	return &p, nil
+}
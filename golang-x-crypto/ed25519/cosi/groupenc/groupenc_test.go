@@ -0,0 +1,118 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package groupenc
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"testing"
+
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/golang-x-crypto/ed25519/cosi"
+	"test-server/golang-x-crypto/ed25519/internal/edwards25519"
+)
+
+type constReader struct{ val byte }
+
+func (cr constReader) Read(buf []byte) (int, error) {
+	for i := range buf {
+		buf[i] = cr.val
+	}
+	return len(buf), nil
+}
+
+func TestScalarMult(t *testing.T) {
+	var P edwards25519.ExtendedGroupElement
+	var seed [32]byte
+	edwards25519.GeScalarMultBase(&P, &seed)
+	for i := 0; i < 16; i++ {
+		digest := sha512.Sum512([]byte{byte(i)})
+		var a [32]byte
+		edwards25519.ScReduce(&a, &digest)
+		if i == 0 {
+			a = [32]byte{}
+		}
+
+		var want, got edwards25519.ExtendedGroupElement
+		var wantBytes, gotBytes, pBytes [32]byte
+		edwards25519.GeScalarMultVartime(&want, &a, &P)
+		edwards25519.GeScalarMult(&got, &a, &P)
+		want.ToBytes(&wantBytes)
+		got.ToBytes(&gotBytes)
+		if wantBytes != gotBytes {
+			t.Errorf("GeScalarMult mismatch for scalar %x", a)
+		}
+		P.ToBytes(&pBytes)
+		pBytes[0] ^= byte(i) // move to another point for the next case
+		for !P.FromBytes(&pBytes) {
+			pBytes[1]++
+		}
+	}
+}
+
+func TestGroupEncryption(t *testing.T) {
+	n := 4
+	pubs := make([]ed25519.PublicKey, n)
+	privs := make([]ed25519.PrivateKey, n)
+	for i := range pubs {
+		pubs[i], privs[i], _ = ed25519.GenerateKey(constReader{byte(i + 1)})
+	}
+	cos := cosi.NewCosigners(pubs, nil)
+	groupKey := cos.AggregatePublicKey()
+
+	plaintext, aad := []byte("sealed for the group"), []byte("header")
+	ct, err := Encrypt(groupKey, plaintext, aad, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	shares := make([][]byte, n)
+	for i := range shares {
+		if shares[i], err = DecryptShare(privs[i], ct, nil); err != nil {
+			t.Fatal(err)
+		}
+		if !VerifyShare(pubs[i], ct, shares[i]) {
+			t.Errorf("valid share %d rejected", i)
+		}
+	}
+	if VerifyShare(pubs[1], ct, shares[0]) {
+		t.Errorf("share verified against wrong key")
+	}
+	bad := append([]byte{}, shares[2]...)
+	copy(bad[:32], shares[3][:32])
+	if VerifyShare(pubs[2], ct, bad) {
+		t.Errorf("share with substituted point accepted")
+	}
+
+	got, err := Decrypt(groupKey, ct, shares, aad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Errorf("decrypted %q", got)
+	}
+	if _, err := Decrypt(groupKey, ct, shares[:n-1], aad); err == nil {
+		t.Errorf("decryption succeeded with a missing share")
+	}
+	if _, err := Decrypt(groupKey, ct, shares, nil); err == nil {
+		t.Errorf("decryption succeeded with wrong additional data")
+	}
+
+	// Encrypting to a subset needs only that subset's shares.
+	cos.SetMaskBit(1, cosi.Disabled)
+	ct, err = Encrypt(cos.AggregatePublicKey(), plaintext, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var subset [][]byte
+	for _, i := range []int{0, 2, 3} {
+		share, _ := DecryptShare(privs[i], ct, nil)
+		subset = append(subset, share)
+	}
+	if got, err := Decrypt(cos.AggregatePublicKey(), ct, subset, nil); err != nil ||
+		!bytes.Equal(got, plaintext) {
+		t.Errorf("subset decryption failed: %v", err)
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cosi
+
+import (
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"strconv"
+
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/golang-x-crypto/ed25519/internal/edwards25519"
+)
+
+// Half-aggregation compresses a batch of Schnorr signatures
+// over distinct messages, each under its own (aggregate) public key,
+// into a single proof consisting of every signature's commit R_i
+// followed by one combined response scalar
+//
+//	s = z_1*s_1 + z_2*s_2 + ... + z_n*s_n,
+//
+// where the z_i are derived by hashing the entire batch.
+// The result is 32*(n+1) bytes instead of 64*n bytes.
+// Verification checks the single equation
+//
+//	s*B = sum of z_i*(R_i + c_i*A_i),
+//
+// with c_i the usual Ed25519 challenge H(R_i || A_i || m_i).
+// See Chalkias, Garillot, Kondi and Nikolaenko,
+// "Non-interactive half-aggregation of EdDSA and variants of Schnorr
+// signatures", https://eprint.iacr.org/2021/350.
+//
+// Participation masks are not part of the half-aggregate:
+// each A_i must be the aggregate public key of the cosigners
+// that actually produced the i-th signature,
+// as returned by AggregatePublicKey after setting the signature's mask.
+
+const halfAggDomain = "cosi-halfagg-v1"
+
+// HalfAggregate combines collective signatures on distinct messages
+// into a single half-aggregated proof.
+// The i-th signature must have been produced over messages[i]
+// under the aggregate public key keys[i].
+// Only the first 64 bytes (R || s) of each signature are used;
+// any trailing participation mask is dropped.
+func HalfAggregate(keys []ed25519.PublicKey, messages, sigs [][]byte) ([]byte, error) {
+	n := len(sigs)
+	if n == 0 {
+		return nil, errors.New("cosi: no signatures to aggregate")
+	}
+	if len(keys) != n || len(messages) != n {
+		return nil, errors.New("cosi: mismatched batch lengths")
+	}
+
+	agg := make([]byte, 32*(n+1))
+	for i, sig := range sigs {
+		if len(sig) < ed25519.SignatureSize || sig[63]&224 != 0 {
+			return nil, errors.New("cosi: malformed signature " + strconv.Itoa(i))
+		}
+		if len(keys[i]) != ed25519.PublicKeySize {
+			return nil, errors.New("cosi: bad public key length " + strconv.Itoa(i))
+		}
+		copy(agg[32*i:], sig[:32])
+	}
+
+	z := halfAggCoefficients(keys, messages, agg[:32*n])
+	var s, si [32]byte
+	for i, sig := range sigs {
+		copy(si[:], sig[32:64])
+		edwards25519.ScMulAdd(&s, &z[i], &si, &s)
+	}
+	copy(agg[32*n:], s[:])
+	return agg, nil
+}
+
+// VerifyHalfAggregate reports whether agg is a valid half-aggregate
+// of signatures on messages[i] under the public keys keys[i].
+func VerifyHalfAggregate(keys []ed25519.PublicKey, messages [][]byte, agg []byte) bool {
+	n := len(keys)
+	if n == 0 || len(messages) != n || len(agg) != 32*(n+1) {
+		return false
+	}
+	if agg[len(agg)-1]&224 != 0 {
+		return false
+	}
+
+	z := halfAggCoefficients(keys, messages, agg[:32*n])
+
+	var sum, P edwards25519.ExtendedGroupElement
+	var A, R edwards25519.ExtendedGroupElement
+	var buf [32]byte
+	var digest [64]byte
+	var c, zc [32]byte
+	sum.Zero()
+	for i := 0; i < n; i++ {
+		if len(keys[i]) != ed25519.PublicKeySize {
+			return false
+		}
+		copy(buf[:], keys[i])
+		if !A.FromBytes(&buf) {
+			return false
+		}
+		copy(buf[:], agg[32*i:])
+		if !R.FromBytes(&buf) {
+			return false
+		}
+
+		h := sha512.New()
+		h.Write(agg[32*i : 32*i+32])
+		h.Write(keys[i])
+		h.Write(messages[i])
+		h.Sum(digest[:0])
+		edwards25519.ScReduce(&c, &digest)
+
+		// sum += z_i*R_i + (z_i*c_i)*A_i
+		edwards25519.ScMulAdd(&zc, &z[i], &c, &scZero)
+		edwards25519.GeScalarMultVartime(&P, &z[i], &R)
+		sum.Add(&sum, &P)
+		edwards25519.GeScalarMultVartime(&P, &zc, &A)
+		sum.Add(&sum, &P)
+	}
+
+	var s [32]byte
+	copy(s[:], agg[32*n:])
+	edwards25519.GeScalarMultBase(&P, &s)
+
+	var lhs, rhs [32]byte
+	P.ToBytes(&lhs)
+	sum.ToBytes(&rhs)
+	return lhs == rhs
+}
+
+var scZero [32]byte
+
+// halfAggCoefficients derives the per-signature coefficients z_i
+// from a digest binding every (R_i, A_i, m_i) triple in the batch.
+func halfAggCoefficients(keys []ed25519.PublicKey, messages [][]byte,
+	commits []byte) [][32]byte {
+
+	var lenBuf [8]byte
+	h := sha512.New()
+	h.Write([]byte(halfAggDomain))
+	for i := range keys {
+		h.Write(commits[32*i : 32*i+32])
+		h.Write(keys[i])
+		binary.LittleEndian.PutUint64(lenBuf[:], uint64(len(messages[i])))
+		h.Write(lenBuf[:])
+		h.Write(messages[i])
+	}
+	transcript := h.Sum(nil)
+
+	z := make([][32]byte, len(keys))
+	var digest [64]byte
+	for i := range z {
+		h.Reset()
+		h.Write(transcript)
+		binary.LittleEndian.PutUint64(lenBuf[:], uint64(i))
+		h.Write(lenBuf[:])
+		h.Sum(digest[:0])
+		edwards25519.ScReduce(&z[i], &digest)
+	}
+	return z
+}
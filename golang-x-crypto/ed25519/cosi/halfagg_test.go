@@ -0,0 +1,59 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cosi
+
+import (
+	"testing"
+
+	"test-server/golang-x-crypto/ed25519"
+)
+
+func TestHalfAggregate(t *testing.T) {
+	n := 5
+	genKeys(n)
+
+	// Mix collective signatures from groups of different sizes
+	// with a plain individual Ed25519 signature.
+	var keys []ed25519.PublicKey
+	var msgs, sigs [][]byte
+	for i := 1; i <= 3; i++ {
+		cos := NewCosigners(pubKeys[:i+1], nil)
+		msg := []byte{'m', byte(i)}
+		keys = append(keys, cos.AggregatePublicKey())
+		msgs = append(msgs, msg)
+		sigs = append(sigs, testCosign(t, msg, priKeys[:i+1], cos))
+	}
+	keys = append(keys, pubKeys[4])
+	msgs = append(msgs, rightMessage)
+	sigs = append(sigs, ed25519.Sign(priKeys[4], rightMessage))
+
+	agg, err := HalfAggregate(keys, msgs, sigs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(agg) != 32*(len(sigs)+1) {
+		t.Errorf("half-aggregate has length %d", len(agg))
+	}
+	if !VerifyHalfAggregate(keys, msgs, agg) {
+		t.Errorf("valid half-aggregate rejected")
+	}
+
+	msgs[1] = wrongMessage
+	if VerifyHalfAggregate(keys, msgs, agg) {
+		t.Errorf("half-aggregate accepted with a wrong message")
+	}
+	msgs[1] = []byte{'m', 2}
+
+	keys[0], keys[2] = keys[2], keys[0]
+	if VerifyHalfAggregate(keys, msgs, agg) {
+		t.Errorf("half-aggregate accepted with permuted keys")
+	}
+	keys[0], keys[2] = keys[2], keys[0]
+
+	agg[len(agg)-32] ^= 1
+	if VerifyHalfAggregate(keys, msgs, agg) {
+		t.Errorf("half-aggregate accepted with corrupted response")
+	}
+}
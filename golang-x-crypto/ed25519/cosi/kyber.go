@@ -0,0 +1,88 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cosi
+
+import (
+	"errors"
+
+	"test-server/golang-x-crypto/ed25519"
+)
+
+// Interoperability with the CoSi implementation in the dedis/kyber library
+// (package go.dedis.ch/kyber/sign/cosi, as used by the cothority stack)
+// over the Ed25519 suite.
+//
+// Kyber signatures use the same R || s || mask layout
+// and the same challenge H(R || A || m) over SHA-512,
+// with A the aggregate of the participating cosigners' keys.
+// The only difference on the wire is the polarity of the mask:
+// kyber sets a bit for each cosigner that *participated*,
+// whereas this package sets a bit for each cosigner that was *absent*.
+// Kyber signatures also always carry a mask,
+// while this package accepts mask-less compact signatures.
+
+// FromKyberSignature converts a collective signature in kyber's encoding
+// into this package's R || s || mask layout.
+func (cos *Cosigners) FromKyberSignature(sig []byte) ([]byte, error) {
+	return cos.flipMask(sig)
+}
+
+// ToKyberSignature converts a collective signature in this package's layout
+// into kyber's encoding.
+// A compact signature without a mask is taken
+// to have been produced by all cosigners.
+func (cos *Cosigners) ToKyberSignature(sig []byte) ([]byte, error) {
+	if len(sig) == ed25519.SignatureSize {
+		sig = append(sig[:ed25519.SignatureSize:ed25519.SignatureSize],
+			make([]byte, cos.MaskLen())...)
+	}
+	return cos.flipMask(sig)
+}
+
+// flipMask inverts the participation bits of a full-length signature,
+// leaving any padding bits in the last mask byte clear.
+func (cos *Cosigners) flipMask(sig []byte) ([]byte, error) {
+	if len(sig) != ed25519.SignatureSize+cos.MaskLen() {
+		return nil, errors.New("cosi: bad signature length for this cosigner group")
+	}
+	out := make([]byte, len(sig))
+	copy(out, sig[:ed25519.SignatureSize])
+	mask := sig[ed25519.SignatureSize:]
+	for i := range cos.keys {
+		byt := i >> 3
+		bit := byte(1) << uint(i&7)
+		if mask[byt]&bit == 0 {
+			out[ed25519.SignatureSize+byt] |= bit
+		}
+	}
+	return out, nil
+}
+
+// VerifyKyber is like Verify,
+// but accepts a collective signature in kyber's encoding.
+// As with Verify, the participation mask of the Cosigners object
+// is updated to the set of cosigners that produced the signature
+// before the policy is consulted.
+func (cos *Cosigners) VerifyKyber(message, sig []byte) bool {
+	converted, err := cos.FromKyberSignature(sig)
+	if err != nil {
+		return false
+	}
+	return cos.Verify(message, converted)
+}
+
+// VerifyKyber checks a collective signature in kyber's encoding
+// on a given message, using a given list of public keys and policy,
+// in the same way as Verify does for this package's own signatures.
+func VerifyKyber(publicKeys []ed25519.PublicKey, policy Policy,
+	message, sig []byte) bool {
+
+	cos := NewCosigners(publicKeys, nil)
+	if cos == nil {
+		return false
+	}
+	cos.SetPolicy(policy)
+	return cos.VerifyKyber(message, sig)
+}
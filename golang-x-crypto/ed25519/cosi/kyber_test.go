@@ -0,0 +1,58 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cosi
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestKyberInterop(t *testing.T) {
+	n := 10
+	genKeys(n)
+	parts := make([]*Participant, n)
+	for i := range parts {
+		parts[i] = NewParticipant(priKeys[i], nil)
+	}
+	sig := runSession(t, NewCosigners(pubKeys[:n], nil), parts, 3, map[int]bool{2: true})
+
+	cos := NewCosigners(pubKeys[:n], nil)
+	ksig, err := cos.ToKyberSignature(sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// kyber marks participants: everyone but cosigner 2, no padding bits
+	if ksig[64] != 0xfb || ksig[65] != 0x03 {
+		t.Errorf("kyber mask %x, want fb03", ksig[64:])
+	}
+
+	if VerifyKyber(pubKeys[:n], nil, rightMessage, ksig) {
+		t.Errorf("partial kyber signature accepted under full policy")
+	}
+	if !VerifyKyber(pubKeys[:n], ThresholdPolicy(n-1), rightMessage, ksig) {
+		t.Errorf("valid kyber signature rejected")
+	}
+	if VerifyKyber(pubKeys[:n], ThresholdPolicy(n-1), wrongMessage, ksig) {
+		t.Errorf("kyber signature on different message accepted")
+	}
+
+	back, err := cos.FromKyberSignature(ksig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(back, sig) {
+		t.Errorf("kyber conversion does not round-trip")
+	}
+
+	// Compact signatures convert to an all-participating kyber mask.
+	full := runSession(t, NewCosigners(pubKeys[:n], nil), parts, 4, nil)
+	ksig, err = cos.ToKyberSignature(full[:64])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyKyber(pubKeys[:n], nil, rightMessage, ksig) {
+		t.Errorf("valid compact signature rejected after conversion")
+	}
+}
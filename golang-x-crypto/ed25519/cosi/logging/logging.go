@@ -0,0 +1,68 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package logging defines the structured logging interface
+// threaded through the collective signing protocol and servers.
+//
+// Log records carry alternating key-value arguments as in log/slog,
+// and the protocol tags every record of a signing round
+// with the round's correlation ID under the key "round",
+// so all events of one round (attempts, exclusions of cosigners,
+// blame for invalid signature parts, the outcome)
+// can be collected with a single query.
+// A *slog.Logger satisfies Logger directly.
+package logging
+
+import "log/slog"
+
+// Logger receives structured log records.
+// Implementations must be safe for concurrent use.
+type Logger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+// Discard is a Logger that drops every record.
+var Discard Logger = discard{}
+
+type discard struct{}
+
+func (discard) Debug(string, ...any) {}
+func (discard) Info(string, ...any)  {}
+func (discard) Warn(string, ...any)  {}
+func (discard) Error(string, ...any) {}
+
+// NewSlog returns a Logger writing to the given slog handler.
+func NewSlog(h slog.Handler) Logger {
+	return slog.New(h)
+}
+
+// With returns a Logger that adds args to every record logged through l.
+func With(l Logger, args ...any) Logger {
+	switch l := l.(type) {
+	case *slog.Logger:
+		return l.With(args...)
+	case discard:
+		return l
+	case *withLogger:
+		return &withLogger{l.l, append(append([]any{}, l.args...), args...)}
+	}
+	return &withLogger{l, args}
+}
+
+type withLogger struct {
+	l    Logger
+	args []any
+}
+
+func (w *withLogger) Debug(msg string, args ...any) { w.l.Debug(msg, w.join(args)...) }
+func (w *withLogger) Info(msg string, args ...any)  { w.l.Info(msg, w.join(args)...) }
+func (w *withLogger) Warn(msg string, args ...any)  { w.l.Warn(msg, w.join(args)...) }
+func (w *withLogger) Error(msg string, args ...any) { w.l.Error(msg, w.join(args)...) }
+
+func (w *withLogger) join(args []any) []any {
+	return append(append([]any{}, w.args...), args...)
+}
@@ -0,0 +1,82 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"test-server/golang-x-crypto/ed25519/cosi/cositest"
+	"test-server/golang-x-crypto/ed25519/cosi/logging"
+)
+
+func TestRoundCorrelation(t *testing.T) {
+	nw, err := cositest.NewNetwork(5, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nw.Close()
+	nw.Leader.Threshold = 4
+	nw.SetFault(3, &cositest.Fault{Phase: cositest.ResponsePhase, Corrupt: true})
+
+	var buf bytes.Buffer
+	nw.Leader.Logger = logging.NewSlog(slog.NewJSONHandler(&buf,
+		&slog.HandlerOptions{Level: slog.LevelDebug}))
+	if _, err := nw.Sign(context.Background(), []byte("message")); err != nil {
+		t.Fatal(err)
+	}
+
+	var round string
+	msgs := map[string]bool{}
+	for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+		var rec map[string]any
+		if err := json.Unmarshal(line, &rec); err != nil {
+			t.Fatal(err)
+		}
+		id, _ := rec["round"].(string)
+		if id == "" || round != "" && id != round {
+			t.Errorf("record %s has round %q, want %q", line, id, round)
+		}
+		round = id
+		msgs[rec["msg"].(string)] = true
+		if rec["msg"] == "cosigner blamed" && rec["cosigner"] != 3.0 {
+			t.Errorf("blamed cosigner %v, want 3", rec["cosigner"])
+		}
+	}
+	for _, want := range []string{"attempt started", "cosigner blamed", "signing round completed"} {
+		if !msgs[want] {
+			t.Errorf("no %q record in:\n%s", want, buf.String())
+		}
+	}
+}
+
+type recorder struct{ records [][]any }
+
+func (r *recorder) Debug(msg string, args ...any) { r.log(msg, args) }
+func (r *recorder) Info(msg string, args ...any)  { r.log(msg, args) }
+func (r *recorder) Warn(msg string, args ...any)  { r.log(msg, args) }
+func (r *recorder) Error(msg string, args ...any) { r.log(msg, args) }
+
+func (r *recorder) log(msg string, args []any) {
+	r.records = append(r.records, append([]any{msg}, args...))
+}
+
+func TestWith(t *testing.T) {
+	r := &recorder{}
+	l := logging.With(logging.With(r, "a", 1), "b", 2)
+	l.Info("x", "c", 3)
+	want := []any{"x", "a", 1, "b", 2, "c", 3}
+	if len(r.records) != 1 || len(r.records[0]) != len(want) {
+		t.Fatalf("got %v, want %v", r.records, want)
+	}
+	for i := range want {
+		if r.records[0][i] != want[i] {
+			t.Errorf("got %v, want %v", r.records[0], want)
+		}
+	}
+}
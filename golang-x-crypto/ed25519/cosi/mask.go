@@ -0,0 +1,80 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cosi
+
+// Set algebra on participation masks in the layout described in SetMask.
+// Recall that a set bit marks a *disabled* cosigner,
+// so bitwise operations on masks correspond to the dual operations
+// on the sets of enabled cosigners.
+// As in SetMask, a mask shorter than another is taken to be
+// extended with zero bytes, i.e., with Enabled cosigners.
+
+// MaskAnd returns the bitwise AND of two masks,
+// which enables every cosigner enabled in either a or b.
+func MaskAnd(a, b []byte) []byte {
+	return maskOp(a, b, func(x, y byte) byte { return x & y })
+}
+
+// MaskOr returns the bitwise OR of two masks,
+// which enables only the cosigners enabled in both a and b.
+func MaskOr(a, b []byte) []byte {
+	return maskOp(a, b, func(x, y byte) byte { return x | y })
+}
+
+// MaskXor returns the bitwise XOR of two masks,
+// which has a bit set for each cosigner enabled in exactly one of a and b.
+func MaskXor(a, b []byte) []byte {
+	return maskOp(a, b, func(x, y byte) byte { return x ^ y })
+}
+
+func maskOp(a, b []byte, op func(x, y byte) byte) []byte {
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+	out := make([]byte, len(a))
+	for i := range a {
+		var y byte
+		if i < len(b) {
+			y = b[i]
+		}
+		out[i] = op(a[i], y)
+	}
+	return out
+}
+
+// MaskSubset reports whether every one of the first n cosigners
+// that is enabled in a is also enabled in b.
+// For example, a leader can check that every cosigner that responded
+// is among those that committed.
+func MaskSubset(a, b []byte, n int) bool {
+	for i := 0; i < n; i++ {
+		if !maskDisabled(a, i) && maskDisabled(b, i) {
+			return false
+		}
+	}
+	return true
+}
+
+// MaskDiff compares two masks over the first n cosigners,
+// returning in ascending order the indices of the cosigners
+// enabled in to but not in from (added)
+// and those enabled in from but not in to (removed).
+func MaskDiff(from, to []byte, n int) (added, removed []int) {
+	for i := 0; i < n; i++ {
+		was, is := !maskDisabled(from, i), !maskDisabled(to, i)
+		switch {
+		case is && !was:
+			added = append(added, i)
+		case was && !is:
+			removed = append(removed, i)
+		}
+	}
+	return added, removed
+}
+
+func maskDisabled(mask []byte, i int) bool {
+	byt := i >> 3
+	return byt < len(mask) && mask[byt]&(1<<uint(i&7)) != 0
+}
@@ -0,0 +1,46 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cosi
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestMaskAlgebra(t *testing.T) {
+	a := []byte{0x05, 0x01} // cosigners 0, 2 and 8 disabled
+	b := []byte{0x06}       // cosigners 1 and 2 disabled
+
+	if got := MaskAnd(a, b); !bytes.Equal(got, []byte{0x04, 0x00}) {
+		t.Errorf("MaskAnd = %x", got)
+	}
+	if got := MaskOr(a, b); !bytes.Equal(got, []byte{0x07, 0x01}) {
+		t.Errorf("MaskOr = %x", got)
+	}
+	if got := MaskXor(b, a); !bytes.Equal(got, []byte{0x03, 0x01}) {
+		t.Errorf("MaskXor = %x", got)
+	}
+
+	n := 10
+	if MaskSubset(b, a, n) {
+		t.Errorf("MaskSubset(b, a) = true")
+	}
+	if !MaskSubset(MaskOr(a, b), a, n) || !MaskSubset(a, MaskAnd(a, b), n) {
+		t.Errorf("MaskSubset rejects a subset")
+	}
+	// padding bits beyond n are ignored
+	if !MaskSubset([]byte{0x00, 0x00}, []byte{0x00, 0xfc}, n) {
+		t.Errorf("MaskSubset considers padding bits")
+	}
+
+	added, removed := MaskDiff(a, b, n)
+	if !reflect.DeepEqual(added, []int{0, 8}) || !reflect.DeepEqual(removed, []int{1}) {
+		t.Errorf("MaskDiff = %v, %v", added, removed)
+	}
+	if added, removed := MaskDiff(a, a, n); added != nil || removed != nil {
+		t.Errorf("MaskDiff of equal masks = %v, %v", added, removed)
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cosi
+
+import (
+	"crypto/subtle"
+	"errors"
+	"strconv"
+
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/golang-x-crypto/ed25519/internal/edwards25519"
+)
+
+// MergeSignatures combines two partial collective signatures
+// produced by disjoint subsets of this cosigner group
+// into a single collective signature.
+//
+// This supports tree-structured signing, in which each sub-leader
+// collects signature parts from only part of the group.
+// All cosigners must nevertheless have signed with the same
+// aggregate commit and aggregate public key,
+// i.e., the commit phase must have been common to both subsets,
+// so both partial signatures carry the same R.
+// Each partial signature must be in the R || s || mask layout accepted
+// by Verify, where s is the sum of the subset's signature parts
+// and the mask disables every cosigner outside the subset.
+//
+// The merged signature's mask enables the union of the two subsets,
+// and its s is the sum of the two partial responses.
+// MergeSignatures returns an error if the signatures are malformed,
+// carry different commits, or have a cosigner enabled in both.
+func (cos *Cosigners) MergeSignatures(sig1, sig2 []byte) ([]byte, error) {
+	sigLen := ed25519.SignatureSize + cos.MaskLen()
+	if len(sig1) != sigLen || len(sig2) != sigLen {
+		return nil, errors.New("cosi: partial signatures must carry a full mask")
+	}
+	if subtle.ConstantTimeCompare(sig1[:32], sig2[:32]) != 1 {
+		return nil, errors.New("cosi: partial signatures have different commits")
+	}
+	if sig1[63]&224 != 0 || sig2[63]&224 != 0 {
+		return nil, errors.New("cosi: non-canonical partial response")
+	}
+
+	mask1, mask2 := sig1[64:], sig2[64:]
+	for i := range cos.keys {
+		byt := i >> 3
+		bit := byte(1) << uint(i&7)
+		if mask1[byt]&bit == 0 && mask2[byt]&bit == 0 {
+			return nil, errors.New("cosi: cosigner " + strconv.Itoa(i) +
+				" appears in both partial signatures")
+		}
+	}
+
+	var s, s1, s2 [32]byte
+	copy(s1[:], sig1[32:64])
+	copy(s2[:], sig2[32:64])
+	edwards25519.ScMulAdd(&s, &scOne, &s1, &s2)
+
+	sig := make([]byte, sigLen)
+	copy(sig[:32], sig1[:32])
+	copy(sig[32:64], s[:])
+	for i := range mask1 {
+		sig[64+i] = mask1[i] & mask2[i] // disabled only if disabled in both
+	}
+	return sig, nil
+}
@@ -0,0 +1,60 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cosi
+
+import "testing"
+
+func TestMergeSignatures(t *testing.T) {
+	n := 6
+	genKeys(n)
+	cos := NewCosigners(pubKeys[:n], nil)
+
+	// Common commit phase across the whole group
+	commits := make([]Commitment, n)
+	secrets := make([]*Secret, n)
+	for i := range commits {
+		commits[i], secrets[i], _ = Commit(nil)
+	}
+	aggK := cos.AggregatePublicKey()
+	aggR := cos.AggregateCommit(commits)
+	parts := make([]SignaturePart, n)
+	for i := range parts {
+		parts[i] = Cosign(priKeys[i], secrets[i], rightMessage, aggK, aggR)
+	}
+
+	// Each sub-leader aggregates only its own half of the group.
+	partial := func(lo, hi int) []byte {
+		sub := NewCosigners(pubKeys[:n], nil)
+		subParts := make([]SignaturePart, n)
+		for i := range subParts {
+			if i >= lo && i < hi {
+				subParts[i] = parts[i]
+			} else {
+				subParts[i] = make([]byte, 32)
+				sub.SetMaskBit(i, Disabled)
+			}
+		}
+		return append(sub.AggregateSignature(aggR, subParts), sub.Mask()...)
+	}
+	sig1, sig2 := partial(0, 3), partial(3, n)
+
+	sig, err := cos.MergeSignatures(sig1, sig2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cos.Verify(rightMessage, sig) {
+		t.Errorf("merged signature rejected")
+	}
+	if cos.CountEnabled() != n {
+		t.Errorf("merged signature does not enable all cosigners")
+	}
+
+	if _, err := cos.MergeSignatures(sig1, partial(2, n)); err == nil {
+		t.Errorf("overlapping partial signatures merged")
+	}
+	if _, err := cos.MergeSignatures(sig1, sig2[:64]); err == nil {
+		t.Errorf("partial signature without mask merged")
+	}
+}
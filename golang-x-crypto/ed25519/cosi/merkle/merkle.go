@@ -0,0 +1,227 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package merkle implements collective signing of large payloads
+// by way of a Merkle tree.
+//
+// A payload such as a firmware image is split into fixed-size chunks,
+// each chunk becomes a leaf of a binary Merkle tree,
+// and only the 32-byte root of the tree is collectively signed.
+// Each chunk is then accompanied by an inclusion proof,
+// so that a verifier holding a single chunk, its proof,
+// and the one collective signature on the root
+// can check that chunk independently of all the others.
+//
+// Leaves and interior nodes are hashed with distinct one-byte prefixes,
+// as in RFC 6962, so that an interior node can never be
+// passed off as a leaf or vice versa.
+// When a tree level has an odd number of nodes,
+// the last node is promoted to the next level unchanged.
+package merkle
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+
+	"test-server/golang-x-crypto/ed25519/cosi"
+)
+
+// HashSize is the size, in bytes, of leaf, node and root hashes.
+const HashSize = sha256.Size
+
+// DefaultChunkSize is the chunk size used by Sign
+// when the caller passes a chunk size of zero.
+const DefaultChunkSize = 64 << 10
+
+const (
+	leafPrefix = 0x00
+	nodePrefix = 0x01
+)
+
+// Proof is an inclusion proof for a single chunk of a payload.
+// Path lists the sibling hashes needed to recompute the root,
+// ordered from the leaf level upwards.
+type Proof struct {
+	Index  int      // index of the chunk in the payload
+	Leaves int      // total number of chunks in the payload
+	Path   [][]byte // sibling hashes, leaf level first
+}
+
+// Tree is a binary Merkle tree over an ordered list of chunks.
+type Tree struct {
+	levels [][][]byte // levels[0] holds the leaf hashes, the last level the root
+}
+
+// LeafHash returns the hash of a chunk as a leaf of the tree.
+func LeafHash(chunk []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{leafPrefix})
+	h.Write(chunk)
+	return h.Sum(nil)
+}
+
+func nodeHash(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{nodePrefix})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// NewTree builds a Merkle tree whose leaves are the given chunks, in order.
+// At least one chunk is required.
+func NewTree(chunks [][]byte) (*Tree, error) {
+	if len(chunks) == 0 {
+		return nil, errors.New("merkle: no chunks")
+	}
+
+	level := make([][]byte, len(chunks))
+	for i, c := range chunks {
+		level[i] = LeafHash(c)
+	}
+	t := &Tree{levels: [][][]byte{level}}
+	for len(level) > 1 {
+		next := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i+1 < len(level); i += 2 {
+			next = append(next, nodeHash(level[i], level[i+1]))
+		}
+		if len(level)%2 == 1 {
+			next = append(next, level[len(level)-1]) // promote odd node
+		}
+		t.levels = append(t.levels, next)
+		level = next
+	}
+	return t, nil
+}
+
+// Root returns the root hash of the tree.
+func (t *Tree) Root() []byte {
+	return append([]byte{}, t.levels[len(t.levels)-1][0]...)
+}
+
+// Leaves returns the number of leaves (chunks) in the tree.
+func (t *Tree) Leaves() int {
+	return len(t.levels[0])
+}
+
+// Proof returns the inclusion proof for the chunk at the given index.
+func (t *Tree) Proof(index int) (*Proof, error) {
+	if index < 0 || index >= t.Leaves() {
+		return nil, errors.New("merkle: chunk index out of range")
+	}
+
+	p := &Proof{Index: index, Leaves: t.Leaves()}
+	idx := index
+	for _, level := range t.levels[:len(t.levels)-1] {
+		if sib := idx ^ 1; sib < len(level) {
+			p.Path = append(p.Path, append([]byte{}, level[sib]...))
+		}
+		idx >>= 1
+	}
+	return p, nil
+}
+
+// RootFromProof recomputes the root hash implied by a chunk and its proof.
+// It returns nil if the proof is malformed.
+func RootFromProof(chunk []byte, p *Proof) []byte {
+	if p == nil || p.Index < 0 || p.Index >= p.Leaves {
+		return nil
+	}
+
+	hash := LeafHash(chunk)
+	path := p.Path
+	idx, width := p.Index, p.Leaves
+	for width > 1 {
+		if sib := idx ^ 1; sib < width {
+			if len(path) == 0 || len(path[0]) != HashSize {
+				return nil
+			}
+			if idx&1 == 0 {
+				hash = nodeHash(hash, path[0])
+			} else {
+				hash = nodeHash(path[0], hash)
+			}
+			path = path[1:]
+		}
+		idx >>= 1
+		width = (width + 1) / 2
+	}
+	if len(path) != 0 {
+		return nil // trailing garbage in the proof
+	}
+	return hash
+}
+
+// VerifyProof reports whether the proof shows that chunk
+// is included in the tree with the given root.
+func VerifyProof(root, chunk []byte, p *Proof) bool {
+	got := RootFromProof(chunk, p)
+	return got != nil && subtle.ConstantTimeCompare(root, got) == 1
+}
+
+// Split divides a payload into consecutive chunks of at most chunkSize bytes.
+// The chunks alias the payload rather than copying it.
+// An empty payload yields a single empty chunk.
+func Split(payload []byte, chunkSize int) [][]byte {
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+	chunks := make([][]byte, 0, (len(payload)+chunkSize-1)/chunkSize+1)
+	for len(payload) > chunkSize {
+		chunks = append(chunks, payload[:chunkSize:chunkSize])
+		payload = payload[chunkSize:]
+	}
+	return append(chunks, payload)
+}
+
+// SignFunc produces a collective signature on a Merkle root,
+// typically by running a collective signing round
+// among the members of a cosigning group.
+type SignFunc func(root []byte) ([]byte, error)
+
+// SignedPayload is the result of signing a chunked payload:
+// the tree root, the collective signature on that root,
+// and one inclusion proof per chunk, in chunk order.
+type SignedPayload struct {
+	Root      []byte
+	Signature []byte
+	Proofs    []*Proof
+}
+
+// Sign splits payload into chunks of chunkSize bytes
+// (DefaultChunkSize if chunkSize is zero),
+// builds a Merkle tree over the chunks,
+// obtains a collective signature on the root via sign,
+// and returns the root and signature together with
+// an inclusion proof for every chunk.
+func Sign(payload []byte, chunkSize int, sign SignFunc) (*SignedPayload, error) {
+	t, err := NewTree(Split(payload, chunkSize))
+	if err != nil {
+		return nil, err
+	}
+
+	sp := &SignedPayload{Root: t.Root()}
+	if sp.Signature, err = sign(sp.Root); err != nil {
+		return nil, err
+	}
+	sp.Proofs = make([]*Proof, t.Leaves())
+	for i := range sp.Proofs {
+		if sp.Proofs[i], err = t.Proof(i); err != nil {
+			return nil, err
+		}
+	}
+	return sp, nil
+}
+
+// VerifyChunk checks a single chunk against a collective signature:
+// the proof must place the chunk in the tree with the given root,
+// and sig must be a valid collective signature on that root
+// according to the cosigners' current policy.
+func VerifyChunk(cosigners *cosi.Cosigners, root, sig, chunk []byte, p *Proof) bool {
+	if !VerifyProof(root, chunk, p) {
+		return false
+	}
+	return cosigners.Verify(root, sig)
+}
@@ -0,0 +1,122 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package merkle
+
+import (
+	"bytes"
+	"testing"
+
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/golang-x-crypto/ed25519/cosi"
+)
+
+type constReader struct{ val byte }
+
+func (cr constReader) Read(buf []byte) (int, error) {
+	for i := range buf {
+		buf[i] = cr.val
+	}
+	return len(buf), nil
+}
+
+// cosign runs a bare-bones collective signing round over message.
+func cosign(message []byte, pubKeys []ed25519.PublicKey,
+	priKeys []ed25519.PrivateKey) []byte {
+
+	cosigners := cosi.NewCosigners(pubKeys, nil)
+	commits := make([]cosi.Commitment, len(priKeys))
+	secrets := make([]*cosi.Secret, len(priKeys))
+	for i := range commits {
+		commits[i], secrets[i], _ = cosi.Commit(nil)
+	}
+	aggK := cosigners.AggregatePublicKey()
+	aggR := cosigners.AggregateCommit(commits)
+	parts := make([]cosi.SignaturePart, len(priKeys))
+	for i := range parts {
+		parts[i] = cosi.Cosign(priKeys[i], secrets[i], message, aggK, aggR)
+	}
+	return cosigners.AggregateSignature(aggR, parts)
+}
+
+func TestProofs(t *testing.T) {
+	for n := 1; n <= 9; n++ {
+		chunks := make([][]byte, n)
+		for i := range chunks {
+			chunks[i] = []byte{byte(i), byte(n)}
+		}
+		tree, err := NewTree(chunks)
+		if err != nil {
+			t.Fatal(err)
+		}
+		root := tree.Root()
+		for i := range chunks {
+			p, err := tree.Proof(i)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !VerifyProof(root, chunks[i], p) {
+				t.Errorf("%d leaves: proof for chunk %d rejected", n, i)
+			}
+			if VerifyProof(root, []byte("bogus"), p) {
+				t.Errorf("%d leaves: proof accepted for wrong chunk %d", n, i)
+			}
+			if n > 1 {
+				q := *p
+				q.Index = (i + 1) % n
+				if VerifyProof(root, chunks[i], &q) {
+					t.Errorf("%d leaves: proof accepted at wrong index %d", n, i)
+				}
+			}
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	payload := bytes.Repeat([]byte("x"), 10)
+	if got := len(Split(payload, 3)); got != 4 {
+		t.Errorf("Split into 3-byte chunks gave %d chunks, want 4", got)
+	}
+	if got := len(Split(payload, 5)); got != 2 {
+		t.Errorf("Split into 5-byte chunks gave %d chunks, want 2", got)
+	}
+	if got := len(Split(nil, 5)); got != 1 {
+		t.Errorf("Split of empty payload gave %d chunks, want 1", got)
+	}
+}
+
+func TestSignVerifyChunk(t *testing.T) {
+	var pubKeys []ed25519.PublicKey
+	var priKeys []ed25519.PrivateKey
+	for i := 0; i < 3; i++ {
+		pub, pri, _ := ed25519.GenerateKey(constReader{byte(i)})
+		pubKeys = append(pubKeys, pub)
+		priKeys = append(priKeys, pri)
+	}
+
+	payload := bytes.Repeat([]byte("firmware segment "), 100)
+	sp, err := Sign(payload, 128, func(root []byte) ([]byte, error) {
+		return cosign(root, pubKeys, priKeys), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cosigners := cosi.NewCosigners(pubKeys, nil)
+	chunks := Split(payload, 128)
+	if len(sp.Proofs) != len(chunks) {
+		t.Fatalf("got %d proofs for %d chunks", len(sp.Proofs), len(chunks))
+	}
+	for i, c := range chunks {
+		if !VerifyChunk(cosigners, sp.Root, sp.Signature, c, sp.Proofs[i]) {
+			t.Errorf("chunk %d rejected", i)
+		}
+	}
+
+	bad := append([]byte{}, chunks[2]...)
+	bad[0] ^= 1
+	if VerifyChunk(cosigners, sp.Root, sp.Signature, bad, sp.Proofs[2]) {
+		t.Errorf("corrupted chunk accepted")
+	}
+}
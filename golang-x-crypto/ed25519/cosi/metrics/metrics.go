@@ -0,0 +1,96 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package metrics defines the pluggable metrics interface
+// through which the collective signing protocol reports
+// counters and latency histograms for monitoring,
+// and provides an adapter exposing them to Prometheus.
+//
+// protocol.Leader and protocol.Service report to the Sink
+// assigned to their Metrics field;
+// verifiers can report failures by reason through Verify.
+package metrics
+
+import (
+	"errors"
+
+	"test-server/golang-x-crypto/ed25519/cosi"
+)
+
+// Names of the metrics reported by this repository's packages.
+const (
+	// RoundsStarted counts signing rounds started by a leader.
+	RoundsStarted = "cosi_rounds_started_total"
+
+	// RoundsCompleted counts signing rounds that produced a signature.
+	RoundsCompleted = "cosi_rounds_completed_total"
+
+	// RoundsFailed counts failed signing rounds, labeled by reason:
+	// "insufficient", "exhausted", "canceled" or "error".
+	RoundsFailed = "cosi_rounds_failed_total"
+
+	// RoundSeconds is a histogram of the duration of successful rounds.
+	RoundSeconds = "cosi_round_duration_seconds"
+
+	// PhaseSeconds is a histogram of the duration of protocol phases,
+	// labeled by phase: "commit" or "response".
+	PhaseSeconds = "cosi_phase_duration_seconds"
+
+	// Signers is a histogram of the number of cosigners
+	// that took part in each completed signature.
+	Signers = "cosi_signers"
+
+	// CosignersBlamed counts cosigners blamed for invalid signature parts.
+	CosignersBlamed = "cosi_cosigners_blamed_total"
+
+	// CosignerRequests counts requests handled by a cosigner,
+	// labeled by op ("commit" or "respond") and result ("ok" or "error").
+	CosignerRequests = "cosi_cosigner_requests_total"
+
+	// VerifyFailures counts rejected collective signatures,
+	// labeled by reason: "malformed", "policy" or "signature".
+	VerifyFailures = "cosi_verification_failures_total"
+)
+
+// Label is a name and value distinguishing the series of one metric.
+type Label struct {
+	Name, Value string
+}
+
+// Sink receives measurements.
+// Implementations must be safe for concurrent use.
+type Sink interface {
+	// Add increments the named counter by delta.
+	Add(name string, delta float64, labels ...Label)
+
+	// Observe records a sample in the named histogram.
+	Observe(name string, value float64, labels ...Label)
+}
+
+// Discard is a Sink that ignores all measurements.
+var Discard Sink = discard{}
+
+type discard struct{}
+
+func (discard) Add(string, float64, ...Label)     {}
+func (discard) Observe(string, float64, ...Label) {}
+
+// Verify checks a collective signature like cosi.Cosigners.Verify,
+// and reports a rejected signature to sink under VerifyFailures,
+// labeled with the reason for the rejection.
+func Verify(sink Sink, cosigners *cosi.Cosigners, message, sig []byte) bool {
+	err := cosigners.VerifyErr(message, sig)
+	if err == nil {
+		return true
+	}
+	reason := "signature"
+	switch {
+	case errors.Is(err, cosi.ErrMalformedSignature):
+		reason = "malformed"
+	case errors.Is(err, cosi.ErrPolicy):
+		reason = "policy"
+	}
+	sink.Add(VerifyFailures, 1, Label{"reason", reason})
+	return false
+}
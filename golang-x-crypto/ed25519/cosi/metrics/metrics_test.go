@@ -0,0 +1,79 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package metrics_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"test-server/golang-x-crypto/ed25519/cosi/cositest"
+	"test-server/golang-x-crypto/ed25519/cosi/metrics"
+)
+
+func TestPrometheusRounds(t *testing.T) {
+	nw, err := cositest.NewNetwork(4, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nw.Close()
+
+	prom := metrics.NewPrometheus()
+	nw.Leader.Metrics = prom
+	for _, svc := range nw.Services {
+		svc.Metrics = prom
+	}
+
+	ctx := context.Background()
+	res, err := nw.Sign(ctx, []byte("message"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	nw.SetOnline(2, false)
+	if _, err := nw.Sign(ctx, []byte("message")); err == nil {
+		t.Fatal("round without quorum succeeded")
+	}
+
+	cos := nw.Cosigners()
+	if !metrics.Verify(prom, cos, []byte("message"), res.Signature) {
+		t.Errorf("valid signature rejected")
+	}
+	metrics.Verify(prom, cos, []byte("other"), res.Signature)
+	metrics.Verify(prom, cos, []byte("message"), res.Signature[:10])
+
+	rec := httptest.NewRecorder()
+	prom.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	out := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE cosi_rounds_started_total counter\ncosi_rounds_started_total 2\n",
+		"cosi_rounds_completed_total 1\n",
+		`cosi_rounds_failed_total{reason="insufficient"} 1`,
+		`cosi_cosigner_requests_total{op="commit",result="ok"} 7`,
+		`cosi_cosigner_requests_total{op="respond",result="ok"} 4`,
+		`cosi_phase_duration_seconds_count{phase="commit"} 2`,
+		`cosi_signers_bucket{le="4"} 1`,
+		`cosi_signers_bucket{le="2"} 0`,
+		`cosi_verification_failures_total{reason="malformed"} 1`,
+		`cosi_verification_failures_total{reason="signature"} 1`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("metrics output lacks %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestPrometheusEscaping(t *testing.T) {
+	prom := metrics.NewPrometheus()
+	prom.SetBuckets("h", []float64{1})
+	prom.Observe("h", 2, metrics.Label{Name: "x", Value: "a\"b\\c\n"})
+	var sb strings.Builder
+	prom.WriteTo(&sb)
+	want := `h_bucket{x="a\"b\\c\n",le="1"} 0` + "\n" +
+		`h_bucket{x="a\"b\\c\n",le="+Inf"} 1` + "\n"
+	if !strings.Contains(sb.String(), want) {
+		t.Errorf("got:\n%s", sb.String())
+	}
+}
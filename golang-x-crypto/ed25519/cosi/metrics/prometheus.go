@@ -0,0 +1,210 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are the histogram buckets used by Prometheus
+// for metrics without buckets of their own, suited to latencies in seconds.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// SignerBuckets are the default buckets of the Signers histogram.
+var SignerBuckets = []float64{1, 2, 4, 8, 16, 32, 64, 128, 256, 512, 1024, 4096, 16384, 65536}
+
+// Prometheus is a Sink that accumulates measurements in memory
+// and serves them over HTTP in the Prometheus text exposition format,
+// so it can be mounted directly as a scrape endpoint.
+type Prometheus struct {
+	mu         sync.Mutex
+	buckets    map[string][]float64
+	counters   map[string]map[string]float64 // name -> labels -> value
+	histograms map[string]map[string]*histogram
+}
+
+type histogram struct {
+	counts []uint64 // per bucket, not cumulative
+	sum    float64
+	count  uint64
+}
+
+// NewPrometheus creates an empty Prometheus sink.
+func NewPrometheus() *Prometheus {
+	return &Prometheus{
+		buckets:    map[string][]float64{Signers: SignerBuckets},
+		counters:   make(map[string]map[string]float64),
+		histograms: make(map[string]map[string]*histogram),
+	}
+}
+
+// SetBuckets sets the upper bounds of the buckets of the named histogram,
+// which must be sorted in increasing order.
+// It must be called before the first observation of that histogram.
+func (p *Prometheus) SetBuckets(name string, buckets []float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.buckets[name] = append([]float64{}, buckets...)
+}
+
+// Add implements Sink.
+func (p *Prometheus) Add(name string, delta float64, labels ...Label) {
+	key := formatLabels(labels)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	series := p.counters[name]
+	if series == nil {
+		series = make(map[string]float64)
+		p.counters[name] = series
+	}
+	series[key] += delta
+}
+
+// Observe implements Sink.
+func (p *Prometheus) Observe(name string, value float64, labels ...Label) {
+	key := formatLabels(labels)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	buckets := p.buckets[name]
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	series := p.histograms[name]
+	if series == nil {
+		series = make(map[string]*histogram)
+		p.histograms[name] = series
+	}
+	h := series[key]
+	if h == nil {
+		h = &histogram{counts: make([]uint64, len(buckets))}
+		series[key] = h
+	}
+	for i, le := range buckets {
+		if value <= le {
+			h.counts[i]++
+			break
+		}
+	}
+	h.sum += value
+	h.count++
+}
+
+// WriteTo writes all metrics in the Prometheus text exposition format.
+func (p *Prometheus) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	bw := bufio.NewWriter(cw)
+
+	p.mu.Lock()
+	for _, name := range sortedKeys(p.counters) {
+		bw.WriteString("# TYPE " + name + " counter\n")
+		series := p.counters[name]
+		for _, key := range sortedKeys(series) {
+			bw.WriteString(name + braces(key) + " " + formatFloat(series[key]) + "\n")
+		}
+	}
+	for _, name := range sortedKeys(p.histograms) {
+		buckets := p.buckets[name]
+		if buckets == nil {
+			buckets = DefaultBuckets
+		}
+		bw.WriteString("# TYPE " + name + " histogram\n")
+		series := p.histograms[name]
+		for _, key := range sortedKeys(series) {
+			h := series[key]
+			var cum uint64
+			for i, le := range buckets {
+				cum += h.counts[i]
+				bw.WriteString(name + "_bucket" + braces(joinLabels(key, `le="`+formatFloat(le)+`"`)) +
+					" " + strconv.FormatUint(cum, 10) + "\n")
+			}
+			bw.WriteString(name + "_bucket" + braces(joinLabels(key, `le="+Inf"`)) +
+				" " + strconv.FormatUint(h.count, 10) + "\n")
+			bw.WriteString(name + "_sum" + braces(key) + " " + formatFloat(h.sum) + "\n")
+			bw.WriteString(name + "_count" + braces(key) + " " + strconv.FormatUint(h.count, 10) + "\n")
+		}
+	}
+	p.mu.Unlock()
+
+	err := bw.Flush()
+	return cw.n, err
+}
+
+// ServeHTTP serves the metrics as a Prometheus scrape endpoint.
+func (p *Prometheus) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	p.WriteTo(w)
+}
+
+// formatLabels renders labels in canonical (sorted) order,
+// without the surrounding braces.
+func formatLabels(labels []Label) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	sorted := append([]Label{}, labels...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	parts := make([]string, len(sorted))
+	for i, l := range sorted {
+		parts[i] = l.Name + `="` + escapeLabel(l.Value) + `"`
+	}
+	return strings.Join(parts, ",")
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(v string) string {
+	return labelEscaper.Replace(v)
+}
+
+func joinLabels(key, extra string) string {
+	if key == "" {
+		return extra
+	}
+	return key + "," + extra
+}
+
+func braces(key string) string {
+	if key == "" {
+		return ""
+	}
+	return "{" + key + "}"
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(b []byte) (int, error) {
+	n, err := cw.w.Write(b)
+	cw.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,168 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package mtls secures the links between a leader and its cosigners
+// with mutual TLS authenticated by the participants' Ed25519 identity keys.
+//
+// No certificate authority is involved.
+// Each participant presents a self-signed certificate
+// derived from its cosigning key (see Certificate),
+// and each side accepts the connection only if
+// the peer's certificate is correctly self-signed
+// and carries exactly the public key it expects:
+// the leader pins the key of the cosigner it is dialing,
+// and a cosigner admits only the keys of its configured leaders.
+// An attacker in the middle can then neither read
+// nor substitute the commits and signature parts exchanged.
+//
+// Config objects are built with ServerConfig and ClientConfig,
+// and wrapped as gRPC transport credentials
+// with ServerCredentials and ClientCredentials.
+// On the server side, PeerPublicKey recovers
+// the authenticated key of the caller,
+// for instance to fill protocol.CommitRequest.Leader.
+package mtls
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+
+	"test-server/golang-x-crypto/ed25519"
+)
+
+var (
+	// ErrNoCertificate is returned when the peer presented no certificate.
+	ErrNoCertificate = errors.New("mtls: peer presented no certificate")
+
+	// ErrUnexpectedKey is returned when the peer's certificate
+	// does not carry an expected public key.
+	ErrUnexpectedKey = errors.New("mtls: peer public key not expected")
+)
+
+// Certificate returns a self-signed TLS certificate
+// for the Ed25519 key privateKey.
+// The certificate's subject is the hex encoding of the public key.
+func Certificate(privateKey ed25519.PrivateKey) (tls.Certificate, error) {
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return tls.Certificate{}, errors.New("mtls: bad private key length")
+	}
+	der, err := ed25519.CreateSelfSignedCertificate(privateKey, nil)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return ed25519.TLSCertificate(privateKey, der)
+}
+
+// ServerConfig returns the TLS configuration of a cosigner
+// identified by privateKey,
+// admitting only clients that present one of the keys in peers.
+func ServerConfig(privateKey ed25519.PrivateKey, peers []ed25519.PublicKey) (*tls.Config, error) {
+	cert, err := Certificate(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		MinVersion:       tls.VersionTLS13,
+		Certificates:     []tls.Certificate{cert},
+		ClientAuth:       tls.RequireAnyClientCert,
+		VerifyConnection: verifier(peers),
+	}, nil
+}
+
+// ClientConfig returns the TLS configuration of a leader
+// identified by privateKey
+// connecting to the cosigner whose public key is server.
+func ClientConfig(privateKey ed25519.PrivateKey, server ed25519.PublicKey) (*tls.Config, error) {
+	cert, err := Certificate(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		MinVersion:   tls.VersionTLS13,
+		Certificates: []tls.Certificate{cert},
+		// The certificate chain is not checked against any roots:
+		// VerifyConnection pins the peer's key instead.
+		InsecureSkipVerify: true,
+		VerifyConnection:   verifier([]ed25519.PublicKey{server}),
+	}, nil
+}
+
+// verifier returns a VerifyConnection callback
+// accepting peers whose certificate carries one of the keys in allowed.
+func verifier(allowed []ed25519.PublicKey) func(tls.ConnectionState) error {
+	allowed = append([]ed25519.PublicKey{}, allowed...)
+	return func(cs tls.ConnectionState) error {
+		pub, err := publicKey(cs)
+		if err != nil {
+			return err
+		}
+		for _, k := range allowed {
+			if bytes.Equal(k, pub) {
+				return nil
+			}
+		}
+		return ErrUnexpectedKey
+	}
+}
+
+// publicKey returns the Ed25519 key of the peer's self-signed certificate.
+func publicKey(cs tls.ConnectionState) (ed25519.PublicKey, error) {
+	if len(cs.PeerCertificates) == 0 {
+		return nil, ErrNoCertificate
+	}
+	cert := cs.PeerCertificates[0]
+	pub, err := ed25519.CertificatePublicKey(cert)
+	if err != nil {
+		return nil, errors.New("mtls: peer certificate key is not Ed25519")
+	}
+	if err := cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature); err != nil {
+		return nil, errors.New("mtls: peer certificate not self-signed: " + err.Error())
+	}
+	return pub, nil
+}
+
+// PeerPublicKey returns the authenticated public key
+// of the TLS peer of a connection.
+func PeerPublicKey(cs tls.ConnectionState) (ed25519.PublicKey, error) {
+	return publicKey(cs)
+}
+
+// ServerCredentials returns gRPC transport credentials for a cosigner
+// (see ServerConfig).
+func ServerCredentials(privateKey ed25519.PrivateKey, peers []ed25519.PublicKey) (credentials.TransportCredentials, error) {
+	config, err := ServerConfig(privateKey, peers)
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(config), nil
+}
+
+// ClientCredentials returns gRPC transport credentials for a leader
+// dialing the cosigner whose public key is server (see ClientConfig).
+func ClientCredentials(privateKey ed25519.PrivateKey, server ed25519.PublicKey) (credentials.TransportCredentials, error) {
+	config, err := ClientConfig(privateKey, server)
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(config), nil
+}
+
+// PeerFromContext returns the authenticated public key
+// of the caller of a gRPC request served with ServerCredentials.
+func PeerFromContext(ctx context.Context) (ed25519.PublicKey, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, errors.New("mtls: no peer in context")
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return nil, errors.New("mtls: peer not authenticated by TLS")
+	}
+	return publicKey(info.State)
+}
@@ -0,0 +1,112 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mtls
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"testing"
+
+	"test-server/golang-x-crypto/ed25519"
+)
+
+func genKey(t *testing.T) (ed25519.PublicKey, ed25519.PrivateKey) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pub, priv
+}
+
+// handshake runs a TLS handshake between the two configs over loopback
+// and returns the client's error and the server's view of the client key.
+func handshake(t *testing.T, client, server *tls.Config) (error, ed25519.PublicKey, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	type result struct {
+		pub ed25519.PublicKey
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		c, err := l.Accept()
+		if err != nil {
+			done <- result{nil, err}
+			return
+		}
+		defer c.Close()
+		conn := tls.Server(c, server)
+		if err := conn.Handshake(); err != nil {
+			done <- result{nil, err}
+			return
+		}
+		pub, err := PeerPublicKey(conn.ConnectionState())
+		done <- result{pub, err}
+		conn.Write([]byte{0})
+	}()
+	conn, err := tls.Dial("tcp", l.Addr().String(), client)
+	if err == nil {
+		// a TLS 1.3 client learns that the server refused it on its first read
+		_, err = conn.Read(make([]byte, 1))
+		conn.Close()
+	}
+	r := <-done
+	return err, r.pub, r.err
+}
+
+func TestMutualAuth(t *testing.T) {
+	leaderPub, leaderPriv := genKey(t)
+	cosignerPub, cosignerPriv := genKey(t)
+	_, otherPriv := genKey(t)
+
+	server, err := ServerConfig(cosignerPriv, []ed25519.PublicKey{leaderPub})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := ClientConfig(leaderPriv, cosignerPub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cerr, pub, serr := handshake(t, client, server)
+	if cerr != nil || serr != nil {
+		t.Fatalf("handshake failed: client %v, server %v", cerr, serr)
+	}
+	if !bytes.Equal(pub, leaderPub) {
+		t.Error("server saw the wrong client key")
+	}
+
+	// an impostor cosigner is refused by the leader
+	impostor, _ := ServerConfig(otherPriv, []ed25519.PublicKey{leaderPub})
+	if cerr, _, _ := handshake(t, client, impostor); !errors.Is(cerr, ErrUnexpectedKey) {
+		t.Errorf("impostor cosigner: got %v, want ErrUnexpectedKey", cerr)
+	}
+
+	// an unknown leader is refused by the cosigner
+	stranger, _ := ClientConfig(otherPriv, cosignerPub)
+	if _, _, serr := handshake(t, stranger, server); !errors.Is(serr, ErrUnexpectedKey) {
+		t.Errorf("unknown leader: got %v, want ErrUnexpectedKey", serr)
+	}
+}
+
+func TestCertificate(t *testing.T) {
+	pub, priv := genKey(t)
+	cert, err := Certificate(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := PeerPublicKey(tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert.Leaf}})
+	if err != nil || !bytes.Equal(got, pub) {
+		t.Errorf("PeerPublicKey = %x, %v; want %x", got, err, pub)
+	}
+	if _, err := PeerPublicKey(tls.ConnectionState{}); err != ErrNoCertificate {
+		t.Errorf("no certificate: got %v", err)
+	}
+}
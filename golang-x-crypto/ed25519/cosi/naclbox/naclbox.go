@@ -0,0 +1,201 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package naclbox encrypts protocol messages to a single recipient
+// with NaCl box (X25519, XSalsa20 and Poly1305),
+// keyed by the participants' Ed25519 identities converted to X25519.
+//
+// It is meant for deployments that relay commits and signature parts
+// through untrusted brokers, such as MQTT servers or message queues,
+// instead of direct connections secured by package mtls or noiseconn:
+// a broker can drop or delay sealed messages but can neither read nor alter them.
+//
+// Seal authenticates the sender to the recipient;
+// SealAnonymous hides the sender's identity, and authenticates nobody.
+// SealCommitment and SealPart wrap a commit or signature part
+// together with a label and the session ID,
+// so that a sealed message replayed into another session,
+// or passed off as the other kind, is rejected when opened.
+package naclbox
+
+import (
+	cryptorand "crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/nacl/box"
+
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/golang-x-crypto/ed25519/cosi"
+)
+
+// NonceSize is the size of the random nonce prefixed to a sealed message.
+const NonceSize = 24
+
+// Overhead is the number of bytes Seal adds to a message.
+const Overhead = NonceSize + box.Overhead
+
+// AnonymousOverhead is the number of bytes SealAnonymous adds to a message.
+const AnonymousOverhead = box.AnonymousOverhead
+
+const (
+	commitLabel = "cosi-box-commit-v1"
+	partLabel   = "cosi-box-part-v1"
+)
+
+var (
+	// ErrOpen is returned when a sealed message fails to decrypt,
+	// because it was altered or sealed for another recipient or by another sender.
+	ErrOpen = errors.New("naclbox: message authentication failed")
+
+	// ErrSession is returned when a sealed commit or signature part
+	// belongs to another session or is of the other kind.
+	ErrSession = errors.New("naclbox: sealed message not for this session")
+
+	errBadKey = errors.New("naclbox: bad public key")
+)
+
+func keys(publicKey ed25519.PublicKey, privateKey ed25519.PrivateKey) (pub, priv *[32]byte, err error) {
+	u, err := ed25519.PublicKeyToCurve25519(publicKey)
+	if err != nil {
+		return nil, nil, errBadKey
+	}
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return nil, nil, errors.New("naclbox: bad private key length")
+	}
+	pub, priv = new([32]byte), new([32]byte)
+	copy(pub[:], u)
+	copy(priv[:], ed25519.PrivateKeyToCurve25519(privateKey))
+	return pub, priv, nil
+}
+
+// Seal encrypts and authenticates message from sender to recipient,
+// and returns the nonce followed by the box.
+// The nonce is drawn from rand, or crypto/rand if rand is nil.
+func Seal(message []byte, recipient ed25519.PublicKey,
+	sender ed25519.PrivateKey, rand io.Reader) ([]byte, error) {
+
+	if rand == nil {
+		rand = cryptorand.Reader
+	}
+	pub, priv, err := keys(recipient, sender)
+	if err != nil {
+		return nil, err
+	}
+	var nonce [NonceSize]byte
+	if _, err := io.ReadFull(rand, nonce[:]); err != nil {
+		return nil, err
+	}
+	return box.Seal(nonce[:], message, &nonce, pub, priv), nil
+}
+
+// Open decrypts a message sealed by sender for recipient.
+func Open(sealed []byte, sender ed25519.PublicKey,
+	recipient ed25519.PrivateKey) ([]byte, error) {
+
+	if len(sealed) < Overhead {
+		return nil, ErrOpen
+	}
+	pub, priv, err := keys(sender, recipient)
+	if err != nil {
+		return nil, err
+	}
+	var nonce [NonceSize]byte
+	copy(nonce[:], sealed)
+	message, ok := box.Open(nil, sealed[NonceSize:], &nonce, pub, priv)
+	if !ok {
+		return nil, ErrOpen
+	}
+	return message, nil
+}
+
+// SealAnonymous encrypts message for recipient under an ephemeral key.
+// The ephemeral key is drawn from rand, or crypto/rand if rand is nil.
+func SealAnonymous(message []byte, recipient ed25519.PublicKey, rand io.Reader) ([]byte, error) {
+	if rand == nil {
+		rand = cryptorand.Reader
+	}
+	u, err := ed25519.PublicKeyToCurve25519(recipient)
+	if err != nil {
+		return nil, errBadKey
+	}
+	var pub [32]byte
+	copy(pub[:], u)
+	return box.SealAnonymous(nil, message, &pub, rand)
+}
+
+// OpenAnonymous decrypts a message sealed with SealAnonymous for recipient.
+func OpenAnonymous(sealed []byte, recipient ed25519.PrivateKey) ([]byte, error) {
+	if len(recipient) != ed25519.PrivateKeySize {
+		return nil, errors.New("naclbox: bad private key length")
+	}
+	pub, priv, err := keys(ed25519.PublicKey(recipient[32:]), recipient)
+	if err != nil {
+		return nil, err
+	}
+	message, ok := box.OpenAnonymous(nil, sealed, pub, priv)
+	if !ok {
+		return nil, ErrOpen
+	}
+	return message, nil
+}
+
+// frame prefixes data with a label and a length-prefixed session ID.
+func frame(label string, sessionID, data []byte) []byte {
+	b := append([]byte(label), 0)
+	b = binary.AppendUvarint(b, uint64(len(sessionID)))
+	b = append(b, sessionID...)
+	return append(b, data...)
+}
+
+// unframe checks the label and session ID of a framed message
+// and returns its data.
+func unframe(label string, sessionID, b []byte) ([]byte, error) {
+	want := frame(label, sessionID, nil)
+	if len(b) < len(want) || string(b[:len(want)]) != string(want) {
+		return nil, ErrSession
+	}
+	return b[len(want):], nil
+}
+
+// SealCommitment seals a cosigner's commit for a session
+// from sender to recipient (see Seal).
+func SealCommitment(sessionID []byte, commit cosi.Commitment,
+	recipient ed25519.PublicKey, sender ed25519.PrivateKey, rand io.Reader) ([]byte, error) {
+
+	return Seal(frame(commitLabel, sessionID, commit), recipient, sender, rand)
+}
+
+// OpenCommitment opens a commit for a session sealed with SealCommitment.
+func OpenCommitment(sealed, sessionID []byte, sender ed25519.PublicKey,
+	recipient ed25519.PrivateKey) (cosi.Commitment, error) {
+
+	b, err := Open(sealed, sender, recipient)
+	if err != nil {
+		return nil, err
+	}
+	commit, err := unframe(commitLabel, sessionID, b)
+	return cosi.Commitment(commit), err
+}
+
+// SealPart seals a cosigner's signature part for a session
+// from sender to recipient (see Seal).
+func SealPart(sessionID []byte, part cosi.SignaturePart,
+	recipient ed25519.PublicKey, sender ed25519.PrivateKey, rand io.Reader) ([]byte, error) {
+
+	return Seal(frame(partLabel, sessionID, part), recipient, sender, rand)
+}
+
+// OpenPart opens a signature part for a session sealed with SealPart.
+func OpenPart(sealed, sessionID []byte, sender ed25519.PublicKey,
+	recipient ed25519.PrivateKey) (cosi.SignaturePart, error) {
+
+	b, err := Open(sealed, sender, recipient)
+	if err != nil {
+		return nil, err
+	}
+	part, err := unframe(partLabel, sessionID, b)
+	return cosi.SignaturePart(part), err
+}
@@ -0,0 +1,99 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package naclbox
+
+import (
+	"bytes"
+	"testing"
+
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/golang-x-crypto/ed25519/cosi"
+)
+
+func genKey(t *testing.T) (ed25519.PublicKey, ed25519.PrivateKey) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pub, priv
+}
+
+func TestSealOpen(t *testing.T) {
+	leaderPub, leaderPriv := genKey(t)
+	cosignerPub, cosignerPriv := genKey(t)
+	_, otherPriv := genKey(t)
+	message := []byte("payload")
+
+	sealed, err := Seal(message, leaderPub, cosignerPriv, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sealed) != len(message)+Overhead {
+		t.Errorf("sealed length %d, want %d", len(sealed), len(message)+Overhead)
+	}
+	got, err := Open(sealed, cosignerPub, leaderPriv)
+	if err != nil || !bytes.Equal(got, message) {
+		t.Fatalf("Open = %q, %v", got, err)
+	}
+	if _, err := Open(sealed, cosignerPub, otherPriv); err != ErrOpen {
+		t.Errorf("wrong recipient: got %v, want ErrOpen", err)
+	}
+	if _, err := Open(sealed, leaderPub, leaderPriv); err != ErrOpen {
+		t.Errorf("wrong sender: got %v, want ErrOpen", err)
+	}
+	sealed[len(sealed)-1] ^= 1
+	if _, err := Open(sealed, cosignerPub, leaderPriv); err != ErrOpen {
+		t.Errorf("altered box: got %v, want ErrOpen", err)
+	}
+}
+
+func TestSealAnonymous(t *testing.T) {
+	pub, priv := genKey(t)
+	_, otherPriv := genKey(t)
+	sealed, err := SealAnonymous([]byte("payload"), pub, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := OpenAnonymous(sealed, priv); err != nil || string(got) != "payload" {
+		t.Errorf("OpenAnonymous = %q, %v", got, err)
+	}
+	if _, err := OpenAnonymous(sealed, otherPriv); err != ErrOpen {
+		t.Errorf("wrong recipient: got %v, want ErrOpen", err)
+	}
+}
+
+func TestSealProtocolMessages(t *testing.T) {
+	leaderPub, leaderPriv := genKey(t)
+	cosignerPub, cosignerPriv := genKey(t)
+	session := []byte("session-1")
+
+	commit, _, err := cosi.Commit(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := SealCommitment(session, commit, leaderPub, cosignerPriv, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := OpenCommitment(sealed, session, cosignerPub, leaderPriv)
+	if err != nil || !bytes.Equal(got, commit) {
+		t.Fatalf("OpenCommitment = %x, %v", got, err)
+	}
+	if _, err := OpenCommitment(sealed, []byte("session-2"), cosignerPub, leaderPriv); err != ErrSession {
+		t.Errorf("replayed into another session: got %v, want ErrSession", err)
+	}
+	if _, err := OpenPart(sealed, session, cosignerPub, leaderPriv); err != ErrSession {
+		t.Errorf("commit opened as part: got %v, want ErrSession", err)
+	}
+
+	part := cosi.SignaturePart(bytes.Repeat([]byte{7}, 32))
+	sealed, err = SealPart(session, part, leaderPub, cosignerPriv, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := OpenPart(sealed, session, cosignerPub, leaderPriv); err != nil || !bytes.Equal(got, part) {
+		t.Errorf("OpenPart = %x, %v", got, err)
+	}
+}
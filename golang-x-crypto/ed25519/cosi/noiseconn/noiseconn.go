@@ -0,0 +1,396 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package noiseconn secures the links between a leader and its cosigners
+// with the Noise protocol framework, as an alternative to package mtls
+// for deployments that want no X.509 at all.
+//
+// Each participant's static Noise key is its Ed25519 identity key
+// converted to X25519, so no separate key material is needed.
+// The leader, which always knows the cosigner it dials,
+// uses the IK handshake by default,
+// which completes in a single round trip
+// and encrypts the leader's identity to the cosigner's key;
+// the XX handshake, which exchanges both identities encrypted
+// in one and a half round trips, can be chosen instead.
+// A cosigner accepts either, and admits only the configured leader keys.
+//
+// After the handshake, traffic is exchanged
+// in frames prefixed with a two-byte big-endian length
+// and encrypted with ChaCha20-Poly1305.
+//
+// Client and Server secure an established net.Conn;
+// ClientCredentials and ServerCredentials do the same for gRPC,
+// and PeerFromContext recovers a gRPC caller's authenticated key.
+package noiseconn
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/flynn/noise"
+	"golang.org/x/crypto/curve25519"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+
+	"test-server/golang-x-crypto/ed25519"
+)
+
+// Pattern selects the Noise handshake a client runs.
+type Pattern byte
+
+const (
+	// IK sends the client's identity in the first message,
+	// encrypted to the server's known static key.
+	IK Pattern = iota
+	// XX exchanges both static keys encrypted under ephemeral keys.
+	XX
+)
+
+const prologue = "cosi-noise-v1"
+
+// maxFrame is the largest Noise message, and so the largest frame payload.
+const maxFrame = noise.MaxMsgLen
+
+// overhead is the size of the authentication tag on every transport frame.
+const overhead = 16
+
+var suite = noise.NewCipherSuite(noise.DH25519, noise.CipherChaChaPoly, noise.HashSHA512)
+
+var (
+	// ErrUnexpectedKey is returned when the peer's static key
+	// does not belong to an expected identity.
+	ErrUnexpectedKey = errors.New("noiseconn: peer public key not expected")
+
+	errBadPattern = errors.New("noiseconn: unknown handshake pattern")
+)
+
+// Config configures one side of a Noise channel.
+type Config struct {
+	// PrivateKey is the local participant's identity key.
+	PrivateKey ed25519.PrivateKey
+
+	// Peers lists the identities a server admits.
+	// For a client, it holds the single identity of the server it dials.
+	Peers []ed25519.PublicKey
+
+	// Pattern is the handshake a client runs; servers accept any.
+	Pattern Pattern
+
+	// Prologue, if set, is bound into the handshake
+	// and must be the same on both sides,
+	// for instance a group or deployment identifier.
+	Prologue []byte
+
+	// Rand is the source of ephemeral keys.
+	// If nil, crypto/rand is used.
+	Rand io.Reader
+}
+
+func (c *Config) static() (noise.DHKey, error) {
+	if len(c.PrivateKey) != ed25519.PrivateKeySize {
+		return noise.DHKey{}, errors.New("noiseconn: bad private key length")
+	}
+	priv := ed25519.PrivateKeyToCurve25519(c.PrivateKey)
+	pub, err := curve25519.X25519(priv, curve25519.Basepoint)
+	if err != nil {
+		return noise.DHKey{}, err
+	}
+	return noise.DHKey{Private: priv, Public: pub}, nil
+}
+
+// match returns the identity in c.Peers whose X25519 key is static.
+func (c *Config) match(static []byte) (ed25519.PublicKey, error) {
+	for _, k := range c.Peers {
+		if x, err := ed25519.PublicKeyToCurve25519(k); err == nil && bytes.Equal(x, static) {
+			return append(ed25519.PublicKey{}, k...), nil
+		}
+	}
+	return nil, ErrUnexpectedKey
+}
+
+func (c *Config) handshakeState(p Pattern, initiator bool, peerStatic []byte) (*noise.HandshakeState, error) {
+	static, err := c.static()
+	if err != nil {
+		return nil, err
+	}
+	var hp noise.HandshakePattern
+	switch p {
+	case IK:
+		hp = noise.HandshakeIK
+	case XX:
+		hp = noise.HandshakeXX
+	default:
+		return nil, errBadPattern
+	}
+	return noise.NewHandshakeState(noise.Config{
+		CipherSuite:   suite,
+		Random:        c.Rand,
+		Pattern:       hp,
+		Initiator:     initiator,
+		Prologue:      append(append([]byte(prologue), byte(p)), c.Prologue...),
+		StaticKeypair: static,
+		PeerStatic:    peerStatic,
+	})
+}
+
+// Conn is a net.Conn secured by a completed Noise handshake.
+type Conn struct {
+	net.Conn
+	peer ed25519.PublicKey
+
+	rmu  sync.Mutex
+	recv *noise.CipherState
+	buf  []byte // decrypted bytes not yet read
+
+	wmu  sync.Mutex
+	send *noise.CipherState
+}
+
+// PeerPublicKey returns the authenticated identity of the remote participant.
+func (c *Conn) PeerPublicKey() ed25519.PublicKey { return c.peer }
+
+// Read implements net.Conn.
+func (c *Conn) Read(p []byte) (int, error) {
+	c.rmu.Lock()
+	defer c.rmu.Unlock()
+	for len(c.buf) == 0 {
+		frame, err := readFrame(c.Conn)
+		if err != nil {
+			return 0, err
+		}
+		c.buf, err = c.recv.Decrypt(frame[:0], nil, frame)
+		if err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, c.buf)
+	c.buf = c.buf[n:]
+	return n, nil
+}
+
+// Write implements net.Conn.
+func (c *Conn) Write(p []byte) (int, error) {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	n := 0
+	for len(p) > 0 {
+		chunk := p
+		if len(chunk) > maxFrame-overhead {
+			chunk = chunk[:maxFrame-overhead]
+		}
+		frame, err := c.send.Encrypt(nil, nil, chunk)
+		if err != nil {
+			return n, err
+		}
+		if err := writeFrame(c.Conn, frame); err != nil {
+			return n, err
+		}
+		n += len(chunk)
+		p = p[len(chunk):]
+	}
+	return n, nil
+}
+
+func readFrame(r io.Reader) ([]byte, error) {
+	var hdr [2]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return nil, err
+	}
+	frame := make([]byte, binary.BigEndian.Uint16(hdr[:]))
+	if _, err := io.ReadFull(r, frame); err != nil {
+		return nil, err
+	}
+	return frame, nil
+}
+
+func writeFrame(w io.Writer, frame []byte) error {
+	b := make([]byte, 2+len(frame))
+	binary.BigEndian.PutUint16(b, uint16(len(frame)))
+	copy(b[2:], frame)
+	_, err := w.Write(b)
+	return err
+}
+
+// Client runs the client side of a handshake over conn,
+// authenticating the server as config.Peers[0].
+// It does not close conn on failure.
+func Client(conn net.Conn, config *Config) (*Conn, error) {
+	if len(config.Peers) != 1 {
+		return nil, errors.New("noiseconn: client needs exactly one server key")
+	}
+	server, err := ed25519.PublicKeyToCurve25519(config.Peers[0])
+	if err != nil {
+		return nil, errors.New("noiseconn: bad server public key")
+	}
+	var peerStatic []byte
+	if config.Pattern == IK {
+		peerStatic = server
+	}
+	hs, err := config.handshakeState(config.Pattern, true, peerStatic)
+	if err != nil {
+		return nil, err
+	}
+
+	// the pattern travels in the clear ahead of the first message,
+	// and is bound into the handshake through the prologue
+	msg, _, _, err := hs.WriteMessage([]byte{byte(config.Pattern)}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFrame(conn, msg); err != nil {
+		return nil, err
+	}
+	frame, err := readFrame(conn)
+	if err != nil {
+		return nil, err
+	}
+	_, send, recv, err := hs.ReadMessage(nil, frame)
+	if err != nil {
+		return nil, err
+	}
+	if config.Pattern == XX {
+		if !bytes.Equal(hs.PeerStatic(), server) {
+			return nil, ErrUnexpectedKey
+		}
+		msg, send, recv, err = hs.WriteMessage(nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		if err := writeFrame(conn, msg); err != nil {
+			return nil, err
+		}
+	}
+	return &Conn{Conn: conn, peer: config.Peers[0], recv: recv, send: send}, nil
+}
+
+// Server runs the server side of a handshake over conn,
+// admitting only clients whose identity is among config.Peers.
+// It does not close conn on failure.
+func Server(conn net.Conn, config *Config) (*Conn, error) {
+	frame, err := readFrame(conn)
+	if err != nil {
+		return nil, err
+	}
+	if len(frame) == 0 {
+		return nil, errBadPattern
+	}
+	p := Pattern(frame[0])
+	hs, err := config.handshakeState(p, false, nil)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, _, err := hs.ReadMessage(nil, frame[1:]); err != nil {
+		return nil, err
+	}
+	var peerKey ed25519.PublicKey
+	if p == IK {
+		// refuse unknown clients before answering
+		if peerKey, err = config.match(hs.PeerStatic()); err != nil {
+			return nil, err
+		}
+	}
+	msg, recv, send, err := hs.WriteMessage(nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFrame(conn, msg); err != nil {
+		return nil, err
+	}
+	if p == XX {
+		if frame, err = readFrame(conn); err != nil {
+			return nil, err
+		}
+		if _, recv, send, err = hs.ReadMessage(nil, frame); err != nil {
+			return nil, err
+		}
+		if peerKey, err = config.match(hs.PeerStatic()); err != nil {
+			return nil, err
+		}
+	}
+	return &Conn{Conn: conn, peer: peerKey, recv: recv, send: send}, nil
+}
+
+// AuthInfo is the gRPC authentication information of a Noise channel.
+type AuthInfo struct {
+	credentials.CommonAuthInfo
+
+	// PeerPublicKey is the authenticated identity of the remote participant.
+	PeerPublicKey ed25519.PublicKey
+}
+
+// AuthType implements credentials.AuthInfo.
+func (AuthInfo) AuthType() string { return "noise" }
+
+type transportCredentials struct {
+	config *Config
+}
+
+// ClientCredentials returns gRPC transport credentials for a leader
+// dialing the cosigner whose identity is config.Peers[0].
+func ClientCredentials(config *Config) credentials.TransportCredentials {
+	return &transportCredentials{config}
+}
+
+// ServerCredentials returns gRPC transport credentials for a cosigner
+// admitting the leaders whose identities are in config.Peers.
+func ServerCredentials(config *Config) credentials.TransportCredentials {
+	return &transportCredentials{config}
+}
+
+func (t *transportCredentials) ClientHandshake(ctx context.Context, _ string, conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return t.handshake(ctx, conn, Client)
+}
+
+func (t *transportCredentials) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return t.handshake(context.Background(), conn, Server)
+}
+
+func (t *transportCredentials) handshake(ctx context.Context, conn net.Conn,
+	run func(net.Conn, *Config) (*Conn, error)) (net.Conn, credentials.AuthInfo, error) {
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+		defer conn.SetDeadline(time.Time{})
+	}
+	c, err := run(conn, t.config)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c, AuthInfo{
+		CommonAuthInfo: credentials.CommonAuthInfo{SecurityLevel: credentials.PrivacyAndIntegrity},
+		PeerPublicKey:  c.peer,
+	}, nil
+}
+
+func (t *transportCredentials) Info() credentials.ProtocolInfo {
+	return credentials.ProtocolInfo{SecurityProtocol: "noise"}
+}
+
+func (t *transportCredentials) Clone() credentials.TransportCredentials {
+	c := *t.config
+	return &transportCredentials{&c}
+}
+
+func (t *transportCredentials) OverrideServerName(string) error { return nil }
+
+// PeerFromContext returns the authenticated identity
+// of the caller of a gRPC request served with ServerCredentials.
+func PeerFromContext(ctx context.Context) (ed25519.PublicKey, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, errors.New("noiseconn: no peer in context")
+	}
+	info, ok := p.AuthInfo.(AuthInfo)
+	if !ok {
+		return nil, errors.New("noiseconn: peer not authenticated by Noise")
+	}
+	return info.PeerPublicKey, nil
+}
@@ -0,0 +1,100 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package noiseconn
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"test-server/golang-x-crypto/ed25519"
+)
+
+func genKey(t *testing.T) (ed25519.PublicKey, ed25519.PrivateKey) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pub, priv
+}
+
+// pair runs a handshake between client and server configs over a pipe.
+func pair(client, server *Config) (*Conn, *Conn, error, error) {
+	c, s := net.Pipe()
+	type result struct {
+		conn *Conn
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		conn, err := Server(s, server)
+		if err != nil {
+			s.Close()
+		}
+		done <- result{conn, err}
+	}()
+	cc, cerr := Client(c, client)
+	if cerr != nil {
+		c.Close()
+	}
+	r := <-done
+	return cc, r.conn, cerr, r.err
+}
+
+func TestHandshake(t *testing.T) {
+	leaderPub, leaderPriv := genKey(t)
+	cosignerPub, cosignerPriv := genKey(t)
+	server := &Config{PrivateKey: cosignerPriv, Peers: []ed25519.PublicKey{leaderPub}}
+
+	for _, p := range []Pattern{IK, XX} {
+		client := &Config{PrivateKey: leaderPriv, Peers: []ed25519.PublicKey{cosignerPub}, Pattern: p}
+		cc, sc, cerr, serr := pair(client, server)
+		if cerr != nil || serr != nil {
+			t.Fatalf("pattern %d: client %v, server %v", p, cerr, serr)
+		}
+		if !bytes.Equal(cc.PeerPublicKey(), cosignerPub) || !bytes.Equal(sc.PeerPublicKey(), leaderPub) {
+			t.Errorf("pattern %d: wrong peer keys", p)
+		}
+
+		// more than one frame each way
+		msg := bytes.Repeat([]byte("commit"), 20000)
+		go func() {
+			cc.Write(msg)
+			cc.Close()
+		}()
+		got, err := io.ReadAll(sc)
+		if err != nil && err != io.EOF {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, msg) {
+			t.Errorf("pattern %d: got %d bytes, want %d", p, len(got), len(msg))
+		}
+		sc.Close()
+	}
+}
+
+func TestHandshakeRejects(t *testing.T) {
+	leaderPub, leaderPriv := genKey(t)
+	cosignerPub, cosignerPriv := genKey(t)
+	_, otherPriv := genKey(t)
+	server := &Config{PrivateKey: cosignerPriv, Peers: []ed25519.PublicKey{leaderPub}}
+
+	for _, p := range []Pattern{IK, XX} {
+		// an unknown leader is refused by the cosigner
+		stranger := &Config{PrivateKey: otherPriv, Peers: []ed25519.PublicKey{cosignerPub}, Pattern: p}
+		if _, _, _, serr := pair(stranger, server); !errors.Is(serr, ErrUnexpectedKey) {
+			t.Errorf("pattern %d, unknown leader: got %v, want ErrUnexpectedKey", p, serr)
+		}
+
+		// an impostor cosigner is refused by the leader
+		client := &Config{PrivateKey: leaderPriv, Peers: []ed25519.PublicKey{cosignerPub}, Pattern: p}
+		impostor := &Config{PrivateKey: otherPriv, Peers: []ed25519.PublicKey{leaderPub}}
+		if _, _, cerr, _ := pair(client, impostor); cerr == nil {
+			t.Errorf("pattern %d: impostor cosigner accepted", p)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cosi
+
+import (
+	"errors"
+
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/golang-x-crypto/ed25519/internal/edwards25519"
+)
+
+// Partial aggregation.
+//
+// Commits and signature parts are combined by plain addition,
+// so disjoint subsets of cosigners can be aggregated separately
+// and the partial aggregates combined later, in any order.
+// This lets cosigners aggregate among themselves
+// (over a tree, or by gossip) instead of sending everything to the leader.
+// The subsets combined must be disjoint:
+// a cosigner counted twice breaks the resulting signature.
+
+// SumCommits returns the sum of the given commits,
+// which may be individual commits or partial aggregates.
+// The sum of no commits is the identity element.
+func SumCommits(commits ...Commitment) (Commitment, error) {
+	var sum, R edwards25519.ExtendedGroupElement
+	var b [32]byte
+	sum.Zero()
+	for _, c := range commits {
+		if len(c) != ed25519.PublicKeySize {
+			return nil, errors.New("cosi: bad commit length")
+		}
+		copy(b[:], c)
+		if !R.FromBytes(&b) {
+			return nil, errors.New("cosi: invalid commit")
+		}
+		sum.Add(&sum, &R)
+	}
+	sum.ToBytes(&b)
+	return b[:], nil
+}
+
+// SumParts returns the sum of the given signature parts,
+// which may be individual parts or partial aggregates.
+// Nil entries, for cosigners that took no part, are skipped.
+func SumParts(parts ...SignaturePart) (SignaturePart, error) {
+	var sum, s [32]byte
+	for _, p := range parts {
+		if p == nil {
+			continue
+		}
+		if len(p) != 32 {
+			return nil, errors.New("cosi: bad signature part length")
+		}
+		copy(s[:], p)
+		edwards25519.ScMulAdd(&sum, &sum, &scOne, &s)
+	}
+	return sum[:], nil
+}
+
+// VerifyPartial checks the partial aggregate partS of the signature parts
+// of the cosigners enabled in subset (a mask in the dense layout)
+// against the partial aggregate partR of their commits,
+// for a session with aggregate public key aggK and aggregate commit aggR.
+// It lets an aggregator check a whole subtree's contribution at once,
+// without seeing the individual commits and parts.
+// The participation mask of the Cosigners object is left unchanged.
+func (cos *Cosigners) VerifyPartial(message []byte, aggK ed25519.PublicKey,
+	aggR, partR Commitment, subset []byte, partS SignaturePart) bool {
+
+	if len(aggK) != ed25519.PublicKeySize || len(aggR) != ed25519.PublicKeySize {
+		return false
+	}
+	var A edwards25519.ExtendedGroupElement
+	A.Zero()
+	for i := range cos.keys {
+		if !maskDisabled(subset, i) {
+			A.Add(&A, &cos.keys[i])
+		}
+	}
+	return verifyWith(message, aggR, aggK, partR, partS, A)
+}
@@ -0,0 +1,165 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protocol
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+// Limits configures admission control of commit requests on a Service,
+// protecting the cosigner's entropy and CPU
+// from a leader that floods it with signing rounds.
+// Each limit is disabled when zero.
+//
+// Leaders are told apart by CommitRequest.Leader;
+// requests without one are all accounted to the same anonymous leader.
+type Limits struct {
+	// Rate and Burst bound the commit requests per second
+	// admitted from all leaders together, as a token bucket.
+	// If Burst is zero, it defaults to the rate rounded up.
+	Rate  float64
+	Burst int
+
+	// LeaderRate and LeaderBurst bound the commit requests per second
+	// admitted from any single leader.
+	LeaderRate  float64
+	LeaderBurst int
+
+	// MaxRounds caps the sessions open at once across all leaders,
+	// and MaxLeaderRounds the sessions open at once for any single leader.
+	MaxRounds       int
+	MaxLeaderRounds int
+}
+
+// ErrBackpressure is matched by every *BackpressureError
+// when tested with errors.Is.
+var ErrBackpressure = errors.New("protocol: request rejected by admission control")
+
+// Kinds of BackpressureError.
+const (
+	LimitRate         = "rate"          // global request rate
+	LimitLeaderRate   = "leader rate"   // per-leader request rate
+	LimitRounds       = "rounds"        // global concurrent sessions
+	LimitLeaderRounds = "leader rounds" // per-leader concurrent sessions
+)
+
+// BackpressureError is returned by Service.Commit
+// for a request refused by the service's Limits.
+// The leader should back off for RetryAfter before trying again;
+// RetryAfter is zero for concurrency limits,
+// which free up as open sessions are answered or expire.
+type BackpressureError struct {
+	Limit      string // one of LimitRate, LimitLeaderRate, ...
+	Leader     string
+	RetryAfter time.Duration
+}
+
+func (e *BackpressureError) Error() string {
+	msg := "protocol: " + e.Limit + " limit exceeded"
+	if e.Leader != "" {
+		msg += " for leader " + e.Leader
+	}
+	if e.RetryAfter > 0 {
+		msg += ", retry after " + e.RetryAfter.String()
+	}
+	return msg
+}
+
+func (e *BackpressureError) Is(target error) bool { return target == ErrBackpressure }
+
+// bucket is a token bucket holding up to burst tokens,
+// refilled at rate tokens per second.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func burstOf(rate float64, burst int) float64 {
+	if burst > 0 {
+		return float64(burst)
+	}
+	return math.Ceil(rate)
+}
+
+// refill brings the bucket up to date at now.
+// A new bucket starts full.
+func (b *bucket) refill(now time.Time, rate, burst float64) {
+	if b.last.IsZero() {
+		b.tokens = burst
+	} else if dt := now.Sub(b.last).Seconds(); dt > 0 {
+		b.tokens = math.Min(burst, b.tokens+dt*rate)
+	}
+	b.last = now
+}
+
+// wait returns how long until the bucket holds a whole token.
+func (b *bucket) wait(rate float64) time.Duration {
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration(math.Ceil((1 - b.tokens) / rate * float64(time.Second)))
+}
+
+// admitLocked decides whether a commit request from leader may open a session,
+// taking a token from the rate limits if so.
+func (s *Service) admitLocked(leader string, now time.Time) error {
+	lim := &s.Limits
+	if lim.MaxRounds > 0 && len(s.sessions) >= lim.MaxRounds {
+		return &BackpressureError{Limit: LimitRounds}
+	}
+	if lim.MaxLeaderRounds > 0 {
+		open := 0
+		for _, sess := range s.sessions {
+			if sess.leader == leader {
+				open++
+			}
+		}
+		if open >= lim.MaxLeaderRounds {
+			return &BackpressureError{Limit: LimitLeaderRounds, Leader: leader}
+		}
+	}
+
+	var lb *bucket
+	if lim.LeaderRate > 0 {
+		if s.buckets == nil {
+			s.buckets = make(map[string]*bucket)
+		}
+		if lb = s.buckets[leader]; lb == nil {
+			lb = &bucket{}
+			s.buckets[leader] = lb
+		}
+		lb.refill(now, lim.LeaderRate, burstOf(lim.LeaderRate, lim.LeaderBurst))
+		if d := lb.wait(lim.LeaderRate); d > 0 {
+			return &BackpressureError{Limit: LimitLeaderRate, Leader: leader, RetryAfter: d}
+		}
+	}
+	if lim.Rate > 0 {
+		s.global.refill(now, lim.Rate, burstOf(lim.Rate, lim.Burst))
+		if d := s.global.wait(lim.Rate); d > 0 {
+			return &BackpressureError{Limit: LimitRate, RetryAfter: d}
+		}
+		s.global.tokens--
+	}
+	if lb != nil {
+		lb.tokens--
+	}
+	return nil
+}
+
+// pruneBucketsLocked forgets the rate state of leaders
+// whose buckets have refilled completely,
+// so idle leaders do not accumulate.
+func (s *Service) pruneBucketsLocked(now time.Time) {
+	lim := &s.Limits
+	burst := burstOf(lim.LeaderRate, lim.LeaderBurst)
+	for leader, b := range s.buckets {
+		b.refill(now, lim.LeaderRate, burst)
+		if b.tokens >= burst {
+			delete(s.buckets, leader)
+		}
+	}
+}
@@ -0,0 +1,461 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protocol
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/golang-x-crypto/ed25519/cosi"
+	"test-server/golang-x-crypto/ed25519/cosi/logging"
+	"test-server/golang-x-crypto/ed25519/cosi/metrics"
+	"test-server/golang-x-crypto/ed25519/cosi/tracing"
+)
+
+const (
+	// DefaultPhaseTimeout bounds each protocol phase
+	// when Leader.PhaseTimeout is zero.
+	DefaultPhaseTimeout = 5 * time.Second
+
+	// DefaultMaxAttempts bounds the number of restarts of a round
+	// when Leader.MaxAttempts is zero.
+	DefaultMaxAttempts = 3
+)
+
+// ErrInsufficientCosigners is returned (wrapped in a SignError)
+// when fewer cosigners than the leader's threshold are able to take part.
+var ErrInsufficientCosigners = errors.New("protocol: too few cosigners available")
+
+// SignError reports the failure of Leader.Sign,
+// including the cosigners blamed for invalid signature parts
+// during the failed attempts.
+type SignError struct {
+	Attempts int
+	Blamed   []int
+	Err      error
+}
+
+func (e *SignError) Error() string {
+	return fmt.Sprintf("protocol: signing failed after %d attempt(s): %v", e.Attempts, e.Err)
+}
+
+func (e *SignError) Unwrap() error { return e.Err }
+
+// Result describes a successfully completed signing round.
+type Result struct {
+	// Signature is the collective signature in the R || s || mask layout.
+	Signature []byte
+
+	// Attempts is the number of rounds it took to produce the signature.
+	Attempts int
+
+	// Absent lists the cosigners that did not take part in the signature.
+	Absent []int
+
+	// Blamed lists the cosigners that returned invalid signature parts
+	// in earlier attempts and were excluded as a result.
+	Blamed []int
+
+	// Binding is the session and leader the signature is bound to
+	// if the leader binds its rounds, or nil;
+	// a bound signature verifies with cosi.VerifyBound.
+	Binding *cosi.Binding
+}
+
+// Leader drives collective signing rounds
+// against a fixed, ordered group of cosigners.
+// A Leader may be used for several concurrent rounds.
+type Leader struct {
+	// PhaseTimeout bounds the commit and response phases of each attempt.
+	// If zero, DefaultPhaseTimeout is used.
+	PhaseTimeout time.Duration
+
+	// MaxAttempts bounds the number of attempts per signature.
+	// If zero, DefaultMaxAttempts is used.
+	MaxAttempts int
+
+	// Threshold is the minimum number of cosigners
+	// that must take part in a signature.
+	// If zero, every cosigner must take part.
+	Threshold int
+
+	// ID identifies this leader to the cosigners' admission control;
+	// it is sent as CommitRequest.Leader.
+	ID string
+
+	// Term is the election term in which this leader was elected,
+	// announced to the cosigners as CommitRequest.Term.
+	// It is zero for a leader designated from outside the group.
+	Term uint64
+
+	// BindSessions makes every round bound (see cosi.Binding)
+	// to its session ID and to ID,
+	// so that no signature part can be replayed into another round.
+	BindSessions bool
+
+	// Metrics receives round counts, phase latencies and blame events.
+	// If nil, no metrics are reported.
+	Metrics metrics.Sink
+
+	// Tracer receives a span for each round, each phase,
+	// and each request to a cosigner.
+	// If nil, no spans are produced.
+	Tracer tracing.Tracer
+
+	// Logger receives a record of each round's attempts,
+	// excluded and blamed cosigners, and outcome,
+	// tagged with the round's correlation ID.
+	// If nil, nothing is logged.
+	Logger logging.Logger
+
+	publicKeys  []ed25519.PublicKey
+	group       *cosi.Cosigners
+	fingerprint []byte
+	cosigners   []Cosigner
+	rand        io.Reader
+}
+
+// NewLeader creates a leader for the group identified by publicKeys,
+// reaching the i-th cosigner through cosigners[i].
+// Session identifiers are drawn from rand, or crypto/rand if rand is nil.
+func NewLeader(publicKeys []ed25519.PublicKey, cosigners []Cosigner,
+	rand io.Reader) (*Leader, error) {
+
+	if len(publicKeys) != len(cosigners) {
+		return nil, errors.New("protocol: one cosigner handle per public key required")
+	}
+	group, err := cosi.NewCosignersErr(publicKeys, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Leader{
+		publicKeys:  publicKeys,
+		group:       group,
+		fingerprint: group.Fingerprint(),
+		cosigners:   cosigners,
+		rand:        rand,
+	}, nil
+}
+
+// Fingerprint returns the fingerprint of the leader's group,
+// which it announces in every commit request.
+func (l *Leader) Fingerprint() []byte {
+	return append([]byte{}, l.fingerprint...)
+}
+
+// Sign runs a collective signing round on message
+// with every cosigner in the group.
+func (l *Leader) Sign(ctx context.Context, message []byte) (*Result, error) {
+	return l.SignWithMask(ctx, message, nil)
+}
+
+// SignWithMask runs a collective signing round on message,
+// contacting only the cosigners enabled in mask,
+// which follows the layout described in cosi.Cosigners.SetMask.
+// A nil mask contacts every cosigner.
+func (l *Leader) SignWithMask(ctx context.Context, message, mask []byte) (*Result, error) {
+	n := len(l.publicKeys)
+	threshold := l.Threshold
+	if threshold == 0 {
+		threshold = n
+	}
+	maxAttempts := l.MaxAttempts
+	if maxAttempts == 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+
+	excluded := make([]bool, n)
+	for i := range excluded {
+		excluded[i] = i>>3 < len(mask) && mask[i>>3]&(1<<uint(i&7)) != 0
+	}
+	var blamed []int
+
+	ctx, span := l.tracer().Start(ctx, "cosi.Sign", tracing.Int("cosigners", n))
+	defer span.End()
+
+	m := l.metrics()
+	m.Add(metrics.RoundsStarted, 1)
+	start := time.Now()
+	log := l.logger()
+	fail := func(attempts int, err error) (*Result, error) {
+		m.Add(metrics.RoundsFailed, 1, metrics.Label{Name: "reason", Value: failReason(err)})
+		log.Error("signing round failed", "attempts", attempts, "blamed", blamed, "err", err)
+		span.SetAttributes(tracing.Int("attempts", attempts))
+		span.RecordError(err)
+		return nil, &SignError{Attempts: attempts, Blamed: blamed, Err: err}
+	}
+
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return fail(attempt-1, err)
+		}
+		cos := l.group.Clone()
+		var sess *cosi.LeaderSession
+		var err error
+		if l.BindSessions {
+			sess, err = cosi.NewBoundLeaderSession(cos, []byte(l.ID), message, l.rand)
+		} else {
+			sess, err = cosi.NewLeaderSession(cos, message, l.rand)
+		}
+		if err != nil {
+			return fail(attempt, err)
+		}
+		if attempt == 1 {
+			// the first session ID doubles as the round's correlation ID
+			log = logging.With(log, "round", hex.EncodeToString(sess.ID()))
+			span.SetAttributes(tracing.String("round", hex.EncodeToString(sess.ID())))
+		}
+		log.Debug("attempt started", "attempt", attempt,
+			"session", hex.EncodeToString(sess.ID()), "excluded", indices(excluded))
+
+		// Commit phase
+		phaseStart := time.Now()
+		commits := l.commitPhase(ctx, sess, excluded)
+		m.Observe(metrics.PhaseSeconds, time.Since(phaseStart).Seconds(),
+			metrics.Label{Name: "phase", Value: "commit"})
+		for i, c := range commits {
+			if excluded[i] {
+				continue
+			}
+			if c == nil {
+				excluded[i] = true // offline; skip it from now on
+				log.Warn("cosigner excluded", "cosigner", i, "reason", "no commit")
+			} else if err := sess.AddCommit(i, c); err != nil {
+				excluded[i] = true // misbehaving; skip it from now on
+				log.Warn("cosigner excluded", "cosigner", i, "reason", "invalid commit", "err", err)
+			}
+		}
+		if len(sess.Committed()) < threshold {
+			return fail(attempt, ErrInsufficientCosigners)
+		}
+		aggK, aggR, err := sess.Challenge()
+		if err != nil {
+			return fail(attempt, err)
+		}
+
+		// Response phase
+		phaseStart = time.Now()
+		parts := l.responsePhase(ctx, sess, aggK, aggR)
+		m.Observe(metrics.PhaseSeconds, time.Since(phaseStart).Seconds(),
+			metrics.Label{Name: "phase", Value: "response"})
+		restart := false
+		for _, i := range sess.Committed() {
+			if parts[i] == nil {
+				excluded[i] = true // committed but went away
+				log.Warn("cosigner excluded", "cosigner", i, "reason", "no response")
+				restart = true
+				continue
+			}
+			if err := sess.AddPart(i, parts[i]); err != nil {
+				excluded[i] = true
+				blamed = append(blamed, i)
+				m.Add(metrics.CosignersBlamed, 1)
+				log.Warn("cosigner blamed", "cosigner", i, "err", err)
+				restart = true
+			}
+		}
+		if restart {
+			continue
+		}
+
+		sig, err := sess.Signature()
+		if err != nil {
+			return fail(attempt, err)
+		}
+		res := &Result{Signature: sig, Attempts: attempt, Blamed: blamed, Binding: sess.Binding()}
+		for i := 0; i < n; i++ {
+			if cos.MaskBit(i) == cosi.Disabled {
+				res.Absent = append(res.Absent, i)
+			}
+		}
+		m.Add(metrics.RoundsCompleted, 1)
+		m.Observe(metrics.RoundSeconds, time.Since(start).Seconds())
+		m.Observe(metrics.Signers, float64(n-len(res.Absent)))
+		span.SetAttributes(tracing.Int("attempts", attempt),
+			tracing.Int("signers", n-len(res.Absent)))
+		log.Info("signing round completed", "attempts", attempt,
+			"signers", n-len(res.Absent), "absent", res.Absent, "blamed", blamed)
+		return res, nil
+	}
+	return fail(maxAttempts, errAttemptsExhausted)
+}
+
+var errAttemptsExhausted = errors.New("protocol: attempts exhausted")
+
+func (l *Leader) logger() logging.Logger {
+	if l.Logger == nil {
+		return logging.Discard
+	}
+	return l.Logger
+}
+
+// indices lists the positions of the set flags.
+func indices(flags []bool) []int {
+	var idx []int
+	for i, f := range flags {
+		if f {
+			idx = append(idx, i)
+		}
+	}
+	return idx
+}
+
+func (l *Leader) tracer() tracing.Tracer {
+	if l.Tracer == nil {
+		return tracing.Noop
+	}
+	return l.Tracer
+}
+
+func (l *Leader) metrics() metrics.Sink {
+	if l.Metrics == nil {
+		return metrics.Discard
+	}
+	return l.Metrics
+}
+
+// failReason classifies the cause of a failed round for metrics.
+func failReason(err error) string {
+	switch {
+	case errors.Is(err, ErrInsufficientCosigners):
+		return "insufficient"
+	case errors.Is(err, errAttemptsExhausted):
+		return "exhausted"
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return "canceled"
+	}
+	return "error"
+}
+
+func (l *Leader) phaseContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	timeout := l.PhaseTimeout
+	if timeout == 0 {
+		timeout = DefaultPhaseTimeout
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// commitPhase collects commits from every cosigner not excluded,
+// returning nil entries for those that failed to answer in time.
+// Cosigners that do not answer before the phase deadline are abandoned.
+func (l *Leader) commitPhase(ctx context.Context, sess *cosi.LeaderSession,
+	excluded []bool) []cosi.Commitment {
+
+	ctx, cancel := l.phaseContext(ctx)
+	defer cancel()
+	tr := l.tracer()
+	ctx, span := tr.Start(ctx, "cosi.commit_phase",
+		tracing.String("session", hex.EncodeToString(sess.ID())))
+	defer span.End()
+
+	type reply struct {
+		i      int
+		commit cosi.Commitment
+	}
+	req := &CommitRequest{
+		SessionID: sess.ID(),
+		Message:   sess.Message(),
+		Leader:    l.ID,
+		Term:      l.Term,
+		Bound:     l.BindSessions,
+		Group:     l.fingerprint,
+		Metadata:  MetadataFromContext(ctx),
+	}
+	replies := make(chan reply, len(l.cosigners))
+	pending := 0
+	for i, c := range l.cosigners {
+		if excluded[i] {
+			continue
+		}
+		pending++
+		go func(i int, c Cosigner) {
+			ctx, span := tr.Start(ctx, "cosi.Commit", tracing.Int("cosigner", i))
+			defer span.End()
+			r := *req
+			r.TraceContext = tracing.Inject(ctx)
+			resp, err := c.Commit(ctx, &r)
+			if err != nil {
+				span.RecordError(err)
+			}
+			if err != nil || resp == nil {
+				replies <- reply{i, nil}
+				return
+			}
+			replies <- reply{i, resp.Commit}
+		}(i, c)
+	}
+
+	commits := make([]cosi.Commitment, len(l.cosigners))
+	for ; pending > 0; pending-- {
+		select {
+		case r := <-replies:
+			commits[r.i] = r.commit
+		case <-ctx.Done():
+			return commits
+		}
+	}
+	return commits
+}
+
+// responsePhase collects signature parts from every cosigner that committed,
+// returning nil entries for those that failed to answer in time.
+// Cosigners that do not answer before the phase deadline are abandoned.
+func (l *Leader) responsePhase(ctx context.Context, sess *cosi.LeaderSession,
+	aggK ed25519.PublicKey, aggR cosi.Commitment) []cosi.SignaturePart {
+
+	ctx, cancel := l.phaseContext(ctx)
+	defer cancel()
+	tr := l.tracer()
+	ctx, span := tr.Start(ctx, "cosi.response_phase",
+		tracing.String("session", hex.EncodeToString(sess.ID())))
+	defer span.End()
+
+	type reply struct {
+		i    int
+		part cosi.SignaturePart
+	}
+	req := &ChallengeRequest{
+		SessionID:       sess.ID(),
+		Message:         sess.Message(),
+		AggregateKey:    aggK,
+		AggregateCommit: aggR,
+		Leader:          l.ID,
+	}
+	committed := sess.Committed()
+	replies := make(chan reply, len(committed))
+	for _, i := range committed {
+		go func(i int, c Cosigner) {
+			ctx, span := tr.Start(ctx, "cosi.Respond", tracing.Int("cosigner", i))
+			defer span.End()
+			r := *req
+			r.TraceContext = tracing.Inject(ctx)
+			resp, err := c.Respond(ctx, &r)
+			if err != nil {
+				span.RecordError(err)
+			}
+			if err != nil || resp == nil {
+				replies <- reply{i, nil}
+				return
+			}
+			replies <- reply{i, resp.Part}
+		}(i, l.cosigners[i])
+	}
+
+	parts := make([]cosi.SignaturePart, len(l.cosigners))
+	for pending := len(committed); pending > 0; pending-- {
+		select {
+		case r := <-replies:
+			parts[r.i] = r.part
+		case <-ctx.Done():
+			return parts
+		}
+	}
+	return parts
+}
@@ -0,0 +1,105 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package protocol implements a simple leader-driven
+// collective signing protocol on top of package cosi.
+//
+// The protocol follows the steps described in the cosi package documentation.
+// A Leader contacts every cosigner with a commit request,
+// closes the commit phase once all reachable cosigners have answered
+// or the phase deadline expires,
+// sends the resulting challenge to the cosigners that committed,
+// and checks every returned signature part individually.
+// A cosigner that fails to answer in the response phase
+// is excluded and the round restarted with fresh commits;
+// a cosigner that returns an invalid signature part
+// is blamed and excluded from all further attempts.
+//
+// The protocol is independent of the transport:
+// the leader talks to each cosigner through the Cosigner interface,
+// and the cosigner side of the protocol is implemented by Service,
+// which itself satisfies Cosigner and may be wrapped by any transport.
+package protocol
+
+import (
+	"context"
+
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/golang-x-crypto/ed25519/cosi"
+)
+
+// CommitRequest asks a cosigner to join a signing session
+// and produce a commit for it.
+type CommitRequest struct {
+	SessionID []byte
+	Message   []byte
+
+	// Leader identifies the requesting leader
+	// for the cosigner's per-leader admission limits (see Limits).
+	// Transports that authenticate the leader
+	// should overwrite it with the authenticated identity.
+	Leader string
+
+	// Term is the election term under which the leader runs the round
+	// (see package election), or zero for an appointed leader.
+	Term uint64
+
+	// Bound asks for a bound round (see cosi.Binding),
+	// in which the cosigner signs the message
+	// prefixed with SessionID and Leader.
+	Bound bool
+
+	// Group is the fingerprint (see cosi.Cosigners.Fingerprint)
+	// of the group the leader runs the round for.
+	// A cosigner that knows its group refuses rounds for another one
+	// (see Service.SetGroup).
+	Group []byte
+
+	// Metadata describes the message for the cosigner's Validator.
+	// The leader sends the metadata attached to its context with WithMetadata.
+	Metadata Metadata
+
+	// TraceContext optionally carries the W3C traceparent
+	// of the leader's span for this request (see package tracing).
+	TraceContext string
+}
+
+// CommitResponse carries a cosigner's commit for a session.
+type CommitResponse struct {
+	Commit cosi.Commitment
+}
+
+// ChallengeRequest carries the aggregate public key and aggregate commit
+// of a session whose commit phase has closed,
+// asking the cosigner for its signature part.
+type ChallengeRequest struct {
+	SessionID       []byte
+	Message         []byte
+	AggregateKey    ed25519.PublicKey
+	AggregateCommit cosi.Commitment
+
+	// Leader identifies the leader of a bound round,
+	// which must be the one that requested the commit.
+	// Transports that authenticate the leader
+	// should overwrite it with the authenticated identity.
+	Leader string
+
+	// TraceContext optionally carries the W3C traceparent
+	// of the leader's span for this request (see package tracing).
+	TraceContext string
+}
+
+// ChallengeResponse carries a cosigner's signature part for a session.
+type ChallengeResponse struct {
+	Part cosi.SignaturePart
+}
+
+// Cosigner is the leader's handle on a single cosigner,
+// local or remote.
+// Implementations must be safe for concurrent use
+// and should honor cancellation of ctx.
+type Cosigner interface {
+	Commit(ctx context.Context, req *CommitRequest) (*CommitResponse, error)
+	Respond(ctx context.Context, req *ChallengeRequest) (*ChallengeResponse, error)
+}
@@ -0,0 +1,364 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protocol
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/golang-x-crypto/ed25519/cosi"
+)
+
+var message = []byte("test message")
+
+func newGroup(t *testing.T, n int) ([]ed25519.PublicKey, []*Service) {
+	var pubs []ed25519.PublicKey
+	var svcs []*Service
+	for i := 0; i < n; i++ {
+		pub, priv, err := ed25519.GenerateKey(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pubs = append(pubs, pub)
+		svcs = append(svcs, NewService(priv, nil))
+	}
+	return pubs, svcs
+}
+
+func TestLeaderSign(t *testing.T) {
+	pubs, svcs := newGroup(t, 4)
+	cosigners := make([]Cosigner, len(svcs))
+	for i, s := range svcs {
+		cosigners[i] = s
+	}
+	l, err := NewLeader(pubs, cosigners, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := l.Sign(context.Background(), message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cosi.Verify(pubs, nil, message, res.Signature) {
+		t.Errorf("valid signature rejected")
+	}
+	for i, s := range svcs {
+		if s.OpenSessions() != 0 {
+			t.Errorf("cosigner %d kept %d sessions open", i, s.OpenSessions())
+		}
+	}
+}
+
+func TestServiceSessions(t *testing.T) {
+	_, svcs := newGroup(t, 1)
+	s := svcs[0]
+	ctx := context.Background()
+	id := []byte("session")
+	if _, err := s.Commit(ctx, &CommitRequest{SessionID: id, Message: message}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Commit(ctx, &CommitRequest{SessionID: id, Message: message}); err != ErrDuplicateSession {
+		t.Errorf("duplicate session: got %v", err)
+	}
+	_, err := s.Respond(ctx, &ChallengeRequest{SessionID: []byte("other")})
+	if !errors.Is(err, ErrUnknownSession) {
+		t.Errorf("unknown session: got %v", err)
+	}
+}
+
+func TestServiceLimits(t *testing.T) {
+	_, svcs := newGroup(t, 1)
+	s := svcs[0]
+	now := time.Unix(1000, 0)
+	s.now = func() time.Time { return now }
+	s.Limits = Limits{Rate: 10, Burst: 3, LeaderRate: 1, LeaderBurst: 2, MaxLeaderRounds: 2}
+
+	ctx := context.Background()
+	n := 0
+	commit := func(leader string) error {
+		n++
+		id := []byte{byte(n)}
+		_, err := s.Commit(ctx, &CommitRequest{SessionID: id, Message: message, Leader: leader})
+		return err
+	}
+	check := func(err error, limit string) {
+		t.Helper()
+		var be *BackpressureError
+		if limit == "" {
+			if err != nil {
+				t.Errorf("request rejected: %v", err)
+			}
+			return
+		}
+		if !errors.As(err, &be) || be.Limit != limit || !errors.Is(err, ErrBackpressure) {
+			t.Errorf("got %v, want %s backpressure", err, limit)
+		}
+	}
+
+	check(commit("a"), "")
+	check(commit("a"), "")
+	check(commit("a"), LimitLeaderRounds)
+	check(commit("b"), "")
+	check(commit("c"), LimitRate)
+
+	// answering a session frees a round, but leader a is out of tokens
+	if _, err := s.Respond(ctx, &ChallengeRequest{SessionID: []byte{1}, Message: message}); err == nil {
+		t.Fatal("bogus challenge accepted")
+	}
+	err := commit("a")
+	check(err, LimitLeaderRate)
+	if be := err.(*BackpressureError); be.RetryAfter != time.Second {
+		t.Errorf("retry after %v, want 1s", be.RetryAfter)
+	}
+
+	now = now.Add(time.Second)
+	check(commit("a"), "")
+}
+
+func TestServiceValidator(t *testing.T) {
+	pubs, svcs := newGroup(t, 3)
+	allow := NewDigestAllowlist()
+	allow.Allow(message)
+	for _, s := range svcs {
+		s.Validator = Validators(MaxMessageSize(64), ContentTypes("text/plain"), allow)
+	}
+	cosigners := make([]Cosigner, len(svcs))
+	for i, s := range svcs {
+		cosigners[i] = s
+	}
+	l, err := NewLeader(pubs, cosigners, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := WithMetadata(context.Background(), Metadata{MetadataContentType: "text/plain"})
+	if _, err := l.Sign(ctx, message); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Sign(context.Background(), message); !errors.Is(err, ErrInsufficientCosigners) {
+		t.Errorf("message without content type: got %v", err)
+	}
+	if _, err := l.Sign(ctx, []byte("not allowed")); err == nil {
+		t.Error("message off the allowlist was signed")
+	}
+	_, err = svcs[0].Commit(ctx, &CommitRequest{SessionID: []byte("s"), Message: make([]byte, 65)})
+	if !errors.Is(err, ErrRejected) {
+		t.Errorf("oversized message: got %v", err)
+	}
+}
+
+func TestLeaderBound(t *testing.T) {
+	pubs, svcs := newGroup(t, 3)
+	cosigners := make([]Cosigner, len(svcs))
+	for i, s := range svcs {
+		s.RequireBinding = true
+		cosigners[i] = s
+	}
+	l, err := NewLeader(pubs, cosigners, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.ID = "leader-1"
+	if _, err := l.Sign(context.Background(), message); !errors.Is(err, ErrInsufficientCosigners) {
+		t.Errorf("unbound round: got %v, want ErrInsufficientCosigners", err)
+	}
+
+	l.BindSessions = true
+	res, err := l.Sign(context.Background(), message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Binding == nil || string(res.Binding.Leader) != l.ID {
+		t.Fatalf("result binding %+v", res.Binding)
+	}
+	if !cosi.VerifyBound(pubs, nil, *res.Binding, message, res.Signature) {
+		t.Errorf("valid bound signature rejected")
+	}
+	if cosi.Verify(pubs, nil, message, res.Signature) {
+		t.Errorf("bound signature verified without its binding")
+	}
+
+	// an unbound round cannot forge a signature bound to another leader
+	ctx := context.Background()
+	for _, s := range svcs {
+		s.RequireBinding = false
+	}
+	l.BindSessions = false
+	victim := cosi.Binding{SessionID: []byte("victim session"), Leader: []byte("leader-2")}
+	if res, err := l.Sign(ctx, victim.Message(message)); err == nil {
+		if cosi.VerifyBound(pubs, nil, victim, message, res.Signature) {
+			t.Errorf("unbound round produced a signature bound to another leader")
+		}
+		t.Errorf("unbound round over a bound message signed")
+	}
+	_, err = svcs[0].Commit(ctx, &CommitRequest{SessionID: []byte("forged"), Message: victim.Message(message), Leader: l.ID})
+	if !errors.Is(err, ErrUnbound) {
+		t.Errorf("unbound commit over a bound message: got %v, want ErrUnbound", err)
+	}
+	if _, err := l.Sign(ctx, message); err != nil {
+		t.Errorf("unbound round over an ordinary message: %v", err)
+	}
+
+	// a challenge from another leader is refused
+	id := []byte("session")
+	resp, err := svcs[0].Commit(ctx, &CommitRequest{SessionID: id, Message: message, Leader: l.ID, Bound: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = svcs[0].Respond(ctx, &ChallengeRequest{SessionID: id, Message: message,
+		AggregateKey: pubs[0], AggregateCommit: resp.Commit, Leader: "leader-2"})
+	if err == nil {
+		t.Errorf("challenge from another leader accepted")
+	}
+}
+
+func TestEnvelopeTimes(t *testing.T) {
+	v := EnvelopeTimes(time.Minute, time.Hour)
+	ctx := context.Background()
+	now := time.Now()
+	for _, test := range []struct {
+		env *cosi.Envelope
+		ok  bool
+	}{
+		{cosi.NewEnvelope(message, now, time.Hour), true},
+		{cosi.NewEnvelope(message, now.Add(-time.Hour), time.Hour), false}, // backdated
+		{cosi.NewEnvelope(message, now, 2*time.Hour), false},               // lives too long
+		{cosi.NewEnvelope(message, now, 0), false},                         // never expires
+	} {
+		if err := v.Validate(ctx, test.env.SignedContent(), nil); (err == nil) != test.ok {
+			t.Errorf("envelope %v..%v: got %v", test.env.Timestamp, test.env.Expiry, err)
+		}
+	}
+	if err := v.Validate(ctx, message, nil); err == nil {
+		t.Errorf("plain message accepted")
+	}
+}
+
+func TestServiceRestart(t *testing.T) {
+	const n = 2
+	pubs := make([]ed25519.PublicKey, n)
+	privs := make([]ed25519.PrivateKey, n)
+	svcs := make([]*Service, n)
+	for i := range svcs {
+		pubs[i], privs[i], _ = ed25519.GenerateKey(nil)
+		svcs[i] = NewService(privs[i], nil)
+	}
+	ctx := context.Background()
+	s, err := cosi.NewBoundLeaderSession(cosi.NewCosigners(pubs, nil), []byte("leader"), message, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, svc := range svcs {
+		resp, err := svc.Commit(ctx, &CommitRequest{SessionID: s.ID(), Message: message, Leader: "leader", Bound: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.AddCommit(i, resp.Commit)
+	}
+
+	// Cosigner 1 shuts down before the challenge arrives.
+	old := svcs[1]
+	old.Drain()
+	if _, err := old.Commit(ctx, &CommitRequest{SessionID: []byte("new"), Message: message}); !errors.Is(err, ErrDraining) {
+		t.Errorf("Commit while draining: got %v, want ErrDraining", err)
+	}
+	wctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := old.WaitIdle(wctx); err != context.DeadlineExceeded {
+		t.Errorf("WaitIdle with an open session: got %v", err)
+	}
+	var saved bytes.Buffer
+	if n, err := old.SaveSessions(&saved); n != 1 || err != nil {
+		t.Fatalf("SaveSessions = %d, %v", n, err)
+	}
+	if old.OpenSessions() != 0 || old.WaitIdle(ctx) != nil {
+		t.Errorf("saved session still open")
+	}
+
+	expired := NewService(privs[1], nil)
+	expired.SessionTimeout = time.Nanosecond
+	if n, err := expired.LoadSessions(bytes.NewReader(saved.Bytes())); n != 0 || err != nil {
+		t.Errorf("LoadSessions of an expired session = %d, %v", n, err)
+	}
+	if _, err := NewService(privs[0], nil).LoadSessions(bytes.NewReader(saved.Bytes())); err == nil {
+		t.Errorf("sessions restored under another key")
+	}
+	svcs[1] = NewService(privs[1], nil)
+	if n, err := svcs[1].LoadSessions(bytes.NewReader(saved.Bytes())); n != 1 || err != nil {
+		t.Fatalf("LoadSessions = %d, %v", n, err)
+	}
+
+	aggK, aggR, err := s.Challenge()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, svc := range svcs {
+		resp, err := svc.Respond(ctx, &ChallengeRequest{SessionID: s.ID(), Message: message,
+			AggregateKey: aggK, AggregateCommit: aggR, Leader: "leader"})
+		if err != nil {
+			t.Fatalf("cosigner %d: %v", i, err)
+		}
+		if err := s.AddPart(i, resp.Part); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sig, err := s.Signature()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cosi.VerifyBound(pubs, nil, *s.Binding(), message, sig) {
+		t.Errorf("signature across a restart rejected")
+	}
+}
+
+func TestStaleGroup(t *testing.T) {
+	pubs, svcs := newGroup(t, 3)
+	cosigners := []Cosigner{svcs[0], svcs[1], svcs[2]}
+	old, err := NewLeader(pubs, cosigners, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range svcs {
+		s.SetGroup(old.Fingerprint())
+	}
+	ctx := context.Background()
+	if _, err := old.Sign(ctx, message); err != nil {
+		t.Fatal(err)
+	}
+
+	// cosigner 2가 빠진 그룹: cosigner가 아직 이전 그룹이면 거부
+	l, err := NewLeader(pubs[:2], cosigners[:2], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Sign(ctx, message); !errors.Is(err, ErrInsufficientCosigners) {
+		t.Errorf("round for a new group: error = %v, want ErrInsufficientCosigners", err)
+	}
+	_, err = svcs[0].Commit(ctx, &CommitRequest{SessionID: []byte("s1"), Message: message, Group: l.Fingerprint()})
+	if !errors.Is(err, ErrStaleGroup) {
+		t.Errorf("Commit(other group) error = %v, want ErrStaleGroup", err)
+	}
+	if _, err := svcs[0].Commit(ctx, &CommitRequest{SessionID: []byte("s2"), Message: message}); err != nil {
+		t.Errorf("Commit(no group announced): %v", err)
+	}
+
+	for _, s := range svcs[:2] {
+		s.SetGroup(l.Fingerprint())
+	}
+	res, err := l.Sign(ctx, message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cosi.Verify(pubs[:2], nil, message, res.Signature) {
+		t.Errorf("signature of the new group rejected")
+	}
+	if _, err := old.Sign(ctx, message); err == nil {
+		t.Errorf("round for the previous group succeeded")
+	}
+}
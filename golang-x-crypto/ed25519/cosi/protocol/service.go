@@ -0,0 +1,303 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protocol
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/golang-x-crypto/ed25519/cosi"
+	"test-server/golang-x-crypto/ed25519/cosi/logging"
+	"test-server/golang-x-crypto/ed25519/cosi/metrics"
+	"test-server/golang-x-crypto/ed25519/cosi/tracing"
+)
+
+// DefaultSessionTimeout is how long a Service keeps the commit secret
+// of a session for which no challenge has arrived.
+const DefaultSessionTimeout = time.Minute
+
+var (
+	// ErrUnknownSession is returned by Service.Respond
+	// for a session that never committed, already responded, or expired.
+	ErrUnknownSession = errors.New("protocol: unknown or expired session")
+
+	// ErrDuplicateSession is returned by Service.Commit
+	// for a session identifier that is already in use.
+	ErrDuplicateSession = errors.New("protocol: duplicate session")
+
+	// ErrUnbound is returned by Service.Commit
+	// for an unbound round when the service requires binding,
+	// or over a message that only a bound round may sign
+	// (see cosi.IsBoundMessage).
+	ErrUnbound = errors.New("protocol: round not bound to session and leader")
+
+	// ErrStaleGroup is returned by Service.Commit
+	// for a round announced for a group other than the service's
+	// (see Service.SetGroup).
+	ErrStaleGroup = errors.New("protocol: round announced for another cosigner group")
+)
+
+// Auditor records the signature parts a Service produces.
+type Auditor interface {
+	RecordPart(sessionID, message []byte) error
+}
+
+type serviceSession struct {
+	participant *cosi.Participant
+	message     []byte
+	leader      string
+	bound       bool
+	started     time.Time
+}
+
+// Service implements the cosigner side of the protocol.
+// It keeps one cosi.Participant per open session,
+// so each commit secret is used for exactly one signature part.
+// Service satisfies the Cosigner interface
+// and is safe for concurrent use.
+type Service struct {
+	// SessionTimeout bounds how long an unanswered session is kept.
+	// If zero, DefaultSessionTimeout is used.
+	SessionTimeout time.Duration
+
+	// Limits configures admission control of commit requests.
+	// The zero value admits every request.
+	Limits Limits
+
+	// Validator, if set, must accept each message
+	// before the service commits to signing it.
+	Validator Validator
+
+	// RequireBinding refuses commit requests for unbound rounds,
+	// so that every signature part this service produces
+	// is bound to its session and leader (see cosi.Binding).
+	RequireBinding bool
+
+	// Auditor, if set, is told of every signature part
+	// before it leaves the service (see package audit).
+	Auditor Auditor
+
+	// Metrics receives counts of handled requests.
+	// If nil, no metrics are reported.
+	Metrics metrics.Sink
+
+	// Tracer receives a span for each request handled,
+	// continuing the leader's trace when the request carries one.
+	// If nil, no spans are produced.
+	Tracer tracing.Tracer
+
+	// Logger receives a record of each request handled,
+	// tagged with its session ID.
+	// If nil, nothing is logged.
+	Logger logging.Logger
+
+	privateKey ed25519.PrivateKey
+	rand       io.Reader
+
+	mu       sync.Mutex
+	draining bool
+	group    []byte // fingerprint, nil if unknown
+	sessions map[string]*serviceSession
+	global   bucket
+	buckets  map[string]*bucket // per-leader rate state
+	now      func() time.Time
+}
+
+// NewService creates the cosigner side of the protocol
+// for the holder of privateKey.
+// Commit randomness is drawn from rand, or crypto/rand if rand is nil.
+func NewService(privateKey ed25519.PrivateKey, rand io.Reader) *Service {
+	return &Service{
+		privateKey: privateKey,
+		rand:       rand,
+		sessions:   make(map[string]*serviceSession),
+		now:        time.Now,
+	}
+}
+
+// PublicKey returns the public key this service signs with.
+func (s *Service) PublicKey() ed25519.PublicKey {
+	return s.privateKey.Public().(ed25519.PublicKey)
+}
+
+// Commit opens a session and returns a fresh commit for it.
+// A message refused by the service's Validator
+// fails with an error wrapping ErrRejected,
+// and a request refused by the service's Limits
+// fails with a *BackpressureError before any randomness is drawn.
+// A round announced for another group than the one set by SetGroup
+// fails with ErrStaleGroup.
+// Once Drain has been called, Commit fails with ErrDraining.
+func (s *Service) Commit(ctx context.Context, req *CommitRequest) (resp *CommitResponse, err error) {
+	ctx, span := s.startSpan(ctx, "cosi.cosigner.Commit", req.SessionID, req.TraceContext)
+	defer func() { s.finish("commit", req.SessionID, span, err) }()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if !req.Bound && (s.RequireBinding || cosi.IsBoundMessage(req.Message)) {
+		return nil, ErrUnbound
+	}
+	if s.Validator != nil {
+		vctx := context.WithValue(ctx, leaderKey{}, leaderInfo{req.Leader, req.Term})
+		if err := s.Validator.Validate(vctx, req.Message, req.Metadata); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrRejected, err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.draining {
+		return nil, ErrDraining
+	}
+	if s.group != nil && len(req.Group) != 0 && !bytes.Equal(req.Group, s.group) {
+		return nil, ErrStaleGroup
+	}
+	now := s.now()
+	s.expireLocked(now)
+
+	key := string(req.SessionID)
+	if _, ok := s.sessions[key]; ok {
+		return nil, ErrDuplicateSession
+	}
+	if err := s.admitLocked(req.Leader, now); err != nil {
+		return nil, err
+	}
+	p := cosi.NewParticipant(s.privateKey, s.rand)
+	var commit cosi.Commitment
+	if req.Bound {
+		commit, err = p.CommitBound(cosi.Binding{SessionID: req.SessionID, Leader: []byte(req.Leader)})
+	} else {
+		commit, err = p.Commit()
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.sessions[key] = &serviceSession{
+		participant: p,
+		message:     append([]byte{}, req.Message...),
+		leader:      req.Leader,
+		bound:       req.Bound,
+		started:     now,
+	}
+	return &CommitResponse{Commit: commit}, nil
+}
+
+// Respond produces this cosigner's signature part for an open session
+// and closes the session.
+// The message in the challenge must match the one committed to,
+// and in a bound round so must the leader.
+func (s *Service) Respond(ctx context.Context, req *ChallengeRequest) (resp *ChallengeResponse, err error) {
+	ctx, span := s.startSpan(ctx, "cosi.cosigner.Respond", req.SessionID, req.TraceContext)
+	defer func() { s.finish("respond", req.SessionID, span, err) }()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	key := string(req.SessionID)
+	sess, ok := s.sessions[key]
+	delete(s.sessions, key)
+	s.mu.Unlock()
+	if !ok {
+		return nil, ErrUnknownSession
+	}
+	if string(sess.message) != string(req.Message) {
+		return nil, errors.New("protocol: challenge for a different message")
+	}
+
+	var part cosi.SignaturePart
+	if sess.bound {
+		binding := cosi.Binding{SessionID: req.SessionID, Leader: []byte(req.Leader)}
+		part, err = sess.participant.CosignBound(binding, sess.message, req.AggregateKey, req.AggregateCommit)
+	} else {
+		part, err = sess.participant.Cosign(sess.message, req.AggregateKey, req.AggregateCommit)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if s.Auditor != nil {
+		if err := s.Auditor.RecordPart(req.SessionID, sess.message); err != nil {
+			return nil, err // never release a part that was not recorded
+		}
+	}
+	return &ChallengeResponse{Part: part}, nil
+}
+
+// SetGroup sets the fingerprint (see cosi.Cosigners.Fingerprint)
+// of the group the service signs for.
+// From then on, Commit refuses rounds whose leader announces another group,
+// such as a leader still running with the group before a membership change.
+// Rounds that announce no group are not checked.
+// SetGroup may be called at any time; sessions already open are kept.
+func (s *Service) SetGroup(fingerprint []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.group = append([]byte{}, fingerprint...)
+}
+
+// OpenSessions returns the number of sessions awaiting a challenge.
+func (s *Service) OpenSessions() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.sessions)
+}
+
+func (s *Service) startSpan(ctx context.Context, name string,
+	sessionID []byte, traceContext string) (context.Context, tracing.Span) {
+
+	tr := s.Tracer
+	if tr == nil {
+		tr = tracing.Noop
+	}
+	return tr.Start(tracing.Extract(ctx, traceContext), name,
+		tracing.String("session", hex.EncodeToString(sessionID)))
+}
+
+// finish records the outcome of a request in the log, metrics and trace.
+func (s *Service) finish(op string, sessionID []byte, span tracing.Span, err error) {
+	if s.Logger != nil {
+		session := hex.EncodeToString(sessionID)
+		if err != nil {
+			s.Logger.Warn("request rejected", "op", op, "session", session, "err", err)
+		} else {
+			s.Logger.Debug("request served", "op", op, "session", session)
+		}
+	}
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.End()
+	if s.Metrics == nil {
+		return
+	}
+	result := "ok"
+	if errors.Is(err, ErrBackpressure) {
+		result = "rejected"
+	} else if err != nil {
+		result = "error"
+	}
+	s.Metrics.Add(metrics.CosignerRequests, 1,
+		metrics.Label{Name: "op", Value: op}, metrics.Label{Name: "result", Value: result})
+}
+
+func (s *Service) expireLocked(now time.Time) {
+	timeout := s.SessionTimeout
+	if timeout == 0 {
+		timeout = DefaultSessionTimeout
+	}
+	for k, sess := range s.sessions {
+		if now.Sub(sess.started) > timeout {
+			delete(s.sessions, k)
+		}
+	}
+	s.pruneBucketsLocked(now)
+}
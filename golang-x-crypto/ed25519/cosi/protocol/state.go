@@ -0,0 +1,171 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protocol
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"test-server/golang-x-crypto/ed25519/cosi"
+)
+
+// Service lifecycle.
+//
+// A cosigner shutting down calls Drain, so that no new sessions are opened,
+// then WaitIdle, so that rounds in flight can complete,
+// and finally SaveSessions for those that did not complete in time.
+// After restarting, LoadSessions restores them,
+// and their leaders can still collect the signature parts
+// as long as the sessions have not expired.
+
+// ErrDraining is returned by Service.Commit once Service.Drain has been called.
+var ErrDraining = errors.New("protocol: cosigner is shutting down")
+
+// drainPoll is how often WaitIdle checks for open sessions.
+const drainPoll = 50 * time.Millisecond
+
+// Drain makes the service refuse new sessions with ErrDraining.
+// Sessions already open can still be answered by Respond.
+func (s *Service) Drain() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.draining = true
+}
+
+// Draining reports whether Drain has been called.
+func (s *Service) Draining() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.draining
+}
+
+// WaitIdle waits until no sessions are open,
+// because every session was either answered or expired,
+// or until ctx is done, in which case it returns ctx.Err().
+func (s *Service) WaitIdle(ctx context.Context) error {
+	t := time.NewTicker(drainPoll)
+	defer t.Stop()
+	for {
+		s.mu.Lock()
+		s.expireLocked(s.now())
+		n := len(s.sessions)
+		s.mu.Unlock()
+		if n == 0 {
+			return nil
+		}
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// savedSessions is the JSON document written by SaveSessions.
+type savedSessions struct {
+	Version  int            `json:"version"`
+	Sessions []savedSession `json:"sessions"`
+}
+
+type savedSession struct {
+	ID      []byte    `json:"id"`
+	Message []byte    `json:"message"`
+	Leader  string    `json:"leader,omitempty"`
+	Bound   bool      `json:"bound,omitempty"`
+	Started time.Time `json:"started"`
+	State   []byte    `json:"state"` // cosi.Participant state
+}
+
+const savedSessionsVersion = 1
+
+// SaveSessions closes every open session and writes it,
+// including its commit secret, to w,
+// so that a restarted service can answer its challenge after LoadSessions.
+// It returns the number of sessions written.
+//
+// The sessions are closed before anything is written,
+// so that no commit secret can be used both by this service
+// and by the one restoring it; if writing fails, they are lost.
+// The output must be protected like the private key (see cosi.Participant).
+func (s *Service) SaveSessions(w io.Writer) (int, error) {
+	s.mu.Lock()
+	s.expireLocked(s.now())
+	doc := savedSessions{Version: savedSessionsVersion}
+	var errs []error
+	for id, sess := range s.sessions {
+		state, err := sess.participant.MarshalBinary()
+		delete(s.sessions, id)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("session %x: %v", id, err))
+			continue
+		}
+		doc.Sessions = append(doc.Sessions, savedSession{
+			ID:      []byte(id),
+			Message: sess.message,
+			Leader:  sess.leader,
+			Bound:   sess.bound,
+			Started: sess.started,
+			State:   state,
+		})
+	}
+	s.mu.Unlock()
+
+	if len(doc.Sessions) == 0 && len(errs) == 0 {
+		return 0, nil
+	}
+	if err := json.NewEncoder(w).Encode(&doc); err != nil {
+		return 0, err
+	}
+	return len(doc.Sessions), errors.Join(errs...)
+}
+
+// LoadSessions restores sessions written by SaveSessions
+// for the same private key, skipping those that have expired
+// and those whose identifier is already in use.
+// It returns the number of sessions restored.
+func (s *Service) LoadSessions(r io.Reader) (int, error) {
+	var doc savedSessions
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return 0, fmt.Errorf("protocol: reading saved sessions: %v", err)
+	}
+	if doc.Version != savedSessionsVersion {
+		return 0, fmt.Errorf("protocol: unsupported saved sessions version %d", doc.Version)
+	}
+	timeout := s.SessionTimeout
+	if timeout == 0 {
+		timeout = DefaultSessionTimeout
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	n := 0
+	for _, saved := range doc.Sessions {
+		key := string(saved.ID)
+		if now.Sub(saved.Started) > timeout {
+			continue
+		}
+		if _, ok := s.sessions[key]; ok {
+			continue
+		}
+		p := cosi.NewParticipant(s.privateKey, s.rand)
+		if err := p.UnmarshalBinary(saved.State); err != nil {
+			return n, fmt.Errorf("protocol: session %x: %v", saved.ID, err)
+		}
+		s.sessions[key] = &serviceSession{
+			participant: p,
+			message:     saved.Message,
+			leader:      saved.Leader,
+			bound:       saved.Bound,
+			started:     saved.Started,
+		}
+		n++
+	}
+	return n, nil
+}
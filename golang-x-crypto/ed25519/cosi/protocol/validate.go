@@ -0,0 +1,186 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protocol
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"test-server/golang-x-crypto/ed25519/cosi"
+)
+
+// Metadata carries application-defined attributes of a message
+// from the leader to the cosigners, such as its content type,
+// for the cosigners' validators to inspect.
+// It is not covered by the collective signature.
+type Metadata map[string]string
+
+// MetadataContentType is the metadata key naming the message's media type.
+const MetadataContentType = "content-type"
+
+type metadataKey struct{}
+
+// WithMetadata returns a context that makes Leader.Sign
+// send md along with the message to every cosigner.
+func WithMetadata(ctx context.Context, md Metadata) context.Context {
+	return context.WithValue(ctx, metadataKey{}, md)
+}
+
+// MetadataFromContext returns the metadata attached to ctx by WithMetadata.
+func MetadataFromContext(ctx context.Context) Metadata {
+	md, _ := ctx.Value(metadataKey{}).(Metadata)
+	return md
+}
+
+type leaderKey struct{}
+
+type leaderInfo struct {
+	id   string
+	term uint64
+}
+
+// LeaderFromContext returns the identity of the leader
+// that sent the request being validated (see CommitRequest.Leader).
+// Service attaches it to the context it passes to its Validator.
+func LeaderFromContext(ctx context.Context) string {
+	info, _ := ctx.Value(leaderKey{}).(leaderInfo)
+	return info.id
+}
+
+// TermFromContext returns the election term announced
+// in the request being validated (see CommitRequest.Term).
+func TermFromContext(ctx context.Context) uint64 {
+	info, _ := ctx.Value(leaderKey{}).(leaderInfo)
+	return info.term
+}
+
+// ErrRejected is wrapped by the error Service.Commit returns
+// for a message refused by the service's Validator.
+var ErrRejected = errors.New("protocol: message rejected")
+
+// Validator inspects a message a cosigner is asked to sign,
+// before the cosigner commits to the session.
+// A non-nil error refuses participation.
+// This is the step of the protocol where cosigners
+// check that the message is one they are willing to vouch for.
+type Validator interface {
+	Validate(ctx context.Context, message []byte, md Metadata) error
+}
+
+// ValidatorFunc adapts an ordinary function to the Validator interface.
+type ValidatorFunc func(ctx context.Context, message []byte, md Metadata) error
+
+func (f ValidatorFunc) Validate(ctx context.Context, message []byte, md Metadata) error {
+	return f(ctx, message, md)
+}
+
+// Validators combines several validators into one
+// that accepts a message only if all of them do,
+// consulting them in order.
+func Validators(vs ...Validator) Validator {
+	return ValidatorFunc(func(ctx context.Context, message []byte, md Metadata) error {
+		for _, v := range vs {
+			if err := v.Validate(ctx, message, md); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// MaxMessageSize returns a Validator refusing messages longer than n bytes.
+func MaxMessageSize(n int) Validator {
+	return ValidatorFunc(func(_ context.Context, message []byte, _ Metadata) error {
+		if len(message) > n {
+			return errors.New("message of " + strconv.Itoa(len(message)) +
+				" bytes exceeds limit of " + strconv.Itoa(n))
+		}
+		return nil
+	})
+}
+
+// ContentTypes returns a Validator accepting only messages
+// whose MetadataContentType is one of types.
+func ContentTypes(types ...string) Validator {
+	allowed := make(map[string]bool, len(types))
+	for _, t := range types {
+		allowed[t] = true
+	}
+	return ValidatorFunc(func(_ context.Context, _ []byte, md Metadata) error {
+		if ct := md[MetadataContentType]; !allowed[ct] {
+			return fmt.Errorf("content type %q not allowed", ct)
+		}
+		return nil
+	})
+}
+
+// EnvelopeTimes returns a Validator accepting only signed-envelope content
+// (see cosi.Envelope) timestamped within skew of the cosigner's clock
+// and expiring no later than maxTTL after its timestamp,
+// so that a leader cannot have the group backdate an envelope
+// or issue one that lives longer than policy allows.
+// A zero maxTTL also accepts envelopes without expiry.
+func EnvelopeTimes(skew, maxTTL time.Duration) Validator {
+	return ValidatorFunc(func(_ context.Context, message []byte, _ Metadata) error {
+		e, err := cosi.ParseEnvelopeContent(message)
+		if err != nil {
+			return err
+		}
+		if d := time.Since(e.Timestamp); d > skew || d < -skew {
+			return errors.New("envelope timestamp off by " + d.String())
+		}
+		if maxTTL > 0 && (e.Expiry.IsZero() || e.Expiry.Sub(e.Timestamp) > maxTTL) {
+			return errors.New("envelope lifetime exceeds " + maxTTL.String())
+		}
+		return nil
+	})
+}
+
+// DigestAllowlist is a Validator accepting only messages
+// whose SHA-256 digest has been allowed in advance,
+// e.g. by an out-of-band approval process.
+// It is safe for concurrent use, so digests may be allowed
+// and revoked while the cosigner is running.
+type DigestAllowlist struct {
+	mu      sync.RWMutex
+	digests map[[sha256.Size]byte]bool
+}
+
+// NewDigestAllowlist returns an allowlist holding the given SHA-256 digests.
+func NewDigestAllowlist(digests ...[sha256.Size]byte) *DigestAllowlist {
+	a := &DigestAllowlist{digests: make(map[[sha256.Size]byte]bool)}
+	for _, d := range digests {
+		a.digests[d] = true
+	}
+	return a
+}
+
+// Allow adds the digest of message to the allowlist.
+func (a *DigestAllowlist) Allow(message []byte) {
+	a.mu.Lock()
+	a.digests[sha256.Sum256(message)] = true
+	a.mu.Unlock()
+}
+
+// Revoke removes the digest of message from the allowlist.
+func (a *DigestAllowlist) Revoke(message []byte) {
+	a.mu.Lock()
+	delete(a.digests, sha256.Sum256(message))
+	a.mu.Unlock()
+}
+
+func (a *DigestAllowlist) Validate(_ context.Context, message []byte, _ Metadata) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if !a.digests[sha256.Sum256(message)] {
+		return errors.New("message digest not on the allowlist")
+	}
+	return nil
+}
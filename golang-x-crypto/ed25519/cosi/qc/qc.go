@@ -0,0 +1,174 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package qc provides quorum certificates built from collective signatures,
+// for BFT consensus engines that use cosi for vote aggregation.
+//
+// A QuorumCert bundles the digest of a block or statement
+// with the collective signature on it,
+// the participation mask, and the fingerprint of the cosigner group,
+// and VerifyQC checks in one call that a certificate was produced
+// by the expected group and carries at least two thirds
+// of the group's voting power.
+package qc
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"errors"
+	"math/bits"
+
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/golang-x-crypto/ed25519/cosi"
+)
+
+var (
+	// ErrWrongGroup is returned by VerifyQC for a certificate
+	// produced by a different cosigner group.
+	ErrWrongGroup = errors.New("qc: certificate from a different cosigner group")
+
+	// ErrNoQuorum is returned by VerifyQC for a certificate
+	// whose signers hold less than two thirds of the voting power.
+	ErrNoQuorum = errors.New("qc: insufficient voting power")
+
+	// ErrBadSignature is returned by VerifyQC for a certificate
+	// whose collective signature is invalid.
+	ErrBadSignature = errors.New("qc: invalid collective signature")
+)
+
+// QuorumCert certifies that a quorum of a cosigner group
+// collectively signed a digest.
+type QuorumCert struct {
+	Digest      []byte // block or statement digest that was signed
+	Signature   []byte // the R || s core of the collective signature
+	Mask        []byte // participation mask, in the group's mask format
+	Fingerprint []byte // cosi.Cosigners.Fingerprint of the group
+}
+
+// New builds a quorum certificate from a collective signature
+// in the R || s || mask layout on digest by the given group.
+func New(cosigners *cosi.Cosigners, digest, sig []byte) (*QuorumCert, error) {
+	if len(sig) <= ed25519.SignatureSize {
+		return nil, errors.New("qc: collective signature carries no mask")
+	}
+	return &QuorumCert{
+		Digest:      append([]byte{}, digest...),
+		Signature:   append([]byte{}, sig[:ed25519.SignatureSize]...),
+		Mask:        append([]byte{}, sig[ed25519.SignatureSize:]...),
+		Fingerprint: cosigners.Fingerprint(),
+	}, nil
+}
+
+// TwoThirdsPolicy creates a cosi.Policy accepting a signature
+// only if its signers hold at least two thirds of the total voting power,
+// where powers[i] is the voting power of the i-th cosigner.
+// A nil powers slice gives every cosigner equal power.
+// The policy rejects every signature if the powers sum past 2^64-1.
+func TwoThirdsPolicy(powers []uint64) cosi.Policy {
+	return powerPolicy(powers)
+}
+
+type powerPolicy []uint64
+
+func (p powerPolicy) Check(cosigners *cosi.Cosigners) bool {
+	var signed, total, carry uint64
+	for i := 0; i < cosigners.CountTotal(); i++ {
+		power := uint64(1)
+		if p != nil {
+			if i >= len(p) {
+				return false
+			}
+			power = p[i]
+		}
+		if total, carry = bits.Add64(total, power, 0); carry != 0 {
+			return false
+		}
+		// signed <= total, so it cannot overflow once total has not
+		if cosigners.MaskBit(i) == cosi.Enabled {
+			signed += power
+		}
+	}
+	// signed/total >= 2/3, computed without overflow
+	sh, sl := bits.Mul64(signed, 3)
+	th, tl := bits.Mul64(total, 2)
+	return total > 0 && (sh > th || sh == th && sl >= tl)
+}
+
+// VerifyQC checks that qc carries a valid collective signature on its digest
+// by the given cosigner group, and that the signers hold
+// at least two thirds of the voting power given by powers,
+// as in TwoThirdsPolicy.
+// The cosigners object is not modified.
+func VerifyQC(cosigners *cosi.Cosigners, powers []uint64, qc *QuorumCert) error {
+	if !bytes.Equal(qc.Fingerprint, cosigners.Fingerprint()) {
+		return ErrWrongGroup
+	}
+	if powers != nil && len(powers) != cosigners.CountTotal() {
+		return errors.New("qc: one voting power per cosigner required")
+	}
+	var total, carry uint64
+	for _, power := range powers {
+		if total, carry = bits.Add64(total, power, 0); carry != 0 {
+			return errors.New("qc: total voting power overflows")
+		}
+	}
+	if qc.Mask == nil {
+		return ErrNoQuorum
+	}
+
+	policy := TwoThirdsPolicy(powers)
+	cos := cosigners.Clone()
+	cos.SetMask(qc.Mask)
+	if !policy.Check(cos) {
+		return ErrNoQuorum
+	}
+	cos.SetPolicy(policy)
+	if !cos.VerifyDetached(qc.Digest, qc.Signature, qc.Mask) {
+		return ErrBadSignature
+	}
+	return nil
+}
+
+type derQC struct {
+	Digest    []byte
+	Signature asn1.RawValue
+}
+
+// MarshalBinary encodes the certificate in DER,
+// as a SEQUENCE of the digest and the cosi DER CollectiveSignature
+// carrying the signature, mask and group fingerprint.
+func (qc *QuorumCert) MarshalBinary() ([]byte, error) {
+	sig, err := cosi.MarshalDER(append(append([]byte{}, qc.Signature...), qc.Mask...),
+		qc.Fingerprint)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(derQC{Digest: qc.Digest, Signature: asn1.RawValue{FullBytes: sig}})
+}
+
+// UnmarshalBinary decodes a certificate encoded by MarshalBinary.
+func (qc *QuorumCert) UnmarshalBinary(data []byte) error {
+	var d derQC
+	rest, err := asn1.Unmarshal(data, &d)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 0 {
+		return errors.New("qc: trailing data after certificate")
+	}
+	sig, fingerprint, err := cosi.ParseDER(d.Signature.FullBytes)
+	if err != nil {
+		return err
+	}
+	if fingerprint == nil || len(sig) == ed25519.SignatureSize {
+		return errors.New("qc: certificate lacks group fingerprint or mask")
+	}
+	*qc = QuorumCert{
+		Digest:      d.Digest,
+		Signature:   sig[:ed25519.SignatureSize],
+		Mask:        sig[ed25519.SignatureSize:],
+		Fingerprint: fingerprint,
+	}
+	return nil
+}
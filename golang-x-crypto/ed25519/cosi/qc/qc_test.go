@@ -0,0 +1,94 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package qc
+
+import (
+	"context"
+	"crypto/sha256"
+	"math"
+	"testing"
+
+	"test-server/golang-x-crypto/ed25519/cosi/cositest"
+)
+
+func TestQuorumCert(t *testing.T) {
+	nw, err := cositest.NewNetwork(6, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nw.Close()
+	nw.Leader.Threshold = 1
+
+	digest := sha256.Sum256([]byte("block 42"))
+	sign := func(disabled ...int) *QuorumCert {
+		res, err := nw.SignWithMask(context.Background(), digest[:],
+			cositest.Mask(6, disabled...))
+		if err != nil {
+			t.Fatal(err)
+		}
+		qc, err := New(nw.Cosigners(), digest[:], res.Signature)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return qc
+	}
+
+	cos := nw.Cosigners()
+	good := sign(0, 1) // 4 of 6
+	if err := VerifyQC(cos, nil, good); err != nil {
+		t.Errorf("valid certificate rejected: %v", err)
+	}
+	if cos.CountEnabled() != 6 {
+		t.Errorf("VerifyQC modified the caller's Cosigners")
+	}
+	if err := VerifyQC(cos, nil, sign(0, 1, 2)); err != ErrNoQuorum {
+		t.Errorf("certificate below two thirds: got %v", err)
+	}
+	// cosigners 0 and 1 hold most of the power
+	if err := VerifyQC(cos, []uint64{5, 5, 1, 1, 1, 1}, good); err != ErrNoQuorum {
+		t.Errorf("certificate below two thirds of power: got %v", err)
+	}
+	// near-max powers that still sum below 2^64
+	const sixth = math.MaxUint64 / 6
+	if err := VerifyQC(cos, []uint64{sixth, sixth, sixth, sixth, sixth, sixth}, good); err != nil {
+		t.Errorf("certificate with near-max powers rejected: %v", err)
+	}
+	// a total that wraps around must not let cosigners 2..5 reach a quorum
+	overflow := []uint64{math.MaxUint64 - 3, 4, 1, 1, 1, 1}
+	if err := VerifyQC(cos, overflow, good); err == nil {
+		t.Errorf("certificate accepted with overflowing voting power")
+	}
+	cos.SetMask(good.Mask)
+	if TwoThirdsPolicy(overflow).Check(cos) {
+		t.Errorf("policy accepted overflowing voting power")
+	}
+	cos.SetMask(nil)
+
+	data, err := good.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded QuorumCert
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyQC(cos, nil, &decoded); err != nil {
+		t.Errorf("decoded certificate rejected: %v", err)
+	}
+
+	decoded.Digest = []byte("other digest")
+	if err := VerifyQC(cos, nil, &decoded); err != ErrBadSignature {
+		t.Errorf("certificate on wrong digest: got %v", err)
+	}
+
+	other, err := cositest.NewNetwork(6, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close()
+	if err := VerifyQC(other.Cosigners(), nil, good); err != ErrWrongGroup {
+		t.Errorf("certificate for other group: got %v", err)
+	}
+}
@@ -0,0 +1,448 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cosi
+
+import (
+	"bytes"
+	cryptorand "crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strconv"
+
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/golang-x-crypto/ed25519/internal/edwards25519"
+)
+
+// SessionIDSize is the size, in bytes, of a LeaderSession identifier.
+const SessionIDSize = 16
+
+// Participant holds one cosigner's state across collective signing rounds,
+// wrapping the Commit and Cosign primitives so that
+// the one-time Secret never leaves the Participant.
+//
+// All randomness a Participant consumes is drawn from the reader
+// supplied to NewParticipant, so a Participant constructed
+// with a deterministic reader behaves fully deterministically.
+// This is intended for tests and fuzzing only:
+// production cosigners must use a cryptographically secure source,
+// since reusing commit randomness reveals the private key.
+type Participant struct {
+	rand       io.Reader
+	privateKey ed25519.PrivateKey
+	secret     *Secret
+	binding    *Binding // binding of the outstanding commit, if bound
+}
+
+// NewParticipant creates a Participant signing with privateKey.
+// Commit secrets are drawn from rand, or from crypto/rand if rand is nil.
+func NewParticipant(privateKey ed25519.PrivateKey, rand io.Reader) *Participant {
+	if rand == nil {
+		rand = cryptorand.Reader
+	}
+	return &Participant{rand: rand, privateKey: privateKey}
+}
+
+// PublicKey returns the participant's public key.
+func (p *Participant) PublicKey() ed25519.PublicKey {
+	return p.privateKey.Public().(ed25519.PublicKey)
+}
+
+// Commit produces a fresh commit for a new signing round,
+// discarding any commit previously produced but not yet used.
+func (p *Participant) Commit() (Commitment, error) {
+	commit, secret, err := Commit(p.rand)
+	if err != nil {
+		return nil, err
+	}
+	p.secret = secret
+	p.binding = nil
+	return commit, nil
+}
+
+// CommitBound produces a fresh commit for a bound signing round
+// (see Binding), discarding any commit previously produced but not yet used.
+// The commit can only be used by CosignBound with the same binding.
+func (p *Participant) CommitBound(binding Binding) (Commitment, error) {
+	if len(binding.SessionID) == 0 {
+		return nil, errors.New("cosi: bound commit without session ID")
+	}
+	commit, err := p.Commit()
+	if err != nil {
+		return nil, err
+	}
+	p.binding = &Binding{
+		SessionID: append([]byte{}, binding.SessionID...),
+		Leader:    append([]byte{}, binding.Leader...),
+	}
+	return commit, nil
+}
+
+// Cosign produces this participant's signature part
+// using the secret from the most recent call to Commit.
+// It returns an error if there is no outstanding commit
+// or the aggregate commit is malformed,
+// and ErrBoundMessage for a message that only a bound round may sign.
+func (p *Participant) Cosign(message []byte, aggregateK ed25519.PublicKey,
+	aggregateR Commitment) (SignaturePart, error) {
+
+	if p.binding != nil {
+		return nil, errors.New("cosi: outstanding commit is bound to a session")
+	}
+	if IsBoundMessage(message) {
+		return nil, ErrBoundMessage
+	}
+	return p.cosign(message, aggregateK, aggregateR)
+}
+
+// CosignBound produces this participant's signature part
+// for a bound round, signing binding.Message(message),
+// using the secret from the most recent call to CommitBound.
+// It returns an error if the binding differs from the one committed under,
+// so a leader cannot obtain a part for another session or leader.
+func (p *Participant) CosignBound(binding Binding, message []byte,
+	aggregateK ed25519.PublicKey, aggregateR Commitment) (SignaturePart, error) {
+
+	if p.binding == nil {
+		return nil, errors.New("cosi: outstanding commit is not bound")
+	}
+	if !p.binding.Equal(binding) {
+		return nil, errors.New("cosi: challenge for a different session or leader")
+	}
+	return p.cosign(binding.Message(message), aggregateK, aggregateR)
+}
+
+// Participant state.
+//
+// A cosigner that must restart between the commit and the challenge
+// of a round can carry its outstanding commit across the restart
+// with MarshalBinary and UnmarshalBinary. The state layout is:
+//
+//	magic       "cosi-participant-v1"
+//	public key  32 bytes
+//	secret      32 bytes, the reduced one-time scalar
+//	bound       1 byte, 1 if the commit is bound
+//	binding     if bound: session ID and leader,
+//	            each as a uvarint length followed by the bytes
+//	checksum    SHA-256 of everything above
+//
+// The state contains the commit secret, which reveals the private key
+// to anyone who also sees the resulting signature part,
+// and which must never be used for two signature parts.
+// It must be stored as carefully as the private key,
+// and restored at most once: callers should delete it
+// before using the restored Participant.
+
+const participantMagic = "cosi-participant-v1"
+
+// MarshalBinary implements encoding.BinaryMarshaler,
+// exporting the outstanding commit.
+// It fails if there is no outstanding commit.
+// The Participant keeps the commit; callers moving the commit elsewhere
+// must discard this Participant, so that the secret is used only once.
+func (p *Participant) MarshalBinary() ([]byte, error) {
+	if p.secret == nil || !p.secret.valid {
+		return nil, errors.New("cosi: no outstanding commit")
+	}
+	b := make([]byte, 0, len(participantMagic)+2*32+1+sha256.Size)
+	b = append(b, participantMagic...)
+	b = append(b, p.PublicKey()...)
+	b = append(b, p.secret.reduced[:]...)
+	if p.binding == nil {
+		b = append(b, 0)
+	} else {
+		b = append(b, 1)
+		b = binary.AppendUvarint(b, uint64(len(p.binding.SessionID)))
+		b = append(b, p.binding.SessionID...)
+		b = binary.AppendUvarint(b, uint64(len(p.binding.Leader)))
+		b = append(b, p.binding.Leader...)
+	}
+	sum := sha256.Sum256(b)
+	return append(b, sum[:]...), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler,
+// restoring an outstanding commit exported by MarshalBinary
+// into a Participant created by NewParticipant with the same private key.
+// The restored commit can be used by Cosign or CosignBound as usual.
+func (p *Participant) UnmarshalBinary(data []byte) error {
+	errState := errors.New("cosi: malformed Participant state")
+
+	if len(data) < len(participantMagic)+2*32+1+sha256.Size ||
+		string(data[:len(participantMagic)]) != participantMagic {
+		return errState
+	}
+	body, sum := data[:len(data)-sha256.Size], data[len(data)-sha256.Size:]
+	want := sha256.Sum256(body)
+	if subtle.ConstantTimeCompare(sum, want[:]) != 1 {
+		return errors.New("cosi: Participant state checksum mismatch")
+	}
+	b := body[len(participantMagic):]
+	if !bytes.Equal(b[:32], p.PublicKey()) {
+		return errors.New("cosi: Participant state is for a different key")
+	}
+	var secret Secret
+	copy(secret.reduced[:], b[32:64])
+	secret.valid = true
+	bound := b[64]
+	b = b[65:]
+
+	var binding *Binding
+	switch bound {
+	case 0:
+	case 1:
+		var sessionID, leader []byte
+		var ok bool
+		if sessionID, b, ok = readUvarintBytes(b); !ok {
+			return errState
+		}
+		if leader, b, ok = readUvarintBytes(b); !ok {
+			return errState
+		}
+		binding = &Binding{SessionID: sessionID, Leader: leader}
+	default:
+		return errState
+	}
+	if len(b) != 0 {
+		return errState
+	}
+	p.secret = &secret
+	p.binding = binding
+	return nil
+}
+
+func readUvarintBytes(b []byte) (v, rest []byte, ok bool) {
+	n, k := binary.Uvarint(b)
+	if k <= 0 || n > uint64(len(b)-k) {
+		return nil, nil, false
+	}
+	b = b[k:]
+	return append([]byte{}, b[:n]...), b[n:], true
+}
+
+func (p *Participant) cosign(message []byte, aggregateK ed25519.PublicKey,
+	aggregateR Commitment) (SignaturePart, error) {
+
+	if p.secret == nil || !p.secret.valid {
+		return nil, errors.New("cosi: no outstanding commit")
+	}
+	if len(aggregateR) != ed25519.PublicKeySize {
+		return nil, errors.New("cosi: bad aggregate commit length")
+	}
+	if len(aggregateK) != ed25519.PublicKeySize {
+		return nil, errors.New("cosi: bad aggregate public key length")
+	}
+	secret := p.secret
+	p.secret = nil
+	p.binding = nil
+	return Cosign(p.privateKey, secret, message, aggregateK, aggregateR), nil
+}
+
+// LeaderSession tracks the leader's state for one collective signing round
+// over a single message: the commits received from cosigners,
+// the resulting aggregate commit, and the signature parts
+// received in response.
+//
+// A LeaderSession takes over the participation mask
+// of the Cosigners object it is given,
+// so that object must not be used elsewhere while the session is active.
+type LeaderSession struct {
+	id      []byte
+	cos     *Cosigners
+	message []byte
+	binding *Binding
+	signed  []byte // message as signed by the cosigners
+
+	commits []Commitment
+	parts   []SignaturePart
+
+	aggK ed25519.PublicKey
+	aggR Commitment // nil until Challenge has been called
+}
+
+// NewLeaderSession starts a signing round for message
+// among the given cosigners.
+// The session identifier is drawn from rand,
+// or from crypto/rand if rand is nil.
+func NewLeaderSession(cosigners *Cosigners, message []byte,
+	rand io.Reader) (*LeaderSession, error) {
+
+	if rand == nil {
+		rand = cryptorand.Reader
+	}
+	id := make([]byte, SessionIDSize)
+	if _, err := io.ReadFull(rand, id); err != nil {
+		return nil, err
+	}
+	n := cosigners.CountTotal()
+	return &LeaderSession{
+		id:      id,
+		cos:     cosigners,
+		message: message,
+		signed:  message,
+		commits: make([]Commitment, n),
+		parts:   make([]SignaturePart, n),
+	}, nil
+}
+
+// NewBoundLeaderSession starts a bound signing round (see Binding)
+// for message among the given cosigners,
+// binding the random session identifier and the leader's identity.
+// The resulting signature verifies with VerifyBound and Binding.
+func NewBoundLeaderSession(cosigners *Cosigners, leader, message []byte,
+	rand io.Reader) (*LeaderSession, error) {
+
+	s, err := NewLeaderSession(cosigners, message, rand)
+	if err != nil {
+		return nil, err
+	}
+	s.binding = &Binding{SessionID: s.id, Leader: append([]byte{}, leader...)}
+	s.signed = s.binding.Message(message)
+	return s, nil
+}
+
+// ID returns the random identifier of this session.
+func (s *LeaderSession) ID() []byte {
+	return s.id
+}
+
+// Message returns the message being signed in this session.
+func (s *LeaderSession) Message() []byte {
+	return s.message
+}
+
+// Binding returns the binding of a bound session,
+// or nil if the session is not bound.
+func (s *LeaderSession) Binding() *Binding {
+	return s.binding
+}
+
+// Cosigners returns the Cosigners object driven by this session.
+func (s *LeaderSession) Cosigners() *Cosigners {
+	return s.cos
+}
+
+// AddCommit records the commit of the given cosigner.
+// Commits may only be added before Challenge is called.
+func (s *LeaderSession) AddCommit(signer int, commit Commitment) error {
+	if s.aggR != nil {
+		return errors.New("cosi: commit phase already closed")
+	}
+	if signer < 0 || signer >= len(s.commits) {
+		return errors.New("cosi: no such cosigner " + strconv.Itoa(signer))
+	}
+	if len(commit) != ed25519.PublicKeySize {
+		return errors.New("cosi: bad commit length from cosigner " + strconv.Itoa(signer))
+	}
+	var R edwards25519.ExtendedGroupElement
+	var commitBytes [32]byte
+	copy(commitBytes[:], commit)
+	if !R.FromBytes(&commitBytes) {
+		return errors.New("cosi: invalid commit from cosigner " + strconv.Itoa(signer))
+	}
+	s.commits[signer] = append(Commitment{}, commit...)
+	return nil
+}
+
+// Challenge closes the commit phase.
+// It enables exactly those cosigners that committed in the participation mask,
+// and returns the aggregate public key and aggregate commit
+// that must be sent to those cosigners for their Cosign operations.
+func (s *LeaderSession) Challenge() (ed25519.PublicKey, Commitment, error) {
+	if s.aggR != nil {
+		return s.aggK, s.aggR, nil
+	}
+
+	var aggR, indivR edwards25519.ExtendedGroupElement
+	var commitBytes [32]byte
+	aggR.Zero()
+	for i, c := range s.commits {
+		if c == nil {
+			s.cos.SetMaskBit(i, Disabled)
+			continue
+		}
+		s.cos.SetMaskBit(i, Enabled)
+		copy(commitBytes[:], c)
+		indivR.FromBytes(&commitBytes) // validated by AddCommit
+		aggR.Add(&aggR, &indivR)
+	}
+
+	var aggRBytes [32]byte
+	aggR.ToBytes(&aggRBytes)
+	s.aggK = s.cos.AggregatePublicKey()
+	s.aggR = aggRBytes[:]
+	return s.aggK, s.aggR, nil
+}
+
+// AddPart records and checks the signature part of the given cosigner.
+// It returns an error, identifying the cosigner, if the part is invalid,
+// in which case the round cannot complete with the current mask
+// and the leader should restart it without that cosigner.
+func (s *LeaderSession) AddPart(signer int, part SignaturePart) error {
+	if s.aggR == nil {
+		return errors.New("cosi: challenge not yet issued")
+	}
+	if signer < 0 || signer >= len(s.parts) || s.commits[signer] == nil {
+		return errors.New("cosi: cosigner " + strconv.Itoa(signer) + " did not commit")
+	}
+	if !s.cos.VerifyPart(s.signed, s.aggR, signer, s.commits[signer], part) {
+		return errors.New("cosi: invalid signature part from cosigner " + strconv.Itoa(signer))
+	}
+	s.parts[signer] = append(SignaturePart{}, part...)
+	return nil
+}
+
+// Committed returns the indices of the cosigners that have committed.
+func (s *LeaderSession) Committed() []int {
+	var idx []int
+	for i, c := range s.commits {
+		if c != nil {
+			idx = append(idx, i)
+		}
+	}
+	return idx
+}
+
+// Missing returns the indices of the cosigners that committed
+// but have not yet supplied a valid signature part.
+func (s *LeaderSession) Missing() []int {
+	var idx []int
+	for i, c := range s.commits {
+		if c != nil && s.parts[i] == nil {
+			idx = append(idx, i)
+		}
+	}
+	return idx
+}
+
+// Signature combines the collected signature parts
+// into a collective signature in the R || s || mask layout
+// accepted by Verify.
+// Every cosigner that committed must have supplied a valid part.
+func (s *LeaderSession) Signature() ([]byte, error) {
+	if s.aggR == nil {
+		return nil, errors.New("cosi: challenge not yet issued")
+	}
+	if missing := s.Missing(); len(missing) > 0 {
+		return nil, errors.New("cosi: missing signature part from cosigner " +
+			strconv.Itoa(missing[0]))
+	}
+
+	var aggS, indivS [32]byte
+	for _, part := range s.parts {
+		if part == nil {
+			continue
+		}
+		copy(indivS[:], part)
+		edwards25519.ScMulAdd(&aggS, &aggS, &scOne, &indivS)
+	}
+
+	sig := make([]byte, ed25519.SignatureSize, ed25519.SignatureSize+s.cos.MaskLen())
+	copy(sig[:32], s.aggR)
+	copy(sig[32:], aggS[:])
+	return append(sig, s.cos.Mask()...), nil
+}
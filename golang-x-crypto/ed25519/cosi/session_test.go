@@ -0,0 +1,296 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cosi
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+// seededReader is a deterministic byte stream derived from a seed.
+type seededReader struct {
+	seed  [32]byte
+	ctr   uint64
+	avail []byte
+}
+
+func newSeededReader(seed byte) *seededReader {
+	return &seededReader{seed: [32]byte{seed}}
+}
+
+func (r *seededReader) Read(buf []byte) (int, error) {
+	n := 0
+	for n < len(buf) {
+		if len(r.avail) == 0 {
+			var block [40]byte
+			copy(block[:], r.seed[:])
+			binary.LittleEndian.PutUint64(block[32:], r.ctr)
+			r.ctr++
+			sum := sha256.Sum256(block[:])
+			r.avail = sum[:]
+		}
+		c := copy(buf[n:], r.avail)
+		r.avail = r.avail[c:]
+		n += c
+	}
+	return n, nil
+}
+
+// runSession runs a full signing round with the given participants,
+// skipping those listed in absent.
+func runSession(t *testing.T, cos *Cosigners, parts []*Participant,
+	seed byte, absent map[int]bool) []byte {
+
+	s, err := NewLeaderSession(cos, rightMessage, newSeededReader(seed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, p := range parts {
+		if absent[i] {
+			continue
+		}
+		c, err := p.Commit()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.AddCommit(i, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	aggK, aggR, err := s.Challenge()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, i := range s.Committed() {
+		part, err := parts[i].Cosign(rightMessage, aggK, aggR)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.AddPart(i, part); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sig, err := s.Signature()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sig
+}
+
+func TestLeaderSessionDeterministic(t *testing.T) {
+	n := 5
+	genKeys(n)
+
+	newParts := func() []*Participant {
+		parts := make([]*Participant, n)
+		for i := range parts {
+			parts[i] = NewParticipant(priKeys[i], newSeededReader(byte(100+i)))
+		}
+		return parts
+	}
+
+	sig1 := runSession(t, NewCosigners(pubKeys[:n], nil), newParts(), 1, nil)
+	sig2 := runSession(t, NewCosigners(pubKeys[:n], nil), newParts(), 1, nil)
+	if !bytes.Equal(sig1, sig2) {
+		t.Errorf("seeded rounds produced different signatures")
+	}
+	if !NewCosigners(pubKeys[:n], nil).Verify(rightMessage, sig1) {
+		t.Errorf("valid signature rejected")
+	}
+
+	// A round with an absent cosigner carries the corresponding mask bit.
+	cos := NewCosigners(pubKeys[:n], nil)
+	sig := runSession(t, cos, newParts(), 2, map[int]bool{3: true})
+	verifier := NewCosigners(pubKeys[:n], nil)
+	verifier.SetPolicy(ThresholdPolicy(n - 1))
+	if !verifier.Verify(rightMessage, sig) {
+		t.Errorf("valid partial signature rejected")
+	}
+	if verifier.MaskBit(3) != Disabled {
+		t.Errorf("absent cosigner not reflected in mask")
+	}
+}
+
+func TestLeaderSessionBadPart(t *testing.T) {
+	n := 3
+	genKeys(n)
+	cos := NewCosigners(pubKeys[:n], nil)
+	s, _ := NewLeaderSession(cos, rightMessage, nil)
+	parts := make([]*Participant, n)
+	for i := range parts {
+		parts[i] = NewParticipant(priKeys[i], nil)
+		c, _ := parts[i].Commit()
+		s.AddCommit(i, c)
+	}
+	aggK, aggR, _ := s.Challenge()
+	part, _ := parts[1].Cosign(wrongMessage, aggK, aggR)
+	if err := s.AddPart(1, part); err == nil {
+		t.Errorf("signature part over the wrong message accepted")
+	}
+	if _, err := parts[1].Cosign(rightMessage, aggK, aggR); err == nil {
+		t.Errorf("participant cosigned twice with one commit")
+	}
+	if _, err := s.Signature(); err == nil {
+		t.Errorf("signature produced with missing parts")
+	}
+}
+
+func TestLeaderSessionBound(t *testing.T) {
+	n := 3
+	genKeys(n)
+	leader := []byte("leader")
+	cos := NewCosigners(pubKeys[:n], nil)
+	s, err := NewBoundLeaderSession(cos, leader, rightMessage, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	binding := *s.Binding()
+	parts := make([]*Participant, n)
+	for i := range parts {
+		parts[i] = NewParticipant(priKeys[i], nil)
+		c, err := parts[i].CommitBound(binding)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.AddCommit(i, c)
+	}
+	aggK, aggR, _ := s.Challenge()
+
+	if _, err := parts[0].Cosign(rightMessage, aggK, aggR); err == nil {
+		t.Errorf("bound commit used for an unbound part")
+	}
+	other := Binding{SessionID: []byte("another session"), Leader: leader}
+	if _, err := parts[0].CosignBound(other, rightMessage, aggK, aggR); err == nil {
+		t.Errorf("participant cosigned for a different session")
+	}
+	for i, p := range parts {
+		part, err := p.CosignBound(binding, rightMessage, aggK, aggR)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.AddPart(i, part); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sig, err := s.Signature()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyBound(pubKeys[:n], nil, binding, rightMessage, sig) {
+		t.Errorf("valid bound signature rejected")
+	}
+	if VerifyBound(pubKeys[:n], nil, other, rightMessage, sig) ||
+		Verify(pubKeys[:n], nil, rightMessage, sig) {
+		t.Errorf("bound signature verified under another binding")
+	}
+}
+
+// An unbound round must not yield a signature that VerifyBound accepts,
+// or a leader could forge one bound to another leader's session.
+func TestUnboundRoundOverBoundMessage(t *testing.T) {
+	n := 3
+	genKeys(n)
+	victim := Binding{SessionID: []byte("victim session"), Leader: []byte("leader-2")}
+	forged := victim.Message(rightMessage)
+
+	cos := NewCosigners(pubKeys[:n], nil)
+	s, err := NewLeaderSession(cos, forged, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := make([]*Participant, n)
+	for i := range parts {
+		parts[i] = NewParticipant(priKeys[i], nil)
+		c, err := parts[i].Commit()
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.AddCommit(i, c)
+	}
+	aggK, aggR, _ := s.Challenge()
+	for i, p := range parts {
+		part, err := p.Cosign(forged, aggK, aggR)
+		if !errors.Is(err, ErrBoundMessage) {
+			t.Fatalf("participant %d: unbound part over a bound message: %v", i, err)
+		}
+		if part != nil {
+			s.AddPart(i, part)
+		}
+	}
+	if sig, err := s.Signature(); err == nil && VerifyBound(pubKeys[:n], nil, victim, rightMessage, sig) {
+		t.Errorf("unbound round produced a signature bound to another leader's session")
+	}
+
+	// The same participants still sign ordinary messages unbound.
+	if !cos.Verify(rightMessage, runSession(t, cos, parts, 1, nil)) {
+		t.Errorf("unbound signature rejected")
+	}
+}
+
+func TestParticipantState(t *testing.T) {
+	n := 3
+	genKeys(n)
+	leader := []byte("leader")
+	cos := NewCosigners(pubKeys[:n], nil)
+	s, err := NewBoundLeaderSession(cos, leader, rightMessage, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	binding := *s.Binding()
+	parts := make([]*Participant, n)
+	for i := range parts {
+		parts[i] = NewParticipant(priKeys[i], nil)
+		if _, err := parts[i].MarshalBinary(); err == nil {
+			t.Errorf("state exported without an outstanding commit")
+		}
+		c, err := parts[i].CommitBound(binding)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.AddCommit(i, c)
+	}
+
+	// Participant 1 restarts between commit and challenge.
+	state, err := parts[1].MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := NewParticipant(priKeys[2], nil).UnmarshalBinary(state); err == nil {
+		t.Errorf("state restored under a different key")
+	}
+	bad := bytes.Clone(state)
+	bad[len(participantMagic)+40] ^= 1
+	if err := NewParticipant(priKeys[1], nil).UnmarshalBinary(bad); err == nil {
+		t.Errorf("corrupted state restored")
+	}
+	parts[1] = NewParticipant(priKeys[1], nil)
+	if err := parts[1].UnmarshalBinary(state); err != nil {
+		t.Fatal(err)
+	}
+
+	aggK, aggR, _ := s.Challenge()
+	for i, p := range parts {
+		part, err := p.CosignBound(binding, rightMessage, aggK, aggR)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.AddPart(i, part); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sig, err := s.Signature()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyBound(pubKeys[:n], nil, binding, rightMessage, sig) {
+		t.Errorf("signature with a restored commit rejected")
+	}
+	if _, err := parts[1].CosignBound(binding, rightMessage, aggK, aggR); err == nil {
+		t.Errorf("restored commit used twice")
+	}
+}
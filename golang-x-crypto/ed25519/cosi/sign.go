@@ -37,6 +37,8 @@ type Secret struct {
 // to be used in the collective signing of a single message.
 // Producing this commit requires fresh cryptographically random bits,
 // which are taken from rand, or from a default source if rand is nil.
+// A cosigner running on hardware whose generator it does not trust
+// can pass an entropy.Provider mixing it with other sources.
 //
 // On success, Commit returns the commit as a byte-slice
 // to be sent to the leader for aggregation via AggregateCommit,
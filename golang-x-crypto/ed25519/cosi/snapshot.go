@@ -0,0 +1,128 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cosi
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+
+	"test-server/golang-x-crypto/ed25519/internal/edwards25519"
+)
+
+// Binary snapshots of Cosigners objects.
+//
+// Decompressing tens of thousands of public keys takes noticeable time,
+// so services can persist a Cosigners object with MarshalBinary
+// and restore it with UnmarshalBinary,
+// which reads back the decompressed group elements
+// and cached aggregate key directly.
+// The snapshot layout is:
+//
+//	magic       "cosi-snapshot-v1"
+//	count       uint32, big-endian
+//	format      1 byte (MaskFormat)
+//	mask        MaskLen bytes, dense
+//	aggregate   1 extended group element
+//	keys        count extended group elements
+//	checksum    SHA-256 of everything above
+//
+// where each extended group element is encoded as its
+// X, Y, Z and T field elements,
+// each as the ten little-endian int32 limbs of ref10,
+// whichever field arithmetic backend is in use.
+// The policy is not included, and must be set again after restoring.
+//
+// The checksum only detects accidental corruption.
+// UnmarshalBinary does not re-validate the group elements,
+// so snapshots must be kept in storage trusted as much as
+// the public key list itself.
+
+const snapshotMagic = "cosi-snapshot-v1"
+
+const elementSize = 4 * 10 * 4
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (cos *Cosigners) MarshalBinary() ([]byte, error) {
+	n := len(cos.keys)
+	size := len(snapshotMagic) + 4 + 1 + len(cos.mask) +
+		(n+1)*elementSize + sha256.Size
+	b := make([]byte, 0, size)
+	b = append(b, snapshotMagic...)
+	b = binary.BigEndian.AppendUint32(b, uint32(n))
+	b = append(b, byte(cos.format))
+	b = append(b, cos.mask...)
+	b = appendElement(b, &cos.aggr)
+	for i := range cos.keys {
+		b = appendElement(b, &cos.keys[i])
+	}
+	sum := sha256.Sum256(b)
+	return append(b, sum[:]...), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler,
+// restoring a Cosigners object from a snapshot made by MarshalBinary.
+// The restored object has the default policy.
+func (cos *Cosigners) UnmarshalBinary(data []byte) error {
+	errSnapshot := errors.New("cosi: malformed Cosigners snapshot")
+
+	if len(data) < len(snapshotMagic)+4+1+elementSize+sha256.Size ||
+		string(data[:len(snapshotMagic)]) != snapshotMagic {
+		return errSnapshot
+	}
+	body, sum := data[:len(data)-sha256.Size], data[len(data)-sha256.Size:]
+	want := sha256.Sum256(body)
+	if subtle.ConstantTimeCompare(sum, want[:]) != 1 {
+		return errors.New("cosi: Cosigners snapshot checksum mismatch")
+	}
+
+	b := body[len(snapshotMagic):]
+	n := int(binary.BigEndian.Uint32(b))
+	format := MaskFormat(b[4])
+	b = b[5:]
+	maskLen := (n + 7) >> 3
+	if format != DenseMask && format != SparseMask ||
+		uint64(len(b)) != uint64(maskLen)+uint64(n+1)*elementSize {
+		return errSnapshot
+	}
+
+	c := Cosigners{
+		keys:   make([]edwards25519.ExtendedGroupElement, n),
+		mask:   append([]byte{}, b[:maskLen]...),
+		policy: fullPolicy{},
+		format: format,
+	}
+	b = b[maskLen:]
+	b = readElement(&c.aggr, b)
+	for i := range c.keys {
+		b = readElement(&c.keys[i], b)
+	}
+	*cos = c
+	return nil
+}
+
+func appendElement(b []byte, p *edwards25519.ExtendedGroupElement) []byte {
+	var limbs [10]int32
+	for _, fe := range []*edwards25519.FieldElement{&p.X, &p.Y, &p.Z, &p.T} {
+		edwards25519.FeToLimbs10(&limbs, fe)
+		for _, limb := range limbs {
+			b = binary.LittleEndian.AppendUint32(b, uint32(limb))
+		}
+	}
+	return b
+}
+
+func readElement(p *edwards25519.ExtendedGroupElement, b []byte) []byte {
+	var limbs [10]int32
+	for _, fe := range []*edwards25519.FieldElement{&p.X, &p.Y, &p.Z, &p.T} {
+		for i := range limbs {
+			limbs[i] = int32(binary.LittleEndian.Uint32(b))
+			b = b[4:]
+		}
+		edwards25519.FeFromLimbs10(fe, &limbs)
+	}
+	return b
+}
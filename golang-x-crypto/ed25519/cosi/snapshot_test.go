@@ -0,0 +1,49 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cosi
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSnapshot(t *testing.T) {
+	n := 11
+	genKeys(n)
+	parts := make([]*Participant, n)
+	for i := range parts {
+		parts[i] = NewParticipant(priKeys[i], nil)
+	}
+	sig := runSession(t, NewCosigners(pubKeys[:n], nil), parts, 9, map[int]bool{0: true})
+
+	cos := NewCosigners(pubKeys[:n], nil)
+	cos.SetMaskBit(7, Disabled)
+	snap, err := cos.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var restored Cosigners
+	if err := restored.UnmarshalBinary(snap); err != nil {
+		t.Fatal(err)
+	}
+	if restored.CountTotal() != n || restored.MaskBit(7) != Disabled ||
+		!bytes.Equal(restored.AggregatePublicKey(), cos.AggregatePublicKey()) ||
+		!bytes.Equal(restored.Fingerprint(), cos.Fingerprint()) {
+		t.Errorf("restored Cosigners differs from original")
+	}
+	restored.SetPolicy(ThresholdPolicy(n - 1))
+	if !restored.Verify(rightMessage, sig) {
+		t.Errorf("restored Cosigners rejects valid signature")
+	}
+
+	snap[len(snap)/2] ^= 1
+	if err := restored.UnmarshalBinary(snap); err == nil {
+		t.Errorf("corrupted snapshot accepted")
+	}
+	if err := restored.UnmarshalBinary(snap[:20]); err == nil {
+		t.Errorf("truncated snapshot accepted")
+	}
+}
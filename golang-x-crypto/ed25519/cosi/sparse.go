@@ -0,0 +1,101 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cosi
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+// MaskFormat selects the external encoding of participation masks
+// accepted by SetMask and Verify and produced by Mask.
+type MaskFormat int
+
+const (
+	// DenseMask is the default byte-packed bit-vector format
+	// described in SetMask, of length MaskLen.
+	DenseMask MaskFormat = iota
+
+	// SparseMask lists the indices of the *enabled* cosigners,
+	// for very large cosigner registries
+	// in which only a small committee signs each message.
+	// The encoding is a uvarint count of enabled cosigners
+	// followed by one uvarint per enabled cosigner in ascending order,
+	// the first giving its index and each subsequent one
+	// the number of cosigners skipped since the previous enabled one.
+	SparseMask
+)
+
+// SetMaskFormat selects the encoding of participation masks
+// used by SetMask, Mask, and Verify,
+// and hence the layout of the mask in collective signatures
+// produced by a LeaderSession over this Cosigners object.
+// Signers and verifiers must agree on the format.
+// The initial mask passed to NewCosigners is always dense,
+// as are the masks handled by MergeSignatures and the kyber conversions.
+func (cos *Cosigners) SetMaskFormat(format MaskFormat) {
+	cos.format = format
+}
+
+// MaskFormat returns the mask encoding currently in use.
+func (cos *Cosigners) MaskFormat() MaskFormat {
+	return cos.format
+}
+
+var errSparse = errors.New("cosi: malformed sparse mask")
+
+// denseToSparse encodes a dense disable-mask as a sparse index list.
+func (cos *Cosigners) denseToSparse(mask []byte) []byte {
+	var idx []int
+	for i := range cos.keys {
+		if mask[i>>3]&(1<<uint(i&7)) == 0 {
+			idx = append(idx, i)
+		}
+	}
+	out := binary.AppendUvarint(nil, uint64(len(idx)))
+	prev := -1
+	for _, i := range idx {
+		out = binary.AppendUvarint(out, uint64(i-prev-1))
+		prev = i
+	}
+	return out
+}
+
+// sparseToDense decodes a sparse index list into a dense disable-mask,
+// rejecting out-of-range indices and non-canonical encodings.
+func (cos *Cosigners) sparseToDense(sparse []byte) ([]byte, error) {
+	dense := cos.allDisabled()
+
+	count, n := binary.Uvarint(sparse)
+	if n <= 0 || count > uint64(len(cos.keys)) {
+		return nil, errSparse
+	}
+	rest := sparse[n:]
+	next := uint64(0)
+	for ; count > 0; count-- {
+		gap, n := binary.Uvarint(rest)
+		if n <= 0 || gap >= uint64(len(cos.keys))-next {
+			return nil, errSparse
+		}
+		rest = rest[n:]
+		i := next + gap
+		dense[i>>3] &^= 1 << uint(i&7)
+		next = i + 1
+	}
+	if len(rest) != 0 || !bytes.Equal(cos.denseToSparse(dense), sparse) {
+		return nil, errSparse
+	}
+	return dense, nil
+}
+
+// allDisabled returns a dense mask disabling every cosigner.
+func (cos *Cosigners) allDisabled() []byte {
+	mask := make([]byte, cos.MaskLen())
+	for i := range cos.keys {
+		mask[i>>3] |= 1 << uint(i&7)
+	}
+	return mask
+}
@@ -0,0 +1,101 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cosi
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSparseMask(t *testing.T) {
+	n := 40
+	genKeys(n)
+	parts := make([]*Participant, n)
+	absent := make(map[int]bool)
+	for i := range parts {
+		parts[i] = NewParticipant(priKeys[i], nil)
+		if i != 3 && i != 17 && i != 39 {
+			absent[i] = true
+		}
+	}
+
+	cos := NewCosigners(pubKeys[:n], nil)
+	cos.SetMaskFormat(SparseMask)
+	sig := runSession(t, cos, parts, 8, absent)
+	if want := []byte{3, 3, 13, 21}; !bytes.Equal(sig[64:], want) {
+		t.Errorf("sparse mask %x, want %x", sig[64:], want)
+	}
+
+	verifier := NewCosigners(pubKeys[:n], nil)
+	verifier.SetMaskFormat(SparseMask)
+	verifier.SetPolicy(ThresholdPolicy(3))
+	if !verifier.Verify(rightMessage, sig) {
+		t.Errorf("valid sparse signature rejected")
+	}
+	if verifier.CountEnabled() != 3 || verifier.MaskBit(17) != Enabled {
+		t.Errorf("sparse mask not applied")
+	}
+	if !verifier.VerifyDetached(rightMessage, sig[:64], sig[64:]) {
+		t.Errorf("valid detached sparse signature rejected")
+	}
+	if verifier.Verify(wrongMessage, sig) {
+		t.Errorf("sparse signature on different message accepted")
+	}
+
+	// The same signature verifies in dense form.
+	dense := NewCosigners(pubKeys[:n], nil)
+	dense.SetMask(dense.allDisabled())
+	dense.SetPolicy(ThresholdPolicy(3))
+	for _, i := range []int{3, 17, 39} {
+		dense.SetMaskBit(i, Enabled)
+	}
+	if !dense.Verify(rightMessage, append(sig[:64:64], dense.Mask()...)) {
+		t.Errorf("dense re-encoding of sparse signature rejected")
+	}
+
+	verifier.SetMaskFormat(SparseMask)
+	for _, bad := range [][]byte{
+		{},                // no count
+		{3, 3, 13},        // truncated
+		{3, 3, 13, 21, 0}, // trailing data
+		{3, 3, 13, 22},    // index out of range
+		{1, 0x83, 0x00},   // non-minimal varint
+	} {
+		if verifier.Verify(rightMessage, append(sig[:64:64], bad...)) {
+			t.Errorf("malformed sparse mask %x accepted", bad)
+		}
+	}
+}
+
+func TestSparseMaskEmpty(t *testing.T) {
+	n := 10
+	genKeys(n)
+	cos := NewCosigners(pubKeys[:n], nil)
+	cos.SetMaskFormat(SparseMask)
+
+	for _, mask := range [][]byte{nil, {}} {
+		cos.SetMask(cos.allDisabled()[:1]) // malformed: disables everyone
+		if cos.CountEnabled() != 0 {
+			t.Fatalf("malformed sparse mask enabled %d cosigners", cos.CountEnabled())
+		}
+		cos.SetMask(mask)
+		if cos.CountEnabled() != n {
+			t.Errorf("SetMask(%#v) enabled %d of %d cosigners", mask, cos.CountEnabled(), n)
+		}
+	}
+
+	parts := make([]*Participant, n)
+	for i := range parts {
+		parts[i] = NewParticipant(priKeys[i], nil)
+	}
+	sig := runSession(t, cos, parts, 8, nil)
+	verifier := NewCosigners(pubKeys[:n], nil)
+	verifier.SetMaskFormat(SparseMask)
+	for _, mask := range [][]byte{nil, {}} {
+		if !verifier.VerifyDetached(rightMessage, sig[:64], mask) {
+			t.Errorf("VerifyDetached with mask %#v rejected a full signature", mask)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package timestamp implements a collective timestamping service.
+//
+// Clients submit hashes of their documents to a Server,
+// which batches all hashes received during each interval
+// into a Merkle tree (see package merkle)
+// and has the cosigner group collectively sign the tree root
+// together with the batch time.
+// Each client receives a Receipt containing the signature
+// and an inclusion proof for its own hash,
+// which anyone holding the group's public keys can check with Verify.
+package timestamp
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"sync"
+	"time"
+
+	"test-server/golang-x-crypto/ed25519/cosi"
+	"test-server/golang-x-crypto/ed25519/cosi/merkle"
+	"test-server/golang-x-crypto/ed25519/cosi/protocol"
+)
+
+// DefaultInterval is the batching interval used by Run
+// when Server.Interval is zero.
+const DefaultInterval = time.Second
+
+// MaxDigestSize bounds the size of submitted hashes.
+const MaxDigestSize = 64
+
+const timestampDomain = "cosi-timestamp-v1"
+
+// Signer runs collective signing rounds;
+// it is satisfied by *protocol.Leader.
+type Signer interface {
+	Sign(ctx context.Context, message []byte) (*protocol.Result, error)
+}
+
+// Receipt proves that a digest was collectively timestamped.
+type Receipt struct {
+	Digest    []byte        // the submitted hash
+	Time      time.Time     // time at which the batch was signed
+	Root      []byte        // Merkle root of the batch
+	Signature []byte        // collective signature on Message(Time, Root)
+	Proof     *merkle.Proof // inclusion proof of Digest under Root
+}
+
+// Message returns the message collectively signed for a batch
+// with the given time and Merkle root.
+func Message(t time.Time, root []byte) []byte {
+	b := make([]byte, 0, len(timestampDomain)+8+len(root))
+	b = append(b, timestampDomain...)
+	b = binary.BigEndian.AppendUint64(b, uint64(t.UnixNano()))
+	return append(b, root...)
+}
+
+// Verify checks a receipt against a cosigner group,
+// using the group's current policy.
+func Verify(cosigners *cosi.Cosigners, r *Receipt) bool {
+	if r.Proof == nil || !merkle.VerifyProof(r.Root, r.Digest, r.Proof) {
+		return false
+	}
+	return cosigners.Verify(Message(r.Time, r.Root), r.Signature)
+}
+
+type submission struct {
+	digest []byte
+	done   chan result
+}
+
+type result struct {
+	receipt *Receipt
+	err     error
+}
+
+// Server batches submitted digests and timestamps each batch.
+// It is safe for concurrent use.
+type Server struct {
+	// Interval is the batching interval used by Run.
+	// If zero, DefaultInterval is used.
+	Interval time.Duration
+
+	signer Signer
+
+	mu      sync.Mutex
+	pending []*submission
+}
+
+// NewServer creates a timestamping server signing through signer.
+func NewServer(signer Signer) *Server {
+	return &Server{signer: signer}
+}
+
+// Submit adds a digest to the current batch
+// and waits until the batch has been signed,
+// returning the digest's receipt.
+func (s *Server) Submit(ctx context.Context, digest []byte) (*Receipt, error) {
+	if len(digest) == 0 || len(digest) > MaxDigestSize {
+		return nil, errors.New("timestamp: bad digest length")
+	}
+	sub := &submission{
+		digest: append([]byte{}, digest...),
+		done:   make(chan result, 1),
+	}
+	s.mu.Lock()
+	s.pending = append(s.pending, sub)
+	s.mu.Unlock()
+
+	select {
+	case r := <-sub.done:
+		return r.receipt, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Pending returns the number of digests waiting for the next batch.
+func (s *Server) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.pending)
+}
+
+// Flush signs the current batch, if any,
+// and delivers the receipts to the waiting submitters.
+// If signing fails, every submitter in the batch receives the error.
+func (s *Server) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	batch := s.pending
+	s.pending = nil
+	s.mu.Unlock()
+	if len(batch) == 0 {
+		return nil
+	}
+
+	receipts, err := s.sign(ctx, batch)
+	for i, sub := range batch {
+		if err != nil {
+			sub.done <- result{err: err}
+		} else {
+			sub.done <- result{receipt: receipts[i]}
+		}
+	}
+	return err
+}
+
+func (s *Server) sign(ctx context.Context, batch []*submission) ([]*Receipt, error) {
+	leaves := make([][]byte, len(batch))
+	for i, sub := range batch {
+		leaves[i] = sub.digest
+	}
+	tree, err := merkle.NewTree(leaves)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	root := tree.Root()
+	res, err := s.signer.Sign(ctx, Message(now, root))
+	if err != nil {
+		return nil, err
+	}
+
+	receipts := make([]*Receipt, len(batch))
+	for i, sub := range batch {
+		proof, err := tree.Proof(i)
+		if err != nil {
+			return nil, err
+		}
+		receipts[i] = &Receipt{
+			Digest:    sub.digest,
+			Time:      now,
+			Root:      root,
+			Signature: res.Signature,
+			Proof:     proof,
+		}
+	}
+	return receipts, nil
+}
+
+// Run flushes a batch every Interval until ctx is done,
+// then returns ctx.Err().
+// Signing failures are reported to the affected submitters only.
+func (s *Server) Run(ctx context.Context) error {
+	interval := s.Interval
+	if interval == 0 {
+		interval = DefaultInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			s.Flush(ctx)
+		}
+	}
+}
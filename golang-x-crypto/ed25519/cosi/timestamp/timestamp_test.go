@@ -0,0 +1,92 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package timestamp
+
+import (
+	"context"
+	"crypto/sha256"
+	"sync"
+	"testing"
+	"time"
+
+	"test-server/golang-x-crypto/ed25519/cosi/cositest"
+)
+
+func TestServer(t *testing.T) {
+	nw, err := cositest.NewNetwork(4, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nw.Close()
+
+	srv := NewServer(nw.Leader)
+	srv.Interval = 10 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go srv.Run(ctx)
+
+	receipts := make([]*Receipt, 5)
+	var wg sync.WaitGroup
+	for i := range receipts {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			digest := sha256.Sum256([]byte{byte(i)})
+			r, err := srv.Submit(ctx, digest[:])
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			receipts[i] = r
+		}(i)
+	}
+	wg.Wait()
+
+	cos := nw.Cosigners()
+	for i, r := range receipts {
+		if r == nil {
+			continue
+		}
+		if !Verify(cos, r) {
+			t.Errorf("receipt %d rejected", i)
+		}
+		forged := *r
+		forged.Time = r.Time.Add(time.Second)
+		if Verify(cos, &forged) {
+			t.Errorf("receipt %d with altered time accepted", i)
+		}
+		forged = *r
+		forged.Digest = []byte("other")
+		if Verify(cos, &forged) {
+			t.Errorf("receipt %d with altered digest accepted", i)
+		}
+	}
+}
+
+func TestServerSignFailure(t *testing.T) {
+	nw, err := cositest.NewNetwork(3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nw.Close()
+	nw.SetOnline(1, false)
+
+	srv := NewServer(nw.Leader)
+	ctx := context.Background()
+	done := make(chan error, 1)
+	go func() {
+		_, err := srv.Submit(ctx, []byte("digest"))
+		done <- err
+	}()
+	for srv.Pending() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if err := srv.Flush(ctx); err == nil {
+		t.Errorf("flush succeeded without quorum")
+	}
+	if err := <-done; err == nil {
+		t.Errorf("submitter not told of failure")
+	}
+}
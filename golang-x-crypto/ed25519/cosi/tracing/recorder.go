@@ -0,0 +1,98 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tracing
+
+import (
+	"context"
+	cryptorand "crypto/rand"
+	"sync"
+	"time"
+)
+
+// RecordedSpan is a finished span captured by a Recorder.
+type RecordedSpan struct {
+	Name       string
+	Context    SpanContext
+	Parent     SpanContext // zero for a root span
+	Attributes []Attribute
+	Errors     []error
+	Start, End time.Time
+}
+
+// Recorder is a Tracer that keeps every finished span in memory.
+// It is safe for concurrent use.
+type Recorder struct {
+	mu    sync.Mutex
+	spans []RecordedSpan
+}
+
+// NewRecorder creates an empty Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Spans returns the spans finished so far, in order of completion.
+func (r *Recorder) Spans() []RecordedSpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]RecordedSpan{}, r.spans...)
+}
+
+// Start implements Tracer.
+func (r *Recorder) Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	s := &recordedSpan{rec: r}
+	s.data.Name = name
+	s.data.Start = time.Now()
+	s.data.Attributes = append([]Attribute{}, attrs...)
+	if parent, ok := ParentFromContext(ctx); ok {
+		s.data.Parent = parent
+		s.data.Context.TraceID = parent.TraceID
+		s.data.Context.Sampled = parent.Sampled
+	} else {
+		cryptorand.Read(s.data.Context.TraceID[:])
+		s.data.Context.Sampled = true
+	}
+	cryptorand.Read(s.data.Context.SpanID[:])
+	return ContextWithSpan(ctx, s), s
+}
+
+type recordedSpan struct {
+	rec  *Recorder
+	mu   sync.Mutex
+	data RecordedSpan
+	done bool
+}
+
+func (s *recordedSpan) SpanContext() SpanContext {
+	return s.data.Context // immutable after Start
+}
+
+func (s *recordedSpan) SetAttributes(attrs ...Attribute) {
+	s.mu.Lock()
+	s.data.Attributes = append(s.data.Attributes, attrs...)
+	s.mu.Unlock()
+}
+
+func (s *recordedSpan) RecordError(err error) {
+	s.mu.Lock()
+	s.data.Errors = append(s.data.Errors, err)
+	s.mu.Unlock()
+}
+
+func (s *recordedSpan) End() {
+	s.mu.Lock()
+	if s.done {
+		s.mu.Unlock()
+		return
+	}
+	s.done = true
+	s.data.End = time.Now()
+	data := s.data
+	s.mu.Unlock()
+
+	s.rec.mu.Lock()
+	s.rec.spans = append(s.rec.spans, data)
+	s.rec.mu.Unlock()
+}
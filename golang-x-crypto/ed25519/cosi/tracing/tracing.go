@@ -0,0 +1,180 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package tracing defines the minimal distributed-tracing interface
+// through which the collective signing protocol reports
+// a trace for each signing round,
+// and the W3C Trace Context encoding used to propagate it to cosigners.
+//
+// protocol.Leader produces a span for each round,
+// a child span for each protocol phase,
+// and a grandchild span for each RPC to a cosigner,
+// whose context travels to the cosigner in the request's
+// TraceContext field as a W3C traceparent header value.
+// protocol.Service continues the trace on the cosigner side.
+//
+// The interface is deliberately small so that it can be adapted
+// to OpenTelemetry or any other tracing system in a few lines:
+// an adapter's Start calls the underlying tracer's Start
+// and wraps the resulting span.
+// Recorder is a self-contained implementation for tests and debugging.
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"strconv"
+)
+
+// Attribute is a key-value annotation of a span.
+type Attribute struct {
+	Key   string
+	Value string
+}
+
+// String returns a string-valued attribute.
+func String(key, value string) Attribute {
+	return Attribute{key, value}
+}
+
+// Int returns an integer-valued attribute.
+func Int(key string, value int) Attribute {
+	return Attribute{key, strconv.Itoa(value)}
+}
+
+// SpanContext identifies a span across process boundaries.
+type SpanContext struct {
+	TraceID [16]byte
+	SpanID  [8]byte
+	Sampled bool
+}
+
+// IsValid reports whether sc has non-zero trace and span IDs.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != [16]byte{} && sc.SpanID != [8]byte{}
+}
+
+// Span is a single timed operation within a trace.
+type Span interface {
+	SpanContext() SpanContext
+	SetAttributes(attrs ...Attribute)
+	RecordError(err error)
+	End()
+}
+
+// Tracer starts spans.
+// Start creates a span that is a child of the span in ctx, if any,
+// or of the remote span context set by ContextWithRemoteSpanContext,
+// and returns a context carrying the new span.
+// Implementations must be safe for concurrent use.
+type Tracer interface {
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+}
+
+// Noop is a Tracer that records nothing.
+var Noop Tracer = noopTracer{}
+
+type noopTracer struct{}
+
+func (noopTracer) Start(ctx context.Context, _ string, _ ...Attribute) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SpanContext() SpanContext   { return SpanContext{} }
+func (noopSpan) SetAttributes(...Attribute) {}
+func (noopSpan) RecordError(error)          {}
+func (noopSpan) End()                       {}
+
+type spanKey struct{}
+type remoteKey struct{}
+
+// ContextWithSpan returns a copy of ctx carrying span.
+func ContextWithSpan(ctx context.Context, span Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// SpanFromContext returns the span carried by ctx, or nil.
+func SpanFromContext(ctx context.Context) Span {
+	span, _ := ctx.Value(spanKey{}).(Span)
+	return span
+}
+
+// ContextWithRemoteSpanContext returns a copy of ctx carrying
+// the context of a span in another process,
+// to become the parent of the next span started from ctx.
+func ContextWithRemoteSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, remoteKey{}, sc)
+}
+
+// ParentFromContext returns the context of the span
+// that a span started from ctx should be a child of,
+// preferring a local span over a remote one.
+func ParentFromContext(ctx context.Context) (SpanContext, bool) {
+	if span := SpanFromContext(ctx); span != nil {
+		if sc := span.SpanContext(); sc.IsValid() {
+			return sc, true
+		}
+	}
+	sc, ok := ctx.Value(remoteKey{}).(SpanContext)
+	return sc, ok && sc.IsValid()
+}
+
+// Inject returns the traceparent value
+// for the span carried by ctx, or "" if there is none.
+func Inject(ctx context.Context) string {
+	sc, ok := ParentFromContext(ctx)
+	if !ok {
+		return ""
+	}
+	return FormatTraceparent(sc)
+}
+
+// Extract returns ctx carrying the remote span context
+// encoded in traceparent, or ctx unchanged if it is empty or invalid.
+func Extract(ctx context.Context, traceparent string) context.Context {
+	sc, err := ParseTraceparent(traceparent)
+	if err != nil {
+		return ctx
+	}
+	return ContextWithRemoteSpanContext(ctx, sc)
+}
+
+// FormatTraceparent encodes sc as a W3C traceparent header value.
+func FormatTraceparent(sc SpanContext) string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return "00-" + hex.EncodeToString(sc.TraceID[:]) + "-" +
+		hex.EncodeToString(sc.SpanID[:]) + "-" + flags
+}
+
+// ParseTraceparent decodes a W3C traceparent header value.
+func ParseTraceparent(s string) (SpanContext, error) {
+	var sc SpanContext
+	errBad := errors.New("tracing: malformed traceparent")
+	if len(s) < 55 || s[2] != '-' || s[35] != '-' || s[52] != '-' ||
+		(len(s) > 55 && s[55] != '-') {
+		return sc, errBad
+	}
+	version, err := hex.DecodeString(s[:2])
+	if err != nil || version[0] == 0xff || (version[0] == 0 && len(s) != 55) {
+		return sc, errBad
+	}
+	if _, err := hex.Decode(sc.TraceID[:], []byte(s[3:35])); err != nil {
+		return sc, errBad
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(s[36:52])); err != nil {
+		return sc, errBad
+	}
+	flags, err := hex.DecodeString(s[53:55])
+	if err != nil || !sc.IsValid() {
+		return sc, errBad
+	}
+	sc.Sampled = flags[0]&1 != 0
+	return sc, nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tracing_test
+
+import (
+	"context"
+	"testing"
+
+	"test-server/golang-x-crypto/ed25519/cosi/cositest"
+	"test-server/golang-x-crypto/ed25519/cosi/tracing"
+)
+
+func TestTraceparent(t *testing.T) {
+	const tp = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	sc, err := tracing.ParseTraceparent(tp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sc.Sampled || sc.SpanID[7] != 0xb7 || tracing.FormatTraceparent(sc) != tp {
+		t.Errorf("traceparent does not round-trip: %+v", sc)
+	}
+	for _, bad := range []string{
+		"",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473x-00f067aa0ba902b7-01",
+	} {
+		if _, err := tracing.ParseTraceparent(bad); err == nil {
+			t.Errorf("accepted %q", bad)
+		}
+	}
+}
+
+func TestRoundTrace(t *testing.T) {
+	nw, err := cositest.NewNetwork(3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nw.Close()
+
+	rec := tracing.NewRecorder()
+	nw.Leader.Tracer = rec
+	for _, svc := range nw.Services {
+		svc.Tracer = rec
+	}
+	if _, err := nw.Sign(context.Background(), []byte("message")); err != nil {
+		t.Fatal(err)
+	}
+
+	byName := make(map[string][]tracing.RecordedSpan)
+	ids := make(map[[8]byte]tracing.RecordedSpan)
+	for _, s := range rec.Spans() {
+		byName[s.Name] = append(byName[s.Name], s)
+		ids[s.Context.SpanID] = s
+	}
+	for name, count := range map[string]int{
+		"cosi.Sign": 1, "cosi.commit_phase": 1, "cosi.response_phase": 1,
+		"cosi.Commit": 3, "cosi.Respond": 3,
+		"cosi.cosigner.Commit": 3, "cosi.cosigner.Respond": 3,
+	} {
+		if len(byName[name]) != count {
+			t.Errorf("%d %s spans, want %d", len(byName[name]), name, count)
+		}
+	}
+
+	root := byName["cosi.Sign"][0]
+	parentName := map[string]string{
+		"cosi.commit_phase":     "cosi.Sign",
+		"cosi.response_phase":   "cosi.Sign",
+		"cosi.Commit":           "cosi.commit_phase",
+		"cosi.Respond":          "cosi.response_phase",
+		"cosi.cosigner.Commit":  "cosi.Commit",
+		"cosi.cosigner.Respond": "cosi.Respond",
+	}
+	for name, want := range parentName {
+		for _, s := range byName[name] {
+			if s.Context.TraceID != root.Context.TraceID {
+				t.Errorf("%s span in a different trace", name)
+			}
+			if p := ids[s.Parent.SpanID]; p.Name != want {
+				t.Errorf("%s span has parent %q, want %q", name, p.Name, want)
+			}
+		}
+	}
+}
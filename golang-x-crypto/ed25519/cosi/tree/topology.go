@@ -0,0 +1,254 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tree
+
+import (
+	"errors"
+	"math"
+	"strconv"
+)
+
+// Config chooses the shape of a communication tree.
+// If Branching is set, every interior node has up to Branching children.
+// Otherwise the smallest branching factor is used
+// that fits the whole group within Depth levels below the root.
+// With neither set, the tree is flat: the root has every other member as a child.
+type Config struct {
+	Branching int
+	Depth     int
+}
+
+// branching returns the branching factor for a group of n members.
+func (c Config) branching(n int) int {
+	switch {
+	case c.Branching > 0:
+		return c.Branching
+	case c.Depth > 0 && n > 1:
+		// smallest b with b + b² + ... + b^Depth >= n-1
+		b := int(math.Ceil(math.Pow(float64(n-1), 1/float64(c.Depth))))
+		for b > 1 && capacity(b-1, c.Depth) >= n-1 {
+			b--
+		}
+		for capacity(b, c.Depth) < n-1 {
+			b++
+		}
+		return b
+	}
+	return n
+}
+
+// capacity returns the number of non-root nodes
+// in a complete b-ary tree of the given depth.
+func capacity(b, depth int) int {
+	total, level := 0, 1
+	for d := 0; d < depth; d++ {
+		level *= b
+		total += level
+		if total >= math.MaxInt32 {
+			break
+		}
+	}
+	return total
+}
+
+// Topology is a tree over the members of a cosigner group,
+// identified by their indices in the group's public key list.
+// Members missing from the tree take no part in rounds run over it.
+// A Topology is immutable.
+type Topology struct {
+	parent   []int // parent of each member; -1 for the root, -2 if absent
+	children [][]int
+	root     int
+}
+
+const (
+	noParent = -1
+	absent   = -2
+)
+
+// Build arranges the members of a group of n cosigners
+// in a complete tree of the shape chosen by config,
+// filled level by level in index order,
+// so member 0 is the root.
+func Build(n int, config Config) *Topology {
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	return build(n, order, config.branching(n))
+}
+
+// build fills a tree level by level with the members in order.
+func build(n int, order []int, b int) *Topology {
+	parent := make([]int, n)
+	for i := range parent {
+		parent[i] = absent
+	}
+	for pos, m := range order {
+		if pos == 0 {
+			parent[m] = noParent
+		} else {
+			parent[m] = order[(pos-1)/b]
+		}
+	}
+	t, _ := FromParents(parent) // a level-order fill is always a tree
+	return t
+}
+
+// FromParents reconstructs a topology from the parent of each member,
+// as returned by Parents:
+// -1 marks the root and -2 a member missing from the tree.
+func FromParents(parents []int) (*Topology, error) {
+	n := len(parents)
+	t := &Topology{
+		parent:   append([]int{}, parents...),
+		children: make([][]int, n),
+		root:     -1,
+	}
+	for i, p := range parents {
+		switch {
+		case p == noParent:
+			if t.root >= 0 {
+				return nil, errors.New("tree: more than one root")
+			}
+			t.root = i
+		case p == absent:
+		case p < 0 || p >= n || p == i || parents[p] == absent:
+			return nil, errors.New("tree: bad parent for member " + strconv.Itoa(i))
+		default:
+			t.children[p] = append(t.children[p], i)
+		}
+	}
+	if t.root < 0 {
+		return nil, errors.New("tree: no root")
+	}
+	// every member present must be reachable from the root
+	seen := 0
+	var walk func(int)
+	walk = func(i int) {
+		seen++
+		for _, c := range t.children[i] {
+			walk(c)
+		}
+	}
+	walk(t.root)
+	present := 0
+	for _, p := range parents {
+		if p != absent {
+			present++
+		}
+	}
+	if seen != present {
+		return nil, errors.New("tree: parent links contain a cycle")
+	}
+	return t, nil
+}
+
+// Parents returns the parent of each member:
+// -1 for the root and -2 for members missing from the tree.
+func (t *Topology) Parents() []int {
+	return append([]int{}, t.parent...)
+}
+
+// Root returns the member at the root of the tree.
+func (t *Topology) Root() int { return t.root }
+
+// Size returns the number of members in the group, present or not.
+func (t *Topology) Size() int { return len(t.parent) }
+
+// Parent returns the parent of member i,
+// or -1 if i is the root or missing from the tree.
+func (t *Topology) Parent(i int) int {
+	if t.parent[i] < 0 {
+		return -1
+	}
+	return t.parent[i]
+}
+
+// Children returns the children of member i.
+func (t *Topology) Children(i int) []int {
+	return append([]int{}, t.children[i]...)
+}
+
+// Contains reports whether member i is part of the tree.
+func (t *Topology) Contains(i int) bool {
+	return t.parent[i] != absent
+}
+
+// Depth returns the number of levels below the root.
+func (t *Topology) Depth() int {
+	var depth func(int) int
+	depth = func(i int) int {
+		d := 0
+		for _, c := range t.children[i] {
+			if cd := depth(c) + 1; cd > d {
+				d = cd
+			}
+		}
+		return d
+	}
+	return depth(t.root)
+}
+
+// Subtree returns member i and all its descendants.
+func (t *Topology) Subtree(i int) []int {
+	out := []int{i}
+	for _, c := range t.children[i] {
+		out = append(out, t.Subtree(c)...)
+	}
+	return out
+}
+
+// Reparent returns a copy of the tree with member i taken out,
+// and each of its children attached to i's parent instead,
+// as an aggregator does at run time when a child fails.
+// Removing the root promotes its first child to the root,
+// which then adopts the remaining children.
+func (t *Topology) Reparent(i int) *Topology {
+	parents := t.Parents()
+	up := parents[i]
+	kids := t.children[i]
+	parents[i] = absent
+	if up == noParent && len(kids) > 0 {
+		parents[kids[0]] = noParent
+		up, kids = kids[0], kids[1:]
+	}
+	for _, c := range kids {
+		parents[c] = up
+	}
+	nt, err := FromParents(parents)
+	if err != nil {
+		return t // removing the last member
+	}
+	return nt
+}
+
+// Rebalance rebuilds the tree with the shape chosen by config,
+// moving the given offline members to the end of the level order,
+// where they are leaves as long as they number fewer than the leaves,
+// so that they no longer cut their subtrees off from the root.
+// Offline members stay in the tree,
+// to take part again as soon as they come back.
+func (t *Topology) Rebalance(config Config, offline []int) *Topology {
+	down := make(map[int]bool)
+	for _, i := range offline {
+		down[i] = true
+	}
+	var order, tail []int
+	for i := range t.parent {
+		switch {
+		case !t.Contains(i):
+		case down[i]:
+			tail = append(tail, i)
+		default:
+			order = append(order, i)
+		}
+	}
+	order = append(order, tail...)
+	if len(order) == 0 {
+		return t
+	}
+	return build(len(t.parent), order, config.branching(len(order)))
+}
@@ -0,0 +1,82 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tree
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBuild(t *testing.T) {
+	top := Build(13, Config{Branching: 3})
+	if top.Root() != 0 || top.Depth() != 2 {
+		t.Errorf("root %d, depth %d", top.Root(), top.Depth())
+	}
+	if got := top.Children(1); !reflect.DeepEqual(got, []int{4, 5, 6}) {
+		t.Errorf("children of 1: %v", got)
+	}
+	if top.Parent(12) != 3 || top.Parent(0) != -1 {
+		t.Errorf("parents %v", top.Parents())
+	}
+
+	for _, tc := range []struct{ n, depth, branching int }{
+		{13, 2, 3}, {15, 3, 2}, {100, 2, 10}, {5, 1, 4}, {1, 3, 1},
+	} {
+		top := Build(tc.n, Config{Depth: tc.depth})
+		if top.Depth() > tc.depth {
+			t.Errorf("n=%d depth=%d: tree has depth %d", tc.n, tc.depth, top.Depth())
+		}
+		if tc.n > 1 && len(top.Children(0)) != tc.branching {
+			t.Errorf("n=%d depth=%d: branching %d, want %d",
+				tc.n, tc.depth, len(top.Children(0)), tc.branching)
+		}
+	}
+
+	if flat := Build(5, Config{}); flat.Depth() != 1 {
+		t.Errorf("flat tree has depth %d", flat.Depth())
+	}
+}
+
+func TestFromParents(t *testing.T) {
+	for _, parents := range [][]int{
+		{-1, -1},    // two roots
+		{1, 0},      // no root
+		{-1, 2, 1},  // cycle
+		{-1, 5},     // out of range
+		{-1, -2, 1}, // child of an absent member
+	} {
+		if _, err := FromParents(parents); err == nil {
+			t.Errorf("FromParents(%v) succeeded", parents)
+		}
+	}
+	top := Build(7, Config{Branching: 2})
+	back, err := FromParents(top.Parents())
+	if err != nil || !reflect.DeepEqual(back, top) {
+		t.Errorf("round trip: %v", err)
+	}
+}
+
+func TestReparent(t *testing.T) {
+	top := Build(7, Config{Branching: 2}).Reparent(1)
+	if top.Contains(1) || top.Parent(3) != 0 || top.Parent(4) != 0 {
+		t.Errorf("parents after removing 1: %v", top.Parents())
+	}
+	top = top.Reparent(0)
+	if top.Root() != 2 || top.Parent(3) != 2 || top.Parent(5) != 2 {
+		t.Errorf("parents after removing the root: %v", top.Parents())
+	}
+}
+
+func TestRebalance(t *testing.T) {
+	top := Build(7, Config{Branching: 2}).Rebalance(Config{Branching: 2}, []int{0, 2})
+	if top.Root() != 1 {
+		t.Errorf("root %d", top.Root())
+	}
+	for _, m := range []int{0, 2} {
+		if !top.Contains(m) || len(top.Children(m)) != 0 {
+			t.Errorf("offline member %d not a leaf: %v", m, top.Parents())
+		}
+	}
+}
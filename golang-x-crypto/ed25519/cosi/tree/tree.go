@@ -0,0 +1,485 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package tree runs collective signing rounds over a tree of cosigners,
+// so that no member, the leader included,
+// talks to more than a handful of others.
+//
+// The shape of the tree is chosen by a Config
+// (a branching factor, or a maximum depth)
+// and built from the group's member list with Build.
+// In each round the leader sends the commit request to the root;
+// every member commits, forwards the request to its children,
+// and returns the sum of its own and its children's commits
+// together with the mask of the members that took part in its subtree.
+// The challenge travels down the same way,
+// and every member returns the sum of its subtree's signature parts,
+// after checking each child's sum against that child's commit
+// with cosi.Cosigners.VerifyPartial.
+//
+// A member whose child does not answer in the commit phase
+// re-parents the child's children, contacting them directly,
+// so a failed interior node costs only itself, not its subtree.
+// A subtree that fails in the response phase
+// is reported to the leader in a SubtreeError,
+// and the leader restarts the round with its members excluded.
+// After every round, the leader rebalances the tree for the next one,
+// moving the members that were absent to the leaves.
+package tree
+
+import (
+	"context"
+	cryptorand "crypto/rand"
+	"errors"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/golang-x-crypto/ed25519/cosi"
+	"test-server/golang-x-crypto/ed25519/cosi/protocol"
+)
+
+// CommitRequest asks a member to commit for itself and its subtree.
+type CommitRequest struct {
+	SessionID []byte
+	Message   []byte
+	Leader    string
+	Term      uint64
+	Metadata  protocol.Metadata
+	Parents   []int // the round's topology, as returned by Topology.Parents
+}
+
+// CommitResponse carries the aggregate commit of a subtree.
+type CommitResponse struct {
+	Commit cosi.Commitment
+	Mask   []byte // members of the subtree that committed, in cosi's dense layout
+}
+
+// ChallengeRequest carries the round's challenge down the tree.
+type ChallengeRequest struct {
+	SessionID       []byte
+	Message         []byte
+	AggregateKey    ed25519.PublicKey
+	AggregateCommit cosi.Commitment
+}
+
+// ChallengeResponse carries the aggregate signature part of a subtree.
+type ChallengeResponse struct {
+	Part cosi.SignaturePart
+}
+
+// Peer is a handle on one member of the tree, local or remote;
+// a Node implements it.
+type Peer interface {
+	Commit(ctx context.Context, req *CommitRequest) (*CommitResponse, error)
+	Respond(ctx context.Context, req *ChallengeRequest) (*ChallengeResponse, error)
+}
+
+// SubtreeError reports members whose combined signature part
+// was missing or invalid, so the round cannot complete with them.
+type SubtreeError struct {
+	Members []int
+}
+
+func (e *SubtreeError) Error() string {
+	s := "tree: subtree failed in response phase:"
+	for _, m := range e.Members {
+		s += " " + strconv.Itoa(m)
+	}
+	return s
+}
+
+// child records a subtree's contribution to a round.
+type child struct {
+	member int
+	commit cosi.Commitment
+	mask   []byte
+}
+
+// gather sends req to each of the given members concurrently.
+// A member that fails is replaced by its own children in the topology,
+// which are then contacted directly.
+func gather(ctx context.Context, peers []Peer, top *Topology,
+	members []int, req *CommitRequest) []child {
+
+	var mu sync.Mutex
+	var out []child
+	var wg sync.WaitGroup
+	var visit func(m int)
+	visit = func(m int) {
+		defer wg.Done()
+		cctx, cancel := childContext(ctx)
+		resp, err := peers[m].Commit(cctx, req)
+		cancel()
+		if err == nil && validSubtree(top, m, resp.Mask) {
+			mu.Lock()
+			out = append(out, child{m, resp.Commit, resp.Mask})
+			mu.Unlock()
+			return
+		}
+		for _, c := range top.Children(m) {
+			wg.Add(1)
+			go visit(c)
+		}
+	}
+	for _, m := range members {
+		wg.Add(1)
+		go visit(m)
+	}
+	wg.Wait()
+	sort.Slice(out, func(i, j int) bool { return out[i].member < out[j].member })
+	return out
+}
+
+// childContext leaves a quarter of the remaining time
+// for the caller to aggregate and answer once its children have,
+// so deadlines shrink level by level down the tree.
+func childContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return context.WithCancel(ctx)
+	}
+	return context.WithDeadline(ctx, time.Now().Add(time.Until(deadline)*3/4))
+}
+
+// validSubtree checks that a subtree's mask enables only members
+// of that subtree, so that no member is counted twice.
+func validSubtree(top *Topology, m int, mask []byte) bool {
+	if len(mask) != (top.Size()+7)>>3 {
+		return false
+	}
+	in := make(map[int]bool)
+	for _, i := range top.Subtree(m) {
+		in[i] = true
+	}
+	for i := 0; i < top.Size(); i++ {
+		if mask[i>>3]&(1<<uint(i&7)) == 0 && !in[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// respond sends the challenge to each child,
+// checks every returned sum against the child's commit,
+// and returns the sum of the valid ones.
+func respond(ctx context.Context, cos *cosi.Cosigners, peers []Peer,
+	children []child, req *ChallengeRequest) (cosi.SignaturePart, error) {
+
+	parts := make([]cosi.SignaturePart, len(children))
+	errs := make([]error, len(children))
+	var wg sync.WaitGroup
+	for i, c := range children {
+		wg.Add(1)
+		go func(i int, c child) {
+			defer wg.Done()
+			cctx, cancel := childContext(ctx)
+			defer cancel()
+			resp, err := peers[c.member].Respond(cctx, req)
+			if err == nil && cos.VerifyPartial(req.Message, req.AggregateKey,
+				req.AggregateCommit, c.commit, c.mask, resp.Part) {
+				parts[i] = resp.Part
+			}
+			errs[i] = err
+		}(i, c)
+	}
+	wg.Wait()
+
+	failed := &SubtreeError{}
+	for i, c := range children {
+		if parts[i] != nil {
+			continue
+		}
+		// blame as deep in the tree as the failure was pinned down
+		var deeper *SubtreeError
+		if errors.As(errs[i], &deeper) {
+			failed.Members = append(failed.Members, deeper.Members...)
+			continue
+		}
+		for m := 0; m < cos.CountTotal(); m++ {
+			if c.mask[m>>3]&(1<<uint(m&7)) == 0 {
+				failed.Members = append(failed.Members, m)
+			}
+		}
+	}
+	if len(failed.Members) > 0 {
+		return nil, failed
+	}
+	return cosi.SumParts(parts...)
+}
+
+// Node is the tree side of one cosigner,
+// signing through the cosigner's protocol.Service
+// and aggregating its children's contributions.
+// It is safe for concurrent use.
+type Node struct {
+	self     int
+	cos      *cosi.Cosigners
+	service  *protocol.Service
+	peers    []Peer
+	mu       sync.Mutex
+	sessions map[string]*nodeSession
+}
+
+type nodeSession struct {
+	children []child
+	started  time.Time
+}
+
+// NewNode creates the tree side of member self
+// of the group identified by publicKeys,
+// reaching member i through peers[i]
+// and signing through service.
+func NewNode(self int, publicKeys []ed25519.PublicKey,
+	service *protocol.Service, peers []Peer) (*Node, error) {
+
+	cos, err := cosi.NewCosignersErr(publicKeys, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(peers) != len(publicKeys) {
+		return nil, errors.New("tree: one peer per public key required")
+	}
+	return &Node{
+		self:     self,
+		cos:      cos,
+		service:  service,
+		peers:    peers,
+		sessions: make(map[string]*nodeSession),
+	}, nil
+}
+
+// Commit implements Peer.
+func (nd *Node) Commit(ctx context.Context, req *CommitRequest) (*CommitResponse, error) {
+	top, err := FromParents(req.Parents)
+	if err != nil || top.Size() != len(nd.peers) || !top.Contains(nd.self) {
+		return nil, errors.New("tree: bad topology in commit request")
+	}
+	own, err := nd.service.Commit(ctx, &protocol.CommitRequest{
+		SessionID: req.SessionID,
+		Message:   req.Message,
+		Leader:    req.Leader,
+		Term:      req.Term,
+		Metadata:  req.Metadata,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	children := gather(ctx, nd.peers, top, top.Children(nd.self), req)
+	commits := []cosi.Commitment{own.Commit}
+	mask := make([]byte, (top.Size()+7)>>3)
+	for i := range mask {
+		mask[i] = 0xff
+	}
+	mask[nd.self>>3] &^= 1 << uint(nd.self&7)
+	for _, c := range children {
+		commits = append(commits, c.commit)
+		mask = cosi.MaskAnd(mask, c.mask)
+	}
+	sum, err := cosi.SumCommits(commits...)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	nd.mu.Lock()
+	for k, s := range nd.sessions {
+		if now.Sub(s.started) > protocol.DefaultSessionTimeout {
+			delete(nd.sessions, k)
+		}
+	}
+	nd.sessions[string(req.SessionID)] = &nodeSession{children, now}
+	nd.mu.Unlock()
+	return &CommitResponse{Commit: sum, Mask: mask}, nil
+}
+
+// Respond implements Peer.
+func (nd *Node) Respond(ctx context.Context, req *ChallengeRequest) (*ChallengeResponse, error) {
+	nd.mu.Lock()
+	sess, ok := nd.sessions[string(req.SessionID)]
+	delete(nd.sessions, string(req.SessionID))
+	nd.mu.Unlock()
+	if !ok {
+		return nil, protocol.ErrUnknownSession
+	}
+	own, err := nd.service.Respond(ctx, &protocol.ChallengeRequest{
+		SessionID:       req.SessionID,
+		Message:         req.Message,
+		AggregateKey:    req.AggregateKey,
+		AggregateCommit: req.AggregateCommit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	sub, err := respond(ctx, nd.cos, nd.peers, sess.children, req)
+	if err != nil {
+		return nil, err
+	}
+	sum, err := cosi.SumParts(own.Part, sub)
+	if err != nil {
+		return nil, err
+	}
+	return &ChallengeResponse{Part: sum}, nil
+}
+
+// Leader drives signing rounds over a tree of cosigners.
+type Leader struct {
+	// PhaseTimeout bounds each phase across the whole tree.
+	// If zero, protocol.DefaultPhaseTimeout is used.
+	PhaseTimeout time.Duration
+
+	// MaxAttempts bounds the number of attempts per signature.
+	// If zero, protocol.DefaultMaxAttempts is used.
+	MaxAttempts int
+
+	// Threshold is the minimum number of cosigners
+	// that must take part in a signature.
+	// If zero, every cosigner must take part.
+	Threshold int
+
+	// ID and Term identify the leader to the cosigners,
+	// as for protocol.Leader.
+	ID   string
+	Term uint64
+
+	config Config
+	group  *cosi.Cosigners
+	peers  []Peer
+	rand   io.Reader
+
+	mu  sync.Mutex
+	top *Topology
+}
+
+// NewLeader creates a leader for the group identified by publicKeys,
+// reaching member i through peers[i],
+// with a tree of the shape chosen by config.
+// Session identifiers are drawn from rand, or crypto/rand if rand is nil.
+func NewLeader(publicKeys []ed25519.PublicKey, peers []Peer,
+	config Config, rand io.Reader) (*Leader, error) {
+
+	if len(peers) != len(publicKeys) {
+		return nil, errors.New("tree: one peer per public key required")
+	}
+	group, err := cosi.NewCosignersErr(publicKeys, nil)
+	if err != nil {
+		return nil, err
+	}
+	if rand == nil {
+		rand = cryptorand.Reader
+	}
+	return &Leader{
+		config: config,
+		group:  group,
+		peers:  peers,
+		rand:   rand,
+		top:    Build(len(publicKeys), config),
+	}, nil
+}
+
+// Topology returns the tree the next round will use.
+func (l *Leader) Topology() *Topology {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.top
+}
+
+// Sign runs a collective signing round on message over the tree.
+// It returns the collective signature in the R || s || mask layout
+// and the members that did not take part.
+func (l *Leader) Sign(ctx context.Context, message []byte) (sig []byte, absent []int, err error) {
+	n := l.group.CountTotal()
+	threshold := l.Threshold
+	if threshold == 0 {
+		threshold = n
+	}
+	maxAttempts := l.MaxAttempts
+	if maxAttempts == 0 {
+		maxAttempts = protocol.DefaultMaxAttempts
+	}
+	timeout := l.PhaseTimeout
+	if timeout == 0 {
+		timeout = protocol.DefaultPhaseTimeout
+	}
+
+	top := l.Topology()
+	base := top
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		id := make([]byte, cosi.SessionIDSize)
+		if _, err := io.ReadFull(l.rand, id); err != nil {
+			return nil, nil, err
+		}
+		creq := &CommitRequest{
+			SessionID: id,
+			Message:   message,
+			Leader:    l.ID,
+			Term:      l.Term,
+			Metadata:  protocol.MetadataFromContext(ctx),
+			Parents:   top.Parents(),
+		}
+		cctx, cancel := context.WithTimeout(ctx, timeout)
+		children := gather(cctx, l.peers, top, []int{top.Root()}, creq)
+		cancel()
+
+		cos := l.group.Clone()
+		cos.SetMaskFormat(cosi.DenseMask)
+		mask := make([]byte, cos.MaskLen())
+		for i := range mask {
+			mask[i] = 0xff
+		}
+		var commits []cosi.Commitment
+		for _, c := range children {
+			commits = append(commits, c.commit)
+			mask = cosi.MaskAnd(mask, c.mask)
+		}
+		cos.SetMask(mask)
+		if cos.CountEnabled() < threshold {
+			return nil, nil, protocol.ErrInsufficientCosigners
+		}
+		aggR, err := cosi.SumCommits(commits...)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		rreq := &ChallengeRequest{
+			SessionID:       id,
+			Message:         message,
+			AggregateKey:    cos.AggregatePublicKey(),
+			AggregateCommit: aggR,
+		}
+		rctx, cancel := context.WithTimeout(ctx, timeout)
+		s, err := respond(rctx, cos, l.peers, children, rreq)
+		cancel()
+		var failed *SubtreeError
+		if errors.As(err, &failed) {
+			// exclude the failed subtrees and try again
+			for _, m := range failed.Members {
+				if top.Contains(m) {
+					top = top.Reparent(m)
+				}
+			}
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for i := 0; i < n; i++ {
+			if cos.MaskBit(i) == cosi.Disabled {
+				absent = append(absent, i)
+			}
+		}
+		l.mu.Lock()
+		l.top = base.Rebalance(l.config, absent)
+		l.mu.Unlock()
+
+		sig = append(append(append(make([]byte, 0, ed25519.SignatureSize+len(mask)),
+			aggR...), s...), mask...)
+		return sig, absent, nil
+	}
+	return nil, nil, errors.New("tree: attempts exhausted")
+}
@@ -0,0 +1,125 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tree
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/golang-x-crypto/ed25519/cosi"
+	"test-server/golang-x-crypto/ed25519/cosi/protocol"
+)
+
+var message = []byte("test message")
+
+// faulty wraps a peer, failing or corrupting its replies on demand.
+type faulty struct {
+	Peer
+	down    atomic.Bool
+	corrupt atomic.Bool
+}
+
+func (f *faulty) Commit(ctx context.Context, req *CommitRequest) (*CommitResponse, error) {
+	if f.down.Load() {
+		return nil, errors.New("down")
+	}
+	return f.Peer.Commit(ctx, req)
+}
+
+func (f *faulty) Respond(ctx context.Context, req *ChallengeRequest) (*ChallengeResponse, error) {
+	resp, err := f.Peer.Respond(ctx, req)
+	if err == nil && f.corrupt.Load() {
+		resp.Part = append(cosi.SignaturePart{}, resp.Part...)
+		resp.Part[0] ^= 1
+	}
+	return resp, err
+}
+
+func newTree(t *testing.T, n int, config Config) ([]ed25519.PublicKey, []*faulty, *Leader) {
+	var pubs []ed25519.PublicKey
+	var privs []ed25519.PrivateKey
+	for i := 0; i < n; i++ {
+		pub, priv, err := ed25519.GenerateKey(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pubs = append(pubs, pub)
+		privs = append(privs, priv)
+	}
+	links := make([]*faulty, n)
+	peers := make([]Peer, n)
+	for i := range links {
+		links[i] = &faulty{}
+		peers[i] = links[i]
+	}
+	for i := 0; i < n; i++ {
+		node, err := NewNode(i, pubs, protocol.NewService(privs[i], nil), peers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		links[i].Peer = node
+	}
+	l, err := NewLeader(pubs, peers, config, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.PhaseTimeout = time.Second
+	return pubs, links, l
+}
+
+func TestSign(t *testing.T) {
+	pubs, _, l := newTree(t, 13, Config{Branching: 3})
+	sig, absent, err := l.Sign(context.Background(), message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(absent) != 0 || !cosi.Verify(pubs, nil, message, sig) {
+		t.Errorf("absent %v, or signature rejected", absent)
+	}
+}
+
+func TestSignReparent(t *testing.T) {
+	pubs, links, l := newTree(t, 13, Config{Branching: 3})
+	links[0].down.Store(true) // the root
+	links[2].down.Store(true) // an interior node
+	l.Threshold = 11
+
+	sig, absent, err := l.Sign(context.Background(), message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(absent) != 2 || absent[0] != 0 || absent[1] != 2 {
+		t.Errorf("absent %v, want [0 2]", absent)
+	}
+	if !cosi.Verify(pubs, cosi.ThresholdPolicy(11), message, sig) {
+		t.Error("signature rejected")
+	}
+	// the next round's tree has the absent members as leaves
+	top := l.Topology()
+	if top.Root() != 1 || len(top.Children(0)) != 0 || len(top.Children(2)) != 0 {
+		t.Errorf("tree not rebalanced: %v", top.Parents())
+	}
+}
+
+func TestSignBlame(t *testing.T) {
+	pubs, links, l := newTree(t, 13, Config{Branching: 3})
+	links[7].corrupt.Store(true) // a leaf under member 2
+	l.Threshold = 12
+
+	sig, absent, err := l.Sign(context.Background(), message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(absent) != 1 || absent[0] != 7 {
+		t.Errorf("absent %v, want [7]", absent)
+	}
+	if !cosi.Verify(pubs, cosi.ThresholdPolicy(12), message, sig) {
+		t.Error("signature rejected")
+	}
+}
@@ -7,6 +7,7 @@ package cosi
 import (
 	"crypto/sha512"
 	"crypto/subtle"
+	"errors"
 
 	//"golang.org/x/crypto/ed25519"
 	//"golang.org/x/crypto/ed25519/internal/edwards25519"
@@ -49,6 +50,45 @@ func ThresholdPolicy(threshold int) Policy {
 	return &thresPolicy{threshold}
 }
 
+// MessagePolicy is a Policy whose decision may also depend
+// on the message being verified,
+// e.g., to require more cosigners for key-rotation messages
+// than for routine configuration changes.
+// When the registered policy implements MessagePolicy,
+// Verify calls CheckMessage with the verified message instead of Check.
+type MessagePolicy interface {
+	Policy
+	CheckMessage(cosigners *Cosigners, message []byte) bool
+}
+
+// MessagePolicyFunc adapts an ordinary function to the MessagePolicy interface.
+// Its Check method calls the function with a nil message.
+type MessagePolicyFunc func(cosigners *Cosigners, message []byte) bool
+
+func (f MessagePolicyFunc) Check(cosigners *Cosigners) bool {
+	return f(cosigners, nil)
+}
+
+func (f MessagePolicyFunc) CheckMessage(cosigners *Cosigners, message []byte) bool {
+	return f(cosigners, message)
+}
+
+// MessageThresholdPolicy creates a MessagePolicy
+// requiring at least threshold(message) cosigners
+// to have signed each verified message.
+func MessageThresholdPolicy(threshold func(message []byte) int) Policy {
+	return MessagePolicyFunc(func(cosigners *Cosigners, message []byte) bool {
+		return cosigners.CountEnabled() >= threshold(message)
+	})
+}
+
+func (cos *Cosigners) checkPolicy(message []byte) bool {
+	if mp, ok := cos.policy.(MessagePolicy); ok {
+		return mp.CheckMessage(cos, message)
+	}
+	return cos.policy.Check(cos)
+}
+
 // Verify determines whether collective signature represented by sig
 // is a valid collective signature on the indicated message,
 // collectively signed by an acceptable set of cosigners.
@@ -72,6 +112,27 @@ func ThresholdPolicy(threshold int) Policy {
 // the caller can similarly inspect the resulting participation mask
 // to determine which specific cosigners did and did not sign.
 func (cos *Cosigners) Verify(message, sig []byte) bool {
+	return cos.VerifyErr(message, sig) == nil
+}
+
+var (
+	// ErrMalformedSignature is returned by VerifyErr
+	// for a signature or mask that cannot be parsed.
+	ErrMalformedSignature = errors.New("cosi: malformed collective signature")
+
+	// ErrPolicy is returned by VerifyErr when the set of cosigners
+	// that produced the signature does not satisfy the policy.
+	ErrPolicy = errors.New("cosi: cosigner set rejected by policy")
+
+	// ErrInvalidSignature is returned by VerifyErr
+	// when the signature does not check out against the message
+	// and the participating cosigners' aggregate key.
+	ErrInvalidSignature = errors.New("cosi: invalid collective signature")
+)
+
+// VerifyErr is like Verify, but reports why a signature was rejected
+// by returning ErrMalformedSignature, ErrPolicy, or ErrInvalidSignature.
+func (cos *Cosigners) VerifyErr(message, sig []byte) error {
 
 	/* cosigSize := ed25519.SignatureSize + cos.MaskLen()
 	if len(sig) != cosigSize {
@@ -89,7 +150,7 @@ func (cos *Cosigners) Verify(message, sig []byte) bool {
 	return cos.verify(message, sig[:32], sig[:32], sig[32:64], cos.aggr) */
 	// 최소 64B(R||s)인지 확인
 	if len(sig) < ed25519.SignatureSize {
-		return false
+		return ErrMalformedSignature
 	}
 
 	// ----- mask 처리 -----
@@ -101,33 +162,67 @@ func (cos *Cosigners) Verify(message, sig []byte) bool {
 	} else {
 		//mask 생략 -> 전원서명 않을 시 primary node에서 해당라운드 파기 후 자동 다음 커미티 선출하는 규칙에 의거
 		//mask = bytes.Repeat([]byte{0xFF}, (len(cos.keys)+7)/8)
-		mask = make([]byte, (len(cos.keys)+7)/8) // all-zero ⇒ 모두 Enabled
+		mask = nil // nil ⇒ 모두 Enabled (마스크 형식과 무관)
+	}
+	return cos.verifyDetached(message, sig, mask)
+}
+
+// VerifyDetached is like Verify,
+// but accepts the 64-byte R || s core of the collective signature
+// and the participation mask as separate pieces,
+// for wire formats that carry the mask apart from the signature.
+// The mask is interpreted according to the current mask format,
+// except that a nil or empty mask always enables every cosigner.
+func (cos *Cosigners) VerifyDetached(message, rs, mask []byte) bool {
+	return cos.verifyDetached(message, rs, mask) == nil
+}
+
+func (cos *Cosigners) verifyDetached(message, rs, mask []byte) error {
+	if len(rs) != ed25519.SignatureSize {
+		return ErrMalformedSignature
+	}
+	if len(mask) != 0 && cos.format == SparseMask {
+		dense, err := cos.sparseToDense(mask)
+		if err != nil {
+			return ErrMalformedSignature
+		}
+		mask = dense
 	}
-	cos.SetMask(mask)
+	cos.setDenseMask(mask)
 
 	//정책 검사(있을 때만
-	if cos.policy != nil && !cos.policy.Check(cos) {
-		return false
+	if cos.policy != nil && !cos.checkPolicy(message) {
+		return ErrPolicy
 	}
 
-	// R= sig[:32], s= sig[32:64]
-	return cos.verify(message, sig[:32], sig[:32], sig[32:], cos.aggr)
+	// R= rs[:32], s= rs[32:64]
+	if !cos.verify(message, rs[:32], rs[:32], rs[32:], cos.aggr) {
+		return ErrInvalidSignature
+	}
+	return nil
 }
 
 func (cos *Cosigners) verify(message, aggR, sigR, sigS []byte,
 	sigA edwards25519.ExtendedGroupElement) bool {
 
-	if len(sigR) != 32 || len(sigS) != 32 || sigS[31]&224 != 0 {
-		return false
-	}
-
 	// Compute the digest against aggregate public key and commit
 	var aggK [32]byte
 	cos.aggr.ToBytes(&aggK)
+	return verifyWith(message, aggR, aggK[:], sigR, sigS, sigA)
+}
+
+// verifyWith checks a (partial) signature R, s against public key A
+// for the challenge defined by aggregate commit aggR and aggregate key aggK.
+func verifyWith(message, aggR, aggK, sigR, sigS []byte,
+	sigA edwards25519.ExtendedGroupElement) bool {
+
+	if len(sigR) != 32 || len(sigS) != 32 || sigS[31]&224 != 0 {
+		return false
+	}
 
 	h := sha512.New()
 	h.Write(aggR)
-	h.Write(aggK[:])
+	h.Write(aggK)
 	h.Write(message)
 	var digest [64]byte
 	h.Sum(digest[:0])
@@ -190,6 +285,24 @@ func Verify(publicKeys []ed25519.PublicKey, policy Policy,
 		return false
 	}
 	cos := NewCosigners(publicKeys, sig[64:])
+	if cos == nil {
+		return false
+	}
 	cos.SetPolicy(policy)
 	return cos.Verify(message, sig)
 }
+
+// VerifyDetached checks a collective signature on a given message
+// whose R || s core and participation mask are carried separately,
+// using a given list of public keys and acceptance policy
+// as described for Verify.
+func VerifyDetached(publicKeys []ed25519.PublicKey, policy Policy,
+	message, rs, mask []byte) bool {
+
+	cos := NewCosigners(publicKeys, nil)
+	if cos == nil {
+		return false
+	}
+	cos.SetPolicy(policy)
+	return cos.VerifyDetached(message, rs, mask)
+}
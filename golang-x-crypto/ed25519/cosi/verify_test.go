@@ -0,0 +1,95 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cosi
+
+import "testing"
+
+func TestVerifyDetached(t *testing.T) {
+	n := 10
+	genKeys(n)
+	parts := make([]*Participant, n)
+	for i := range parts {
+		parts[i] = NewParticipant(priKeys[i], nil)
+	}
+	sig := runSession(t, NewCosigners(pubKeys[:n], nil), parts, 6, map[int]bool{4: true})
+	rs, mask := sig[:64], sig[64:]
+
+	if !VerifyDetached(pubKeys[:n], ThresholdPolicy(n-1), rightMessage, rs, mask) {
+		t.Errorf("valid detached signature rejected")
+	}
+	if VerifyDetached(pubKeys[:n], ThresholdPolicy(n-1), wrongMessage, rs, mask) {
+		t.Errorf("detached signature on different message accepted")
+	}
+	if VerifyDetached(pubKeys[:n], ThresholdPolicy(n-1), rightMessage, rs, nil) {
+		t.Errorf("detached signature accepted with wrong mask")
+	}
+	if VerifyDetached(pubKeys[:n], ThresholdPolicy(n-1), rightMessage, sig, mask) {
+		t.Errorf("detached signature accepted with mask left in the core")
+	}
+
+	full := runSession(t, NewCosigners(pubKeys[:n], nil), parts, 7, nil)
+	cos := NewCosigners(pubKeys[:n], nil)
+	if !cos.VerifyDetached(rightMessage, full[:64], nil) {
+		t.Errorf("valid detached signature rejected with nil mask")
+	}
+}
+
+func TestMessagePolicy(t *testing.T) {
+	n := 8
+	genKeys(n)
+	parts := make([]*Participant, n)
+	for i := range parts {
+		parts[i] = NewParticipant(priKeys[i], nil)
+	}
+	absent := map[int]bool{1: true, 6: true}
+	sig := runSession(t, NewCosigners(pubKeys[:n], nil), parts, 10, absent)
+
+	var seen []byte
+	policy := MessageThresholdPolicy(func(message []byte) int {
+		seen = message
+		if string(message) == string(rightMessage) {
+			return n - 2
+		}
+		return n
+	})
+	cos := NewCosigners(pubKeys[:n], nil)
+	cos.SetPolicy(policy)
+	if !cos.Verify(rightMessage, sig) {
+		t.Errorf("signature meeting message threshold rejected")
+	}
+	if string(seen) != string(rightMessage) {
+		t.Errorf("policy saw message %q", seen)
+	}
+
+	strict := MessageThresholdPolicy(func([]byte) int { return n - 1 })
+	if Verify(pubKeys[:n], strict, rightMessage, sig) {
+		t.Errorf("signature below message threshold accepted")
+	}
+}
+
+func TestVerifyErr(t *testing.T) {
+	n := 6
+	genKeys(n)
+	parts := make([]*Participant, n)
+	for i := range parts {
+		parts[i] = NewParticipant(priKeys[i], nil)
+	}
+	sig := runSession(t, NewCosigners(pubKeys[:n], nil), parts, 11, map[int]bool{2: true})
+
+	cos := NewCosigners(pubKeys[:n], nil)
+	if err := cos.VerifyErr(rightMessage, sig[:10]); err != ErrMalformedSignature {
+		t.Errorf("short signature: got %v", err)
+	}
+	if err := cos.VerifyErr(rightMessage, sig); err != ErrPolicy {
+		t.Errorf("partial signature under full policy: got %v", err)
+	}
+	cos.SetPolicy(ThresholdPolicy(n - 1))
+	if err := cos.VerifyErr(wrongMessage, sig); err != ErrInvalidSignature {
+		t.Errorf("wrong message: got %v", err)
+	}
+	if err := cos.VerifyErr(rightMessage, sig); err != nil {
+		t.Errorf("valid signature: got %v", err)
+	}
+}
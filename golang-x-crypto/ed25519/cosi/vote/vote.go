@@ -0,0 +1,267 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package vote aggregates individual cosigner votes
+// tagged with consensus height, round and block hash
+// into collective certificates,
+// for integration into chain replication layers.
+//
+// Each vote is an ordinary Ed25519 signature by one cosigner
+// on the canonical encoding of its (height, round, block hash) tag,
+// so votes can be cast independently and asynchronously.
+// Once the votes received for one tag satisfy the group's policy,
+// the Aggregator emits a Certificate in which they are compressed
+// by half-aggregation (see cosi.HalfAggregate)
+// together with the participation mask of the voters.
+// A cosigner that votes for two different block hashes
+// at the same height and round is rejected with a *ConflictError
+// carrying both votes as evidence of equivocation.
+package vote
+
+import (
+	"encoding/binary"
+	"errors"
+	"strconv"
+	"sync"
+
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/golang-x-crypto/ed25519/cosi"
+)
+
+const voteDomain = "cosi-vote-v1"
+
+// Vote is one cosigner's signed vote for a block.
+type Vote struct {
+	Height    uint64
+	Round     uint64
+	BlockHash []byte
+	Signer    int    // index of the voter in the group's public key list
+	Signature []byte // Ed25519 signature on Message(Height, Round, BlockHash)
+}
+
+// Message returns the canonical message signed by a vote.
+func Message(height, round uint64, blockHash []byte) []byte {
+	b := make([]byte, 0, len(voteDomain)+16+len(blockHash))
+	b = append(b, voteDomain...)
+	b = binary.BigEndian.AppendUint64(b, height)
+	b = binary.BigEndian.AppendUint64(b, round)
+	return append(b, blockHash...)
+}
+
+// Sign produces the vote of the signer-th cosigner, holding privateKey,
+// for blockHash at the given height and round.
+func Sign(privateKey ed25519.PrivateKey, signer int,
+	height, round uint64, blockHash []byte) Vote {
+
+	return Vote{
+		Height:    height,
+		Round:     round,
+		BlockHash: append([]byte{}, blockHash...),
+		Signer:    signer,
+		Signature: ed25519.Sign(privateKey, Message(height, round, blockHash)),
+	}
+}
+
+// ConflictError reports a cosigner that voted for two different blocks
+// at the same height and round.
+type ConflictError struct {
+	First, Second Vote
+}
+
+func (e *ConflictError) Error() string {
+	return "vote: cosigner " + strconv.Itoa(e.First.Signer) +
+		" voted for conflicting blocks at height " +
+		strconv.FormatUint(e.First.Height, 10) +
+		" round " + strconv.FormatUint(e.First.Round, 10)
+}
+
+// Certificate is a collective certificate for a block:
+// the half-aggregate of the votes of the cosigners enabled in Mask.
+type Certificate struct {
+	Height    uint64
+	Round     uint64
+	BlockHash []byte
+	Mask      []byte // dense participation mask of the voters
+	Signature []byte // half-aggregate of the voters' signatures, in index order
+}
+
+type slot struct {
+	height, round uint64
+}
+
+type tally struct {
+	votes   map[int]Vote
+	emitted bool
+}
+
+// Aggregator collects votes for a fixed cosigner group.
+// It is safe for concurrent use.
+type Aggregator struct {
+	publicKeys []ed25519.PublicKey
+	cosigners  *cosi.Cosigners
+
+	mu     sync.Mutex
+	cast   map[slot]map[int]Vote      // each signer's vote per height and round
+	tally  map[slot]map[string]*tally // votes per block hash
+	policy cosi.Policy
+}
+
+// NewAggregator creates an Aggregator for the group identified by publicKeys,
+// emitting a certificate for a block once the set of its voters
+// satisfies policy (nil meaning every cosigner must vote).
+func NewAggregator(publicKeys []ed25519.PublicKey, policy cosi.Policy) (*Aggregator, error) {
+	cos, err := cosi.NewCosignersErr(publicKeys, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Aggregator{
+		publicKeys: publicKeys,
+		cosigners:  cos,
+		cast:       make(map[slot]map[int]Vote),
+		tally:      make(map[slot]map[string]*tally),
+		policy:     policy,
+	}, nil
+}
+
+// Add records a vote.
+// It returns an error if the vote's signature is invalid
+// or if its signer already voted for a different block
+// at the same height and round (as a *ConflictError).
+// A repeated identical vote is ignored.
+// When the vote completes a set of voters satisfying the policy,
+// Add returns the certificate for its block;
+// it does so only once per block, height and round,
+// and returns a nil certificate otherwise.
+func (a *Aggregator) Add(v Vote) (*Certificate, error) {
+	if v.Signer < 0 || v.Signer >= len(a.publicKeys) {
+		return nil, errors.New("vote: no such cosigner " + strconv.Itoa(v.Signer))
+	}
+	if ed25519.VerifyErr(a.publicKeys[v.Signer],
+		Message(v.Height, v.Round, v.BlockHash), v.Signature) != nil {
+		return nil, errors.New("vote: invalid signature from cosigner " +
+			strconv.Itoa(v.Signer))
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	sl := slot{v.Height, v.Round}
+	cast := a.cast[sl]
+	if cast == nil {
+		cast = make(map[int]Vote)
+		a.cast[sl] = cast
+		a.tally[sl] = make(map[string]*tally)
+	}
+	if prev, ok := cast[v.Signer]; ok {
+		if string(prev.BlockHash) != string(v.BlockHash) {
+			return nil, &ConflictError{First: prev, Second: v}
+		}
+		return nil, nil
+	}
+	cast[v.Signer] = v
+
+	t := a.tally[sl][string(v.BlockHash)]
+	if t == nil {
+		t = &tally{votes: make(map[int]Vote)}
+		a.tally[sl][string(v.BlockHash)] = t
+	}
+	t.votes[v.Signer] = v
+	if t.emitted {
+		return nil, nil
+	}
+
+	mask := a.mask(t.votes)
+	a.cosigners.SetMask(mask)
+	if !a.checkPolicy() {
+		return nil, nil
+	}
+	cert, err := a.certify(v, mask, t.votes)
+	if err != nil {
+		return nil, err
+	}
+	t.emitted = true
+	return cert, nil
+}
+
+// Prune discards all votes below the given height.
+func (a *Aggregator) Prune(height uint64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for sl := range a.cast {
+		if sl.height < height {
+			delete(a.cast, sl)
+			delete(a.tally, sl)
+		}
+	}
+}
+
+func (a *Aggregator) checkPolicy() bool {
+	if a.policy == nil {
+		return a.cosigners.CountEnabled() == a.cosigners.CountTotal()
+	}
+	return a.policy.Check(a.cosigners)
+}
+
+func (a *Aggregator) mask(votes map[int]Vote) []byte {
+	mask := make([]byte, a.cosigners.MaskLen())
+	for i := range a.publicKeys {
+		if _, ok := votes[i]; !ok {
+			mask[i>>3] |= 1 << uint(i&7)
+		}
+	}
+	return mask
+}
+
+func (a *Aggregator) certify(v Vote, mask []byte, votes map[int]Vote) (*Certificate, error) {
+	msg := Message(v.Height, v.Round, v.BlockHash)
+	var keys []ed25519.PublicKey
+	var messages, sigs [][]byte
+	for i := range a.publicKeys {
+		if w, ok := votes[i]; ok {
+			keys = append(keys, a.publicKeys[i])
+			messages = append(messages, msg)
+			sigs = append(sigs, w.Signature)
+		}
+	}
+	agg, err := cosi.HalfAggregate(keys, messages, sigs)
+	if err != nil {
+		return nil, err
+	}
+	return &Certificate{
+		Height:    v.Height,
+		Round:     v.Round,
+		BlockHash: append([]byte{}, v.BlockHash...),
+		Mask:      mask,
+		Signature: agg,
+	}, nil
+}
+
+// Verify checks a certificate against the group identified by publicKeys,
+// requiring its voters to satisfy policy
+// (nil meaning every cosigner must have voted).
+func Verify(publicKeys []ed25519.PublicKey, policy cosi.Policy, cert *Certificate) bool {
+	cos := cosi.NewCosigners(publicKeys, nil)
+	if cos == nil || len(cert.Mask) != cos.MaskLen() {
+		return false
+	}
+	cos.SetMask(cert.Mask)
+	if policy == nil {
+		if cos.CountEnabled() != cos.CountTotal() {
+			return false
+		}
+	} else if !policy.Check(cos) {
+		return false
+	}
+
+	msg := Message(cert.Height, cert.Round, cert.BlockHash)
+	var keys []ed25519.PublicKey
+	var messages [][]byte
+	for i := range publicKeys {
+		if cos.MaskBit(i) == cosi.Enabled {
+			keys = append(keys, publicKeys[i])
+			messages = append(messages, msg)
+		}
+	}
+	return len(keys) > 0 && cosi.VerifyHalfAggregate(keys, messages, cert.Signature)
+}
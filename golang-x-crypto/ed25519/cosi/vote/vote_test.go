@@ -0,0 +1,93 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vote
+
+import (
+	"testing"
+
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/golang-x-crypto/ed25519/cosi"
+)
+
+type constReader struct{ val byte }
+
+func (cr constReader) Read(buf []byte) (int, error) {
+	for i := range buf {
+		buf[i] = cr.val
+	}
+	return len(buf), nil
+}
+
+func TestAggregator(t *testing.T) {
+	n := 4
+	pubs := make([]ed25519.PublicKey, n)
+	privs := make([]ed25519.PrivateKey, n)
+	for i := range pubs {
+		pubs[i], privs[i], _ = ed25519.GenerateKey(constReader{byte(i)})
+	}
+	policy := cosi.ThresholdPolicy(3)
+	agg, err := NewAggregator(pubs, policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blockA, blockB := []byte("block A"), []byte("block B")
+	for i := 0; i < 2; i++ {
+		if cert, err := agg.Add(Sign(privs[i], i, 7, 0, blockA)); cert != nil || err != nil {
+			t.Fatalf("vote %d: cert %v, err %v", i, cert, err)
+		}
+	}
+	// A repeated vote is ignored and does not count twice.
+	if cert, err := agg.Add(Sign(privs[1], 1, 7, 0, blockA)); cert != nil || err != nil {
+		t.Fatalf("repeated vote: cert %v, err %v", cert, err)
+	}
+
+	// Equivocation is rejected with evidence.
+	_, err = agg.Add(Sign(privs[1], 1, 7, 0, blockB))
+	conflict, ok := err.(*ConflictError)
+	if !ok || string(conflict.First.BlockHash) != string(blockA) {
+		t.Fatalf("conflicting vote: got %v", err)
+	}
+	// A vote in another round is not a conflict.
+	if _, err := agg.Add(Sign(privs[1], 1, 7, 1, blockB)); err != nil {
+		t.Errorf("vote in new round rejected: %v", err)
+	}
+
+	bad := Sign(privs[2], 2, 7, 0, blockA)
+	bad.Signer = 3
+	if _, err := agg.Add(bad); err == nil {
+		t.Errorf("vote with wrong signer accepted")
+	}
+
+	cert, err := agg.Add(Sign(privs[3], 3, 7, 0, blockA))
+	if err != nil || cert == nil {
+		t.Fatalf("quorum vote: cert %v, err %v", cert, err)
+	}
+	if !Verify(pubs, policy, cert) {
+		t.Errorf("valid certificate rejected")
+	}
+	if Verify(pubs, nil, cert) {
+		t.Errorf("partial certificate accepted under full policy")
+	}
+	if cert, _ := agg.Add(Sign(privs[2], 2, 7, 0, blockA)); cert != nil {
+		t.Errorf("certificate emitted twice")
+	}
+
+	forged := *cert
+	forged.BlockHash = blockB
+	if Verify(pubs, policy, &forged) {
+		t.Errorf("certificate for different block accepted")
+	}
+	forged = *cert
+	forged.Mask = []byte{0x02}
+	if Verify(pubs, policy, &forged) {
+		t.Errorf("certificate with altered mask accepted")
+	}
+
+	agg.Prune(8)
+	if _, err := agg.Add(Sign(privs[1], 1, 7, 0, blockB)); err != nil {
+		t.Errorf("vote after pruning rejected: %v", err)
+	}
+}
@@ -0,0 +1,252 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package witnesslog implements an append-only transparency log
+// of collectively signed statements, witnessed by the cosigners.
+//
+// Every statement the group signs is appended to the log
+// together with its collective signature.
+// The log is a Merkle tree as in Certificate Transparency (RFC 6962),
+// and its state is periodically published as a Head
+// carrying the tree size, the tree root,
+// and the hash of the previous head, forming a hash chain.
+// Heads are themselves collectively signed,
+// so every cosigner acts as a witness:
+// before cosigning a new head, a cosigner checks with its Witness
+// that the head extends the last one it signed,
+// using a consistency proof fetched from the log.
+// A leader that tries to show different histories to different parties
+// therefore cannot obtain signatures on both,
+// and clients holding two heads can check their consistency themselves.
+package witnesslog
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"sync"
+
+	"test-server/golang-x-crypto/ed25519/cosi"
+	"test-server/golang-x-crypto/ed25519/cosi/protocol"
+)
+
+const headDomain = "cosi-witnesslog-head-v1"
+
+// ErrEquivocation is returned by Witness.Observe for a head
+// that does not extend the previously observed head.
+var ErrEquivocation = errors.New("witnesslog: head does not extend the observed history")
+
+// Signer runs collective signing rounds;
+// it is satisfied by *protocol.Leader.
+type Signer interface {
+	Sign(ctx context.Context, message []byte) (*protocol.Result, error)
+}
+
+// Head is a published state of the log.
+type Head struct {
+	Size      uint64 // number of entries covered
+	Root      []byte // Merkle tree hash of the first Size entries
+	Prev      []byte // Hash of the previous head, or nil for the first one
+	Signature []byte // collective signature on Message
+}
+
+// Message returns the message collectively signed for the head.
+func (h *Head) Message() []byte {
+	b := make([]byte, 0, len(headDomain)+8+2*sha256.Size)
+	b = append(b, headDomain...)
+	b = binary.BigEndian.AppendUint64(b, h.Size)
+	b = append(b, h.Root...)
+	return append(b, h.Prev...)
+}
+
+// Hash returns the hash by which the next head refers to this one.
+func (h *Head) Hash() []byte {
+	sum := sha256.Sum256(h.Message())
+	return sum[:]
+}
+
+// VerifyHead checks the collective signature on a head,
+// using the cosigner group's current policy.
+func VerifyHead(cosigners *cosi.Cosigners, h *Head) bool {
+	return len(h.Root) == sha256.Size &&
+		(h.Prev == nil || len(h.Prev) == sha256.Size) &&
+		cosigners.Verify(h.Message(), h.Signature)
+}
+
+// LeafHash returns the leaf hash of a log entry
+// recording statement with its collective signature,
+// for use with VerifyInclusion.
+func LeafHash(statement, signature []byte) []byte {
+	return hashLeaf(encodeEntry(statement, signature))
+}
+
+func encodeEntry(statement, signature []byte) []byte {
+	b := binary.AppendUvarint(nil, uint64(len(statement)))
+	b = append(b, statement...)
+	return append(b, signature...)
+}
+
+type entry struct {
+	statement, signature []byte
+}
+
+// Log is the leader's copy of the transparency log.
+// It is safe for concurrent use.
+type Log struct {
+	signer Signer
+
+	mu      sync.Mutex
+	entries []entry
+	leaves  [][]byte
+	heads   []*Head
+}
+
+// NewLog creates an empty log whose heads are signed through signer.
+func NewLog(signer Signer) *Log {
+	return &Log{signer: signer}
+}
+
+// Append records a collectively signed statement
+// and returns its index in the log.
+// The entry is covered by the next published head.
+func (l *Log) Append(statement, signature []byte) uint64 {
+	e := entry{append([]byte{}, statement...), append([]byte{}, signature...)}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, e)
+	l.leaves = append(l.leaves, LeafHash(e.statement, e.signature))
+	return uint64(len(l.entries) - 1)
+}
+
+// Sign collectively signs statement through the log's signer
+// and records the statement with its signature.
+func (l *Log) Sign(ctx context.Context, statement []byte) (index uint64, sig []byte, err error) {
+	res, err := l.signer.Sign(ctx, statement)
+	if err != nil {
+		return 0, nil, err
+	}
+	return l.Append(statement, res.Signature), res.Signature, nil
+}
+
+// Entry returns the statement and signature recorded at index.
+func (l *Log) Entry(index uint64) (statement, signature []byte, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if index >= uint64(len(l.entries)) {
+		return nil, nil, errors.New("witnesslog: no such entry")
+	}
+	e := l.entries[index]
+	return e.statement, e.signature, nil
+}
+
+// Size returns the number of entries in the log.
+func (l *Log) Size() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return uint64(len(l.leaves))
+}
+
+// Publish has the group collectively sign a new head
+// covering every entry appended so far,
+// chained to the previously published head.
+func (l *Log) Publish(ctx context.Context) (*Head, error) {
+	l.mu.Lock()
+	h := &Head{Size: uint64(len(l.leaves)), Root: rootOf(l.leaves)}
+	var prev *Head
+	if len(l.heads) > 0 {
+		prev = l.heads[len(l.heads)-1]
+		h.Prev = prev.Hash()
+	}
+	l.mu.Unlock()
+
+	res, err := l.signer.Sign(ctx, h.Message())
+	if err != nil {
+		return nil, err
+	}
+	h.Signature = res.Signature
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.heads) > 0 && l.heads[len(l.heads)-1] != prev {
+		return nil, errors.New("witnesslog: concurrent publication")
+	}
+	l.heads = append(l.heads, h)
+	return h, nil
+}
+
+// Head returns the latest published head, or nil if there is none.
+func (l *Log) Head() *Head {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.heads) == 0 {
+		return nil
+	}
+	return l.heads[len(l.heads)-1]
+}
+
+// InclusionProof returns the proof that the entry at index
+// is included in the tree of the given size.
+func (l *Log) InclusionProof(index, size uint64) ([][]byte, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if index >= size || size > uint64(len(l.leaves)) {
+		return nil, errors.New("witnesslog: inclusion proof out of range")
+	}
+	return inclusionPath(index, l.leaves[:size]), nil
+}
+
+// ConsistencyProof returns the proof that the tree of size second
+// extends the tree of size first.
+func (l *Log) ConsistencyProof(first, second uint64) ([][]byte, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if first > second || second > uint64(len(l.leaves)) {
+		return nil, errors.New("witnesslog: consistency proof out of range")
+	}
+	if first == 0 || first == second {
+		return nil, nil
+	}
+	return consistencyPath(first, l.leaves[:second], true), nil
+}
+
+// Witness tracks the latest head a cosigner has vouched for.
+// It is safe for concurrent use.
+type Witness struct {
+	mu   sync.Mutex
+	last *Head
+}
+
+// Last returns the latest head observed, or nil if there is none.
+func (w *Witness) Last() *Head {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.last
+}
+
+// Observe checks that the (not yet signed) head h extends
+// the previously observed head, as shown by the consistency proof
+// from the previous head's size to h.Size,
+// and if so records h as the latest head.
+// It returns ErrEquivocation otherwise.
+// A cosigner should call Observe before cosigning a head.
+func (w *Witness) Observe(h *Head, proof [][]byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.last == nil {
+		if h.Prev != nil {
+			return ErrEquivocation
+		}
+		w.last = h
+		return nil
+	}
+	if subtle.ConstantTimeCompare(h.Prev, w.last.Hash()) != 1 ||
+		!VerifyConsistency(w.last.Size, h.Size, w.last.Root, h.Root, proof) {
+		return ErrEquivocation
+	}
+	w.last = h
+	return nil
+}
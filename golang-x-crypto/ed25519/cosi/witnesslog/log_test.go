@@ -0,0 +1,125 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package witnesslog
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"test-server/golang-x-crypto/ed25519/cosi/cositest"
+)
+
+func TestProofs(t *testing.T) {
+	var leaves [][]byte
+	for n := 1; n <= 20; n++ {
+		leaves = append(leaves, hashLeaf([]byte(strconv.Itoa(n))))
+		root := rootOf(leaves)
+		for m := 0; m < n; m++ {
+			proof := inclusionPath(uint64(m), leaves)
+			if !VerifyInclusion(leaves[m], uint64(m), uint64(n), root, proof) {
+				t.Errorf("inclusion of %d in %d rejected", m, n)
+			}
+			if VerifyInclusion(leaves[(m+1)%n], uint64(m), uint64(n), root, proof) && n > 1 {
+				t.Errorf("inclusion of wrong leaf at %d in %d accepted", m, n)
+			}
+		}
+		for m := 1; m <= n; m++ {
+			oldRoot := rootOf(leaves[:m])
+			var proof [][]byte
+			if m < n {
+				proof = consistencyPath(uint64(m), leaves, true)
+			}
+			if !VerifyConsistency(uint64(m), uint64(n), oldRoot, root, proof) {
+				t.Errorf("consistency %d -> %d rejected", m, n)
+			}
+			if m < n && VerifyConsistency(uint64(m), uint64(n), leaves[0][:], root, proof) && m > 1 {
+				t.Errorf("consistency %d -> %d with wrong old root accepted", m, n)
+			}
+		}
+	}
+}
+
+func TestLogWitness(t *testing.T) {
+	nw, err := cositest.NewNetwork(3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nw.Close()
+	ctx := context.Background()
+	cos := nw.Cosigners()
+
+	log := NewLog(nw.Leader)
+	var w Witness
+	publish := func() *Head {
+		next := &Head{Size: log.Size(), Root: rootOf(log.leaves)}
+		if last := w.Last(); last != nil {
+			next.Prev = last.Hash()
+			proof, err := log.ConsistencyProof(last.Size, next.Size)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := w.Observe(next, proof); err != nil {
+				t.Fatal(err)
+			}
+		} else if err := w.Observe(next, nil); err != nil {
+			t.Fatal(err)
+		}
+		h, err := log.Publish(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !VerifyHead(cos, h) {
+			t.Fatalf("valid head rejected")
+		}
+		return h
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, _, err := log.Sign(ctx, []byte("statement "+strconv.Itoa(i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	h1 := publish()
+	for i := 3; i < 7; i++ {
+		if _, _, err := log.Sign(ctx, []byte("statement "+strconv.Itoa(i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	h2 := publish()
+	if string(h2.Prev) != string(h1.Hash()) {
+		t.Errorf("heads not chained")
+	}
+
+	statement, sig, err := log.Entry(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cos.Verify(statement, sig) {
+		t.Errorf("logged signature rejected")
+	}
+	proof, err := log.InclusionProof(4, h2.Size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyInclusion(LeafHash(statement, sig), 4, h2.Size, h2.Root, proof) {
+		t.Errorf("inclusion proof rejected")
+	}
+	proof, _ = log.ConsistencyProof(h1.Size, h2.Size)
+	if !VerifyConsistency(h1.Size, h2.Size, h1.Root, h2.Root, proof) {
+		t.Errorf("consistency proof between heads rejected")
+	}
+
+	// A forked history is detected by the witness.
+	fork := &Head{Size: h2.Size + 1, Root: rootOf(append(log.leaves[:3:3],
+		LeafHash([]byte("forged"), nil))), Prev: h2.Hash()}
+	proof, _ = log.ConsistencyProof(h2.Size, h2.Size)
+	if err := w.Observe(fork, proof); err != ErrEquivocation {
+		t.Errorf("forked head: got %v", err)
+	}
+	if err := w.Observe(&Head{Size: h2.Size, Root: h2.Root, Prev: h1.Hash()}, nil); err != ErrEquivocation {
+		t.Errorf("head with stale chain link: got %v", err)
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package witnesslog
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+)
+
+// Merkle tree hashing, inclusion and consistency proofs
+// following RFC 6962 and RFC 9162, Section 2.1.
+
+const (
+	leafPrefix = 0x00
+	nodePrefix = 0x01
+)
+
+func hashLeaf(data []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{leafPrefix})
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+func hashNode(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{nodePrefix})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// split returns the largest power of two smaller than n, for n > 1.
+func split(n uint64) uint64 {
+	k := uint64(1)
+	for k<<1 < n {
+		k <<= 1
+	}
+	return k
+}
+
+// rootOf computes the Merkle tree hash over a list of leaf hashes.
+func rootOf(leaves [][]byte) []byte {
+	switch len(leaves) {
+	case 0:
+		sum := sha256.Sum256(nil)
+		return sum[:]
+	case 1:
+		return leaves[0]
+	}
+	k := split(uint64(len(leaves)))
+	return hashNode(rootOf(leaves[:k]), rootOf(leaves[k:]))
+}
+
+// inclusionPath computes the audit path of leaf m among leaves.
+func inclusionPath(m uint64, leaves [][]byte) [][]byte {
+	n := uint64(len(leaves))
+	if n <= 1 {
+		return nil
+	}
+	k := split(n)
+	if m < k {
+		return append(inclusionPath(m, leaves[:k]), rootOf(leaves[k:]))
+	}
+	return append(inclusionPath(m-k, leaves[k:]), rootOf(leaves[:k]))
+}
+
+// consistencyPath computes the consistency proof
+// from the tree of the first m leaves to the tree of all leaves.
+func consistencyPath(m uint64, leaves [][]byte, complete bool) [][]byte {
+	n := uint64(len(leaves))
+	if m == n {
+		if complete {
+			return nil
+		}
+		return [][]byte{rootOf(leaves)}
+	}
+	k := split(n)
+	if m <= k {
+		return append(consistencyPath(m, leaves[:k], complete), rootOf(leaves[k:]))
+	}
+	return append(consistencyPath(m-k, leaves[k:], false), rootOf(leaves[:k]))
+}
+
+// VerifyInclusion checks that leafHash is the hash of the entry at index
+// in the tree of the given size with the given root.
+func VerifyInclusion(leafHash []byte, index, size uint64, root []byte, proof [][]byte) bool {
+	if index >= size {
+		return false
+	}
+	fn, sn := index, size-1
+	r := leafHash
+	for _, p := range proof {
+		if sn == 0 {
+			return false
+		}
+		if fn&1 == 1 || fn == sn {
+			r = hashNode(p, r)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = hashNode(r, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	return sn == 0 && subtle.ConstantTimeCompare(r, root) == 1
+}
+
+// VerifyConsistency checks that the tree of size second with root secondRoot
+// extends the tree of size first with root firstRoot.
+func VerifyConsistency(first, second uint64, firstRoot, secondRoot []byte, proof [][]byte) bool {
+	switch {
+	case first > second:
+		return false
+	case first == second:
+		return len(proof) == 0 && subtle.ConstantTimeCompare(firstRoot, secondRoot) == 1
+	case first == 0:
+		return len(proof) == 0 // the empty tree is a prefix of every tree
+	case len(proof) == 0:
+		return false
+	}
+
+	if first&(first-1) == 0 {
+		proof = append([][]byte{firstRoot}, proof...)
+	}
+	fn, sn := first-1, second-1
+	for fn&1 == 1 {
+		fn >>= 1
+		sn >>= 1
+	}
+	fr, sr := proof[0], proof[0]
+	for _, c := range proof[1:] {
+		if sn == 0 {
+			return false
+		}
+		if fn&1 == 1 || fn == sn {
+			fr = hashNode(c, fr)
+			sr = hashNode(c, sr)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			sr = hashNode(sr, c)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	return sn == 0 &&
+		subtle.ConstantTimeCompare(fr, firstRoot) == 1 &&
+		subtle.ConstantTimeCompare(sr, secondRoot) == 1
+}
@@ -0,0 +1,66 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"crypto/sha512"
+	"errors"
+	"strconv"
+
+	"golang.org/x/crypto/curve25519"
+
+	"test-server/golang-x-crypto/ed25519/internal/edwards25519"
+)
+
+// PrivateKeyToCurve25519 returns the X25519 private key
+// corresponding to privateKey: the clamped first half
+// of the SHA-512 hash of its seed, which is also its Ed25519 secret scalar.
+// Its X25519 public key is PublicKeyToCurve25519 of privateKey's public key,
+// so one identity key pair serves both for signing
+// and for Diffie-Hellman key agreement.
+// It will panic if len(privateKey) is not PrivateKeySize.
+func PrivateKeyToCurve25519(privateKey PrivateKey) []byte {
+	if l := len(privateKey); l != PrivateKeySize {
+		panic("ed25519: bad private key length: " + strconv.Itoa(l))
+	}
+	h := sha512.Sum512(privateKey[:32])
+	h[0] &= 248
+	h[31] &= 127
+	h[31] |= 64
+	return h[:32]
+}
+
+// PublicKeyToCurve25519 returns the X25519 public key
+// corresponding to publicKey, the Montgomery u-coordinate
+// (1+y)/(1-y) of its point.
+// It returns an error if publicKey is not a valid point,
+// or is of small order and so would yield an all-zero shared secret.
+func PublicKeyToCurve25519(publicKey PublicKey) ([]byte, error) {
+	var A edwards25519.ExtendedGroupElement
+	if !decodePoint(&A, publicKey) {
+		return nil, errors.New("ed25519: invalid public key")
+	}
+	var b [32]byte
+	copy(b[:], publicKey)
+	var y, one, num, den, u edwards25519.FieldElement
+	edwards25519.FeFromBytes(&y, &b)
+	edwards25519.FeOne(&one)
+	edwards25519.FeAdd(&num, &one, &y)
+	edwards25519.FeSub(&den, &one, &y)
+	edwards25519.FeInvert(&den, &den)
+	edwards25519.FeMul(&u, &num, &den)
+	var out [32]byte
+	edwards25519.FeToBytes(&out, &u)
+
+	// A multiple of the cofactor, after clamping,
+	// maps every small-order point to the all-zero output.
+	var scalar [32]byte
+	scalar[0] = 8
+	scalar[31] = 64
+	if _, err := curve25519.X25519(scalar[:], out[:]); err != nil {
+		return nil, errors.New("ed25519: public key of small order")
+	}
+	return out[:], nil
+}
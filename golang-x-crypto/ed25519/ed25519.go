@@ -6,7 +6,15 @@
 // http://ed25519.cr.yp.to/.
 //
 // These functions are also compatible with the “Ed25519” function defined in
-// https://tools.ietf.org/html/draft-irtf-cfrg-eddsa-05.
+// RFC 8032. The Ed25519ph and Ed25519ctx variants of RFC 8032 are selected
+// with Options, accepted by PrivateKey.Sign and VerifyWithOptions.
+//
+// The package is a superset of crypto/ed25519: keys and signatures are
+// interchangeable with those of the standard library, and every function of
+// the standard library package is available here with the same semantics.
+//
+// The package builds for js/wasm and wasip1, and with TinyGo, under which
+// the X.509, PKCS #8 and OpenSSH encodings are left out.
 package ed25519
 
 // This code is a port of the public domain, “ref10” implementation of ed25519
@@ -14,6 +22,7 @@ package ed25519
 
 import (
 	"crypto"
+	stded25519 "crypto/ed25519"
 	cryptorand "crypto/rand"
 	"crypto/sha512"
 	"crypto/subtle"
@@ -32,11 +41,37 @@ const (
 	PrivateKeySize = 64
 	// SignatureSize is the size, in bytes, of signatures generated and verified by this package.
 	SignatureSize = 64
+	// SeedSize is the size, in bytes, of private key seeds. These are the private key representations used by RFC 8032.
+	SeedSize = 32
 )
 
+// Backend reports which implementation of the curve arithmetic is in use,
+// such as "radix51+avx2", "radix51", "radix51-arm64" or "ref10". It is
+// chosen at build time by GOARCH and the purego and ed25519_ref10 build
+// tags, and on amd64 also at run time by the CPU features; building with
+// purego forces the portable Go implementation.
+func Backend() string {
+	return edwards25519.Backend()
+}
+
 // PublicKey is the type of Ed25519 public keys.
 type PublicKey []byte
 
+// Equal reports whether pub and x have the same value. x may be a
+// PublicKey from this package or from crypto/ed25519.
+func (pub PublicKey) Equal(x crypto.PublicKey) bool {
+	var xx []byte
+	switch x := x.(type) {
+	case PublicKey:
+		xx = x
+	case stded25519.PublicKey:
+		xx = x
+	default:
+		return false
+	}
+	return subtle.ConstantTimeCompare(pub, xx) == 1
+}
+
 // PrivateKey is the type of Ed25519 private keys. It implements crypto.Signer.
 type PrivateKey []byte
 
@@ -47,34 +82,207 @@ func (priv PrivateKey) Public() crypto.PublicKey {
 	return PublicKey(publicKey)
 }
 
-// Sign signs the given message with priv.
-// Ed25519 performs two passes over messages to be signed and therefore cannot
-// handle pre-hashed messages. Thus opts.HashFunc() must return zero to
-// indicate the message hasn't been hashed. This can be achieved by passing
-// crypto.Hash(0) as the value for opts.
+// Equal reports whether priv and x have the same value. x may be a
+// PrivateKey from this package or from crypto/ed25519.
+func (priv PrivateKey) Equal(x crypto.PrivateKey) bool {
+	var xx []byte
+	switch x := x.(type) {
+	case PrivateKey:
+		xx = x
+	case stded25519.PrivateKey:
+		xx = x
+	default:
+		return false
+	}
+	return subtle.ConstantTimeCompare(priv, xx) == 1
+}
+
+// Seed returns the private key seed corresponding to priv. It is provided for
+// interoperability with RFC 8032. RFC 8032's private keys correspond to seeds
+// in this package.
+func (priv PrivateKey) Seed() []byte {
+	seed := make([]byte, SeedSize)
+	copy(seed, priv[:32])
+	return seed
+}
+
+// Sign signs the given message with priv. rand is ignored,
+// unless opts is an *Options with Hedged set.
+//
+// If opts.HashFunc() is crypto.SHA512, the pre-hashed variant Ed25519ph is used
+// and message is expected to be a SHA-512 hash, otherwise opts.HashFunc() must
+// be crypto.Hash(0) and the message must not be hashed, as Ed25519 performs two
+// passes over messages to be signed.
+//
+// A value of type Options can be used as opts, or crypto.Hash(0) or
+// crypto.SHA512 directly to select plain Ed25519 or Ed25519ph, respectively.
+// An Options value with a non-empty Context selects Ed25519ctx,
+// or Ed25519ph with that context.
+//
+// Unlike the package-level Sign, it returns ErrPrivateKeySize
+// rather than panicking if len(priv) is not PrivateKeySize.
 func (priv PrivateKey) Sign(rand io.Reader, message []byte, opts crypto.SignerOpts) (signature []byte, err error) {
-	if opts.HashFunc() != crypto.Hash(0) {
-		return nil, errors.New("ed25519: cannot sign hashed message")
+	if len(priv) != PrivateKeySize {
+		return nil, ErrPrivateKeySize
+	}
+	domPrefix, context, noise, err := signerOpts(rand, message, opts)
+	if err != nil {
+		return nil, err
+	}
+	return sign(priv, message, domPrefix, context, noise), nil
+}
+
+// signerOpts returns the domain separation prefix, context
+// and hedging noise that opts selects for signing message,
+// as documented on PrivateKey.Sign.
+func signerOpts(rand io.Reader, message []byte, opts crypto.SignerOpts) (domPrefix, context string, noise []byte, err error) {
+	hash := opts.HashFunc()
+	if opts, ok := opts.(*Options); ok {
+		context = opts.Context
+		if opts.Hedged {
+			if rand == nil {
+				rand = cryptorand.Reader
+			}
+			noise = make([]byte, hedgeNoiseSize)
+			if _, err := io.ReadFull(rand, noise); err != nil {
+				return "", "", nil, err
+			}
+		}
 	}
+	switch {
+	case hash == crypto.SHA512: // Ed25519ph
+		if l := len(message); l != sha512.Size {
+			return "", "", nil, errors.New("ed25519: bad Ed25519ph message hash length: " + strconv.Itoa(l))
+		}
+		if l := len(context); l > 255 {
+			return "", "", nil, errors.New("ed25519: bad Ed25519ph context length: " + strconv.Itoa(l))
+		}
+		return domPrefixPh, context, noise, nil
+	case hash == crypto.Hash(0) && context != "": // Ed25519ctx
+		if l := len(context); l > 255 {
+			return "", "", nil, errors.New("ed25519: bad Ed25519ctx context length: " + strconv.Itoa(l))
+		}
+		return domPrefixCtx, context, noise, nil
+	case hash == crypto.Hash(0): // Ed25519
+		return domPrefixPure, "", noise, nil
+	default:
+		return "", "", nil, errors.New("ed25519: expected opts.HashFunc() zero (unhashed message, for standard Ed25519) or SHA-512 (for Ed25519ph)")
+	}
+}
 
-	return Sign(priv, message), nil
+// Options can be used with PrivateKey.Sign or VerifyWithOptions
+// to select Ed25519 variants.
+type Options struct {
+	// Hash can be zero for regular Ed25519, or crypto.SHA512 for Ed25519ph.
+	Hash crypto.Hash
+
+	// Context, if not empty, selects Ed25519ctx or provides the context string
+	// for Ed25519ph. It can be at most 255 bytes in length.
+	Context string
+
+	// Mode selects the validation rules applied by VerifyWithOptions.
+	// It is ignored when signing.
+	Mode VerifyMode
+
+	// Hedged, if true, makes PrivateKey.Sign mix 64 bytes read from its
+	// rand argument, or crypto/rand if that is nil, into the nonce,
+	// as in draft-irtf-cfrg-det-sigs-with-noise.
+	// The signature is no longer deterministic, but it verifies as usual,
+	// and the nonce stays secret and unique even if the randomness fails
+	// or repeats, as after a virtual machine snapshot is restored.
+	// Unlike purely deterministic signing, a hedged signer
+	// does not compute the same nonce twice for the same message,
+	// which defeats fault attacks that compare a correct signature
+	// with a faulty one.
+	// It is ignored by VerifyWithOptions.
+	Hedged bool
 }
 
+// VerifyMode selects the rules deciding signature validity
+// in the edge cases where Ed25519 implementations disagree:
+// non-canonical encodings, small-order points and the choice
+// between the cofactored and cofactorless verification equations.
+// Signatures produced by Sign are valid under every mode.
+type VerifyMode int
+
+const (
+	// VerifyDefault applies the rules of Verify:
+	// the cofactorless equation [S]B = R + [k]A,
+	// checked by comparing the encoding of R.
+	VerifyDefault VerifyMode = iota
+
+	// VerifyZIP215 applies the rules of ZIP 215
+	// (https://zips.z.cash/zip-0215), under which every implementation
+	// reaches the same decision on every input, as consensus systems require:
+	// A and R may use non-canonical encodings of the y-coordinate
+	// and the negative-zero x-coordinate,
+	// S must be canonical (less than the group order),
+	// and the cofactored equation [8][S]B = [8]R + [8][k]A is checked,
+	// with k computed over the encodings of R and A as given.
+	VerifyZIP215
+
+	// VerifyStrict applies the rules of Verify and additionally rejects
+	// the inputs that make signatures malleable or not binding:
+	// a non-canonical S, non-canonical encodings of A or R,
+	// and an A or R of small order.
+	// Under these rules, a public key has exactly one valid signature
+	// encoding for each signing event.
+	// See IsCanonicalScalar, IsCanonicalPoint and IsSmallOrder.
+	VerifyStrict
+)
+
+var (
+	_ crypto.Signer     = PrivateKey(nil)
+	_ crypto.SignerOpts = (*Options)(nil)
+)
+
+// HashFunc returns o.Hash.
+func (o *Options) HashFunc() crypto.Hash { return o.Hash }
+
 // GenerateKey generates a public/private key pair using entropy from rand.
 // If rand is nil, crypto/rand.Reader will be used.
+// On devices with a questionable random number generator, rand can be
+// an entropy.Provider, which mixes several health-tested sources.
 func GenerateKey(rand io.Reader) (publicKey PublicKey, privateKey PrivateKey, err error) {
 	if rand == nil {
 		rand = cryptorand.Reader
 	}
 
-	privateKey = make([]byte, PrivateKeySize)
-	publicKey = make([]byte, PublicKeySize)
-	_, err = io.ReadFull(rand, privateKey[:32])
-	if err != nil {
+	seed := make([]byte, SeedSize)
+	if _, err := io.ReadFull(rand, seed); err != nil {
 		return nil, nil, err
 	}
 
-	digest := sha512.Sum512(privateKey[:32])
+	privateKey = NewKeyFromSeed(seed)
+	publicKey = make([]byte, PublicKeySize)
+	copy(publicKey, privateKey[32:])
+
+	return publicKey, privateKey, nil
+}
+
+// NewKeyFromSeed calculates a private key from a seed. It will panic if
+// len(seed) is not SeedSize. This function is provided for interoperability
+// with RFC 8032. RFC 8032's private keys correspond to seeds in this
+// package.
+func NewKeyFromSeed(seed []byte) PrivateKey {
+	if l := len(seed); l != SeedSize {
+		panic("ed25519: bad seed length: " + strconv.Itoa(l))
+	}
+	return newKeyFromSeed(seed)
+}
+
+// NewKeyFromSeedErr is like NewKeyFromSeed, but returns ErrSeedSize
+// instead of panicking if len(seed) is not SeedSize.
+func NewKeyFromSeedErr(seed []byte) (PrivateKey, error) {
+	if len(seed) != SeedSize {
+		return nil, ErrSeedSize
+	}
+	return newKeyFromSeed(seed), nil
+}
+
+func newKeyFromSeed(seed []byte) PrivateKey {
+
+	digest := sha512.Sum512(seed)
 	digest[0] &= 248
 	digest[31] &= 127
 	digest[31] |= 64
@@ -86,32 +294,110 @@ func GenerateKey(rand io.Reader) (publicKey PublicKey, privateKey PrivateKey, er
 	var publicKeyBytes [32]byte
 	A.ToBytes(&publicKeyBytes)
 
+	privateKey := make([]byte, PrivateKeySize)
+	copy(privateKey, seed)
 	copy(privateKey[32:], publicKeyBytes[:])
-	copy(publicKey, publicKeyBytes[:])
 
-	return publicKey, privateKey, nil
+	return privateKey
 }
 
 // Sign signs the message with privateKey and returns a signature. It will
 // panic if len(privateKey) is not PrivateKeySize.
 func Sign(privateKey PrivateKey, message []byte) []byte {
+	return sign(privateKey, message, domPrefixPure, "", nil)
+}
+
+// AppendSign appends the signature of message by privateKey to dst and
+// returns the extended slice. It will panic if len(privateKey) is not
+// PrivateKeySize. It does not allocate if dst has room for SignatureSize
+// more bytes, for servers signing at rates where Sign's allocation of
+// the returned signature shows up in garbage collection.
+func AppendSign(dst []byte, privateKey PrivateKey, message []byte) []byte {
+	return appendSign(dst, privateKey, message, domPrefixPure, "", nil)
+}
+
+// SignErr is like Sign, but returns ErrPrivateKeySize
+// instead of panicking if len(privateKey) is not PrivateKeySize,
+// for servers signing with keys they did not create.
+func SignErr(privateKey PrivateKey, message []byte) ([]byte, error) {
+	if len(privateKey) != PrivateKeySize {
+		return nil, ErrPrivateKeySize
+	}
+	return sign(privateKey, message, domPrefixPure, "", nil), nil
+}
+
+// Domain separation prefixes used to disambiguate Ed25519/Ed25519ph.
+// See RFC 8032, Section 2 and Section 5.1.
+const (
+	// domPrefixPure is empty for pure Ed25519.
+	domPrefixPure = ""
+	// domPrefixPh is dom2(phflag=1) for Ed25519ph. It must be followed by ctx.
+	domPrefixPh = "SigEd25519 no Ed25519 collisions\x01"
+	// domPrefixCtx is dom2(phflag=0) for Ed25519ctx. It must be followed by ctx.
+	domPrefixCtx = "SigEd25519 no Ed25519 collisions\x00"
+)
+
+// dom2 returns dom2(phflag, context) as domPrefix selects it, or nothing
+// for pure Ed25519, in buf: hashing the prefix as a []byte conversion
+// would allocate, as it is longer than the compiler's stack buffer.
+func dom2(buf *[len(domPrefixPh) + 1 + 255]byte, domPrefix, context string) []byte {
+	if domPrefix == domPrefixPure {
+		return nil
+	}
+	n := copy(buf[:], domPrefix)
+	buf[n] = byte(len(context))
+	n++
+	n += copy(buf[n:], context)
+	return buf[:n]
+}
+
+// hedgeNoiseSize is the size of the randomness mixed into hedged nonces.
+const hedgeNoiseSize = 64
+
+func sign(privateKey PrivateKey, message []byte, domPrefix, context string, noise []byte) []byte {
+	return appendSign(make([]byte, 0, SignatureSize), privateKey, message, domPrefix, context, noise)
+}
+
+func appendSign(dst []byte, privateKey PrivateKey, message []byte, domPrefix, context string, noise []byte) []byte {
 	if l := len(privateKey); l != PrivateKeySize {
 		panic("ed25519: bad private key length: " + strconv.Itoa(l))
 	}
 
-	h := sha512.New()
-	h.Write(privateKey[:32])
-
-	var digest1, messageDigest, hramDigest [64]byte
+	digest1 := sha512.Sum512(privateKey[:32])
 	var expandedSecretKey [32]byte
-	h.Sum(digest1[:0])
 	copy(expandedSecretKey[:], digest1[:])
 	expandedSecretKey[0] &= 248
 	expandedSecretKey[31] &= 63
 	expandedSecretKey[31] |= 64
 
-	h.Reset()
-	h.Write(digest1[32:])
+	dst = appendSignExpanded(dst, &expandedSecretKey, digest1[32:], privateKey[32:], message, domPrefix, context, noise)
+	clear(digest1[:])
+	clear(expandedSecretKey[:])
+	return dst
+}
+
+// signExpanded signs message with the secret scalar, nonce prefix
+// and public key of an expanded private key.
+// If noise is not nil, it is hashed into the nonce ahead of the prefix,
+// and the two are zero-padded to the SHA-512 block size.
+func signExpanded(scalar *[32]byte, prefix, publicKey, message []byte, domPrefix, context string, noise []byte) []byte {
+	return appendSignExpanded(make([]byte, 0, SignatureSize), scalar, prefix, publicKey, message, domPrefix, context, noise)
+}
+
+// appendSignExpanded is like signExpanded,
+// but appends the signature to dst.
+func appendSignExpanded(dst []byte, scalar *[32]byte, prefix, publicKey, message []byte, domPrefix, context string, noise []byte) []byte {
+	h := sha512.New()
+	var messageDigest, hramDigest [64]byte
+	var dom [len(domPrefixPh) + 1 + 255]byte
+	h.Write(dom2(&dom, domPrefix, context))
+	if noise != nil {
+		h.Write(noise)
+		h.Write(prefix)
+		h.Write(make([]byte, h.BlockSize()-len(noise)-len(prefix)))
+	} else {
+		h.Write(prefix)
+	}
 	h.Write(message)
 	h.Sum(messageDigest[:0])
 
@@ -124,21 +410,22 @@ func Sign(privateKey PrivateKey, message []byte) []byte {
 	R.ToBytes(&encodedR)
 
 	h.Reset()
+	h.Write(dom2(&dom, domPrefix, context))
 	h.Write(encodedR[:])
-	h.Write(privateKey[32:])
+	h.Write(publicKey)
 	h.Write(message)
 	h.Sum(hramDigest[:0])
 	var hramDigestReduced [32]byte
 	edwards25519.ScReduce(&hramDigestReduced, &hramDigest)
 
 	var s [32]byte
-	edwards25519.ScMulAdd(&s, &hramDigestReduced, &expandedSecretKey, &messageDigestReduced)
+	edwards25519.ScMulAdd(&s, &hramDigestReduced, scalar, &messageDigestReduced)
+	// the nonce gives away the secret scalar along with the signature
+	clear(messageDigest[:])
+	clear(messageDigestReduced[:])
 
-	signature := make([]byte, SignatureSize)
-	copy(signature[:], encodedR[:])
-	copy(signature[32:], s[:])
-
-	return signature
+	dst = append(dst, encodedR[:]...)
+	return append(dst, s[:]...)
 }
 
 // Verify reports whether sig is a valid signature of message by publicKey. It
@@ -147,21 +434,156 @@ func Verify(publicKey PublicKey, message, sig []byte) bool {
 	if l := len(publicKey); l != PublicKeySize {
 		panic("ed25519: bad public key length: " + strconv.Itoa(l))
 	}
+	return verify(publicKey, nil, message, sig, domPrefixPure, "", VerifyDefault) == nil
+}
 
-	if len(sig) != SignatureSize || sig[63]&224 != 0 {
-		return false
+var (
+	// ErrPublicKeySize is returned by VerifyErr
+	// for a public key that is not PublicKeySize bytes long.
+	ErrPublicKeySize = errors.New("ed25519: bad public key length")
+
+	// ErrPrivateKeySize is returned by SignErr and PrivateKey.Sign
+	// for a private key that is not PrivateKeySize bytes long.
+	ErrPrivateKeySize = errors.New("ed25519: bad private key length")
+
+	// ErrSeedSize is returned by NewKeyFromSeedErr
+	// for a seed that is not SeedSize bytes long.
+	ErrSeedSize = errors.New("ed25519: bad seed length")
+
+	// ErrSignatureSize is returned by VerifyErr and VerifyWithOptions
+	// for a signature that is not SignatureSize bytes long.
+	ErrSignatureSize = errors.New("ed25519: bad signature length")
+
+	// ErrNonCanonical is returned by VerifyErr and VerifyWithOptions
+	// for a signature or public key using an encoding
+	// the verification mode does not accept:
+	// an S with any of its top three bits set in every mode,
+	// an S not less than the group order in VerifyZIP215 and VerifyStrict,
+	// and a non-canonical point encoding in VerifyStrict.
+	ErrNonCanonical = errors.New("ed25519: non-canonical encoding")
+
+	// ErrInvalidPoint is returned by VerifyErr and VerifyWithOptions
+	// for a public key, or the R half of a signature
+	// in VerifyZIP215 and VerifyStrict, that does not encode a curve point.
+	ErrInvalidPoint = errors.New("ed25519: invalid curve point")
+
+	// ErrSmallOrder is returned by VerifyWithOptions in VerifyStrict
+	// for a public key or R of small order,
+	// and by ValidatePublicKey for a public key of small order,
+	// the identity included.
+	ErrSmallOrder = errors.New("ed25519: small-order point")
+
+	// ErrTorsion is returned by ValidatePublicKey for a public key
+	// outside the prime-order subgroup:
+	// the sum of a valid key and a point of small order.
+	ErrTorsion = errors.New("ed25519: point has a small-order component")
+
+	// ErrInvalidSignature is returned by VerifyErr and VerifyWithOptions
+	// for a well-formed signature that does not satisfy
+	// the verification equation for the message and public key.
+	ErrInvalidSignature = errors.New("ed25519: invalid signature")
+)
+
+// VerifyErr is like Verify, but reports why a signature was rejected
+// by returning ErrPublicKeySize, ErrSignatureSize, ErrNonCanonical,
+// ErrInvalidPoint or ErrInvalidSignature, and does not panic
+// on a public key of the wrong length.
+// It returns nil for a valid signature.
+func VerifyErr(publicKey PublicKey, message, sig []byte) error {
+	return verify(publicKey, nil, message, sig, domPrefixPure, "", VerifyDefault)
+}
+
+// VerifyWithOptions reports whether sig is a valid signature of message by
+// publicKey. A valid signature is indicated by returning a nil error;
+// an invalid one by one of the errors returned by VerifyErr, or ErrSmallOrder.
+// It will panic if len(publicKey) is not PublicKeySize;
+// for keys of unchecked length, NewExpandedPublicKey
+// returns ErrPublicKeySize instead.
+//
+// If opts.Hash is crypto.SHA512, the pre-hashed variant Ed25519ph is used and
+// message is expected to be a SHA-512 hash, otherwise opts.Hash must be
+// crypto.Hash(0) and the message must not be hashed, as Ed25519 performs two
+// passes over messages to be signed.
+//
+// opts.Mode selects the validation rules; see VerifyMode.
+//
+// A nil opts is equivalent to &Options{}, selecting plain Ed25519.
+func VerifyWithOptions(publicKey PublicKey, message, sig []byte, opts *Options) error {
+	if l := len(publicKey); l != PublicKeySize {
+		panic("ed25519: bad public key length: " + strconv.Itoa(l))
 	}
+	return verifyWithOptions(publicKey, nil, message, sig, opts)
+}
 
-	var A edwards25519.ExtendedGroupElement
-	var publicKeyBytes [32]byte
-	copy(publicKeyBytes[:], publicKey)
-	if !A.FromBytes(&publicKeyBytes) {
-		return false
+// verifyWithOptions implements VerifyWithOptions,
+// using the decoded public key if expanded is not nil.
+func verifyWithOptions(publicKey PublicKey, expanded *ExpandedPublicKey, message, sig []byte, opts *Options) error {
+	if opts == nil {
+		opts = &Options{}
+	}
+	if opts.Mode < VerifyDefault || opts.Mode > VerifyStrict {
+		return errors.New("ed25519: unknown verification mode " + strconv.Itoa(int(opts.Mode)))
+	}
+	switch {
+	case opts.Hash == crypto.SHA512: // Ed25519ph
+		if l := len(message); l != sha512.Size {
+			return errors.New("ed25519: bad Ed25519ph message hash length: " + strconv.Itoa(l))
+		}
+		if l := len(opts.Context); l > 255 {
+			return errors.New("ed25519: bad Ed25519ph context length: " + strconv.Itoa(l))
+		}
+		return verify(publicKey, expanded, message, sig, domPrefixPh, opts.Context, opts.Mode)
+	case opts.Hash == crypto.Hash(0) && opts.Context != "": // Ed25519ctx
+		if l := len(opts.Context); l > 255 {
+			return errors.New("ed25519: bad Ed25519ctx context length: " + strconv.Itoa(l))
+		}
+		return verify(publicKey, expanded, message, sig, domPrefixCtx, opts.Context, opts.Mode)
+	case opts.Hash == crypto.Hash(0): // Ed25519
+		return verify(publicKey, expanded, message, sig, domPrefixPure, "", opts.Mode)
+	default:
+		return errors.New("ed25519: expected opts.Hash zero (unhashed message, for standard Ed25519) or SHA-512 (for Ed25519ph)")
+	}
+}
+
+// verify checks sig under mode. If expanded is not nil,
+// it holds publicKey decoded, so that it need not be decoded again.
+func verify(publicKey PublicKey, expanded *ExpandedPublicKey, message, sig []byte, domPrefix, context string, mode VerifyMode) error {
+	if len(publicKey) != PublicKeySize {
+		return ErrPublicKeySize
+	}
+	if len(sig) != SignatureSize {
+		return ErrSignatureSize
+	}
+	if sig[63]&224 != 0 {
+		return ErrNonCanonical
+	}
+	switch mode {
+	case VerifyZIP215:
+		return verifyCofactored(publicKey, message, sig, domPrefix, context)
+	case VerifyStrict:
+		if err := checkStrict(publicKey, sig); err != nil {
+			return err
+		}
+	}
+
+	var table edwards25519.DoubleScalarMultTable
+	negATable := &table
+	switch {
+	case expanded != nil && expanded.table != nil:
+		negATable = expanded.table
+	case expanded != nil:
+		table.Init(&expanded.negA)
+	default:
+		var negA edwards25519.ExtendedGroupElement
+		if !decodeNegated(&negA, publicKey) {
+			return ErrInvalidPoint
+		}
+		table.Init(&negA)
 	}
-	edwards25519.FeNeg(&A.X, &A.X)
-	edwards25519.FeNeg(&A.T, &A.T)
 
 	h := sha512.New()
+	var dom [len(domPrefixPh) + 1 + 255]byte
+	h.Write(dom2(&dom, domPrefix, context))
 	h.Write(sig[:32])
 	h.Write(publicKey[:])
 	h.Write(message)
@@ -174,9 +596,54 @@ func Verify(publicKey PublicKey, message, sig []byte) bool {
 	var R edwards25519.ProjectiveGroupElement
 	var b [32]byte
 	copy(b[:], sig[32:])
-	edwards25519.GeDoubleScalarMultVartime(&R, &hReduced, &A, &b)
+	edwards25519.GeDoubleScalarMultTableVartime(&R, &hReduced, negATable, &b)
 
 	var checkR [32]byte
 	R.ToBytes(&checkR)
-	return subtle.ConstantTimeCompare(sig[:32], checkR[:]) == 1
+	if subtle.ConstantTimeCompare(sig[:32], checkR[:]) != 1 {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// verifyCofactored checks the cofactored verification equation
+// [8][S]B = [8]R + [8][k]A under the ZIP 215 rules.
+func verifyCofactored(publicKey PublicKey, message, sig []byte, domPrefix, context string) error {
+	if !IsCanonicalScalar(sig[32:]) {
+		return ErrNonCanonical
+	}
+	var s [32]byte
+	copy(s[:], sig[32:])
+
+	// decodePoint accepts non-canonical y-coordinates and negative zero,
+	// as ZIP 215 requires.
+	var A, R edwards25519.ExtendedGroupElement
+	if !decodePoint(&A, publicKey) || !decodePoint(&R, sig[:32]) {
+		return ErrInvalidPoint
+	}
+
+	h := sha512.New()
+	var dom [len(domPrefixPh) + 1 + 255]byte
+	h.Write(dom2(&dom, domPrefix, context))
+	h.Write(sig[:32])
+	h.Write(publicKey[:])
+	h.Write(message)
+	var digest [64]byte
+	h.Sum(digest[:0])
+
+	var k, one [32]byte
+	edwards25519.ScReduce(&k, &digest)
+	one[0] = 1
+
+	// check = [8]([S]B - (R + [k]A))
+	var kAR, sB, check edwards25519.ExtendedGroupElement
+	edwards25519.GeMultiScalarMultVartime(&kAR, []*[32]byte{&k, &one},
+		[]*edwards25519.ExtendedGroupElement{&A, &R})
+	edwards25519.GeScalarMultBase(&sB, &s)
+	check.Sub(&sB, &kAR)
+	check.MulByCofactor(&check)
+	if !check.IsIdentity() {
+		return ErrInvalidSignature
+	}
+	return nil
 }
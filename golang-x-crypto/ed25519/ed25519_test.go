@@ -8,13 +8,19 @@ import (
 	"bufio"
 	"bytes"
 	"compress/gzip"
+	"context"
 	"crypto"
+	stded25519 "crypto/ed25519"
 	"crypto/rand"
+	"crypto/sha512"
 	"encoding/hex"
 	"os"
+	"strconv"
 	"strings"
 	"testing"
 
+	"golang.org/x/crypto/curve25519"
+
 	//"golang.org/x/crypto/ed25519/internal/edwards25519"
 	"test-server/golang-x-crypto/ed25519/internal/edwards25519"
 )
@@ -140,6 +146,19 @@ func TestGolden(t *testing.T) {
 		if !Verify(pubKey, msg, sig2) {
 			t.Errorf("signature failed to verify on line %d", lineNo)
 		}
+
+		priv2 := NewKeyFromSeed(priv[:32])
+		if !bytes.Equal(priv[:], priv2) {
+			t.Errorf("recreating key pair gave different private key on line %d: %x vs %x", lineNo, priv[:], priv2)
+		}
+
+		if pubKey2 := priv2.Public().(PublicKey); !bytes.Equal(pubKey, pubKey2) {
+			t.Errorf("recreating key pair gave different public key on line %d: %x vs %x", lineNo, pubKey, pubKey2)
+		}
+
+		if seed := priv2.Seed(); !bytes.Equal(priv[:32], seed) {
+			t.Errorf("recreating key pair gave different seed on line %d: %x vs %x", lineNo, priv[:32], seed)
+		}
 	}
 
 	if err := scanner.Err(); err != nil {
@@ -156,6 +175,15 @@ func BenchmarkKeyGeneration(b *testing.B) {
 	}
 }
 
+func BenchmarkGenerateKeys(b *testing.B) {
+	var zero zeroReader
+	for i := 0; i < b.N; i++ {
+		if _, _, err := GenerateKeys(zero, 1000); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 func BenchmarkSigning(b *testing.B) {
 	var zero zeroReader
 	_, priv, err := GenerateKey(zero)
@@ -169,6 +197,20 @@ func BenchmarkSigning(b *testing.B) {
 	}
 }
 
+func BenchmarkSigningExpanded(b *testing.B) {
+	var zero zeroReader
+	_, priv, err := GenerateKey(zero)
+	if err != nil {
+		b.Fatal(err)
+	}
+	k := NewExpandedPrivateKey(priv)
+	message := []byte("Hello, world!")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		k.SignMessage(message)
+	}
+}
+
 func BenchmarkVerification(b *testing.B) {
 	var zero zeroReader
 	pub, priv, err := GenerateKey(zero)
@@ -182,3 +224,967 @@ func BenchmarkVerification(b *testing.B) {
 		Verify(pub, message, signature)
 	}
 }
+
+func BenchmarkVerificationExpanded(b *testing.B) {
+	var zero zeroReader
+	pub, priv, err := GenerateKey(zero)
+	if err != nil {
+		b.Fatal(err)
+	}
+	message := []byte("Hello, world!")
+	signature := Sign(priv, message)
+	k, _ := NewExpandedPublicKey(pub)
+	b.Run("Decoded", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			k.Verify(message, signature)
+		}
+	})
+	k.Precompute()
+	b.Run("Precomputed", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			k.Verify(message, signature)
+		}
+	})
+}
+
+func TestEqual(t *testing.T) {
+	public, private, _ := GenerateKey(rand.Reader)
+
+	if !public.Equal(public) {
+		t.Errorf("public key is not equal to itself: %q", public)
+	}
+	if !public.Equal(crypto.Signer(private).Public()) {
+		t.Errorf("private.Public() is not Equal to public: %q", public)
+	}
+	if !private.Equal(private) {
+		t.Errorf("private key is not equal to itself: %q", private)
+	}
+	if !public.Equal(stded25519.PublicKey(public)) || !private.Equal(stded25519.PrivateKey(private)) {
+		t.Errorf("keys not equal to their crypto/ed25519 counterparts")
+	}
+
+	otherPub, otherPriv, _ := GenerateKey(rand.Reader)
+	if public.Equal(otherPub) {
+		t.Errorf("different public keys are Equal")
+	}
+	if private.Equal(otherPriv) {
+		t.Errorf("different private keys are Equal")
+	}
+	if public.Equal([]byte(public)) {
+		t.Errorf("public key is Equal to an untyped byte slice")
+	}
+}
+
+func TestSignVerifyHashed(t *testing.T) {
+	// From RFC 8032, Section 7.3
+	key, _ := hex.DecodeString("833fe62409237b9d62ec77587520911e9a759cec1d19755b7da901b96dca3d42ec172b93ad5e563bf4932c70e1245034c35467ef2efd4d64ebf819683467e2bf")
+	expectedSig, _ := hex.DecodeString("98a70222f0b8121aa9d30f813d683f809e462b469c7ff87639499bb94e6dae4131f85042463c2a355a2003d062adf5aaa10b8c61e636062aaad11c2a26083406")
+	message, _ := hex.DecodeString("616263")
+
+	private := PrivateKey(key)
+	public := private.Public().(PublicKey)
+	hash := sha512.Sum512(message)
+	sig, err := private.Sign(nil, hash[:], crypto.SHA512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sig, expectedSig) {
+		t.Errorf("signature doesn't match test vector")
+	}
+	sig, err = private.Sign(nil, hash[:], &Options{Hash: crypto.SHA512})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sig, expectedSig) {
+		t.Errorf("signature doesn't match test vector")
+	}
+	if err := VerifyWithOptions(public, hash[:], sig, &Options{Hash: crypto.SHA512}); err != nil {
+		t.Errorf("valid signature rejected: %v", err)
+	}
+
+	wrongHash := sha512.Sum512([]byte("wrong message"))
+	if VerifyWithOptions(public, wrongHash[:], sig, &Options{Hash: crypto.SHA512}) == nil {
+		t.Errorf("signature of different message accepted")
+	}
+	if VerifyWithOptions(public, hash[:], sig, &Options{}) == nil {
+		t.Errorf("Ed25519ph signature accepted as pure Ed25519")
+	}
+	if Verify(public, hash[:], sig) {
+		t.Errorf("Ed25519ph signature accepted by Verify")
+	}
+
+	// interoperability with the standard library
+	stdSig, err := stded25519.PrivateKey(private).Sign(nil, hash[:], &stded25519.Options{Hash: crypto.SHA512})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(stdSig, sig) {
+		t.Errorf("signature differs from crypto/ed25519")
+	}
+
+	if _, err := private.Sign(nil, message, crypto.SHA512); err == nil {
+		t.Errorf("Ed25519ph accepted a message that is not a SHA-512 hash")
+	}
+	if _, err := private.Sign(nil, message, crypto.SHA256); err == nil {
+		t.Errorf("unsupported hash accepted")
+	}
+}
+
+func TestSignVerifyContext(t *testing.T) {
+	// From RFC 8032, Section 7.2
+	key, _ := hex.DecodeString("0305334e381af78f141cb666f6199f57bc3495335a256a95bd2a55bf546663f6dfc9425e4f968f7f0c29f0259cf5f9aed6851c2bb4ad8bfb860cfee0ab248292")
+	expectedSig, _ := hex.DecodeString("55a4cc2f70a54e04288c5f4cd1e45a7bb520b36292911876cada7323198dd87a8b36950b95130022907a7fb7c4e9b2d5f6cca685a587b4b21f4b888e4e7edb0d")
+	message, _ := hex.DecodeString("f726936d19c800494e3fdaff20b276a8")
+	context := "foo"
+
+	private := PrivateKey(key)
+	public := private.Public().(PublicKey)
+	sig, err := private.Sign(nil, message, &Options{Context: context})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sig, expectedSig) {
+		t.Errorf("signature doesn't match test vector")
+	}
+	if err := VerifyWithOptions(public, message, sig, &Options{Context: context}); err != nil {
+		t.Errorf("valid signature rejected: %v", err)
+	}
+
+	if VerifyWithOptions(public, []byte("wrong message"), sig, &Options{Context: context}) == nil {
+		t.Errorf("signature of different message accepted")
+	}
+	if VerifyWithOptions(public, message, sig, &Options{Context: "bar"}) == nil {
+		t.Errorf("signature with different context accepted")
+	}
+	if Verify(public, message, sig) {
+		t.Errorf("Ed25519ctx signature accepted as pure Ed25519")
+	}
+
+	// Ed25519ph with a context, against the standard library
+	hash := sha512.Sum512(message)
+	opts := &Options{Hash: crypto.SHA512, Context: context}
+	sig, err = private.Sign(nil, hash[:], opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdSig, _ := stded25519.PrivateKey(private).Sign(nil, hash[:],
+		&stded25519.Options{Hash: crypto.SHA512, Context: context})
+	if !bytes.Equal(sig, stdSig) {
+		t.Errorf("Ed25519ph signature with context differs from crypto/ed25519")
+	}
+	if err := VerifyWithOptions(public, hash[:], sig, opts); err != nil {
+		t.Errorf("valid Ed25519ph signature with context rejected: %v", err)
+	}
+
+	long := strings.Repeat("x", 256)
+	if _, err := private.Sign(nil, message, &Options{Context: long}); err == nil {
+		t.Errorf("context longer than 255 bytes accepted")
+	}
+}
+
+func TestSignHedged(t *testing.T) {
+	public, private, _ := GenerateKey(rand.Reader)
+	message := []byte("test message")
+	hash := sha512.Sum512(message)
+	for _, opts := range []*Options{
+		{Hedged: true},
+		{Hedged: true, Context: "foo"},
+		{Hedged: true, Hash: crypto.SHA512},
+	} {
+		msg := message
+		if opts.Hash == crypto.SHA512 {
+			msg = hash[:]
+		}
+		sig1, err := private.Sign(nil, msg, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig2, _ := private.Sign(rand.Reader, msg, opts)
+		if bytes.Equal(sig1, sig2) {
+			t.Errorf("hedged signatures are deterministic")
+		}
+		for _, sig := range [][]byte{sig1, sig2} {
+			if err := VerifyWithOptions(public, msg, sig, opts); err != nil {
+				t.Errorf("hedged signature rejected: %v", err)
+			}
+		}
+	}
+
+	// with the same randomness, the nonce is the same
+	sig1, _ := private.Sign(zeroReader{}, message, &Options{Hedged: true})
+	sig2, _ := private.Sign(zeroReader{}, message, &Options{Hedged: true})
+	if !bytes.Equal(sig1, sig2) {
+		t.Errorf("hedged signatures with equal randomness differ")
+	}
+	if bytes.Equal(sig1, Sign(private, message)) {
+		t.Errorf("hedged signature equals the deterministic one")
+	}
+
+	if _, err := private.Sign(strings.NewReader("short"), message, &Options{Hedged: true}); err == nil {
+		t.Errorf("failing randomness source not reported")
+	}
+}
+
+func TestSignHardened(t *testing.T) {
+	public, private, _ := GenerateKey(rand.Reader)
+	message := []byte("test message")
+	sig1, err := SignHardened(private, message, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig2, _ := SignHardened(private, message, rand.Reader)
+	if bytes.Equal(sig1, sig2) {
+		t.Errorf("hardened signatures are deterministic")
+	}
+	for _, sig := range [][]byte{sig1, sig2} {
+		if err := VerifyWithOptions(public, message, sig, &Options{Mode: VerifyStrict}); err != nil {
+			t.Errorf("hardened signature rejected: %v", err)
+		}
+	}
+
+	// Blinding changes the computation, not its result.
+	hardened, _ := SignHardened(private, message, zeroReader{})
+	hedged, _ := private.Sign(zeroReader{}, message, &Options{Hedged: true})
+	if !bytes.Equal(hardened, hedged) {
+		t.Errorf("hardened signature differs from the hedged one with the same nonce")
+	}
+
+	if _, err := SignHardened(private, message, strings.NewReader("short")); err == nil {
+		t.Errorf("failing randomness source not reported")
+	}
+}
+
+func TestSensitiveKey(t *testing.T) {
+	public, private, _ := GenerateKey(rand.Reader)
+	message := []byte("test message")
+	k := NewSensitiveKey(private)
+	var _ crypto.Signer = k
+	if !public.Equal(k.Public()) {
+		t.Errorf("public key mismatch")
+	}
+	sig, err := k.Sign(nil, message, crypto.Hash(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sig, Sign(private, message)) {
+		t.Errorf("signature differs from Sign")
+	}
+	opts := &Options{Context: "foo"}
+	sig, _ = k.Sign(nil, message, opts)
+	if want, _ := private.Sign(nil, message, opts); !bytes.Equal(sig, want) {
+		t.Errorf("Ed25519ctx signature differs from PrivateKey.Sign")
+	}
+
+	if err := k.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := k.Sign(nil, message, crypto.Hash(0)); err != ErrKeyClosed {
+		t.Errorf("Sign after Close: err = %v, want ErrKeyClosed", err)
+	}
+	if !public.Equal(k.Public()) {
+		t.Errorf("public key lost on Close")
+	}
+	k.Close()
+
+	private.Zeroize()
+	if !bytes.Equal(private, make([]byte, PrivateKeySize)) {
+		t.Errorf("Zeroize left %x", private)
+	}
+
+	k, err = GenerateSensitiveKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer k.Close()
+	sig, _ = k.Sign(nil, message, crypto.Hash(0))
+	if !Verify(k.Public().(PublicKey), message, sig) {
+		t.Errorf("signature by generated key rejected")
+	}
+}
+
+// faultyBackend corrupts the signatures of a working backend.
+type faultyBackend struct{ RemoteBackend }
+
+func (b faultyBackend) SignEd25519(ctx context.Context, message []byte) ([]byte, error) {
+	sig, err := b.RemoteBackend.SignEd25519(ctx, message)
+	if err == nil {
+		sig[0] ^= 1
+	}
+	return sig, err
+}
+
+func TestRemoteSigner(t *testing.T) {
+	ctx := context.Background()
+	public, private, _ := GenerateKey(rand.Reader)
+	message := []byte("test message")
+	s, err := NewRemoteSigner(ctx, SignerBackend(private))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var _ crypto.Signer = s
+	if !public.Equal(s.Public()) {
+		t.Errorf("public key mismatch")
+	}
+	sig, err := s.Sign(nil, message, crypto.Hash(0))
+	if err != nil || !bytes.Equal(sig, Sign(private, message)) {
+		t.Errorf("Sign = %x, %v; want the signature of Sign", sig, err)
+	}
+	if _, err := s.Sign(nil, message, crypto.SHA512); err == nil {
+		t.Errorf("Ed25519ph accepted")
+	}
+	if _, err := s.Sign(nil, message, &Options{Context: "foo"}); err == nil {
+		t.Errorf("Ed25519ctx accepted")
+	}
+	if sig, _ := SignContext(ctx, private, message); !Verify(public, message, sig) {
+		t.Errorf("SignContext with a PrivateKey produced an invalid signature")
+	}
+
+	// The standard library's keys work as backends too.
+	stdPublic, stdPrivate, _ := stded25519.GenerateKey(rand.Reader)
+	s, err = NewRemoteSigner(ctx, SignerBackend(stdPrivate))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sig, _ := SignContext(ctx, s, message); !stded25519.Verify(stdPublic, message, sig) {
+		t.Errorf("signature by crypto/ed25519 backend rejected")
+	}
+
+	s, _ = NewRemoteSigner(ctx, faultyBackend{SignerBackend(private)})
+	if _, err := s.SignContext(ctx, message); err != ErrInvalidSignature {
+		t.Errorf("faulty backend: err = %v, want ErrInvalidSignature", err)
+	}
+}
+
+func TestExpandedPublicKey(t *testing.T) {
+	public, private, _ := GenerateKey(rand.Reader)
+	message := []byte("test message")
+	sig := Sign(private, message)
+	k, err := NewExpandedPublicKey(public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !public.Equal(k.PublicKey()) {
+		t.Errorf("public key mismatch")
+	}
+	for _, k := range []*ExpandedPublicKey{k, k.Precompute()} {
+		if !k.Verify(message, sig) {
+			t.Errorf("valid signature rejected")
+		}
+		wrong := append([]byte{}, sig...)
+		wrong[10] ^= 1
+		if err := k.VerifyErr(message, wrong); err != ErrInvalidSignature {
+			t.Errorf("VerifyErr(wrong signature) = %v, want ErrInvalidSignature", err)
+		}
+		if k.Verify([]byte("other message"), sig) {
+			t.Errorf("signature of other message accepted")
+		}
+		opts := &Options{Context: "foo", Mode: VerifyStrict}
+		ctxSig, _ := private.Sign(nil, message, opts)
+		if err := k.VerifyWithOptions(message, ctxSig, opts); err != nil {
+			t.Errorf("Ed25519ctx signature rejected: %v", err)
+		}
+		if err := k.VerifyWithOptions(message, sig, opts); err == nil {
+			t.Errorf("Ed25519 signature accepted as Ed25519ctx")
+		}
+	}
+
+	if _, err := NewExpandedPublicKey(public[:31]); err != ErrPublicKeySize {
+		t.Errorf("short key: err = %v, want ErrPublicKeySize", err)
+	}
+	bad, _ := hex.DecodeString("0200000000000000000000000000000000000000000000000000000000000000")
+	if _, err := NewExpandedPublicKey(bad); err != ErrInvalidPoint {
+		t.Errorf("invalid key: err = %v, want ErrInvalidPoint", err)
+	}
+}
+
+func TestExpandedPrivateKey(t *testing.T) {
+	public, private, _ := GenerateKey(rand.Reader)
+	message := []byte("test message")
+	k := NewExpandedPrivateKey(private)
+	var _ crypto.Signer = k
+	if !public.Equal(k.Public()) {
+		t.Errorf("public key mismatch")
+	}
+	if sig := k.SignMessage(message); !bytes.Equal(sig, Sign(private, message)) {
+		t.Errorf("SignMessage differs from Sign")
+	}
+	for _, opts := range []crypto.SignerOpts{crypto.Hash(0), &Options{Context: "foo"}} {
+		sig, err := k.Sign(nil, message, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, _ := private.Sign(nil, message, opts); !bytes.Equal(sig, want) {
+			t.Errorf("Sign with %v differs from PrivateKey.Sign", opts)
+		}
+	}
+	if _, err := k.Sign(nil, message, crypto.SHA512); err == nil {
+		t.Errorf("Ed25519ph signing of an unhashed message accepted")
+	}
+	k.Zeroize()
+	if k.scalar != [32]byte{} || k.prefix != [32]byte{} {
+		t.Errorf("Zeroize left secrets behind")
+	}
+}
+
+func TestVerifyWithOptionsStdlib(t *testing.T) {
+	public, private, _ := GenerateKey(rand.Reader)
+	stdPriv := stded25519.PrivateKey(private)
+	message := []byte("test message")
+	hash := sha512.Sum512(message)
+
+	for _, test := range []struct {
+		name    string
+		message []byte
+		opts    *Options
+	}{
+		{"Ed25519", message, &Options{}},
+		{"Ed25519ctx", message, &Options{Context: "cosi"}},
+		{"Ed25519ph", hash[:], &Options{Hash: crypto.SHA512}},
+		{"Ed25519ph with context", hash[:], &Options{Hash: crypto.SHA512, Context: "cosi"}},
+	} {
+		stdOpts := &stded25519.Options{Hash: test.opts.Hash, Context: test.opts.Context}
+		stdSig, err := stdPriv.Sign(nil, test.message, stdOpts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := VerifyWithOptions(public, test.message, stdSig, test.opts); err != nil {
+			t.Errorf("%s: crypto/ed25519 signature rejected: %v", test.name, err)
+		}
+		sig, err := private.Sign(nil, test.message, test.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := stded25519.VerifyWithOptions(stded25519.PublicKey(public), test.message, sig, stdOpts); err != nil {
+			t.Errorf("%s: signature rejected by crypto/ed25519: %v", test.name, err)
+		}
+	}
+
+	sig := Sign(private, message)
+	if err := VerifyWithOptions(public, message, sig, nil); err != nil {
+		t.Errorf("nil options: %v", err)
+	}
+	if err := VerifyWithOptions(public, message, sig, &Options{Hash: crypto.SHA256}); err == nil {
+		t.Errorf("unsupported hash accepted")
+	}
+	sig[0] ^= 1
+	if err := VerifyWithOptions(public, message, sig, nil); err == nil {
+		t.Errorf("invalid signature accepted")
+	}
+}
+
+func batchInput(tb testing.TB, n int) ([]PublicKey, [][]byte, [][]byte) {
+	pubs := make([]PublicKey, n)
+	msgs := make([][]byte, n)
+	sigs := make([][]byte, n)
+	for i := range pubs {
+		pub, priv, err := GenerateKey(rand.Reader)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		pubs[i] = pub
+		msgs[i] = []byte("message " + strconv.Itoa(i))
+		sigs[i] = Sign(priv, msgs[i])
+	}
+	return pubs, msgs, sigs
+}
+
+func TestVerifyBatch(t *testing.T) {
+	pubs, msgs, sigs := batchInput(t, 16)
+	if ok, invalid := VerifyBatch(pubs, msgs, sigs); !ok || invalid != nil {
+		t.Fatalf("valid batch rejected: %v", invalid)
+	}
+	if ok, _ := VerifyBatch(nil, nil, nil); !ok {
+		t.Errorf("empty batch rejected")
+	}
+
+	sigs[3] = append([]byte{}, sigs[3]...)
+	sigs[3][40] ^= 1
+	msgs[11] = []byte("wrong message")
+	ok, invalid := VerifyBatch(pubs, msgs, sigs)
+	if ok || len(invalid) != 2 || invalid[0] != 3 || invalid[1] != 11 {
+		t.Errorf("got %v %v, want false [3 11]", ok, invalid)
+	}
+
+	// Malformed inputs are reported like invalid signatures.
+	pubs, msgs, sigs = batchInput(t, 4)
+	pubs[1] = pubs[1][:10]
+	sigs[2] = sigs[2][:10]
+	ok, invalid = VerifyBatch(pubs, msgs, sigs)
+	if ok || len(invalid) != 2 || invalid[0] != 1 || invalid[1] != 2 {
+		t.Errorf("got %v %v, want false [1 2]", ok, invalid)
+	}
+}
+
+func BenchmarkVerifyBatch(b *testing.B) {
+	for _, n := range []int{1, 8, 64} {
+		pubs, msgs, sigs := batchInput(b, n)
+		b.Run("batch-"+strconv.Itoa(n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				VerifyBatch(pubs, msgs, sigs)
+			}
+		})
+		b.Run("single-"+strconv.Itoa(n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for j := range pubs {
+					Verify(pubs[j], msgs[j], sigs[j])
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateKeys(t *testing.T) {
+	const n = 300
+	random := make([]byte, n*SeedSize)
+	rand.Read(random)
+	src := bytes.NewReader(random)
+	for _, workers := range []int{1, 4, 0} {
+		pubs, privs, err := GenerateKeysParallel(bytes.NewReader(random), n, workers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(pubs) != n || len(privs) != n {
+			t.Fatalf("got %d and %d keys, want %d", len(pubs), len(privs), n)
+		}
+		src.Reset(random)
+		for i := range pubs {
+			pub, priv, _ := GenerateKey(src)
+			if !pub.Equal(pubs[i]) || !priv.Equal(privs[i]) {
+				t.Fatalf("workers=%d: key %d differs from GenerateKey", workers, i)
+			}
+		}
+	}
+	if _, _, err := GenerateKeys(bytes.NewReader(random[:100]), 4); err == nil {
+		t.Errorf("short randomness source not reported")
+	}
+	if pubs, _, err := GenerateKeys(nil, 0); err != nil || len(pubs) != 0 {
+		t.Errorf("GenerateKeys(nil, 0) = %d keys, %v", len(pubs), err)
+	}
+}
+
+func TestVerifyZIP215(t *testing.T) {
+	zip215 := &Options{Mode: VerifyZIP215}
+
+	public, private, _ := GenerateKey(rand.Reader)
+	message := []byte("test message")
+	sig := Sign(private, message)
+	if err := VerifyWithOptions(public, message, sig, zip215); err != nil {
+		t.Errorf("valid signature rejected: %v", err)
+	}
+	if err := VerifyWithOptions(public, []byte("wrong message"), sig, zip215); err == nil {
+		t.Errorf("signature of different message accepted")
+	}
+
+	// S + L, a non-canonical encoding of S, passes the
+	// cofactorless check but not ZIP 215.
+	var s, l, sl [32]byte
+	copy(s[:], sig[32:])
+	l = [32]byte{0xed, 0xd3, 0xf5, 0x5c, 0x1a, 0x63, 0x12, 0x58, 0xd6, 0x9c, 0xf7, 0xa2,
+		0xde, 0xf9, 0xde, 0x14, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x10}
+	var carry uint16
+	for i := range sl {
+		carry += uint16(s[i]) + uint16(l[i])
+		sl[i] = byte(carry)
+		carry >>= 8
+	}
+	malleated := append(append([]byte{}, sig[:32]...), sl[:]...)
+	if !Verify(public, message, malleated) {
+		t.Fatalf("S + L rejected by Verify")
+	}
+	if err := VerifyWithOptions(public, message, malleated, zip215); err == nil {
+		t.Errorf("non-canonical S accepted")
+	}
+
+	identity, _ := hex.DecodeString("0100000000000000000000000000000000000000000000000000000000000000")
+	// y = p + 1, a non-canonical encoding of the identity
+	identityNonCanonical, _ := hex.DecodeString("eeffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f")
+	// y = -1, the point of order 2
+	order2, _ := hex.DecodeString("ecffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f")
+	zeroS := make([]byte, 32)
+
+	tests := []struct {
+		name      string
+		pub, R    []byte
+		defaultOK bool
+		zip215OK  bool
+	}{
+		{"identity", identity, identity, true, true},
+		{"small order R", identity, order2, false, true},
+		{"non-canonical A", identityNonCanonical, identity, true, true},
+		{"non-canonical R", identity, identityNonCanonical, false, true},
+	}
+	for _, tt := range tests {
+		sig := append(append([]byte{}, tt.R...), zeroS...)
+		if got := Verify(tt.pub, message, sig); got != tt.defaultOK {
+			t.Errorf("%s: Verify = %v, want %v", tt.name, got, tt.defaultOK)
+		}
+		err := VerifyWithOptions(tt.pub, message, sig, zip215)
+		if got := err == nil; got != tt.zip215OK {
+			t.Errorf("%s: ZIP 215 verification = %v, want %v", tt.name, err, tt.zip215OK)
+		}
+	}
+
+	if err := VerifyWithOptions(public, message, sig, &Options{Mode: 42}); err == nil {
+		t.Errorf("unknown mode accepted")
+	}
+}
+
+func TestVerifyStrict(t *testing.T) {
+	strict := &Options{Mode: VerifyStrict}
+
+	public, private, _ := GenerateKey(rand.Reader)
+	message := []byte("test message")
+	sig := Sign(private, message)
+	if err := VerifyWithOptions(public, message, sig, strict); err != nil {
+		t.Errorf("valid signature rejected: %v", err)
+	}
+	if !IsCanonicalScalar(sig[32:]) || !IsCanonicalPoint(sig[:32]) || !IsCanonicalPoint(public) {
+		t.Errorf("signature or key reported as non-canonical")
+	}
+	if IsSmallOrder(sig[:32]) || IsSmallOrder(public) {
+		t.Errorf("signature or key reported as small order")
+	}
+
+	// S + L passes Verify, but not strict verification.
+	malleated := append([]byte{}, sig...)
+	var carry uint16
+	for i, l := range []byte{0xed, 0xd3, 0xf5, 0x5c, 0x1a, 0x63, 0x12, 0x58, 0xd6, 0x9c, 0xf7, 0xa2,
+		0xde, 0xf9, 0xde, 0x14, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x10} {
+		carry += uint16(malleated[32+i]) + uint16(l)
+		malleated[32+i] = byte(carry)
+		carry >>= 8
+	}
+	if !Verify(public, message, malleated) {
+		t.Fatalf("S + L rejected by Verify")
+	}
+	if IsCanonicalScalar(malleated[32:]) {
+		t.Errorf("S + L reported as canonical")
+	}
+	if err := VerifyWithOptions(public, message, malleated, strict); err == nil {
+		t.Errorf("non-canonical S accepted")
+	}
+
+	// A signature by the identity key, valid for any message under Verify.
+	identity, _ := hex.DecodeString("0100000000000000000000000000000000000000000000000000000000000000")
+	forged := append(append([]byte{}, identity...), make([]byte, 32)...)
+	if !Verify(identity, message, forged) {
+		t.Fatalf("identity signature rejected by Verify")
+	}
+	if !IsSmallOrder(identity) {
+		t.Errorf("identity not reported as small order")
+	}
+	if err := VerifyWithOptions(identity, message, forged, strict); err == nil {
+		t.Errorf("small-order key accepted")
+	}
+
+	points := []struct {
+		enc        string
+		canonical  bool
+		smallOrder bool
+	}{
+		{"0100000000000000000000000000000000000000000000000000000000000000", true, true},
+		{"0100000000000000000000000000000000000000000000000000000000000080", false, true}, // negative zero
+		{"eeffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f", false, true}, // y = p + 1
+		{"ecffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f", true, true},  // order 2
+		{"c7176a703d4dd84fba3c0b760d10670f2a2053fa2c39ccc64ec7fd7792ac037a", true, true},  // order 8
+		{"5866666666666666666666666666666666666666666666666666666666666666", true, false}, // B
+	}
+	for _, tt := range points {
+		p, _ := hex.DecodeString(tt.enc)
+		if got := IsCanonicalPoint(p); got != tt.canonical {
+			t.Errorf("IsCanonicalPoint(%s) = %v, want %v", tt.enc, got, tt.canonical)
+		}
+		if got := IsSmallOrder(p); got != tt.smallOrder {
+			t.Errorf("IsSmallOrder(%s) = %v, want %v", tt.enc, got, tt.smallOrder)
+		}
+	}
+	if IsCanonicalPoint(public[:31]) || IsCanonicalScalar(sig[32:63]) {
+		t.Errorf("short encoding accepted")
+	}
+}
+
+func TestValidatePublicKey(t *testing.T) {
+	public, _, _ := GenerateKey(rand.Reader)
+	if err := ValidatePublicKey(public); err != nil {
+		t.Errorf("valid key rejected: %v", err)
+	}
+
+	// public plus a point of order 8 passes every check but the last
+	var A, T edwards25519.ExtendedGroupElement
+	var b [32]byte
+	copy(b[:], public)
+	A.FromBytes(&b)
+	lowOrder, _ := hex.DecodeString("c7176a703d4dd84fba3c0b760d10670f2a2053fa2c39ccc64ec7fd7792ac037a")
+	copy(b[:], lowOrder)
+	T.FromBytes(&b)
+	A.Add(&A, &T)
+	A.ToBytes(&b)
+	mixed := PublicKey(b[:])
+
+	for _, tt := range []struct {
+		key  string
+		want error
+	}{
+		{"0200000000000000000000000000000000000000000000000000000000000000", ErrInvalidPoint},
+		{"eeffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f", ErrNonCanonical},
+		{"0100000000000000000000000000000000000000000000000000000000000000", ErrSmallOrder},
+		{"ecffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f", ErrSmallOrder},
+		{hex.EncodeToString(lowOrder), ErrSmallOrder},
+		{hex.EncodeToString(mixed), ErrTorsion},
+		{hex.EncodeToString(public[:31]), ErrPublicKeySize},
+	} {
+		key, _ := hex.DecodeString(tt.key)
+		if err := ValidatePublicKey(key); err != tt.want {
+			t.Errorf("ValidatePublicKey(%s) = %v, want %v", tt.key, err, tt.want)
+		}
+	}
+}
+
+func TestIsWeakPublicKey(t *testing.T) {
+	for _, k := range weakKeys {
+		for _, sign := range []byte{0, 0x80} {
+			key := append([]byte{}, k[:]...)
+			key[31] |= sign
+			if !IsWeakPublicKey(key) {
+				t.Errorf("IsWeakPublicKey(%x) = false", key)
+			}
+			if !IsSmallOrder(key) {
+				t.Errorf("blacklisted key %x is not of small order", key)
+			}
+		}
+	}
+	for i := 0; i < 8; i++ {
+		public, _, _ := GenerateKey(rand.Reader)
+		if IsWeakPublicKey(public) {
+			t.Errorf("IsWeakPublicKey(%x) = true", public)
+		}
+	}
+	if IsWeakPublicKey(make([]byte, 31)) {
+		t.Errorf("short key reported as weak")
+	}
+}
+
+// scOrder is the order of the prime-order subgroup, L.
+var scOrder = [32]byte{0xed, 0xd3, 0xf5, 0x5c, 0x1a, 0x63, 0x12, 0x58, 0xd6, 0x9c, 0xf7, 0xa2, 0xde, 0xf9, 0xde, 0x14, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x10}
+
+func TestNormalizeS(t *testing.T) {
+	_, private, _ := GenerateKey(rand.Reader)
+	message := []byte("test message")
+	sig := Sign(private, message)
+	if !IsCanonicalS(sig) {
+		t.Errorf("signature reported as non-canonical")
+	}
+	if norm, err := NormalizeS(sig); err != nil || !bytes.Equal(norm, sig) {
+		t.Errorf("NormalizeS changed a canonical signature: %x, %v", norm, err)
+	}
+
+	malleated := append([]byte{}, sig...)
+	var carry uint16
+	for i, l := range scOrder {
+		carry += uint16(malleated[32+i]) + uint16(l)
+		malleated[32+i] = byte(carry)
+		carry >>= 8
+	}
+	if IsCanonicalS(malleated) {
+		t.Errorf("S + L reported as canonical")
+	}
+	norm, err := NormalizeS(malleated)
+	if err != nil || !bytes.Equal(norm, sig) {
+		t.Errorf("NormalizeS(S + L) = %x, %v; want %x", norm, err, sig)
+	}
+
+	highS := append([]byte{}, sig...)
+	highS[63] |= 0x80
+	if _, err := NormalizeS(highS); err != ErrNonCanonical {
+		t.Errorf("NormalizeS with top bits set: err = %v, want ErrNonCanonical", err)
+	}
+	if _, err := NormalizeS(sig[:63]); err != ErrSignatureSize {
+		t.Errorf("NormalizeS of short signature: err = %v, want ErrSignatureSize", err)
+	}
+	if IsCanonicalS(sig[:63]) {
+		t.Errorf("short signature reported as canonical")
+	}
+}
+
+func TestVerifyErr(t *testing.T) {
+	public, private, _ := GenerateKey(rand.Reader)
+	message := []byte("test message")
+	sig := Sign(private, message)
+	if err := VerifyErr(public, message, sig); err != nil {
+		t.Errorf("valid signature rejected: %v", err)
+	}
+
+	highS := append([]byte{}, sig...)
+	highS[63] |= 0x80
+	notOnCurve, _ := hex.DecodeString("0200000000000000000000000000000000000000000000000000000000000000")
+	identity, _ := hex.DecodeString("0100000000000000000000000000000000000000000000000000000000000000")
+	forged := append(append([]byte{}, identity...), make([]byte, 32)...)
+
+	tests := []struct {
+		name string
+		pub  PublicKey
+		msg  []byte
+		sig  []byte
+		opts *Options
+		want error
+	}{
+		{"short key", public[:31], message, sig, nil, ErrPublicKeySize},
+		{"short signature", public, message, sig[:63], nil, ErrSignatureSize},
+		{"high S", public, message, highS, nil, ErrNonCanonical},
+		{"key not on curve", notOnCurve, message, sig, nil, ErrInvalidPoint},
+		{"wrong message", public, []byte("wrong message"), sig, nil, ErrInvalidSignature},
+		{"small order", identity, message, forged, &Options{Mode: VerifyStrict}, ErrSmallOrder},
+		{"R not on curve", public, message, append(append([]byte{}, notOnCurve...), sig[32:]...),
+			&Options{Mode: VerifyZIP215}, ErrInvalidPoint},
+	}
+	for _, tt := range tests {
+		var err error
+		if tt.opts == nil {
+			err = VerifyErr(tt.pub, tt.msg, tt.sig)
+		} else {
+			err = VerifyWithOptions(tt.pub, tt.msg, tt.sig, tt.opts)
+		}
+		if err != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestSignErr(t *testing.T) {
+	_, private, _ := GenerateKey(rand.Reader)
+	message := []byte("test message")
+	if sig, err := SignErr(private, message); err != nil || !bytes.Equal(sig, Sign(private, message)) {
+		t.Errorf("SignErr = %x, %v; want the signature of Sign", sig, err)
+	}
+	if _, err := SignErr(private[:32], message); err != ErrPrivateKeySize {
+		t.Errorf("SignErr(short key) error = %v, want ErrPrivateKeySize", err)
+	}
+	if _, err := private[:63].Sign(nil, message, crypto.Hash(0)); err != ErrPrivateKeySize {
+		t.Errorf("PrivateKey.Sign(short key) error = %v, want ErrPrivateKeySize", err)
+	}
+	if priv, err := NewKeyFromSeedErr(private.Seed()); err != nil || !priv.Equal(private) {
+		t.Errorf("NewKeyFromSeedErr = %x, %v; want %x", priv, err, private)
+	}
+	if _, err := NewKeyFromSeedErr(private); err != ErrSeedSize {
+		t.Errorf("NewKeyFromSeedErr(long seed) error = %v, want ErrSeedSize", err)
+	}
+}
+
+func TestBackend(t *testing.T) {
+	switch b := Backend(); b {
+	case "radix51", "radix51+avx2", "radix51-arm64", "ref10":
+	default:
+		t.Errorf("Backend() = %q, want a known implementation", b)
+	}
+}
+
+func TestAppendSign(t *testing.T) {
+	_, private, _ := GenerateKey(rand.Reader)
+	message := []byte("test message")
+	want := Sign(private, message)
+	prefix := []byte("prefix")
+	if sig := AppendSign(prefix, private, message); !bytes.Equal(sig, append(prefix, want...)) {
+		t.Errorf("AppendSign differs from Sign")
+	}
+	k := NewExpandedPrivateKey(private)
+	if sig := k.AppendSign(nil, message); !bytes.Equal(sig, want) {
+		t.Errorf("ExpandedPrivateKey.AppendSign differs from Sign")
+	}
+}
+
+func TestAllocations(t *testing.T) {
+	if testing.CoverMode() != "" {
+		t.Skip("coverage instrumentation allocates")
+	}
+	public, private, _ := GenerateKey(rand.Reader)
+	message := []byte("test message")
+	sig := Sign(private, message)
+	k, _ := NewExpandedPublicKey(public)
+	ek := NewExpandedPrivateKey(private)
+	ctxOpts := &Options{Context: "test context"}
+	ctxSig, _ := private.Sign(nil, message, ctxOpts)
+	buf := make([]byte, 0, SignatureSize)
+
+	for _, tt := range []struct {
+		name string
+		f    func()
+	}{
+		{"AppendSign", func() { AppendSign(buf, private, message) }},
+		{"ExpandedPrivateKey.AppendSign", func() { ek.AppendSign(buf, message) }},
+		{"Verify", func() { Verify(public, message, sig) }},
+		{"ExpandedPublicKey.Verify", func() { k.Verify(message, sig) }},
+		{"VerifyWithOptions", func() {
+			if err := VerifyWithOptions(public, message, ctxSig, ctxOpts); err != nil {
+				t.Fatal(err)
+			}
+		}},
+	} {
+		if allocs := testing.AllocsPerRun(10, tt.f); allocs > 0 {
+			t.Errorf("%s: expected zero allocations, got %0.1f", tt.name, allocs)
+		}
+	}
+}
+
+func TestBlind(t *testing.T) {
+	public, private, _ := GenerateKey(rand.Reader)
+	message := []byte("test message")
+
+	var keys []PublicKey
+	for period := uint64(0); period < 3; period++ {
+		param := EpochParam(period, 1440)
+		blindedPub, err := BlindPublicKey(public, param)
+		if err != nil {
+			t.Fatal(err)
+		}
+		blindedPriv := BlindPrivateKey(private, param)
+		if !blindedPub.Equal(blindedPriv.Public()) {
+			t.Fatalf("period %d: blinded public keys differ", period)
+		}
+		sig, err := blindedPriv.Sign(nil, message, crypto.Hash(0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !Verify(blindedPub, message, sig) {
+			t.Errorf("period %d: signature under blinded key rejected", period)
+		}
+		if Verify(public, message, sig) {
+			t.Errorf("period %d: signature under blinded key accepted by long-term key", period)
+		}
+		for _, k := range append(keys, public) {
+			if blindedPub.Equal(k) {
+				t.Errorf("period %d: blinded key repeats", period)
+			}
+		}
+		keys = append(keys, blindedPub)
+	}
+
+	if _, err := BlindPrivateKey(private, nil).Sign(nil, message, crypto.SHA512); err == nil {
+		t.Errorf("hashed signing accepted")
+	}
+	bad, _ := hex.DecodeString("0200000000000000000000000000000000000000000000000000000000000000")
+	if _, err := BlindPublicKey(bad, nil); err == nil {
+		t.Errorf("invalid public key blinded")
+	}
+}
+
+func TestCurve25519(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		pub, priv, err := GenerateKey(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := curve25519.X25519(PrivateKeyToCurve25519(priv), curve25519.Basepoint)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := PublicKeyToCurve25519(pub)
+		if err != nil || !bytes.Equal(got, want) {
+			t.Fatalf("PublicKeyToCurve25519 = %x, %v; want %x", got, err, want)
+		}
+	}
+	identity := make(PublicKey, 32)
+	identity[0] = 1
+	if _, err := PublicKeyToCurve25519(identity); err == nil {
+		t.Error("identity point accepted")
+	}
+}
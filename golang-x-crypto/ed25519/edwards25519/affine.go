@@ -0,0 +1,92 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package edwards25519
+
+import (
+	"errors"
+	"math/big"
+	"slices"
+
+	ref10 "test-server/golang-x-crypto/ed25519/internal/edwards25519"
+)
+
+// The coordinates below are integers modulo p = 2^255 - 19, as used by
+// test vectors and by libraries that specify points in affine form.
+// None of these conversions run in constant time.
+
+// fieldOrder is p = 2^255 - 19.
+var fieldOrder = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+
+// feToInt returns the value of f in [0, p).
+func feToInt(f *ref10.FieldElement) *big.Int {
+	var b [32]byte
+	ref10.FeToBytes(&b, f)
+	slices.Reverse(b[:])
+	return new(big.Int).SetBytes(b[:])
+}
+
+// feFromInt sets f to x and reports whether x is in [0, p).
+func feFromInt(f *ref10.FieldElement, x *big.Int) bool {
+	if x.Sign() < 0 || x.Cmp(fieldOrder) >= 0 {
+		return false
+	}
+	var b [32]byte
+	x.FillBytes(b[:])
+	slices.Reverse(b[:])
+	ref10.FeFromBytes(f, &b)
+	return true
+}
+
+// AffineCoordinates returns the affine coordinates (x, y) of v,
+// in [0, p). The encoding Bytes returns is y, little-endian,
+// with the least significant bit of x in its top bit, so with
+// SetBytes it converts between the two forms.
+func (v *Point) AffineCoordinates() (x, y *big.Int) {
+	var recip, fx, fy ref10.FieldElement
+	ref10.FeInvert(&recip, &v.p.Z)
+	ref10.FeMul(&fx, &v.p.X, &recip)
+	ref10.FeMul(&fy, &v.p.Y, &recip)
+	return feToInt(&fx), feToInt(&fy)
+}
+
+// SetAffineCoordinates sets v to the point (x, y) and returns v.
+// If x or y is not in [0, p), or (x, y) is not on the curve,
+// SetAffineCoordinates returns nil and an error, and leaves v unchanged.
+func (v *Point) SetAffineCoordinates(x, y *big.Int) (*Point, error) {
+	var p ref10.ExtendedGroupElement
+	if !feFromInt(&p.X, x) || !feFromInt(&p.Y, y) {
+		return nil, errors.New("edwards25519: coordinate out of range")
+	}
+	ref10.FeOne(&p.Z)
+	ref10.FeMul(&p.T, &p.X, &p.Y)
+	if p.IsOnCurve() != 1 {
+		return nil, errors.New("edwards25519: point not on the curve")
+	}
+	v.p = p
+	return v, nil
+}
+
+// ExtendedCoordinates returns v in extended coordinates (X:Y:Z:T),
+// where x = X/Z, y = Y/Z and xy = T/Z, each in [0, p).
+// They are not unique: multiplying all four by the same
+// nonzero value gives the same point.
+func (v *Point) ExtendedCoordinates() (X, Y, Z, T *big.Int) {
+	return feToInt(&v.p.X), feToInt(&v.p.Y), feToInt(&v.p.Z), feToInt(&v.p.T)
+}
+
+// SetExtendedCoordinates sets v to the point (X:Y:Z:T) and returns v.
+// If a coordinate is not in [0, p), or (X:Y:Z:T) is not a valid point,
+// SetExtendedCoordinates returns nil and an error, and leaves v unchanged.
+func (v *Point) SetExtendedCoordinates(X, Y, Z, T *big.Int) (*Point, error) {
+	var p ref10.ExtendedGroupElement
+	if !feFromInt(&p.X, X) || !feFromInt(&p.Y, Y) || !feFromInt(&p.Z, Z) || !feFromInt(&p.T, T) {
+		return nil, errors.New("edwards25519: coordinate out of range")
+	}
+	if p.IsOnCurve() != 1 {
+		return nil, errors.New("edwards25519: invalid extended coordinates")
+	}
+	v.p = p
+	return v, nil
+}
@@ -0,0 +1,233 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package edwards25519 implements group operations on the twisted Edwards
+// curve edwards25519, the curve of Ed25519, for protocols built on it
+// such as multi-signatures, threshold signatures and VRFs.
+//
+// It is a supported, curated interface to the arithmetic package ed25519
+// uses internally, in the style of filippo.io/edwards25519:
+// Point and Scalar values are used with setter methods
+// in the style of math/big, where the receiver is set to the result
+// and returned, and arguments may alias the receiver.
+// Operations run in constant time unless their name begins with VarTime.
+//
+// The group has order 8*l for a prime l. Most protocols want
+// the prime-order subgroup, and must take care of the cofactor 8
+// themselves, with MultByCofactor, ClearCofactor, IsSmallOrder
+// and IsTorsionFree, or use package ristretto255 instead.
+package edwards25519
+
+import (
+	"errors"
+
+	ref10 "test-server/golang-x-crypto/ed25519/internal/edwards25519"
+)
+
+// A Point is a point on the edwards25519 curve.
+// The zero value is not valid; use NewIdentityPoint, NewGeneratorPoint
+// or SetBytes to initialize a Point.
+type Point struct {
+	p ref10.ExtendedGroupElement
+}
+
+// NewIdentityPoint returns a new Point set to the identity.
+func NewIdentityPoint() *Point {
+	v := new(Point)
+	v.p.Zero()
+	return v
+}
+
+// NewGeneratorPoint returns a new Point set to the canonical generator B,
+// the base point of Ed25519.
+func NewGeneratorPoint() *Point {
+	v := new(Point)
+	ref10.GeScalarMultBase(&v.p, &[32]byte{1})
+	return v
+}
+
+// Set sets v = u and returns v.
+func (v *Point) Set(u *Point) *Point {
+	*v = *u
+	return v
+}
+
+// Bytes returns the canonical 32-byte encoding of v,
+// as used for Ed25519 public keys and signature R values.
+func (v *Point) Bytes() []byte {
+	var b [32]byte
+	v.p.ToBytes(&b)
+	return b[:]
+}
+
+// SetBytes sets v to the point encoded by x and returns v.
+// x must be 32 bytes long. Like Ed25519 verification, SetBytes accepts
+// the non-canonical encodings of valid points, which Bytes never returns.
+// If x does not encode a point, SetBytes returns nil and an error,
+// and leaves v unchanged.
+func (v *Point) SetBytes(x []byte) (*Point, error) {
+	if len(x) != 32 {
+		return nil, errors.New("edwards25519: invalid point encoding length")
+	}
+	var b [32]byte
+	copy(b[:], x)
+	var p ref10.ExtendedGroupElement
+	if !p.FromBytes(&b) {
+		return nil, errors.New("edwards25519: invalid point encoding")
+	}
+	v.p = p
+	return v, nil
+}
+
+// Equal returns 1 if v and u are the same point, and 0 otherwise.
+func (v *Point) Equal(u *Point) int {
+	// X1/Z1 == X2/Z2 and Y1/Z1 == Y2/Z2
+	var t0, t1 ref10.FieldElement
+	ref10.FeMul(&t0, &v.p.X, &u.p.Z)
+	ref10.FeMul(&t1, &u.p.X, &v.p.Z)
+	ref10.FeSub(&t0, &t0, &t1)
+	x := ref10.FeIsNonZero(&t0)
+	ref10.FeMul(&t0, &v.p.Y, &u.p.Z)
+	ref10.FeMul(&t1, &u.p.Y, &v.p.Z)
+	ref10.FeSub(&t0, &t0, &t1)
+	y := ref10.FeIsNonZero(&t0)
+	return int(1 ^ (x | y))
+}
+
+// Add sets v = p + q and returns v.
+func (v *Point) Add(p, q *Point) *Point {
+	v.p.Add(&p.p, &q.p)
+	return v
+}
+
+// Subtract sets v = p - q and returns v.
+func (v *Point) Subtract(p, q *Point) *Point {
+	v.p.Sub(&p.p, &q.p)
+	return v
+}
+
+// Negate sets v = -p and returns v.
+func (v *Point) Negate(p *Point) *Point {
+	v.p = p.p
+	ref10.FeNeg(&v.p.X, &v.p.X)
+	ref10.FeNeg(&v.p.T, &v.p.T)
+	return v
+}
+
+// MultByCofactor sets v = 8 * p and returns v.
+func (v *Point) MultByCofactor(p *Point) *Point {
+	v.p.MulByCofactor(&p.p)
+	return v
+}
+
+// IsSmallOrder returns 1 if v has order 1, 2, 4 or 8, that is, if it is
+// in the torsion subgroup and so has no component in the prime-order one,
+// and 0 otherwise. Such points must usually be rejected as public keys
+// or commitments.
+func (v *Point) IsSmallOrder() int {
+	var p, identity Point
+	p.MultByCofactor(v)
+	identity.p.Zero()
+	return p.Equal(&identity)
+}
+
+// ClearCofactor sets v to the component of p in the prime-order subgroup
+// and returns v: for p = x*B + T, with T of small order, v = x*B.
+// Unlike MultByCofactor, which returns 8*x*B, it leaves points of the
+// prime-order subgroup unchanged. It costs a scalar multiplication.
+func (v *Point) ClearCofactor(p *Point) *Point {
+	v.p.ClearCofactor(&p.p)
+	return v
+}
+
+// IsTorsionFree returns 1 if v is in the prime-order subgroup, that is,
+// if it has no small-order component, and 0 otherwise. Public keys and
+// other points received from peers usually must be. It costs a scalar
+// multiplication.
+func (v *Point) IsTorsionFree() int {
+	return int(v.p.IsTorsionFree())
+}
+
+// IsOnCurve returns 1 if v is a valid point on the curve, and 0 otherwise.
+// Every Point this package returns is valid; the zero Point is not.
+func (v *Point) IsOnCurve() int {
+	return int(v.p.IsOnCurve())
+}
+
+// ScalarBaseMult sets v = x * B, where B is the generator, and returns v.
+func (v *Point) ScalarBaseMult(x *Scalar) *Point {
+	ref10.GeScalarMultBase(&v.p, &x.s)
+	return v
+}
+
+// ScalarMult sets v = x * q and returns v.
+func (v *Point) ScalarMult(x *Scalar, q *Point) *Point {
+	ref10.GeScalarMult(&v.p, &x.s, &q.p)
+	return v
+}
+
+// A FixedBaseTable is a precomputed table of multiples of a point P,
+// with which ScalarMultFixedBase multiplies P about three times
+// as fast as ScalarMult: as fast as ScalarBaseMult multiplies
+// the generator. It pays off for points multiplied again and again,
+// such as a pinned aggregate key. A table takes about 30 KiB,
+// and is safe for concurrent use.
+type FixedBaseTable struct {
+	t ref10.FixedBaseTable
+}
+
+// NewFixedBaseTable returns the table of p.
+// Building it costs about as much as two calls to ScalarMult.
+func NewFixedBaseTable(p *Point) *FixedBaseTable {
+	t := new(FixedBaseTable)
+	t.t.Init(&p.p)
+	return t
+}
+
+// ScalarMultFixedBase sets v = x * P, where table is the table of P,
+// and returns v.
+func (v *Point) ScalarMultFixedBase(x *Scalar, table *FixedBaseTable) *Point {
+	ref10.GeScalarMultFixedBase(&v.p, &x.s, &table.t)
+	return v
+}
+
+// VarTimeDoubleScalarBaseMult sets v = a * A + b * B, where B is the
+// generator, and returns v. It runs in variable time, and so must
+// only be used with public scalars, as in signature verification.
+func (v *Point) VarTimeDoubleScalarBaseMult(a *Scalar, A *Point, b *Scalar) *Point {
+	var r ref10.ProjectiveGroupElement
+	ref10.GeDoubleScalarMultVartime(&r, &a.s, &A.p, &b.s)
+	v.setProjective(&r)
+	return v
+}
+
+// VarTimeMultiScalarMult sets v = sum(scalars[i] * points[i]) and returns v.
+// Large sums are computed with Pippenger's bucket method,
+// at a cost per term that falls as the number of terms grows.
+// It runs in variable time, and so must only be used with public scalars.
+// It panics if the slices have different lengths.
+func (v *Point) VarTimeMultiScalarMult(scalars []*Scalar, points []*Point) *Point {
+	if len(scalars) != len(points) {
+		panic("edwards25519: called VarTimeMultiScalarMult with different size inputs")
+	}
+	a := make([]*[32]byte, len(scalars))
+	A := make([]*ref10.ExtendedGroupElement, len(points))
+	for i := range scalars {
+		a[i] = &scalars[i].s
+		A[i] = &points[i].p
+	}
+	ref10.GeMultiScalarMultVartime(&v.p, a, A)
+	return v
+}
+
+// setProjective sets v to the point (X:Y:Z) of r,
+// which is (XZ:YZ:Z²:XY) in extended coordinates.
+func (v *Point) setProjective(r *ref10.ProjectiveGroupElement) {
+	var p ref10.ExtendedGroupElement
+	ref10.FeMul(&p.X, &r.X, &r.Z)
+	ref10.FeMul(&p.Y, &r.Y, &r.Z)
+	ref10.FeSquare(&p.Z, &r.Z)
+	ref10.FeMul(&p.T, &r.X, &r.Y)
+	v.p = p
+}
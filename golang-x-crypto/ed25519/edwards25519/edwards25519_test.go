@@ -0,0 +1,324 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package edwards25519
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha512"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"test-server/golang-x-crypto/ed25519"
+)
+
+func randomScalar(t *testing.T) *Scalar {
+	var b [64]byte
+	rand.Read(b[:])
+	s, err := NewScalar().SetUniformBytes(b[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestPublicKey(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	digest := sha512.Sum512(priv.Seed())
+	s, err := NewScalar().SetBytesWithClamping(digest[:32])
+	if err != nil {
+		t.Fatal(err)
+	}
+	A := NewIdentityPoint().ScalarBaseMult(s)
+	if !bytes.Equal(A.Bytes(), pub) {
+		t.Errorf("ScalarBaseMult = %x, want public key %x", A.Bytes(), pub)
+	}
+	P, err := NewIdentityPoint().SetBytes(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if P.Equal(A) != 1 {
+		t.Errorf("decoded public key differs")
+	}
+}
+
+func TestPointArithmetic(t *testing.T) {
+	B := NewGeneratorPoint()
+	I := NewIdentityPoint()
+	a, b := randomScalar(t), randomScalar(t)
+	aB := NewIdentityPoint().ScalarBaseMult(a)
+	bB := NewIdentityPoint().ScalarMult(b, B)
+	sum := NewScalar().Add(a, b)
+
+	if got := NewIdentityPoint().Add(aB, bB); got.Equal(NewIdentityPoint().ScalarBaseMult(sum)) != 1 {
+		t.Errorf("aB + bB != (a+b)B")
+	}
+	if got := NewIdentityPoint().Subtract(aB, aB); got.Equal(I) != 1 {
+		t.Errorf("aB - aB != identity")
+	}
+	if got := NewIdentityPoint().Add(aB, NewIdentityPoint().Negate(aB)); got.Equal(I) != 1 {
+		t.Errorf("aB + -aB != identity")
+	}
+	if aB.Equal(bB) == 1 {
+		t.Errorf("aB == bB")
+	}
+
+	// a(bB) + bB == (ab + b)B
+	abB := NewIdentityPoint().ScalarMult(a, bB)
+	want := NewIdentityPoint().ScalarBaseMult(NewScalar().MultiplyAdd(a, b, b))
+	if got := NewIdentityPoint().Add(abB, bB); got.Equal(want) != 1 {
+		t.Errorf("a(bB) + bB != (ab+b)B")
+	}
+	if got := NewIdentityPoint().VarTimeDoubleScalarBaseMult(a, bB, b); got.Equal(want) != 1 {
+		t.Errorf("VarTimeDoubleScalarBaseMult(a, bB, b) != (ab+b)B")
+	}
+	one := NewScalar().Invert(b)
+	one.Multiply(one, b)
+	if got := NewIdentityPoint().VarTimeMultiScalarMult([]*Scalar{a, one}, []*Point{bB, B}); got.Equal(abB.Add(abB, B)) != 1 {
+		t.Errorf("VarTimeMultiScalarMult(a, bB, 1, B) != a(bB) + B")
+	}
+	if got := B.Bytes(); !bytes.Equal(NewIdentityPoint().ScalarBaseMult(one).Bytes(), got) {
+		t.Errorf("b^-1 * b != 1")
+	}
+}
+
+func TestFixedBaseTable(t *testing.T) {
+	P := NewIdentityPoint().ScalarBaseMult(randomScalar(t))
+	table := NewFixedBaseTable(P)
+	for i := 0; i < 8; i++ {
+		x := randomScalar(t)
+		want := NewIdentityPoint().ScalarMult(x, P)
+		if got := NewIdentityPoint().ScalarMultFixedBase(x, table); got.Equal(want) != 1 {
+			t.Fatalf("ScalarMultFixedBase differs from ScalarMult")
+		}
+	}
+	if got := NewGeneratorPoint().ScalarMultFixedBase(NewScalar(), table); got.Equal(NewIdentityPoint()) != 1 {
+		t.Errorf("0 * P != identity")
+	}
+	B := NewFixedBaseTable(NewGeneratorPoint())
+	x := randomScalar(t)
+	if got := NewIdentityPoint().ScalarMultFixedBase(x, B); got.Equal(NewIdentityPoint().ScalarBaseMult(x)) != 1 {
+		t.Errorf("table of the generator differs from ScalarBaseMult")
+	}
+}
+
+func BenchmarkScalarMult(b *testing.B) {
+	var x [64]byte
+	rand.Read(x[:])
+	s, _ := NewScalar().SetUniformBytes(x[:])
+	P := NewIdentityPoint().ScalarBaseMult(s)
+	v := NewIdentityPoint()
+	b.Run("Variable", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			v.ScalarMult(s, P)
+		}
+	})
+	table := NewFixedBaseTable(P)
+	b.Run("FixedBase", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			v.ScalarMultFixedBase(s, table)
+		}
+	})
+	b.Run("NewFixedBaseTable", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			NewFixedBaseTable(P)
+		}
+	})
+}
+
+func TestVarTimeMultiScalarMult(t *testing.T) {
+	// Sizes on both sides of the switch to Pippenger's method.
+	for _, n := range []int{0, 1, 5, 199, 200, 600} {
+		scalars := make([]*Scalar, n)
+		points := make([]*Point, n)
+		want := NewIdentityPoint()
+		for i := range scalars {
+			scalars[i] = randomScalar(t)
+			points[i] = NewIdentityPoint().ScalarBaseMult(randomScalar(t))
+			want.Add(want, NewIdentityPoint().ScalarMult(scalars[i], points[i]))
+		}
+		if got := NewGeneratorPoint().VarTimeMultiScalarMult(scalars, points); got.Equal(want) != 1 {
+			t.Errorf("n=%d: VarTimeMultiScalarMult differs from the sum of ScalarMult", n)
+		}
+	}
+}
+
+func BenchmarkVarTimeMultiScalarMult(b *testing.B) {
+	for _, n := range []int{64, 256, 1024} {
+		scalars := make([]*Scalar, n)
+		points := make([]*Point, n)
+		for i := range scalars {
+			var x [64]byte
+			rand.Read(x[:])
+			scalars[i], _ = NewScalar().SetUniformBytes(x[:])
+			points[i] = NewIdentityPoint().ScalarBaseMult(scalars[i])
+		}
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			v := NewIdentityPoint()
+			for i := 0; i < b.N; i++ {
+				v.VarTimeMultiScalarMult(scalars, points)
+			}
+		})
+	}
+}
+
+func TestSetBytes(t *testing.T) {
+	bad := make([]byte, 32)
+	bad[0] = 2
+	P := NewGeneratorPoint()
+	if _, err := P.SetBytes(bad); err == nil {
+		t.Errorf("invalid encoding accepted")
+	}
+	if _, err := P.SetBytes(bad[:31]); err == nil {
+		t.Errorf("short encoding accepted")
+	}
+	if P.Equal(NewGeneratorPoint()) != 1 {
+		t.Errorf("failed SetBytes changed the receiver")
+	}
+
+	// y = p+1 is a non-canonical encoding of the identity.
+	nonCanonical := []byte{0xee, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f}
+	if _, err := P.SetBytes(nonCanonical); err != nil {
+		t.Fatal(err)
+	}
+	if P.Equal(NewIdentityPoint()) != 1 {
+		t.Errorf("y = p+1 did not decode to the identity")
+	}
+}
+
+func TestCofactor(t *testing.T) {
+	// An encoding of a point of order 8.
+	order8 := []byte{0x26, 0xe8, 0x95, 0x8f, 0xc2, 0xb2, 0x27, 0xb0, 0x45, 0xc3, 0xf4, 0x89, 0xf2, 0xef, 0x98, 0xf0, 0xd5, 0xdf, 0xac, 0x05, 0xd3, 0xc6, 0x33, 0x39, 0xb1, 0x38, 0x02, 0x88, 0x6d, 0x53, 0xfc, 0x05}
+	T, err := NewIdentityPoint().SetBytes(order8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if T.IsSmallOrder() != 1 || NewIdentityPoint().IsSmallOrder() != 1 {
+		t.Errorf("small-order point not detected")
+	}
+	P := NewIdentityPoint().ScalarBaseMult(randomScalar(t))
+	if P.IsSmallOrder() != 0 {
+		t.Errorf("random multiple of B has small order")
+	}
+
+	// 8(P + T) == 8P
+	PT := NewIdentityPoint().Add(P, T)
+	if PT.IsSmallOrder() != 0 {
+		t.Errorf("P + T has small order")
+	}
+	want := NewIdentityPoint().Add(P, P)
+	want.Add(want, want)
+	want.Add(want, want)
+	if got := NewIdentityPoint().MultByCofactor(PT); got.Equal(want) != 1 {
+		t.Errorf("8(P + T) != 8P")
+	}
+
+	// ClearCofactor(P + T) == P, and P is unchanged.
+	if got := NewIdentityPoint().ClearCofactor(PT); got.Equal(P) != 1 {
+		t.Errorf("ClearCofactor(P + T) != P")
+	}
+	if got := NewIdentityPoint().ClearCofactor(P); got.Equal(P) != 1 {
+		t.Errorf("ClearCofactor(P) != P")
+	}
+	if got := NewIdentityPoint().ClearCofactor(T); got.Equal(NewIdentityPoint()) != 1 {
+		t.Errorf("ClearCofactor(T) != identity")
+	}
+	if P.IsTorsionFree() != 1 || NewIdentityPoint().IsTorsionFree() != 1 {
+		t.Errorf("point of the prime-order subgroup has torsion")
+	}
+	if PT.IsTorsionFree() != 0 || T.IsTorsionFree() != 0 {
+		t.Errorf("torsion not detected")
+	}
+
+	for _, p := range []*Point{P, T, PT, NewIdentityPoint(), NewGeneratorPoint()} {
+		if p.IsOnCurve() != 1 {
+			t.Errorf("valid point not on the curve")
+		}
+	}
+	if new(Point).IsOnCurve() != 0 {
+		t.Errorf("zero Point on the curve")
+	}
+	bad := NewIdentityPoint()
+	bad.p.T = P.p.T
+	if bad.IsOnCurve() != 0 {
+		t.Errorf("point with a wrong T on the curve")
+	}
+}
+
+func TestAffineCoordinates(t *testing.T) {
+	// The base point, from RFC 8032, Section 5.1.
+	bx, _ := new(big.Int).SetString("15112221349535400772501151409588531511454012693041857206046113283949847762202", 10)
+	by, _ := new(big.Int).SetString("46316835694926478169428394003475163141307993866256225615783033603165251855960", 10)
+	x, y := NewGeneratorPoint().AffineCoordinates()
+	if x.Cmp(bx) != 0 || y.Cmp(by) != 0 {
+		t.Errorf("generator = (%v, %v), want (%v, %v)", x, y, bx, by)
+	}
+	B, err := NewIdentityPoint().SetAffineCoordinates(bx, by)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if B.Equal(NewGeneratorPoint()) != 1 {
+		t.Errorf("SetAffineCoordinates(B) != B")
+	}
+
+	P := NewIdentityPoint().ScalarBaseMult(randomScalar(t))
+	x, y = P.AffineCoordinates()
+	Q, err := NewIdentityPoint().SetAffineCoordinates(x, y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(Q.Bytes(), P.Bytes()) {
+		t.Errorf("affine round trip changed the point")
+	}
+
+	// Scaled extended coordinates are the same point.
+	X, Y, Z, T := P.ExtendedCoordinates()
+	for _, c := range []*big.Int{X, Y, Z, T} {
+		c.Lsh(c, 1).Mod(c, fieldOrder)
+	}
+	if Q, err = NewIdentityPoint().SetExtendedCoordinates(X, Y, Z, T); err != nil {
+		t.Fatal(err)
+	}
+	if Q.Equal(P) != 1 {
+		t.Errorf("scaled extended coordinates are a different point")
+	}
+
+	if _, err := B.SetAffineCoordinates(x, by); err == nil {
+		t.Errorf("point off the curve accepted")
+	}
+	if _, err := B.SetAffineCoordinates(new(big.Int).Add(bx, fieldOrder), by); err == nil {
+		t.Errorf("x >= p accepted")
+	}
+	if _, err := B.SetExtendedCoordinates(X, Y, Z, Z); err == nil {
+		t.Errorf("invalid T accepted")
+	}
+	if _, err := B.SetExtendedCoordinates(big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0)); err == nil {
+		t.Errorf("Z = 0 accepted")
+	}
+	if B.Equal(NewGeneratorPoint()) != 1 {
+		t.Errorf("failed Set changed the receiver")
+	}
+}
+
+func TestScalar(t *testing.T) {
+	a, b := randomScalar(t), randomScalar(t)
+	if got := NewScalar().Subtract(NewScalar().Add(a, b), b); got.Equal(a) != 1 {
+		t.Errorf("(a+b)-b != a")
+	}
+	if got := NewScalar().Add(a, NewScalar().Negate(a)); got.Equal(NewScalar()) != 1 {
+		t.Errorf("a + -a != 0")
+	}
+	if got := NewScalar().Invert(NewScalar()); got.Equal(NewScalar()) != 1 {
+		t.Errorf("0^-1 != 0")
+	}
+	if _, err := NewScalar().SetCanonicalBytes(a.Bytes()); err != nil {
+		t.Errorf("canonical encoding rejected: %v", err)
+	}
+	l := []byte{0xed, 0xd3, 0xf5, 0x5c, 0x1a, 0x63, 0x12, 0x58, 0xd6, 0x9c, 0xf7, 0xa2, 0xde, 0xf9, 0xde, 0x14, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x10}
+	if _, err := NewScalar().SetCanonicalBytes(l); err == nil {
+		t.Errorf("l accepted as a canonical scalar")
+	}
+}
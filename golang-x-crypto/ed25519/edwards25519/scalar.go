@@ -0,0 +1,139 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package edwards25519
+
+import (
+	"crypto/subtle"
+	"errors"
+
+	ref10 "test-server/golang-x-crypto/ed25519/internal/edwards25519"
+)
+
+// A Scalar is an integer modulo the prime order of the group,
+// l = 2^252 + 27742317777372353535851937790883648493.
+// The zero value is a valid zero scalar.
+type Scalar struct {
+	s [32]byte // canonical little-endian encoding
+}
+
+var (
+	scZero     [32]byte
+	scOne      = [32]byte{1}
+	scMinusOne = [32]byte{0xec, 0xd3, 0xf5, 0x5c, 0x1a, 0x63, 0x12, 0x58, 0xd6, 0x9c, 0xf7, 0xa2, 0xde, 0xf9, 0xde, 0x14, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x10}
+	// scOrderMinusTwo is l - 2, the exponent of inversion.
+	scOrderMinusTwo = [32]byte{0xeb, 0xd3, 0xf5, 0x5c, 0x1a, 0x63, 0x12, 0x58, 0xd6, 0x9c, 0xf7, 0xa2, 0xde, 0xf9, 0xde, 0x14, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x10}
+)
+
+// NewScalar returns a new zero Scalar.
+func NewScalar() *Scalar {
+	return new(Scalar)
+}
+
+// Set sets s = x and returns s.
+func (s *Scalar) Set(x *Scalar) *Scalar {
+	*s = *x
+	return s
+}
+
+// MultiplyAdd sets s = x * y + z and returns s.
+func (s *Scalar) MultiplyAdd(x, y, z *Scalar) *Scalar {
+	ref10.ScMulAdd(&s.s, &x.s, &y.s, &z.s)
+	return s
+}
+
+// Add sets s = x + y and returns s.
+func (s *Scalar) Add(x, y *Scalar) *Scalar {
+	ref10.ScMulAdd(&s.s, &x.s, &scOne, &y.s)
+	return s
+}
+
+// Subtract sets s = x - y and returns s.
+func (s *Scalar) Subtract(x, y *Scalar) *Scalar {
+	ref10.ScMulAdd(&s.s, &y.s, &scMinusOne, &x.s)
+	return s
+}
+
+// Negate sets s = -x and returns s.
+func (s *Scalar) Negate(x *Scalar) *Scalar {
+	ref10.ScMulAdd(&s.s, &x.s, &scMinusOne, &scZero)
+	return s
+}
+
+// Multiply sets s = x * y and returns s.
+func (s *Scalar) Multiply(x, y *Scalar) *Scalar {
+	ref10.ScMulAdd(&s.s, &x.s, &y.s, &scZero)
+	return s
+}
+
+// Invert sets s to the inverse of x, or to zero if x is zero, and returns s.
+func (s *Scalar) Invert(x *Scalar) *Scalar {
+	r := scOne
+	for i := 255; i >= 0; i-- {
+		ref10.ScMulAdd(&r, &r, &r, &scZero)
+		if scOrderMinusTwo[i/8]>>(i%8)&1 == 1 {
+			// The exponent is public, so branching on it is safe.
+			ref10.ScMulAdd(&r, &r, &x.s, &scZero)
+		}
+	}
+	s.s = r
+	return s
+}
+
+// Equal returns 1 if s and x are equal, and 0 otherwise.
+func (s *Scalar) Equal(x *Scalar) int {
+	return subtle.ConstantTimeCompare(s.s[:], x.s[:])
+}
+
+// Bytes returns the canonical 32-byte little-endian encoding of s.
+func (s *Scalar) Bytes() []byte {
+	return append([]byte{}, s.s[:]...)
+}
+
+// SetCanonicalBytes sets s to the little-endian integer x, which must be
+// 32 bytes long and less than l, and returns s.
+// Otherwise it returns nil and an error, and leaves s unchanged.
+func (s *Scalar) SetCanonicalBytes(x []byte) (*Scalar, error) {
+	if len(x) != 32 {
+		return nil, errors.New("edwards25519: invalid scalar length")
+	}
+	var b [32]byte
+	copy(b[:], x)
+	if !ref10.ScMinimal(&b) {
+		return nil, errors.New("edwards25519: non-canonical scalar encoding")
+	}
+	s.s = b
+	return s, nil
+}
+
+// SetUniformBytes sets s to the little-endian integer x, which must be
+// 64 bytes long, reduced modulo l, and returns s.
+// x should be the uniformly random output of a hash function or RNG,
+// as the SHA-512 digests of Ed25519 are.
+func (s *Scalar) SetUniformBytes(x []byte) (*Scalar, error) {
+	if len(x) != 64 {
+		return nil, errors.New("edwards25519: invalid SetUniformBytes input length")
+	}
+	var wide [64]byte
+	copy(wide[:], x)
+	ref10.ScReduce(&s.s, &wide)
+	return s, nil
+}
+
+// SetBytesWithClamping sets s to the 32-byte little-endian x,
+// clamped as Ed25519 clamps its secret scalars, reduced modulo l,
+// and returns s. For x the first half of the SHA-512 digest
+// of a private key seed, ScalarBaseMult(s) is the key's public key.
+func (s *Scalar) SetBytesWithClamping(x []byte) (*Scalar, error) {
+	if len(x) != 32 {
+		return nil, errors.New("edwards25519: invalid SetBytesWithClamping input length")
+	}
+	var wide [64]byte
+	copy(wide[:], x)
+	wide[0] &= 248
+	wide[31] &= 63
+	wide[31] |= 64
+	ref10.ScReduce(&s.s, &wide)
+	return s, nil
+}
@@ -0,0 +1,388 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package elligator2 implements the Elligator 2 map
+// from field elements to points of curve25519 and edwards25519,
+// and its inverse for curve25519.
+//
+// HashToPoint and EncodeToPoint are the edwards25519_XMD:SHA-512_ELL2_RO_
+// and edwards25519_XMD:SHA-512_ELL2_NU_ suites of RFC 9380,
+// which hash arbitrary strings to points of the prime-order subgroup
+// for protocols such as VRFs and PAKEs.
+//
+// Representative inverts the map: it encodes about half
+// of all X25519 public keys as 32-byte strings
+// indistinguishable from random, and MapToCurve25519 decodes them.
+// GenerateKey draws X25519 key pairs until one has a representative.
+// Its public keys carry a random low-order component,
+// which X25519 ignores, because keys in the prime-order subgroup alone
+// would leave their representatives distinguishable from random,
+// as needed by censorship-resistant transports.
+package elligator2
+
+import (
+	cryptorand "crypto/rand"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"io"
+
+	"test-server/golang-x-crypto/ed25519/internal/edwards25519"
+)
+
+const (
+	// RepresentativeSize is the size, in bytes, of representatives.
+	RepresentativeSize = 32
+	// PublicKeySize is the size, in bytes, of X25519 public keys.
+	PublicKeySize = 32
+	// PrivateKeySize is the size, in bytes, of X25519 private keys.
+	PrivateKeySize = 32
+	// PointSize is the size, in bytes, of edwards25519 point encodings.
+	PointSize = 32
+)
+
+// ErrNotRepresentable is returned by Representative
+// for public keys outside the image of the map.
+var ErrNotRepresentable = errors.New("elligator2: public key has no representative")
+
+var errLength = errors.New("elligator2: bad input length")
+
+var (
+	// feSqrtMinusAPlus2 is the non-negative square root of -(A+2),
+	// the scale factor of the map from curve25519 to edwards25519.
+	feSqrtMinusAPlus2 edwards25519.FieldElement
+	// fe2To192 is 2^192, used to reduce 48-byte hash outputs.
+	fe2To192 edwards25519.FieldElement
+	// lowOrder is a point of order 8.
+	lowOrder edwards25519.ExtendedGroupElement
+)
+
+func init() {
+	var one, t edwards25519.FieldElement
+	edwards25519.FeOne(&one)
+	edwards25519.FeAdd(&t, &edwards25519.A, &one)
+	edwards25519.FeAdd(&t, &t, &one)
+	edwards25519.FeNeg(&t, &t)
+	edwards25519.FeSqrtRatio(&feSqrtMinusAPlus2, &t, &one)
+
+	var b [32]byte
+	b[24] = 1
+	edwards25519.FeFromBytes(&fe2To192, &b)
+
+	enc, _ := hex.DecodeString("c7176a703d4dd84fba3c0b760d10670f2a2053fa2c39ccc64ec7fd7792ac037a")
+	copy(b[:], enc)
+	if !lowOrder.FromBytes(&b) {
+		panic("elligator2: bad low-order point")
+	}
+}
+
+// MapToCurve25519 returns the X25519 public key,
+// the Montgomery u-coordinate, that representative maps to.
+// The two most significant bits of representative are ignored.
+func MapToCurve25519(representative []byte) ([]byte, error) {
+	r, err := decodeRepresentative(representative)
+	if err != nil {
+		return nil, err
+	}
+	var u, v edwards25519.FieldElement
+	mapToMontgomery(&u, &v, &r)
+	var b [32]byte
+	edwards25519.FeToBytes(&b, &u)
+	return b[:], nil
+}
+
+// MapToPoint returns the encoding of the edwards25519 point
+// that representative maps to, as the map_to_curve function of RFC 9380.
+// The two most significant bits of representative are ignored.
+// The point is not in general in the prime-order subgroup;
+// HashToPoint and EncodeToPoint clear its cofactor.
+func MapToPoint(representative []byte) ([]byte, error) {
+	r, err := decodeRepresentative(representative)
+	if err != nil {
+		return nil, err
+	}
+	var P edwards25519.ExtendedGroupElement
+	mapToEdwards(&P, &r)
+	var b [32]byte
+	P.ToBytes(&b)
+	return b[:], nil
+}
+
+// HashToPoint hashes message to a point of the prime-order subgroup
+// with the edwards25519_XMD:SHA-512_ELL2_RO_ suite of RFC 9380
+// and the domain separation tag dst, and returns its encoding.
+// Its output is indistinguishable from a random oracle.
+func HashToPoint(message, dst []byte) []byte {
+	u := hashToField(message, dst, 2)
+	var Q0, Q1, P edwards25519.ExtendedGroupElement
+	mapToEdwards(&Q0, &u[0])
+	mapToEdwards(&Q1, &u[1])
+	P.Add(&Q0, &Q1)
+	P.MulByCofactor(&P)
+	var b [32]byte
+	P.ToBytes(&b)
+	return b[:]
+}
+
+// EncodeToPoint hashes message to a point of the prime-order subgroup
+// with the edwards25519_XMD:SHA-512_ELL2_NU_ suite of RFC 9380
+// and the domain separation tag dst, and returns its encoding.
+// It is faster than HashToPoint, but only reaches about half the points,
+// so it suits only protocols proven secure with such an encoding.
+func EncodeToPoint(message, dst []byte) []byte {
+	u := hashToField(message, dst, 1)
+	var P edwards25519.ExtendedGroupElement
+	mapToEdwards(&P, &u[0])
+	P.MulByCofactor(&P)
+	var b [32]byte
+	P.ToBytes(&b)
+	return b[:]
+}
+
+// Representative returns a representative of the X25519 public key,
+// which MapToCurve25519 maps back to publicKey.
+// Every representable key has two representatives up to the padding:
+// the least significant bit of tweak chooses between them,
+// and its two most significant bits become the padding bits.
+// For representatives indistinguishable from random,
+// tweak must be random and publicKey must come from GenerateKey.
+// Representative returns ErrNotRepresentable for the about half
+// of all public keys outside the image of the map.
+func Representative(publicKey []byte, tweak byte) ([]byte, error) {
+	if len(publicKey) != PublicKeySize {
+		return nil, errLength
+	}
+	var b [32]byte
+	copy(b[:], publicKey)
+	var one, u, uPlusA, t, r edwards25519.FieldElement
+	edwards25519.FeOne(&one)
+	edwards25519.FeFromBytes(&u, &b)
+
+	// r = sqrt(-u/(2(u+A))) or sqrt(-(u+A)/(2u)),
+	// computed from 1/sqrt(-2u(u+A)), which exists only for keys in the image.
+	edwards25519.FeAdd(&uPlusA, &u, &edwards25519.A)
+	edwards25519.FeMul(&t, &u, &uPlusA)
+	edwards25519.FeAdd(&t, &t, &t)
+	edwards25519.FeNeg(&t, &t)
+	if edwards25519.FeSqrtRatio(&r, &one, &t) == 0 {
+		// This reveals only how many keys GenerateKey tried.
+		return nil, ErrNotRepresentable
+	}
+	f := u
+	edwards25519.FeCMove(&f, &uPlusA, int32(tweak&1))
+	edwards25519.FeMul(&r, &r, &f)
+
+	// Of r and -r, pick the one at most (p-1)/2, whose double is even,
+	// so that the top two bits of the encoding are free for padding.
+	var twoR, negR edwards25519.FieldElement
+	edwards25519.FeAdd(&twoR, &r, &r)
+	edwards25519.FeNeg(&negR, &r)
+	edwards25519.FeCMove(&r, &negR, feIsNegative(&twoR))
+
+	edwards25519.FeToBytes(&b, &r)
+	b[31] |= tweak & 0xc0
+	return b[:], nil
+}
+
+// GenerateKey generates an X25519 key pair with a representative,
+// using entropy from rand, or crypto/rand if rand is nil.
+// The private key is an ordinary X25519 private key,
+// and X25519 key agreement works unchanged with either key,
+// but the public key also carries a low-order component
+// chosen by the bits of the private key that X25519 clamps away.
+// It is therefore not the X25519 public key of the private key,
+// and GenerateKey is the only way to derive it.
+func GenerateKey(rand io.Reader) (privateKey, publicKey, representative []byte, err error) {
+	if rand == nil {
+		rand = cryptorand.Reader
+	}
+	var buf [PrivateKeySize + 1]byte
+	for {
+		if _, err := io.ReadFull(rand, buf[:]); err != nil {
+			return nil, nil, nil, err
+		}
+		publicKey = dirtyPublicKey(buf[:PrivateKeySize])
+		representative, err = Representative(publicKey, buf[PrivateKeySize])
+		if err == nil {
+			privateKey = append([]byte{}, buf[:PrivateKeySize]...)
+			return privateKey, publicKey, representative, nil
+		}
+	}
+}
+
+// dirtyPublicKey returns the u-coordinate of a*B + k*L,
+// where a is the clamped X25519 scalar of privateKey,
+// k its three low bits, and L the point of order 8.
+func dirtyPublicKey(privateKey []byte) []byte {
+	var a, k [32]byte
+	copy(a[:], privateKey)
+	k[0] = a[0] & 7
+	a[0] &= 248
+	a[31] &= 127
+	a[31] |= 64
+	var P, T edwards25519.ExtendedGroupElement
+	edwards25519.GeScalarMultBase(&P, &a)
+	edwards25519.GeScalarMult(&T, &k, &lowOrder)
+	P.Add(&P, &T)
+
+	// u = (Z+Y)/(Z-Y)
+	var num, den, u edwards25519.FieldElement
+	edwards25519.FeAdd(&num, &P.Z, &P.Y)
+	edwards25519.FeSub(&den, &P.Z, &P.Y)
+	edwards25519.FeInvert(&den, &den)
+	edwards25519.FeMul(&u, &num, &den)
+	var b [32]byte
+	edwards25519.FeToBytes(&b, &u)
+	return b[:]
+}
+
+func decodeRepresentative(representative []byte) (edwards25519.FieldElement, error) {
+	var r edwards25519.FieldElement
+	if len(representative) != RepresentativeSize {
+		return r, errLength
+	}
+	var b [32]byte
+	copy(b[:], representative)
+	b[31] &= 63
+	edwards25519.FeFromBytes(&r, &b)
+	return r, nil
+}
+
+// mapToMontgomery sets (u, v) to the curve25519 point r maps to,
+// the map_to_curve_elligator2 function of RFC 9380 with Z = 2.
+func mapToMontgomery(u, v, r *edwards25519.FieldElement) {
+	var one, x1, x2, gx1, gx2, y1, y2 edwards25519.FieldElement
+	edwards25519.FeOne(&one)
+
+	// x1 = -A/(1 + 2r^2), whose denominator is never zero
+	// since -1/2 is not a square.
+	edwards25519.FeSquare(&x1, r)
+	edwards25519.FeAdd(&x1, &x1, &x1)
+	edwards25519.FeAdd(&x1, &x1, &one)
+	edwards25519.FeInvert(&x1, &x1)
+	edwards25519.FeMul(&x1, &x1, &edwards25519.A)
+	edwards25519.FeNeg(&x1, &x1)
+	curveRHS(&gx1, &x1)
+
+	// x2 = -x1 - A
+	edwards25519.FeNeg(&x2, &x1)
+	edwards25519.FeSub(&x2, &x2, &edwards25519.A)
+	curveRHS(&gx2, &x2)
+
+	// Exactly one of g(x1) and g(x2) is a square.
+	// The square root is taken negative for x1 and non-negative for x2.
+	isSquare := edwards25519.FeSqrtRatio(&y1, &gx1, &one)
+	edwards25519.FeSqrtRatio(&y2, &gx2, &one)
+	edwards25519.FeNeg(&y1, &y1)
+
+	*u = x2
+	edwards25519.FeCMove(u, &x1, isSquare)
+	*v = y2
+	edwards25519.FeCMove(v, &y1, isSquare)
+}
+
+// curveRHS sets out = x^3 + A*x^2 + x.
+func curveRHS(out, x *edwards25519.FieldElement) {
+	var one, t edwards25519.FieldElement
+	edwards25519.FeOne(&one)
+	edwards25519.FeAdd(&t, x, &edwards25519.A)
+	edwards25519.FeMul(&t, &t, x)
+	edwards25519.FeAdd(&t, &t, &one)
+	edwards25519.FeMul(out, &t, x)
+}
+
+// mapToEdwards sets P to the edwards25519 point r maps to,
+// the curve25519 point of mapToMontgomery under the rational map of RFC 9380.
+func mapToEdwards(P *edwards25519.ExtendedGroupElement, r *edwards25519.FieldElement) {
+	var s, t edwards25519.FieldElement
+	mapToMontgomery(&s, &t, r)
+
+	// (x, y) = (sqrt(-(A+2)) * s/t, (s-1)/(s+1)),
+	// or the identity where a denominator is zero.
+	var one, sPlusOne, sMinusOne, den, inv edwards25519.FieldElement
+	edwards25519.FeOne(&one)
+	edwards25519.FeAdd(&sPlusOne, &s, &one)
+	edwards25519.FeSub(&sMinusOne, &s, &one)
+	edwards25519.FeMul(&den, &t, &sPlusOne)
+	edwards25519.FeInvert(&inv, &den)
+
+	edwards25519.FeMul(&P.X, &s, &sPlusOne)
+	edwards25519.FeMul(&P.X, &P.X, &inv)
+	edwards25519.FeMul(&P.X, &P.X, &feSqrtMinusAPlus2)
+	edwards25519.FeMul(&P.Y, &sMinusOne, &t)
+	edwards25519.FeMul(&P.Y, &P.Y, &inv)
+	edwards25519.FeCMove(&P.Y, &one, feIsZero(&den))
+	edwards25519.FeOne(&P.Z)
+	edwards25519.FeMul(&P.T, &P.X, &P.Y)
+}
+
+// hashToField returns count field elements derived from message
+// with expand_message_xmd and SHA-512, the hash_to_field function of RFC 9380.
+func hashToField(message, dst []byte, count int) []edwards25519.FieldElement {
+	const L = 48
+	uniform := expandMessageXMD(message, dst, count*L)
+	u := make([]edwards25519.FieldElement, count)
+	for i := range u {
+		// Each element is a 384-bit big-endian integer mod p,
+		// reduced as lo + hi * 2^192 from its two 192-bit halves.
+		var lo, hi [32]byte
+		for j := 0; j < L/2; j++ {
+			lo[j] = uniform[(i+1)*L-1-j]
+			hi[j] = uniform[(i+1)*L-1-L/2-j]
+		}
+		var fLo, fHi edwards25519.FieldElement
+		edwards25519.FeFromBytes(&fLo, &lo)
+		edwards25519.FeFromBytes(&fHi, &hi)
+		edwards25519.FeMul(&fHi, &fHi, &fe2To192)
+		edwards25519.FeAdd(&u[i], &fLo, &fHi)
+	}
+	return u
+}
+
+// expandMessageXMD is the expand_message_xmd function of RFC 9380
+// with SHA-512, for outputs of at most 255 blocks.
+func expandMessageXMD(message, dst []byte, n int) []byte {
+	h := sha512.New()
+	if len(dst) > 255 {
+		h.Write([]byte("H2C-OVERSIZE-DST-"))
+		h.Write(dst)
+		dst = h.Sum(nil)
+		h.Reset()
+	}
+	dstPrime := append(append([]byte{}, dst...), byte(len(dst)))
+
+	h.Write(make([]byte, h.BlockSize()))
+	h.Write(message)
+	h.Write([]byte{byte(n >> 8), byte(n), 0})
+	h.Write(dstPrime)
+	b0 := h.Sum(nil)
+
+	out := make([]byte, 0, n+h.Size())
+	bi := make([]byte, h.Size())
+	for i := 1; len(out) < n; i++ {
+		for j := range bi {
+			bi[j] ^= b0[j]
+		}
+		h.Reset()
+		h.Write(bi)
+		h.Write([]byte{byte(i)})
+		h.Write(dstPrime)
+		bi = h.Sum(bi[:0])
+		out = append(out, bi...)
+	}
+	return out[:n]
+}
+
+// The edwards25519 predicates carry their argument in place,
+// so these wrappers test a copy instead.
+
+func feIsZero(f *edwards25519.FieldElement) int32 {
+	t := *f
+	return 1 - edwards25519.FeIsNonZero(&t)
+}
+
+func feIsNegative(f *edwards25519.FieldElement) int32 {
+	t := *f
+	return int32(edwards25519.FeIsNegative(&t))
+}
@@ -0,0 +1,227 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package elligator2
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/curve25519"
+
+	"test-server/golang-x-crypto/ed25519/internal/edwards25519"
+)
+
+// Elligator 2 vectors from Monocypher, with the padding bits cleared.
+var mapVectors = []struct {
+	representative, u string
+}{
+	{"0000000000000000000000000000000000000000000000000000000000000000", "0000000000000000000000000000000000000000000000000000000000000000"},
+	{"673a505e107189ee54ca93310ac42e4545e9e59050aaac6f8b5f64295c8ec02f", "242ae39ef158ed60f20b89396d7d7eef5374aba15dc312a6aea6d1e57cacf85e"},
+	{"922688fa428d42bc1fa8806998fbc5959ae801817e85a42a45e8ec25a0d7541a", "696f341266c64bcfa7afa834f8c34b2730be11c932e08474d1a22f26ed82410b"},
+	{"0d3b0eb88b74ed13d5f6a130e03c4ad607817057dc227152827c0506a538bb3a", "0b00df174d9fb0b6ee584d2cf05613130bad18875268c38b377e86dfefef177f"},
+	{"01a3ea5658f4e00622eeacf724e0bd82068992fae66ed2b04a8599be16662e35", "7ae4c58bc647b5646c9f5ae4c2554ccbf7c6e428e7b242a574a5a9c293c21f7e"},
+	{"69599ab5a829c3e9515128d368da7354a8b69fcee4e34d0a668b783b6cae550f", "09024abaaef243e3b69366397e8dfc1fdc14a0ecc7cf497cbe4f328839acce69"},
+}
+
+func TestMapToCurve25519(t *testing.T) {
+	for _, v := range mapVectors {
+		r, _ := hex.DecodeString(v.representative)
+		u, err := MapToCurve25519(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(u); got != v.u {
+			t.Errorf("MapToCurve25519(%s) = %s, want %s", v.representative, got, v.u)
+		}
+		// the padding bits are ignored
+		r[31] |= 0xc0
+		if u2, _ := MapToCurve25519(r); !bytes.Equal(u, u2) {
+			t.Errorf("MapToCurve25519 depends on the padding bits")
+		}
+	}
+}
+
+// Test vectors from RFC 9380, Appendix J.5.
+var hashVectors = []struct {
+	msg, x, y string
+}{
+	{"", "3c3da6925a3c3c268448dcabb47ccde5439559d9599646a8260e47b1e4822fc6", "09a6c8561a0b22bef63124c588ce4c62ea83a3c899763af26d795302e115dc21"},
+	{"abc", "608040b42285cc0d72cbb3985c6b04c935370c7361f4b7fbdb1ae7f8c1a8ecad", "1a8395b88338f22e435bbd301183e7f20a5f9de643f11882fb237f88268a5531"},
+	{"abcdef0123456789", "6d7fabf47a2dc03fe7d47f7dddd21082c5fb8f86743cd020f3fb147d57161472", "53060a3d140e7fbcda641ed3cf42c88a75411e648a1add71217f70ea8ec561a6"},
+	{"a512_" + strings.Repeat("a", 512), "0efcfde5898a839b00997fbe40d2ebe950bc81181afbd5cd6b9618aa336c1e8c", "6dc2fc04f266c5c27f236a80b14f92ccd051ef1ff027f26a07f8c0f327d8f995"},
+}
+
+var encodeVectors = []struct {
+	msg, x, y string
+}{
+	{"", "1ff2b70ecf862799e11b7ae744e3489aa058ce805dd323a936375a84695e76da", "222e314d04a4d5725e9f2aff9fb2a6b69ef375a1214eb19021ceab2d687f0f9b"},
+	{"abc", "5f13cc69c891d86927eb37bd4afc6672360007c63f68a33ab423a3aa040fd2a8", "67732d50f9a26f73111dd1ed5dba225614e538599db58ba30aaea1f5c827fa42"},
+	{"abcdef0123456789", "1dd2fefce934ecfd7aae6ec998de088d7dd03316aa1847198aecf699ba6613f1", "2f8a6c24dd1adde73909cada6a4a137577b0f179d336685c4a955a0a8e1a86fb"},
+}
+
+// encodePoint returns the edwards25519 encoding of the point
+// with big-endian hexadecimal coordinates x and y.
+func encodePoint(x, y string) string {
+	X, _ := new(big.Int).SetString(x, 16)
+	Y, _ := new(big.Int).SetString(y, 16)
+	b := make([]byte, 32)
+	Y.FillBytes(b)
+	for i, j := 0, 31; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+	b[31] |= byte(X.Bit(0)) << 7
+	return hex.EncodeToString(b)
+}
+
+func TestHashToPoint(t *testing.T) {
+	dst := []byte("QUUX-V01-CS02-with-edwards25519_XMD:SHA-512_ELL2_RO_")
+	for _, v := range hashVectors {
+		got := hex.EncodeToString(HashToPoint([]byte(v.msg), dst))
+		if want := encodePoint(v.x, v.y); got != want {
+			t.Errorf("HashToPoint(%.10q) = %s, want %s", v.msg, got, want)
+		}
+	}
+}
+
+func TestEncodeToPoint(t *testing.T) {
+	dst := []byte("QUUX-V01-CS02-with-edwards25519_XMD:SHA-512_ELL2_NU_")
+	for _, v := range encodeVectors {
+		got := hex.EncodeToString(EncodeToPoint([]byte(v.msg), dst))
+		if want := encodePoint(v.x, v.y); got != want {
+			t.Errorf("EncodeToPoint(%q) = %s, want %s", v.msg, got, want)
+		}
+	}
+}
+
+func TestMapToPointAgrees(t *testing.T) {
+	// The edwards25519 point maps to the curve25519 point under u = (1+y)/(1-y).
+	for i := 0; i < 32; i++ {
+		r := make([]byte, RepresentativeSize)
+		rand.Read(r)
+		u, _ := MapToCurve25519(r)
+		p, _ := MapToPoint(r)
+		var b [32]byte
+		copy(b[:], p)
+		b[31] &= 127
+		var y, one, num, den edwards25519.FieldElement
+		edwards25519.FeFromBytes(&y, &b)
+		edwards25519.FeOne(&one)
+		edwards25519.FeAdd(&num, &one, &y)
+		edwards25519.FeSub(&den, &one, &y)
+		edwards25519.FeInvert(&den, &den)
+		edwards25519.FeMul(&num, &num, &den)
+		edwards25519.FeToBytes(&b, &num)
+		if !bytes.Equal(b[:], u) {
+			t.Fatalf("MapToPoint and MapToCurve25519 disagree for %x", r)
+		}
+	}
+}
+
+func TestRepresentativeRoundTrip(t *testing.T) {
+	for i := 0; i < 64; i++ {
+		r := make([]byte, RepresentativeSize)
+		rand.Read(r)
+		r[31] &= 63
+		u, _ := MapToCurve25519(r)
+		found := false
+		for tweak := byte(0); tweak < 2; tweak++ {
+			r2, err := Representative(u, tweak)
+			if err != nil {
+				t.Fatalf("Representative(%x): %v", u, err)
+			}
+			if u2, _ := MapToCurve25519(r2); !bytes.Equal(u, u2) {
+				t.Fatalf("representative %x maps to %x, want %x", r2, u2, u)
+			}
+			found = found || bytes.Equal(r, r2)
+		}
+		// r and -r map to the same key; only the smaller comes back.
+		var neg big.Int
+		if found != (representativeInt(r).Cmp(neg.Rsh(p, 1)) <= 0) {
+			t.Errorf("representative %x recovered = %v", r, found)
+		}
+	}
+}
+
+var p, _ = new(big.Int).SetString("7fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffed", 16)
+
+func representativeInt(r []byte) *big.Int {
+	b := make([]byte, len(r))
+	for i := range r {
+		b[len(r)-1-i] = r[i]
+	}
+	return new(big.Int).SetBytes(b)
+}
+
+func TestNotRepresentable(t *testing.T) {
+	zero := make([]byte, PublicKeySize)
+	if _, err := Representative(zero, 0); err != ErrNotRepresentable {
+		t.Errorf("Representative(0) error = %v, want ErrNotRepresentable", err)
+	}
+	misses := 0
+	for i := 0; i < 64; i++ {
+		priv := make([]byte, 32)
+		rand.Read(priv)
+		pub, _ := curve25519.X25519(priv, curve25519.Basepoint)
+		if _, err := Representative(pub, 0); err == ErrNotRepresentable {
+			misses++
+		}
+	}
+	if misses == 0 || misses == 64 {
+		t.Errorf("%d of 64 keys not representable, want about half", misses)
+	}
+}
+
+func TestGenerateKey(t *testing.T) {
+	privA, pubA, reprA, err := GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privB, pubB, _, err := GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u, _ := MapToCurve25519(reprA); !bytes.Equal(u, pubA) {
+		t.Errorf("representative maps to %x, want %x", u, pubA)
+	}
+	ab, _ := curve25519.X25519(privA, pubB)
+	ba, _ := curve25519.X25519(privB, pubA)
+	if !bytes.Equal(ab, ba) {
+		t.Errorf("key agreement mismatch")
+	}
+
+	// The low-order component of the public keys is random.
+	seen := make(map[byte]bool)
+	for i := 0; i < 64; i++ {
+		priv, pub, _, _ := GenerateKey(nil)
+		std, _ := curve25519.X25519(priv, curve25519.Basepoint)
+		if !bytes.Equal(pub, std) {
+			seen[priv[0]&7] = true
+		} else if priv[0]&7 != 0 {
+			t.Fatalf("public key lacks its low-order component")
+		}
+	}
+	if len(seen) < 4 {
+		t.Errorf("only %d low-order components seen", len(seen))
+	}
+}
+
+func TestLowOrderPoint(t *testing.T) {
+	var P edwards25519.ExtendedGroupElement
+	P.MulByCofactor(&lowOrder)
+	if !P.IsIdentity() {
+		t.Fatalf("[8]L is not the identity")
+	}
+	var Q edwards25519.ExtendedGroupElement
+	var four [32]byte
+	four[0] = 4
+	edwards25519.GeScalarMult(&Q, &four, &lowOrder)
+	if Q.IsIdentity() {
+		t.Errorf("[4]L is the identity")
+	}
+}
@@ -0,0 +1,210 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package entropy provides randomness for key generation and cosigning
+// on devices whose random number generators cannot be fully trusted.
+//
+// A Provider draws from one or more sources, typically the operating
+// system's generator and a hardware RNG, runs the continuous health tests
+// of NIST SP 800-90B on each source's raw output, and hashes the outputs
+// together, so that its output is unpredictable as long as any one
+// healthy source is. Once a source fails a test the Provider fails closed:
+// every later Read returns a *HealthError.
+//
+// A Provider is an io.Reader, and so can be passed wherever this module
+// takes a rand argument:
+//
+//	p := entropy.New(nil, hwrng)
+//	pub, priv, err := ed25519.GenerateKey(p)
+//	commit, secret, err := cosi.Commit(p)
+package entropy
+
+import (
+	cryptorand "crypto/rand"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// The health test cutoffs assume a conservative min-entropy
+// of one bit per byte of source output, as SP 800-90B, Section 4.4,
+// derives them for a false alarm probability of 2^-20 per test.
+const (
+	// repetitionCutoff is the number of identical consecutive bytes
+	// that fails the Repetition Count Test: 1 + ceil(20/H).
+	repetitionCutoff = 21
+
+	// proportionWindow is the window of the Adaptive Proportion Test,
+	// and proportionCutoff the number of occurrences of its first byte
+	// within the window that fails it.
+	proportionWindow = 512
+	proportionCutoff = 410
+
+	// startupSamples is the number of bytes tested and discarded
+	// from every source before it contributes to the output.
+	startupSamples = 1024
+)
+
+// HealthError reports a source that failed a health test or a read.
+type HealthError struct {
+	Source int    // index of the source in the call to New
+	Test   string // "repetition count", "adaptive proportion" or "read"
+	Err    error  // the read error, if Test is "read"
+}
+
+func (e *HealthError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("entropy: source %d failed: %v", e.Source, e.Err)
+	}
+	return fmt.Sprintf("entropy: source %d failed the %s test", e.Source, e.Test)
+}
+
+func (e *HealthError) Unwrap() error { return e.Err }
+
+// source is a raw entropy source with its continuous health test state.
+type source struct {
+	r io.Reader
+
+	last      byte // Repetition Count Test
+	repeats   int
+	first     byte // Adaptive Proportion Test
+	seen      int
+	windowPos int
+}
+
+// test runs the health tests over b,
+// returning the name of the first test that fails.
+func (s *source) test(b []byte) string {
+	for _, x := range b {
+		if x == s.last && s.repeats > 0 {
+			s.repeats++
+		} else {
+			s.last, s.repeats = x, 1
+		}
+		if s.repeats >= repetitionCutoff {
+			return "repetition count"
+		}
+
+		if s.windowPos == 0 {
+			s.first, s.seen = x, 1
+		} else if x == s.first {
+			s.seen++
+		}
+		if s.seen >= proportionCutoff {
+			return "adaptive proportion"
+		}
+		s.windowPos = (s.windowPos + 1) % proportionWindow
+	}
+	return ""
+}
+
+// A Provider mixes several health-tested entropy sources.
+// It is safe for concurrent use.
+type Provider struct {
+	mu      sync.Mutex
+	sources []*source
+	started bool
+	counter uint64
+	block   [sha512.Size]byte
+	raw     []byte
+	off     int
+	err     error
+}
+
+// New returns a Provider mixing sources.
+// A nil source, or no source at all, stands for crypto/rand.
+func New(sources ...io.Reader) *Provider {
+	if len(sources) == 0 {
+		sources = []io.Reader{nil}
+	}
+	p := &Provider{
+		sources: make([]*source, len(sources)),
+		raw:     make([]byte, startupSamples),
+		off:     sha512.Size,
+	}
+	for i, r := range sources {
+		if r == nil {
+			r = cryptorand.Reader
+		}
+		p.sources[i] = &source{r: r}
+	}
+	return p
+}
+
+// Read fills b with mixed entropy. It returns a *HealthError
+// if a source failed to be read or failed a health test,
+// on this call or any earlier one.
+func (p *Provider) Read(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return 0, p.err
+	}
+	if !p.started {
+		for i := range p.sources {
+			if err := p.draw(i, p.raw); err != nil {
+				p.err = err
+				return 0, err
+			}
+		}
+		p.started = true
+	}
+	n := 0
+	for n < len(b) {
+		if p.off == len(p.block) {
+			if err := p.refill(); err != nil {
+				p.err = err
+				return n, err
+			}
+		}
+		m := copy(b[n:], p.block[p.off:])
+		clear(p.block[p.off : p.off+m])
+		p.off += m
+		n += m
+	}
+	return n, nil
+}
+
+// Err returns the error that made p fail, or nil if p is healthy.
+func (p *Provider) Err() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err
+}
+
+// refill computes the next output block as
+// SHA-512(counter || block from source 0 || block from source 1 || ...),
+// taking a full block from every source.
+func (p *Provider) refill() error {
+	h := sha512.New()
+	var ctr [8]byte
+	binary.LittleEndian.PutUint64(ctr[:], p.counter)
+	p.counter++
+	h.Write(ctr[:])
+	raw := p.raw[:sha512.Size]
+	for i := range p.sources {
+		if err := p.draw(i, raw); err != nil {
+			return err
+		}
+		h.Write(raw)
+	}
+	clear(raw)
+	h.Sum(p.block[:0])
+	p.off = 0
+	return nil
+}
+
+// draw fills b from source i and runs the health tests over it.
+func (p *Provider) draw(i int, b []byte) error {
+	s := p.sources[i]
+	if _, err := io.ReadFull(s.r, b); err != nil {
+		return &HealthError{Source: i, Test: "read", Err: err}
+	}
+	if test := s.test(b); test != "" {
+		return &HealthError{Source: i, Test: test}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package entropy
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"testing"
+)
+
+// constReader returns the same byte forever.
+type constReader byte
+
+func (c constReader) Read(b []byte) (int, error) {
+	for i := range b {
+		b[i] = byte(c)
+	}
+	return len(b), nil
+}
+
+// biasedReader returns mostly zeros, never repeating a byte many times:
+// it has too little entropy for the Adaptive Proportion Test.
+type biasedReader struct{ n int }
+
+func (r *biasedReader) Read(b []byte) (int, error) {
+	for i := range b {
+		r.n++
+		if r.n%8 == 0 {
+			b[i] = byte(r.n >> 3)
+		} else {
+			b[i] = 0
+		}
+	}
+	return len(b), nil
+}
+
+// healthError returns the HealthError reading from p,
+// which must fail.
+func healthError(t *testing.T, p *Provider) *HealthError {
+	t.Helper()
+	_, err := io.ReadFull(p, make([]byte, 4096))
+	var he *HealthError
+	if !errors.As(err, &he) {
+		t.Fatalf("Read error = %v, want a HealthError", err)
+	}
+	if p.Err() != err {
+		t.Errorf("Err() = %v, want %v", p.Err(), err)
+	}
+	if _, err2 := p.Read(make([]byte, 1)); err2 != err {
+		t.Errorf("failure is not sticky: %v", err2)
+	}
+	return he
+}
+
+func TestProvider(t *testing.T) {
+	p := New(nil, rand.Reader)
+	a := make([]byte, 100)
+	b := make([]byte, 100)
+	if _, err := io.ReadFull(p, a); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.ReadFull(p, b); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(a, b) {
+		t.Errorf("outputs repeat")
+	}
+	if p.Err() != nil {
+		t.Errorf("healthy provider reports %v", p.Err())
+	}
+}
+
+func TestRepetitionCount(t *testing.T) {
+	he := healthError(t, New(nil, constReader(0x42)))
+	if he.Source != 1 || he.Test != "repetition count" {
+		t.Errorf("got %v, want source 1 failing the repetition count test", he)
+	}
+}
+
+func TestAdaptiveProportion(t *testing.T) {
+	he := healthError(t, New(&biasedReader{}))
+	if he.Source != 0 || he.Test != "adaptive proportion" {
+		t.Errorf("got %v, want source 0 failing the adaptive proportion test", he)
+	}
+}
+
+func TestReadFailure(t *testing.T) {
+	he := healthError(t, New(bytes.NewReader(make([]byte, 10))))
+	if he.Test != "read" || !errors.Is(he, io.ErrUnexpectedEOF) {
+		t.Errorf("got %v, want a read failure", he)
+	}
+}
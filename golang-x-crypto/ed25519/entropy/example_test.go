@@ -0,0 +1,33 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package entropy_test
+
+import (
+	"crypto/rand"
+	"fmt"
+
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/golang-x-crypto/ed25519/cosi"
+	"test-server/golang-x-crypto/ed25519/entropy"
+)
+
+func Example() {
+	// hwrng would be the device's hardware generator, e.g. /dev/hwrng.
+	hwrng := rand.Reader
+	p := entropy.New(nil, hwrng)
+
+	pub, _, err := ed25519.GenerateKey(p)
+	if err != nil {
+		fmt.Println("key generation refused:", err)
+		return
+	}
+	commit, _, err := cosi.Commit(p)
+	if err != nil {
+		fmt.Println("commit refused:", err)
+		return
+	}
+	fmt.Println(len(pub), len(commit))
+	// Output: 32 32
+}
@@ -0,0 +1,152 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"crypto"
+	"crypto/sha512"
+	"io"
+	"strconv"
+
+	"test-server/golang-x-crypto/ed25519/internal/edwards25519"
+)
+
+// ExpandedPublicKey is a public key decoded for verification,
+// for verifiers that check many signatures by the same key:
+// Verify decodes its public key on every call, at the cost of
+// a field square root, which an ExpandedPublicKey pays only once.
+//
+// An ExpandedPublicKey is safe for concurrent use
+// once it has been created and, optionally, precomputed.
+type ExpandedPublicKey struct {
+	publicKey PublicKey
+	negA      edwards25519.ExtendedGroupElement // -A, as the verification equation uses it
+	table     *edwards25519.DoubleScalarMultTable
+}
+
+// NewExpandedPublicKey decodes publicKey, returning ErrPublicKeySize
+// or ErrInvalidPoint if it is not a valid encoding of a curve point.
+func NewExpandedPublicKey(publicKey PublicKey) (*ExpandedPublicKey, error) {
+	if len(publicKey) != PublicKeySize {
+		return nil, ErrPublicKeySize
+	}
+	k := &ExpandedPublicKey{publicKey: append(PublicKey{}, publicKey...)}
+	if !decodeNegated(&k.negA, publicKey) {
+		return nil, ErrInvalidPoint
+	}
+	return k, nil
+}
+
+// Precompute additionally stores the table of odd multiples of the key
+// that every verification otherwise builds, saving eight point
+// additions per verification at the cost of about 1.3 KiB of memory.
+// It returns k, and must not be called concurrently with k's other methods.
+func (k *ExpandedPublicKey) Precompute() *ExpandedPublicKey {
+	if k.table == nil {
+		k.table = new(edwards25519.DoubleScalarMultTable)
+		k.table.Init(&k.negA)
+	}
+	return k
+}
+
+// PublicKey returns the public key k was created from.
+func (k *ExpandedPublicKey) PublicKey() PublicKey {
+	return append(PublicKey{}, k.publicKey...)
+}
+
+// Verify reports whether sig is a valid signature of message by k,
+// exactly as Verify does for k's public key.
+func (k *ExpandedPublicKey) Verify(message, sig []byte) bool {
+	return verify(k.publicKey, k, message, sig, domPrefixPure, "", VerifyDefault) == nil
+}
+
+// VerifyErr is like Verify, but returns the error VerifyErr would.
+func (k *ExpandedPublicKey) VerifyErr(message, sig []byte) error {
+	return verify(k.publicKey, k, message, sig, domPrefixPure, "", VerifyDefault)
+}
+
+// VerifyWithOptions verifies sig as VerifyWithOptions does for k's public key.
+// The VerifyZIP215 mode decodes the key under its own rules,
+// and so does not benefit from k.
+func (k *ExpandedPublicKey) VerifyWithOptions(message, sig []byte, opts *Options) error {
+	return verifyWithOptions(k.publicKey, k, message, sig, opts)
+}
+
+// decodeNegated sets negA to the negation of the point encoded by publicKey,
+// and reports whether publicKey is a valid encoding.
+func decodeNegated(negA *edwards25519.ExtendedGroupElement, publicKey []byte) bool {
+	var publicKeyBytes [32]byte
+	copy(publicKeyBytes[:], publicKey)
+	if !negA.FromBytes(&publicKeyBytes) {
+		return false
+	}
+	edwards25519.FeNeg(&negA.X, &negA.X)
+	edwards25519.FeNeg(&negA.T, &negA.T)
+	return true
+}
+
+// ExpandedPrivateKey is a private key with the secret scalar and nonce prefix
+// derived from its seed, which Sign and PrivateKey.Sign rehash on every call.
+// High-throughput signers can sign through an ExpandedPrivateKey
+// to skip that hash; the signatures are the same.
+//
+// An ExpandedPrivateKey implements crypto.Signer, with the options
+// of PrivateKey.Sign, and is safe for concurrent use.
+type ExpandedPrivateKey struct {
+	scalar    [32]byte
+	prefix    [32]byte
+	publicKey PublicKey
+}
+
+// NewExpandedPrivateKey expands privateKey.
+// It will panic if len(privateKey) is not PrivateKeySize.
+func NewExpandedPrivateKey(privateKey PrivateKey) *ExpandedPrivateKey {
+	if l := len(privateKey); l != PrivateKeySize {
+		panic("ed25519: bad private key length: " + strconv.Itoa(l))
+	}
+	digest := sha512.Sum512(privateKey[:SeedSize])
+	k := &ExpandedPrivateKey{publicKey: append(PublicKey{}, privateKey[SeedSize:]...)}
+	copy(k.scalar[:], digest[:32])
+	copy(k.prefix[:], digest[32:])
+	clear(digest[:])
+	k.scalar[0] &= 248
+	k.scalar[31] &= 63
+	k.scalar[31] |= 64
+	return k
+}
+
+// Public returns the PublicKey corresponding to k.
+func (k *ExpandedPrivateKey) Public() crypto.PublicKey {
+	return append(PublicKey{}, k.publicKey...)
+}
+
+// Sign signs message with k, as PrivateKey.Sign does.
+func (k *ExpandedPrivateKey) Sign(rand io.Reader, message []byte, opts crypto.SignerOpts) ([]byte, error) {
+	domPrefix, context, noise, err := signerOpts(rand, message, opts)
+	if err != nil {
+		return nil, err
+	}
+	return signExpanded(&k.scalar, k.prefix[:], k.publicKey, message, domPrefix, context, noise), nil
+}
+
+// SignMessage returns the Ed25519 signature of message by k,
+// as the package-level Sign does for the key k was expanded from.
+func (k *ExpandedPrivateKey) SignMessage(message []byte) []byte {
+	return signExpanded(&k.scalar, k.prefix[:], k.publicKey, message, domPrefixPure, "", nil)
+}
+
+// AppendSign appends the Ed25519 signature of message by k to dst and
+// returns the extended slice. Like the package-level AppendSign,
+// it does not allocate if dst has room for SignatureSize more bytes.
+func (k *ExpandedPrivateKey) AppendSign(dst, message []byte) []byte {
+	return appendSignExpanded(dst, &k.scalar, k.prefix[:], k.publicKey, message, domPrefixPure, "", nil)
+}
+
+// Zeroize overwrites the secrets of k with zeros.
+// k must not be used afterwards.
+func (k *ExpandedPrivateKey) Zeroize() {
+	clear(k.scalar[:])
+	clear(k.prefix[:])
+}
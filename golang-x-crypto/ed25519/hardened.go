@@ -0,0 +1,107 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	cryptorand "crypto/rand"
+	"crypto/sha512"
+	"io"
+	"strconv"
+
+	"test-server/golang-x-crypto/ed25519/internal/edwards25519"
+)
+
+var (
+	scOne      = [32]byte{1}
+	scMinusOne = [32]byte{0xec, 0xd3, 0xf5, 0x5c, 0x1a, 0x63, 0x12, 0x58, 0xd6, 0x9c, 0xf7, 0xa2, 0xde, 0xf9, 0xde, 0x14, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x10}
+)
+
+// SignHardened is like Sign, but hardened against side channels
+// for signers sharing hardware with potential attackers,
+// at the cost of about twice the work of Sign.
+// It reads 128 bytes from rand, or crypto/rand if rand is nil,
+// and returns an error only if that fails.
+//
+// The nonce r is hedged as with Options.Hedged,
+// so that it never repeats for a message.
+// R = [r]B is computed as [r+b]B - [b]B for a random blinding scalar b,
+// so that neither base-point multiplication works on r itself,
+// and S = k*a + r as k*(a-c) + (k*c + r) for a random c.
+// As in Sign, the base-point multiplications select table entries
+// by scanning a whole row rather than by indexing with secret digits.
+//
+// The signature verifies like any other,
+// but is not deterministic. It will panic
+// if len(privateKey) is not PrivateKeySize.
+func SignHardened(privateKey PrivateKey, message []byte, rand io.Reader) ([]byte, error) {
+	if l := len(privateKey); l != PrivateKeySize {
+		panic("ed25519: bad private key length: " + strconv.Itoa(l))
+	}
+	if rand == nil {
+		rand = cryptorand.Reader
+	}
+	var random [hedgeNoiseSize + 64]byte
+	if _, err := io.ReadFull(rand, random[:]); err != nil {
+		return nil, err
+	}
+
+	digest := sha512.Sum512(privateKey[:32])
+	var a [32]byte
+	copy(a[:], digest[:32])
+	a[0] &= 248
+	a[31] &= 63
+	a[31] |= 64
+
+	// r = H(Z || prefix || pad || M)
+	h := sha512.New()
+	var wide [64]byte
+	h.Write(random[:hedgeNoiseSize])
+	h.Write(digest[32:])
+	h.Write(make([]byte, h.BlockSize()-hedgeNoiseSize-32))
+	h.Write(message)
+	h.Sum(wide[:0])
+	var r [32]byte
+	edwards25519.ScReduce(&r, &wide)
+
+	// The blinding scalars b and c are drawn from one hash,
+	// so that a biased rand biases neither.
+	h.Reset()
+	h.Write(random[hedgeNoiseSize:])
+	h.Write(digest[32:])
+	h.Sum(wide[:0])
+	var b, c [32]byte
+	edwards25519.ScReduce(&b, &wide)
+	wide = sha512.Sum512(wide[:])
+	edwards25519.ScReduce(&c, &wide)
+
+	// R = [r+b]B - [b]B
+	var rb [32]byte
+	edwards25519.ScMulAdd(&rb, &scOne, &r, &b)
+	var R, bB edwards25519.ExtendedGroupElement
+	edwards25519.GeScalarMultBase(&R, &rb)
+	edwards25519.GeScalarMultBase(&bB, &b)
+	R.Sub(&R, &bB)
+	var encodedR [32]byte
+	R.ToBytes(&encodedR)
+
+	h.Reset()
+	h.Write(encodedR[:])
+	h.Write(privateKey[32:])
+	h.Write(message)
+	h.Sum(wide[:0])
+	var k [32]byte
+	edwards25519.ScReduce(&k, &wide)
+
+	// S = k*(a-c) + (k*c + r)
+	var aMinusC, t, s [32]byte
+	edwards25519.ScMulAdd(&aMinusC, &scMinusOne, &c, &a)
+	edwards25519.ScMulAdd(&t, &k, &c, &r)
+	edwards25519.ScMulAdd(&s, &k, &aMinusC, &t)
+
+	signature := make([]byte, SignatureSize)
+	copy(signature, encodedR[:])
+	copy(signature[32:], s[:])
+	return signature, nil
+}
@@ -0,0 +1,29 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package edwards25519
+
+// The implementation is selected at build time, by GOARCH and these tags:
+//
+//	ed25519_ref10  ten 25.5-bit limbs (fe_ref10.go), the default on 32-bit
+//	               platforms
+//	purego         no assembly, no CPU feature detection and no
+//	               architecture-specific Go code
+//
+// Without ed25519_ref10, 64-bit platforms (amd64, arm64, ppc64le, s390x,
+// riscv64, loong64) use the five 51-bit limbs of fe_radix51.go. On arm64,
+// unless purego is set, FeMul and FeSquare use the row-ordered code of
+// fe_rows.go (fe_mul_arm64.go). On amd64, unless purego or ed25519_ref10 is
+// set, the 4-way AVX2 code of fe4_amd64.s is used at run time when the CPU
+// supports it. Every other configuration is pure Go.
+
+// Backend returns the implementation in use: the field arithmetic, one of
+// "radix51", "radix51-arm64" or "ref10", followed by "+avx2" when the vector
+// code is active.
+func Backend() string {
+	if useAVX2 {
+		return fieldBackend + "+avx2"
+	}
+	return fieldBackend
+}
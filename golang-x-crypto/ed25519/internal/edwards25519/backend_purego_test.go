@@ -0,0 +1,10 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build purego
+
+package edwards25519
+
+// puregoTag reports whether the purego build tag is set.
+const puregoTag = true
@@ -0,0 +1,10 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build ed25519_ref10
+
+package edwards25519
+
+// ref10Tag reports whether the ed25519_ref10 build tag is set.
+const ref10Tag = true
@@ -0,0 +1,41 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package edwards25519
+
+import (
+	"runtime"
+	"slices"
+	"testing"
+)
+
+// TestBackend checks that the files selected for GOARCH and the build tags
+// are the ones backend.go describes.
+func TestBackend(t *testing.T) {
+	radix51 := slices.Contains([]string{"amd64", "arm64", "ppc64le", "s390x", "riscv64", "loong64"}, runtime.GOARCH)
+	want := "ref10"
+	switch {
+	case radix51 && !ref10Tag && runtime.GOARCH == "arm64" && !puregoTag:
+		want = "radix51-arm64"
+	case radix51 && !ref10Tag:
+		want = "radix51"
+	}
+	limbs := 5
+	if want == "ref10" {
+		limbs = 10
+	}
+	if len(FieldElement{}) != limbs {
+		t.Errorf("FieldElement has %d limbs for the %s backend", len(FieldElement{}), want)
+	}
+	if useAVX2 {
+		if runtime.GOARCH != "amd64" || puregoTag || ref10Tag {
+			t.Errorf("AVX2 code active on %s (purego %v, ed25519_ref10 %v)", runtime.GOARCH, puregoTag, ref10Tag)
+		}
+		want += "+avx2"
+	}
+	if got := Backend(); got != want {
+		t.Errorf("Backend() = %q on %s (purego %v, ed25519_ref10 %v), want %q",
+			got, runtime.GOARCH, puregoTag, ref10Tag, want)
+	}
+}
@@ -0,0 +1,250 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build amd64 && gc && !purego && !ed25519_ref10
+
+package edwards25519
+
+import "golang.org/x/sys/cpu"
+
+// useAVX2 selects the 4-way AVX2 code in fe4_amd64.s
+// for GeMultiScalarMultVartime and GeFromBytesBatch.
+var useAVX2 = cpu.X86.HasAVX2
+
+// fieldElement4 holds four field elements, its lanes, in the ten-limb
+// radix of ref10 with unsigned limbs: v[k][i] is limb k of lane i, so
+// that each limb of all four lanes fills one 256-bit register.
+//
+// One AVX2 multiplication multiplies four pairs of elements for little
+// more than the cost of one scalar FeMul. Lanes hold either four unrelated
+// elements, as in fe4Pow22523, or the four coordinates of one point,
+// as in geAdd4, whose additions each take two such multiplications.
+type fieldElement4 [10][4]uint64
+
+//go:noescape
+func fe4Mul(h, a, b *fieldElement4)
+
+//go:noescape
+func fe4Square(h, a *fieldElement4)
+
+//go:noescape
+func fe4PrepAdd(t, p *fieldElement4)
+
+//go:noescape
+func fe4PrepMul(l, r, m *fieldElement4)
+
+// setLane sets lane i of v to f.
+func (v *fieldElement4) setLane(i int, f *FieldElement) {
+	var l [10]int32
+	FeToLimbs10(&l, f)
+	for k := range l {
+		v[k][i] = uint64(l[k])
+	}
+}
+
+// lane sets f to lane i of v.
+func (v *fieldElement4) lane(f *FieldElement, i int) {
+	var l [10]int32
+	for k := range l {
+		l[k] = int32(v[k][i])
+	}
+	FeFromLimbs10(f, &l)
+}
+
+// fe4Pow22523 sets out to z^((p-5)/8) lane by lane, like fePow22523.
+func fe4Pow22523(out, z *fieldElement4) {
+	var t0, t1, t2 fieldElement4
+	var i int
+
+	fe4Square(&t0, z)
+	fe4Square(&t1, &t0)
+	fe4Square(&t1, &t1)
+	fe4Mul(&t1, z, &t1)
+	fe4Mul(&t0, &t0, &t1)
+	fe4Square(&t0, &t0)
+	fe4Mul(&t0, &t1, &t0)
+	fe4Square(&t1, &t0)
+	for i = 1; i < 5; i++ {
+		fe4Square(&t1, &t1)
+	}
+	fe4Mul(&t0, &t1, &t0)
+	fe4Square(&t1, &t0)
+	for i = 1; i < 10; i++ {
+		fe4Square(&t1, &t1)
+	}
+	fe4Mul(&t1, &t1, &t0)
+	fe4Square(&t2, &t1)
+	for i = 1; i < 20; i++ {
+		fe4Square(&t2, &t2)
+	}
+	fe4Mul(&t1, &t2, &t1)
+	fe4Square(&t1, &t1)
+	for i = 1; i < 10; i++ {
+		fe4Square(&t1, &t1)
+	}
+	fe4Mul(&t0, &t1, &t0)
+	fe4Square(&t1, &t0)
+	for i = 1; i < 50; i++ {
+		fe4Square(&t1, &t1)
+	}
+	fe4Mul(&t1, &t1, &t0)
+	fe4Square(&t2, &t1)
+	for i = 1; i < 100; i++ {
+		fe4Square(&t2, &t2)
+	}
+	fe4Mul(&t1, &t2, &t1)
+	fe4Square(&t1, &t1)
+	for i = 1; i < 50; i++ {
+		fe4Square(&t1, &t1)
+	}
+	fe4Mul(&t0, &t1, &t0)
+	fe4Square(&t0, &t0)
+	fe4Square(&t0, &t0)
+	fe4Mul(out, &t0, z)
+}
+
+// geFromBytesBatchAVX2 implements GeFromBytesBatch, computing
+// the exponentiations of FromBytes four at a time.
+func geFromBytesBatchAVX2(p []ExtendedGroupElement, s []*[32]byte) int {
+	var u, v, v3 [4]FieldElement
+	var x fieldElement4
+	n := len(s) &^ 3
+	for i := 0; i < n; i += 4 {
+		for j := 0; j < 4; j++ {
+			p[i+j].fromBytesStart(s[i+j], &u[j], &v[j], &v3[j])
+			x.setLane(j, &p[i+j].X)
+		}
+		fe4Pow22523(&x, &x)
+		for j := 0; j < 4; j++ {
+			x.lane(&p[i+j].X, j)
+			if !p[i+j].fromBytesFinish(s[i+j], &u[j], &v[j], &v3[j]) {
+				return i + j
+			}
+		}
+	}
+	return geFromBytesBatchGeneric(p[n:], s[n:], n)
+}
+
+// The point operations below hold the coordinates of a point in the
+// lanes of a fieldElement4, as (X, Y, Z, T) for extended coordinates
+// and (Y-X, Y+X, 2dT, 2Z) for cached ones, and implement the addition
+// formulas of Hisil, Wong, Carter and Dawson with two multiplications.
+
+// identity4 is the neutral element in extended coordinates.
+var identity4 = fieldElement4{0: {0, 1, 1, 0}}
+
+// cachedScale4 is (1, 1, 2d, 2), which turns (Y-X, Y+X, T, Z)
+// into cached coordinates.
+var cachedScale4 = func() (v fieldElement4) {
+	var one, two FieldElement
+	FeOne(&one)
+	FeAdd(&two, &one, &one)
+	v.setLane(0, &one)
+	v.setLane(1, &one)
+	v.setLane(2, &d2)
+	v.setLane(3, &two)
+	return v
+}()
+
+// geAdd4 sets r = p + q, for p and r in extended coordinates
+// and q in cached ones. r may alias p.
+func geAdd4(r, p, q *fieldElement4) {
+	var t, l fieldElement4
+	fe4PrepAdd(&t, p)
+	fe4Mul(&t, &t, q)
+	fe4PrepMul(&l, &t, &t)
+	fe4Mul(r, &l, &t)
+}
+
+// toCached4 sets c to the cached coordinates of p.
+func toCached4(c, p *fieldElement4) {
+	var t fieldElement4
+	fe4PrepAdd(&t, p)
+	fe4Mul(c, &t, &cachedScale4)
+}
+
+// setCached sets v to the cached point c, negated if neg.
+func (v *fieldElement4) setCached(c *CachedGroupElement, neg bool) {
+	var z2, t2d FieldElement
+	FeAdd(&z2, &c.Z, &c.Z)
+	t2d = c.T2d
+	yMinusX, yPlusX := &c.yMinusX, &c.yPlusX
+	if neg {
+		// -(X, Y, Z, T) = (-X, Y, Z, -T)
+		yMinusX, yPlusX = yPlusX, yMinusX
+		FeNeg(&t2d, &t2d)
+	}
+	v.setLane(0, yMinusX)
+	v.setLane(1, yPlusX)
+	v.setLane(2, &t2d)
+	v.setLane(3, &z2)
+}
+
+// extended sets p to the point in v.
+func (v *fieldElement4) extended(p *ExtendedGroupElement) {
+	v.lane(&p.X, 0)
+	v.lane(&p.Y, 1)
+	v.lane(&p.Z, 2)
+	v.lane(&p.T, 3)
+}
+
+// geMultiScalarMultPippengerAVX2 is geMultiScalarMultPippenger with
+// the buckets, and the additions to them, in fieldElement4 lanes.
+func geMultiScalarMultPippengerAVX2(r *ExtendedGroupElement, a []*[32]byte, A []*ExtendedGroupElement) {
+	n := len(a)
+	w := pippengerWindow(n)
+	digits, digitCount := pippengerDigits(a, w)
+	points := make([]fieldElement4, 2*n) // A[j] and -A[j]
+	for j := range A {
+		var c CachedGroupElement
+		A[j].ToCached(&c)
+		points[2*j].setCached(&c, false)
+		points[2*j+1].setCached(&c, true)
+	}
+
+	buckets := make([]fieldElement4, 1<<(w-1))
+	used := make([]bool, len(buckets))
+	var t CompletedGroupElement
+	var total, sumExt ExtendedGroupElement
+	var running, sum, c fieldElement4
+	total.Zero()
+	for i := digitCount - 1; i >= 0; i-- {
+		if i != digitCount-1 {
+			for k := uint(0); k < w; k++ {
+				total.Double(&t)
+				t.ToExtended(&total)
+			}
+		}
+
+		clear(used)
+		for j := 0; j < n; j++ {
+			d := digits[j*digitCount+i]
+			q := &points[2*j]
+			if d < 0 {
+				d, q = -d, &points[2*j+1]
+			}
+			if d == 0 {
+				continue
+			}
+			if !used[d-1] {
+				buckets[d-1] = identity4
+				used[d-1] = true
+			}
+			geAdd4(&buckets[d-1], &buckets[d-1], q)
+		}
+
+		running, sum = identity4, identity4
+		for b := len(buckets) - 1; b >= 0; b-- {
+			if used[b] {
+				toCached4(&c, &buckets[b])
+				geAdd4(&running, &running, &c)
+			}
+			toCached4(&c, &running)
+			geAdd4(&sum, &sum, &c)
+		}
+		sum.extended(&sumExt)
+		total.Add(&total, &sumExt)
+	}
+	*r = total
+}
@@ -0,0 +1,734 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build amd64 && gc && !purego && !ed25519_ref10
+
+#include "textflag.h"
+
+// Four field elements are processed at once, one per 64-bit lane of
+// the 256-bit AVX2 registers: Yk holds limb k of all four. The limbs
+// are unsigned, and VPMULUDQ multiplies their low 32 bits.
+
+DATA fe4Nineteen<>+0(SB)/8, $19
+GLOBL fe4Nineteen<>(SB), RODATA|NOPTR, $8
+
+DATA fe4Mask26<>+0(SB)/8, $0x3ffffff
+GLOBL fe4Mask26<>(SB), RODATA|NOPTR, $8
+
+DATA fe4Mask25<>+0(SB)/8, $0x1ffffff
+GLOBL fe4Mask25<>(SB), RODATA|NOPTR, $8
+
+// 2p, limb by limb: limb 0, the other even limbs, and the odd limbs.
+DATA fe4TwoP0<>+0(SB)/8, $0x7ffffda
+GLOBL fe4TwoP0<>(SB), RODATA|NOPTR, $8
+
+DATA fe4TwoPEven<>+0(SB)/8, $0x7fffffe
+GLOBL fe4TwoPEven<>(SB), RODATA|NOPTR, $8
+
+DATA fe4TwoPOdd<>+0(SB)/8, $0x3fffffe
+GLOBL fe4TwoPOdd<>(SB), RODATA|NOPTR, $8
+
+
+// func fe4Mul(h, a, b *fieldElement4)
+//
+// The limbs of a must be below 2^27.6, and those of b below 2^27.7,
+// so that 19*b fits in 32 bits; the limbs of h are carried.
+TEXT ·fe4Mul(SB), NOSPLIT, $320-24
+	MOVQ h+0(FP), DI
+	MOVQ a+8(FP), SI
+	MOVQ b+16(FP), DX
+
+	// 19*b, for the products that wrap around past 2^255.
+	VPBROADCASTQ fe4Nineteen<>(SB), Y15
+	VPMULUDQ 32(DX), Y15, Y12
+	VMOVDQU Y12, 32(SP)
+	VPMULUDQ 64(DX), Y15, Y12
+	VMOVDQU Y12, 64(SP)
+	VPMULUDQ 96(DX), Y15, Y12
+	VMOVDQU Y12, 96(SP)
+	VPMULUDQ 128(DX), Y15, Y12
+	VMOVDQU Y12, 128(SP)
+	VPMULUDQ 160(DX), Y15, Y12
+	VMOVDQU Y12, 160(SP)
+	VPMULUDQ 192(DX), Y15, Y12
+	VMOVDQU Y12, 192(SP)
+	VPMULUDQ 224(DX), Y15, Y12
+	VMOVDQU Y12, 224(SP)
+	VPMULUDQ 256(DX), Y15, Y12
+	VMOVDQU Y12, 256(SP)
+	VPMULUDQ 288(DX), Y15, Y12
+	VMOVDQU Y12, 288(SP)
+
+	// h_k = Σ a_i b_j for i+j = k, and 19 a_i b_j for i+j = k+10,
+	// doubled when i and j are both odd.
+	VMOVDQU 0(SI), Y10
+	VPMULUDQ 0(DX), Y10, Y0
+	VPMULUDQ 32(DX), Y10, Y1
+	VPMULUDQ 64(DX), Y10, Y2
+	VPMULUDQ 96(DX), Y10, Y3
+	VPMULUDQ 128(DX), Y10, Y4
+	VPMULUDQ 160(DX), Y10, Y5
+	VPMULUDQ 192(DX), Y10, Y6
+	VPMULUDQ 224(DX), Y10, Y7
+	VPMULUDQ 256(DX), Y10, Y8
+	VPMULUDQ 288(DX), Y10, Y9
+	VMOVDQU 32(SI), Y10
+	VPADDQ Y10, Y10, Y11
+	VPMULUDQ 0(DX), Y10, Y12
+	VPADDQ Y12, Y1, Y1
+	VPMULUDQ 32(DX), Y11, Y12
+	VPADDQ Y12, Y2, Y2
+	VPMULUDQ 64(DX), Y10, Y12
+	VPADDQ Y12, Y3, Y3
+	VPMULUDQ 96(DX), Y11, Y12
+	VPADDQ Y12, Y4, Y4
+	VPMULUDQ 128(DX), Y10, Y12
+	VPADDQ Y12, Y5, Y5
+	VPMULUDQ 160(DX), Y11, Y12
+	VPADDQ Y12, Y6, Y6
+	VPMULUDQ 192(DX), Y10, Y12
+	VPADDQ Y12, Y7, Y7
+	VPMULUDQ 224(DX), Y11, Y12
+	VPADDQ Y12, Y8, Y8
+	VPMULUDQ 256(DX), Y10, Y12
+	VPADDQ Y12, Y9, Y9
+	VPMULUDQ 288(SP), Y11, Y12
+	VPADDQ Y12, Y0, Y0
+	VMOVDQU 64(SI), Y10
+	VPMULUDQ 0(DX), Y10, Y12
+	VPADDQ Y12, Y2, Y2
+	VPMULUDQ 32(DX), Y10, Y12
+	VPADDQ Y12, Y3, Y3
+	VPMULUDQ 64(DX), Y10, Y12
+	VPADDQ Y12, Y4, Y4
+	VPMULUDQ 96(DX), Y10, Y12
+	VPADDQ Y12, Y5, Y5
+	VPMULUDQ 128(DX), Y10, Y12
+	VPADDQ Y12, Y6, Y6
+	VPMULUDQ 160(DX), Y10, Y12
+	VPADDQ Y12, Y7, Y7
+	VPMULUDQ 192(DX), Y10, Y12
+	VPADDQ Y12, Y8, Y8
+	VPMULUDQ 224(DX), Y10, Y12
+	VPADDQ Y12, Y9, Y9
+	VPMULUDQ 256(SP), Y10, Y12
+	VPADDQ Y12, Y0, Y0
+	VPMULUDQ 288(SP), Y10, Y12
+	VPADDQ Y12, Y1, Y1
+	VMOVDQU 96(SI), Y10
+	VPADDQ Y10, Y10, Y11
+	VPMULUDQ 0(DX), Y10, Y12
+	VPADDQ Y12, Y3, Y3
+	VPMULUDQ 32(DX), Y11, Y12
+	VPADDQ Y12, Y4, Y4
+	VPMULUDQ 64(DX), Y10, Y12
+	VPADDQ Y12, Y5, Y5
+	VPMULUDQ 96(DX), Y11, Y12
+	VPADDQ Y12, Y6, Y6
+	VPMULUDQ 128(DX), Y10, Y12
+	VPADDQ Y12, Y7, Y7
+	VPMULUDQ 160(DX), Y11, Y12
+	VPADDQ Y12, Y8, Y8
+	VPMULUDQ 192(DX), Y10, Y12
+	VPADDQ Y12, Y9, Y9
+	VPMULUDQ 224(SP), Y11, Y12
+	VPADDQ Y12, Y0, Y0
+	VPMULUDQ 256(SP), Y10, Y12
+	VPADDQ Y12, Y1, Y1
+	VPMULUDQ 288(SP), Y11, Y12
+	VPADDQ Y12, Y2, Y2
+	VMOVDQU 128(SI), Y10
+	VPMULUDQ 0(DX), Y10, Y12
+	VPADDQ Y12, Y4, Y4
+	VPMULUDQ 32(DX), Y10, Y12
+	VPADDQ Y12, Y5, Y5
+	VPMULUDQ 64(DX), Y10, Y12
+	VPADDQ Y12, Y6, Y6
+	VPMULUDQ 96(DX), Y10, Y12
+	VPADDQ Y12, Y7, Y7
+	VPMULUDQ 128(DX), Y10, Y12
+	VPADDQ Y12, Y8, Y8
+	VPMULUDQ 160(DX), Y10, Y12
+	VPADDQ Y12, Y9, Y9
+	VPMULUDQ 192(SP), Y10, Y12
+	VPADDQ Y12, Y0, Y0
+	VPMULUDQ 224(SP), Y10, Y12
+	VPADDQ Y12, Y1, Y1
+	VPMULUDQ 256(SP), Y10, Y12
+	VPADDQ Y12, Y2, Y2
+	VPMULUDQ 288(SP), Y10, Y12
+	VPADDQ Y12, Y3, Y3
+	VMOVDQU 160(SI), Y10
+	VPADDQ Y10, Y10, Y11
+	VPMULUDQ 0(DX), Y10, Y12
+	VPADDQ Y12, Y5, Y5
+	VPMULUDQ 32(DX), Y11, Y12
+	VPADDQ Y12, Y6, Y6
+	VPMULUDQ 64(DX), Y10, Y12
+	VPADDQ Y12, Y7, Y7
+	VPMULUDQ 96(DX), Y11, Y12
+	VPADDQ Y12, Y8, Y8
+	VPMULUDQ 128(DX), Y10, Y12
+	VPADDQ Y12, Y9, Y9
+	VPMULUDQ 160(SP), Y11, Y12
+	VPADDQ Y12, Y0, Y0
+	VPMULUDQ 192(SP), Y10, Y12
+	VPADDQ Y12, Y1, Y1
+	VPMULUDQ 224(SP), Y11, Y12
+	VPADDQ Y12, Y2, Y2
+	VPMULUDQ 256(SP), Y10, Y12
+	VPADDQ Y12, Y3, Y3
+	VPMULUDQ 288(SP), Y11, Y12
+	VPADDQ Y12, Y4, Y4
+	VMOVDQU 192(SI), Y10
+	VPMULUDQ 0(DX), Y10, Y12
+	VPADDQ Y12, Y6, Y6
+	VPMULUDQ 32(DX), Y10, Y12
+	VPADDQ Y12, Y7, Y7
+	VPMULUDQ 64(DX), Y10, Y12
+	VPADDQ Y12, Y8, Y8
+	VPMULUDQ 96(DX), Y10, Y12
+	VPADDQ Y12, Y9, Y9
+	VPMULUDQ 128(SP), Y10, Y12
+	VPADDQ Y12, Y0, Y0
+	VPMULUDQ 160(SP), Y10, Y12
+	VPADDQ Y12, Y1, Y1
+	VPMULUDQ 192(SP), Y10, Y12
+	VPADDQ Y12, Y2, Y2
+	VPMULUDQ 224(SP), Y10, Y12
+	VPADDQ Y12, Y3, Y3
+	VPMULUDQ 256(SP), Y10, Y12
+	VPADDQ Y12, Y4, Y4
+	VPMULUDQ 288(SP), Y10, Y12
+	VPADDQ Y12, Y5, Y5
+	VMOVDQU 224(SI), Y10
+	VPADDQ Y10, Y10, Y11
+	VPMULUDQ 0(DX), Y10, Y12
+	VPADDQ Y12, Y7, Y7
+	VPMULUDQ 32(DX), Y11, Y12
+	VPADDQ Y12, Y8, Y8
+	VPMULUDQ 64(DX), Y10, Y12
+	VPADDQ Y12, Y9, Y9
+	VPMULUDQ 96(SP), Y11, Y12
+	VPADDQ Y12, Y0, Y0
+	VPMULUDQ 128(SP), Y10, Y12
+	VPADDQ Y12, Y1, Y1
+	VPMULUDQ 160(SP), Y11, Y12
+	VPADDQ Y12, Y2, Y2
+	VPMULUDQ 192(SP), Y10, Y12
+	VPADDQ Y12, Y3, Y3
+	VPMULUDQ 224(SP), Y11, Y12
+	VPADDQ Y12, Y4, Y4
+	VPMULUDQ 256(SP), Y10, Y12
+	VPADDQ Y12, Y5, Y5
+	VPMULUDQ 288(SP), Y11, Y12
+	VPADDQ Y12, Y6, Y6
+	VMOVDQU 256(SI), Y10
+	VPMULUDQ 0(DX), Y10, Y12
+	VPADDQ Y12, Y8, Y8
+	VPMULUDQ 32(DX), Y10, Y12
+	VPADDQ Y12, Y9, Y9
+	VPMULUDQ 64(SP), Y10, Y12
+	VPADDQ Y12, Y0, Y0
+	VPMULUDQ 96(SP), Y10, Y12
+	VPADDQ Y12, Y1, Y1
+	VPMULUDQ 128(SP), Y10, Y12
+	VPADDQ Y12, Y2, Y2
+	VPMULUDQ 160(SP), Y10, Y12
+	VPADDQ Y12, Y3, Y3
+	VPMULUDQ 192(SP), Y10, Y12
+	VPADDQ Y12, Y4, Y4
+	VPMULUDQ 224(SP), Y10, Y12
+	VPADDQ Y12, Y5, Y5
+	VPMULUDQ 256(SP), Y10, Y12
+	VPADDQ Y12, Y6, Y6
+	VPMULUDQ 288(SP), Y10, Y12
+	VPADDQ Y12, Y7, Y7
+	VMOVDQU 288(SI), Y10
+	VPADDQ Y10, Y10, Y11
+	VPMULUDQ 0(DX), Y10, Y12
+	VPADDQ Y12, Y9, Y9
+	VPMULUDQ 32(SP), Y11, Y12
+	VPADDQ Y12, Y0, Y0
+	VPMULUDQ 64(SP), Y10, Y12
+	VPADDQ Y12, Y1, Y1
+	VPMULUDQ 96(SP), Y11, Y12
+	VPADDQ Y12, Y2, Y2
+	VPMULUDQ 128(SP), Y10, Y12
+	VPADDQ Y12, Y3, Y3
+	VPMULUDQ 160(SP), Y11, Y12
+	VPADDQ Y12, Y4, Y4
+	VPMULUDQ 192(SP), Y10, Y12
+	VPADDQ Y12, Y5, Y5
+	VPMULUDQ 224(SP), Y11, Y12
+	VPADDQ Y12, Y6, Y6
+	VPMULUDQ 256(SP), Y10, Y12
+	VPADDQ Y12, Y7, Y7
+	VPMULUDQ 288(SP), Y11, Y12
+	VPADDQ Y12, Y8, Y8
+
+	// Carry h0 → h1 → ... → h9, h9 → 19*h0, and h0 → h1 once more.
+	VPBROADCASTQ fe4Mask26<>(SB), Y13
+	VPBROADCASTQ fe4Mask25<>(SB), Y14
+	VPSRLQ $26, Y0, Y12
+	VPADDQ Y12, Y1, Y1
+	VPAND Y13, Y0, Y0
+	VPSRLQ $25, Y1, Y12
+	VPADDQ Y12, Y2, Y2
+	VPAND Y14, Y1, Y1
+	VPSRLQ $26, Y2, Y12
+	VPADDQ Y12, Y3, Y3
+	VPAND Y13, Y2, Y2
+	VPSRLQ $25, Y3, Y12
+	VPADDQ Y12, Y4, Y4
+	VPAND Y14, Y3, Y3
+	VPSRLQ $26, Y4, Y12
+	VPADDQ Y12, Y5, Y5
+	VPAND Y13, Y4, Y4
+	VPSRLQ $25, Y5, Y12
+	VPADDQ Y12, Y6, Y6
+	VPAND Y14, Y5, Y5
+	VPSRLQ $26, Y6, Y12
+	VPADDQ Y12, Y7, Y7
+	VPAND Y13, Y6, Y6
+	VPSRLQ $25, Y7, Y12
+	VPADDQ Y12, Y8, Y8
+	VPAND Y14, Y7, Y7
+	VPSRLQ $26, Y8, Y12
+	VPADDQ Y12, Y9, Y9
+	VPAND Y13, Y8, Y8
+	VPSRLQ $25, Y9, Y12
+	VPAND Y14, Y9, Y9
+	VPSLLQ $4, Y12, Y11
+	VPADDQ Y12, Y11, Y11
+	VPADDQ Y12, Y12, Y12
+	VPADDQ Y12, Y11, Y11
+	VPADDQ Y11, Y0, Y0
+	VPSRLQ $26, Y0, Y12
+	VPADDQ Y12, Y1, Y1
+	VPAND Y13, Y0, Y0
+
+	VMOVDQU Y0, 0(DI)
+	VMOVDQU Y1, 32(DI)
+	VMOVDQU Y2, 64(DI)
+	VMOVDQU Y3, 96(DI)
+	VMOVDQU Y4, 128(DI)
+	VMOVDQU Y5, 160(DI)
+	VMOVDQU Y6, 192(DI)
+	VMOVDQU Y7, 224(DI)
+	VMOVDQU Y8, 256(DI)
+	VMOVDQU Y9, 288(DI)
+	VZEROUPPER
+	RET
+
+// func fe4Square(h, a *fieldElement4)
+//
+// The limbs of a must be carried; so are those of h.
+TEXT ·fe4Square(SB), NOSPLIT, $160-16
+	MOVQ h+0(FP), DI
+	MOVQ a+8(FP), SI
+
+	// 19*a_j for j >= 5, for the products that wrap around past 2^255.
+	VPBROADCASTQ fe4Nineteen<>(SB), Y15
+	VPMULUDQ 160(SI), Y15, Y12
+	VMOVDQU Y12, 0(SP)
+	VPMULUDQ 192(SI), Y15, Y12
+	VMOVDQU Y12, 32(SP)
+	VPMULUDQ 224(SI), Y15, Y12
+	VMOVDQU Y12, 64(SP)
+	VPMULUDQ 256(SI), Y15, Y12
+	VMOVDQU Y12, 96(SP)
+	VPMULUDQ 288(SI), Y15, Y12
+	VMOVDQU Y12, 128(SP)
+
+	// Each product a_i a_j with i < j appears twice; pairs of
+	// odd limbs are doubled again, and wrapped ones multiplied by 19.
+	VMOVDQU 0(SI), Y10
+	VPADDQ Y10, Y10, Y11
+	VPMULUDQ 0(SI), Y10, Y0
+	VPMULUDQ 32(SI), Y11, Y1
+	VPMULUDQ 64(SI), Y11, Y2
+	VPMULUDQ 96(SI), Y11, Y3
+	VPMULUDQ 128(SI), Y11, Y4
+	VPMULUDQ 160(SI), Y11, Y5
+	VPMULUDQ 192(SI), Y11, Y6
+	VPMULUDQ 224(SI), Y11, Y7
+	VPMULUDQ 256(SI), Y11, Y8
+	VPMULUDQ 288(SI), Y11, Y9
+	VMOVDQU 32(SI), Y10
+	VPADDQ Y10, Y10, Y11
+	VPADDQ Y11, Y11, Y13
+	VPMULUDQ 32(SI), Y11, Y12
+	VPADDQ Y12, Y2, Y2
+	VPMULUDQ 64(SI), Y11, Y12
+	VPADDQ Y12, Y3, Y3
+	VPMULUDQ 96(SI), Y13, Y12
+	VPADDQ Y12, Y4, Y4
+	VPMULUDQ 128(SI), Y11, Y12
+	VPADDQ Y12, Y5, Y5
+	VPMULUDQ 160(SI), Y13, Y12
+	VPADDQ Y12, Y6, Y6
+	VPMULUDQ 192(SI), Y11, Y12
+	VPADDQ Y12, Y7, Y7
+	VPMULUDQ 224(SI), Y13, Y12
+	VPADDQ Y12, Y8, Y8
+	VPMULUDQ 256(SI), Y11, Y12
+	VPADDQ Y12, Y9, Y9
+	VPMULUDQ 128(SP), Y13, Y12
+	VPADDQ Y12, Y0, Y0
+	VMOVDQU 64(SI), Y10
+	VPADDQ Y10, Y10, Y11
+	VPMULUDQ 64(SI), Y10, Y12
+	VPADDQ Y12, Y4, Y4
+	VPMULUDQ 96(SI), Y11, Y12
+	VPADDQ Y12, Y5, Y5
+	VPMULUDQ 128(SI), Y11, Y12
+	VPADDQ Y12, Y6, Y6
+	VPMULUDQ 160(SI), Y11, Y12
+	VPADDQ Y12, Y7, Y7
+	VPMULUDQ 192(SI), Y11, Y12
+	VPADDQ Y12, Y8, Y8
+	VPMULUDQ 224(SI), Y11, Y12
+	VPADDQ Y12, Y9, Y9
+	VPMULUDQ 96(SP), Y11, Y12
+	VPADDQ Y12, Y0, Y0
+	VPMULUDQ 128(SP), Y11, Y12
+	VPADDQ Y12, Y1, Y1
+	VMOVDQU 96(SI), Y10
+	VPADDQ Y10, Y10, Y11
+	VPADDQ Y11, Y11, Y13
+	VPMULUDQ 96(SI), Y11, Y12
+	VPADDQ Y12, Y6, Y6
+	VPMULUDQ 128(SI), Y11, Y12
+	VPADDQ Y12, Y7, Y7
+	VPMULUDQ 160(SI), Y13, Y12
+	VPADDQ Y12, Y8, Y8
+	VPMULUDQ 192(SI), Y11, Y12
+	VPADDQ Y12, Y9, Y9
+	VPMULUDQ 64(SP), Y13, Y12
+	VPADDQ Y12, Y0, Y0
+	VPMULUDQ 96(SP), Y11, Y12
+	VPADDQ Y12, Y1, Y1
+	VPMULUDQ 128(SP), Y13, Y12
+	VPADDQ Y12, Y2, Y2
+	VMOVDQU 128(SI), Y10
+	VPADDQ Y10, Y10, Y11
+	VPMULUDQ 128(SI), Y10, Y12
+	VPADDQ Y12, Y8, Y8
+	VPMULUDQ 160(SI), Y11, Y12
+	VPADDQ Y12, Y9, Y9
+	VPMULUDQ 32(SP), Y11, Y12
+	VPADDQ Y12, Y0, Y0
+	VPMULUDQ 64(SP), Y11, Y12
+	VPADDQ Y12, Y1, Y1
+	VPMULUDQ 96(SP), Y11, Y12
+	VPADDQ Y12, Y2, Y2
+	VPMULUDQ 128(SP), Y11, Y12
+	VPADDQ Y12, Y3, Y3
+	VMOVDQU 160(SI), Y10
+	VPADDQ Y10, Y10, Y11
+	VPADDQ Y11, Y11, Y13
+	VPMULUDQ 0(SP), Y11, Y12
+	VPADDQ Y12, Y0, Y0
+	VPMULUDQ 32(SP), Y11, Y12
+	VPADDQ Y12, Y1, Y1
+	VPMULUDQ 64(SP), Y13, Y12
+	VPADDQ Y12, Y2, Y2
+	VPMULUDQ 96(SP), Y11, Y12
+	VPADDQ Y12, Y3, Y3
+	VPMULUDQ 128(SP), Y13, Y12
+	VPADDQ Y12, Y4, Y4
+	VMOVDQU 192(SI), Y10
+	VPADDQ Y10, Y10, Y11
+	VPMULUDQ 32(SP), Y10, Y12
+	VPADDQ Y12, Y2, Y2
+	VPMULUDQ 64(SP), Y11, Y12
+	VPADDQ Y12, Y3, Y3
+	VPMULUDQ 96(SP), Y11, Y12
+	VPADDQ Y12, Y4, Y4
+	VPMULUDQ 128(SP), Y11, Y12
+	VPADDQ Y12, Y5, Y5
+	VMOVDQU 224(SI), Y10
+	VPADDQ Y10, Y10, Y11
+	VPADDQ Y11, Y11, Y13
+	VPMULUDQ 64(SP), Y11, Y12
+	VPADDQ Y12, Y4, Y4
+	VPMULUDQ 96(SP), Y11, Y12
+	VPADDQ Y12, Y5, Y5
+	VPMULUDQ 128(SP), Y13, Y12
+	VPADDQ Y12, Y6, Y6
+	VMOVDQU 256(SI), Y10
+	VPADDQ Y10, Y10, Y11
+	VPMULUDQ 96(SP), Y10, Y12
+	VPADDQ Y12, Y6, Y6
+	VPMULUDQ 128(SP), Y11, Y12
+	VPADDQ Y12, Y7, Y7
+	VMOVDQU 288(SI), Y10
+	VPADDQ Y10, Y10, Y11
+	VPADDQ Y11, Y11, Y13
+	VPMULUDQ 128(SP), Y11, Y12
+	VPADDQ Y12, Y8, Y8
+
+	// Carry h0 → h1 → ... → h9, h9 → 19*h0, and h0 → h1 once more.
+	VPBROADCASTQ fe4Mask26<>(SB), Y13
+	VPBROADCASTQ fe4Mask25<>(SB), Y14
+	VPSRLQ $26, Y0, Y12
+	VPADDQ Y12, Y1, Y1
+	VPAND Y13, Y0, Y0
+	VPSRLQ $25, Y1, Y12
+	VPADDQ Y12, Y2, Y2
+	VPAND Y14, Y1, Y1
+	VPSRLQ $26, Y2, Y12
+	VPADDQ Y12, Y3, Y3
+	VPAND Y13, Y2, Y2
+	VPSRLQ $25, Y3, Y12
+	VPADDQ Y12, Y4, Y4
+	VPAND Y14, Y3, Y3
+	VPSRLQ $26, Y4, Y12
+	VPADDQ Y12, Y5, Y5
+	VPAND Y13, Y4, Y4
+	VPSRLQ $25, Y5, Y12
+	VPADDQ Y12, Y6, Y6
+	VPAND Y14, Y5, Y5
+	VPSRLQ $26, Y6, Y12
+	VPADDQ Y12, Y7, Y7
+	VPAND Y13, Y6, Y6
+	VPSRLQ $25, Y7, Y12
+	VPADDQ Y12, Y8, Y8
+	VPAND Y14, Y7, Y7
+	VPSRLQ $26, Y8, Y12
+	VPADDQ Y12, Y9, Y9
+	VPAND Y13, Y8, Y8
+	VPSRLQ $25, Y9, Y12
+	VPAND Y14, Y9, Y9
+	VPSLLQ $4, Y12, Y11
+	VPADDQ Y12, Y11, Y11
+	VPADDQ Y12, Y12, Y12
+	VPADDQ Y12, Y11, Y11
+	VPADDQ Y11, Y0, Y0
+	VPSRLQ $26, Y0, Y12
+	VPADDQ Y12, Y1, Y1
+	VPAND Y13, Y0, Y0
+
+	VMOVDQU Y0, 0(DI)
+	VMOVDQU Y1, 32(DI)
+	VMOVDQU Y2, 64(DI)
+	VMOVDQU Y3, 96(DI)
+	VMOVDQU Y4, 128(DI)
+	VMOVDQU Y5, 160(DI)
+	VMOVDQU Y6, 192(DI)
+	VMOVDQU Y7, 224(DI)
+	VMOVDQU Y8, 256(DI)
+	VMOVDQU Y9, 288(DI)
+	VZEROUPPER
+	RET
+
+// func fe4PrepAdd(t, p *fieldElement4)
+//
+// p holds (X, Y, Z, T); t is set to (Y-X, Y+X, T, Z).
+TEXT ·fe4PrepAdd(SB), NOSPLIT, $0-16
+	MOVQ t+0(FP), DI
+	MOVQ p+8(FP), SI
+
+	VPBROADCASTQ fe4TwoP0<>(SB), Y13
+	VPBROADCASTQ fe4TwoPEven<>(SB), Y14
+	VPBROADCASTQ fe4TwoPOdd<>(SB), Y15
+	VMOVDQU 0(SI), Y0
+	VPERMQ $0xb1, Y0, Y1
+	VPADDQ Y0, Y1, Y2
+	VPADDQ Y13, Y1, Y3
+	VPSUBQ Y0, Y3, Y3
+	VPBLENDD $0x0c, Y2, Y3, Y3
+	VPBLENDD $0xf0, Y1, Y3, Y3
+	VMOVDQU Y3, 0(DI)
+	VMOVDQU 32(SI), Y0
+	VPERMQ $0xb1, Y0, Y1
+	VPADDQ Y0, Y1, Y2
+	VPADDQ Y15, Y1, Y3
+	VPSUBQ Y0, Y3, Y3
+	VPBLENDD $0x0c, Y2, Y3, Y3
+	VPBLENDD $0xf0, Y1, Y3, Y3
+	VMOVDQU Y3, 32(DI)
+	VMOVDQU 64(SI), Y0
+	VPERMQ $0xb1, Y0, Y1
+	VPADDQ Y0, Y1, Y2
+	VPADDQ Y14, Y1, Y3
+	VPSUBQ Y0, Y3, Y3
+	VPBLENDD $0x0c, Y2, Y3, Y3
+	VPBLENDD $0xf0, Y1, Y3, Y3
+	VMOVDQU Y3, 64(DI)
+	VMOVDQU 96(SI), Y0
+	VPERMQ $0xb1, Y0, Y1
+	VPADDQ Y0, Y1, Y2
+	VPADDQ Y15, Y1, Y3
+	VPSUBQ Y0, Y3, Y3
+	VPBLENDD $0x0c, Y2, Y3, Y3
+	VPBLENDD $0xf0, Y1, Y3, Y3
+	VMOVDQU Y3, 96(DI)
+	VMOVDQU 128(SI), Y0
+	VPERMQ $0xb1, Y0, Y1
+	VPADDQ Y0, Y1, Y2
+	VPADDQ Y14, Y1, Y3
+	VPSUBQ Y0, Y3, Y3
+	VPBLENDD $0x0c, Y2, Y3, Y3
+	VPBLENDD $0xf0, Y1, Y3, Y3
+	VMOVDQU Y3, 128(DI)
+	VMOVDQU 160(SI), Y0
+	VPERMQ $0xb1, Y0, Y1
+	VPADDQ Y0, Y1, Y2
+	VPADDQ Y15, Y1, Y3
+	VPSUBQ Y0, Y3, Y3
+	VPBLENDD $0x0c, Y2, Y3, Y3
+	VPBLENDD $0xf0, Y1, Y3, Y3
+	VMOVDQU Y3, 160(DI)
+	VMOVDQU 192(SI), Y0
+	VPERMQ $0xb1, Y0, Y1
+	VPADDQ Y0, Y1, Y2
+	VPADDQ Y14, Y1, Y3
+	VPSUBQ Y0, Y3, Y3
+	VPBLENDD $0x0c, Y2, Y3, Y3
+	VPBLENDD $0xf0, Y1, Y3, Y3
+	VMOVDQU Y3, 192(DI)
+	VMOVDQU 224(SI), Y0
+	VPERMQ $0xb1, Y0, Y1
+	VPADDQ Y0, Y1, Y2
+	VPADDQ Y15, Y1, Y3
+	VPSUBQ Y0, Y3, Y3
+	VPBLENDD $0x0c, Y2, Y3, Y3
+	VPBLENDD $0xf0, Y1, Y3, Y3
+	VMOVDQU Y3, 224(DI)
+	VMOVDQU 256(SI), Y0
+	VPERMQ $0xb1, Y0, Y1
+	VPADDQ Y0, Y1, Y2
+	VPADDQ Y14, Y1, Y3
+	VPSUBQ Y0, Y3, Y3
+	VPBLENDD $0x0c, Y2, Y3, Y3
+	VPBLENDD $0xf0, Y1, Y3, Y3
+	VMOVDQU Y3, 256(DI)
+	VMOVDQU 288(SI), Y0
+	VPERMQ $0xb1, Y0, Y1
+	VPADDQ Y0, Y1, Y2
+	VPADDQ Y15, Y1, Y3
+	VPSUBQ Y0, Y3, Y3
+	VPBLENDD $0x0c, Y2, Y3, Y3
+	VPBLENDD $0xf0, Y1, Y3, Y3
+	VMOVDQU Y3, 288(DI)
+	VZEROUPPER
+	RET
+
+// func fe4PrepMul(l, r, m *fieldElement4)
+//
+// m holds (A, B, C, D) of the addition formulas; with E = B-A,
+// F = D-C, G = D+C and H = B+A, l is set to (E, G, F, E)
+// and r to (F, H, G, H), whose product is (X, Y, Z, T).
+TEXT ·fe4PrepMul(SB), NOSPLIT, $0-24
+	MOVQ l+0(FP), DI
+	MOVQ r+8(FP), BX
+	MOVQ m+16(FP), SI
+
+	VPBROADCASTQ fe4TwoP0<>(SB), Y13
+	VPBROADCASTQ fe4TwoPEven<>(SB), Y14
+	VPBROADCASTQ fe4TwoPOdd<>(SB), Y15
+	VMOVDQU 0(SI), Y0
+	VPERMQ $0xb1, Y0, Y1
+	VPADDQ Y0, Y1, Y2
+	VPADDQ Y13, Y1, Y3
+	VPSUBQ Y0, Y3, Y3
+	VPBLENDD $0xcc, Y2, Y3, Y3
+	VPERMQ $0x2c, Y3, Y4
+	VPERMQ $0x76, Y3, Y5
+	VMOVDQU Y4, 0(DI)
+	VMOVDQU Y5, 0(BX)
+	VMOVDQU 32(SI), Y0
+	VPERMQ $0xb1, Y0, Y1
+	VPADDQ Y0, Y1, Y2
+	VPADDQ Y15, Y1, Y3
+	VPSUBQ Y0, Y3, Y3
+	VPBLENDD $0xcc, Y2, Y3, Y3
+	VPERMQ $0x2c, Y3, Y4
+	VPERMQ $0x76, Y3, Y5
+	VMOVDQU Y4, 32(DI)
+	VMOVDQU Y5, 32(BX)
+	VMOVDQU 64(SI), Y0
+	VPERMQ $0xb1, Y0, Y1
+	VPADDQ Y0, Y1, Y2
+	VPADDQ Y14, Y1, Y3
+	VPSUBQ Y0, Y3, Y3
+	VPBLENDD $0xcc, Y2, Y3, Y3
+	VPERMQ $0x2c, Y3, Y4
+	VPERMQ $0x76, Y3, Y5
+	VMOVDQU Y4, 64(DI)
+	VMOVDQU Y5, 64(BX)
+	VMOVDQU 96(SI), Y0
+	VPERMQ $0xb1, Y0, Y1
+	VPADDQ Y0, Y1, Y2
+	VPADDQ Y15, Y1, Y3
+	VPSUBQ Y0, Y3, Y3
+	VPBLENDD $0xcc, Y2, Y3, Y3
+	VPERMQ $0x2c, Y3, Y4
+	VPERMQ $0x76, Y3, Y5
+	VMOVDQU Y4, 96(DI)
+	VMOVDQU Y5, 96(BX)
+	VMOVDQU 128(SI), Y0
+	VPERMQ $0xb1, Y0, Y1
+	VPADDQ Y0, Y1, Y2
+	VPADDQ Y14, Y1, Y3
+	VPSUBQ Y0, Y3, Y3
+	VPBLENDD $0xcc, Y2, Y3, Y3
+	VPERMQ $0x2c, Y3, Y4
+	VPERMQ $0x76, Y3, Y5
+	VMOVDQU Y4, 128(DI)
+	VMOVDQU Y5, 128(BX)
+	VMOVDQU 160(SI), Y0
+	VPERMQ $0xb1, Y0, Y1
+	VPADDQ Y0, Y1, Y2
+	VPADDQ Y15, Y1, Y3
+	VPSUBQ Y0, Y3, Y3
+	VPBLENDD $0xcc, Y2, Y3, Y3
+	VPERMQ $0x2c, Y3, Y4
+	VPERMQ $0x76, Y3, Y5
+	VMOVDQU Y4, 160(DI)
+	VMOVDQU Y5, 160(BX)
+	VMOVDQU 192(SI), Y0
+	VPERMQ $0xb1, Y0, Y1
+	VPADDQ Y0, Y1, Y2
+	VPADDQ Y14, Y1, Y3
+	VPSUBQ Y0, Y3, Y3
+	VPBLENDD $0xcc, Y2, Y3, Y3
+	VPERMQ $0x2c, Y3, Y4
+	VPERMQ $0x76, Y3, Y5
+	VMOVDQU Y4, 192(DI)
+	VMOVDQU Y5, 192(BX)
+	VMOVDQU 224(SI), Y0
+	VPERMQ $0xb1, Y0, Y1
+	VPADDQ Y0, Y1, Y2
+	VPADDQ Y15, Y1, Y3
+	VPSUBQ Y0, Y3, Y3
+	VPBLENDD $0xcc, Y2, Y3, Y3
+	VPERMQ $0x2c, Y3, Y4
+	VPERMQ $0x76, Y3, Y5
+	VMOVDQU Y4, 224(DI)
+	VMOVDQU Y5, 224(BX)
+	VMOVDQU 256(SI), Y0
+	VPERMQ $0xb1, Y0, Y1
+	VPADDQ Y0, Y1, Y2
+	VPADDQ Y14, Y1, Y3
+	VPSUBQ Y0, Y3, Y3
+	VPBLENDD $0xcc, Y2, Y3, Y3
+	VPERMQ $0x2c, Y3, Y4
+	VPERMQ $0x76, Y3, Y5
+	VMOVDQU Y4, 256(DI)
+	VMOVDQU Y5, 256(BX)
+	VMOVDQU 288(SI), Y0
+	VPERMQ $0xb1, Y0, Y1
+	VPADDQ Y0, Y1, Y2
+	VPADDQ Y15, Y1, Y3
+	VPSUBQ Y0, Y3, Y3
+	VPBLENDD $0xcc, Y2, Y3, Y3
+	VPERMQ $0x2c, Y3, Y4
+	VPERMQ $0x76, Y3, Y5
+	VMOVDQU Y4, 288(DI)
+	VMOVDQU Y5, 288(BX)
+	VZEROUPPER
+	RET
@@ -0,0 +1,192 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build amd64 && gc && !purego && !ed25519_ref10
+
+package edwards25519
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"golang.org/x/sys/cpu"
+)
+
+func skipWithoutAVX2(t testing.TB) {
+	if !useAVX2 {
+		t.Skip("AVX2 not available")
+	}
+}
+
+func TestFieldArithmetic4(t *testing.T) {
+	skipWithoutAVX2(t)
+	// p-1 has the largest carried limbs.
+	var pm1 FieldElement
+	FeOne(&pm1)
+	FeNeg(&pm1, &pm1)
+	for i := 0; i < 64; i++ {
+		var f, g [4]FieldElement
+		var f4, g4, h4 fieldElement4
+		for j := range f {
+			f[j], _ = randomFe(t)
+			g[j], _ = randomFe(t)
+			if i == 0 {
+				f[j], g[j] = pm1, pm1
+			}
+			f4.setLane(j, &f[j])
+			g4.setLane(j, &g[j])
+		}
+		fe4Mul(&h4, &f4, &g4)
+		for j := range f {
+			var want, got FieldElement
+			FeMul(&want, &f[j], &g[j])
+			h4.lane(&got, j)
+			if feEqual(&got, &want) != 1 {
+				t.Fatalf("fe4Mul differs from FeMul in lane %d", j)
+			}
+		}
+		fe4Square(&h4, &f4)
+		for j := range f {
+			var want, got FieldElement
+			FeSquare(&want, &f[j])
+			h4.lane(&got, j)
+			if feEqual(&got, &want) != 1 {
+				t.Fatalf("fe4Square differs from FeSquare in lane %d", j)
+			}
+		}
+	}
+}
+
+func randomPoint(t testing.TB) ExtendedGroupElement {
+	var a [32]byte
+	if _, err := rand.Read(a[:]); err != nil {
+		t.Fatal(err)
+	}
+	a[31] &= 127
+	var p ExtendedGroupElement
+	GeScalarMultBase(&p, &a)
+	return p
+}
+
+func TestGeAdd4(t *testing.T) {
+	skipWithoutAVX2(t)
+	for i := 0; i < 16; i++ {
+		p, q := randomPoint(t), randomPoint(t)
+		var c CachedGroupElement
+		q.ToCached(&c)
+		for _, neg := range []bool{false, true} {
+			var p4, q4 fieldElement4
+			p4.setLane(0, &p.X)
+			p4.setLane(1, &p.Y)
+			p4.setLane(2, &p.Z)
+			p4.setLane(3, &p.T)
+			q4.setCached(&c, neg)
+			geAdd4(&p4, &p4, &q4)
+			toCached4(&q4, &p4)
+			geAdd4(&p4, &p4, &q4)
+
+			var want, got ExtendedGroupElement
+			if neg {
+				want.Sub(&p, &q)
+			} else {
+				want.Add(&p, &q)
+			}
+			want.Add(&want, &want)
+			p4.extended(&got)
+			var a, b [32]byte
+			want.ToBytes(&a)
+			got.ToBytes(&b)
+			if a != b {
+				t.Fatalf("geAdd4 differs from Add (neg = %v)", neg)
+			}
+		}
+	}
+}
+
+func TestGeFromBytesBatch(t *testing.T) {
+	defer func(old bool) { useAVX2 = old }(useAVX2)
+	for _, avx2 := range []bool{false, cpu.X86.HasAVX2} {
+		useAVX2 = avx2
+
+		const n = 11
+		s := make([]*[32]byte, n)
+		want := make([]ExtendedGroupElement, n)
+		for i := range s {
+			p := randomPoint(t)
+			s[i] = new([32]byte)
+			p.ToBytes(s[i])
+			want[i].FromBytes(s[i])
+		}
+		p := make([]ExtendedGroupElement, n)
+		if i := GeFromBytesBatch(p, s); i != -1 {
+			t.Fatalf("avx2 = %v: valid point %d rejected", avx2, i)
+		}
+		for i := range p {
+			var a [32]byte
+			p[i].ToBytes(&a)
+			if a != *s[i] || feEqual(&p[i].X, &want[i].X) != 1 || feEqual(&p[i].T, &want[i].T) != 1 {
+				t.Fatalf("avx2 = %v: point %d differs from FromBytes", avx2, i)
+			}
+		}
+
+		// y = 2 is not on the curve.
+		for _, bad := range []int{0, 6, 10} {
+			old := s[bad]
+			s[bad] = &[32]byte{2}
+			if i := GeFromBytesBatch(p, s); i != bad {
+				t.Errorf("avx2 = %v: GeFromBytesBatch = %d, want %d", avx2, i, bad)
+			}
+			s[bad] = old
+		}
+	}
+}
+
+func TestMultiScalarMultAVX2(t *testing.T) {
+	skipWithoutAVX2(t)
+	const n = 300
+	a := make([]*[32]byte, n)
+	A := make([]*ExtendedGroupElement, n)
+	for i := range a {
+		a[i] = new([32]byte)
+		rand.Read(a[i][:])
+		a[i][31] &= 127
+		p := randomPoint(t)
+		A[i] = &p
+	}
+	var want, got ExtendedGroupElement
+	geMultiScalarMultPippenger(&want, a, A)
+	geMultiScalarMultPippengerAVX2(&got, a, A)
+	var wb, gb [32]byte
+	want.ToBytes(&wb)
+	got.ToBytes(&gb)
+	if wb != gb {
+		t.Errorf("AVX2 Pippenger differs from the generic one")
+	}
+}
+
+func BenchmarkGeFromBytesBatch(b *testing.B) {
+	s := make([]*[32]byte, 64)
+	for i := range s {
+		p := randomPoint(b)
+		s[i] = new([32]byte)
+		p.ToBytes(s[i])
+	}
+	p := make([]ExtendedGroupElement, len(s))
+	defer func(old bool) { useAVX2 = old }(useAVX2)
+	useAVX2 = false
+	b.Run("Generic", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			GeFromBytesBatch(p, s)
+		}
+	})
+	if !cpu.X86.HasAVX2 {
+		return
+	}
+	useAVX2 = true
+	b.Run("AVX2", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			GeFromBytesBatch(p, s)
+		}
+	})
+}
@@ -0,0 +1,17 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !amd64 || !gc || purego || ed25519_ref10
+
+package edwards25519
+
+const useAVX2 = false
+
+func geFromBytesBatchAVX2(p []ExtendedGroupElement, s []*[32]byte) int {
+	return geFromBytesBatchGeneric(p, s, 0)
+}
+
+func geMultiScalarMultPippengerAVX2(r *ExtendedGroupElement, a []*[32]byte, A []*ExtendedGroupElement) {
+	geMultiScalarMultPippenger(r, a, A)
+}
@@ -0,0 +1,17 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build arm64 && !purego && !ed25519_ref10
+
+package edwards25519
+
+// fieldBackend names this implementation for Backend.
+const fieldBackend = "radix51-arm64"
+
+// FeMul calculates h = f * g.
+// Can overlap h with f or g.
+func FeMul(h, f, g *FieldElement) { feMulRows(h, f, g) }
+
+// FeSquare calculates h = f*f. Can overlap h with f.
+func FeSquare(h, f *FieldElement) { feSquareRows(h, f) }
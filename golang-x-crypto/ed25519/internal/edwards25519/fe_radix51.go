@@ -0,0 +1,327 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build (amd64 || arm64 || ppc64le || s390x || riscv64 || loong64) && !ed25519_ref10
+
+package edwards25519
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// FieldElement represents an element of the field GF(2^255 - 19). An element
+// t, entries t[0]...t[4], represents the integer t[0]+2^51 t[1]+2^102 t[2]+
+// 2^153 t[3]+2^204 t[4].
+//
+// Every function leaves its result with limbs below 2^52, and accepts such
+// inputs. Unlike in ref10, additions and subtractions carry right away;
+// in exchange, the 25 limb products of a multiplication replace the 100 of
+// ref10, each computed by a single 64x64→128-bit multiply instruction.
+// Multiplication, squaring, FeAdd and FeSub carry inline, so that they make
+// no calls. FeMul and FeSquare are chosen per architecture: fe_mul_arm64.go
+// uses the row-ordered code of fe_rows.go, fe_mul_generic.go the
+// column-ordered code below.
+type FieldElement [5]uint64
+
+const maskLow51Bits uint64 = (1 << 51) - 1
+
+func FeZero(fe *FieldElement) {
+	*fe = FieldElement{}
+}
+
+func FeOne(fe *FieldElement) {
+	*fe = FieldElement{1}
+}
+
+func FeAdd(dst, a, b *FieldElement) {
+	h0 := a[0] + b[0]
+	h1 := a[1] + b[1]
+	h2 := a[2] + b[2]
+	h3 := a[3] + b[3]
+	h4 := a[4] + b[4]
+	dst[0] = h0&maskLow51Bits + (h4>>51)*19
+	dst[1] = h1&maskLow51Bits + h0>>51
+	dst[2] = h2&maskLow51Bits + h1>>51
+	dst[3] = h3&maskLow51Bits + h2>>51
+	dst[4] = h4&maskLow51Bits + h3>>51
+}
+
+// FeSub sets dst = a - b, adding 2p first so that no limb underflows.
+func FeSub(dst, a, b *FieldElement) {
+	h0 := (a[0] + 0xFFFFFFFFFFFDA) - b[0]
+	h1 := (a[1] + 0xFFFFFFFFFFFFE) - b[1]
+	h2 := (a[2] + 0xFFFFFFFFFFFFE) - b[2]
+	h3 := (a[3] + 0xFFFFFFFFFFFFE) - b[3]
+	h4 := (a[4] + 0xFFFFFFFFFFFFE) - b[4]
+	dst[0] = h0&maskLow51Bits + (h4>>51)*19
+	dst[1] = h1&maskLow51Bits + h0>>51
+	dst[2] = h2&maskLow51Bits + h1>>51
+	dst[3] = h3&maskLow51Bits + h2>>51
+	dst[4] = h4&maskLow51Bits + h3>>51
+}
+
+// FeNeg sets h = -f.
+func FeNeg(h, f *FieldElement) {
+	var zero FieldElement
+	FeSub(h, &zero, f)
+}
+
+func FeCopy(dst, src *FieldElement) {
+	*dst = *src
+}
+
+// Replace (f,g) with (g,g) if b == 1;
+// replace (f,g) with (f,g) if b == 0.
+//
+// Preconditions: b in {0,1}.
+func FeCMove(f, g *FieldElement, b int32) {
+	mask := -uint64(b)
+	f[0] ^= mask & (f[0] ^ g[0])
+	f[1] ^= mask & (f[1] ^ g[1])
+	f[2] ^= mask & (f[2] ^ g[2])
+	f[3] ^= mask & (f[3] ^ g[3])
+	f[4] ^= mask & (f[4] ^ g[4])
+}
+
+// FeFromBytes sets dst to the little-endian integer src,
+// ignoring its most significant bit.
+func FeFromBytes(dst *FieldElement, src *[32]byte) {
+	dst[0] = binary.LittleEndian.Uint64(src[0:8]) & maskLow51Bits
+	dst[1] = (binary.LittleEndian.Uint64(src[6:14]) >> 3) & maskLow51Bits
+	dst[2] = (binary.LittleEndian.Uint64(src[12:20]) >> 6) & maskLow51Bits
+	dst[3] = (binary.LittleEndian.Uint64(src[19:27]) >> 1) & maskLow51Bits
+	dst[4] = (binary.LittleEndian.Uint64(src[24:32]) >> 12) & maskLow51Bits
+}
+
+// FeToBytes sets s to the canonical little-endian encoding of h.
+// Unlike the ref10 version, it leaves h unchanged.
+func FeToBytes(s *[32]byte, h *FieldElement) {
+	t := *h
+	feReduce(&t)
+	*s = [32]byte{}
+	var buf [8]byte
+	for i, l := range t {
+		offset := i * 51
+		binary.LittleEndian.PutUint64(buf[:], l<<uint(offset%8))
+		for j, b := range buf {
+			k := offset/8 + j
+			if k >= len(s) {
+				break
+			}
+			s[k] |= b
+		}
+	}
+}
+
+// feCarryPropagate brings the limbs of h below 2^52,
+// folding the carry out of the top limb back in as 19, since 2^255 = 19 mod p.
+func feCarryPropagate(h *FieldElement) {
+	c0 := h[0] >> 51
+	c1 := h[1] >> 51
+	c2 := h[2] >> 51
+	c3 := h[3] >> 51
+	c4 := h[4] >> 51
+
+	// c4 is at most 13 bits, so 19*c4 is at most 18 bits,
+	// and every limb ends up at most 2^51 + 2^18.
+	h[0] = h[0]&maskLow51Bits + c4*19
+	h[1] = h[1]&maskLow51Bits + c0
+	h[2] = h[2]&maskLow51Bits + c1
+	h[3] = h[3]&maskLow51Bits + c2
+	h[4] = h[4]&maskLow51Bits + c3
+}
+
+// feReduce sets h to its canonical representative, less than p.
+func feReduce(h *FieldElement) {
+	feCarryPropagate(h)
+
+	// Now h < 2^255 + 2^18. It is at least p exactly when h + 19
+	// carries out of bit 255, and then h - p = h + 19 - 2^255.
+	c := (h[0] + 19) >> 51
+	c = (h[1] + c) >> 51
+	c = (h[2] + c) >> 51
+	c = (h[3] + c) >> 51
+	c = (h[4] + c) >> 51
+
+	h[0] += 19 * c
+	h[1] += h[0] >> 51
+	h[0] &= maskLow51Bits
+	h[2] += h[1] >> 51
+	h[1] &= maskLow51Bits
+	h[3] += h[2] >> 51
+	h[2] &= maskLow51Bits
+	h[4] += h[3] >> 51
+	h[3] &= maskLow51Bits
+	h[4] &= maskLow51Bits // drops the 2^255
+}
+
+// uint128 holds a 128-bit product.
+type uint128 struct {
+	lo, hi uint64
+}
+
+func mul64(a, b uint64) uint128 {
+	hi, lo := bits.Mul64(a, b)
+	return uint128{lo, hi}
+}
+
+func addMul64(v uint128, a, b uint64) uint128 {
+	hi, lo := bits.Mul64(a, b)
+	lo, c := bits.Add64(lo, v.lo, 0)
+	hi, _ = bits.Add64(hi, v.hi, c)
+	return uint128{lo, hi}
+}
+
+func shiftRightBy51(a uint128) uint64 {
+	return a.hi<<(64-51) | a.lo>>51
+}
+
+// feMulGeneric calculates h = f * g, one output column at a time.
+// Can overlap h with f or g.
+//
+// Products of limbs past 2^255 wrap around to the bottom multiplied by 19,
+// since 2^255 = 19 mod p.
+func feMulGeneric(h, f, g *FieldElement) {
+	f0, f1, f2, f3, f4 := f[0], f[1], f[2], f[3], f[4]
+	g0, g1, g2, g3, g4 := g[0], g[1], g[2], g[3], g[4]
+	f1_19 := f1 * 19
+	f2_19 := f2 * 19
+	f3_19 := f3 * 19
+	f4_19 := f4 * 19
+
+	// r0 = f0×g0 + 19×(f1×g4 + f2×g3 + f3×g2 + f4×g1)
+	r0 := mul64(f0, g0)
+	r0 = addMul64(r0, f1_19, g4)
+	r0 = addMul64(r0, f2_19, g3)
+	r0 = addMul64(r0, f3_19, g2)
+	r0 = addMul64(r0, f4_19, g1)
+
+	// r1 = f0×g1 + f1×g0 + 19×(f2×g4 + f3×g3 + f4×g2)
+	r1 := mul64(f0, g1)
+	r1 = addMul64(r1, f1, g0)
+	r1 = addMul64(r1, f2_19, g4)
+	r1 = addMul64(r1, f3_19, g3)
+	r1 = addMul64(r1, f4_19, g2)
+
+	// r2 = f0×g2 + f1×g1 + f2×g0 + 19×(f3×g4 + f4×g3)
+	r2 := mul64(f0, g2)
+	r2 = addMul64(r2, f1, g1)
+	r2 = addMul64(r2, f2, g0)
+	r2 = addMul64(r2, f3_19, g4)
+	r2 = addMul64(r2, f4_19, g3)
+
+	// r3 = f0×g3 + f1×g2 + f2×g1 + f3×g0 + 19×f4×g4
+	r3 := mul64(f0, g3)
+	r3 = addMul64(r3, f1, g2)
+	r3 = addMul64(r3, f2, g1)
+	r3 = addMul64(r3, f3, g0)
+	r3 = addMul64(r3, f4_19, g4)
+
+	// r4 = f0×g4 + f1×g3 + f2×g2 + f3×g1 + f4×g0
+	r4 := mul64(f0, g4)
+	r4 = addMul64(r4, f1, g3)
+	r4 = addMul64(r4, f2, g2)
+	r4 = addMul64(r4, f3, g1)
+	r4 = addMul64(r4, f4, g0)
+
+	// Each column is below 2^111. Carry it into the next, and the top
+	// one into the bottom times 19, then carry once more to bring every
+	// limb below 2^52. This is written out rather than left to
+	// feCarryPropagate, which is too large for the compiler to inline.
+	h0 := r0.lo&maskLow51Bits + shiftRightBy51(r4)*19
+	h1 := r1.lo&maskLow51Bits + shiftRightBy51(r0)
+	h2 := r2.lo&maskLow51Bits + shiftRightBy51(r1)
+	h3 := r3.lo&maskLow51Bits + shiftRightBy51(r2)
+	h4 := r4.lo&maskLow51Bits + shiftRightBy51(r3)
+
+	h[0] = h0&maskLow51Bits + (h4>>51)*19
+	h[1] = h1&maskLow51Bits + h0>>51
+	h[2] = h2&maskLow51Bits + h1>>51
+	h[3] = h3&maskLow51Bits + h2>>51
+	h[4] = h4&maskLow51Bits + h3>>51
+}
+
+// feSquareGeneric calculates h = f*f, one output column at a time.
+// Can overlap h with f.
+func feSquareGeneric(h, f *FieldElement) {
+	f0, f1, f2, f3, f4 := f[0], f[1], f[2], f[3], f[4]
+	f0_2 := f0 * 2
+	f1_2 := f1 * 2
+	f1_38 := f1 * 38
+	f2_38 := f2 * 38
+	f3_38 := f3 * 38
+	f3_19 := f3 * 19
+	f4_19 := f4 * 19
+
+	// r0 = f0×f0 + 19×2×(f1×f4 + f2×f3)
+	r0 := mul64(f0, f0)
+	r0 = addMul64(r0, f1_38, f4)
+	r0 = addMul64(r0, f2_38, f3)
+
+	// r1 = 2×f0×f1 + 19×2×f2×f4 + 19×f3×f3
+	r1 := mul64(f0_2, f1)
+	r1 = addMul64(r1, f2_38, f4)
+	r1 = addMul64(r1, f3_19, f3)
+
+	// r2 = 2×f0×f2 + f1×f1 + 19×2×f3×f4
+	r2 := mul64(f0_2, f2)
+	r2 = addMul64(r2, f1, f1)
+	r2 = addMul64(r2, f3_38, f4)
+
+	// r3 = 2×f0×f3 + 2×f1×f2 + 19×f4×f4
+	r3 := mul64(f0_2, f3)
+	r3 = addMul64(r3, f1_2, f2)
+	r3 = addMul64(r3, f4_19, f4)
+
+	// r4 = 2×f0×f4 + 2×f1×f3 + f2×f2
+	r4 := mul64(f0_2, f4)
+	r4 = addMul64(r4, f1_2, f3)
+	r4 = addMul64(r4, f2, f2)
+
+	// See feMulGeneric.
+	h0 := r0.lo&maskLow51Bits + shiftRightBy51(r4)*19
+	h1 := r1.lo&maskLow51Bits + shiftRightBy51(r0)
+	h2 := r2.lo&maskLow51Bits + shiftRightBy51(r1)
+	h3 := r3.lo&maskLow51Bits + shiftRightBy51(r2)
+	h4 := r4.lo&maskLow51Bits + shiftRightBy51(r3)
+
+	h[0] = h0&maskLow51Bits + (h4>>51)*19
+	h[1] = h1&maskLow51Bits + h0>>51
+	h[2] = h2&maskLow51Bits + h1>>51
+	h[3] = h3&maskLow51Bits + h2>>51
+	h[4] = h4&maskLow51Bits + h3>>51
+}
+
+// FeSquare2 sets h = 2 * f * f. Can overlap h with f.
+func FeSquare2(h, f *FieldElement) {
+	FeSquare(h, f)
+	FeAdd(h, h, h)
+}
+
+// FeFromLimbs10 sets h to the ref10 limbs l, each less than 2^27 in
+// absolute value, as are those of any ref10 field element.
+func FeFromLimbs10(h *FieldElement, l *[10]int32) {
+	// Limbs 2i and 2i+1 of ref10 start at bits 51i and 51i+26. Their
+	// signed sums are below 2^53 in absolute value, so adding 8p makes
+	// them non-negative without changing the element.
+	const p0, pi = 1<<51 - 19, 1<<51 - 1
+	h[0] = uint64(int64(l[0]) + int64(l[1])<<26 + 8*p0)
+	h[1] = uint64(int64(l[2]) + int64(l[3])<<26 + 8*pi)
+	h[2] = uint64(int64(l[4]) + int64(l[5])<<26 + 8*pi)
+	h[3] = uint64(int64(l[6]) + int64(l[7])<<26 + 8*pi)
+	h[4] = uint64(int64(l[8]) + int64(l[9])<<26 + 8*pi)
+	feCarryPropagate(h)
+}
+
+// FeToLimbs10 sets l to ref10 limbs of f, within the bounds
+// of a carried ref10 field element.
+func FeToLimbs10(l *[10]int32, f *FieldElement) {
+	t := *f
+	feReduce(&t)
+	for i, x := range t {
+		l[2*i] = int32(x & (1<<26 - 1))
+		l[2*i+1] = int32(x >> 26)
+	}
+}
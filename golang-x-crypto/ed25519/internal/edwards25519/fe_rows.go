@@ -0,0 +1,112 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build (amd64 || arm64 || ppc64le || s390x || riscv64 || loong64) && !ed25519_ref10
+
+package edwards25519
+
+// feMulRows calculates h = f * g like feMulGeneric, but one limb of f at a
+// time. Each row feeds all five column accumulators, so five independent
+// multiply-accumulate chains are in flight instead of one, for the separate
+// MUL and UMULH instructions of arm64 to overlap. The result is identical to
+// that of feMulGeneric, limb for limb; BenchmarkFeMulRows and
+// BenchmarkFeMulGeneric compare their speed.
+// Can overlap h with f or g.
+func feMulRows(h, f, g *FieldElement) {
+	f0, f1, f2, f3, f4 := f[0], f[1], f[2], f[3], f[4]
+	g0, g1, g2, g3, g4 := g[0], g[1], g[2], g[3], g[4]
+	f1_19 := f1 * 19
+	f2_19 := f2 * 19
+	f3_19 := f3 * 19
+	f4_19 := f4 * 19
+
+	r0 := mul64(f0, g0)
+	r1 := mul64(f0, g1)
+	r2 := mul64(f0, g2)
+	r3 := mul64(f0, g3)
+	r4 := mul64(f0, g4)
+
+	r0 = addMul64(r0, f1_19, g4)
+	r1 = addMul64(r1, f1, g0)
+	r2 = addMul64(r2, f1, g1)
+	r3 = addMul64(r3, f1, g2)
+	r4 = addMul64(r4, f1, g3)
+
+	r0 = addMul64(r0, f2_19, g3)
+	r1 = addMul64(r1, f2_19, g4)
+	r2 = addMul64(r2, f2, g0)
+	r3 = addMul64(r3, f2, g1)
+	r4 = addMul64(r4, f2, g2)
+
+	r0 = addMul64(r0, f3_19, g2)
+	r1 = addMul64(r1, f3_19, g3)
+	r2 = addMul64(r2, f3_19, g4)
+	r3 = addMul64(r3, f3, g0)
+	r4 = addMul64(r4, f3, g1)
+
+	r0 = addMul64(r0, f4_19, g1)
+	r1 = addMul64(r1, f4_19, g2)
+	r2 = addMul64(r2, f4_19, g3)
+	r3 = addMul64(r3, f4_19, g4)
+	r4 = addMul64(r4, f4, g0)
+
+	// See feMulGeneric.
+	h0 := r0.lo&maskLow51Bits + shiftRightBy51(r4)*19
+	h1 := r1.lo&maskLow51Bits + shiftRightBy51(r0)
+	h2 := r2.lo&maskLow51Bits + shiftRightBy51(r1)
+	h3 := r3.lo&maskLow51Bits + shiftRightBy51(r2)
+	h4 := r4.lo&maskLow51Bits + shiftRightBy51(r3)
+
+	h[0] = h0&maskLow51Bits + (h4>>51)*19
+	h[1] = h1&maskLow51Bits + h0>>51
+	h[2] = h2&maskLow51Bits + h1>>51
+	h[3] = h3&maskLow51Bits + h2>>51
+	h[4] = h4&maskLow51Bits + h3>>51
+}
+
+// feSquareRows calculates h = f*f like feSquareGeneric, in the row order
+// of feMulRows. Can overlap h with f.
+func feSquareRows(h, f *FieldElement) {
+	f0, f1, f2, f3, f4 := f[0], f[1], f[2], f[3], f[4]
+	f0_2 := f0 * 2
+	f1_2 := f1 * 2
+	f1_38 := f1 * 38
+	f2_38 := f2 * 38
+	f3_38 := f3 * 38
+	f3_19 := f3 * 19
+	f4_19 := f4 * 19
+
+	r0 := mul64(f0, f0)
+	r1 := mul64(f0_2, f1)
+	r2 := mul64(f0_2, f2)
+	r3 := mul64(f0_2, f3)
+	r4 := mul64(f0_2, f4)
+
+	r0 = addMul64(r0, f1_38, f4)
+	r2 = addMul64(r2, f1, f1)
+	r3 = addMul64(r3, f1_2, f2)
+	r4 = addMul64(r4, f1_2, f3)
+
+	r0 = addMul64(r0, f2_38, f3)
+	r1 = addMul64(r1, f2_38, f4)
+	r4 = addMul64(r4, f2, f2)
+
+	r1 = addMul64(r1, f3_19, f3)
+	r2 = addMul64(r2, f3_38, f4)
+
+	r3 = addMul64(r3, f4_19, f4)
+
+	// See feMulGeneric.
+	h0 := r0.lo&maskLow51Bits + shiftRightBy51(r4)*19
+	h1 := r1.lo&maskLow51Bits + shiftRightBy51(r0)
+	h2 := r2.lo&maskLow51Bits + shiftRightBy51(r1)
+	h3 := r3.lo&maskLow51Bits + shiftRightBy51(r2)
+	h4 := r4.lo&maskLow51Bits + shiftRightBy51(r3)
+
+	h[0] = h0&maskLow51Bits + (h4>>51)*19
+	h[1] = h1&maskLow51Bits + h0>>51
+	h[2] = h2&maskLow51Bits + h1>>51
+	h[3] = h3&maskLow51Bits + h2>>51
+	h[4] = h4&maskLow51Bits + h3>>51
+}
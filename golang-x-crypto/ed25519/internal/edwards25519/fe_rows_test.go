@@ -0,0 +1,69 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build (amd64 || arm64 || ppc64le || s390x || riscv64 || loong64) && !ed25519_ref10
+
+package edwards25519
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"testing"
+)
+
+// TestFeRows checks the arm64 row-ordered code against the column-ordered
+// code on every architecture, on random elements and on limbs at the top of
+// the accepted range.
+func TestFeRows(t *testing.T) {
+	const maxLimb = 1<<52 - 1
+	inputs := []FieldElement{
+		{},
+		{maxLimb, maxLimb, maxLimb, maxLimb, maxLimb},
+		{maxLimb, 0, maxLimb, 0, maxLimb},
+		{1, maxLimb, 1, maxLimb, 1},
+	}
+	var buf [40]byte
+	for i := 0; i < 256; i++ {
+		if _, err := rand.Read(buf[:]); err != nil {
+			t.Fatal(err)
+		}
+		var f FieldElement
+		for j := range f {
+			f[j] = binary.LittleEndian.Uint64(buf[8*j:]) & maxLimb
+		}
+		inputs = append(inputs, f)
+	}
+	for i, f := range inputs {
+		g := inputs[(i+1)%len(inputs)]
+		var want, got FieldElement
+		feMulGeneric(&want, &f, &g)
+		feMulRows(&got, &f, &g)
+		if got != want {
+			t.Fatalf("feMulRows(%x, %x) = %x, want %x", f, g, got, want)
+		}
+		feSquareGeneric(&want, &f)
+		feSquareRows(&got, &f)
+		if got != want {
+			t.Fatalf("feSquareRows(%x) = %x, want %x", f, got, want)
+		}
+	}
+}
+
+func BenchmarkFeMulRows(b *testing.B) {
+	var f FieldElement
+	FeOne(&f)
+	FeAdd(&f, &f, &SqrtM1)
+	for i := 0; i < b.N; i++ {
+		feMulRows(&f, &f, &d)
+	}
+}
+
+func BenchmarkFeMulGeneric(b *testing.B) {
+	var f FieldElement
+	FeOne(&f)
+	FeAdd(&f, &f, &SqrtM1)
+	for i := 0; i < b.N; i++ {
+		feMulGeneric(&f, &f, &d)
+	}
+}
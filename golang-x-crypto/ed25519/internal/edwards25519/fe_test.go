@@ -0,0 +1,126 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package edwards25519
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+var feP = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+
+func feToBig(f *FieldElement) *big.Int {
+	var b [32]byte
+	t := *f
+	FeToBytes(&b, &t)
+	for i := 0; i < 16; i++ {
+		b[i], b[31-i] = b[31-i], b[i]
+	}
+	return new(big.Int).SetBytes(b[:])
+}
+
+// randomFe returns a random field element and its value. Its encoding is
+// random below 2^255, so some elements are non-canonical, at least p.
+func randomFe(t *testing.T) (FieldElement, *big.Int) {
+	var b [32]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		t.Fatal(err)
+	}
+	b[31] &= 0x7f
+	var f FieldElement
+	FeFromBytes(&f, &b)
+	for i := 0; i < 16; i++ {
+		b[i], b[31-i] = b[31-i], b[i]
+	}
+	return f, new(big.Int).Mod(new(big.Int).SetBytes(b[:]), feP)
+}
+
+func TestFieldArithmetic(t *testing.T) {
+	check := func(op string, got *FieldElement, want *big.Int) {
+		t.Helper()
+		if g := feToBig(got); g.Cmp(want.Mod(want, feP)) != 0 {
+			t.Fatalf("%s = %x, want %x", op, g, want)
+		}
+	}
+	for i := 0; i < 256; i++ {
+		f, fb := randomFe(t)
+		g, gb := randomFe(t)
+		var h FieldElement
+
+		FeAdd(&h, &f, &g)
+		check("FeAdd", &h, new(big.Int).Add(fb, gb))
+		FeSub(&h, &f, &g)
+		check("FeSub", &h, new(big.Int).Sub(fb, gb))
+		FeNeg(&h, &f)
+		check("FeNeg", &h, new(big.Int).Neg(fb))
+		FeMul(&h, &f, &g)
+		check("FeMul", &h, new(big.Int).Mul(fb, gb))
+		FeSquare(&h, &f)
+		check("FeSquare", &h, new(big.Int).Mul(fb, fb))
+		FeSquare2(&h, &f)
+		check("FeSquare2", &h, new(big.Int).Lsh(new(big.Int).Mul(fb, fb), 1))
+		FeInvert(&h, &f)
+		check("FeInvert", &h, new(big.Int).Exp(fb, new(big.Int).Sub(feP, big.NewInt(2)), feP))
+
+		// Chained operations, as in the group law, without
+		// intermediate encodings.
+		var s, d FieldElement
+		FeAdd(&s, &f, &g)
+		FeSub(&d, &f, &g)
+		FeMul(&h, &s, &d)
+		FeSquare2(&h, &h)
+		FeSub(&h, &h, &s)
+		sb := new(big.Int).Add(fb, gb)
+		want := new(big.Int).Mul(sb, new(big.Int).Sub(fb, gb))
+		want.Mod(want, feP)
+		want.Lsh(want.Mul(want, want), 1)
+		check("chain", &h, want.Sub(want, sb))
+	}
+}
+
+func TestFeLimbs10(t *testing.T) {
+	for i := 0; i < 64; i++ {
+		f, fb := randomFe(t)
+		var l [10]int32
+		var g FieldElement
+		FeToLimbs10(&l, &f)
+		FeFromLimbs10(&g, &l)
+		if feToBig(&g).Cmp(fb) != 0 {
+			t.Fatalf("limbs10 round trip changed %x", fb)
+		}
+	}
+
+	// The constants were written as ref10 limbs.
+	var one, h FieldElement
+	FeOne(&one)
+	FeSquare(&h, &SqrtM1)
+	FeAdd(&h, &h, &one)
+	if FeIsNonZero(&h) != 0 {
+		t.Errorf("SqrtM1^2 != -1")
+	}
+	FeAdd(&h, &d, &d)
+	if feEqual(&h, &d2) != 1 {
+		t.Errorf("d + d != d2")
+	}
+}
+
+func BenchmarkFeMul(b *testing.B) {
+	var f FieldElement
+	FeOne(&f)
+	FeAdd(&f, &f, &SqrtM1)
+	for i := 0; i < b.N; i++ {
+		FeMul(&f, &f, &d)
+	}
+}
+
+func BenchmarkFeSquare(b *testing.B) {
+	var f FieldElement
+	FeOne(&f)
+	FeAdd(&f, &f, &SqrtM1)
+	for i := 0; i < b.N; i++ {
+		FeSquare(&f, &f)
+	}
+}
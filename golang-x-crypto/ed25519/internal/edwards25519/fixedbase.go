@@ -0,0 +1,76 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package edwards25519
+
+// FixedBaseTable holds the multiples j*256^i*P, for i < 32 and 1 <= j <= 8,
+// of a point P, in the affine form of the base point table,
+// so that GeScalarMultFixedBase computes multiples of P
+// as fast as GeScalarMultBase computes those of B.
+// It takes about 30 KiB.
+type FixedBaseTable [32][8]PreComputedGroupElement
+
+// Init sets table to the table of P.
+func (table *FixedBaseTable) Init(P *ExtendedGroupElement) {
+	var points [32][8]ExtendedGroupElement
+	var t CompletedGroupElement
+	var s ProjectiveGroupElement
+	var c CachedGroupElement
+	Q := *P // 256^i * P
+	for i := range points {
+		points[i][0] = Q
+		Q.ToCached(&c)
+		for j := 1; j < 8; j++ {
+			geAdd(&t, &points[i][j-1], &c)
+			t.ToExtended(&points[i][j])
+		}
+		if i == len(points)-1 {
+			break
+		}
+		Q.ToProjective(&s)
+		for k := 0; k < 7; k++ {
+			s.Double(&t)
+			t.ToProjective(&s)
+		}
+		s.Double(&t)
+		t.ToExtended(&Q)
+	}
+
+	// Convert to affine coordinates with one inversion
+	// shared by all 256 points (Montgomery's trick).
+	var prefix [32][8]FieldElement
+	var acc, inv, zInv, x, y FieldElement
+	FeOne(&acc)
+	for i := range points {
+		for j := range points[i] {
+			prefix[i][j] = acc
+			FeMul(&acc, &acc, &points[i][j].Z)
+		}
+	}
+	FeInvert(&inv, &acc)
+	for i := len(points) - 1; i >= 0; i-- {
+		for j := len(points[i]) - 1; j >= 0; j-- {
+			p := &points[i][j]
+			FeMul(&zInv, &inv, &prefix[i][j])
+			FeMul(&inv, &inv, &p.Z)
+			FeMul(&x, &p.X, &zInv)
+			FeMul(&y, &p.Y, &zInv)
+			e := &table[i][j]
+			FeAdd(&e.yPlusX, &y, &x)
+			FeSub(&e.yMinusX, &y, &x)
+			FeMul(&e.xy2d, &x, &y)
+			FeMul(&e.xy2d, &e.xy2d, &d2)
+		}
+	}
+}
+
+// GeScalarMultFixedBase computes h = a*P, where table is the table of P,
+// in constant time.
+//
+// Preconditions:
+//
+//	a[31] <= 127
+func GeScalarMultFixedBase(h *ExtendedGroupElement, a *[32]byte, table *FixedBaseTable) {
+	geScalarMultTable(h, a, (*[32][8]PreComputedGroupElement)(table))
+}
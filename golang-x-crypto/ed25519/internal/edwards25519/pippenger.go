@@ -0,0 +1,132 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package edwards25519
+
+import "encoding/binary"
+
+// pippengerThreshold is the number of terms from which
+// GeMultiScalarMultVartime switches to Pippenger's method.
+const pippengerThreshold = 200
+
+// pippengerWindow returns the digit width for a sum of n terms.
+func pippengerWindow(n int) uint {
+	switch {
+	case n < 500:
+		return 6
+	case n < 2000:
+		return 7
+	case n < 4000:
+		return 8
+	default:
+		return 9
+	}
+}
+
+// geMultiScalarMultPippenger implements GeMultiScalarMultVartime
+// with Pippenger's bucket method.
+//
+// Each scalar is recoded into signed w-bit digits d in [-2^(w-1), 2^(w-1)).
+// For every digit position, from the top, the total is multiplied by 2^w,
+// and each point is added to (or subtracted from) the bucket of its digit;
+// Σ b*bucket[b] is then summed with running sums, in 2^w additions,
+// however many points there are.
+func geMultiScalarMultPippenger(r *ExtendedGroupElement, a []*[32]byte, A []*ExtendedGroupElement) {
+	n := len(a)
+	w := pippengerWindow(n)
+	digits, digitCount := pippengerDigits(a, w)
+	points := make([]CachedGroupElement, n)
+	for j := range A {
+		A[j].ToCached(&points[j])
+	}
+
+	buckets := make([]ExtendedGroupElement, 1<<(w-1))
+	used := make([]bool, len(buckets))
+	var t CompletedGroupElement
+	var total, running, sum ExtendedGroupElement
+	total.Zero()
+	for i := digitCount - 1; i >= 0; i-- {
+		if i != digitCount-1 {
+			for k := uint(0); k < w; k++ {
+				total.Double(&t)
+				t.ToExtended(&total)
+			}
+		}
+
+		clear(used)
+		for j := range points {
+			d := digits[j*digitCount+i]
+			switch {
+			case d > 0:
+				addToBucket(&buckets[d-1], &used[d-1], &points[j], false)
+			case d < 0:
+				addToBucket(&buckets[-d-1], &used[-d-1], &points[j], true)
+			}
+		}
+
+		// sum = Σ b*bucket[b-1] = Σ_b Σ_{c>=b} bucket[c-1]
+		running.Zero()
+		sum.Zero()
+		for b := len(buckets) - 1; b >= 0; b-- {
+			if used[b] {
+				running.Add(&running, &buckets[b])
+			}
+			sum.Add(&sum, &running)
+		}
+		total.Add(&total, &sum)
+	}
+	*r = total
+}
+
+// pippengerDigits returns the signed w-bit digits of the scalars a,
+// digitCount of them for each scalar in turn.
+func pippengerDigits(a []*[32]byte, w uint) (digits []int16, digitCount int) {
+	digitCount = (255+int(w)-1)/int(w) + 1
+	digits = make([]int16, len(a)*digitCount)
+	for j := range a {
+		recodeSigned(digits[j*digitCount:(j+1)*digitCount], a[j], w)
+	}
+	return digits, digitCount
+}
+
+// addToBucket adds p to, or if neg subtracts it from, the bucket B,
+// which holds the identity if !*used.
+func addToBucket(B *ExtendedGroupElement, used *bool, p *CachedGroupElement, neg bool) {
+	if !*used {
+		B.Zero()
+		*used = true
+	}
+	var t CompletedGroupElement
+	if neg {
+		geSub(&t, B, p)
+	} else {
+		geAdd(&t, B, p)
+	}
+	t.ToExtended(B)
+}
+
+// recodeSigned writes the signed radix-2^w digits of a to digits,
+// least significant first, with each digit in [-2^(w-1), 2^(w-1))
+// except possibly the last, which takes the final carry.
+func recodeSigned(digits []int16, a *[32]byte, w uint) {
+	var buf [40]byte
+	copy(buf[:], a[:])
+	mask := uint64(1)<<w - 1
+	half := int16(1) << (w - 1)
+	carry := int16(0)
+	for i := range digits {
+		bit := uint(i) * w
+		var window uint64
+		if bit < 256 {
+			window = binary.LittleEndian.Uint64(buf[bit/8:]) >> (bit % 8) & mask
+		}
+		d := int16(window) + carry
+		carry = 0
+		if d >= half && i != len(digits)-1 {
+			d -= 1 << w
+			carry = 1
+		}
+		digits[i] = d
+	}
+}
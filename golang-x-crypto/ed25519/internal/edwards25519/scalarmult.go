@@ -0,0 +1,261 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package edwards25519
+
+// GeScalarMultVartime sets r = a*A
+// where a = a[0]+256*a[1]+...+256^31 a[31].
+// It runs in variable time and must not be used with secret scalars.
+//
+// Preconditions:
+//   a[31] <= 127
+func GeScalarMultVartime(r *ExtendedGroupElement, a *[32]byte, A *ExtendedGroupElement) {
+	var aSlide [256]int8
+	var Ai [8]CachedGroupElement // A,3A,5A,7A,9A,11A,13A,15A
+	var t CompletedGroupElement
+	var u, A2 ExtendedGroupElement
+	var p ProjectiveGroupElement
+	var i int
+
+	slide(&aSlide, a)
+
+	A.ToCached(&Ai[0])
+	A.Double(&t)
+	t.ToExtended(&A2)
+
+	for i := 0; i < 7; i++ {
+		geAdd(&t, &A2, &Ai[i])
+		t.ToExtended(&u)
+		u.ToCached(&Ai[i+1])
+	}
+
+	for i = 255; i >= 0; i-- {
+		if aSlide[i] != 0 {
+			break
+		}
+	}
+
+	r.Zero()
+	p.Zero()
+	for ; i >= 0; i-- {
+		p.Double(&t)
+
+		if aSlide[i] > 0 {
+			t.ToExtended(&u)
+			geAdd(&t, &u, &Ai[aSlide[i]/2])
+		} else if aSlide[i] < 0 {
+			t.ToExtended(&u)
+			geSub(&t, &u, &Ai[(-aSlide[i])/2])
+		}
+
+		if i == 0 {
+			t.ToExtended(r)
+		} else {
+			t.ToProjective(&p)
+		}
+	}
+}
+
+func (p *CachedGroupElement) Zero() {
+	FeOne(&p.yPlusX)
+	FeOne(&p.yMinusX)
+	FeOne(&p.Z)
+	FeZero(&p.T2d)
+}
+
+func CachedGroupElementCMove(t, u *CachedGroupElement, b int32) {
+	FeCMove(&t.yPlusX, &u.yPlusX, b)
+	FeCMove(&t.yMinusX, &u.yMinusX, b)
+	FeCMove(&t.Z, &u.Z, b)
+	FeCMove(&t.T2d, &u.T2d, b)
+}
+
+// selectCached sets t = b*A in constant time,
+// given table[i] = (i+1)*A and -8 <= b <= 8.
+func selectCached(t *CachedGroupElement, table *[8]CachedGroupElement, b int32) {
+	var minusT CachedGroupElement
+	bNegative := negative(b)
+	bAbs := b - (((-bNegative) & b) << 1)
+
+	t.Zero()
+	for i := int32(0); i < 8; i++ {
+		CachedGroupElementCMove(t, &table[i], equal(bAbs, i+1))
+	}
+	FeCopy(&minusT.yPlusX, &t.yMinusX)
+	FeCopy(&minusT.yMinusX, &t.yPlusX)
+	FeCopy(&minusT.Z, &t.Z)
+	FeNeg(&minusT.T2d, &t.T2d)
+	CachedGroupElementCMove(t, &minusT, bNegative)
+}
+
+// GeScalarMult sets r = a*A
+// where a = a[0]+256*a[1]+...+256^31 a[31].
+// Unlike GeScalarMultVartime, it runs in constant time
+// with respect to a, so it may be used with secret scalars.
+//
+// Preconditions:
+//   a[31] <= 127
+func GeScalarMult(r *ExtendedGroupElement, a *[32]byte, A *ExtendedGroupElement) {
+	var e [64]int8
+	for i, v := range a {
+		e[2*i] = int8(v & 15)
+		e[2*i+1] = int8((v >> 4) & 15)
+	}
+	carry := int8(0)
+	for i := 0; i < 63; i++ {
+		e[i] += carry
+		carry = (e[i] + 8) >> 4
+		e[i] -= carry << 4
+	}
+	e[63] += carry
+	// each e[i] is between -8 and 8.
+
+	var table [8]CachedGroupElement // A, 2A, ..., 8A
+	var t CompletedGroupElement
+	var u ExtendedGroupElement
+	A.ToCached(&table[0])
+	for i := 0; i < 7; i++ {
+		geAdd(&t, A, &table[i])
+		t.ToExtended(&u)
+		u.ToCached(&table[i+1])
+	}
+
+	var s ProjectiveGroupElement
+	var c CachedGroupElement
+	r.Zero()
+	for i := 63; i >= 0; i-- {
+		if i != 63 {
+			r.Double(&t)
+			t.ToProjective(&s)
+			s.Double(&t)
+			t.ToProjective(&s)
+			s.Double(&t)
+			t.ToProjective(&s)
+			s.Double(&t)
+			t.ToExtended(r)
+		}
+		selectCached(&c, &table, int32(e[i]))
+		geAdd(&t, r, &c)
+		t.ToExtended(r)
+	}
+}
+
+// GeMultiScalarMultVartime sets r = a[0]*A[0] + ... + a[n-1]*A[n-1].
+// Small sums use Straus' interleaved method, sharing the doublings
+// among all terms; from pippengerThreshold terms on, Pippenger's
+// bucket method, whose cost per term shrinks as the sum grows,
+// and whose bucket additions use AVX2 where available.
+// It runs in variable time and must not be used with secret scalars.
+//
+// Preconditions:
+//   len(a) == len(A)
+//   a[i][31] <= 127
+func GeMultiScalarMultVartime(r *ExtendedGroupElement, a []*[32]byte, A []*ExtendedGroupElement) {
+	if len(a) >= pippengerThreshold && useAVX2 {
+		geMultiScalarMultPippengerAVX2(r, a, A)
+	} else if len(a) >= pippengerThreshold {
+		geMultiScalarMultPippenger(r, a, A)
+	} else {
+		geMultiScalarMultStraus(r, a, A)
+	}
+}
+
+// geMultiScalarMultStraus implements GeMultiScalarMultVartime
+// with Straus' method.
+func geMultiScalarMultStraus(r *ExtendedGroupElement, a []*[32]byte, A []*ExtendedGroupElement) {
+	n := len(a)
+	slides := make([][256]int8, n)
+	tables := make([][8]CachedGroupElement, n) // A,3A,5A,...,15A
+	var t CompletedGroupElement
+	var u, A2 ExtendedGroupElement
+
+	top := -1
+	for j := 0; j < n; j++ {
+		slide(&slides[j], a[j])
+		for i := 255; i > top; i-- {
+			if slides[j][i] != 0 {
+				top = i
+				break
+			}
+		}
+
+		A[j].ToCached(&tables[j][0])
+		A[j].Double(&t)
+		t.ToExtended(&A2)
+		for i := 0; i < 7; i++ {
+			geAdd(&t, &A2, &tables[j][i])
+			t.ToExtended(&u)
+			u.ToCached(&tables[j][i+1])
+		}
+	}
+
+	r.Zero()
+	var p ProjectiveGroupElement
+	p.Zero()
+	for i := top; i >= 0; i-- {
+		p.Double(&t)
+		t.ToExtended(&u)
+		for j := 0; j < n; j++ {
+			if s := slides[j][i]; s > 0 {
+				geAdd(&t, &u, &tables[j][s/2])
+				t.ToExtended(&u)
+			} else if s < 0 {
+				geSub(&t, &u, &tables[j][(-s)/2])
+				t.ToExtended(&u)
+			}
+		}
+		u.ToProjective(&p)
+	}
+	if top >= 0 {
+		*r = u
+	}
+}
+
+// IsIdentity reports whether p is the neutral element.
+// It runs in variable time.
+func (p *ExtendedGroupElement) IsIdentity() bool {
+	var d FieldElement
+	FeSub(&d, &p.Y, &p.Z)
+	return FeIsNonZero(&p.X) == 0 && FeIsNonZero(&d) == 0
+}
+
+// MulByCofactor sets p = 8*a.
+// See ClearCofactor for the map that keeps the prime-order component.
+func (p *ExtendedGroupElement) MulByCofactor(a *ExtendedGroupElement) {
+	var t CompletedGroupElement
+	var s ProjectiveGroupElement
+	a.Double(&t)
+	t.ToProjective(&s)
+	s.Double(&t)
+	t.ToProjective(&s)
+	s.Double(&t)
+	t.ToExtended(p)
+}
+
+// scOrder is the order l of the prime-order subgroup.
+var scOrder = [32]byte{0xed, 0xd3, 0xf5, 0x5c, 0x1a, 0x63, 0x12, 0x58, 0xd6, 0x9c, 0xf7, 0xa2, 0xde, 0xf9, 0xde, 0x14, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x10}
+
+// scEightInverse is the inverse of 8 modulo l.
+var scEightInverse = [32]byte{0x79, 0x2f, 0xdc, 0xe2, 0x29, 0xe5, 0x06, 0x61, 0xd0, 0xda, 0x1c, 0x7d, 0xb3, 0x9d, 0xd3, 0x07, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x06}
+
+// ClearCofactor sets p to the component of a in the prime-order subgroup,
+// dropping its small-order component: for a = x*B + T, with T of order
+// 1, 2, 4 or 8, p = x*B. Unlike MulByCofactor, it leaves points of the
+// prime-order subgroup unchanged. It runs in constant time.
+func (p *ExtendedGroupElement) ClearCofactor(a *ExtendedGroupElement) {
+	// 8 * (8^-1 mod l) is 1 modulo l and 0 modulo 8.
+	var t ExtendedGroupElement
+	t.MulByCofactor(a)
+	GeScalarMult(p, &scEightInverse, &t)
+}
+
+// IsTorsionFree returns 1 if p is in the prime-order subgroup, that is,
+// if l*p is the identity, and 0 otherwise. It runs in constant time.
+func (p *ExtendedGroupElement) IsTorsionFree() int32 {
+	var q ExtendedGroupElement
+	var d FieldElement
+	GeScalarMult(&q, &scOrder, p)
+	FeSub(&d, &q.Y, &q.Z)
+	return 1 ^ (FeIsNonZero(&q.X) | FeIsNonZero(&d))
+}
@@ -0,0 +1,104 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+)
+
+// JWK is an Ed25519 key in the JSON Web Key format
+// of RFC 7517, using the "OKP" key type of RFC 8037.
+// It marshals to and from JSON with encoding/json.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Curve     string `json:"crv"`
+	X         string `json:"x"`           // base64url public key
+	D         string `json:"d,omitempty"` // base64url seed, for private keys
+	KeyID     string `json:"kid,omitempty"`
+	Algorithm string `json:"alg,omitempty"`
+	Use       string `json:"use,omitempty"`
+}
+
+// JWKSet is a JSON Web Key Set, as served by JWKS endpoints.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// PublicJWK returns the JWK form of a public key, with the given key ID.
+func PublicJWK(publicKey PublicKey, kid string) JWK {
+	return JWK{
+		KeyType:   "OKP",
+		Curve:     "Ed25519",
+		X:         base64.RawURLEncoding.EncodeToString(publicKey),
+		KeyID:     kid,
+		Algorithm: "EdDSA",
+	}
+}
+
+// PrivateJWK returns the JWK form of a private key, with the given key ID.
+// It will panic if len(privateKey) is not PrivateKeySize.
+func PrivateJWK(privateKey PrivateKey, kid string) JWK {
+	k := PublicJWK(privateKey.Public().(PublicKey), kid)
+	k.D = base64.RawURLEncoding.EncodeToString(privateKey.Seed())
+	return k
+}
+
+// PublicKey returns the public key held by k.
+// It returns an error if k is not an Ed25519 key.
+func (k JWK) PublicKey() (PublicKey, error) {
+	if k.KeyType != "OKP" || k.Curve != "Ed25519" {
+		return nil, errors.New("ed25519: JWK is not an Ed25519 key")
+	}
+	if k.Algorithm != "" && k.Algorithm != "EdDSA" {
+		return nil, errors.New("ed25519: JWK algorithm is not EdDSA")
+	}
+	x, err := base64.RawURLEncoding.DecodeString(k.X)
+	if err != nil || len(x) != PublicKeySize {
+		return nil, errors.New("ed25519: malformed JWK public key")
+	}
+	return PublicKey(x), nil
+}
+
+// PrivateKey returns the private key held by k.
+// It returns an error if k is not an Ed25519 private key,
+// or if its public and private parts do not match.
+func (k JWK) PrivateKey() (PrivateKey, error) {
+	pub, err := k.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+	if k.D == "" {
+		return nil, errors.New("ed25519: JWK has no private key")
+	}
+	d, err := base64.RawURLEncoding.DecodeString(k.D)
+	if err != nil || len(d) != SeedSize {
+		return nil, errors.New("ed25519: malformed JWK private key")
+	}
+	priv := NewKeyFromSeed(d)
+	if subtle.ConstantTimeCompare(priv[32:], pub) != 1 {
+		return nil, errors.New("ed25519: JWK private key does not match public key")
+	}
+	return priv, nil
+}
+
+// Public returns the JWK form of the public part of k,
+// dropping any private key.
+func (k JWK) Public() JWK {
+	k.D = ""
+	return k
+}
+
+// Thumbprint returns the RFC 7638 thumbprint of k,
+// the base64url SHA-256 hash of its required members,
+// suitable as a key ID.
+func (k JWK) Thumbprint() string {
+	// The members in lexicographic order, with no whitespace.
+	// kty, crv and x never need escaping for valid keys.
+	h := sha256.Sum256([]byte(`{"crv":"` + k.Curve + `","kty":"` + k.KeyType + `","x":"` + k.X + `"}`))
+	return base64.RawURLEncoding.EncodeToString(h[:])
+}
@@ -0,0 +1,79 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"testing"
+)
+
+// The example key of RFC 8037, Appendix A.1.
+const rfc8037Key = `{"kty":"OKP","crv":"Ed25519",
+"d":"nWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A",
+"x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`
+
+func TestJWK(t *testing.T) {
+	var k JWK
+	if err := json.Unmarshal([]byte(rfc8037Key), &k); err != nil {
+		t.Fatal(err)
+	}
+	priv, err := k.PrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := k.Public().PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pub.Equal(priv.Public()) {
+		t.Errorf("public key does not match private key")
+	}
+	// RFC 8037, Appendix A.3
+	if got, want := k.Thumbprint(), "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k"; got != want {
+		t.Errorf("thumbprint %s, want %s", got, want)
+	}
+
+	if _, err := k.Public().PrivateKey(); err == nil {
+		t.Errorf("private key extracted from public JWK")
+	}
+	mismatched := k
+	mismatched.X = PublicJWK(make(PublicKey, PublicKeySize), "").X
+	if _, err := mismatched.PrivateKey(); err == nil {
+		t.Errorf("mismatched private key accepted")
+	}
+	wrongCurve := k
+	wrongCurve.Curve = "X25519"
+	if _, err := wrongCurve.PublicKey(); err == nil {
+		t.Errorf("X25519 key accepted")
+	}
+}
+
+func TestJWKRoundTrip(t *testing.T) {
+	pub, priv, _ := GenerateKey(rand.Reader)
+	set := JWKSet{Keys: []JWK{PrivateJWK(priv, "signer")}}
+	data, err := json.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var set2 JWKSet
+	if err := json.Unmarshal(data, &set2); err != nil {
+		t.Fatal(err)
+	}
+	if len(set2.Keys) != 1 || set2.Keys[0].KeyID != "signer" {
+		t.Fatalf("got %+v", set2)
+	}
+	priv2, err := set2.Keys[0].PrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(priv, priv2) {
+		t.Errorf("private key changed in round trip")
+	}
+	if got := PublicJWK(pub, "signer"); got != set2.Keys[0].Public() {
+		t.Errorf("public JWK %+v, want %+v", set2.Keys[0].Public(), got)
+	}
+}
@@ -0,0 +1,350 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package openpgp implements the small part of OpenPGP (RFC 4880)
+// needed to publish Ed25519 keys and detached signatures:
+// a version 4 EdDSA public key with one user ID and its self-signature,
+// and detached signatures over binary data,
+// as produced and checked by GnuPG with
+// gpg --import, gpg --detach-sign and gpg --verify.
+//
+// Keys use the EdDSA algorithm and the Ed25519 curve OID of
+// draft-ietf-openpgp-rfc4880bis, as GnuPG 2.2 and later do.
+// Encryption, subkeys, compression, revocation
+// and the other parts of OpenPGP are not supported.
+package openpgp
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"time"
+
+	"test-server/golang-x-crypto/ed25519"
+)
+
+const (
+	algoEdDSA  = 22
+	hashSHA256 = 8
+	hashSHA512 = 10
+
+	sigBinary       = 0x00
+	sigCertGeneric  = 0x10
+	sigCertPositive = 0x13
+
+	subCreationTime = 2
+	subIssuer       = 16
+	subKeyFlags     = 27
+	subIssuerFpr    = 33
+
+	keyFlagsCertifySign = 0x03
+)
+
+// oidEd25519 is the curve OID 1.3.6.1.4.1.11591.15.1, without its tag and length.
+var oidEd25519 = []byte{0x2b, 0x06, 0x01, 0x04, 0x01, 0xda, 0x47, 0x0f, 0x01}
+
+// An Entity is an OpenPGP identity: an Ed25519 key,
+// the time it was created, and a user ID.
+// The creation time is part of the key's fingerprint,
+// so the same key with a different creation time
+// is a different OpenPGP key.
+type Entity struct {
+	PublicKey ed25519.PublicKey
+	// PrivateKey is the private key, or nil for an entity
+	// that can only verify signatures.
+	PrivateKey ed25519.PrivateKey
+	Created    time.Time
+	// UserID is conventionally of the form "Name <email>".
+	UserID string
+}
+
+// NewEntity returns an entity for privateKey, created at the given time,
+// with the given user ID.
+func NewEntity(privateKey ed25519.PrivateKey, userID string, created time.Time) *Entity {
+	return &Entity{
+		PublicKey:  privateKey.Public().(ed25519.PublicKey),
+		PrivateKey: privateKey,
+		Created:    created,
+		UserID:     userID,
+	}
+}
+
+// publicKeyBody returns the body of the entity's public key packet.
+func (e *Entity) publicKeyBody() []byte {
+	b := []byte{4}
+	b = binary.BigEndian.AppendUint32(b, uint32(e.Created.Unix()))
+	b = append(b, algoEdDSA, byte(len(oidEd25519)))
+	b = append(b, oidEd25519...)
+	return appendMPI(b, append([]byte{0x40}, e.PublicKey...))
+}
+
+// Fingerprint returns the 20-byte version 4 fingerprint of the entity's key.
+func (e *Entity) Fingerprint() []byte {
+	h := sha1.New()
+	e.hashKey(h)
+	return h.Sum(nil)
+}
+
+// KeyID returns the key ID, the low 64 bits of the fingerprint.
+func (e *Entity) KeyID() uint64 {
+	return binary.BigEndian.Uint64(e.Fingerprint()[12:])
+}
+
+// hashKey writes the key to h as hashed for fingerprints and certifications.
+func (e *Entity) hashKey(h hash.Hash) {
+	body := e.publicKeyBody()
+	h.Write([]byte{0x99, byte(len(body) >> 8), byte(len(body))})
+	h.Write(body)
+}
+
+// hashUserID writes the user ID to h as hashed for certifications.
+func (e *Entity) hashUserID(h hash.Hash) {
+	var l [4]byte
+	binary.BigEndian.PutUint32(l[:], uint32(len(e.UserID)))
+	h.Write([]byte{0xb4})
+	h.Write(l[:])
+	h.Write([]byte(e.UserID))
+}
+
+// Serialize returns the entity's transferable public key:
+// its public key, user ID and self-signature packets.
+// It returns an error if the entity has no private key to self-sign with.
+func (e *Entity) Serialize() ([]byte, error) {
+	if e.PrivateKey == nil {
+		return nil, errors.New("openpgp: self-signature requires the private key")
+	}
+	h := sha512.New()
+	e.hashKey(h)
+	e.hashUserID(h)
+	sig := e.sign(h, sigCertPositive, e.Created, []byte{keyFlagsCertifySign})
+
+	var b []byte
+	b = appendPacket(b, tagPublicKey, e.publicKeyBody())
+	b = appendPacket(b, tagUserID, []byte(e.UserID))
+	return appendPacket(b, tagSignature, sig), nil
+}
+
+// Armored is like Serialize, but returns the key
+// as an ASCII-armored "PGP PUBLIC KEY BLOCK", as gpg --armor --export does.
+func (e *Entity) Armored() ([]byte, error) {
+	b, err := e.Serialize()
+	if err != nil {
+		return nil, err
+	}
+	return armor(armorPublicKey, b), nil
+}
+
+// SignDetached returns an ASCII-armored detached signature of message,
+// made at time now, as gpg --armor --detach-sign does.
+// It will panic if the entity has no private key.
+func (e *Entity) SignDetached(message []byte, now time.Time) []byte {
+	if e.PrivateKey == nil {
+		panic("openpgp: signing requires the private key")
+	}
+	h := sha512.New()
+	h.Write(message)
+	return armor(armorSignature, appendPacket(nil, tagSignature, e.sign(h, sigBinary, now, nil)))
+}
+
+// sign returns the body of a version 4 signature packet
+// over the data already written to h, which must be SHA-512.
+// keyFlags, if not nil, is included as a key flags subpacket.
+func (e *Entity) sign(h hash.Hash, sigType byte, now time.Time, keyFlags []byte) []byte {
+	var hashed []byte
+	hashed = appendSubpacket(hashed, subIssuerFpr, append([]byte{4}, e.Fingerprint()...))
+	hashed = appendSubpacket(hashed, subCreationTime, binary.BigEndian.AppendUint32(nil, uint32(now.Unix())))
+	if keyFlags != nil {
+		hashed = appendSubpacket(hashed, subKeyFlags, keyFlags)
+	}
+	var unhashed []byte
+	unhashed = appendSubpacket(unhashed, subIssuer, binary.BigEndian.AppendUint64(nil, e.KeyID()))
+
+	b := []byte{4, sigType, algoEdDSA, hashSHA512}
+	b = binary.BigEndian.AppendUint16(b, uint16(len(hashed)))
+	b = append(b, hashed...)
+	digest := hashTrailer(h, b)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(unhashed)))
+	b = append(b, unhashed...)
+
+	sig := ed25519.Sign(e.PrivateKey, digest)
+	b = append(b, digest[:2]...)
+	b = appendMPI(b, sig[:32])
+	return appendMPI(b, sig[32:])
+}
+
+// hashTrailer finishes a version 4 signature hash,
+// given the signature's hashed part, and returns the digest.
+func hashTrailer(h hash.Hash, hashedPart []byte) []byte {
+	h.Write(hashedPart)
+	h.Write([]byte{4, 0xff})
+	h.Write(binary.BigEndian.AppendUint32(nil, uint32(len(hashedPart))))
+	return h.Sum(nil)
+}
+
+// appendSubpacket appends a signature subpacket to b.
+func appendSubpacket(b []byte, typ byte, data []byte) []byte {
+	b = append(b, byte(1+len(data)), typ) // all subpackets here are short
+	return append(b, data...)
+}
+
+// ReadEntity parses a transferable public key,
+// ASCII-armored or binary, as written by Serialize or gpg --export.
+// It uses the first user ID carrying a valid self-certification,
+// and returns an error if there is none or the key is not an Ed25519 key.
+// Subkeys and any packets following them are ignored.
+func ReadEntity(data []byte) (*Entity, error) {
+	data, err := dearmor(armorPublicKey, data)
+	if err != nil {
+		return nil, err
+	}
+	packets, err := readPackets(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(packets) == 0 || packets[0].tag != tagPublicKey {
+		return nil, errors.New("openpgp: no public key packet")
+	}
+	e, err := parsePublicKey(packets[0].body)
+	if err != nil {
+		return nil, err
+	}
+	var userID *string
+packets:
+	for _, p := range packets[1:] {
+		switch p.tag {
+		case tagUserID:
+			uid := string(p.body)
+			userID = &uid
+		case tagSignature:
+			if userID == nil {
+				continue // direct key signature
+			}
+			e.UserID = *userID
+			if e.verifyCertification(p.body) == nil {
+				return e, nil
+			}
+		case tagPublicKey, tagPublicSubkey:
+			break packets
+		}
+	}
+	return nil, errors.New("openpgp: no self-signed user ID")
+}
+
+// parsePublicKey parses the body of an Ed25519 public key packet.
+func parsePublicKey(body []byte) (*Entity, error) {
+	if len(body) < 7 || body[0] != 4 {
+		return nil, errors.New("openpgp: unsupported public key version")
+	}
+	created := time.Unix(int64(binary.BigEndian.Uint32(body[1:])), 0)
+	if body[5] != algoEdDSA {
+		return nil, errors.New("openpgp: public key is not an EdDSA key")
+	}
+	rest := body[6:]
+	if len(rest) < 1+len(oidEd25519) || int(rest[0]) != len(oidEd25519) ||
+		!bytes.Equal(rest[1:1+len(oidEd25519)], oidEd25519) {
+		return nil, errors.New("openpgp: public key is not an Ed25519 key")
+	}
+	point, rest, err := readMPI(rest[1+len(oidEd25519):])
+	if err != nil || len(rest) != 0 || len(point) != 1+ed25519.PublicKeySize || point[0] != 0x40 {
+		return nil, errMalformed
+	}
+	return &Entity{
+		PublicKey: ed25519.PublicKey(append([]byte{}, point[1:]...)),
+		Created:   created,
+	}, nil
+}
+
+// VerifyDetached checks that sig, an ASCII-armored or binary
+// detached signature, is a valid signature of message by the entity,
+// as gpg --verify does.
+func (e *Entity) VerifyDetached(message, sig []byte) error {
+	sig, err := dearmor(armorSignature, sig)
+	if err != nil {
+		return err
+	}
+	packets, err := readPackets(sig)
+	if err != nil {
+		return err
+	}
+	if len(packets) != 1 || packets[0].tag != tagSignature {
+		return errors.New("openpgp: not a detached signature")
+	}
+	return e.verify(packets[0].body, func(h hash.Hash) { h.Write(message) }, sigBinary, sigBinary)
+}
+
+// verifyCertification checks a certification of the entity's user ID.
+func (e *Entity) verifyCertification(body []byte) error {
+	return e.verify(body, func(h hash.Hash) {
+		e.hashKey(h)
+		e.hashUserID(h)
+	}, sigCertGeneric, sigCertPositive)
+}
+
+// verify checks the body of a version 4 signature packet
+// of a type between minType and maxType
+// over the data written by hashData.
+func (e *Entity) verify(body []byte, hashData func(hash.Hash), minType, maxType byte) error {
+	if len(body) < 6 || body[0] != 4 {
+		return errors.New("openpgp: unsupported signature version")
+	}
+	if body[1] < minType || body[1] > maxType {
+		return errors.New("openpgp: unexpected signature type")
+	}
+	if body[2] != algoEdDSA {
+		return errors.New("openpgp: signature is not an EdDSA signature")
+	}
+	var hashFunc crypto.Hash
+	switch body[3] {
+	case hashSHA256:
+		hashFunc = crypto.SHA256
+	case hashSHA512:
+		hashFunc = crypto.SHA512
+	default:
+		return errors.New("openpgp: unsupported signature hash algorithm")
+	}
+	n := int(binary.BigEndian.Uint16(body[4:]))
+	if 6+n+2 > len(body) {
+		return errMalformed
+	}
+	hashedPart, rest := body[:6+n], body[6+n:]
+	n = int(binary.BigEndian.Uint16(rest))
+	if 2+n+2 > len(rest) {
+		return errMalformed
+	}
+	prefix, rest := rest[2+n:2+n+2], rest[2+n+2:]
+	r, rest, err := readMPI(rest)
+	if err != nil {
+		return err
+	}
+	s, rest, err := readMPI(rest)
+	if err != nil || len(rest) != 0 || len(r) > 32 || len(s) > 32 {
+		return errMalformed
+	}
+
+	var h hash.Hash
+	if hashFunc == crypto.SHA256 {
+		h = sha256.New()
+	} else {
+		h = sha512.New()
+	}
+	hashData(h)
+	digest := hashTrailer(h, hashedPart)
+	if !bytes.Equal(prefix, digest[:2]) {
+		return errors.New("openpgp: signature does not match data")
+	}
+	// R and S are MPIs of their Ed25519 encodings,
+	// without the leading zero bytes.
+	sig := make([]byte, ed25519.SignatureSize)
+	copy(sig[32-len(r):], r)
+	copy(sig[64-len(s):], s)
+	if !ed25519.Verify(e.PublicKey, digest, sig) {
+		return errors.New("openpgp: invalid signature")
+	}
+	return nil
+}
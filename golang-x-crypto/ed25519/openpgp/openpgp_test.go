@@ -0,0 +1,105 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openpgp
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"test-server/golang-x-crypto/ed25519"
+)
+
+// A key and a detached signature of gpgMessage written by GnuPG 2.2
+// (gpg --quick-gen-key ... ed25519; gpg --armor --detach-sign).
+const (
+	gpgMessage = "hello openpgp\n"
+	gpgKey     = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mDMEatIA8xYJKwYBBAHaRw8BAQdAH5tkN4LXiEcz6Y4yrvA0LO+GlFQlxyKK774h
+SU+kYMK0JENvc2lnbmVyIFRlc3QgPGNvc2lnbmVyQGV4YW1wbGUuY29tPoiQBBMW
+CAA4FiEEramT1t6FbOkhOYBvDzU3WIq8om8FAmrSAPMCGwMFCwkIBwIGFQoJCAsC
+BBYCAwECHgECF4AACgkQDzU3WIq8om/nQwD6A5yojyKVPkvlQY0txdEWCASdizMF
+3vdUtyZNZAG/q+EBAJ/VzIFTlK4g9Hvt7Na29CnvghPviCnLGEcZPGAZVSAP
+=XI51
+-----END PGP PUBLIC KEY BLOCK-----
+`
+	gpgSignature = `-----BEGIN PGP SIGNATURE-----
+
+iHUEABYKAB0WIQStqZPW3oVs6SE5gG8PNTdYiryibwUCatIA8wAKCRAPNTdYiryi
+b9olAQDAiQ6EGAeSx3ugvvaUb+jbbmYg3UK+lH/LcgUUnghKogEA2pwHoGEMIuvA
+wHsQmh7fnbFn0u1vi6BdeQchA1Fm8A8=
+=Mo4T
+-----END PGP SIGNATURE-----
+`
+)
+
+func TestGnuPG(t *testing.T) {
+	e, err := ReadEntity([]byte(gpgKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.UserID != "Cosigner Test <cosigner@example.com>" {
+		t.Errorf("user ID %q", e.UserID)
+	}
+	if got, want := e.KeyID(), uint64(0x0F3537588ABCA26F); got != want {
+		t.Errorf("key ID %X, want %X", got, want)
+	}
+	if err := e.VerifyDetached([]byte(gpgMessage), []byte(gpgSignature)); err != nil {
+		t.Errorf("GnuPG signature rejected: %v", err)
+	}
+	if err := e.VerifyDetached([]byte("other message"), []byte(gpgSignature)); err == nil {
+		t.Errorf("signature of other message accepted")
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(rand.Reader)
+	created := time.Unix(1700000000, 0)
+	e := NewEntity(priv, "Leader <leader@example.com>", created)
+	key, err := e.Armored()
+	if err != nil {
+		t.Fatal(err)
+	}
+	e2, err := ReadEntity(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !e2.PublicKey.Equal(e.PublicKey) || e2.UserID != e.UserID || !e2.Created.Equal(created) {
+		t.Errorf("entity changed in round trip: %+v", e2)
+	}
+	if !bytes.Equal(e2.Fingerprint(), e.Fingerprint()) {
+		t.Errorf("fingerprint changed in round trip")
+	}
+
+	message := []byte("release artifact")
+	sig := e.SignDetached(message, created.Add(time.Hour))
+	if err := e2.VerifyDetached(message, sig); err != nil {
+		t.Errorf("signature rejected: %v", err)
+	}
+	unarmored, err := dearmor(armorSignature, sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e2.VerifyDetached(message, unarmored); err != nil {
+		t.Errorf("binary signature rejected: %v", err)
+	}
+
+	// A self-signature by another key must not be accepted.
+	_, other, _ := ed25519.GenerateKey(rand.Reader)
+	forged := *e
+	forged.PrivateKey = other
+	forgedKey, _ := forged.Serialize()
+	if _, err := ReadEntity(forgedKey); err == nil {
+		t.Errorf("key with forged self-signature accepted")
+	}
+
+	corrupted := append([]byte{}, key...)
+	corrupted[len("-----BEGIN PGP PUBLIC KEY BLOCK-----\n\n")+10] ^= 1
+	if _, err := ReadEntity(corrupted); err == nil {
+		t.Errorf("corrupted armor accepted")
+	}
+}
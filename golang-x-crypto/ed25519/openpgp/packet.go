@@ -0,0 +1,228 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openpgp
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"strings"
+)
+
+// Packet tags, RFC 4880, Section 4.3.
+const (
+	tagSignature    = 2
+	tagPublicKey    = 6
+	tagUserID       = 13
+	tagPublicSubkey = 14
+)
+
+var errMalformed = errors.New("openpgp: malformed packet")
+
+// packet is an OpenPGP packet: its tag and body.
+type packet struct {
+	tag  byte
+	body []byte
+}
+
+// appendPacket appends a packet with a new-format header to b.
+func appendPacket(b []byte, tag byte, body []byte) []byte {
+	b = append(b, 0xc0|tag)
+	switch n := len(body); {
+	case n < 192:
+		b = append(b, byte(n))
+	case n < 8384:
+		n -= 192
+		b = append(b, byte(n>>8)+192, byte(n))
+	default:
+		b = append(b, 255)
+		b = binary.BigEndian.AppendUint32(b, uint32(n))
+	}
+	return append(b, body...)
+}
+
+// readPackets splits data into packets,
+// accepting both old- and new-format headers.
+// Partial body lengths and indeterminate lengths are not supported.
+func readPackets(data []byte) ([]packet, error) {
+	var packets []packet
+	for len(data) > 0 {
+		ctb := data[0]
+		if ctb&0x80 == 0 {
+			return nil, errMalformed
+		}
+		var tag byte
+		var n, hlen int
+		if ctb&0x40 != 0 { // new format
+			tag = ctb & 0x3f
+			if len(data) < 2 {
+				return nil, errMalformed
+			}
+			switch l := int(data[1]); {
+			case l < 192:
+				n, hlen = l, 2
+			case l < 224:
+				if len(data) < 3 {
+					return nil, errMalformed
+				}
+				n, hlen = (l-192)<<8+int(data[2])+192, 3
+			case l == 255:
+				if len(data) < 6 {
+					return nil, errMalformed
+				}
+				n, hlen = int(binary.BigEndian.Uint32(data[2:])), 6
+			default:
+				return nil, errors.New("openpgp: partial body lengths not supported")
+			}
+		} else { // old format
+			tag = (ctb >> 2) & 0x0f
+			switch ctb & 3 {
+			case 0:
+				if len(data) < 2 {
+					return nil, errMalformed
+				}
+				n, hlen = int(data[1]), 2
+			case 1:
+				if len(data) < 3 {
+					return nil, errMalformed
+				}
+				n, hlen = int(binary.BigEndian.Uint16(data[1:])), 3
+			case 2:
+				if len(data) < 5 {
+					return nil, errMalformed
+				}
+				n, hlen = int(binary.BigEndian.Uint32(data[1:])), 5
+			default:
+				return nil, errors.New("openpgp: indeterminate packet length not supported")
+			}
+		}
+		if n < 0 || n > len(data)-hlen {
+			return nil, errMalformed
+		}
+		packets = append(packets, packet{tag: tag, body: data[hlen : hlen+n]})
+		data = data[hlen+n:]
+	}
+	return packets, nil
+}
+
+// appendMPI appends b, a big-endian integer, as an MPI:
+// a 16-bit bit count followed by the integer without leading zero bytes.
+func appendMPI(b, x []byte) []byte {
+	for len(x) > 0 && x[0] == 0 {
+		x = x[1:]
+	}
+	bits := 0
+	if len(x) > 0 {
+		bits = 8 * len(x)
+		for top := x[0]; top&0x80 == 0; top <<= 1 {
+			bits--
+		}
+	}
+	b = binary.BigEndian.AppendUint16(b, uint16(bits))
+	return append(b, x...)
+}
+
+// readMPI reads an MPI from the start of b.
+func readMPI(b []byte) (x, rest []byte, err error) {
+	if len(b) < 2 {
+		return nil, nil, errMalformed
+	}
+	n := (int(binary.BigEndian.Uint16(b)) + 7) / 8
+	if n > len(b)-2 {
+		return nil, nil, errMalformed
+	}
+	return b[2 : 2+n], b[2+n:], nil
+}
+
+// Armor block types.
+const (
+	armorPublicKey = "PGP PUBLIC KEY BLOCK"
+	armorSignature = "PGP SIGNATURE"
+)
+
+// armor encodes data in the ASCII armor of RFC 4880, Section 6.2.
+func armor(blockType string, data []byte) []byte {
+	var b bytes.Buffer
+	b.WriteString("-----BEGIN " + blockType + "-----\n\n")
+	enc := base64.StdEncoding.EncodeToString(data)
+	for len(enc) > 64 {
+		b.WriteString(enc[:64] + "\n")
+		enc = enc[64:]
+	}
+	b.WriteString(enc + "\n")
+	crc := crc24(data)
+	b.WriteString("=" + base64.StdEncoding.EncodeToString([]byte{byte(crc >> 16), byte(crc >> 8), byte(crc)}) + "\n")
+	b.WriteString("-----END " + blockType + "-----\n")
+	return b.Bytes()
+}
+
+// dearmor decodes data if it is ASCII armored with the given block type,
+// checking the checksum if there is one.
+// Data that is not armored is returned as is.
+func dearmor(blockType string, data []byte) ([]byte, error) {
+	begin := []byte("-----BEGIN " + blockType + "-----")
+	start := bytes.Index(data, begin)
+	if start < 0 {
+		if len(data) > 0 && data[0]&0x80 != 0 {
+			return data, nil // binary
+		}
+		return nil, errors.New("openpgp: no " + strings.ToLower(blockType) + " found")
+	}
+	lines := strings.Split(string(data[start+len(begin):]), "\n")
+	// Skip armor headers, up to the first blank line.
+	i := 0
+	for ; i < len(lines) && strings.TrimSpace(lines[i]) != ""; i++ {
+		if i > 0 && !strings.Contains(lines[i], ":") {
+			return nil, errors.New("openpgp: malformed armor")
+		}
+	}
+	var body strings.Builder
+	var checksum string
+	end := false
+	for i++; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == "-----END "+blockType+"-----" {
+			end = true
+			break
+		}
+		if strings.HasPrefix(line, "=") {
+			checksum = line[1:]
+			continue
+		}
+		body.WriteString(line)
+	}
+	if !end {
+		return nil, errors.New("openpgp: malformed armor")
+	}
+	out, err := base64.StdEncoding.DecodeString(body.String())
+	if err != nil {
+		return nil, errors.New("openpgp: malformed armor")
+	}
+	if checksum != "" {
+		sum, err := base64.StdEncoding.DecodeString(checksum)
+		crc := crc24(out)
+		if err != nil || len(sum) != 3 ||
+			uint32(sum[0])<<16|uint32(sum[1])<<8|uint32(sum[2]) != crc {
+			return nil, errors.New("openpgp: armor checksum mismatch")
+		}
+	}
+	return out, nil
+}
+
+// crc24 computes the armor checksum of RFC 4880, Section 6.1.
+func crc24(data []byte) uint32 {
+	crc := uint32(0xb704ce)
+	for _, b := range data {
+		crc ^= uint32(b) << 16
+		for i := 0; i < 8; i++ {
+			crc <<= 1
+			if crc&0x1000000 != 0 {
+				crc ^= 0x1864cfb
+			}
+		}
+	}
+	return crc & 0xffffff
+}
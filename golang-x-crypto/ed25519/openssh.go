@@ -0,0 +1,109 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !tinygo
+
+package ed25519
+
+import (
+	"bytes"
+	stded25519 "crypto/ed25519"
+	"encoding/pem"
+	"errors"
+	"strconv"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// ParseOpenSSHPrivateKey parses an unencrypted private key
+// in the PEM-armored OpenSSH format written by ssh-keygen,
+// such as the contents of ~/.ssh/id_ed25519.
+// It returns an error if the key is not an Ed25519 key
+// or is protected by a passphrase.
+func ParseOpenSSHPrivateKey(pemBytes []byte) (PrivateKey, error) {
+	key, err := ssh.ParseRawPrivateKey(pemBytes)
+	if err != nil {
+		return nil, err
+	}
+	return openSSHPrivateKey(key)
+}
+
+// ParseOpenSSHPrivateKeyWithPassphrase is like ParseOpenSSHPrivateKey,
+// but decrypts a key protected by passphrase.
+func ParseOpenSSHPrivateKeyWithPassphrase(pemBytes, passphrase []byte) (PrivateKey, error) {
+	key, err := ssh.ParseRawPrivateKeyWithPassphrase(pemBytes, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return openSSHPrivateKey(key)
+}
+
+func openSSHPrivateKey(key interface{}) (PrivateKey, error) {
+	switch key := key.(type) {
+	case *stded25519.PrivateKey:
+		return PrivateKey(*key), nil
+	case stded25519.PrivateKey:
+		return PrivateKey(key), nil
+	}
+	return nil, errors.New("ed25519: OpenSSH key is not an Ed25519 key")
+}
+
+// MarshalOpenSSHPrivateKey encodes a private key
+// in the PEM-armored OpenSSH format, with the given comment.
+// If passphrase is not empty, the key is encrypted with it
+// as ssh-keygen does.
+func MarshalOpenSSHPrivateKey(privateKey PrivateKey, comment string, passphrase []byte) ([]byte, error) {
+	if l := len(privateKey); l != PrivateKeySize {
+		return nil, errors.New("ed25519: bad private key length: " + strconv.Itoa(l))
+	}
+	var block *pem.Block
+	var err error
+	if len(passphrase) == 0 {
+		block, err = ssh.MarshalPrivateKey(stded25519.PrivateKey(privateKey), comment)
+	} else {
+		block, err = ssh.MarshalPrivateKeyWithPassphrase(stded25519.PrivateKey(privateKey), comment, passphrase)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(block), nil
+}
+
+// ParseAuthorizedKey parses the first public key
+// in the OpenSSH authorized_keys format in in,
+// such as a line of ~/.ssh/authorized_keys or the contents of id_ed25519.pub,
+// and returns it with the comment that follows it.
+// Leading options and blank or comment lines are skipped.
+// It returns an error if the key is not an Ed25519 key.
+func ParseAuthorizedKey(in []byte) (publicKey PublicKey, comment string, err error) {
+	key, comment, _, _, err := ssh.ParseAuthorizedKey(in)
+	if err != nil {
+		return nil, "", err
+	}
+	if key.Type() != ssh.KeyAlgoED25519 {
+		return nil, "", errors.New("ed25519: authorized key is not an Ed25519 key: " + key.Type())
+	}
+	pub, ok := key.(ssh.CryptoPublicKey).CryptoPublicKey().(stded25519.PublicKey)
+	if !ok {
+		return nil, "", errors.New("ed25519: authorized key is not an Ed25519 key")
+	}
+	return PublicKey(pub), comment, nil
+}
+
+// MarshalAuthorizedKey encodes a public key as a line
+// in the OpenSSH authorized_keys format, ending in a newline,
+// followed by comment if it is not empty.
+func MarshalAuthorizedKey(publicKey PublicKey, comment string) ([]byte, error) {
+	key, err := ssh.NewPublicKey(stded25519.PublicKey(publicKey))
+	if err != nil {
+		return nil, err
+	}
+	line := ssh.MarshalAuthorizedKey(key)
+	if comment != "" {
+		line = append(bytes.TrimSuffix(line, []byte("\n")), ' ')
+		line = append(line, comment...)
+		line = append(line, '\n')
+	}
+	return line, nil
+}
@@ -0,0 +1,121 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !tinygo
+
+package ed25519
+
+import (
+	stded25519 "crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"strconv"
+)
+
+// PEM block types used by MarshalPrivateKeyPEM and MarshalPublicKeyPEM,
+// as written by OpenSSL.
+const (
+	pemPrivateKey = "PRIVATE KEY"
+	pemPublicKey  = "PUBLIC KEY"
+)
+
+// MarshalPKCS8PrivateKey converts a private key to PKCS #8, ASN.1 DER form,
+// as specified by RFC 8410.
+func MarshalPKCS8PrivateKey(privateKey PrivateKey) ([]byte, error) {
+	if l := len(privateKey); l != PrivateKeySize {
+		return nil, errors.New("ed25519: bad private key length: " + strconv.Itoa(l))
+	}
+	return x509.MarshalPKCS8PrivateKey(stded25519.PrivateKey(privateKey))
+}
+
+// ParsePKCS8PrivateKey parses an unencrypted private key in PKCS #8,
+// ASN.1 DER form. It returns an error if the key is not an Ed25519 key.
+func ParsePKCS8PrivateKey(der []byte) (PrivateKey, error) {
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+	priv, ok := key.(stded25519.PrivateKey)
+	if !ok {
+		return nil, errors.New("ed25519: PKCS #8 key is not an Ed25519 key")
+	}
+	return PrivateKey(priv), nil
+}
+
+// MarshalPKIXPublicKey converts a public key to PKIX, ASN.1 DER form,
+// the SubjectPublicKeyInfo structure of RFC 8410.
+func MarshalPKIXPublicKey(publicKey PublicKey) ([]byte, error) {
+	if l := len(publicKey); l != PublicKeySize {
+		return nil, errors.New("ed25519: bad public key length: " + strconv.Itoa(l))
+	}
+	return x509.MarshalPKIXPublicKey(stded25519.PublicKey(publicKey))
+}
+
+// ParsePKIXPublicKey parses a public key in PKIX, ASN.1 DER form.
+// It returns an error if the key is not an Ed25519 key.
+func ParsePKIXPublicKey(der []byte) (PublicKey, error) {
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, err
+	}
+	pub, ok := key.(stded25519.PublicKey)
+	if !ok {
+		return nil, errors.New("ed25519: PKIX key is not an Ed25519 key")
+	}
+	return PublicKey(pub), nil
+}
+
+// MarshalPrivateKeyPEM encodes a private key as a PEM "PRIVATE KEY" block
+// holding its PKCS #8 form, as read by OpenSSL.
+func MarshalPrivateKeyPEM(privateKey PrivateKey) ([]byte, error) {
+	der, err := MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: pemPrivateKey, Bytes: der}), nil
+}
+
+// ParsePrivateKeyPEM parses the first PEM block in data,
+// which must be a "PRIVATE KEY" block holding a PKCS #8 Ed25519 key.
+func ParsePrivateKeyPEM(data []byte) (PrivateKey, error) {
+	der, err := pemBlock(data, pemPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePKCS8PrivateKey(der)
+}
+
+// MarshalPublicKeyPEM encodes a public key as a PEM "PUBLIC KEY" block
+// holding its PKIX form, as read by OpenSSL.
+func MarshalPublicKeyPEM(publicKey PublicKey) ([]byte, error) {
+	der, err := MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: pemPublicKey, Bytes: der}), nil
+}
+
+// ParsePublicKeyPEM parses the first PEM block in data,
+// which must be a "PUBLIC KEY" block holding a PKIX Ed25519 key.
+func ParsePublicKeyPEM(data []byte) (PublicKey, error) {
+	der, err := pemBlock(data, pemPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePKIXPublicKey(der)
+}
+
+// pemBlock returns the contents of the first PEM block in data,
+// checking that it has the given type.
+func pemBlock(data []byte, blockType string) ([]byte, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("ed25519: no PEM data found")
+	}
+	if block.Type != blockType {
+		return nil, errors.New("ed25519: unexpected PEM block type " + strconv.Quote(block.Type))
+	}
+	return block.Bytes, nil
+}
@@ -0,0 +1,121 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"context"
+	"crypto"
+	stded25519 "crypto/ed25519"
+	"errors"
+	"io"
+)
+
+// A RemoteBackend signs with an Ed25519 private key that never leaves it,
+// such as a key in a PKCS#11 token, a cloud KMS or a signing service.
+// Implementations only need to produce pure Ed25519 signatures:
+// RemoteSigner checks them and the public key locally.
+type RemoteBackend interface {
+	// PublicKey returns the public key of the backend's private key.
+	PublicKey(ctx context.Context) (PublicKey, error)
+
+	// SignEd25519 returns the Ed25519 signature of message.
+	SignEd25519(ctx context.Context, message []byte) ([]byte, error)
+}
+
+// SignerBackend returns a RemoteBackend signing with s,
+// a crypto.Signer whose public key is an Ed25519 key of this package
+// or of crypto/ed25519. Most PKCS#11 and cloud KMS client libraries
+// expose their keys as such signers.
+func SignerBackend(s crypto.Signer) RemoteBackend {
+	return signerBackend{s}
+}
+
+type signerBackend struct{ s crypto.Signer }
+
+func (b signerBackend) PublicKey(context.Context) (PublicKey, error) {
+	switch pub := b.s.Public().(type) {
+	case PublicKey:
+		return pub, nil
+	case stded25519.PublicKey:
+		return PublicKey(pub), nil
+	default:
+		return nil, errors.New("ed25519: signer does not hold an Ed25519 key")
+	}
+}
+
+func (b signerBackend) SignEd25519(_ context.Context, message []byte) ([]byte, error) {
+	return b.s.Sign(nil, message, crypto.Hash(0))
+}
+
+// A RemoteSigner is a crypto.Signer delegating to a RemoteBackend,
+// so that servers can sign without ever loading the private key.
+// Verification and key parsing stay local: every signature the backend
+// returns is verified against the public key before it is handed out,
+// so a faulty backend cannot cause an invalid signature to be published.
+// A RemoteSigner is safe for concurrent use if its backend is.
+type RemoteSigner struct {
+	backend RemoteBackend
+	public  PublicKey
+}
+
+// NewRemoteSigner returns a RemoteSigner for backend,
+// whose public key it fetches once and validates with ValidatePublicKey.
+func NewRemoteSigner(ctx context.Context, backend RemoteBackend) (*RemoteSigner, error) {
+	pub, err := backend.PublicKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidatePublicKey(pub); err != nil {
+		return nil, err
+	}
+	return &RemoteSigner{backend: backend, public: append(PublicKey{}, pub...)}, nil
+}
+
+// Public returns the public key of the remote private key.
+func (s *RemoteSigner) Public() crypto.PublicKey {
+	return append(PublicKey{}, s.public...)
+}
+
+// Sign signs message with the remote key. rand is ignored,
+// and opts must select pure Ed25519: remote backends
+// support neither Ed25519ph nor Ed25519ctx.
+func (s *RemoteSigner) Sign(rand io.Reader, message []byte, opts crypto.SignerOpts) ([]byte, error) {
+	if opts.HashFunc() != crypto.Hash(0) {
+		return nil, errors.New("ed25519: remote signing supports only pure Ed25519")
+	}
+	if o, ok := opts.(*Options); ok && o.Context != "" {
+		return nil, errors.New("ed25519: remote signing supports only pure Ed25519")
+	}
+	return s.SignContext(context.Background(), message)
+}
+
+// SignContext signs message with the remote key,
+// passing ctx to the backend to bound the call.
+// It returns ErrInvalidSignature if the backend's signature does not verify.
+func (s *RemoteSigner) SignContext(ctx context.Context, message []byte) ([]byte, error) {
+	sig, err := s.backend.SignEd25519(ctx, message)
+	if err != nil {
+		return nil, err
+	}
+	if len(sig) != SignatureSize || !Verify(s.public, message, sig) {
+		return nil, ErrInvalidSignature
+	}
+	return sig, nil
+}
+
+// SignContext signs message with signer, which must hold an Ed25519 key,
+// such as a PrivateKey, SensitiveKey or RemoteSigner.
+// Signers with a SignContext method, like RemoteSigner, receive ctx;
+// the others are called through crypto.Signer for pure Ed25519.
+// Components that sign only plain messages take a crypto.Signer
+// and sign through SignContext, so they work with any of these keys.
+func SignContext(ctx context.Context, signer crypto.Signer, message []byte) ([]byte, error) {
+	if s, ok := signer.(interface {
+		SignContext(context.Context, []byte) ([]byte, error)
+	}); ok {
+		return s.SignContext(ctx, message)
+	}
+	return signer.Sign(nil, message, crypto.Hash(0))
+}
@@ -0,0 +1,286 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package ring implements linkable ring signatures with Ed25519 keys,
+// following the LSAG scheme of Liu, Wei and Wong (2004)
+// in the form used by CryptoNote.
+//
+// A ring signature proves that the message was signed by the holder
+// of one of the private keys of a ring of public keys,
+// without revealing which.
+// Each signature carries a key image, determined by the signer's key
+// alone, so two signatures by the same key are linked (see Linked)
+// even under different rings and messages,
+// which lets a verifier enforce one vote or one spend per member.
+package ring
+
+import (
+	"bytes"
+	cryptorand "crypto/rand"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/golang-x-crypto/ed25519/internal/edwards25519"
+)
+
+// SignatureSize returns the size, in bytes,
+// of a signature under a ring of n keys.
+func SignatureSize(n int) int {
+	return 64 + 32*n
+}
+
+var (
+	scZero     [32]byte
+	scMinusOne = [32]byte{0xec, 0xd3, 0xf5, 0x5c, 0x1a, 0x63, 0x12, 0x58, 0xd6, 0x9c, 0xf7, 0xa2, 0xde, 0xf9, 0xde, 0x14, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x10}
+)
+
+// Sign signs message with privateKey anonymously among ring,
+// which must contain privateKey's public key.
+// The signature layout is c_0 || key image || s_0 || ... || s_{n-1}.
+// Random values are drawn from rand, or from crypto/rand if rand is nil.
+func Sign(privateKey ed25519.PrivateKey, ring []ed25519.PublicKey, message []byte,
+	rand io.Reader) ([]byte, error) {
+
+	if rand == nil {
+		rand = cryptorand.Reader
+	}
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return nil, errors.New("ring: bad private key length")
+	}
+	points, hashes, err := decodeRing(ring)
+	if err != nil {
+		return nil, err
+	}
+	self := -1
+	for i, k := range ring {
+		if bytes.Equal(k, privateKey[32:]) {
+			self = i
+			break
+		}
+	}
+	if self < 0 {
+		return nil, errors.New("ring: signer's key not in ring")
+	}
+
+	digest := sha512.Sum512(privateKey[:32])
+	var a [32]byte
+	copy(a[:], digest[:32])
+	a[0] &= 248
+	a[31] &= 63
+	a[31] |= 64
+
+	// I = a * Hp(A_self)
+	var I edwards25519.ExtendedGroupElement
+	edwards25519.GeScalarMult(&I, &a, hashes[self])
+	var image [32]byte
+	I.ToBytes(&image)
+
+	n := len(ring)
+	c := make([][32]byte, n)
+	s := make([][32]byte, n)
+	alpha, err := randomScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+	var L, R edwards25519.ExtendedGroupElement
+	edwards25519.GeScalarMultBase(&L, alpha)
+	edwards25519.GeScalarMult(&R, alpha, hashes[self])
+	t := newTranscript(ring, image[:], message)
+	c[(self+1)%n] = t.challenge(&L, &R)
+
+	for j := 1; j < n; j++ {
+		i := (self + j) % n
+		si, err := randomScalar(rand)
+		if err != nil {
+			return nil, err
+		}
+		s[i] = *si
+		step(&L, &R, &s[i], &c[i], points[i], hashes[i], &I)
+		c[(i+1)%n] = t.challenge(&L, &R)
+	}
+
+	// s_self = alpha - c_self * a
+	var negC [32]byte
+	edwards25519.ScMulAdd(&negC, &scMinusOne, &c[self], &scZero)
+	edwards25519.ScMulAdd(&s[self], &negC, &a, alpha)
+
+	sig := make([]byte, 0, SignatureSize(n))
+	sig = append(sig, c[0][:]...)
+	sig = append(sig, image[:]...)
+	for i := range s {
+		sig = append(sig, s[i][:]...)
+	}
+	return sig, nil
+}
+
+// Verify reports whether sig is a valid ring signature of message
+// by one of the holders of the keys in ring.
+func Verify(ring []ed25519.PublicKey, message, sig []byte) bool {
+	n := len(ring)
+	if len(sig) != SignatureSize(n) {
+		return false
+	}
+	points, hashes, err := decodeRing(ring)
+	if err != nil {
+		return false
+	}
+	I, err := decodeImage(sig[32:64])
+	if err != nil {
+		return false
+	}
+	var c0, c, s [32]byte
+	copy(c0[:], sig[:32])
+	if !ed25519.IsCanonicalScalar(c0[:]) {
+		return false
+	}
+	c = c0
+	t := newTranscript(ring, sig[32:64], message)
+	var L, R edwards25519.ExtendedGroupElement
+	for i := 0; i < n; i++ {
+		copy(s[:], sig[64+32*i:])
+		if !ed25519.IsCanonicalScalar(s[:]) {
+			return false
+		}
+		step(&L, &R, &s, &c, points[i], hashes[i], I)
+		c = t.challenge(&L, &R)
+	}
+	return c == c0
+}
+
+// KeyImage returns the key image of a ring signature,
+// which is the same for all signatures by one key.
+// It does not verify the signature.
+func KeyImage(sig []byte) ([]byte, error) {
+	if len(sig) < SignatureSize(1) || (len(sig)-64)%32 != 0 {
+		return nil, errors.New("ring: malformed signature")
+	}
+	return append([]byte{}, sig[32:64]...), nil
+}
+
+// Linked reports whether two ring signatures were made with the same key.
+// Both signatures must have been verified first.
+func Linked(sig1, sig2 []byte) bool {
+	i1, err1 := KeyImage(sig1)
+	i2, err2 := KeyImage(sig2)
+	return err1 == nil && err2 == nil && bytes.Equal(i1, i2)
+}
+
+// step computes L = s*B + c*A and R = s*Hp(A) + c*I.
+func step(L, R *edwards25519.ExtendedGroupElement, s, c *[32]byte,
+	A, HA, I *edwards25519.ExtendedGroupElement) {
+
+	var sB, cA, sH, cI edwards25519.ExtendedGroupElement
+	edwards25519.GeScalarMultBase(&sB, s)
+	edwards25519.GeScalarMultVartime(&cA, c, A)
+	L.Add(&sB, &cA)
+	edwards25519.GeScalarMultVartime(&sH, s, HA)
+	edwards25519.GeScalarMultVartime(&cI, c, I)
+	R.Add(&sH, &cI)
+}
+
+// transcript computes challenges bound to the ring, key image and message.
+type transcript struct {
+	prefix []byte
+}
+
+func newTranscript(ring []ed25519.PublicKey, image, message []byte) *transcript {
+	t := &transcript{prefix: []byte("ed25519 lsag v1")}
+	t.prefix = binary.BigEndian.AppendUint32(t.prefix, uint32(len(ring)))
+	for _, k := range ring {
+		t.prefix = append(t.prefix, k...)
+	}
+	t.prefix = append(t.prefix, image...)
+	t.prefix = binary.BigEndian.AppendUint64(t.prefix, uint64(len(message)))
+	t.prefix = append(t.prefix, message...)
+	return t
+}
+
+func (t *transcript) challenge(L, R *edwards25519.ExtendedGroupElement) [32]byte {
+	var Lb, Rb [32]byte
+	L.ToBytes(&Lb)
+	R.ToBytes(&Rb)
+	h := sha512.New()
+	h.Write(t.prefix)
+	h.Write(Lb[:])
+	h.Write(Rb[:])
+	var wide [64]byte
+	h.Sum(wide[:0])
+	var c [32]byte
+	edwards25519.ScReduce(&c, &wide)
+	return c
+}
+
+// decodeRing decodes the ring's keys and hashes each to a point.
+func decodeRing(ring []ed25519.PublicKey) (points, hashes []*edwards25519.ExtendedGroupElement, err error) {
+	if len(ring) == 0 {
+		return nil, nil, errors.New("ring: empty ring")
+	}
+	for _, k := range ring {
+		if !ed25519.IsCanonicalPoint(k) || ed25519.IsSmallOrder(k) {
+			return nil, nil, errors.New("ring: invalid public key in ring")
+		}
+		var b [32]byte
+		copy(b[:], k)
+		A := new(edwards25519.ExtendedGroupElement)
+		A.FromBytes(&b)
+		points = append(points, A)
+		hashes = append(hashes, hashToPoint(k))
+	}
+	return points, hashes, nil
+}
+
+// hashToPoint hashes a public key to a point of the prime-order subgroup
+// whose discrete logarithm is unknown, by try-and-increment.
+func hashToPoint(publicKey []byte) *edwards25519.ExtendedGroupElement {
+	P := new(edwards25519.ExtendedGroupElement)
+	var digest [64]byte
+	var b [32]byte
+	for ctr := 0; ctr < 256; ctr++ {
+		h := sha512.New()
+		h.Write([]byte("ed25519 lsag hash to point"))
+		h.Write(publicKey)
+		h.Write([]byte{byte(ctr)})
+		h.Sum(digest[:0])
+		copy(b[:], digest[:32])
+		if !ed25519.IsCanonicalPoint(b[:]) || ed25519.IsSmallOrder(b[:]) {
+			continue
+		}
+		P.FromBytes(&b)
+		P.MulByCofactor(P)
+		return P
+	}
+	// Each attempt succeeds with probability about 1/2.
+	panic("ring: no curve point found")
+}
+
+// decodeImage decodes a key image, which must lie in the prime-order subgroup:
+// otherwise adding a small-order component would unlink two signatures
+// by the same key.
+func decodeImage(image []byte) (*edwards25519.ExtendedGroupElement, error) {
+	if !ed25519.IsCanonicalPoint(image) || ed25519.IsSmallOrder(image) {
+		return nil, errors.New("ring: invalid key image")
+	}
+	var b [32]byte
+	copy(b[:], image)
+	I := new(edwards25519.ExtendedGroupElement)
+	I.FromBytes(&b)
+	if I.IsTorsionFree() != 1 {
+		return nil, errors.New("ring: key image has a small-order component")
+	}
+	return I, nil
+}
+
+// randomScalar returns a uniformly random scalar read from rand.
+func randomScalar(rand io.Reader) (*[32]byte, error) {
+	var wide [64]byte
+	if _, err := io.ReadFull(rand, wide[:]); err != nil {
+		return nil, err
+	}
+	s := new([32]byte)
+	edwards25519.ScReduce(s, &wide)
+	return s, nil
+}
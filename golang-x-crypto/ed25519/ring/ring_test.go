@@ -0,0 +1,122 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ring
+
+import (
+	"bytes"
+	"testing"
+
+	"test-server/golang-x-crypto/ed25519"
+)
+
+func testRing(t *testing.T, n int) ([]ed25519.PublicKey, []ed25519.PrivateKey) {
+	pubs := make([]ed25519.PublicKey, n)
+	privs := make([]ed25519.PrivateKey, n)
+	for i := range pubs {
+		var err error
+		pubs[i], privs[i], err = ed25519.GenerateKey(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return pubs, privs
+}
+
+func TestSignVerify(t *testing.T) {
+	for _, n := range []int{1, 2, 5} {
+		ring, privs := testRing(t, n)
+		message := []byte("vote: yes")
+		for i := range privs {
+			sig, err := Sign(privs[i], ring, message, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(sig) != SignatureSize(n) {
+				t.Fatalf("signature size %d, want %d", len(sig), SignatureSize(n))
+			}
+			if !Verify(ring, message, sig) {
+				t.Fatalf("n=%d: valid signature by member %d rejected", n, i)
+			}
+			if Verify(ring, []byte("vote: no"), sig) {
+				t.Errorf("n=%d: signature accepted for other message", n)
+			}
+		}
+	}
+}
+
+func TestRejects(t *testing.T) {
+	ring, privs := testRing(t, 3)
+	message := []byte("message")
+	sig, _ := Sign(privs[1], ring, message, nil)
+
+	if Verify([]ed25519.PublicKey{ring[1], ring[0], ring[2]}, message, sig) {
+		t.Errorf("signature accepted for reordered ring")
+	}
+	if Verify(ring[:2], message, sig) {
+		t.Errorf("signature accepted for smaller ring")
+	}
+	for i := range sig {
+		bad := append([]byte{}, sig...)
+		bad[i] ^= 1
+		if Verify(ring, message, bad) {
+			t.Fatalf("signature with byte %d altered accepted", i)
+		}
+	}
+
+	outsider, _ := testRing(t, 1)
+	_, outsiderPriv := testRing(t, 1)
+	if _, err := Sign(outsiderPriv[0], ring, message, nil); err == nil {
+		t.Errorf("signed for a ring without the signer's key")
+	}
+	if _, err := Sign(privs[0], append(ring, make(ed25519.PublicKey, 32)), message, nil); err == nil {
+		t.Errorf("signed for a ring with a small-order key")
+	}
+	if Verify(append(ring, outsider[0]), message, sig) {
+		t.Errorf("signature accepted for larger ring")
+	}
+}
+
+func TestLinked(t *testing.T) {
+	ring, privs := testRing(t, 4)
+	other, _ := testRing(t, 3)
+	other = append(other, ring[2])
+
+	sig1, _ := Sign(privs[2], ring, []byte("first"), nil)
+	sig2, _ := Sign(privs[2], other, []byte("second"), nil)
+	sig3, _ := Sign(privs[3], ring, []byte("first"), nil)
+	if !Verify(other, []byte("second"), sig2) {
+		t.Fatalf("valid signature rejected")
+	}
+	if !Linked(sig1, sig2) {
+		t.Errorf("signatures by the same key not linked")
+	}
+	if Linked(sig1, sig3) {
+		t.Errorf("signatures by different keys linked")
+	}
+	image, err := KeyImage(sig1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(image, sig1[32:64]) {
+		t.Errorf("KeyImage = %x, want %x", image, sig1[32:64])
+	}
+	if Linked(sig1, sig1[:40]) {
+		t.Errorf("malformed signature linked")
+	}
+}
+
+func BenchmarkVerify(b *testing.B) {
+	ring := make([]ed25519.PublicKey, 16)
+	var priv ed25519.PrivateKey
+	for i := range ring {
+		ring[i], priv, _ = ed25519.GenerateKey(nil)
+	}
+	message := []byte("message")
+	sig, _ := Sign(priv, ring, message, nil)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Verify(ring, message, sig)
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package cosi implements collective Schnorr signatures
+// over the ristretto255 group, with the same API shape
+// as the Ed25519-based package ed25519/cosi.
+//
+// It suits protocols that already work in ristretto255
+// and want multisignatures free of the cofactor of edwards25519.
+// Keys and signatures are not compatible with Ed25519:
+// a private key is a canonical scalar x and its public key is x*G.
+// The collective signature is R || s || mask, where
+// s*G = R + k*A for the aggregate key A of the participating cosigners
+// and k = SHA-512(domain || R || A || message) reduced modulo the order,
+// and the mask has one bit set per cosigner that did not take part.
+//
+// As in package ed25519/cosi, public keys must be known
+// to have been generated honestly, since aggregate keys
+// are not protected against rogue-key attacks.
+package cosi
+
+import (
+	cryptorand "crypto/rand"
+	"errors"
+	"io"
+	"strconv"
+
+	"test-server/golang-x-crypto/ed25519/ristretto255"
+)
+
+const (
+	// PublicKeySize is the size, in bytes, of public keys.
+	PublicKeySize = ristretto255.ElementSize
+	// PrivateKeySize is the size, in bytes, of private keys.
+	PrivateKeySize = ristretto255.ScalarSize
+	// SignatureSize is the size, in bytes, of a collective signature
+	// without its participation mask.
+	SignatureSize = 64
+)
+
+// PublicKey is the encoding of a ristretto255 public key.
+type PublicKey []byte
+
+// PrivateKey is the encoding of a ristretto255 private scalar.
+type PrivateKey []byte
+
+// Public returns the public key corresponding to priv.
+func (priv PrivateKey) Public() (PublicKey, error) {
+	x, err := new(ristretto255.Scalar).SetCanonicalBytes(priv)
+	if err != nil {
+		return nil, err
+	}
+	return new(ristretto255.Element).ScalarBaseMult(x).Bytes(), nil
+}
+
+// GenerateKey generates a key pair using entropy from rand,
+// or crypto/rand if rand is nil.
+func GenerateKey(rand io.Reader) (PublicKey, PrivateKey, error) {
+	x, err := randomScalar(rand)
+	if err != nil {
+		return nil, nil, err
+	}
+	return new(ristretto255.Element).ScalarBaseMult(x).Bytes(), x.Bytes(), nil
+}
+
+func randomScalar(rand io.Reader) (*ristretto255.Scalar, error) {
+	if rand == nil {
+		rand = cryptorand.Reader
+	}
+	var wide [64]byte
+	if _, err := io.ReadFull(rand, wide[:]); err != nil {
+		return nil, err
+	}
+	return new(ristretto255.Scalar).SetUniformBytes(wide[:])
+}
+
+// MaskBit represents one bit of a Cosigners participation bitmask,
+// indicating whether a given cosigner is Enabled or Disabled.
+type MaskBit bool
+
+const (
+	Enabled  MaskBit = false
+	Disabled MaskBit = true
+)
+
+// Cosigners represents a group of collective signers
+// identified by an immutable, ordered list of their public keys,
+// together with a mutable participation bitmask
+// and the Policy used when verifying.
+//
+// A given Cosigners instance must be used only by one goroutine at a time.
+type Cosigners struct {
+	keys   []*ristretto255.Element
+	mask   []byte // disabled cosigners, byte-packed little-endian
+	aggr   *ristretto255.Element
+	policy Policy
+}
+
+// InvalidKeyError reports a public key rejected by NewCosigners.
+type InvalidKeyError struct {
+	Index  int    // position of the offending key in the list
+	Reason string // why the key was rejected
+}
+
+func (e *InvalidKeyError) Error() string {
+	return "cosi: invalid public key " + strconv.Itoa(e.Index) + ": " + e.Reason
+}
+
+// NewCosigners creates a Cosigners object for a list of public keys,
+// with every cosigner initially enabled unless mask says otherwise
+// (see SetMask). The default policy requires all cosigners to sign.
+// It returns an *InvalidKeyError for the first unusable public key.
+func NewCosigners(publicKeys []PublicKey, mask []byte) (*Cosigners, error) {
+	cos := &Cosigners{
+		keys:   make([]*ristretto255.Element, len(publicKeys)),
+		mask:   make([]byte, (len(publicKeys)+7)>>3),
+		aggr:   ristretto255.NewElement(),
+		policy: fullPolicy{},
+	}
+	for i, pk := range publicKeys {
+		P, err := new(ristretto255.Element).SetCanonicalBytes(pk)
+		if err != nil {
+			return nil, &InvalidKeyError{i, "not a canonical element encoding"}
+		}
+		cos.keys[i] = P
+		cos.aggr.Add(cos.aggr, P)
+	}
+	cos.SetMask(mask)
+	return cos, nil
+}
+
+// CountTotal returns the total number of cosigners.
+func (cos *Cosigners) CountTotal() int {
+	return len(cos.keys)
+}
+
+// CountEnabled returns the number of cosigners currently Enabled.
+func (cos *Cosigners) CountEnabled() int {
+	count := 0
+	for i := range cos.keys {
+		if cos.MaskBit(i) == Enabled {
+			count++
+		}
+	}
+	return count
+}
+
+// SetMask sets the participation bitmask from a byte-packed little-endian
+// bit-vector, in which a set bit disables the corresponding cosigner.
+// Missing bytes of a short (or nil) mask enable their cosigners.
+func (cos *Cosigners) SetMask(mask []byte) {
+	for i := range cos.keys {
+		byt := i >> 3
+		bit := byte(1) << uint(i&7)
+		value := Enabled
+		if byt < len(mask) && mask[byt]&bit != 0 {
+			value = Disabled
+		}
+		cos.SetMaskBit(i, value)
+	}
+}
+
+// Mask returns a copy of the current participation bitmask.
+func (cos *Cosigners) Mask() []byte {
+	return append([]byte{}, cos.mask...)
+}
+
+// MaskLen returns the length in bytes of a complete participation bitmask.
+func (cos *Cosigners) MaskLen() int {
+	return (len(cos.keys) + 7) >> 3
+}
+
+// SetMaskBit enables or disables the mask bit for an individual cosigner.
+func (cos *Cosigners) SetMaskBit(signer int, value MaskBit) {
+	byt := signer >> 3
+	bit := byte(1) << uint(signer&7)
+	if value == Disabled {
+		if cos.mask[byt]&bit == 0 {
+			cos.mask[byt] |= bit
+			cos.aggr.Subtract(cos.aggr, cos.keys[signer])
+		}
+	} else {
+		if cos.mask[byt]&bit != 0 {
+			cos.mask[byt] &^= bit
+			cos.aggr.Add(cos.aggr, cos.keys[signer])
+		}
+	}
+}
+
+// MaskBit returns whether the indicated cosigner is Enabled or Disabled.
+func (cos *Cosigners) MaskBit(signer int) MaskBit {
+	byt := signer >> 3
+	bit := byte(1) << uint(signer&7)
+	return cos.mask[byt]&bit != 0
+}
+
+// AggregatePublicKey returns the aggregate public key
+// of the cosigners currently enabled.
+func (cos *Cosigners) AggregatePublicKey() PublicKey {
+	return cos.aggr.Bytes()
+}
+
+var errMalformed = errors.New("cosi: malformed commit")
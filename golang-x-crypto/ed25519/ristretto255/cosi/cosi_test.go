@@ -0,0 +1,131 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cosi
+
+import (
+	"testing"
+)
+
+func testKeys(t *testing.T, n int) ([]PublicKey, []PrivateKey) {
+	pubs := make([]PublicKey, n)
+	privs := make([]PrivateKey, n)
+	for i := range pubs {
+		var err error
+		pubs[i], privs[i], err = GenerateKey(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return pubs, privs
+}
+
+// collectiveSign runs a signing round with the cosigners enabled in cos.
+func collectiveSign(t *testing.T, cos *Cosigners, privs []PrivateKey, message []byte) []byte {
+	n := len(privs)
+	commits := make([]Commitment, n)
+	secrets := make([]*Secret, n)
+	for i := range privs {
+		if cos.MaskBit(i) == Disabled {
+			continue
+		}
+		var err error
+		commits[i], secrets[i], err = Commit(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	aggR, err := cos.AggregateCommit(commits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aggK := cos.AggregatePublicKey()
+	parts := make([]SignaturePart, n)
+	for i := range privs {
+		if cos.MaskBit(i) == Disabled {
+			continue
+		}
+		parts[i], err = Cosign(privs[i], secrets[i], message, aggK, aggR)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !cos.VerifyPart(message, aggR, commits[i], i, parts[i]) {
+			t.Fatalf("valid signature part %d rejected", i)
+		}
+	}
+	sig := cos.AggregateSignature(aggR, parts)
+	if sig == nil {
+		t.Fatal("AggregateSignature failed")
+	}
+	return sig
+}
+
+func TestSignVerify(t *testing.T) {
+	pubs, privs := testKeys(t, 5)
+	message := []byte("test message")
+	cos, err := NewCosigners(pubs, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := collectiveSign(t, cos, privs, message)
+
+	if !Verify(pubs, nil, message, sig) {
+		t.Fatalf("valid collective signature rejected")
+	}
+	if Verify(pubs, nil, []byte("other message"), sig) {
+		t.Errorf("collective signature of other message accepted")
+	}
+}
+
+func TestThreshold(t *testing.T) {
+	pubs, privs := testKeys(t, 5)
+	message := []byte("test message")
+	cos, _ := NewCosigners(pubs, nil)
+	cos.SetMaskBit(1, Disabled)
+	cos.SetMaskBit(3, Disabled)
+	sig := collectiveSign(t, cos, privs, message)
+
+	if Verify(pubs, nil, message, sig) {
+		t.Errorf("partial signature accepted under the full policy")
+	}
+	if !Verify(pubs, ThresholdPolicy(3), message, sig) {
+		t.Errorf("3-of-5 signature rejected under threshold 3")
+	}
+	if Verify(pubs, ThresholdPolicy(4), message, sig) {
+		t.Errorf("3-of-5 signature accepted under threshold 4")
+	}
+
+	// Claiming a cosigner that did not take part breaks the signature.
+	forged := append([]byte{}, sig...)
+	forged[SignatureSize] &^= 1 << 1
+	if Verify(pubs, ThresholdPolicy(3), message, forged) {
+		t.Errorf("signature with altered mask accepted")
+	}
+}
+
+func TestInvalidKey(t *testing.T) {
+	pubs, _ := testKeys(t, 3)
+	pubs[2] = make(PublicKey, PublicKeySize)
+	pubs[2][0] = 1 // negative field element
+	_, err := NewCosigners(pubs, nil)
+	if e, ok := err.(*InvalidKeyError); !ok || e.Index != 2 {
+		t.Errorf("NewCosigners error = %v, want InvalidKeyError for key 2", err)
+	}
+}
+
+func TestSecretSingleUse(t *testing.T) {
+	pubs, privs := testKeys(t, 1)
+	cos, _ := NewCosigners(pubs, nil)
+	commit, secret, _ := Commit(nil)
+	aggR, _ := cos.AggregateCommit([]Commitment{commit})
+	if _, err := Cosign(privs[0], secret, nil, cos.AggregatePublicKey(), aggR); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("second Cosign with the same secret did not panic")
+		}
+	}()
+	Cosign(privs[0], secret, nil, cos.AggregatePublicKey(), aggR)
+}
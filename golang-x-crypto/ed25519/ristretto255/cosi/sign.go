@@ -0,0 +1,156 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cosi
+
+import (
+	"crypto/sha512"
+	"io"
+
+	"test-server/golang-x-crypto/ed25519/ristretto255"
+)
+
+const challengeDomain = "ristretto255-cosi-v1"
+
+// Commitment is a cosigner's one-time commit,
+// sent to the leader for combination via AggregateCommit.
+type Commitment []byte
+
+// SignaturePart is a cosigner's part of a collective signature,
+// sent to the leader for combination via AggregateSignature.
+type SignaturePart []byte
+
+// Secret is the one-time secret behind a Commitment.
+type Secret struct {
+	r     *ristretto255.Scalar
+	valid bool
+}
+
+// Commit is invoked by cosigners to produce a one-time commit
+// for the collective signing of a single message,
+// using randomness from rand, or crypto/rand if rand is nil.
+// The Secret must be passed to the corresponding call to Cosign.
+func Commit(rand io.Reader) (Commitment, *Secret, error) {
+	r, err := randomScalar(rand)
+	if err != nil {
+		return nil, nil, err
+	}
+	R := new(ristretto255.Element).ScalarBaseMult(r)
+	return R.Bytes(), &Secret{r: r, valid: true}, nil
+}
+
+// Cosign is used by a cosigner to produce its part of a collective signature
+// of message, given the aggregate public key and aggregate commit
+// that the leader obtained from AggregatePublicKey and AggregateCommit.
+// It returns an error if privateKey is malformed.
+//
+// Cosign invalidates the secret,
+// and panics if called with a previously-used secret.
+func Cosign(privateKey PrivateKey, secret *Secret, message []byte,
+	aggregateK PublicKey, aggregateR Commitment) (SignaturePart, error) {
+
+	if !secret.valid {
+		panic("cosi: you must use a cosigning Secret only once")
+	}
+	x, err := new(ristretto255.Scalar).SetCanonicalBytes(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	k := challenge(aggregateR, aggregateK, message)
+	s := new(ristretto255.Scalar).Multiply(k, x)
+	s.Add(s, secret.r)
+
+	secret.r = ristretto255.NewScalar()
+	secret.valid = false
+	return s.Bytes(), nil
+}
+
+// challenge returns SHA-512(domain || R || A || message)
+// reduced modulo the group order.
+func challenge(R, A, message []byte) *ristretto255.Scalar {
+	h := sha512.New()
+	h.Write([]byte(challengeDomain))
+	h.Write(R)
+	h.Write(A)
+	h.Write(message)
+	k, _ := new(ristretto255.Scalar).SetUniformBytes(h.Sum(nil))
+	return k
+}
+
+// AggregateCommit is invoked by the leader to combine the commits
+// of the cosigners enabled in the participation mask.
+// The commits slice must have one entry per cosigner;
+// entries of disabled cosigners are ignored.
+func (cos *Cosigners) AggregateCommit(commits []Commitment) (Commitment, error) {
+	if len(commits) != len(cos.keys) {
+		return nil, errMalformed
+	}
+	aggR := ristretto255.NewElement()
+	for i := range cos.keys {
+		if cos.MaskBit(i) == Disabled {
+			continue
+		}
+		R, err := new(ristretto255.Element).SetCanonicalBytes(commits[i])
+		if err != nil {
+			return nil, errMalformed
+		}
+		aggR.Add(aggR, R)
+	}
+	return aggR.Bytes(), nil
+}
+
+// AggregateSignature is invoked by the leader to combine the signature parts
+// of the cosigners enabled in the participation mask,
+// which must not have changed since AggregateCommit,
+// into a collective signature R || s || mask.
+// It returns nil if a part is malformed.
+func (cos *Cosigners) AggregateSignature(aggregateR Commitment, sigParts []SignaturePart) []byte {
+	if len(aggregateR) != ristretto255.ElementSize || len(sigParts) != len(cos.keys) {
+		return nil
+	}
+	aggS := ristretto255.NewScalar()
+	for i := range cos.keys {
+		if cos.MaskBit(i) == Disabled {
+			continue
+		}
+		s, err := new(ristretto255.Scalar).SetCanonicalBytes(sigParts[i])
+		if err != nil {
+			return nil
+		}
+		aggS.Add(aggS, s)
+	}
+	sig := make([]byte, 0, SignatureSize+cos.MaskLen())
+	sig = append(sig, aggregateR...)
+	sig = append(sig, aggS.Bytes()...)
+	return append(sig, cos.mask...)
+}
+
+// VerifyPart allows the leader to check the signature part
+// of the cosigner at index signer, given its commit,
+// before aggregating it.
+func (cos *Cosigners) VerifyPart(message []byte, aggregateR, commit Commitment,
+	signer int, part SignaturePart) bool {
+
+	R, err := new(ristretto255.Element).SetCanonicalBytes(commit)
+	if err != nil {
+		return false
+	}
+	s, err := new(ristretto255.Scalar).SetCanonicalBytes(part)
+	if err != nil {
+		return false
+	}
+	k := challenge(aggregateR, cos.AggregatePublicKey(), message)
+	return checkEquation(s, R, k, cos.keys[signer])
+}
+
+// checkEquation reports whether s*G == R + k*A.
+func checkEquation(s *ristretto255.Scalar, R *ristretto255.Element,
+	k *ristretto255.Scalar, A *ristretto255.Element) bool {
+
+	minusK := new(ristretto255.Scalar).Negate(k)
+	check := new(ristretto255.Element).VarTimeMultiScalarMult(
+		[]*ristretto255.Scalar{s, minusK},
+		[]*ristretto255.Element{ristretto255.NewGeneratorElement(), A})
+	return check.Equal(R) == 1
+}
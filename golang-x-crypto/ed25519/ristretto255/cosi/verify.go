@@ -0,0 +1,82 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cosi
+
+import (
+	"test-server/golang-x-crypto/ed25519/ristretto255"
+)
+
+// Policy decides which sets of cosigners are sufficient
+// for a collective signature to be acceptable to a verifier.
+// The Check method may inspect the participating cosigners
+// through cosigners.Mask and cosigners.MaskBit.
+type Policy interface {
+	Check(cosigners *Cosigners) bool
+}
+
+// The default, conservative policy
+// just requires all participants to have signed.
+type fullPolicy struct{}
+
+func (fullPolicy) Check(cosigners *Cosigners) bool {
+	return cosigners.CountEnabled() == cosigners.CountTotal()
+}
+
+type thresPolicy struct{ t int }
+
+func (p thresPolicy) Check(cosigners *Cosigners) bool {
+	return cosigners.CountEnabled() >= p.t
+}
+
+// ThresholdPolicy returns a Policy accepting collective signatures
+// by at least threshold cosigners.
+func ThresholdPolicy(threshold int) Policy {
+	return &thresPolicy{threshold}
+}
+
+// SetPolicy changes the Policy used by Verify.
+// A nil policy requires all cosigners to have signed.
+func (cos *Cosigners) SetPolicy(policy Policy) {
+	if policy == nil {
+		policy = fullPolicy{}
+	}
+	cos.policy = policy
+}
+
+// Verify reports whether sig is a valid collective signature of message
+// by a set of cosigners acceptable to the current Policy.
+// It sets the participation bitmask to the mask carried in sig,
+// so that the caller can inspect afterwards which cosigners signed.
+func (cos *Cosigners) Verify(message, sig []byte) bool {
+	if len(sig) != SignatureSize+cos.MaskLen() {
+		return false
+	}
+	cos.SetMask(sig[SignatureSize:])
+	if !cos.policy.Check(cos) {
+		return false
+	}
+	R, err := new(ristretto255.Element).SetCanonicalBytes(sig[:32])
+	if err != nil {
+		return false
+	}
+	s, err := new(ristretto255.Scalar).SetCanonicalBytes(sig[32:64])
+	if err != nil {
+		return false
+	}
+	k := challenge(sig[:32], cos.AggregatePublicKey(), message)
+	return checkEquation(s, R, k, cos.aggr)
+}
+
+// Verify checks a collective signature of message
+// by the cosigners with the given public keys under policy,
+// where a nil policy requires all of them to have signed.
+func Verify(publicKeys []PublicKey, policy Policy, message, sig []byte) bool {
+	cos, err := NewCosigners(publicKeys, nil)
+	if err != nil {
+		return false
+	}
+	cos.SetPolicy(policy)
+	return cos.Verify(message, sig)
+}
@@ -0,0 +1,344 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package ristretto255 implements the ristretto255 prime-order group
+// of RFC 9496, built on the edwards25519 arithmetic of package ed25519.
+//
+// Unlike edwards25519, whose order is eight times a prime,
+// ristretto255 has prime order and a canonical encoding of every element,
+// so protocols built on it need no cofactor clearing,
+// small-order checks or malleability workarounds.
+// Element and Scalar values are used with setter methods
+// in the style of math/big: the receiver is set to the result
+// and returned, and arguments may alias the receiver.
+//
+// Encodings of Element are not interchangeable with Ed25519 public keys.
+package ristretto255
+
+import (
+	"crypto/subtle"
+	"errors"
+	"math/big"
+
+	"test-server/golang-x-crypto/ed25519/internal/edwards25519"
+)
+
+// ElementSize and ScalarSize are the sizes, in bytes,
+// of canonical Element and Scalar encodings.
+const (
+	ElementSize = 32
+	ScalarSize  = 32
+)
+
+// Constants of RFC 9496, section 4.1.
+var (
+	feD            = feFromDecimal("37095705934669439343138083508754565189542113879843219016388785533085940283555")
+	feSqrtADMinus1 = feFromDecimal("25063068953384623474111414158702152701244531502492656460079210482610430750235")
+	feInvSqrtAMinD = feFromDecimal("54469307008909316920995813868745141605393597292927456921205312896311721017578")
+	feOneMinusDSq  = feFromDecimal("1159843021668779879193775521855586647937357759715417654439879720876111806838")
+	feDMinusOneSq  = feFromDecimal("40440834346308536858101042469323190826248399146238708352240133220865137265952")
+)
+
+func feFromDecimal(s string) edwards25519.FieldElement {
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		panic("ristretto255: bad constant")
+	}
+	var b [32]byte
+	n.FillBytes(b[:])
+	for i := 0; i < 16; i++ {
+		b[i], b[31-i] = b[31-i], b[i]
+	}
+	var fe edwards25519.FieldElement
+	edwards25519.FeFromBytes(&fe, &b)
+	return fe
+}
+
+var errInvalidEncoding = errors.New("ristretto255: invalid element encoding")
+
+// An Element is an element of the ristretto255 group.
+// The zero value is not valid; use NewElement or NewGeneratorElement.
+type Element struct {
+	r edwards25519.ExtendedGroupElement
+}
+
+// NewElement returns a new Element set to the identity.
+func NewElement() *Element {
+	e := new(Element)
+	e.r.Zero()
+	return e
+}
+
+// NewGeneratorElement returns a new Element set to the canonical generator.
+func NewGeneratorElement() *Element {
+	e := new(Element)
+	edwards25519.GeScalarMultBase(&e.r, &[32]byte{1})
+	return e
+}
+
+// Set sets e = x and returns e.
+func (e *Element) Set(x *Element) *Element {
+	*e = *x
+	return e
+}
+
+// Equal returns 1 if e and x are the same element, and 0 otherwise,
+// in constant time.
+func (e *Element) Equal(x *Element) int {
+	var f0, f1 edwards25519.FieldElement
+	edwards25519.FeMul(&f0, &e.r.X, &x.r.Y)
+	edwards25519.FeMul(&f1, &e.r.Y, &x.r.X)
+	out := feEqual(&f0, &f1)
+	edwards25519.FeMul(&f0, &e.r.Y, &x.r.Y)
+	edwards25519.FeMul(&f1, &e.r.X, &x.r.X)
+	out |= feEqual(&f0, &f1)
+	return int(out)
+}
+
+// Add sets e = p + q and returns e.
+func (e *Element) Add(p, q *Element) *Element {
+	e.r.Add(&p.r, &q.r)
+	return e
+}
+
+// Subtract sets e = p - q and returns e.
+func (e *Element) Subtract(p, q *Element) *Element {
+	e.r.Sub(&p.r, &q.r)
+	return e
+}
+
+// Negate sets e = -p and returns e.
+func (e *Element) Negate(p *Element) *Element {
+	e.r = p.r
+	edwards25519.FeNeg(&e.r.X, &e.r.X)
+	edwards25519.FeNeg(&e.r.T, &e.r.T)
+	return e
+}
+
+// ScalarMult sets e = s * p and returns e, in constant time.
+func (e *Element) ScalarMult(s *Scalar, p *Element) *Element {
+	edwards25519.GeScalarMult(&e.r, &s.s, &p.r)
+	return e
+}
+
+// ScalarBaseMult sets e = s * G, where G is the generator, and returns e,
+// in constant time.
+func (e *Element) ScalarBaseMult(s *Scalar) *Element {
+	edwards25519.GeScalarMultBase(&e.r, &s.s)
+	return e
+}
+
+// VarTimeMultiScalarMult sets e = sum(scalars[i] * elements[i]) and returns e.
+// It runs in variable time and must not be used with secret scalars.
+// It panics if the slices have different lengths.
+func (e *Element) VarTimeMultiScalarMult(scalars []*Scalar, elements []*Element) *Element {
+	if len(scalars) != len(elements) {
+		panic("ristretto255: mismatched number of scalars and elements")
+	}
+	a := make([]*[32]byte, len(scalars))
+	A := make([]*edwards25519.ExtendedGroupElement, len(elements))
+	for i := range scalars {
+		a[i] = &scalars[i].s
+		A[i] = &elements[i].r
+	}
+	edwards25519.GeMultiScalarMultVartime(&e.r, a, A)
+	return e
+}
+
+// Bytes returns the 32-byte canonical encoding of e.
+func (e *Element) Bytes() []byte {
+	p := &e.r
+	var u1, u2, t, invSqrt, den1, den2, zInv, ix, iy, enchanted edwards25519.FieldElement
+	edwards25519.FeAdd(&u1, &p.Z, &p.Y)
+	edwards25519.FeSub(&t, &p.Z, &p.Y)
+	edwards25519.FeMul(&u1, &u1, &t) // u1 = (Z+Y)(Z-Y)
+	edwards25519.FeMul(&u2, &p.X, &p.Y)
+
+	var one edwards25519.FieldElement
+	edwards25519.FeOne(&one)
+	edwards25519.FeSquare(&t, &u2)
+	edwards25519.FeMul(&t, &t, &u1)
+	edwards25519.FeSqrtRatio(&invSqrt, &one, &t)
+
+	edwards25519.FeMul(&den1, &invSqrt, &u1)
+	edwards25519.FeMul(&den2, &invSqrt, &u2)
+	edwards25519.FeMul(&zInv, &den1, &den2)
+	edwards25519.FeMul(&zInv, &zInv, &p.T)
+
+	edwards25519.FeMul(&ix, &p.X, &edwards25519.SqrtM1)
+	edwards25519.FeMul(&iy, &p.Y, &edwards25519.SqrtM1)
+	edwards25519.FeMul(&enchanted, &den1, &feInvSqrtAMinD)
+
+	edwards25519.FeMul(&t, &p.T, &zInv)
+	rotate := feIsNegative(&t)
+	var x, y, denInv edwards25519.FieldElement
+	x, y, denInv = p.X, p.Y, den2
+	edwards25519.FeCMove(&x, &iy, rotate)
+	edwards25519.FeCMove(&y, &ix, rotate)
+	edwards25519.FeCMove(&denInv, &enchanted, rotate)
+
+	edwards25519.FeMul(&t, &x, &zInv)
+	feCondNeg(&y, feIsNegative(&t))
+
+	var s edwards25519.FieldElement
+	edwards25519.FeSub(&s, &p.Z, &y)
+	edwards25519.FeMul(&s, &s, &denInv)
+	feAbs(&s)
+
+	out := make([]byte, ElementSize)
+	var b [32]byte
+	edwards25519.FeToBytes(&b, &s)
+	copy(out, b[:])
+	return out
+}
+
+// SetCanonicalBytes sets e to the decoding of the 32-byte encoding x
+// and returns e. If x is not a canonical encoding,
+// SetCanonicalBytes returns nil and an error, and leaves e unchanged.
+func (e *Element) SetCanonicalBytes(x []byte) (*Element, error) {
+	if len(x) != ElementSize {
+		return nil, errInvalidEncoding
+	}
+	var b, check [32]byte
+	copy(b[:], x)
+	var s edwards25519.FieldElement
+	edwards25519.FeFromBytes(&s, &b)
+	sCheck := s
+	edwards25519.FeToBytes(&check, &sCheck)
+	if subtle.ConstantTimeCompare(b[:], check[:]) != 1 || check[0]&1 == 1 {
+		return nil, errInvalidEncoding
+	}
+
+	var one, ss, u1, u2, u2Sq, v, t, invSqrt, denX, denY edwards25519.FieldElement
+	edwards25519.FeOne(&one)
+	edwards25519.FeSquare(&ss, &s)
+	edwards25519.FeSub(&u1, &one, &ss)
+	edwards25519.FeAdd(&u2, &one, &ss)
+	edwards25519.FeSquare(&u2Sq, &u2)
+
+	edwards25519.FeSquare(&v, &u1)
+	edwards25519.FeMul(&v, &v, &feD)
+	edwards25519.FeNeg(&v, &v)
+	edwards25519.FeSub(&v, &v, &u2Sq) // v = -(D * u1^2) - u2^2
+
+	edwards25519.FeMul(&t, &v, &u2Sq)
+	wasSquare := edwards25519.FeSqrtRatio(&invSqrt, &one, &t)
+
+	edwards25519.FeMul(&denX, &invSqrt, &u2)
+	edwards25519.FeMul(&denY, &invSqrt, &denX)
+	edwards25519.FeMul(&denY, &denY, &v)
+
+	var r edwards25519.ExtendedGroupElement
+	edwards25519.FeAdd(&r.X, &s, &s)
+	edwards25519.FeMul(&r.X, &r.X, &denX)
+	feAbs(&r.X)
+	edwards25519.FeMul(&r.Y, &u1, &denY)
+	edwards25519.FeOne(&r.Z)
+	edwards25519.FeMul(&r.T, &r.X, &r.Y)
+
+	if wasSquare == 0 || feIsNegative(&r.T) == 1 || feIsZero(&r.Y) == 1 {
+		return nil, errInvalidEncoding
+	}
+	e.r = r
+	return e, nil
+}
+
+// SetUniformBytes sets e to an element derived from the 64 bytes of x,
+// which should be the uniformly random output of a hash function,
+// and returns e. It is the one-way map of RFC 9496, section 4.3.4,
+// and returns an error only if len(x) is not 64.
+func (e *Element) SetUniformBytes(x []byte) (*Element, error) {
+	if len(x) != 64 {
+		return nil, errors.New("ristretto255: SetUniformBytes input is not 64 bytes long")
+	}
+	var b [32]byte
+	var t edwards25519.FieldElement
+	var p1, p2 edwards25519.ExtendedGroupElement
+	copy(b[:], x[:32])
+	edwards25519.FeFromBytes(&t, &b)
+	mapToPoint(&p1, &t)
+	copy(b[:], x[32:])
+	edwards25519.FeFromBytes(&t, &b)
+	mapToPoint(&p2, &t)
+	e.r.Add(&p1, &p2)
+	return e, nil
+}
+
+// mapToPoint is the MAP function of RFC 9496, section 4.3.4.
+func mapToPoint(p *edwards25519.ExtendedGroupElement, t *edwards25519.FieldElement) {
+	var one, minusOne, r, u, v, tmp, s, sPrime, c, n edwards25519.FieldElement
+	edwards25519.FeOne(&one)
+	edwards25519.FeNeg(&minusOne, &one)
+
+	edwards25519.FeSquare(&r, t)
+	edwards25519.FeMul(&r, &r, &edwards25519.SqrtM1)
+	edwards25519.FeAdd(&u, &r, &one)
+	edwards25519.FeMul(&u, &u, &feOneMinusDSq)
+
+	edwards25519.FeMul(&v, &r, &feD)
+	edwards25519.FeSub(&v, &minusOne, &v)
+	edwards25519.FeAdd(&tmp, &r, &feD)
+	edwards25519.FeMul(&v, &v, &tmp) // v = (-1 - rD)(r + D)
+
+	wasSquare := edwards25519.FeSqrtRatio(&s, &u, &v)
+	edwards25519.FeMul(&sPrime, &s, t)
+	feAbs(&sPrime)
+	edwards25519.FeNeg(&sPrime, &sPrime)
+	edwards25519.FeCMove(&s, &sPrime, 1-wasSquare)
+	c = r
+	edwards25519.FeCMove(&c, &minusOne, wasSquare)
+
+	edwards25519.FeSub(&n, &r, &one)
+	edwards25519.FeMul(&n, &n, &c)
+	edwards25519.FeMul(&n, &n, &feDMinusOneSq)
+	edwards25519.FeSub(&n, &n, &v)
+
+	var w0, w1, w2, w3, ss edwards25519.FieldElement
+	edwards25519.FeAdd(&w0, &s, &s)
+	edwards25519.FeMul(&w0, &w0, &v)
+	edwards25519.FeMul(&w1, &n, &feSqrtADMinus1)
+	edwards25519.FeSquare(&ss, &s)
+	edwards25519.FeSub(&w2, &one, &ss)
+	edwards25519.FeAdd(&w3, &one, &ss)
+
+	edwards25519.FeMul(&p.X, &w0, &w3)
+	edwards25519.FeMul(&p.Y, &w2, &w1)
+	edwards25519.FeMul(&p.Z, &w1, &w3)
+	edwards25519.FeMul(&p.T, &w0, &w2)
+}
+
+// feEqual returns 1 if f == g and 0 otherwise, in constant time.
+func feEqual(f, g *edwards25519.FieldElement) int32 {
+	var t edwards25519.FieldElement
+	edwards25519.FeSub(&t, f, g)
+	return feIsZero(&t)
+}
+
+// The edwards25519 predicates carry their argument in place,
+// leaving limbs too loose for a following FeAdd,
+// so these wrappers test a copy instead.
+
+// feIsZero returns 1 if f == 0 and 0 otherwise, in constant time.
+func feIsZero(f *edwards25519.FieldElement) int32 {
+	t := *f
+	return 1 - edwards25519.FeIsNonZero(&t)
+}
+
+// feIsNegative returns 1 if f is negative and 0 otherwise, in constant time.
+func feIsNegative(f *edwards25519.FieldElement) int32 {
+	t := *f
+	return int32(edwards25519.FeIsNegative(&t))
+}
+
+// feCondNeg sets f = -f if b is 1, in constant time.
+func feCondNeg(f *edwards25519.FieldElement, b int32) {
+	var neg edwards25519.FieldElement
+	edwards25519.FeNeg(&neg, f)
+	edwards25519.FeCMove(f, &neg, b)
+}
+
+// feAbs sets f to whichever of f and -f is non-negative, in constant time.
+func feAbs(f *edwards25519.FieldElement) {
+	feCondNeg(f, feIsNegative(f))
+}
@@ -0,0 +1,167 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ristretto255
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/hex"
+	"testing"
+)
+
+// Multiples 0 through 8 of the generator, from RFC 9496, appendix A.1.
+var multiples = []string{
+	"0000000000000000000000000000000000000000000000000000000000000000",
+	"e2f2ae0a6abc4e71a884a961c500515f58e30b6aa582dd8db6a65945e08d2d76",
+	"6a493210f7499cd17fecb510ae0cea23a110e8d5b901f8acadd3095c73a3b919",
+	"94741f5d5d52755ece4f23f044ee27d5d1ea1e2bd196b462166b16152a9d0259",
+	"da80862773358b466ffadfe0b3293ab3d9fd53c5ea6c955358f568322daf6a57",
+	"e882b131016b52c1d3337080187cf768423efccbb517bb495ab812c4160ff44e",
+	"f64746d3c92b13050ed8d80236a7f0007c3b3f962f5ba793d19a601ebb1df403",
+	"44f53520926ec81fbd5a387845beb7df85a96a24ece18738bdcfa6a7822a176d",
+	"903293d8f2287ebe10e2374dc1a53e0bc887e592699f02d077d5263cdd55601c",
+}
+
+func TestMultiples(t *testing.T) {
+	B := NewGeneratorElement()
+	e := NewElement()
+	for i, want := range multiples {
+		if got := hex.EncodeToString(e.Bytes()); got != want {
+			t.Errorf("%d*B = %s, want %s", i, got, want)
+		}
+		b, _ := hex.DecodeString(want)
+		d, err := new(Element).SetCanonicalBytes(b)
+		if err != nil {
+			t.Errorf("%d*B: decoding failed: %v", i, err)
+		} else if d.Equal(e) != 1 {
+			t.Errorf("%d*B: decoded element differs", i)
+		}
+
+		s := new(Scalar)
+		s.s[0] = byte(i)
+		if got := new(Element).ScalarBaseMult(s); got.Equal(e) != 1 {
+			t.Errorf("ScalarBaseMult(%d) != %d*B", i, i)
+		}
+		e.Add(e, B)
+	}
+}
+
+// Bad encodings, from RFC 9496, appendix A.2.
+var badEncodings = []string{
+	// Non-canonical field encodings.
+	"00ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+	"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f",
+	"f3ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f",
+	"edffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f",
+	// Negative field elements.
+	"0100000000000000000000000000000000000000000000000000000000000000",
+	"01ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f",
+	// Non-square x^2.
+	"26948d35ca62e643e26a83177332e6b6afeb9d08e4268b650f1f5bbd8d81d371",
+	// Negative xy value.
+	"3eb858e78f5a7254d8c9731174a94f76755fd3941c0ac93735c07ba14579630e",
+	// s = -1, which causes y = 0.
+	"ecffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f",
+}
+
+func TestBadEncodings(t *testing.T) {
+	for _, enc := range badEncodings {
+		b, _ := hex.DecodeString(enc)
+		if _, err := new(Element).SetCanonicalBytes(b); err == nil {
+			t.Errorf("bad encoding %s accepted", enc)
+		}
+	}
+}
+
+func TestSetUniformBytes(t *testing.T) {
+	// RFC 9496, appendix A.3.
+	vectors := []struct{ input, want string }{
+		{"Ristretto is traditionally a short shot of espresso coffee",
+			"3066f82a1a747d45120d1740f14358531a8f04bbffe6a819f86dfe50f44a0a46"},
+		{"made with the normal amount of ground coffee but extracted with",
+			"f26e5b6f7d362d2d2a94c5d0e7602cb4773c95a2e5c31a64f133189fa76ed61b"},
+		{"about half the amount of water in the same amount of time",
+			"006ccd2a9e6867e6a2c5cea83d3302cc9de128dd2a9a57dd8ee7b9d7ffe02826"},
+		{"by using a finer grind.",
+			"f8f0c87cf237953c5890aec3998169005dae3eca1fbb04548c635953c817f92a"},
+		{"This produces a concentrated shot of coffee per volume.",
+			"ae81e7dedf20a497e10c304a765c1767a42d6e06029758d2d7e8ef7cc4c41179"},
+		{"Just pulling a normal shot short will produce a weaker shot",
+			"e2705652ff9f5e44d3e841bf1c251cf7dddb77d140870d1ab2ed64f1a9ce8628"},
+		{"and is not a Ristretto as some believe.",
+			"80bd07262511cdde4863f8a7434cef696750681cb9510eea557088f76d9e5065"},
+	}
+	for _, v := range vectors {
+		h := sha512.Sum512([]byte(v.input))
+		e, err := new(Element).SetUniformBytes(h[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(e.Bytes()); got != v.want {
+			t.Errorf("SetUniformBytes(%q) = %s, want %s", v.input, got, v.want)
+		}
+	}
+}
+
+func TestGroupLaws(t *testing.T) {
+	var buf [64]byte
+	for i := range buf {
+		buf[i] = byte(i * 7)
+	}
+	x, _ := new(Scalar).SetUniformBytes(buf[:])
+	buf[0]++
+	y, _ := new(Scalar).SetUniformBytes(buf[:])
+	P := new(Element).ScalarBaseMult(x)
+	Q := new(Element).ScalarBaseMult(y)
+
+	// (x+y)B = xB + yB
+	sum := new(Element).ScalarBaseMult(new(Scalar).Add(x, y))
+	if sum.Equal(new(Element).Add(P, Q)) != 1 {
+		t.Errorf("(x+y)B != xB + yB")
+	}
+	// (x-y)B = xB - yB
+	diff := new(Element).ScalarBaseMult(new(Scalar).Subtract(x, y))
+	if diff.Equal(new(Element).Subtract(P, Q)) != 1 {
+		t.Errorf("(x-y)B != xB - yB")
+	}
+	// y(xB) = (xy)B
+	if new(Element).ScalarMult(y, P).Equal(new(Element).ScalarBaseMult(new(Scalar).Multiply(x, y))) != 1 {
+		t.Errorf("y(xB) != (xy)B")
+	}
+	// x^-1(xB) = B
+	if new(Element).ScalarMult(new(Scalar).Invert(x), P).Equal(NewGeneratorElement()) != 1 {
+		t.Errorf("x^-1(xB) != B")
+	}
+	// -P + P = 0
+	if new(Element).Add(new(Element).Negate(P), P).Equal(NewElement()) != 1 {
+		t.Errorf("-P + P != 0")
+	}
+	multi := new(Element).VarTimeMultiScalarMult([]*Scalar{x, y}, []*Element{Q, P})
+	if multi.Equal(new(Element).ScalarMult(new(Scalar).Add(x, x), Q)) != 1 {
+		t.Errorf("VarTimeMultiScalarMult mismatch")
+	}
+
+	// Encodings round-trip.
+	enc := P.Bytes()
+	dec, err := new(Element).SetCanonicalBytes(enc)
+	if err != nil || !bytes.Equal(dec.Bytes(), enc) {
+		t.Errorf("encoding round trip failed: %v", err)
+	}
+}
+
+func TestScalarEncoding(t *testing.T) {
+	order := []byte{0xed, 0xd3, 0xf5, 0x5c, 0x1a, 0x63, 0x12, 0x58, 0xd6, 0x9c, 0xf7, 0xa2, 0xde, 0xf9, 0xde, 0x14, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x10}
+	if _, err := new(Scalar).SetCanonicalBytes(order); err == nil {
+		t.Errorf("group order accepted as a canonical scalar")
+	}
+	order[0]--
+	s, err := new(Scalar).SetCanonicalBytes(order)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(s.Bytes(), order) {
+		t.Errorf("scalar round trip failed")
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ristretto255
+
+import (
+	"crypto/subtle"
+	"errors"
+
+	"test-server/golang-x-crypto/ed25519/internal/edwards25519"
+)
+
+// A Scalar is an integer modulo the group order
+// l = 2^252 + 27742317777372353535851937790883648493.
+// The zero value is a valid zero scalar.
+type Scalar struct {
+	s [32]byte // canonical little-endian encoding
+}
+
+var (
+	scZero     [32]byte
+	scOne      = [32]byte{1}
+	scMinusOne = [32]byte{0xec, 0xd3, 0xf5, 0x5c, 0x1a, 0x63, 0x12, 0x58, 0xd6, 0x9c, 0xf7, 0xa2, 0xde, 0xf9, 0xde, 0x14, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x10}
+	// scOrderMinusTwo is l - 2, the exponent of inversion.
+	scOrderMinusTwo = [32]byte{0xeb, 0xd3, 0xf5, 0x5c, 0x1a, 0x63, 0x12, 0x58, 0xd6, 0x9c, 0xf7, 0xa2, 0xde, 0xf9, 0xde, 0x14, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x10}
+)
+
+// NewScalar returns a new zero Scalar.
+func NewScalar() *Scalar {
+	return new(Scalar)
+}
+
+// Set sets s = x and returns s.
+func (s *Scalar) Set(x *Scalar) *Scalar {
+	*s = *x
+	return s
+}
+
+// Add sets s = x + y and returns s.
+func (s *Scalar) Add(x, y *Scalar) *Scalar {
+	edwards25519.ScMulAdd(&s.s, &x.s, &scOne, &y.s)
+	return s
+}
+
+// Subtract sets s = x - y and returns s.
+func (s *Scalar) Subtract(x, y *Scalar) *Scalar {
+	edwards25519.ScMulAdd(&s.s, &y.s, &scMinusOne, &x.s)
+	return s
+}
+
+// Negate sets s = -x and returns s.
+func (s *Scalar) Negate(x *Scalar) *Scalar {
+	edwards25519.ScMulAdd(&s.s, &x.s, &scMinusOne, &scZero)
+	return s
+}
+
+// Multiply sets s = x * y and returns s.
+func (s *Scalar) Multiply(x, y *Scalar) *Scalar {
+	edwards25519.ScMulAdd(&s.s, &x.s, &y.s, &scZero)
+	return s
+}
+
+// Invert sets s to the inverse of x, or to zero if x is zero,
+// and returns s. It runs in constant time.
+func (s *Scalar) Invert(x *Scalar) *Scalar {
+	r := scOne
+	for i := 255; i >= 0; i-- {
+		edwards25519.ScMulAdd(&r, &r, &r, &scZero)
+		if scOrderMinusTwo[i/8]>>(i%8)&1 == 1 {
+			// The exponent is public, so branching on it is safe.
+			edwards25519.ScMulAdd(&r, &r, &x.s, &scZero)
+		}
+	}
+	s.s = r
+	return s
+}
+
+// Equal returns 1 if s and x are equal, and 0 otherwise, in constant time.
+func (s *Scalar) Equal(x *Scalar) int {
+	return subtle.ConstantTimeCompare(s.s[:], x.s[:])
+}
+
+// Bytes returns the 32-byte canonical little-endian encoding of s.
+func (s *Scalar) Bytes() []byte {
+	return append([]byte{}, s.s[:]...)
+}
+
+// SetCanonicalBytes sets s to the little-endian integer x, which must be
+// 32 bytes long and less than the group order, and returns s.
+// Otherwise it returns nil and an error, and leaves s unchanged.
+func (s *Scalar) SetCanonicalBytes(x []byte) (*Scalar, error) {
+	if len(x) != ScalarSize {
+		return nil, errors.New("ristretto255: invalid scalar length")
+	}
+	var b [32]byte
+	copy(b[:], x)
+	if !edwards25519.ScMinimal(&b) {
+		return nil, errors.New("ristretto255: non-canonical scalar encoding")
+	}
+	s.s = b
+	return s, nil
+}
+
+// SetUniformBytes sets s to the little-endian integer x, which must be
+// 64 bytes long, reduced modulo the group order, and returns s.
+// x should be the uniformly random output of a hash function or RNG.
+func (s *Scalar) SetUniformBytes(x []byte) (*Scalar, error) {
+	if len(x) != 64 {
+		return nil, errors.New("ristretto255: SetUniformBytes input is not 64 bytes long")
+	}
+	var wide [64]byte
+	copy(wide[:], x)
+	edwards25519.ScReduce(&s.s, &wide)
+	return s, nil
+}
@@ -0,0 +1,135 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package schnorr implements Schnorr signatures over edwards25519
+// with a configurable challenge hash and domain tag.
+//
+// A Scheme with the zero value signs exactly as pure Ed25519,
+// and one whose Domain is the RFC 8032 dom2 prefix signs as Ed25519ctx;
+// other settings describe Schnorr formats that share the curve and key
+// encoding but not the hash, such as those hashing challenges with SHA3
+// or BLAKE2b. Challenge exposes the challenge computation on its own
+// for multi-party protocols, which assemble R and s themselves.
+//
+// Signatures are the 32-byte encoding of R followed by the scalar s,
+// with s*B = R + k*A and k = H(Domain || R || A || M) reduced modulo
+// the group order. Keys are ordinary Ed25519 keys.
+package schnorr
+
+import (
+	"crypto/sha512"
+	"crypto/subtle"
+	"hash"
+
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/golang-x-crypto/ed25519/internal/edwards25519"
+)
+
+// SignatureSize is the size, in bytes, of signatures.
+const SignatureSize = 64
+
+// A Scheme describes a Schnorr signature format over edwards25519.
+type Scheme struct {
+	// Hash returns the hash used for challenges, SHA-512 if nil.
+	// Digests shorter than 64 bytes are reduced modulo the group order
+	// as little-endian integers, which biases them slightly;
+	// only the first 64 bytes of longer ones are used.
+	Hash func() hash.Hash
+
+	// Domain is hashed before R in challenges and before the key prefix
+	// in nonces. It should be fixed for a scheme, or self-delimiting,
+	// so that distinct schemes never hash the same input.
+	Domain []byte
+}
+
+// Ed25519 is the Scheme of pure Ed25519.
+var Ed25519 = &Scheme{}
+
+// Challenge returns the challenge scalar k for nonce point R,
+// public key A and message.
+func (s *Scheme) Challenge(R, publicKey, message []byte) [32]byte {
+	newHash := s.Hash
+	if newHash == nil {
+		newHash = sha512.New
+	}
+	h := newHash()
+	h.Write(s.Domain)
+	h.Write(R)
+	h.Write(publicKey)
+	h.Write(message)
+	var wide [64]byte
+	copy(wide[:], h.Sum(nil))
+	var k [32]byte
+	edwards25519.ScReduce(&k, &wide)
+	return k
+}
+
+// Sign signs message with privateKey.
+// The nonce is derived deterministically from the key and message with
+// SHA-512 as in Ed25519, whatever the challenge hash.
+// It will panic if len(privateKey) is not ed25519.PrivateKeySize.
+func (s *Scheme) Sign(privateKey ed25519.PrivateKey, message []byte) []byte {
+	if len(privateKey) != ed25519.PrivateKeySize {
+		panic("schnorr: bad private key length")
+	}
+	digest := sha512.Sum512(privateKey[:32])
+	var a [32]byte
+	copy(a[:], digest[:32])
+	a[0] &= 248
+	a[31] &= 63
+	a[31] |= 64
+
+	h := sha512.New()
+	h.Write(s.Domain)
+	h.Write(digest[32:])
+	h.Write(message)
+	var wide [64]byte
+	h.Sum(wide[:0])
+	var r [32]byte
+	edwards25519.ScReduce(&r, &wide)
+
+	var R edwards25519.ExtendedGroupElement
+	edwards25519.GeScalarMultBase(&R, &r)
+	var Rb [32]byte
+	R.ToBytes(&Rb)
+
+	k := s.Challenge(Rb[:], privateKey[32:], message)
+	var S [32]byte
+	edwards25519.ScMulAdd(&S, &k, &a, &r)
+
+	sig := make([]byte, SignatureSize)
+	copy(sig, Rb[:])
+	copy(sig[32:], S[:])
+	return sig
+}
+
+// Verify reports whether sig is a valid signature of message by publicKey.
+// It rejects non-canonical s, but accepts public keys
+// as ed25519.Verify does.
+func (s *Scheme) Verify(publicKey ed25519.PublicKey, message, sig []byte) bool {
+	if len(publicKey) != ed25519.PublicKeySize || len(sig) != SignatureSize {
+		return false
+	}
+	if !ed25519.IsCanonicalScalar(sig[32:]) {
+		return false
+	}
+	var A edwards25519.ExtendedGroupElement
+	var Ab [32]byte
+	copy(Ab[:], publicKey)
+	if !A.FromBytes(&Ab) {
+		return false
+	}
+	edwards25519.FeNeg(&A.X, &A.X)
+	edwards25519.FeNeg(&A.T, &A.T)
+
+	k := s.Challenge(sig[:32], publicKey, message)
+	var S [32]byte
+	copy(S[:], sig[32:])
+	var R edwards25519.ProjectiveGroupElement
+	edwards25519.GeDoubleScalarMultVartime(&R, &k, &A, &S)
+
+	var checkR [32]byte
+	R.ToBytes(&checkR)
+	return subtle.ConstantTimeCompare(sig[:32], checkR[:]) == 1
+}
@@ -0,0 +1,80 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package schnorr
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+
+	"golang.org/x/crypto/sha3"
+
+	"test-server/golang-x-crypto/ed25519"
+)
+
+func TestEd25519Compatible(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	message := []byte("message")
+
+	sig := Ed25519.Sign(priv, message)
+	if want := ed25519.Sign(priv, message); !bytes.Equal(sig, want) {
+		t.Errorf("Sign = %x, want %x", sig, want)
+	}
+	if !ed25519.Verify(pub, message, sig) {
+		t.Errorf("signature rejected by ed25519.Verify")
+	}
+
+	const context = "test context"
+	ctx := &Scheme{Domain: []byte("SigEd25519 no Ed25519 collisions\x00" + string([]byte{byte(len(context))}) + context)}
+	sig = ctx.Sign(priv, message)
+	want, _ := priv.Sign(nil, message, &ed25519.Options{Context: context})
+	if !bytes.Equal(sig, want) {
+		t.Errorf("Ed25519ctx Sign = %x, want %x", sig, want)
+	}
+	if !ctx.Verify(pub, message, want) {
+		t.Errorf("Ed25519ctx signature rejected")
+	}
+}
+
+func TestSchemes(t *testing.T) {
+	schemes := map[string]*Scheme{
+		"ed25519":  Ed25519,
+		"sha3-512": {Hash: sha3.New512, Domain: []byte("test sha3")},
+		"sha256":   {Hash: sha256.New, Domain: []byte("test sha256")},
+	}
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	message := []byte("message")
+	for name, s := range schemes {
+		sig := s.Sign(priv, message)
+		if !s.Verify(pub, message, sig) {
+			t.Errorf("%s: valid signature rejected", name)
+		}
+		if s.Verify(pub, []byte("other"), sig) {
+			t.Errorf("%s: signature accepted for other message", name)
+		}
+		for other, o := range schemes {
+			if other != name && o.Verify(pub, message, sig) {
+				t.Errorf("%s signature accepted by %s", name, other)
+			}
+		}
+	}
+}
+
+func TestRejectsNonCanonicalS(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	message := []byte("message")
+	sig := Ed25519.Sign(priv, message)
+	// Add the group order to s.
+	order := []byte{0xed, 0xd3, 0xf5, 0x5c, 0x1a, 0x63, 0x12, 0x58, 0xd6, 0x9c, 0xf7, 0xa2, 0xde, 0xf9, 0xde, 0x14, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x10}
+	var carry int
+	for i := range order {
+		v := int(sig[32+i]) + int(order[i]) + carry
+		sig[32+i] = byte(v)
+		carry = v >> 8
+	}
+	if Ed25519.Verify(pub, message, sig) {
+		t.Errorf("signature with non-canonical s accepted")
+	}
+}
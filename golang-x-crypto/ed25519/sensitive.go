@@ -0,0 +1,147 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"crypto"
+	cryptorand "crypto/rand"
+	"crypto/sha512"
+	"errors"
+	"io"
+	"strconv"
+	"sync"
+
+	"test-server/golang-x-crypto/ed25519/internal/edwards25519"
+)
+
+// ErrKeyClosed is returned by SensitiveKey.Sign after Close.
+var ErrKeyClosed = errors.New("ed25519: use of closed key")
+
+// Layout of the secret buffer of a SensitiveKey.
+const (
+	sensitiveSeed   = 0  // the seed
+	sensitiveScalar = 32 // the clamped secret scalar
+	sensitivePrefix = 64 // the nonce prefix
+	sensitiveSize   = 96
+)
+
+// A SensitiveKey is an Ed25519 private key whose secrets,
+// the seed, secret scalar and nonce prefix, live in a buffer
+// kept out of swap where the platform allows it,
+// and wiped by Close. Long-running servers can use it
+// to bound how long a key stays in memory.
+//
+// Signing works on that buffer directly and wipes
+// its own temporary copies of the nonce,
+// so no other copy of the secrets outlives a call to Sign.
+// A SensitiveKey implements crypto.Signer, with the options
+// of PrivateKey.Sign, and is safe for concurrent use.
+type SensitiveKey struct {
+	mu     sync.RWMutex
+	buf    []byte // secrets, nil once closed
+	locked bool
+	public PublicKey
+}
+
+// NewSensitiveKey returns a SensitiveKey holding a copy of privateKey.
+// The caller should wipe privateKey afterwards, with PrivateKey.Zeroize.
+// It will panic if len(privateKey) is not PrivateKeySize.
+func NewSensitiveKey(privateKey PrivateKey) *SensitiveKey {
+	if l := len(privateKey); l != PrivateKeySize {
+		panic("ed25519: bad private key length: " + strconv.Itoa(l))
+	}
+	k := newSensitiveKey()
+	copy(k.buf[sensitiveSeed:], privateKey[:SeedSize])
+	k.expand()
+	return k
+}
+
+// GenerateSensitiveKey generates a SensitiveKey using entropy from rand,
+// or crypto/rand if rand is nil. The seed is read directly
+// into the key's buffer, so it is never held anywhere else.
+func GenerateSensitiveKey(rand io.Reader) (*SensitiveKey, error) {
+	if rand == nil {
+		rand = cryptorand.Reader
+	}
+	k := newSensitiveKey()
+	if _, err := io.ReadFull(rand, k.buf[sensitiveSeed:sensitiveSeed+SeedSize]); err != nil {
+		k.Close()
+		return nil, err
+	}
+	k.expand()
+	return k, nil
+}
+
+func newSensitiveKey() *SensitiveKey {
+	buf, locked := allocSecret(sensitiveSize)
+	return &SensitiveKey{buf: buf, locked: locked}
+}
+
+// expand derives the secret scalar, nonce prefix and public key from the seed.
+func (k *SensitiveKey) expand() {
+	h := sha512.New()
+	h.Write(k.buf[sensitiveSeed : sensitiveSeed+SeedSize])
+	digest := h.Sum(k.buf[sensitiveScalar:sensitiveScalar])
+	digest[0] &= 248
+	digest[31] &= 63
+	digest[31] |= 64
+
+	var A edwards25519.ExtendedGroupElement
+	var publicKey [32]byte
+	edwards25519.GeScalarMultBase(&A, (*[32]byte)(digest[:32]))
+	A.ToBytes(&publicKey)
+	k.public = publicKey[:]
+}
+
+// Public returns the PublicKey of k. It remains available after Close.
+func (k *SensitiveKey) Public() crypto.PublicKey {
+	return append(PublicKey{}, k.public...)
+}
+
+// Locked reports whether the secrets of k are held in memory
+// locked against swapping. Locking is best effort:
+// it is unsupported on some platforms, and others limit
+// how much memory a process may lock.
+func (k *SensitiveKey) Locked() bool {
+	return k.locked
+}
+
+// Sign signs message with k, as PrivateKey.Sign does.
+// It returns ErrKeyClosed if k has been closed.
+func (k *SensitiveKey) Sign(rand io.Reader, message []byte, opts crypto.SignerOpts) ([]byte, error) {
+	domPrefix, context, noise, err := signerOpts(rand, message, opts)
+	if err != nil {
+		return nil, err
+	}
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	if k.buf == nil {
+		return nil, ErrKeyClosed
+	}
+	scalar := (*[32]byte)(k.buf[sensitiveScalar : sensitiveScalar+32])
+	prefix := k.buf[sensitivePrefix : sensitivePrefix+32]
+	return signExpanded(scalar, prefix, k.public, message, domPrefix, context, noise), nil
+}
+
+// Close wipes the secrets of k and releases their memory.
+// Signing with k afterwards fails with ErrKeyClosed.
+// Close waits for signatures in progress, and always returns nil.
+func (k *SensitiveKey) Close() error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.buf != nil {
+		clear(k.buf)
+		freeSecret(k.buf, k.locked)
+		k.buf = nil
+	}
+	return nil
+}
+
+// Zeroize overwrites priv with zeros,
+// for callers done with a key they have handed to NewSensitiveKey.
+// Other copies of the key, such as the seed it was made from, are unaffected.
+func (priv PrivateKey) Zeroize() {
+	clear(priv)
+}
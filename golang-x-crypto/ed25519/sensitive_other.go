@@ -0,0 +1,17 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !unix
+
+package ed25519
+
+// allocSecret returns n bytes of memory for secrets.
+// Memory locking is not supported on this platform.
+func allocSecret(n int) ([]byte, bool) {
+	return make([]byte, n), false
+}
+
+// freeSecret releases memory returned by allocSecret,
+// which the caller has wiped.
+func freeSecret(b []byte, locked bool) {}
@@ -0,0 +1,29 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build unix
+
+package ed25519
+
+import "golang.org/x/sys/unix"
+
+// allocSecret returns n bytes of memory for secrets, mapped
+// outside the Go heap and locked against swapping if possible,
+// and reports whether it is locked.
+func allocSecret(n int) ([]byte, bool) {
+	b, err := unix.Mmap(-1, 0, n, unix.PROT_READ|unix.PROT_WRITE, unix.MAP_ANON|unix.MAP_PRIVATE)
+	if err != nil {
+		return make([]byte, n), false
+	}
+	return b, unix.Mlock(b) == nil
+}
+
+// freeSecret releases memory returned by allocSecret,
+// which the caller has wiped.
+func freeSecret(b []byte, locked bool) {
+	if locked {
+		unix.Munlock(b)
+	}
+	unix.Munmap(b)
+}
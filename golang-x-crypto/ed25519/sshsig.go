@@ -0,0 +1,186 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"hash"
+)
+
+// This file implements the detached signature format of OpenSSH,
+// as produced by ssh-keygen -Y sign and specified in PROTOCOL.sshsig
+// of the OpenSSH distribution.
+
+const (
+	sshsigMagic   = "SSHSIG"
+	sshsigVersion = 1
+	sshsigBegin   = "-----BEGIN SSH SIGNATURE-----"
+	sshsigEnd     = "-----END SSH SIGNATURE-----"
+	sshsigLineLen = 70
+	sshKeyType    = "ssh-ed25519"
+)
+
+// SignSSHSig signs message with privateKey in the armored SSHSIG format,
+// as ssh-keygen -Y sign does with the same key and namespace.
+// The namespace binds the signature to its purpose,
+// such as "git" for git commits and tags or "file" for files,
+// and must be given again to verify it.
+// It will panic if len(privateKey) is not PrivateKeySize.
+func SignSSHSig(privateKey PrivateKey, namespace string, message []byte) []byte {
+	if namespace == "" {
+		panic("ed25519: empty SSHSIG namespace")
+	}
+	digest := sha512.Sum512(message)
+	sig := Sign(privateKey, sshsigSignedData(namespace, "sha512", digest[:]))
+
+	var blob []byte
+	blob = append(blob, sshsigMagic...)
+	blob = binary.BigEndian.AppendUint32(blob, sshsigVersion)
+	blob = appendSSHString(blob, sshPublicKey(privateKey[32:]))
+	blob = appendSSHString(blob, []byte(namespace))
+	blob = appendSSHString(blob, nil) // reserved
+	blob = appendSSHString(blob, []byte("sha512"))
+	blob = appendSSHString(blob, appendSSHString(appendSSHString(nil, []byte(sshKeyType)), sig))
+
+	enc := base64.StdEncoding.EncodeToString(blob)
+	out := []byte(sshsigBegin + "\n")
+	for len(enc) > sshsigLineLen {
+		out = append(out, enc[:sshsigLineLen]+"\n"...)
+		enc = enc[sshsigLineLen:]
+	}
+	out = append(out, enc+"\n"...)
+	return append(out, sshsigEnd+"\n"...)
+}
+
+// VerifySSHSig checks that armored is an SSHSIG signature of message
+// by publicKey within namespace, as ssh-keygen -Y verify does.
+// It returns nil if the signature is valid.
+func VerifySSHSig(publicKey PublicKey, namespace string, message, armored []byte) error {
+	signer, hashAlg, sig, err := parseSSHSig(namespace, armored)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(signer, publicKey) {
+		return errors.New("ed25519: SSHSIG signed by a different key")
+	}
+	var h hash.Hash
+	switch hashAlg {
+	case "sha512":
+		h = sha512.New()
+	case "sha256":
+		h = sha256.New()
+	default:
+		return errors.New("ed25519: unsupported SSHSIG hash algorithm " + hashAlg)
+	}
+	h.Write(message)
+	return VerifyErr(publicKey, sshsigSignedData(namespace, hashAlg, h.Sum(nil)), sig)
+}
+
+// SSHSigPublicKey returns the public key that claims to have made
+// the armored SSHSIG signature, for looking up in a list of allowed signers
+// before calling VerifySSHSig. The signature itself is not checked.
+func SSHSigPublicKey(armored []byte) (PublicKey, error) {
+	signer, _, _, err := parseSSHSig("", armored)
+	return signer, err
+}
+
+// parseSSHSig decodes an armored SSHSIG signature,
+// checking its namespace unless namespace is empty.
+func parseSSHSig(namespace string, armored []byte) (signer PublicKey, hashAlg string, sig []byte, err error) {
+	errMalformed := errors.New("ed25519: malformed SSHSIG signature")
+	armored = bytes.TrimSpace(armored)
+	if !bytes.HasPrefix(armored, []byte(sshsigBegin)) || !bytes.HasSuffix(armored, []byte(sshsigEnd)) {
+		return nil, "", nil, errMalformed
+	}
+	body := armored[len(sshsigBegin) : len(armored)-len(sshsigEnd)]
+	body = bytes.Join(bytes.Fields(body), nil)
+	blob := make([]byte, base64.StdEncoding.DecodedLen(len(body)))
+	n, err := base64.StdEncoding.Decode(blob, body)
+	if err != nil {
+		return nil, "", nil, errMalformed
+	}
+	blob = blob[:n]
+
+	if !bytes.HasPrefix(blob, []byte(sshsigMagic)) || len(blob) < len(sshsigMagic)+4 {
+		return nil, "", nil, errMalformed
+	}
+	blob = blob[len(sshsigMagic):]
+	if binary.BigEndian.Uint32(blob) != sshsigVersion {
+		return nil, "", nil, errors.New("ed25519: unsupported SSHSIG version")
+	}
+	blob = blob[4:]
+
+	var fields [5][]byte
+	for i := range fields {
+		var ok bool
+		if fields[i], blob, ok = readSSHString(blob); !ok {
+			return nil, "", nil, errMalformed
+		}
+	}
+	if len(blob) != 0 {
+		return nil, "", nil, errMalformed
+	}
+	pubBlob, ns, hashName, sigBlob := fields[0], fields[1], fields[3], fields[4]
+
+	keyType, rest, ok := readSSHString(pubBlob)
+	if !ok || string(keyType) != sshKeyType {
+		return nil, "", nil, errors.New("ed25519: SSHSIG key is not an Ed25519 key")
+	}
+	pub, rest, ok := readSSHString(rest)
+	if !ok || len(rest) != 0 || len(pub) != PublicKeySize {
+		return nil, "", nil, errMalformed
+	}
+	if namespace != "" && string(ns) != namespace {
+		return nil, "", nil, errors.New("ed25519: SSHSIG namespace mismatch")
+	}
+
+	sigType, rest, ok := readSSHString(sigBlob)
+	if !ok || string(sigType) != sshKeyType {
+		return nil, "", nil, errMalformed
+	}
+	if sig, rest, ok = readSSHString(rest); !ok || len(rest) != 0 {
+		return nil, "", nil, errMalformed
+	}
+	return PublicKey(pub), string(hashName), sig, nil
+}
+
+// sshsigSignedData returns the data actually signed for an SSHSIG signature.
+func sshsigSignedData(namespace, hashAlg string, digest []byte) []byte {
+	var b []byte
+	b = append(b, sshsigMagic...)
+	b = appendSSHString(b, []byte(namespace))
+	b = appendSSHString(b, nil) // reserved
+	b = appendSSHString(b, []byte(hashAlg))
+	return appendSSHString(b, digest)
+}
+
+// sshPublicKey returns the SSH wire encoding of an Ed25519 public key.
+func sshPublicKey(publicKey []byte) []byte {
+	return appendSSHString(appendSSHString(nil, []byte(sshKeyType)), publicKey)
+}
+
+// appendSSHString appends s to b as an SSH wire string,
+// prefixed by its 32-bit big-endian length.
+func appendSSHString(b, s []byte) []byte {
+	b = binary.BigEndian.AppendUint32(b, uint32(len(s)))
+	return append(b, s...)
+}
+
+// readSSHString reads an SSH wire string from the start of b.
+func readSSHString(b []byte) (s, rest []byte, ok bool) {
+	if len(b) < 4 {
+		return nil, nil, false
+	}
+	n := binary.BigEndian.Uint32(b)
+	if uint64(n) > uint64(len(b)-4) {
+		return nil, nil, false
+	}
+	return b[4 : 4+n], b[4+n:], true
+}
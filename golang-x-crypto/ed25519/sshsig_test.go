@@ -0,0 +1,57 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !tinygo
+
+package ed25519
+
+import (
+	"testing"
+)
+
+// Written by ssh-keygen -Y sign -n file with testOpenSSHPrivateKey.
+const (
+	testSSHSigMessage = "hello sshsig\n"
+	testSSHSig        = `-----BEGIN SSH SIGNATURE-----
+U1NIU0lHAAAAAQAAADMAAAALc3NoLWVkMjU1MTkAAAAg5bkYtKcf8CP+KDLDXAaxjWUQIY
+vUSTOrMf8geB/mMBUAAAAEZmlsZQAAAAAAAAAGc2hhNTEyAAAAUwAAAAtzc2gtZWQyNTUx
+OQAAAECQZ0NKdqUInTOvfl6KWyAp6IV6L/3Is2T3Eji7yN3H8BDlK/oIFqcn0a6rbhki+h
+Dg4rpr7zscV/pJGaF8YogG
+-----END SSH SIGNATURE-----
+`
+)
+
+func TestSSHSig(t *testing.T) {
+	priv, err := ParseOpenSSHPrivateKey([]byte(testOpenSSHPrivateKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := priv.Public().(PublicKey)
+
+	if err := VerifySSHSig(pub, "file", []byte(testSSHSigMessage), []byte(testSSHSig)); err != nil {
+		t.Errorf("ssh-keygen signature rejected: %v", err)
+	}
+	// Ed25519 is deterministic, so ssh-keygen's output is reproduced exactly.
+	if got := SignSSHSig(priv, "file", []byte(testSSHSigMessage)); string(got) != testSSHSig {
+		t.Errorf("signature:\n%s\nwant\n%s", got, testSSHSig)
+	}
+	signer, err := SSHSigPublicKey([]byte(testSSHSig))
+	if err != nil || !signer.Equal(pub) {
+		t.Errorf("SSHSigPublicKey = %x, %v; want %x", signer, err, pub)
+	}
+
+	if err := VerifySSHSig(pub, "git", []byte(testSSHSigMessage), []byte(testSSHSig)); err == nil {
+		t.Errorf("signature accepted in wrong namespace")
+	}
+	if err := VerifySSHSig(pub, "file", []byte("other message"), []byte(testSSHSig)); err == nil {
+		t.Errorf("signature accepted for wrong message")
+	}
+	other, _, _ := GenerateKey(nil)
+	if err := VerifySSHSig(other, "file", []byte(testSSHSigMessage), []byte(testSSHSig)); err == nil {
+		t.Errorf("signature accepted for wrong key")
+	}
+	if err := VerifySSHSig(pub, "file", []byte(testSSHSigMessage), []byte(testSSHSig[:100])); err == nil {
+		t.Errorf("truncated signature accepted")
+	}
+}
@@ -0,0 +1,206 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"crypto/subtle"
+
+	"test-server/golang-x-crypto/ed25519/internal/edwards25519"
+)
+
+// This file holds the individual checks applied by VerifyStrict,
+// for callers that need them on their own, e.g. to validate
+// public keys once at registration rather than on every verification.
+
+// IsCanonicalScalar reports whether s is the 32-byte little-endian encoding
+// of an integer less than the order of the group,
+// as RFC 8032 requires of the S half of a signature.
+func IsCanonicalScalar(s []byte) bool {
+	if len(s) != 32 {
+		return false
+	}
+	var b [32]byte
+	copy(b[:], s)
+	return edwards25519.ScMinimal(&b)
+}
+
+// IsCanonicalS reports whether sig is SignatureSize bytes long
+// and its S half is less than the order of the group.
+// Verify also accepts S + L, a second encoding of every signature,
+// so systems using signatures as unique identifiers,
+// such as deduplication keys or transaction hashes,
+// should require IsCanonicalS or apply NormalizeS first.
+func IsCanonicalS(sig []byte) bool {
+	return len(sig) == SignatureSize && IsCanonicalScalar(sig[32:])
+}
+
+// NormalizeS returns a copy of sig with its S half reduced modulo
+// the order of the group, which Verify accepts if and only if it accepts sig.
+// It returns ErrSignatureSize if sig is not SignatureSize bytes long,
+// and ErrNonCanonical if S has any of its top three bits set,
+// which no verification mode accepts.
+// NormalizeS does not touch R: VerifyStrict rejects
+// the non-canonical encodings of R that Verify accepts.
+func NormalizeS(sig []byte) ([]byte, error) {
+	if len(sig) != SignatureSize {
+		return nil, ErrSignatureSize
+	}
+	if sig[63]&224 != 0 {
+		return nil, ErrNonCanonical
+	}
+	var wide [64]byte
+	copy(wide[:], sig[32:])
+	var s [32]byte
+	edwards25519.ScReduce(&s, &wide)
+	out := make([]byte, SignatureSize)
+	copy(out, sig[:32])
+	copy(out[32:], s[:])
+	return out, nil
+}
+
+// IsCanonicalPoint reports whether p is the canonical encoding
+// of a point on the curve: a y-coordinate less than 2^255 - 19,
+// and no sign bit set for an x-coordinate of zero.
+// Every point has exactly one canonical encoding.
+func IsCanonicalPoint(p []byte) bool {
+	var P edwards25519.ExtendedGroupElement
+	if !decodePoint(&P, p) {
+		return false
+	}
+	var enc [32]byte
+	P.ToBytes(&enc)
+	return subtle.ConstantTimeCompare(enc[:], p) == 1
+}
+
+// IsSmallOrder reports whether p encodes a point of order 1, 2, 4 or 8,
+// that is, one in the torsion subgroup of the curve.
+// Used as a public key or a signature's R, such a point makes
+// signatures independent of the message or the private key.
+// It returns false if p does not encode a point at all.
+func IsSmallOrder(p []byte) bool {
+	var P edwards25519.ExtendedGroupElement
+	if !decodePoint(&P, p) {
+		return false
+	}
+	P.MulByCofactor(&P)
+	return P.IsIdentity()
+}
+
+// weakKeys are the encodings of the points of order 1, 2, 4 and 8
+// that libsodium and others refuse as public keys,
+// the non-canonical ones included, with the sign bit ignored.
+var weakKeys = [...][32]byte{
+	// 0 (order 4)
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+	// 1 (order 1)
+	{0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+	// order 8
+	{0x26, 0xe8, 0x95, 0x8f, 0xc2, 0xb2, 0x27, 0xb0, 0x45, 0xc3, 0xf4, 0x89, 0xf2, 0xef, 0x98, 0xf0, 0xd5, 0xdf, 0xac, 0x05, 0xd3, 0xc6, 0x33, 0x39, 0xb1, 0x38, 0x02, 0x88, 0x6d, 0x53, 0xfc, 0x05},
+	// order 8
+	{0xc7, 0x17, 0x6a, 0x70, 0x3d, 0x4d, 0xd8, 0x4f, 0xba, 0x3c, 0x0b, 0x76, 0x0d, 0x10, 0x67, 0x0f, 0x2a, 0x20, 0x53, 0xfa, 0x2c, 0x39, 0xcc, 0xc6, 0x4e, 0xc7, 0xfd, 0x77, 0x92, 0xac, 0x03, 0x7a},
+	// p-1 (order 2)
+	{0xec, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f},
+	// p (=0, order 4)
+	{0xed, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f},
+	// p+1 (=1, order 1)
+	{0xee, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f},
+}
+
+// IsWeakPublicKey reports whether publicKey is on the standard blacklist
+// of weak Ed25519 public keys: the all-zero key
+// and the other encodings of points of small order,
+// with or without the sign bit set, canonical or not.
+// Such a key makes any signature checked against it
+// independent of the message, or of any private key.
+//
+// IsWeakPublicKey compares bytes in constant time without decoding,
+// so it is cheap enough to run before every verification,
+// and rejects exactly what libsodium's blacklist does.
+// It returns false for keys of the wrong length,
+// which verification rejects anyway.
+// ValidatePublicKey subsumes it for keys checked once, at onboarding.
+func IsWeakPublicKey(publicKey []byte) bool {
+	if len(publicKey) != PublicKeySize {
+		return false
+	}
+	var k [32]byte
+	copy(k[:], publicKey)
+	k[31] &= 0x7f
+	weak := 0
+	for i := range weakKeys {
+		weak |= subtle.ConstantTimeCompare(k[:], weakKeys[i][:])
+	}
+	return weak == 1
+}
+
+// ValidatePublicKey checks that publicKey is fit to identify a signer,
+// as when registering a key or admitting a cosigner:
+// that it is PublicKeySize bytes long, canonically encodes a curve point,
+// and that point generates the prime-order subgroup.
+// It returns ErrPublicKeySize, ErrInvalidPoint, ErrNonCanonical,
+// ErrSmallOrder for the identity and other points of small order,
+// or ErrTorsion for a point with a small-order component.
+// Every key made by GenerateKey or NewKeyFromSeed passes.
+//
+// Unlike the checks of VerifyStrict, the last one costs a scalar multiplication,
+// so ValidatePublicKey is meant to run once per key, not per signature.
+func ValidatePublicKey(publicKey PublicKey) error {
+	if len(publicKey) != PublicKeySize {
+		return ErrPublicKeySize
+	}
+	var P edwards25519.ExtendedGroupElement
+	if !decodePoint(&P, publicKey) {
+		return ErrInvalidPoint
+	}
+	var enc [32]byte
+	P.ToBytes(&enc)
+	if subtle.ConstantTimeCompare(enc[:], publicKey) != 1 {
+		return ErrNonCanonical
+	}
+	var Q edwards25519.ExtendedGroupElement
+	Q.MulByCofactor(&P)
+	if Q.IsIdentity() {
+		return ErrSmallOrder
+	}
+	if P.IsTorsionFree() != 1 {
+		return ErrTorsion
+	}
+	return nil
+}
+
+// decodePoint decodes a 32-byte point encoding into P.
+// It accepts non-canonical encodings.
+func decodePoint(P *edwards25519.ExtendedGroupElement, p []byte) bool {
+	if len(p) != 32 {
+		return false
+	}
+	var b [32]byte
+	copy(b[:], p)
+	return P.FromBytes(&b)
+}
+
+// checkStrict applies the encoding checks of VerifyStrict
+// to a public key and signature of the right lengths.
+func checkStrict(publicKey PublicKey, sig []byte) error {
+	if !IsCanonicalScalar(sig[32:]) {
+		return ErrNonCanonical
+	}
+	for _, p := range [][]byte{publicKey, sig[:32]} {
+		var P edwards25519.ExtendedGroupElement
+		if !decodePoint(&P, p) {
+			return ErrInvalidPoint
+		}
+		var enc [32]byte
+		P.ToBytes(&enc)
+		if subtle.ConstantTimeCompare(enc[:], p) != 1 {
+			return ErrNonCanonical
+		}
+		P.MulByCofactor(&P)
+		if P.IsIdentity() {
+			return ErrSmallOrder
+		}
+	}
+	return nil
+}
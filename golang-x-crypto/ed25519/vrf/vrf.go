@@ -0,0 +1,206 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package vrf implements the ECVRF-EDWARDS25519-SHA512-TAI
+// verifiable random function of RFC 9381 with Ed25519 keys.
+//
+// The holder of a private key computes, for any input alpha,
+// a proof pi from which anyone can derive the pseudorandom output
+// beta = ProofToHash(pi), and which anyone with the public key
+// can check binds beta to alpha and the key.
+// No one without the private key can predict beta,
+// and the key holder cannot produce two outputs for one input,
+// which makes VRF outputs suitable for unbiased committee sampling
+// and leader election anchored in the cosigners' existing keys.
+package vrf
+
+import (
+	"crypto/sha512"
+	"crypto/subtle"
+	"errors"
+
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/golang-x-crypto/ed25519/internal/edwards25519"
+)
+
+const (
+	// ProofSize is the size, in bytes, of proofs.
+	ProofSize = 80
+	// OutputSize is the size, in bytes, of VRF outputs.
+	OutputSize = 64
+
+	suite = 0x03 // ECVRF-EDWARDS25519-SHA512-TAI
+	cLen  = 16
+)
+
+// Prove returns the proof of the VRF output of privateKey for alpha.
+// It will panic if len(privateKey) is not ed25519.PrivateKeySize.
+func Prove(privateKey ed25519.PrivateKey, alpha []byte) []byte {
+	if len(privateKey) != ed25519.PrivateKeySize {
+		panic("vrf: bad private key length")
+	}
+	digest := sha512.Sum512(privateKey[:32])
+	var x [32]byte
+	copy(x[:], digest[:32])
+	x[0] &= 248
+	x[31] &= 127
+	x[31] |= 64
+
+	var Y edwards25519.ExtendedGroupElement
+	edwards25519.GeScalarMultBase(&Y, &x)
+	var pk [32]byte
+	Y.ToBytes(&pk)
+
+	H, hString := encodeToCurve(pk[:], alpha)
+	var Gamma edwards25519.ExtendedGroupElement
+	edwards25519.GeScalarMult(&Gamma, &x, H)
+
+	// Nonce generation as in RFC 8032, Section 5.1.6.
+	h := sha512.New()
+	h.Write(digest[32:])
+	h.Write(hString)
+	var kDigest [64]byte
+	h.Sum(kDigest[:0])
+	var k [32]byte
+	edwards25519.ScReduce(&k, &kDigest)
+
+	var kB, kH edwards25519.ExtendedGroupElement
+	edwards25519.GeScalarMultBase(&kB, &k)
+	edwards25519.GeScalarMult(&kH, &k, H)
+	c := challenge(pk[:], hString, &Gamma, &kB, &kH)
+
+	// s = k + c*x mod q
+	var s [32]byte
+	edwards25519.ScMulAdd(&s, &c, &x, &k)
+
+	pi := make([]byte, ProofSize)
+	var gammaString [32]byte
+	Gamma.ToBytes(&gammaString)
+	copy(pi, gammaString[:])
+	copy(pi[32:], c[:cLen])
+	copy(pi[32+cLen:], s[:])
+	return pi
+}
+
+// ProofToHash returns the VRF output beta of a proof.
+// It does not check the proof: outputs must only be used
+// after Verify has accepted the proof, or be taken from Verify.
+func ProofToHash(pi []byte) ([]byte, error) {
+	Gamma, _, _, err := decodeProof(pi)
+	if err != nil {
+		return nil, err
+	}
+	return gammaToHash(Gamma), nil
+}
+
+// Verify checks that pi is a valid proof for alpha by publicKey,
+// and if so returns the VRF output beta.
+func Verify(publicKey ed25519.PublicKey, alpha, pi []byte) ([]byte, bool) {
+	if len(publicKey) != ed25519.PublicKeySize || ed25519.IsSmallOrder(publicKey) {
+		return nil, false
+	}
+	var Y edwards25519.ExtendedGroupElement
+	var pk [32]byte
+	copy(pk[:], publicKey)
+	if !Y.FromBytes(&pk) {
+		return nil, false
+	}
+	Gamma, c, s, err := decodeProof(pi)
+	if err != nil {
+		return nil, false
+	}
+	H, hString := encodeToCurve(publicKey, alpha)
+
+	// U = s*B - c*Y, V = s*H - c*Gamma
+	var sB, cY, U, sH, cGamma, V edwards25519.ExtendedGroupElement
+	edwards25519.GeScalarMultBase(&sB, s)
+	edwards25519.GeScalarMultVartime(&cY, c, &Y)
+	U.Sub(&sB, &cY)
+	edwards25519.GeScalarMultVartime(&sH, s, H)
+	edwards25519.GeScalarMultVartime(&cGamma, c, Gamma)
+	V.Sub(&sH, &cGamma)
+
+	c2 := challenge(publicKey, hString, Gamma, &U, &V)
+	if subtle.ConstantTimeCompare(c[:cLen], c2[:cLen]) != 1 {
+		return nil, false
+	}
+	return gammaToHash(Gamma), true
+}
+
+// encodeToCurve hashes alpha to a point of the prime-order subgroup
+// with ECVRF_encode_to_curve_try_and_increment,
+// salted with the public key, and returns it and its encoding.
+func encodeToCurve(publicKey, alpha []byte) (*edwards25519.ExtendedGroupElement, []byte) {
+	h := sha512.New()
+	var digest [64]byte
+	var b [32]byte
+	H := new(edwards25519.ExtendedGroupElement)
+	for ctr := 0; ctr < 256; ctr++ {
+		h.Reset()
+		h.Write([]byte{suite, 0x01})
+		h.Write(publicKey)
+		h.Write(alpha)
+		h.Write([]byte{byte(ctr), 0x00})
+		h.Sum(digest[:0])
+		copy(b[:], digest[:32])
+		if ed25519.IsCanonicalPoint(b[:]) && H.FromBytes(&b) {
+			H.MulByCofactor(H)
+			H.ToBytes(&b)
+			return H, b[:]
+		}
+	}
+	// Each attempt succeeds with probability about 1/2.
+	panic("vrf: no curve point found")
+}
+
+// challenge returns the challenge scalar, truncated to cLen bytes,
+// computed over the public key and the points H, Gamma, U and V.
+func challenge(publicKey, hString []byte, Gamma, U, V *edwards25519.ExtendedGroupElement) [32]byte {
+	h := sha512.New()
+	h.Write([]byte{suite, 0x02})
+	h.Write(publicKey)
+	h.Write(hString)
+	var b [32]byte
+	for _, P := range []*edwards25519.ExtendedGroupElement{Gamma, U, V} {
+		P.ToBytes(&b)
+		h.Write(b[:])
+	}
+	h.Write([]byte{0x00})
+	var digest [64]byte
+	h.Sum(digest[:0])
+	var c [32]byte
+	copy(c[:cLen], digest[:cLen])
+	return c
+}
+
+// gammaToHash returns the VRF output for the point Gamma of a proof.
+func gammaToHash(Gamma *edwards25519.ExtendedGroupElement) []byte {
+	var cofactorGamma edwards25519.ExtendedGroupElement
+	cofactorGamma.MulByCofactor(Gamma)
+	var b [32]byte
+	cofactorGamma.ToBytes(&b)
+	h := sha512.New()
+	h.Write([]byte{suite, 0x03})
+	h.Write(b[:])
+	h.Write([]byte{0x00})
+	return h.Sum(nil)
+}
+
+// decodeProof splits a proof into Gamma, c and s.
+func decodeProof(pi []byte) (Gamma *edwards25519.ExtendedGroupElement, c, s *[32]byte, err error) {
+	errMalformed := errors.New("vrf: malformed proof")
+	if len(pi) != ProofSize || !ed25519.IsCanonicalScalar(pi[32+cLen:]) {
+		return nil, nil, nil, errMalformed
+	}
+	var b [32]byte
+	copy(b[:], pi[:32])
+	Gamma = new(edwards25519.ExtendedGroupElement)
+	if !Gamma.FromBytes(&b) {
+		return nil, nil, nil, errMalformed
+	}
+	c, s = new([32]byte), new([32]byte)
+	copy(c[:cLen], pi[32:])
+	copy(s[:], pi[32+cLen:])
+	return Gamma, c, s, nil
+}
@@ -0,0 +1,82 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vrf
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"test-server/golang-x-crypto/ed25519"
+)
+
+// Test vectors for ECVRF-EDWARDS25519-SHA512-TAI from RFC 9381, Appendix B.3.
+var vectors = []struct {
+	sk, alpha, pi, beta string
+}{
+	{
+		"9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60",
+		"",
+		"8657106690b5526245a92b003bb079ccd1a92130477671f6fc01ad16f26f723f26f8a57ccaed74ee1b190bed1f479d9727d2d0f9b005a6e456a35d4fb0daab1268a1b0db10836d9826a528ca76567805",
+		"90cf1df3b703cce59e2a35b925d411164068269d7b2d29f3301c03dd757876ff66b71dda49d2de59d03450451af026798e8f81cd2e333de5cdf4f3e140fdd8ae",
+	},
+	{
+		"4ccd089b28ff96da9db6c346ec114e0f5b8a319f35aba624da8cf6ed4fb8a6fb",
+		"72",
+		"f3141cd382dc42909d19ec5110469e4feae18300e94f304590abdced48aed5933bf0864a62558b3ed7f2fea45c92a465301b3bbf5e3e54ddf2d935be3b67926da3ef39226bbc355bdc9850112c8f4b02",
+		"eb4440665d3891d668e7e0fcaf587f1b4bd7fbfe99d0eb2211ccec90496310eb5e33821bc613efb94db5e5b54c70a848a0bef4553a41befc57663b56373a5031",
+	},
+}
+
+func TestVectors(t *testing.T) {
+	for _, v := range vectors {
+		seed, _ := hex.DecodeString(v.sk)
+		alpha, _ := hex.DecodeString(v.alpha)
+		priv := ed25519.NewKeyFromSeed(seed)
+		pi := Prove(priv, alpha)
+		if got := hex.EncodeToString(pi); got != v.pi {
+			t.Errorf("pi = %s, want %s", got, v.pi)
+		}
+		beta, ok := Verify(priv.Public().(ed25519.PublicKey), alpha, pi)
+		if !ok {
+			t.Fatalf("proof rejected")
+		}
+		if got := hex.EncodeToString(beta); got != v.beta {
+			t.Errorf("beta = %s, want %s", got, v.beta)
+		}
+		if beta2, err := ProofToHash(pi); err != nil || !bytes.Equal(beta, beta2) {
+			t.Errorf("ProofToHash = %x, %v; want %x", beta2, err, beta)
+		}
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	alpha := []byte("round 42")
+	pi := Prove(priv, alpha)
+	if _, ok := Verify(pub, alpha, pi); !ok {
+		t.Fatalf("valid proof rejected")
+	}
+	if _, ok := Verify(pub, []byte("round 43"), pi); ok {
+		t.Errorf("proof accepted for other input")
+	}
+	other, _, _ := ed25519.GenerateKey(nil)
+	if _, ok := Verify(other, alpha, pi); ok {
+		t.Errorf("proof accepted for other key")
+	}
+	for i := 0; i < ProofSize; i += 8 {
+		bad := append([]byte{}, pi...)
+		bad[i] ^= 1
+		if _, ok := Verify(pub, alpha, bad); ok {
+			t.Errorf("proof with byte %d flipped accepted", i)
+		}
+	}
+	if _, ok := Verify(pub, alpha, pi[:ProofSize-1]); ok {
+		t.Errorf("short proof accepted")
+	}
+	if !bytes.Equal(Prove(priv, alpha), pi) {
+		t.Errorf("proofs not deterministic")
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !tinygo
+
+package ed25519
+
+import (
+	stded25519 "crypto/ed25519"
+	cryptorand "crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"strconv"
+	"time"
+)
+
+// CreateCertificateRequest creates a PKCS #10 certificate signing request,
+// in DER form, for the public key of privateKey, signed by privateKey.
+// Only the fields of template documented as used by
+// x509.CreateCertificateRequest are used; a nil template
+// requests a certificate for the hex-encoded public key as common name.
+func CreateCertificateRequest(privateKey PrivateKey, template *x509.CertificateRequest) ([]byte, error) {
+	if l := len(privateKey); l != PrivateKeySize {
+		return nil, errors.New("ed25519: bad private key length: " + strconv.Itoa(l))
+	}
+	if template == nil {
+		template = &x509.CertificateRequest{
+			Subject: pkix.Name{CommonName: hex.EncodeToString(privateKey[32:])},
+		}
+	}
+	return x509.CreateCertificateRequest(cryptorand.Reader, template, stded25519.PrivateKey(privateKey))
+}
+
+// CreateSelfSignedCertificate creates a certificate, in DER form,
+// for the public key of privateKey, signed by privateKey itself.
+//
+// A nil template creates a certificate valid for ten years
+// for both TLS server and client authentication,
+// whose subject is the hex-encoded public key.
+// A template without a SerialNumber is given a random 128-bit one.
+func CreateSelfSignedCertificate(privateKey PrivateKey, template *x509.Certificate) ([]byte, error) {
+	if l := len(privateKey); l != PrivateKeySize {
+		return nil, errors.New("ed25519: bad private key length: " + strconv.Itoa(l))
+	}
+	if template == nil {
+		now := time.Now()
+		template = &x509.Certificate{
+			Subject:   pkix.Name{CommonName: hex.EncodeToString(privateKey[32:])},
+			NotBefore: now.Add(-time.Hour),
+			NotAfter:  now.AddDate(10, 0, 0),
+			KeyUsage:  x509.KeyUsageDigitalSignature,
+			ExtKeyUsage: []x509.ExtKeyUsage{
+				x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth,
+			},
+		}
+	}
+	if template.SerialNumber == nil {
+		serial, err := cryptorand.Int(cryptorand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+		if err != nil {
+			return nil, err
+		}
+		t := *template
+		t.SerialNumber = serial
+		template = &t
+	}
+	key := stded25519.PrivateKey(privateKey)
+	return x509.CreateCertificate(cryptorand.Reader, template, template, key.Public(), key)
+}
+
+// TLSCertificate returns a tls.Certificate presenting the chain certDER,
+// leaf first, with privateKey as the leaf's private key.
+// It returns an error if the leaf does not certify the public key of privateKey.
+func TLSCertificate(privateKey PrivateKey, certDER ...[]byte) (tls.Certificate, error) {
+	if l := len(privateKey); l != PrivateKeySize {
+		return tls.Certificate{}, errors.New("ed25519: bad private key length: " + strconv.Itoa(l))
+	}
+	if len(certDER) == 0 {
+		return tls.Certificate{}, errors.New("ed25519: no certificate")
+	}
+	leaf, err := x509.ParseCertificate(certDER[0])
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	pub, err := CertificatePublicKey(leaf)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	if !pub.Equal(privateKey.Public()) {
+		return tls.Certificate{}, errors.New("ed25519: certificate does not match private key")
+	}
+	return tls.Certificate{
+		Certificate: certDER,
+		PrivateKey:  stded25519.PrivateKey(privateKey),
+		Leaf:        leaf,
+	}, nil
+}
+
+// CertificatePublicKey returns the public key certified by cert.
+// It returns an error if it is not an Ed25519 key.
+func CertificatePublicKey(cert *x509.Certificate) (PublicKey, error) {
+	pub, ok := cert.PublicKey.(stded25519.PublicKey)
+	if !ok {
+		return nil, errors.New("ed25519: certificate key is not an Ed25519 key")
+	}
+	return PublicKey(pub), nil
+}
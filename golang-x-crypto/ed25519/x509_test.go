@@ -0,0 +1,62 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !tinygo
+
+package ed25519
+
+import (
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+)
+
+func TestCreateCertificateRequest(t *testing.T) {
+	pub, priv, _ := GenerateKey(rand.Reader)
+	der, err := CreateCertificateRequest(priv, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: "cosigner"},
+		DNSNames: []string{"cosigner.example"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	csr, err := x509.ParseCertificateRequest(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := csr.CheckSignature(); err != nil {
+		t.Errorf("bad CSR signature: %v", err)
+	}
+	if !pub.Equal(csr.PublicKey) || csr.Subject.CommonName != "cosigner" {
+		t.Errorf("unexpected CSR contents")
+	}
+}
+
+func TestCreateSelfSignedCertificate(t *testing.T) {
+	pub, priv, _ := GenerateKey(rand.Reader)
+	der, err := CreateSelfSignedCertificate(priv, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature); err != nil {
+		t.Errorf("bad self-signature: %v", err)
+	}
+	certPub, err := CertificatePublicKey(cert)
+	if err != nil || !certPub.Equal(pub) {
+		t.Errorf("certificate key %x, %v; want %x", certPub, err, pub)
+	}
+
+	if _, err := TLSCertificate(priv, der); err != nil {
+		t.Errorf("TLSCertificate: %v", err)
+	}
+	_, other, _ := GenerateKey(rand.Reader)
+	if _, err := TLSCertificate(other, der); err == nil {
+		t.Errorf("certificate accepted for another key")
+	}
+}
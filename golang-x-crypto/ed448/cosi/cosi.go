@@ -0,0 +1,184 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package cosi implements collective signatures based on Ed448,
+// with the same API shape as the Ed25519-based package ed25519/cosi,
+// for deployments that require the 224-bit security level.
+//
+// Cosigners each Commit to a one-time secret,
+// the leader combines the commits with AggregateCommit,
+// each cosigner returns its part of the signature with Cosign,
+// and the leader combines the parts with AggregateSignature.
+// The collective signature is R || S || mask, 114 bytes plus one bit
+// per cosigner marking those that did not take part;
+// its R || S core is an ordinary Ed448 signature,
+// verifiable with ed448.Verify under the aggregate public key
+// of the participating cosigners.
+//
+// As in package ed25519/cosi, public keys must be known
+// to have been generated honestly, for instance by having each holder
+// sign its key, since aggregate keys are not protected
+// against rogue-key attacks.
+package cosi
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/cloudflare/circl/ecc/goldilocks"
+
+	"test-server/golang-x-crypto/ed448"
+)
+
+// MaskBit represents one bit of a Cosigners participation bitmask,
+// indicating whether a given cosigner is Enabled or Disabled.
+type MaskBit bool
+
+const (
+	Enabled  MaskBit = false
+	Disabled MaskBit = true
+)
+
+// Cosigners represents a group of collective signers
+// identified by an immutable, ordered list of their public keys,
+// together with a mutable participation bitmask
+// and the Policy used when verifying.
+//
+// A given Cosigners instance must be used only by one goroutine at a time.
+type Cosigners struct {
+	// decoded public keys of all cosigners
+	keys []goldilocks.Point
+
+	// bit-vector of disabled cosigners, byte-packed little-endian
+	mask []byte
+
+	// aggregate of the enabled cosigners' public keys
+	aggr goldilocks.Point
+
+	// cosigner-presence policy for checking signatures
+	policy Policy
+}
+
+// InvalidKeyError reports a public key rejected by NewCosigners.
+type InvalidKeyError struct {
+	Index  int    // position of the offending key in the list
+	Reason string // why the key was rejected
+}
+
+func (e *InvalidKeyError) Error() string {
+	return "cosi: invalid public key " + strconv.Itoa(e.Index) + ": " + e.Reason
+}
+
+// NewCosigners creates a Cosigners object
+// for a list of cosigners identified by Ed448 public keys,
+// with every cosigner initially enabled unless mask says otherwise
+// (see SetMask). The default policy requires all cosigners to sign.
+// It returns an *InvalidKeyError for the first unusable public key.
+func NewCosigners(publicKeys []ed448.PublicKey, mask []byte) (*Cosigners, error) {
+	cos := &Cosigners{
+		keys:   make([]goldilocks.Point, len(publicKeys)),
+		mask:   make([]byte, (len(publicKeys)+7)>>3),
+		aggr:   *goldilocks.Curve{}.Identity(),
+		policy: fullPolicy{},
+	}
+	for i, pk := range publicKeys {
+		if len(pk) != ed448.PublicKeySize {
+			return nil, &InvalidKeyError{i, "bad length " + strconv.Itoa(len(pk))}
+		}
+		P, err := goldilocks.FromBytes(pk)
+		if err != nil {
+			return nil, &InvalidKeyError{i, "not a valid curve point"}
+		}
+		cos.keys[i] = *P
+		cos.aggr.Add(P)
+	}
+	cos.SetMask(mask)
+	return cos, nil
+}
+
+// CountTotal returns the total number of cosigners.
+func (cos *Cosigners) CountTotal() int {
+	return len(cos.keys)
+}
+
+// CountEnabled returns the number of cosigners currently Enabled
+// in the participation bitmask.
+func (cos *Cosigners) CountEnabled() int {
+	count := 0
+	for i := range cos.keys {
+		if cos.MaskBit(i) == Enabled {
+			count++
+		}
+	}
+	return count
+}
+
+// SetMask sets the participation bitmask from a byte-packed little-endian
+// bit-vector, in which a set bit disables the corresponding cosigner.
+// Missing bytes of a short (or nil) mask enable their cosigners.
+func (cos *Cosigners) SetMask(mask []byte) {
+	for i := range cos.keys {
+		byt := i >> 3
+		bit := byte(1) << uint(i&7)
+		value := Enabled
+		if byt < len(mask) && mask[byt]&bit != 0 {
+			value = Disabled
+		}
+		cos.SetMaskBit(i, value)
+	}
+}
+
+// Mask returns a copy of the current participation bitmask.
+func (cos *Cosigners) Mask() []byte {
+	return append([]byte{}, cos.mask...)
+}
+
+// MaskLen returns the length in bytes of a complete participation bitmask.
+func (cos *Cosigners) MaskLen() int {
+	return (len(cos.keys) + 7) >> 3
+}
+
+// SetMaskBit enables or disables the mask bit for an individual cosigner.
+func (cos *Cosigners) SetMaskBit(signer int, value MaskBit) {
+	byt := signer >> 3
+	bit := byte(1) << uint(signer&7)
+	if value == Disabled {
+		if cos.mask[byt]&bit == 0 {
+			cos.mask[byt] |= bit
+			neg := cos.keys[signer]
+			neg.Neg()
+			cos.aggr.Add(&neg)
+		}
+	} else {
+		if cos.mask[byt]&bit != 0 {
+			cos.mask[byt] &^= bit
+			cos.aggr.Add(&cos.keys[signer])
+		}
+	}
+}
+
+// MaskBit returns whether the indicated cosigner is Enabled or Disabled.
+func (cos *Cosigners) MaskBit(signer int) MaskBit {
+	byt := signer >> 3
+	bit := byte(1) << uint(signer&7)
+	return cos.mask[byt]&bit != 0
+}
+
+// AggregatePublicKey returns the aggregate public key
+// of the cosigners currently enabled.
+func (cos *Cosigners) AggregatePublicKey() ed448.PublicKey {
+	return encode(&cos.aggr)
+}
+
+// encode returns the encoding of P, leaving P unchanged.
+func encode(P *goldilocks.Point) []byte {
+	Q := *P
+	b := make([]byte, ed448.PublicKeySize)
+	if err := Q.ToBytes(b); err != nil {
+		panic(err)
+	}
+	return b
+}
+
+var errMalformed = errors.New("cosi: malformed commit")
@@ -0,0 +1,180 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cosi
+
+import (
+	cryptorand "crypto/rand"
+	"io"
+	"strconv"
+
+	"github.com/cloudflare/circl/ecc/goldilocks"
+	"golang.org/x/crypto/sha3"
+
+	"test-server/golang-x-crypto/ed448"
+)
+
+// Commitment is a cosigner's one-time commit,
+// sent to the leader for combination via AggregateCommit.
+type Commitment []byte
+
+// SignaturePart is a cosigner's part of a collective signature,
+// sent to the leader for combination via AggregateSignature.
+type SignaturePart []byte
+
+// Secret is the one-time secret behind a Commitment.
+type Secret struct {
+	reduced goldilocks.Scalar
+	valid   bool
+}
+
+// Commit is invoked by cosigners to produce a one-time commit
+// for the collective signing of a single message,
+// using randomness from rand, or crypto/rand if rand is nil.
+// The Secret must be passed to the corresponding call to Cosign.
+func Commit(rand io.Reader) (Commitment, *Secret, error) {
+	if rand == nil {
+		rand = cryptorand.Reader
+	}
+	var wide [2 * ed448.SeedSize]byte
+	if _, err := io.ReadFull(rand, wide[:]); err != nil {
+		return nil, nil, err
+	}
+	secret := &Secret{valid: true}
+	secret.reduced.FromBytes(wide[:])
+	R := goldilocks.Curve{}.ScalarBaseMult(&secret.reduced)
+	return encode(R), secret, nil
+}
+
+// Cosign is used by a cosigner to produce its part of a collective signature
+// of message, given the aggregate public key and aggregate commit
+// that the leader obtained from AggregatePublicKey and AggregateCommit.
+//
+// Cosign invalidates the secret,
+// and panics if called with a previously-used secret.
+func Cosign(privateKey ed448.PrivateKey, secret *Secret, message []byte,
+	aggregateK ed448.PublicKey, aggregateR Commitment) SignaturePart {
+
+	if l := len(privateKey); l != ed448.PrivateKeySize {
+		panic("ed448: bad private key length: " + strconv.Itoa(l))
+	}
+	if l := len(aggregateR); l != ed448.PublicKeySize {
+		panic("ed448: bad aggregateR length: " + strconv.Itoa(l))
+	}
+	if !secret.valid {
+		panic("ed448: you must use a cosigning Secret only once")
+	}
+
+	var digest [2 * ed448.SeedSize]byte
+	sha3.ShakeSum256(digest[:], privateKey[:ed448.SeedSize])
+	digest[0] &= 0xfc
+	digest[ed448.SeedSize-1] = 0
+	digest[ed448.SeedSize-2] |= 0x80
+	var a goldilocks.Scalar
+	a.FromBytes(digest[:ed448.SeedSize])
+
+	k := challenge(aggregateR, aggregateK, message)
+	var s goldilocks.Scalar
+	s.Mul(k, &a)
+	s.Add(&s, &secret.reduced)
+
+	secret.reduced = goldilocks.Scalar{}
+	secret.valid = false
+
+	part := make([]byte, ed448.SeedSize)
+	copy(part, s[:])
+	return part
+}
+
+// challenge returns the Ed448 challenge SHAKE256(dom4(0, "") || R || A || M)
+// reduced modulo the group order.
+func challenge(R, A, message []byte) *goldilocks.Scalar {
+	h := sha3.NewShake256()
+	h.Write([]byte("SigEd448\x00\x00"))
+	h.Write(R)
+	h.Write(A)
+	h.Write(message)
+	var digest [2 * ed448.SeedSize]byte
+	h.Read(digest[:])
+	k := new(goldilocks.Scalar)
+	k.FromBytes(digest[:])
+	return k
+}
+
+// AggregateCommit is invoked by the leader to combine the commits
+// of the cosigners enabled in the participation mask.
+// The commits slice must have one entry per cosigner;
+// entries of disabled cosigners are ignored.
+func (cos *Cosigners) AggregateCommit(commits []Commitment) (Commitment, error) {
+	if len(commits) != len(cos.keys) {
+		return nil, errMalformed
+	}
+	aggR := goldilocks.Curve{}.Identity()
+	for i := range cos.keys {
+		if cos.MaskBit(i) == Disabled {
+			continue
+		}
+		if len(commits[i]) != ed448.PublicKeySize {
+			return nil, errMalformed
+		}
+		R, err := goldilocks.FromBytes(commits[i])
+		if err != nil {
+			return nil, errMalformed
+		}
+		aggR.Add(R)
+	}
+	return encode(aggR), nil
+}
+
+// AggregateSignature is invoked by the leader to combine the signature parts
+// of the cosigners enabled in the participation mask,
+// which must not have changed since AggregateCommit,
+// into a collective signature R || S || mask.
+// It returns nil if a part is malformed.
+func (cos *Cosigners) AggregateSignature(aggregateR Commitment, sigParts []SignaturePart) []byte {
+	if len(aggregateR) != ed448.PublicKeySize || len(sigParts) != len(cos.keys) {
+		return nil
+	}
+	var aggS goldilocks.Scalar
+	for i := range cos.keys {
+		if cos.MaskBit(i) == Disabled {
+			continue
+		}
+		if len(sigParts[i]) != ed448.SeedSize {
+			return nil
+		}
+		var s goldilocks.Scalar
+		s.FromBytes(sigParts[i])
+		aggS.Add(&aggS, &s)
+	}
+	sig := make([]byte, 0, ed448.SignatureSize+cos.MaskLen())
+	sig = append(sig, aggregateR...)
+	sig = append(sig, aggS[:]...)
+	sig = append(sig, 0) // S occupies 57 bytes, the last always zero
+	return append(sig, cos.mask...)
+}
+
+// VerifyPart allows the leader to check the signature part
+// of the cosigner at index signer, given its commit,
+// before aggregating it.
+func (cos *Cosigners) VerifyPart(message []byte, aggregateR, commit Commitment,
+	signer int, part SignaturePart) bool {
+
+	if len(part) != ed448.SeedSize || len(commit) != ed448.PublicKeySize {
+		return false
+	}
+	R, err := goldilocks.FromBytes(commit)
+	if err != nil {
+		return false
+	}
+	k := challenge(aggregateR, cos.AggregatePublicKey(), message)
+	var s goldilocks.Scalar
+	s.FromBytes(part)
+
+	// Check s*B - k*A == R.
+	A := cos.keys[signer]
+	A.Neg()
+	check := goldilocks.Curve{}.CombinedMult(&s, k, &A)
+	return check.IsEqual(R)
+}
@@ -0,0 +1,165 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package ed448 implements the Ed448 signature algorithm of RFC 8032,
+// at the 224-bit security level, with the same API shape
+// as package ed25519. See package ed448/cosi for collective signatures.
+//
+// The curve arithmetic is that of github.com/cloudflare/circl.
+package ed448
+
+import (
+	"bytes"
+	"crypto"
+	cryptorand "crypto/rand"
+	"errors"
+	"io"
+	"strconv"
+
+	circl "github.com/cloudflare/circl/sign/ed448"
+)
+
+const (
+	// PublicKeySize is the size, in bytes, of public keys as used in this package.
+	PublicKeySize = 57
+	// PrivateKeySize is the size, in bytes, of private keys as used in this package.
+	PrivateKeySize = 114
+	// SignatureSize is the size, in bytes, of signatures generated and verified by this package.
+	SignatureSize = 114
+	// SeedSize is the size, in bytes, of private key seeds. These are the private key representations used by RFC 8032.
+	SeedSize = 57
+	// ContextMaxSize is the maximum length, in bytes, of a context string.
+	ContextMaxSize = 255
+)
+
+// PublicKey is the type of Ed448 public keys.
+type PublicKey []byte
+
+// Equal reports whether pub and x have the same value.
+func (pub PublicKey) Equal(x crypto.PublicKey) bool {
+	xx, ok := x.(PublicKey)
+	if !ok {
+		return false
+	}
+	return bytes.Equal(pub, xx)
+}
+
+// PrivateKey is the type of Ed448 private keys. It implements crypto.Signer.
+type PrivateKey []byte
+
+// Public returns the PublicKey corresponding to priv.
+func (priv PrivateKey) Public() crypto.PublicKey {
+	publicKey := make([]byte, PublicKeySize)
+	copy(publicKey, priv[SeedSize:])
+	return PublicKey(publicKey)
+}
+
+// Equal reports whether priv and x have the same value.
+func (priv PrivateKey) Equal(x crypto.PrivateKey) bool {
+	xx, ok := x.(PrivateKey)
+	if !ok {
+		return false
+	}
+	return bytes.Equal(priv, xx)
+}
+
+// Seed returns the private key seed corresponding to priv. It is provided for
+// interoperability with RFC 8032. RFC 8032's private keys correspond to seeds
+// in this package.
+func (priv PrivateKey) Seed() []byte {
+	seed := make([]byte, SeedSize)
+	copy(seed, priv[:SeedSize])
+	return seed
+}
+
+// Options can be used with PrivateKey.Sign or VerifyWithOptions
+// to select a context string.
+type Options struct {
+	// Context, if not empty, is bound into the signature.
+	// It must be at most ContextMaxSize bytes.
+	Context string
+}
+
+// HashFunc returns zero, as Ed448 signs unhashed messages.
+func (o *Options) HashFunc() crypto.Hash { return crypto.Hash(0) }
+
+// Sign signs the given message with priv. rand is ignored.
+// opts.HashFunc() must return zero, as Ed448ph is not supported;
+// an *Options value may carry a context string.
+func (priv PrivateKey) Sign(rand io.Reader, message []byte, opts crypto.SignerOpts) (signature []byte, err error) {
+	if opts.HashFunc() != crypto.Hash(0) {
+		return nil, errors.New("ed448: cannot sign hashed message")
+	}
+	var context string
+	if o, ok := opts.(*Options); ok {
+		context = o.Context
+	}
+	if l := len(context); l > ContextMaxSize {
+		return nil, errors.New("ed448: bad context length: " + strconv.Itoa(l))
+	}
+	return circl.Sign(circl.PrivateKey(priv), message, context), nil
+}
+
+// GenerateKey generates a public/private key pair using entropy from rand.
+// If rand is nil, crypto/rand.Reader will be used.
+func GenerateKey(rand io.Reader) (PublicKey, PrivateKey, error) {
+	if rand == nil {
+		rand = cryptorand.Reader
+	}
+	seed := make([]byte, SeedSize)
+	if _, err := io.ReadFull(rand, seed); err != nil {
+		return nil, nil, err
+	}
+	privateKey := NewKeyFromSeed(seed)
+	return PublicKey(privateKey[SeedSize:]), privateKey, nil
+}
+
+// NewKeyFromSeed calculates a private key from a seed. It will panic if
+// len(seed) is not SeedSize. This function is provided for interoperability
+// with RFC 8032. RFC 8032's private keys correspond to seeds in this
+// package.
+func NewKeyFromSeed(seed []byte) PrivateKey {
+	if l := len(seed); l != SeedSize {
+		panic("ed448: bad seed length: " + strconv.Itoa(l))
+	}
+	return PrivateKey(circl.NewKeyFromSeed(seed))
+}
+
+// Sign signs the message with privateKey and returns a signature. It will
+// panic if len(privateKey) is not PrivateKeySize.
+func Sign(privateKey PrivateKey, message []byte) []byte {
+	if l := len(privateKey); l != PrivateKeySize {
+		panic("ed448: bad private key length: " + strconv.Itoa(l))
+	}
+	return circl.Sign(circl.PrivateKey(privateKey), message, "")
+}
+
+// Verify reports whether sig is a valid signature of message by publicKey. It
+// will panic if len(publicKey) is not PublicKeySize.
+func Verify(publicKey PublicKey, message, sig []byte) bool {
+	if l := len(publicKey); l != PublicKeySize {
+		panic("ed448: bad public key length: " + strconv.Itoa(l))
+	}
+	return circl.Verify(circl.PublicKey(publicKey), message, sig, "")
+}
+
+// VerifyWithOptions reports whether sig is a valid signature of message
+// by publicKey under the context string in opts.
+// A nil opts is the same as an empty context.
+func VerifyWithOptions(publicKey PublicKey, message, sig []byte, opts *Options) error {
+	if l := len(publicKey); l != PublicKeySize {
+		return errors.New("ed448: bad public key length: " + strconv.Itoa(l))
+	}
+	var context string
+	if opts != nil {
+		context = opts.Context
+	}
+	if l := len(context); l > ContextMaxSize {
+		return errors.New("ed448: bad context length: " + strconv.Itoa(l))
+	}
+	if !circl.Verify(circl.PublicKey(publicKey), message, sig, context) {
+		return errors.New("ed448: invalid signature")
+	}
+	return nil
+}
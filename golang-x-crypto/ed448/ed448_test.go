@@ -0,0 +1,80 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed448
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/hex"
+	"testing"
+)
+
+func TestRFC8032(t *testing.T) {
+	// RFC 8032, section 7.4, "-----Blank".
+	seed, _ := hex.DecodeString("6c82a562cb808d10d632be89c8513ebf6c929f34ddfa8c9f63c9960ef6e348a3528c8a3fcc2f044e39a3fc5b94492f8f032e7549a20098f95b")
+	pub, _ := hex.DecodeString("5fd7449b59b461fd2ce787ec616ad46a1da1342485a70e1f8a0ea75d80e96778edf124769b46c7061bd6783df1e50f6cd1fa1abeafe8256180")
+	sig, _ := hex.DecodeString("533a37f6bbe457251f023c0d88f976ae2dfb504a843e34d2074fd823d41a591f2b233f034f628281f2fd7a22ddd47d7828c59bd0a21bfd3980ff0d2028d4b18a9df63e006c5d1c2d345b925d8dc00b4104852db99ac5c7cdda8530a113a0f4dbb61149f05a7363268c71d95808ff2e652600")
+
+	priv := NewKeyFromSeed(seed)
+	if got := priv.Public().(PublicKey); !bytes.Equal(got, pub) {
+		t.Errorf("public key %x, want %x", got, pub)
+	}
+	if got := Sign(priv, nil); !bytes.Equal(got, sig) {
+		t.Errorf("signature %x, want %x", got, sig)
+	}
+	if !Verify(pub, nil, sig) {
+		t.Errorf("RFC 8032 signature rejected")
+	}
+	if !bytes.Equal(priv.Seed(), seed) {
+		t.Errorf("Seed() = %x, want %x", priv.Seed(), seed)
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	public, private, err := GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := []byte("test message")
+	sig := Sign(private, message)
+	if !Verify(public, message, sig) {
+		t.Errorf("valid signature rejected")
+	}
+	if Verify(public, []byte("wrong message"), sig) {
+		t.Errorf("signature of different message accepted")
+	}
+	if !public.Equal(private.Public()) {
+		t.Errorf("public key not equal to private key's public half")
+	}
+}
+
+func TestCryptoSigner(t *testing.T) {
+	public, private, _ := GenerateKey(nil)
+	var signer crypto.Signer = private
+	message := []byte("message")
+
+	sig, err := signer.Sign(nil, message, crypto.Hash(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Verify(public, message, sig) {
+		t.Errorf("crypto.Signer signature rejected")
+	}
+
+	opts := &Options{Context: "test context"}
+	sig, err = signer.Sign(nil, message, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyWithOptions(public, message, sig, opts); err != nil {
+		t.Errorf("context signature rejected: %v", err)
+	}
+	if Verify(public, message, sig) {
+		t.Errorf("context signature accepted without context")
+	}
+	if _, err := signer.Sign(nil, message, crypto.SHA512); err == nil {
+		t.Errorf("signed a hashed message")
+	}
+}
@@ -0,0 +1,179 @@
+// Package group: cosigner 그룹 파일과 키 파일 형식
+//
+// 그룹 파일은 JSON으로, 서명 순서대로 나열된 cosigner와
+// cosigner가 받아들이는 leader 키를 담는다. 키는 모두 hex 문자열.
+//
+//	{
+//	  "cosigners": [
+//	    {"publicKey": "3b6a27bc...", "address": "cosigner1:7000"},
+//	    {"publicKey": "8a88e3dd...", "address": "cosigner2:7000"}
+//	  ],
+//	  "leaders": ["d75a9801..."],
+//	  "threshold": 2
+//	}
+//
+// 개인키 파일은 PKCS #8 PEM("PRIVATE KEY") 또는 OpenSSH 형식.
+package group
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/golang-x-crypto/ed25519/cosi"
+)
+
+// PublicKey: JSON에서 hex 문자열로 인코딩되는 Ed25519 공개키
+type PublicKey ed25519.PublicKey
+
+func (k PublicKey) MarshalText() ([]byte, error) {
+	return []byte(hex.EncodeToString(k)), nil
+}
+
+func (k *PublicKey) UnmarshalText(text []byte) error {
+	b, err := hex.DecodeString(string(text))
+	if err != nil {
+		return fmt.Errorf("invalid hex key: %v", err)
+	}
+	if len(b) != ed25519.PublicKeySize {
+		return fmt.Errorf("bad public key length: %d", len(b))
+	}
+	*k = b
+	return nil
+}
+
+// Member: 그룹의 cosigner 하나
+type Member struct {
+	PublicKey PublicKey `json:"publicKey"`
+	Address   string    `json:"address,omitempty"` // cosigner 데몬의 host:port
+}
+
+// File: 그룹 파일 내용
+type File struct {
+	Cosigners []Member    `json:"cosigners"`
+	Leaders   []PublicKey `json:"leaders,omitempty"`   // cosigner가 받아들이는 leader
+	Threshold int         `json:"threshold,omitempty"` // 0이면 모든 cosigner 필요
+}
+
+// Load: 그룹 파일을 읽고 Validate로 검사
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("group file %s: %w", path, err)
+	}
+	return f, nil
+}
+
+// Parse: 그룹 파일 내용을 파싱하고 Validate로 검사
+func Parse(data []byte) (*File, error) {
+	var f File
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&f); err != nil {
+		return nil, err
+	}
+	if err := f.Validate(); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// Validate: cosigner가 한 명 이상이고, 키가 중복 없이 모두 곡선 위의 점이며,
+// threshold가 범위 안인지 확인
+func (f *File) Validate() error {
+	if len(f.Cosigners) == 0 {
+		return errors.New("no cosigners")
+	}
+	seen := make(map[string]int)
+	for i, m := range f.Cosigners {
+		if len(m.PublicKey) != ed25519.PublicKeySize {
+			return fmt.Errorf("cosigner %d: missing public key", i)
+		}
+		if j, ok := seen[string(m.PublicKey)]; ok {
+			return fmt.Errorf("cosigner %d: same public key as cosigner %d", i, j)
+		}
+		seen[string(m.PublicKey)] = i
+	}
+	if _, err := cosi.NewCosignersErr(f.PublicKeys(), nil); err != nil {
+		return err
+	}
+	if f.Threshold < 0 || f.Threshold > len(f.Cosigners) {
+		return fmt.Errorf("threshold %d out of range [0, %d]", f.Threshold, len(f.Cosigners))
+	}
+	return nil
+}
+
+// PublicKeys: cosigner 공개키 목록(그룹 순서)
+func (f *File) PublicKeys() []ed25519.PublicKey {
+	keys := make([]ed25519.PublicKey, len(f.Cosigners))
+	for i, m := range f.Cosigners {
+		keys[i] = ed25519.PublicKey(m.PublicKey)
+	}
+	return keys
+}
+
+// LeaderKeys: leader 공개키 목록
+func (f *File) LeaderKeys() []ed25519.PublicKey {
+	keys := make([]ed25519.PublicKey, len(f.Leaders))
+	for i, k := range f.Leaders {
+		keys[i] = ed25519.PublicKey(k)
+	}
+	return keys
+}
+
+// Index: pub을 키로 가진 cosigner의 번호, 없으면 -1
+func (f *File) Index(pub ed25519.PublicKey) int {
+	for i, m := range f.Cosigners {
+		if bytes.Equal(m.PublicKey, pub) {
+			return i
+		}
+	}
+	return -1
+}
+
+// NewCosigners: 검증용 cosi.Cosigners, threshold가 있으면 ThresholdPolicy 적용
+func (f *File) NewCosigners() *cosi.Cosigners {
+	cos := cosi.NewCosigners(f.PublicKeys(), nil)
+	if f.Threshold > 0 {
+		cos.SetPolicy(cosi.ThresholdPolicy(f.Threshold))
+	}
+	return cos
+}
+
+// Fingerprint: 그룹 fingerprint(cosi.Cosigners.Fingerprint)
+func (f *File) Fingerprint() []byte {
+	return cosi.NewCosigners(f.PublicKeys(), nil).Fingerprint()
+}
+
+// Marshal: 그룹 파일 내용(들여쓰기 된 JSON)
+func (f *File) Marshal() ([]byte, error) {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// LoadPrivateKey: PKCS #8 PEM 또는 OpenSSH 형식의 개인키 파일을 읽음
+func LoadPrivateKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	priv, err := ed25519.ParsePrivateKeyPEM(data)
+	if err != nil {
+		var sshErr error
+		if priv, sshErr = ed25519.ParseOpenSSHPrivateKey(data); sshErr != nil {
+			return nil, fmt.Errorf("key file %s: %v", path, err)
+		}
+	}
+	return priv, nil
+}
@@ -0,0 +1,134 @@
+package group
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/golang-x-crypto/ed25519/cosi"
+	"test-server/golang-x-crypto/ed25519/cosi/cositest"
+)
+
+func testFile(n int) *File {
+	f := &File{Threshold: n - 1}
+	for i := 0; i < n; i++ {
+		pub, _, _ := ed25519.GenerateKey(nil)
+		f.Cosigners = append(f.Cosigners, Member{PublicKey: PublicKey(pub), Address: "localhost:7000"})
+	}
+	leader, _, _ := ed25519.GenerateKey(nil)
+	f.Leaders = []PublicKey{PublicKey(leader)}
+	return f
+}
+
+func TestRoundTrip(t *testing.T) {
+	f := testFile(3)
+	data, err := f.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(data, []byte(hex.EncodeToString(f.Cosigners[1].PublicKey))) {
+		t.Errorf("keys are not hex encoded:\n%s", data)
+	}
+	g, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(g.Fingerprint(), f.Fingerprint()) || g.Threshold != 2 || len(g.Leaders) != 1 {
+		t.Errorf("Parse(Marshal(f)) differs from f")
+	}
+	if i := g.Index(ed25519.PublicKey(f.Cosigners[2].PublicKey)); i != 2 {
+		t.Errorf("Index = %d, want 2", i)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		edit    func(*File)
+		wantErr string
+	}{
+		{"empty", func(f *File) { f.Cosigners = nil }, "no cosigners"},
+		{"duplicate", func(f *File) { f.Cosigners[2] = f.Cosigners[0] }, "same public key"},
+		{"threshold", func(f *File) { f.Threshold = 4 }, "threshold"},
+		{"not a point", func(f *File) { f.Cosigners[1].PublicKey = make(PublicKey, 32); f.Cosigners[1].PublicKey[0] = 2 }, "not a valid curve point"},
+	} {
+		f := testFile(3)
+		tt.edit(f)
+		if err := f.Validate(); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: Validate() = %v, want error containing %q", tt.name, err, tt.wantErr)
+		}
+	}
+	if _, err := Parse([]byte(`{"cosigners": [{"publicKey": "abcd"}]}`)); err == nil {
+		t.Errorf("short key accepted")
+	}
+}
+
+func TestLoadPrivateKey(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(nil)
+	pemKey, err := ed25519.MarshalPrivateKeyPEM(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sshKey, err := ed25519.MarshalOpenSSHPrivateKey(priv, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	for name, data := range map[string][]byte{"key.pem": pemKey, "id_ed25519": sshKey} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		got, err := LoadPrivateKey(path)
+		if err != nil || !got.Equal(priv) {
+			t.Errorf("LoadPrivateKey(%s) = %v", name, err)
+		}
+	}
+}
+
+func TestRotation(t *testing.T) {
+	nw, err := cositest.NewNetwork(3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nw.Close()
+	nw.Leader.Threshold = 1
+	cur := &File{Threshold: 2}
+	for _, pub := range nw.PublicKeys {
+		cur.Cosigners = append(cur.Cosigners, Member{PublicKey: PublicKey(pub)})
+	}
+	next := &File{Cosigners: cur.Cosigners[:2], Threshold: 2}
+	message, err := RotationMessage(cur.Fingerprint(), next)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := nw.SignWithMask(context.Background(), message, cositest.Mask(3, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cur.VerifyRotation(next, res.Signature, nil); err != nil {
+		t.Errorf("2 of 3 endorsement: %v", err)
+	}
+	if err := next.VerifyRotation(next, res.Signature, nil); err == nil {
+		t.Errorf("endorsement accepted for another current group")
+	}
+	other := &File{Cosigners: cur.Cosigners[1:], Threshold: 2}
+	if err := cur.VerifyRotation(other, res.Signature, nil); err == nil {
+		t.Errorf("endorsement accepted for another next group")
+	}
+
+	res, err = nw.SignWithMask(context.Background(), message, cositest.Mask(3, 1, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cur.VerifyRotation(next, res.Signature, nil); !errors.Is(err, cosi.ErrPolicy) {
+		t.Errorf("1 of 3 endorsement: error = %v, want cosi.ErrPolicy", err)
+	}
+}
@@ -0,0 +1,52 @@
+package group
+
+import (
+	"fmt"
+
+	"test-server/golang-x-crypto/ed25519/cosi"
+)
+
+// rotation.go: 그룹 변경(rotation) 승인
+//
+// 그룹 파일을 바꿀 때는 지금 그룹이 새 그룹 파일에 collective signature로 서명(endorsement)하고,
+// 각 노드는 자기가 실행 중인 그룹으로 그 서명을 검증한 뒤에만 새 파일을 받아들인다.
+// 서명하는 메시지(RotationMessage)는
+//
+//	"cosi-group-rotation-v1\n" || 지금 그룹 fingerprint || 새 그룹 파일(Marshal)
+//
+// 이므로 서명은 정확히 그 그룹에서 그 파일로의 변경에만 쓸 수 있다.
+
+// RotationContentType: endorsement 라운드의 content-type 메타데이터
+// cosigner의 -content-types나 승인 규칙에서 그룹 변경 서명을 구분하는 데 쓴다.
+const RotationContentType = "application/vnd.cosi.group-rotation+json"
+
+const rotationDomain = "cosi-group-rotation-v1\n"
+
+// RotationMessage: fingerprint가 from인 그룹이 next로 바뀌는 것을 승인하는 서명 메시지
+func RotationMessage(from []byte, next *File) ([]byte, error) {
+	data, err := next.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	m := make([]byte, 0, len(rotationDomain)+len(from)+len(data))
+	m = append(m, rotationDomain...)
+	m = append(m, from...)
+	return append(m, data...), nil
+}
+
+// VerifyRotation: sig가 f(지금 그룹)의 next 승인 서명인지
+// f의 threshold(0이면 모든 cosigner)를 만족해야 하며,
+// bound 라운드에서 만든 서명이면 binding도 넘겨야 한다(cosi.VerifyBound).
+func (f *File) VerifyRotation(next *File, sig []byte, binding *cosi.Binding) error {
+	message, err := RotationMessage(f.Fingerprint(), next)
+	if err != nil {
+		return err
+	}
+	if binding != nil {
+		message = binding.Message(message)
+	}
+	if err := f.NewCosigners().VerifyErr(message, sig); err != nil {
+		return fmt.Errorf("group rotation not endorsed by the current group: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+// Package health: 데몬의 상태 점검 HTTP 엔드포인트
+//
+// Checker에 등록한 Check를 요청마다 병렬로 실행하고 결과를 JSON으로 보고한다.
+//
+//	GET /healthz  Liveness 점검만 실패 여부에 반영 (실패: 재시작이 필요한 상태)
+//	GET /readyz   모든 점검이 통과해야 200 (실패: 트래픽을 보내면 안 되는 상태)
+//
+// 두 엔드포인트 모두 모든 점검의 결과를 본문에 담으며,
+// 실패하면 503 Service Unavailable로 응답한다.
+//
+//	{
+//	  "status": "fail",
+//	  "checks": {
+//	    "key":        {"status": "ok"},
+//	    "group":      {"status": "ok", "detail": "fingerprint 9f86d081..."},
+//	    "peers":      {"status": "fail", "error": "1 of 3 cosigners reachable, need 2"},
+//	    "last-round": {"status": "ok", "detail": "12s ago"}
+//	  }
+//	}
+package health
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/group"
+)
+
+// DefaultTimeout: Checker.Timeout 기본값
+const DefaultTimeout = 5 * time.Second
+
+// Check: 점검 하나
+// Run은 사람이 읽을 부가 정보(없으면 "")와, 실패하면 에러를 반환한다.
+type Check struct {
+	Name     string
+	Liveness bool // 실패하면 /healthz도 실패
+	Run      func(ctx context.Context) (detail string, err error)
+}
+
+// Result: 점검 하나의 결과
+type Result struct {
+	Status string `json:"status"` // "ok" 또는 "fail"
+	Detail string `json:"detail,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Report: 엔드포인트 응답 본문
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+// Checker: 등록된 점검을 실행하는 /healthz, /readyz 핸들러
+type Checker struct {
+	Checks  []Check
+	Timeout time.Duration // 요청 하나의 점검 제한 시간, 0이면 DefaultTimeout
+}
+
+// Register: mux에 GET /healthz, GET /readyz 등록
+func (c *Checker) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) { c.serve(w, r, true) })
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) { c.serve(w, r, false) })
+}
+
+// Handler: /healthz, /readyz만 제공하는 핸들러 (별도 listen 주소용)
+func (c *Checker) Handler() http.Handler {
+	mux := http.NewServeMux()
+	c.Register(mux)
+	return mux
+}
+
+func (c *Checker) serve(w http.ResponseWriter, r *http.Request, liveness bool) {
+	report := c.Run(r.Context(), liveness)
+	status := http.StatusOK
+	if report.Status != "ok" {
+		status = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(report)
+}
+
+// Run: 모든 점검을 병렬로 실행
+// liveness가 true면 Liveness 점검의 실패만 전체 상태에 반영
+func (c *Checker) Run(ctx context.Context, liveness bool) *Report {
+	timeout := c.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	results := make([]Result, len(c.Checks))
+	var wg sync.WaitGroup
+	for i, check := range c.Checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			detail, err := check.Run(ctx)
+			results[i] = Result{Status: "ok", Detail: detail}
+			if err != nil {
+				results[i].Status, results[i].Error = "fail", err.Error()
+			}
+		}()
+	}
+	wg.Wait()
+
+	report := &Report{Status: "ok", Checks: make(map[string]Result, len(c.Checks))}
+	for i, check := range c.Checks {
+		report.Checks[check.Name] = results[i]
+		if results[i].Status != "ok" && (check.Liveness || !liveness) {
+			report.Status = "fail"
+		}
+	}
+	return report
+}
+
+// Key: 메모리의 개인키로 서명·검증이 되는지 (Liveness)
+func Key(priv ed25519.PrivateKey) Check {
+	return Check{Name: "key", Liveness: true, Run: func(context.Context) (string, error) {
+		probe := []byte("health probe")
+		if !ed25519.Verify(priv.Public().(ed25519.PublicKey), probe, ed25519.Sign(priv, probe)) {
+			return "", errors.New("loaded private key does not produce valid signatures")
+		}
+		return "", nil
+	}}
+}
+
+// KeyFile: 키 파일을 여전히 읽을 수 있고 실행 중인 키와 같은지
+// 재시작하면 다른 키로(또는 시작하지 못하고) 뜨게 되는 상태를 미리 알린다.
+func KeyFile(path string, pub ed25519.PublicKey) Check {
+	return Check{Name: "key-file", Run: func(context.Context) (string, error) {
+		priv, err := group.LoadPrivateKey(path)
+		if err != nil {
+			return "", err
+		}
+		if !bytes.Equal(priv.Public().(ed25519.PublicKey), pub) {
+			return "", fmt.Errorf("%s no longer holds the running key", path)
+		}
+		return "", nil
+	}}
+}
+
+// Group: 그룹 파일이 유효하고 fingerprint가 실행 중인 그룹(fingerprint())과 같은지
+// 그룹을 다시 읽는 데몬에서는 파일을 바꾼 뒤 재적재가 반영될 때까지 실패한다.
+func Group(path string, fingerprint func() []byte) Check {
+	return Check{Name: "group", Run: func(context.Context) (string, error) {
+		g, err := group.Load(path)
+		if err != nil {
+			return "", err
+		}
+		running := fingerprint()
+		if got := g.Fingerprint(); !bytes.Equal(got, running) {
+			return "", fmt.Errorf("%s changed: fingerprint %x, running %x", path, got, running)
+		}
+		return "fingerprint " + hex.EncodeToString(running), nil
+	}}
+}
+
+// LastRound: 마지막으로 성공한 서명 라운드 시각
+// 아직 성공한 라운드가 없으면 NewLastRound 호출 시각부터 잰다.
+type LastRound struct {
+	start time.Time
+	last  atomic.Int64 // UnixNano, 0이면 없음
+}
+
+func NewLastRound() *LastRound {
+	return &LastRound{start: time.Now()}
+}
+
+// Record: 라운드 성공 기록
+func (l *LastRound) Record() {
+	l.last.Store(time.Now().UnixNano())
+}
+
+// Age: 마지막 성공 라운드 이후 시간, 없으면 시작 이후 시간과 false
+func (l *LastRound) Age() (time.Duration, bool) {
+	if ns := l.last.Load(); ns != 0 {
+		return time.Since(time.Unix(0, ns)), true
+	}
+	return time.Since(l.start), false
+}
+
+// Check: 마지막 성공 라운드가 maxAge보다 오래되지 않았는지, maxAge가 0이면 보고만 함
+func (l *LastRound) Check(maxAge time.Duration) Check {
+	return Check{Name: "last-round", Run: func(context.Context) (string, error) {
+		age, ok := l.Age()
+		age = age.Round(time.Millisecond)
+		detail := age.String() + " ago"
+		if !ok {
+			detail = "none since start " + age.String() + " ago"
+		}
+		if maxAge > 0 && age > maxAge {
+			return detail, fmt.Errorf("no successful round for %v (max %v)", age, maxAge)
+		}
+		return detail, nil
+	}}
+}
@@ -0,0 +1,127 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/group"
+)
+
+func get(t *testing.T, h http.Handler, path string) (int, *Report) {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+	var report Report
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+		t.Fatal(err)
+	}
+	return rec.Code, &report
+}
+
+func TestEndpoints(t *testing.T) {
+	var ready error
+	c := &Checker{Checks: []Check{
+		{Name: "alive", Liveness: true, Run: func(context.Context) (string, error) { return "fine", nil }},
+		{Name: "ready", Run: func(context.Context) (string, error) { return "", ready }},
+	}}
+	h := c.Handler()
+	for _, path := range []string{"/healthz", "/readyz"} {
+		if code, report := get(t, h, path); code != http.StatusOK || report.Checks["alive"].Detail != "fine" {
+			t.Errorf("%s: %d %+v", path, code, report)
+		}
+	}
+
+	// readiness 점검 실패는 /readyz에만 반영
+	ready = errors.New("peers unreachable")
+	if code, _ := get(t, h, "/healthz"); code != http.StatusOK {
+		t.Errorf("/healthz with a failing readiness check: %d", code)
+	}
+	code, report := get(t, h, "/readyz")
+	if code != http.StatusServiceUnavailable || report.Status != "fail" || report.Checks["ready"].Error != "peers unreachable" {
+		t.Errorf("/readyz: %d %+v", code, report)
+	}
+
+	c.Checks[0].Run = func(context.Context) (string, error) { return "", errors.New("dead") }
+	if code, _ := get(t, h, "/healthz"); code != http.StatusServiceUnavailable {
+		t.Errorf("/healthz with a failing liveness check: %d", code)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	c := &Checker{Timeout: 10 * time.Millisecond, Checks: []Check{
+		{Name: "slow", Run: func(ctx context.Context) (string, error) {
+			<-ctx.Done()
+			return "", ctx.Err()
+		}},
+	}}
+	if report := c.Run(context.Background(), false); report.Status != "fail" {
+		t.Errorf("slow check passed: %+v", report)
+	}
+}
+
+func TestFileChecks(t *testing.T) {
+	dir := t.TempDir()
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	keyPath := filepath.Join(dir, "key.pem")
+	data, _ := ed25519.MarshalPrivateKeyPEM(priv)
+	if err := os.WriteFile(keyPath, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	f := &group.File{Cosigners: []group.Member{{PublicKey: group.PublicKey(pub)}}}
+	groupPath := filepath.Join(dir, "group.json")
+	data, _ = f.Marshal()
+	if err := os.WriteFile(groupPath, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	fingerprint := f.Fingerprint()
+	ctx := context.Background()
+	checks := []Check{Key(priv), KeyFile(keyPath, pub), Group(groupPath, func() []byte { return fingerprint })}
+	for _, c := range checks {
+		if _, err := c.Run(ctx); err != nil {
+			t.Errorf("%s: %v", c.Name, err)
+		}
+	}
+
+	// 다른 키와 다른 그룹으로 바뀐 파일
+	other, otherPriv, _ := ed25519.GenerateKey(nil)
+	data, _ = ed25519.MarshalPrivateKeyPEM(otherPriv)
+	os.WriteFile(keyPath, data, 0o600)
+	f.Cosigners = append(f.Cosigners, group.Member{PublicKey: group.PublicKey(other)})
+	data, _ = f.Marshal()
+	os.WriteFile(groupPath, data, 0o644)
+	for _, c := range checks[1:] {
+		if _, err := c.Run(ctx); err == nil {
+			t.Errorf("%s: changed file passed", c.Name)
+		}
+	}
+}
+
+func TestLastRound(t *testing.T) {
+	l := NewLastRound()
+	check := l.Check(20 * time.Millisecond)
+	if _, err := check.Run(context.Background()); err != nil {
+		t.Errorf("fresh start: %v", err)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if _, err := check.Run(context.Background()); err == nil {
+		t.Errorf("no round for longer than max age: check passed")
+	}
+	l.Record()
+	if age, ok := l.Age(); !ok || age > 20*time.Millisecond {
+		t.Errorf("Age() = %v, %v after Record", age, ok)
+	}
+	if _, err := check.Run(context.Background()); err != nil {
+		t.Errorf("after Record: %v", err)
+	}
+	if _, err := l.Check(0).Run(context.Background()); err != nil {
+		t.Errorf("max age 0: %v", err)
+	}
+}
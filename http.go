@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"test-server/golang-x-crypto/ed25519"
+)
+
+// http.go에는 통합 테스트용 JSON HTTP API만 구현
+// 모든 바이트 값(키, 메시지, 서명)은 hex 문자열로 주고받는다.
+
+const (
+	httpAddr        = ":8080"
+	maxHTTPBodySize = 1 << 20 // 요청 본문 최대 크기(1MiB)
+)
+
+// hexBytes: JSON에서 hex 문자열로 인코딩되는 바이트열
+type hexBytes []byte
+
+func (b hexBytes) MarshalJSON() ([]byte, error) {
+	return json.Marshal(hex.EncodeToString(b))
+}
+
+func (b *hexBytes) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return errors.New("expected a hex string")
+	}
+	v, err := hex.DecodeString(s)
+	if err != nil {
+		return fmt.Errorf("invalid hex string: %v", err)
+	}
+	*b = v
+	return nil
+}
+
+type keysRequest struct {
+	Seed hexBytes `json:"seed,omitempty"` // 생략 시 무작위 키 생성
+}
+
+type keysResponse struct {
+	PublicKey  hexBytes `json:"publicKey"`
+	PrivateKey hexBytes `json:"privateKey"`
+}
+
+type signRequest struct {
+	PrivateKey hexBytes `json:"privateKey"`
+	Message    hexBytes `json:"message"`
+	Context    string   `json:"context,omitempty"` // 지정 시 Ed25519ctx
+}
+
+type signResponse struct {
+	Signature hexBytes `json:"signature"`
+}
+
+type verifyRequest struct {
+	PublicKey hexBytes `json:"publicKey"`
+	Message   hexBytes `json:"message"`
+	Signature hexBytes `json:"signature"`
+	Context   string   `json:"context,omitempty"`
+}
+
+type cosiVerifyRequest struct {
+	PublicKeys []hexBytes `json:"publicKeys"`
+	Message    hexBytes   `json:"message"`
+	Signature  hexBytes   `json:"signature"` // R || s || mask
+	Threshold  int        `json:"threshold"` // 0이면 모든 cosigner의 서명 필요
+}
+
+type verifyResponse struct {
+	Valid bool   `json:"valid"`
+	Error string `json:"error,omitempty"` // 검증 실패 사유
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// newHTTPHandler: /v1 API 라우팅
+func newHTTPHandler(logger *slog.Logger) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/keys", handle(logger, keys))
+	mux.HandleFunc("POST /v1/sign", handle(logger, sign))
+	mux.HandleFunc("POST /v1/verify", handle(logger, verify))
+	mux.HandleFunc("POST /v1/cosi/verify", handle(logger, cosiVerify))
+	return mux
+}
+
+// handle: JSON 요청 디코딩 → f 호출 → JSON 응답 인코딩
+// f가 반환한 에러는 요청 오류로 보고 400으로 응답한다.
+func handle[Req, Resp any](logger *slog.Logger, f func(*Req) (*Resp, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req Req
+		dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxHTTPBodySize))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, &errorResponse{"invalid request body: " + err.Error()})
+			return
+		}
+		resp, err := f(&req)
+		if err != nil {
+			logger.Debug("http request rejected", "path", r.URL.Path, "err", err)
+			writeJSON(w, http.StatusBadRequest, &errorResponse{err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func keys(req *keysRequest) (*keysResponse, error) {
+	if req.Seed != nil {
+		priv, err := ed25519.NewKeyFromSeedErr(req.Seed)
+		if err != nil {
+			return nil, err
+		}
+		return &keysResponse{hexBytes(priv.Public().(ed25519.PublicKey)), hexBytes(priv)}, nil
+	}
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		return nil, err
+	}
+	return &keysResponse{hexBytes(pub), hexBytes(priv)}, nil
+}
+
+func sign(req *signRequest) (*signResponse, error) {
+	priv := ed25519.PrivateKey(req.PrivateKey)
+	if len(priv) != ed25519.PrivateKeySize {
+		return nil, ed25519.ErrPrivateKeySize
+	}
+	sig, err := priv.Sign(nil, req.Message, &ed25519.Options{Context: req.Context})
+	if err != nil {
+		return nil, err
+	}
+	return &signResponse{sig}, nil
+}
+
+// 서명이 틀린 경우는 요청 오류가 아니므로 200과 valid=false로 응답
+func verify(req *verifyRequest) (*verifyResponse, error) {
+	if err := checkPublicKey(req.PublicKey); err != nil {
+		return nil, err
+	}
+	err := ed25519.VerifyWithOptions(ed25519.PublicKey(req.PublicKey), req.Message, req.Signature,
+		&ed25519.Options{Context: req.Context})
+	return newVerifyResponse(err), nil
+}
+
+func cosiVerify(req *cosiVerifyRequest) (*verifyResponse, error) {
+	pubKeys := make([]ed25519.PublicKey, len(req.PublicKeys))
+	for i, pk := range req.PublicKeys {
+		pubKeys[i] = ed25519.PublicKey(pk)
+	}
+	cosigners, err := newCosigners(pubKeys, req.Threshold)
+	if err != nil {
+		return nil, err
+	}
+	return newVerifyResponse(cosigners.VerifyErr(req.Message, req.Signature)), nil
+}
+
+func newVerifyResponse(err error) *verifyResponse {
+	if err != nil {
+		return &verifyResponse{Valid: false, Error: err.Error()}
+	}
+	return &verifyResponse{Valid: true}
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/golang-x-crypto/ed25519/cosi"
+	"test-server/golang-x-crypto/ed25519/cosi/cositest"
+)
+
+// postJSON: body를 path로 POST하고 상태 코드를 반환, v가 있으면 응답 본문을 디코딩
+func postJSON(t *testing.T, h http.Handler, path, body string, v any) int {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("POST", path, strings.NewReader(body)))
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("%s: Content-Type %q", path, ct)
+	}
+	if v != nil {
+		if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+			t.Fatalf("%s: %v: %s", path, err, rec.Body.Bytes())
+		}
+	}
+	return rec.Code
+}
+
+// jsonBody: 요청 본문 생성 ([]byte 값은 hexBytes로 인코딩)
+func jsonBody(t *testing.T, v any) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func newTestHTTPHandler() http.Handler {
+	return newHTTPHandler(slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestHTTPKeys(t *testing.T) {
+	h := newTestHTTPHandler()
+	seed := bytes.Repeat([]byte{7}, ed25519.SeedSize)
+	want := ed25519.NewKeyFromSeed(seed)
+
+	var keys keysResponse
+	if code := postJSON(t, h, "/v1/keys", jsonBody(t, &keysRequest{Seed: seed}), &keys); code != http.StatusOK {
+		t.Fatalf("keys from seed: status %d", code)
+	}
+	if !bytes.Equal(keys.PrivateKey, want) || !bytes.Equal(keys.PublicKey, want.Public().(ed25519.PublicKey)) {
+		t.Errorf("keys from seed: got %x", keys.PublicKey)
+	}
+
+	var random keysResponse
+	if code := postJSON(t, h, "/v1/keys", `{}`, &random); code != http.StatusOK {
+		t.Fatalf("random keys: status %d", code)
+	}
+	if len(random.PublicKey) != ed25519.PublicKeySize || len(random.PrivateKey) != ed25519.PrivateKeySize {
+		t.Errorf("random keys: %d-byte public key, %d-byte private key", len(random.PublicKey), len(random.PrivateKey))
+	}
+
+	var e errorResponse
+	if code := postJSON(t, h, "/v1/keys", `{"seed": "0102"}`, &e); code != http.StatusBadRequest || e.Error == "" {
+		t.Errorf("short seed: status %d, error %q", code, e.Error)
+	}
+}
+
+func TestHTTPSignVerify(t *testing.T) {
+	h := newTestHTTPHandler()
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	message := []byte("test message")
+
+	var s signResponse
+	body := jsonBody(t, &signRequest{PrivateKey: hexBytes(priv), Message: message, Context: "ctx"})
+	if code := postJSON(t, h, "/v1/sign", body, &s); code != http.StatusOK {
+		t.Fatalf("sign: status %d", code)
+	}
+	if err := ed25519.VerifyWithOptions(pub, message, s.Signature, &ed25519.Options{Context: "ctx"}); err != nil {
+		t.Errorf("signature from /v1/sign: %v", err)
+	}
+
+	wrong := append([]byte{}, s.Signature...)
+	wrong[0] ^= 1
+	for _, tt := range []struct {
+		name  string
+		req   verifyRequest
+		valid bool
+	}{
+		{"valid", verifyRequest{PublicKey: hexBytes(pub), Message: message, Signature: s.Signature, Context: "ctx"}, true},
+		{"bad signature", verifyRequest{PublicKey: hexBytes(pub), Message: message, Signature: wrong, Context: "ctx"}, false},
+		{"other context", verifyRequest{PublicKey: hexBytes(pub), Message: message, Signature: s.Signature}, false},
+		{"short signature", verifyRequest{PublicKey: hexBytes(pub), Message: message, Signature: s.Signature[:10], Context: "ctx"}, false},
+	} {
+		var v verifyResponse
+		if code := postJSON(t, h, "/v1/verify", jsonBody(t, &tt.req), &v); code != http.StatusOK {
+			t.Errorf("%s: status %d", tt.name, code)
+		}
+		if v.Valid != tt.valid || (v.Error != "") == tt.valid {
+			t.Errorf("%s: got %+v, want valid=%v", tt.name, v, tt.valid)
+		}
+	}
+}
+
+func TestHTTPBadRequest(t *testing.T) {
+	h := newTestHTTPHandler()
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	huge := `{"privateKey": "` + hex.EncodeToString(priv) + `", "message": "` +
+		strings.Repeat("00", maxHTTPBodySize/2) + `"}`
+
+	for _, tt := range []struct {
+		name, path, body string
+		want             string // 에러 메시지에 포함되어야 할 문자열
+	}{
+		{"sign with short key", "/v1/sign", jsonBody(t, &signRequest{PrivateKey: hexBytes(priv[:32]), Message: []byte("m")}), "private key"},
+		{"verify with short key", "/v1/verify", jsonBody(t, &verifyRequest{PublicKey: hexBytes(pub[:31]), Message: []byte("m")}), "bad public key length: 31"},
+		{"verify with long key", "/v1/verify", jsonBody(t, &verifyRequest{PublicKey: hexBytes(priv), Message: []byte("m")}), "bad public key length: 64"},
+		{"invalid hex", "/v1/sign", `{"privateKey": "zz", "message": ""}`, "invalid hex string"},
+		{"non-string bytes", "/v1/sign", `{"privateKey": 1, "message": ""}`, "expected a hex string"},
+		{"unknown field", "/v1/sign", `{"privateKey": "` + hex.EncodeToString(priv) + `", "message": "", "hash": "sha512"}`, `unknown field "hash"`},
+		{"malformed JSON", "/v1/verify", `{"publicKey": `, "invalid request body"},
+		{"body too large", "/v1/sign", huge, "request body too large"},
+	} {
+		var e errorResponse
+		if code := postJSON(t, h, tt.path, tt.body, &e); code != http.StatusBadRequest || !strings.Contains(e.Error, tt.want) {
+			t.Errorf("%s: status %d, error %q, want 400 with %q", tt.name, code, e.Error, tt.want)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/v1/sign", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET /v1/sign: status %d", rec.Code)
+	}
+}
+
+func TestHTTPCosiVerify(t *testing.T) {
+	h := newTestHTTPHandler()
+	nw, err := cositest.NewNetwork(3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nw.Close()
+	nw.Leader.Threshold = 2
+	nw.SetOnline(2, false)
+	message := []byte("test message")
+	res, err := nw.Sign(context.Background(), message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := make([]hexBytes, len(nw.PublicKeys))
+	for i, pk := range nw.PublicKeys {
+		keys[i] = hexBytes(pk)
+	}
+
+	for _, tt := range []struct {
+		name      string
+		message   []byte
+		threshold int
+		err       error // valid=false일 때의 사유
+	}{
+		{"threshold met", message, 2, nil},
+		{"all cosigners required", message, 0, cosi.ErrPolicy},
+		{"different message", []byte("other message"), 2, cosi.ErrInvalidSignature},
+	} {
+		var v verifyResponse
+		body := jsonBody(t, &cosiVerifyRequest{PublicKeys: keys, Message: tt.message, Signature: res.Signature, Threshold: tt.threshold})
+		if code := postJSON(t, h, "/v1/cosi/verify", body, &v); code != http.StatusOK {
+			t.Errorf("%s: status %d", tt.name, code)
+		}
+		if tt.err == nil && (!v.Valid || v.Error != "") {
+			t.Errorf("%s: got %+v, want valid", tt.name, v)
+		}
+		if tt.err != nil && (v.Valid || v.Error != tt.err.Error()) {
+			t.Errorf("%s: got %+v, want error %q", tt.name, v, tt.err)
+		}
+	}
+
+	for _, tt := range []struct {
+		name      string
+		keys      []hexBytes
+		threshold int
+	}{
+		{"no keys", nil, 0},
+		{"short key", []hexBytes{keys[0], keys[1][:31], keys[2]}, 2},
+		{"threshold too high", keys, 4},
+		{"negative threshold", keys, -1},
+	} {
+		var e errorResponse
+		body := jsonBody(t, &cosiVerifyRequest{PublicKeys: tt.keys, Message: message, Signature: res.Signature, Threshold: tt.threshold})
+		if code := postJSON(t, h, "/v1/cosi/verify", body, &e); code != http.StatusBadRequest || e.Error == "" {
+			t.Errorf("%s: status %d, error %q", tt.name, code, e.Error)
+		}
+	}
+}
@@ -2,7 +2,9 @@ package main
 
 import (
 	"log"
+	"log/slog"
 	"net"
+	"net/http"
 	pb "test-server/proto_interface"
 
 	"google.golang.org/grpc"
@@ -13,11 +15,20 @@ func main() {
 
 	s := grpc.NewServer()
 	pb.RegisterMeshServer(s, srv)
+	pb.RegisterSignerServer(s, &signerSrv{})
 
 	lis, err := net.Listen("tcp", ":50051")
 	if err != nil {
 		log.Fatalf("listen: %v", err)
 	}
+	// 통합 테스트용 HTTP API
+	go func() {
+		log.Printf("HTTP API server %s start", httpAddr)
+		if err := http.ListenAndServe(httpAddr, newHTTPHandler(slog.Default())); err != nil {
+			log.Fatalf("http serve: %v", err)
+		}
+	}()
+
 	log.Println("CEF gRPC server :50051 start")
 	if err := s.Serve(lis); err != nil {
 		log.Fatalf("serve: %v", err)
@@ -2,13 +2,14 @@ package main
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"sync"
 	"time"
 
 	pb "test-server/proto_interface"
 
 	"test-server/golang-x-crypto/ed25519/cosi"
+	"test-server/golang-x-crypto/ed25519/cosi/logging"
 )
 
 // network.go에는 grpc RPC구현에 관련된 내용만
@@ -23,6 +24,7 @@ type meshSrv struct {
 	committeeCandidates map[uint64][]*pb.CommitteeCandidateInfo // 후보자 정보
 	commitData          map[uint64][]*pb.CommitData             // 커미티 정보
 	cosigners           *cosi.Cosigners                         // cosi 집계용, requestCommittee호출 시 반드시 초기화
+	logger              logging.Logger                          // 라운드 번호를 상관 ID로 붙여 기록
 }
 
 var (
@@ -34,7 +36,8 @@ const maxWait = 10 * time.Second
 
 func newMeshSrv() *meshSrv {
 	return &meshSrv{
-		subs: make(map[string]chan *pb.FinalizedCommittee),
+		subs:   make(map[string]chan *pb.FinalizedCommittee),
+		logger: slog.Default(),
 	}
 }
 
@@ -50,7 +53,7 @@ func (m *meshSrv) JoinNetwork(
 	m.subs[nodeID] = ch
 	m.mu.Unlock()
 
-	log.Printf("node %s joined", nodeID)
+	m.logger.Info("node joined", "node", nodeID)
 
 	// 스트림 종료 시 정리
 	defer func() {
@@ -59,7 +62,7 @@ func (m *meshSrv) JoinNetwork(
 		m.mu.Unlock()
 		close(ch)
 		m.processed = make(map[uint64]bool)
-		log.Printf("node %s left", nodeID)
+		m.logger.Info("node left", "node", nodeID)
 	}()
 
 	// 채널에 오는 메시지를 계속 스트림으로 밀어줌
@@ -143,6 +146,7 @@ func (m *meshSrv) RequestAggregatedCommit(
 	}
 
 	aggCommit := m.cosigners.AggregateCommit(recvPartCommit)
+	m.logger.Info("aggregate commit broadcast", "round", cd.Round, "commits", len(recvPartCommit))
 	m.broadcast(&pb.FinalizedCommittee{
 		Round:            cd.Round,
 		AggregatedCommit: aggCommit,
@@ -1,7 +1,7 @@
 package main
 
 import (
-	"log"
+	"encoding/hex"
 	pb "test-server/proto_interface"
 	"time"
 
@@ -36,7 +36,8 @@ func (m *meshSrv) appendCandidate(cci *pb.CommitteeCandidateInfo) bool {
 
 	if _, ok := m.timers[r]; !ok {
 		m.timers[r] = time.AfterFunc(maxWait, func() {
-			log.Println("timeout! begin to make commmittee!")
+			m.logger.Warn("candidate wait timed out, finalizing committee",
+				"round", r, "candidates", len(m.committeeCandidates[r]))
 			m.tryFinalize(r)
 		})
 	}
@@ -77,22 +78,21 @@ func (m *meshSrv) tryFinalize(round uint64) {
 		recvPartCommit = append(recvPartCommit, c.Commit)
 		nodeIds = append(nodeIds, c.NodeId)
 		//mu.Unlock()
-		log.Printf("recvPartPubKey: %x, recvPartCommit: %x || ",
-			c.PublicKey, c.Commit)
+		m.logger.Debug("committee member", "round", round, "node", c.NodeId,
+			"pubkey", hex.EncodeToString(c.PublicKey), "commit", hex.EncodeToString(c.Commit))
 	}
 
 	var pubKeys [][]byte
 	for _, pk := range recvPartPubKey {
 		pubKeys = append(pubKeys, pk)
 	}
-	log.Println("[recvPartPubKey]:", recvPartPubKey)
-	log.Println("[recvPartCommit]:", recvPartCommit)
-	log.Println("[pubKeys]:", pubKeys)
 
 	//aggPubKey, aggCommit := aggregatePubKey(recvPartPubKey)
 	m.cosigners = cosi.NewCosigners(recvPartPubKey, nil)
 	aggPubKey := m.cosigners.AggregatePublicKey()
 	aggCommit := m.cosigners.AggregateCommit(recvPartCommit)
+	m.logger.Info("committee finalized", "round", round, "members", len(nodeIds),
+		"aggpubkey", hex.EncodeToString(aggPubKey))
 
 	// ③ 브로드캐스트
 	m.broadcast(&pb.FinalizedCommittee{
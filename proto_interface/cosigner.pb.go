@@ -0,0 +1,507 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: cosigner.proto
+
+package proto_interface
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type CosiCommitRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     []byte                 `protobuf:"bytes,1,opt,name=sessionId,proto3" json:"sessionId,omitempty"`
+	Message       []byte                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Leader        string                 `protobuf:"bytes,3,opt,name=leader,proto3" json:"leader,omitempty"` // 인증된 transport에서는 서버가 peer 키로 덮어씀
+	Term          uint64                 `protobuf:"varint,4,opt,name=term,proto3" json:"term,omitempty"`
+	Bound         bool                   `protobuf:"varint,5,opt,name=bound,proto3" json:"bound,omitempty"`
+	Metadata      map[string]string      `protobuf:"bytes,6,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	TraceContext  string                 `protobuf:"bytes,7,opt,name=traceContext,proto3" json:"traceContext,omitempty"`
+	Group         []byte                 `protobuf:"bytes,8,opt,name=group,proto3" json:"group,omitempty"` // 라운드를 연 그룹의 fingerprint
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CosiCommitRequest) Reset() {
+	*x = CosiCommitRequest{}
+	mi := &file_cosigner_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CosiCommitRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CosiCommitRequest) ProtoMessage() {}
+
+func (x *CosiCommitRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cosigner_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CosiCommitRequest.ProtoReflect.Descriptor instead.
+func (*CosiCommitRequest) Descriptor() ([]byte, []int) {
+	return file_cosigner_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CosiCommitRequest) GetSessionId() []byte {
+	if x != nil {
+		return x.SessionId
+	}
+	return nil
+}
+
+func (x *CosiCommitRequest) GetMessage() []byte {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+func (x *CosiCommitRequest) GetLeader() string {
+	if x != nil {
+		return x.Leader
+	}
+	return ""
+}
+
+func (x *CosiCommitRequest) GetTerm() uint64 {
+	if x != nil {
+		return x.Term
+	}
+	return 0
+}
+
+func (x *CosiCommitRequest) GetBound() bool {
+	if x != nil {
+		return x.Bound
+	}
+	return false
+}
+
+func (x *CosiCommitRequest) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *CosiCommitRequest) GetTraceContext() string {
+	if x != nil {
+		return x.TraceContext
+	}
+	return ""
+}
+
+func (x *CosiCommitRequest) GetGroup() []byte {
+	if x != nil {
+		return x.Group
+	}
+	return nil
+}
+
+type CosiCommitResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Commit        []byte                 `protobuf:"bytes,1,opt,name=commit,proto3" json:"commit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CosiCommitResponse) Reset() {
+	*x = CosiCommitResponse{}
+	mi := &file_cosigner_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CosiCommitResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CosiCommitResponse) ProtoMessage() {}
+
+func (x *CosiCommitResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cosigner_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CosiCommitResponse.ProtoReflect.Descriptor instead.
+func (*CosiCommitResponse) Descriptor() ([]byte, []int) {
+	return file_cosigner_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CosiCommitResponse) GetCommit() []byte {
+	if x != nil {
+		return x.Commit
+	}
+	return nil
+}
+
+type CosiChallengeRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	SessionId       []byte                 `protobuf:"bytes,1,opt,name=sessionId,proto3" json:"sessionId,omitempty"`
+	Message         []byte                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	AggregateKey    []byte                 `protobuf:"bytes,3,opt,name=aggregateKey,proto3" json:"aggregateKey,omitempty"`
+	AggregateCommit []byte                 `protobuf:"bytes,4,opt,name=aggregateCommit,proto3" json:"aggregateCommit,omitempty"`
+	Leader          string                 `protobuf:"bytes,5,opt,name=leader,proto3" json:"leader,omitempty"`
+	TraceContext    string                 `protobuf:"bytes,6,opt,name=traceContext,proto3" json:"traceContext,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *CosiChallengeRequest) Reset() {
+	*x = CosiChallengeRequest{}
+	mi := &file_cosigner_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CosiChallengeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CosiChallengeRequest) ProtoMessage() {}
+
+func (x *CosiChallengeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cosigner_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CosiChallengeRequest.ProtoReflect.Descriptor instead.
+func (*CosiChallengeRequest) Descriptor() ([]byte, []int) {
+	return file_cosigner_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CosiChallengeRequest) GetSessionId() []byte {
+	if x != nil {
+		return x.SessionId
+	}
+	return nil
+}
+
+func (x *CosiChallengeRequest) GetMessage() []byte {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+func (x *CosiChallengeRequest) GetAggregateKey() []byte {
+	if x != nil {
+		return x.AggregateKey
+	}
+	return nil
+}
+
+func (x *CosiChallengeRequest) GetAggregateCommit() []byte {
+	if x != nil {
+		return x.AggregateCommit
+	}
+	return nil
+}
+
+func (x *CosiChallengeRequest) GetLeader() string {
+	if x != nil {
+		return x.Leader
+	}
+	return ""
+}
+
+func (x *CosiChallengeRequest) GetTraceContext() string {
+	if x != nil {
+		return x.TraceContext
+	}
+	return ""
+}
+
+type CosiChallengeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Part          []byte                 `protobuf:"bytes,1,opt,name=part,proto3" json:"part,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CosiChallengeResponse) Reset() {
+	*x = CosiChallengeResponse{}
+	mi := &file_cosigner_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CosiChallengeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CosiChallengeResponse) ProtoMessage() {}
+
+func (x *CosiChallengeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cosigner_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CosiChallengeResponse.ProtoReflect.Descriptor instead.
+func (*CosiChallengeResponse) Descriptor() ([]byte, []int) {
+	return file_cosigner_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CosiChallengeResponse) GetPart() []byte {
+	if x != nil {
+		return x.Part
+	}
+	return nil
+}
+
+type CosiGroupUpdate struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Group            []byte                 `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`                       // 새 그룹 파일 (JSON)
+	Signature        []byte                 `protobuf:"bytes,2,opt,name=signature,proto3" json:"signature,omitempty"`               // 지금 그룹의 endorsement
+	BindingSessionId []byte                 `protobuf:"bytes,3,opt,name=bindingSessionId,proto3" json:"bindingSessionId,omitempty"` // bound 라운드의 서명이면 그 binding
+	BindingLeader    []byte                 `protobuf:"bytes,4,opt,name=bindingLeader,proto3" json:"bindingLeader,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *CosiGroupUpdate) Reset() {
+	*x = CosiGroupUpdate{}
+	mi := &file_cosigner_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CosiGroupUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CosiGroupUpdate) ProtoMessage() {}
+
+func (x *CosiGroupUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_cosigner_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CosiGroupUpdate.ProtoReflect.Descriptor instead.
+func (*CosiGroupUpdate) Descriptor() ([]byte, []int) {
+	return file_cosigner_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CosiGroupUpdate) GetGroup() []byte {
+	if x != nil {
+		return x.Group
+	}
+	return nil
+}
+
+func (x *CosiGroupUpdate) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+func (x *CosiGroupUpdate) GetBindingSessionId() []byte {
+	if x != nil {
+		return x.BindingSessionId
+	}
+	return nil
+}
+
+func (x *CosiGroupUpdate) GetBindingLeader() []byte {
+	if x != nil {
+		return x.BindingLeader
+	}
+	return nil
+}
+
+type CosiGroupUpdateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Fingerprint   []byte                 `protobuf:"bytes,1,opt,name=fingerprint,proto3" json:"fingerprint,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CosiGroupUpdateResponse) Reset() {
+	*x = CosiGroupUpdateResponse{}
+	mi := &file_cosigner_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CosiGroupUpdateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CosiGroupUpdateResponse) ProtoMessage() {}
+
+func (x *CosiGroupUpdateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cosigner_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CosiGroupUpdateResponse.ProtoReflect.Descriptor instead.
+func (*CosiGroupUpdateResponse) Descriptor() ([]byte, []int) {
+	return file_cosigner_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CosiGroupUpdateResponse) GetFingerprint() []byte {
+	if x != nil {
+		return x.Fingerprint
+	}
+	return nil
+}
+
+var File_cosigner_proto protoreflect.FileDescriptor
+
+const file_cosigner_proto_rawDesc = "" +
+	"\n" +
+	"\x0ecosigner.proto\x12\x04mesh\"\xc7\x02\n" +
+	"\x11CosiCommitRequest\x12\x1c\n" +
+	"\tsessionId\x18\x01 \x01(\fR\tsessionId\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\fR\amessage\x12\x16\n" +
+	"\x06leader\x18\x03 \x01(\tR\x06leader\x12\x12\n" +
+	"\x04term\x18\x04 \x01(\x04R\x04term\x12\x14\n" +
+	"\x05bound\x18\x05 \x01(\bR\x05bound\x12A\n" +
+	"\bmetadata\x18\x06 \x03(\v2%.mesh.CosiCommitRequest.MetadataEntryR\bmetadata\x12\"\n" +
+	"\ftraceContext\x18\a \x01(\tR\ftraceContext\x12\x14\n" +
+	"\x05group\x18\b \x01(\fR\x05group\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\",\n" +
+	"\x12CosiCommitResponse\x12\x16\n" +
+	"\x06commit\x18\x01 \x01(\fR\x06commit\"\xd8\x01\n" +
+	"\x14CosiChallengeRequest\x12\x1c\n" +
+	"\tsessionId\x18\x01 \x01(\fR\tsessionId\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\fR\amessage\x12\"\n" +
+	"\faggregateKey\x18\x03 \x01(\fR\faggregateKey\x12(\n" +
+	"\x0faggregateCommit\x18\x04 \x01(\fR\x0faggregateCommit\x12\x16\n" +
+	"\x06leader\x18\x05 \x01(\tR\x06leader\x12\"\n" +
+	"\ftraceContext\x18\x06 \x01(\tR\ftraceContext\"+\n" +
+	"\x15CosiChallengeResponse\x12\x12\n" +
+	"\x04part\x18\x01 \x01(\fR\x04part\"\x97\x01\n" +
+	"\x0fCosiGroupUpdate\x12\x14\n" +
+	"\x05group\x18\x01 \x01(\fR\x05group\x12\x1c\n" +
+	"\tsignature\x18\x02 \x01(\fR\tsignature\x12*\n" +
+	"\x10bindingSessionId\x18\x03 \x01(\fR\x10bindingSessionId\x12$\n" +
+	"\rbindingLeader\x18\x04 \x01(\fR\rbindingLeader\";\n" +
+	"\x17CosiGroupUpdateResponse\x12 \n" +
+	"\vfingerprint\x18\x01 \x01(\fR\vfingerprint2\xd0\x01\n" +
+	"\bCosigner\x12;\n" +
+	"\x06Commit\x12\x17.mesh.CosiCommitRequest\x1a\x18.mesh.CosiCommitResponse\x12B\n" +
+	"\aRespond\x12\x1a.mesh.CosiChallengeRequest\x1a\x1b.mesh.CosiChallengeResponse\x12C\n" +
+	"\vUpdateGroup\x12\x15.mesh.CosiGroupUpdate\x1a\x1d.mesh.CosiGroupUpdateResponseB\x13Z\x11./proto_interfaceb\x06proto3"
+
+var (
+	file_cosigner_proto_rawDescOnce sync.Once
+	file_cosigner_proto_rawDescData []byte
+)
+
+func file_cosigner_proto_rawDescGZIP() []byte {
+	file_cosigner_proto_rawDescOnce.Do(func() {
+		file_cosigner_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_cosigner_proto_rawDesc), len(file_cosigner_proto_rawDesc)))
+	})
+	return file_cosigner_proto_rawDescData
+}
+
+var file_cosigner_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_cosigner_proto_goTypes = []any{
+	(*CosiCommitRequest)(nil),       // 0: mesh.CosiCommitRequest
+	(*CosiCommitResponse)(nil),      // 1: mesh.CosiCommitResponse
+	(*CosiChallengeRequest)(nil),    // 2: mesh.CosiChallengeRequest
+	(*CosiChallengeResponse)(nil),   // 3: mesh.CosiChallengeResponse
+	(*CosiGroupUpdate)(nil),         // 4: mesh.CosiGroupUpdate
+	(*CosiGroupUpdateResponse)(nil), // 5: mesh.CosiGroupUpdateResponse
+	nil,                             // 6: mesh.CosiCommitRequest.MetadataEntry
+}
+var file_cosigner_proto_depIdxs = []int32{
+	6, // 0: mesh.CosiCommitRequest.metadata:type_name -> mesh.CosiCommitRequest.MetadataEntry
+	0, // 1: mesh.Cosigner.Commit:input_type -> mesh.CosiCommitRequest
+	2, // 2: mesh.Cosigner.Respond:input_type -> mesh.CosiChallengeRequest
+	4, // 3: mesh.Cosigner.UpdateGroup:input_type -> mesh.CosiGroupUpdate
+	1, // 4: mesh.Cosigner.Commit:output_type -> mesh.CosiCommitResponse
+	3, // 5: mesh.Cosigner.Respond:output_type -> mesh.CosiChallengeResponse
+	5, // 6: mesh.Cosigner.UpdateGroup:output_type -> mesh.CosiGroupUpdateResponse
+	4, // [4:7] is the sub-list for method output_type
+	1, // [1:4] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_cosigner_proto_init() }
+func file_cosigner_proto_init() {
+	if File_cosigner_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_cosigner_proto_rawDesc), len(file_cosigner_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_cosigner_proto_goTypes,
+		DependencyIndexes: file_cosigner_proto_depIdxs,
+		MessageInfos:      file_cosigner_proto_msgTypes,
+	}.Build()
+	File_cosigner_proto = out.File
+	file_cosigner_proto_goTypes = nil
+	file_cosigner_proto_depIdxs = nil
+}
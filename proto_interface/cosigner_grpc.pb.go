@@ -0,0 +1,185 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: cosigner.proto
+
+package proto_interface
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Cosigner_Commit_FullMethodName      = "/mesh.Cosigner/Commit"
+	Cosigner_Respond_FullMethodName     = "/mesh.Cosigner/Respond"
+	Cosigner_UpdateGroup_FullMethodName = "/mesh.Cosigner/UpdateGroup"
+)
+
+// CosignerClient is the client API for Cosigner service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type CosignerClient interface {
+	Commit(ctx context.Context, in *CosiCommitRequest, opts ...grpc.CallOption) (*CosiCommitResponse, error)
+	Respond(ctx context.Context, in *CosiChallengeRequest, opts ...grpc.CallOption) (*CosiChallengeResponse, error)
+	// 지금 그룹이 승인한(group.VerifyRotation) 새 그룹 파일로 교체
+	UpdateGroup(ctx context.Context, in *CosiGroupUpdate, opts ...grpc.CallOption) (*CosiGroupUpdateResponse, error)
+}
+
+type cosignerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCosignerClient(cc grpc.ClientConnInterface) CosignerClient {
+	return &cosignerClient{cc}
+}
+
+func (c *cosignerClient) Commit(ctx context.Context, in *CosiCommitRequest, opts ...grpc.CallOption) (*CosiCommitResponse, error) {
+	out := new(CosiCommitResponse)
+	err := c.cc.Invoke(ctx, Cosigner_Commit_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cosignerClient) Respond(ctx context.Context, in *CosiChallengeRequest, opts ...grpc.CallOption) (*CosiChallengeResponse, error) {
+	out := new(CosiChallengeResponse)
+	err := c.cc.Invoke(ctx, Cosigner_Respond_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cosignerClient) UpdateGroup(ctx context.Context, in *CosiGroupUpdate, opts ...grpc.CallOption) (*CosiGroupUpdateResponse, error) {
+	out := new(CosiGroupUpdateResponse)
+	err := c.cc.Invoke(ctx, Cosigner_UpdateGroup_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CosignerServer is the server API for Cosigner service.
+// All implementations must embed UnimplementedCosignerServer
+// for forward compatibility
+type CosignerServer interface {
+	Commit(context.Context, *CosiCommitRequest) (*CosiCommitResponse, error)
+	Respond(context.Context, *CosiChallengeRequest) (*CosiChallengeResponse, error)
+	// 지금 그룹이 승인한(group.VerifyRotation) 새 그룹 파일로 교체
+	UpdateGroup(context.Context, *CosiGroupUpdate) (*CosiGroupUpdateResponse, error)
+	mustEmbedUnimplementedCosignerServer()
+}
+
+// UnimplementedCosignerServer must be embedded to have forward compatible implementations.
+type UnimplementedCosignerServer struct {
+}
+
+func (UnimplementedCosignerServer) Commit(context.Context, *CosiCommitRequest) (*CosiCommitResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Commit not implemented")
+}
+func (UnimplementedCosignerServer) Respond(context.Context, *CosiChallengeRequest) (*CosiChallengeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Respond not implemented")
+}
+func (UnimplementedCosignerServer) UpdateGroup(context.Context, *CosiGroupUpdate) (*CosiGroupUpdateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateGroup not implemented")
+}
+func (UnimplementedCosignerServer) mustEmbedUnimplementedCosignerServer() {}
+
+// UnsafeCosignerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CosignerServer will
+// result in compilation errors.
+type UnsafeCosignerServer interface {
+	mustEmbedUnimplementedCosignerServer()
+}
+
+func RegisterCosignerServer(s grpc.ServiceRegistrar, srv CosignerServer) {
+	s.RegisterService(&Cosigner_ServiceDesc, srv)
+}
+
+func _Cosigner_Commit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CosiCommitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CosignerServer).Commit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cosigner_Commit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CosignerServer).Commit(ctx, req.(*CosiCommitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cosigner_Respond_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CosiChallengeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CosignerServer).Respond(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cosigner_Respond_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CosignerServer).Respond(ctx, req.(*CosiChallengeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cosigner_UpdateGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CosiGroupUpdate)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CosignerServer).UpdateGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cosigner_UpdateGroup_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CosignerServer).UpdateGroup(ctx, req.(*CosiGroupUpdate))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Cosigner_ServiceDesc is the grpc.ServiceDesc for Cosigner service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Cosigner_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "mesh.Cosigner",
+	HandlerType: (*CosignerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Commit",
+			Handler:    _Cosigner_Commit_Handler,
+		},
+		{
+			MethodName: "Respond",
+			Handler:    _Cosigner_Respond_Handler,
+		},
+		{
+			MethodName: "UpdateGroup",
+			Handler:    _Cosigner_UpdateGroup_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "cosigner.proto",
+}
@@ -0,0 +1,403 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: signer.proto
+
+package proto_interface
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Signer_Sign_FullMethodName                   = "/mesh.Signer/Sign"
+	Signer_Verify_FullMethodName                 = "/mesh.Signer/Verify"
+	Signer_CollectiveVerify_FullMethodName       = "/mesh.Signer/CollectiveVerify"
+	Signer_SignStream_FullMethodName             = "/mesh.Signer/SignStream"
+	Signer_VerifyStream_FullMethodName           = "/mesh.Signer/VerifyStream"
+	Signer_CollectiveVerifyStream_FullMethodName = "/mesh.Signer/CollectiveVerifyStream"
+)
+
+// SignerClient is the client API for Signer service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type SignerClient interface {
+	Sign(ctx context.Context, in *SignRequest, opts ...grpc.CallOption) (*SignResponse, error)
+	Verify(ctx context.Context, in *VerifyRequest, opts ...grpc.CallOption) (*VerifyResponse, error)
+	CollectiveVerify(ctx context.Context, in *CollectiveVerifyRequest, opts ...grpc.CallOption) (*VerifyResponse, error)
+	// 큰 메시지용 스트리밍 버전: 메시지는 모든 요청의 message를 이어붙인 것이고,
+	// 나머지 필드는 첫 요청의 값만 사용한다.
+	// prehash(Ed25519ph)면 서버가 메시지를 버퍼링하지 않고 해시한다.
+	SignStream(ctx context.Context, opts ...grpc.CallOption) (Signer_SignStreamClient, error)
+	VerifyStream(ctx context.Context, opts ...grpc.CallOption) (Signer_VerifyStreamClient, error)
+	CollectiveVerifyStream(ctx context.Context, opts ...grpc.CallOption) (Signer_CollectiveVerifyStreamClient, error)
+}
+
+type signerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSignerClient(cc grpc.ClientConnInterface) SignerClient {
+	return &signerClient{cc}
+}
+
+func (c *signerClient) Sign(ctx context.Context, in *SignRequest, opts ...grpc.CallOption) (*SignResponse, error) {
+	out := new(SignResponse)
+	err := c.cc.Invoke(ctx, Signer_Sign_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *signerClient) Verify(ctx context.Context, in *VerifyRequest, opts ...grpc.CallOption) (*VerifyResponse, error) {
+	out := new(VerifyResponse)
+	err := c.cc.Invoke(ctx, Signer_Verify_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *signerClient) CollectiveVerify(ctx context.Context, in *CollectiveVerifyRequest, opts ...grpc.CallOption) (*VerifyResponse, error) {
+	out := new(VerifyResponse)
+	err := c.cc.Invoke(ctx, Signer_CollectiveVerify_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *signerClient) SignStream(ctx context.Context, opts ...grpc.CallOption) (Signer_SignStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Signer_ServiceDesc.Streams[0], Signer_SignStream_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &signerSignStreamClient{stream}
+	return x, nil
+}
+
+type Signer_SignStreamClient interface {
+	Send(*SignRequest) error
+	CloseAndRecv() (*SignResponse, error)
+	grpc.ClientStream
+}
+
+type signerSignStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *signerSignStreamClient) Send(m *SignRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *signerSignStreamClient) CloseAndRecv() (*SignResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(SignResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *signerClient) VerifyStream(ctx context.Context, opts ...grpc.CallOption) (Signer_VerifyStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Signer_ServiceDesc.Streams[1], Signer_VerifyStream_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &signerVerifyStreamClient{stream}
+	return x, nil
+}
+
+type Signer_VerifyStreamClient interface {
+	Send(*VerifyRequest) error
+	CloseAndRecv() (*VerifyResponse, error)
+	grpc.ClientStream
+}
+
+type signerVerifyStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *signerVerifyStreamClient) Send(m *VerifyRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *signerVerifyStreamClient) CloseAndRecv() (*VerifyResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(VerifyResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *signerClient) CollectiveVerifyStream(ctx context.Context, opts ...grpc.CallOption) (Signer_CollectiveVerifyStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Signer_ServiceDesc.Streams[2], Signer_CollectiveVerifyStream_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &signerCollectiveVerifyStreamClient{stream}
+	return x, nil
+}
+
+type Signer_CollectiveVerifyStreamClient interface {
+	Send(*CollectiveVerifyRequest) error
+	CloseAndRecv() (*VerifyResponse, error)
+	grpc.ClientStream
+}
+
+type signerCollectiveVerifyStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *signerCollectiveVerifyStreamClient) Send(m *CollectiveVerifyRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *signerCollectiveVerifyStreamClient) CloseAndRecv() (*VerifyResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(VerifyResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// SignerServer is the server API for Signer service.
+// All implementations must embed UnimplementedSignerServer
+// for forward compatibility
+type SignerServer interface {
+	Sign(context.Context, *SignRequest) (*SignResponse, error)
+	Verify(context.Context, *VerifyRequest) (*VerifyResponse, error)
+	CollectiveVerify(context.Context, *CollectiveVerifyRequest) (*VerifyResponse, error)
+	// 큰 메시지용 스트리밍 버전: 메시지는 모든 요청의 message를 이어붙인 것이고,
+	// 나머지 필드는 첫 요청의 값만 사용한다.
+	// prehash(Ed25519ph)면 서버가 메시지를 버퍼링하지 않고 해시한다.
+	SignStream(Signer_SignStreamServer) error
+	VerifyStream(Signer_VerifyStreamServer) error
+	CollectiveVerifyStream(Signer_CollectiveVerifyStreamServer) error
+	mustEmbedUnimplementedSignerServer()
+}
+
+// UnimplementedSignerServer must be embedded to have forward compatible implementations.
+type UnimplementedSignerServer struct {
+}
+
+func (UnimplementedSignerServer) Sign(context.Context, *SignRequest) (*SignResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Sign not implemented")
+}
+func (UnimplementedSignerServer) Verify(context.Context, *VerifyRequest) (*VerifyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Verify not implemented")
+}
+func (UnimplementedSignerServer) CollectiveVerify(context.Context, *CollectiveVerifyRequest) (*VerifyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CollectiveVerify not implemented")
+}
+func (UnimplementedSignerServer) SignStream(Signer_SignStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method SignStream not implemented")
+}
+func (UnimplementedSignerServer) VerifyStream(Signer_VerifyStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method VerifyStream not implemented")
+}
+func (UnimplementedSignerServer) CollectiveVerifyStream(Signer_CollectiveVerifyStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method CollectiveVerifyStream not implemented")
+}
+func (UnimplementedSignerServer) mustEmbedUnimplementedSignerServer() {}
+
+// UnsafeSignerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SignerServer will
+// result in compilation errors.
+type UnsafeSignerServer interface {
+	mustEmbedUnimplementedSignerServer()
+}
+
+func RegisterSignerServer(s grpc.ServiceRegistrar, srv SignerServer) {
+	s.RegisterService(&Signer_ServiceDesc, srv)
+}
+
+func _Signer_Sign_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SignRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SignerServer).Sign(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Signer_Sign_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SignerServer).Sign(ctx, req.(*SignRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Signer_Verify_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SignerServer).Verify(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Signer_Verify_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SignerServer).Verify(ctx, req.(*VerifyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Signer_CollectiveVerify_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CollectiveVerifyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SignerServer).CollectiveVerify(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Signer_CollectiveVerify_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SignerServer).CollectiveVerify(ctx, req.(*CollectiveVerifyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Signer_SignStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(SignerServer).SignStream(&signerSignStreamServer{stream})
+}
+
+type Signer_SignStreamServer interface {
+	SendAndClose(*SignResponse) error
+	Recv() (*SignRequest, error)
+	grpc.ServerStream
+}
+
+type signerSignStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *signerSignStreamServer) SendAndClose(m *SignResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *signerSignStreamServer) Recv() (*SignRequest, error) {
+	m := new(SignRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Signer_VerifyStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(SignerServer).VerifyStream(&signerVerifyStreamServer{stream})
+}
+
+type Signer_VerifyStreamServer interface {
+	SendAndClose(*VerifyResponse) error
+	Recv() (*VerifyRequest, error)
+	grpc.ServerStream
+}
+
+type signerVerifyStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *signerVerifyStreamServer) SendAndClose(m *VerifyResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *signerVerifyStreamServer) Recv() (*VerifyRequest, error) {
+	m := new(VerifyRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Signer_CollectiveVerifyStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(SignerServer).CollectiveVerifyStream(&signerCollectiveVerifyStreamServer{stream})
+}
+
+type Signer_CollectiveVerifyStreamServer interface {
+	SendAndClose(*VerifyResponse) error
+	Recv() (*CollectiveVerifyRequest, error)
+	grpc.ServerStream
+}
+
+type signerCollectiveVerifyStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *signerCollectiveVerifyStreamServer) SendAndClose(m *VerifyResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *signerCollectiveVerifyStreamServer) Recv() (*CollectiveVerifyRequest, error) {
+	m := new(CollectiveVerifyRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Signer_ServiceDesc is the grpc.ServiceDesc for Signer service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Signer_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "mesh.Signer",
+	HandlerType: (*SignerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Sign",
+			Handler:    _Signer_Sign_Handler,
+		},
+		{
+			MethodName: "Verify",
+			Handler:    _Signer_Verify_Handler,
+		},
+		{
+			MethodName: "CollectiveVerify",
+			Handler:    _Signer_CollectiveVerify_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SignStream",
+			Handler:       _Signer_SignStream_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "VerifyStream",
+			Handler:       _Signer_VerifyStream_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "CollectiveVerifyStream",
+			Handler:       _Signer_CollectiveVerifyStream_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "signer.proto",
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"io"
+
+	pb "test-server/proto_interface"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/golang-x-crypto/ed25519/cosi"
+)
+
+// signer.go에는 Signer gRPC 서비스(서명/검증)와 HTTP API와 공유하는 검증 로직
+type signerSrv struct {
+	pb.UnimplementedSignerServer
+}
+
+// 스트리밍 요청에서 prehash가 아닐 때 버퍼링할 수 있는 메시지 최대 크기(64MiB)
+const maxStreamMessageSize = 64 << 20
+
+func (s *signerSrv) Sign(_ context.Context, req *pb.SignRequest) (*pb.SignResponse, error) {
+	msg := req.Message
+	if req.Prehash {
+		digest := sha512.Sum512(msg)
+		msg = digest[:]
+	}
+	return signMessage(req, msg)
+}
+
+func (s *signerSrv) Verify(_ context.Context, req *pb.VerifyRequest) (*pb.VerifyResponse, error) {
+	msg := req.Message
+	if req.Prehash {
+		digest := sha512.Sum512(msg)
+		msg = digest[:]
+	}
+	return verifyMessage(req, msg)
+}
+
+func (s *signerSrv) CollectiveVerify(_ context.Context, req *pb.CollectiveVerifyRequest) (*pb.VerifyResponse, error) {
+	return collectiveVerifyMessage(req, req.Message)
+}
+
+func (s *signerSrv) SignStream(stream pb.Signer_SignStreamServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return recvError(err)
+	}
+	msg, err := readStream(req.Message, func() ([]byte, error) {
+		r, err := stream.Recv()
+		return r.GetMessage(), err
+	}, req.Prehash)
+	if err != nil {
+		return err
+	}
+	resp, err := signMessage(req, msg)
+	if err != nil {
+		return err
+	}
+	return stream.SendAndClose(resp)
+}
+
+func (s *signerSrv) VerifyStream(stream pb.Signer_VerifyStreamServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return recvError(err)
+	}
+	msg, err := readStream(req.Message, func() ([]byte, error) {
+		r, err := stream.Recv()
+		return r.GetMessage(), err
+	}, req.Prehash)
+	if err != nil {
+		return err
+	}
+	resp, err := verifyMessage(req, msg)
+	if err != nil {
+		return err
+	}
+	return stream.SendAndClose(resp)
+}
+
+func (s *signerSrv) CollectiveVerifyStream(stream pb.Signer_CollectiveVerifyStreamServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return recvError(err)
+	}
+	msg, err := readStream(req.Message, func() ([]byte, error) {
+		r, err := stream.Recv()
+		return r.GetMessage(), err
+	}, false)
+	if err != nil {
+		return err
+	}
+	resp, err := collectiveVerifyMessage(req, msg)
+	if err != nil {
+		return err
+	}
+	return stream.SendAndClose(resp)
+}
+
+// 첫 요청 없이 스트림이 끝난 경우는 요청 오류
+func recvError(err error) error {
+	if err == io.EOF {
+		return status.Error(codes.InvalidArgument, "empty request stream")
+	}
+	return err
+}
+
+// readStream: first 뒤에 나머지 요청의 message를 이어붙여 반환
+// prehash면 버퍼링 없이 SHA-512 다이제스트를 반환한다.
+func readStream(first []byte, next func() ([]byte, error), prehash bool) ([]byte, error) {
+	h := sha512.New()
+	var buf []byte
+	for chunk := first; ; {
+		if prehash {
+			h.Write(chunk)
+		} else {
+			if len(buf)+len(chunk) > maxStreamMessageSize {
+				return nil, status.Errorf(codes.ResourceExhausted,
+					"message exceeds %d bytes; use prehash", maxStreamMessageSize)
+			}
+			buf = append(buf, chunk...)
+		}
+		var err error
+		if chunk, err = next(); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	if prehash {
+		return h.Sum(nil), nil
+	}
+	return buf, nil
+}
+
+// msg는 prehash면 이미 계산된 SHA-512 다이제스트
+func signMessage(req *pb.SignRequest, msg []byte) (*pb.SignResponse, error) {
+	priv := ed25519.PrivateKey(req.PrivateKey)
+	if len(priv) != ed25519.PrivateKeySize {
+		return nil, status.Error(codes.InvalidArgument, ed25519.ErrPrivateKeySize.Error())
+	}
+	sig, err := priv.Sign(nil, msg, ed25519Options(req.Context, req.Prehash))
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return &pb.SignResponse{Signature: sig}, nil
+}
+
+func verifyMessage(req *pb.VerifyRequest, msg []byte) (*pb.VerifyResponse, error) {
+	if err := checkPublicKey(req.PublicKey); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	err := ed25519.VerifyWithOptions(req.PublicKey, msg, req.Signature,
+		ed25519Options(req.Context, req.Prehash))
+	return newPBVerifyResponse(err), nil
+}
+
+func collectiveVerifyMessage(req *pb.CollectiveVerifyRequest, msg []byte) (*pb.VerifyResponse, error) {
+	pubKeys := make([]ed25519.PublicKey, len(req.PublicKeys))
+	for i, pk := range req.PublicKeys {
+		pubKeys[i] = pk
+	}
+	cosigners, err := newCosigners(pubKeys, int(req.Threshold))
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return newPBVerifyResponse(cosigners.VerifyErr(msg, req.Signature)), nil
+}
+
+func newPBVerifyResponse(err error) *pb.VerifyResponse {
+	if err != nil {
+		return &pb.VerifyResponse{Valid: false, Error: err.Error()}
+	}
+	return &pb.VerifyResponse{Valid: true}
+}
+
+// ed25519Options: context/prehash 조합 → Ed25519, Ed25519ctx, Ed25519ph
+func ed25519Options(context string, prehash bool) *ed25519.Options {
+	if prehash {
+		return &ed25519.Options{Hash: crypto.SHA512, Context: context}
+	}
+	return &ed25519.Options{Context: context}
+}
+
+// VerifyWithOptions는 길이가 틀린 공개키에 panic하므로 먼저 확인
+func checkPublicKey(pub []byte) error {
+	if len(pub) != ed25519.PublicKeySize {
+		return fmt.Errorf("ed25519: bad public key length: %d", len(pub))
+	}
+	return nil
+}
+
+// newCosigners: 검증용 Cosigners 생성, threshold가 0이면 모든 cosigner의 서명 필요
+func newCosigners(pubKeys []ed25519.PublicKey, threshold int) (*cosi.Cosigners, error) {
+	if len(pubKeys) == 0 {
+		return nil, errors.New("cosi: no public keys")
+	}
+	if threshold < 0 || threshold > len(pubKeys) {
+		return nil, fmt.Errorf("cosi: threshold %d out of range [0, %d]", threshold, len(pubKeys))
+	}
+	cosigners, err := cosi.NewCosignersErr(pubKeys, nil)
+	if err != nil {
+		return nil, err
+	}
+	if threshold > 0 {
+		cosigners.SetPolicy(cosi.ThresholdPolicy(threshold))
+	}
+	return cosigners, nil
+}
@@ -0,0 +1,323 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/sha512"
+	"net"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"test-server/golang-x-crypto/ed25519"
+	"test-server/golang-x-crypto/ed25519/cosi/cositest"
+	pb "test-server/proto_interface"
+)
+
+// 스트리밍 테스트에서 한 요청에 싣는 메시지 조각 크기(gRPC 기본 최대 수신 크기 4MiB보다 작게)
+const testChunkSize = 1 << 20
+
+// newSignerClient: bufconn 위에 Signer 서비스를 띄우고 연결한 클라이언트를 반환
+func newSignerClient(t *testing.T) pb.SignerClient {
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	pb.RegisterSignerServer(s, &signerSrv{})
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewSignerClient(conn)
+}
+
+// chunks: message를 size 바이트씩 나눔
+func chunks(message []byte, size int) [][]byte {
+	var out [][]byte
+	for len(message) > size {
+		out = append(out, message[:size])
+		message = message[size:]
+	}
+	return append(out, message)
+}
+
+func signStream(ctx context.Context, c pb.SignerClient, reqs []*pb.SignRequest) (*pb.SignResponse, error) {
+	stream, err := c.SignStream(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, req := range reqs {
+		if err := stream.Send(req); err != nil {
+			break // 서버가 먼저 끝낸 경우, 에러는 CloseAndRecv가 반환
+		}
+	}
+	return stream.CloseAndRecv()
+}
+
+func verifyStream(ctx context.Context, c pb.SignerClient, reqs []*pb.VerifyRequest) (*pb.VerifyResponse, error) {
+	stream, err := c.VerifyStream(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, req := range reqs {
+		if err := stream.Send(req); err != nil {
+			break
+		}
+	}
+	return stream.CloseAndRecv()
+}
+
+func TestSignerUnary(t *testing.T) {
+	ctx := context.Background()
+	c := newSignerClient(t)
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	message := []byte("test message")
+
+	for _, tt := range []struct {
+		name    string
+		context string
+		prehash bool
+		opts    *ed25519.Options
+	}{
+		{"Ed25519", "", false, &ed25519.Options{}},
+		{"Ed25519ctx", "ctx", false, &ed25519.Options{Context: "ctx"}},
+		{"Ed25519ph", "ctx", true, &ed25519.Options{Hash: crypto.SHA512, Context: "ctx"}},
+	} {
+		s, err := c.Sign(ctx, &pb.SignRequest{PrivateKey: priv, Message: message, Context: tt.context, Prehash: tt.prehash})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		msg := message
+		if tt.prehash {
+			digest := sha512.Sum512(message)
+			msg = digest[:]
+		}
+		if err := ed25519.VerifyWithOptions(pub, msg, s.Signature, tt.opts); err != nil {
+			t.Errorf("%s: signature from Sign: %v", tt.name, err)
+		}
+
+		v, err := c.Verify(ctx, &pb.VerifyRequest{PublicKey: pub, Message: message, Signature: s.Signature,
+			Context: tt.context, Prehash: tt.prehash})
+		if err != nil || !v.Valid {
+			t.Errorf("%s: Verify = %v, %v", tt.name, v, err)
+		}
+		v, err = c.Verify(ctx, &pb.VerifyRequest{PublicKey: pub, Message: []byte("other message"), Signature: s.Signature,
+			Context: tt.context, Prehash: tt.prehash})
+		if err != nil || v.Valid || v.Error == "" {
+			t.Errorf("%s: Verify of a different message = %v, %v", tt.name, v, err)
+		}
+	}
+}
+
+func TestSignerInvalidArgument(t *testing.T) {
+	ctx := context.Background()
+	c := newSignerClient(t)
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	message := []byte("test message")
+	long := strings.Repeat("c", 256)
+
+	for _, tt := range []struct {
+		name string
+		call func() error
+	}{
+		{"Sign with short key", func() error {
+			_, err := c.Sign(ctx, &pb.SignRequest{PrivateKey: priv[:32], Message: message})
+			return err
+		}},
+		{"Sign with long context", func() error {
+			_, err := c.Sign(ctx, &pb.SignRequest{PrivateKey: priv, Message: message, Context: long})
+			return err
+		}},
+		{"Verify with short key", func() error {
+			_, err := c.Verify(ctx, &pb.VerifyRequest{PublicKey: pub[:31], Message: message})
+			return err
+		}},
+		{"Verify with long key", func() error {
+			_, err := c.Verify(ctx, &pb.VerifyRequest{PublicKey: priv, Message: message})
+			return err
+		}},
+		{"SignStream with short key", func() error {
+			_, err := signStream(ctx, c, []*pb.SignRequest{{PrivateKey: priv[:32], Message: message}})
+			return err
+		}},
+		{"VerifyStream with short key", func() error {
+			_, err := verifyStream(ctx, c, []*pb.VerifyRequest{{PublicKey: pub[:31], Message: message}})
+			return err
+		}},
+		{"empty SignStream", func() error {
+			_, err := signStream(ctx, c, nil)
+			return err
+		}},
+		{"empty VerifyStream", func() error {
+			_, err := verifyStream(ctx, c, nil)
+			return err
+		}},
+	} {
+		if err := tt.call(); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("%s: %v, want InvalidArgument", tt.name, err)
+		}
+	}
+
+	// 서명 길이가 틀린 것은 요청 오류가 아니라 검증 실패(HTTP API와 같음)
+	s, err := c.Sign(ctx, &pb.SignRequest{PrivateKey: priv, Message: message})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, sig := range [][]byte{nil, s.Signature[:63], append(s.Signature, 0)} {
+		v, err := c.Verify(ctx, &pb.VerifyRequest{PublicKey: pub, Message: message, Signature: sig})
+		if err != nil || v.Valid || v.Error != ed25519.ErrSignatureSize.Error() {
+			t.Errorf("Verify with a %d-byte signature = %v, %v", len(sig), v, err)
+		}
+	}
+}
+
+func TestSignerStream(t *testing.T) {
+	ctx := context.Background()
+	c := newSignerClient(t)
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	_, other, _ := ed25519.GenerateKey(nil)
+	message := bytes.Repeat([]byte("0123456789abcdef"), 3*testChunkSize/16+5)
+
+	for _, prehash := range []bool{false, true} {
+		// 둘째 요청부터의 message 이외 필드는 무시되어야 함
+		var reqs []*pb.SignRequest
+		for i, chunk := range chunks(message, testChunkSize) {
+			req := &pb.SignRequest{Message: chunk}
+			if i == 0 {
+				req.PrivateKey, req.Context, req.Prehash = priv, "ctx", prehash
+			} else {
+				req.PrivateKey, req.Context, req.Prehash = other, "other", !prehash
+			}
+			reqs = append(reqs, req)
+		}
+		s, err := signStream(ctx, c, reqs)
+		if err != nil {
+			t.Fatalf("prehash=%v: SignStream: %v", prehash, err)
+		}
+		want, err := c.Sign(ctx, &pb.SignRequest{PrivateKey: priv, Message: message, Context: "ctx", Prehash: prehash})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(s.Signature, want.Signature) {
+			t.Errorf("prehash=%v: SignStream signature differs from Sign of the whole message", prehash)
+		}
+
+		var vreqs []*pb.VerifyRequest
+		for i, chunk := range chunks(message, testChunkSize) {
+			req := &pb.VerifyRequest{Message: chunk}
+			if i == 0 {
+				req.PublicKey, req.Signature, req.Context, req.Prehash = pub, s.Signature, "ctx", prehash
+			} else {
+				req.Signature, req.Context = []byte("ignored"), "other"
+			}
+			vreqs = append(vreqs, req)
+		}
+		v, err := verifyStream(ctx, c, vreqs)
+		if err != nil || !v.Valid {
+			t.Errorf("prehash=%v: VerifyStream = %v, %v", prehash, v, err)
+		}
+		vreqs[len(vreqs)-1] = &pb.VerifyRequest{Message: []byte("tampered")}
+		v, err = verifyStream(ctx, c, vreqs)
+		if err != nil || v.Valid {
+			t.Errorf("prehash=%v: VerifyStream of a different message = %v, %v", prehash, v, err)
+		}
+	}
+}
+
+func TestSignerStreamLimit(t *testing.T) {
+	ctx := context.Background()
+	c := newSignerClient(t)
+	_, priv, _ := ed25519.GenerateKey(nil)
+	chunk := make([]byte, testChunkSize)
+	n := maxStreamMessageSize/testChunkSize + 1
+
+	for _, prehash := range []bool{false, true} {
+		reqs := []*pb.SignRequest{{PrivateKey: priv, Prehash: prehash}}
+		for i := 0; i < n; i++ {
+			reqs = append(reqs, &pb.SignRequest{Message: chunk})
+		}
+		_, err := signStream(ctx, c, reqs)
+		if prehash && err != nil {
+			t.Errorf("prehash stream over %d bytes: %v", maxStreamMessageSize, err)
+		}
+		if !prehash && status.Code(err) != codes.ResourceExhausted {
+			t.Errorf("stream over %d bytes: %v, want ResourceExhausted", maxStreamMessageSize, err)
+		}
+	}
+}
+
+func TestSignerCollectiveVerify(t *testing.T) {
+	ctx := context.Background()
+	c := newSignerClient(t)
+	nw, err := cositest.NewNetwork(3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nw.Close()
+	nw.Leader.Threshold = 2
+	nw.SetOnline(2, false)
+	message := bytes.Repeat([]byte("m"), 2*testChunkSize+1)
+	res, err := nw.Sign(ctx, message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := make([][]byte, len(nw.PublicKeys))
+	for i, pk := range nw.PublicKeys {
+		keys[i] = pk
+	}
+
+	req := &pb.CollectiveVerifyRequest{PublicKeys: keys, Message: message, Signature: res.Signature, Threshold: 2}
+	if v, err := c.CollectiveVerify(ctx, req); err != nil || !v.Valid {
+		t.Errorf("CollectiveVerify = %v, %v", v, err)
+	}
+	full := &pb.CollectiveVerifyRequest{PublicKeys: keys, Message: message, Signature: res.Signature}
+	if v, err := c.CollectiveVerify(ctx, full); err != nil || v.Valid || v.Error == "" {
+		t.Errorf("CollectiveVerify requiring every cosigner = %v, %v", v, err)
+	}
+
+	stream, err := c.CollectiveVerifyStream(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, chunk := range chunks(message, testChunkSize) {
+		req := &pb.CollectiveVerifyRequest{Message: chunk}
+		if i == 0 {
+			req.PublicKeys, req.Signature, req.Threshold = keys, res.Signature, 2
+		} else {
+			req.Threshold = 3 // 무시됨
+		}
+		if err := stream.Send(req); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if v, err := stream.CloseAndRecv(); err != nil || !v.Valid {
+		t.Errorf("CollectiveVerifyStream = %v, %v", v, err)
+	}
+
+	for _, tt := range []struct {
+		name string
+		req  *pb.CollectiveVerifyRequest
+	}{
+		{"no keys", &pb.CollectiveVerifyRequest{Message: message, Signature: res.Signature}},
+		{"short key", &pb.CollectiveVerifyRequest{PublicKeys: [][]byte{keys[0], keys[1][:31], keys[2]},
+			Message: message, Signature: res.Signature}},
+		{"threshold too high", &pb.CollectiveVerifyRequest{PublicKeys: keys, Message: message,
+			Signature: res.Signature, Threshold: 4}},
+	} {
+		if _, err := c.CollectiveVerify(ctx, tt.req); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("%s: %v, want InvalidArgument", tt.name, err)
+		}
+	}
+}